@@ -0,0 +1,116 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The alerts package sends push notifications to operators when the bridge encounters a critical error.
+
+Alerts are posted to a Slack incoming webhook or to the PagerDuty Events API v2. Each alert has a level, and
+alerts below the configured minimum level are dropped, so operators are only paged for the errors they choose.
+*/
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Alert levels, from least to most severe
+const (
+	LevelInfo     = "INFO"
+	LevelWarn     = "WARN"
+	LevelError    = "ERROR"
+	LevelCritical = "CRITICAL"
+)
+
+var levels = []string{LevelInfo, LevelWarn, LevelError, LevelCritical}
+
+// Supported alert providers
+const (
+	SlackProvider     = "slack"
+	PagerDutyProvider = "pagerduty"
+)
+
+// DefaultMinLevel is the least severe level sent when no minimum level is configured
+const DefaultMinLevel = LevelError
+
+// DefaultTimeout limits each request to the provider
+const DefaultTimeout = 10 * time.Second
+
+// Notifier sends alerts to operators. context holds details of the event, such as the chain it occurred on.
+type Notifier interface {
+	Notify(level string, message string, context map[string]string) error
+}
+
+var _ Notifier = &SlackNotifier{}
+var _ Notifier = &PagerDutyNotifier{}
+
+// NewNotifier returns the Notifier for provider, sending alerts of at least minLevel. For Slack webhook is the
+// incoming webhook URL, for PagerDuty it is the integration's routing key. DefaultMinLevel is used if minLevel is empty.
+func NewNotifier(provider, webhook, minLevel string) (Notifier, error) {
+	if minLevel == "" {
+		minLevel = DefaultMinLevel
+	}
+	min, err := rank(minLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifier Notifier
+	switch provider {
+	case SlackProvider:
+		notifier = NewSlackNotifier(webhook)
+	case PagerDutyProvider:
+		notifier = NewPagerDutyNotifier(webhook)
+	default:
+		return nil, fmt.Errorf("unknown alert provider: %s", provider)
+	}
+	return &levelFilter{notifier: notifier, min: min}, nil
+}
+
+// rank returns the severity of level, levels are case insensitive
+func rank(level string) (int, error) {
+	for i, l := range levels {
+		if strings.EqualFold(level, l) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown alert level: %s", level)
+}
+
+// levelFilter drops alerts below a minimum level
+type levelFilter struct {
+	notifier Notifier
+	min      int
+}
+
+func (f *levelFilter) Notify(level string, message string, context map[string]string) error {
+	r, err := rank(level)
+	if err != nil {
+		return err
+	}
+	if r < f.min {
+		return nil
+	}
+	return f.notifier.Notify(strings.ToUpper(level), message, context)
+}
+
+// postJSON posts body to url as JSON, returning an error if the response is not successful
+func postJSON(client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert request failed: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package alerts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestServer returns a server that sends each request body it receives to bodies
+func newTestServer(t *testing.T, bodies chan<- map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type: %s", r.Header.Get("Content-Type"))
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		body := make(map[string]interface{})
+		err = json.Unmarshal(data, &body)
+		if err != nil {
+			t.Error(err)
+		}
+		bodies <- body
+	}))
+}
+
+func TestSlackNotifier(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 1)
+	srv := newTestServer(t, bodies)
+	defer srv.Close()
+
+	n, err := NewNotifier(SlackProvider, srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Notify(LevelError, "chain stopped", map[string]string{"chain": "1", "err": "connection lost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"text": "[ERROR] chain stopped",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"color": "danger",
+				"fields": []interface{}{
+					map[string]interface{}{"title": "chain", "value": "1", "short": true},
+					map[string]interface{}{"title": "err", "value": "connection lost", "short": true},
+				},
+			},
+		},
+	}
+	if body := <-bodies; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("unexpected payload.\n\tExpected: %#v\n\tGot: %#v\n", expected, body)
+	}
+}
+
+func TestPagerDutyNotifier(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 1)
+	srv := newTestServer(t, bodies)
+	defer srv.Close()
+
+	n := NewPagerDutyNotifier("routingkey")
+	n.url = srv.URL
+	err := n.Notify(LevelError, "chain stopped", map[string]string{"chain": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"routing_key":  "routingkey",
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":        "chain stopped",
+			"source":         "chainbridge",
+			"severity":       "error",
+			"custom_details": map[string]interface{}{"chain": "1"},
+		},
+	}
+	if body := <-bodies; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("unexpected payload.\n\tExpected: %#v\n\tGot: %#v\n", expected, body)
+	}
+}
+
+func TestNotifier_MinLevel(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 2)
+	srv := newTestServer(t, bodies)
+	defer srv.Close()
+
+	n, err := NewNotifier(SlackProvider, srv.URL, "warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range []string{LevelInfo, LevelWarn} {
+		err = n.Notify(level, "message", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if body := <-bodies; body["text"] != "[WARN] message" {
+		t.Fatalf("unexpected payload: %v", body)
+	}
+	if len(bodies) != 0 {
+		t.Fatal("alert below the minimum level was sent")
+	}
+}
+
+func TestNotifier_Failed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := NewSlackNotifier(srv.URL).Notify(LevelError, "message", nil)
+	if err == nil {
+		t.Fatal("expected error for unsuccessful response")
+	}
+}
+
+func TestNewNotifier_Invalid(t *testing.T) {
+	_, err := NewNotifier("email", "url", "")
+	if err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+	_, err = NewNotifier(SlackProvider, "url", "DEBUG")
+	if err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package alerts
+
+import (
+	"net/http"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySeverities are the event severities of each level
+var pagerDutySeverities = map[string]string{
+	LevelInfo:     "info",
+	LevelWarn:     "warning",
+	LevelError:    "error",
+	LevelCritical: "critical",
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents with the Events API v2
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string // Integration key of the PagerDuty service
+	client     *http.Client
+}
+
+func NewPagerDutyNotifier(routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{url: DefaultPagerDutyURL, routingKey: routingKey, client: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// Notify triggers an event with the message as its summary and the context as its details
+func (n *PagerDutyNotifier) Notify(level string, message string, context map[string]string) error {
+	return postJSON(n.client, n.url, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyPayload{
+			Summary:       message,
+			Source:        "chainbridge",
+			Severity:      pagerDutySeverities[level],
+			CustomDetails: context,
+		},
+	})
+}
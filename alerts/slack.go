@@ -0,0 +1,63 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package alerts
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// slackColors are the attachment colors of each level
+var slackColors = map[string]string{
+	LevelInfo:     "good",
+	LevelWarn:     "warning",
+	LevelError:    "danger",
+	LevelCritical: "danger",
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// Notify posts the message, with the context as fields sorted by key
+func (n *SlackNotifier) Notify(level string, message string, context map[string]string) error {
+	msg := slackMessage{Text: fmt.Sprintf("[%s] %s", level, message)}
+	if len(context) != 0 {
+		keys := make([]string, 0, len(context))
+		for k := range context {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attachment := slackAttachment{Color: slackColors[level]}
+		for _, k := range keys {
+			attachment.Fields = append(attachment.Fields, slackField{Title: k, Value: context[k], Short: true})
+		}
+		msg.Attachments = []slackAttachment{attachment}
+	}
+	return postJSON(n.client, n.url, msg)
+}
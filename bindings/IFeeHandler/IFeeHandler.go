@@ -0,0 +1,232 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package IFeeHandler
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// IFeeHandlerMetaData contains all meta data concerning the IFeeHandler contract.
+var IFeeHandlerMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"uint8\",\"name\":\"originChainID\",\"type\":\"uint8\"},{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"resourceID\",\"type\":\"bytes32\"}],\"name\":\"calculateFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint8\",\"name\":\"originChainID\",\"type\":\"uint8\"},{\"internalType\":\"uint64\",\"name\":\"depositNonce\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"resourceID\",\"type\":\"bytes32\"}],\"name\":\"collectFee\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"}]",
+}
+
+// IFeeHandlerABI is the input ABI used to generate the binding from.
+// Deprecated: Use IFeeHandlerMetaData.ABI instead.
+var IFeeHandlerABI = IFeeHandlerMetaData.ABI
+
+// IFeeHandler is an auto generated Go binding around an Ethereum contract.
+type IFeeHandler struct {
+	IFeeHandlerCaller     // Read-only binding to the contract
+	IFeeHandlerTransactor // Write-only binding to the contract
+	IFeeHandlerFilterer   // Log filterer for contract events
+}
+
+// IFeeHandlerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type IFeeHandlerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeHandlerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type IFeeHandlerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeHandlerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type IFeeHandlerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IFeeHandlerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type IFeeHandlerSession struct {
+	Contract     *IFeeHandler      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// IFeeHandlerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type IFeeHandlerCallerSession struct {
+	Contract *IFeeHandlerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// IFeeHandlerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type IFeeHandlerTransactorSession struct {
+	Contract     *IFeeHandlerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// IFeeHandlerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type IFeeHandlerRaw struct {
+	Contract *IFeeHandler // Generic contract binding to access the raw methods on
+}
+
+// IFeeHandlerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type IFeeHandlerCallerRaw struct {
+	Contract *IFeeHandlerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// IFeeHandlerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type IFeeHandlerTransactorRaw struct {
+	Contract *IFeeHandlerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewIFeeHandler creates a new instance of IFeeHandler, bound to a specific deployed contract.
+func NewIFeeHandler(address common.Address, backend bind.ContractBackend) (*IFeeHandler, error) {
+	contract, err := bindIFeeHandler(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeHandler{IFeeHandlerCaller: IFeeHandlerCaller{contract: contract}, IFeeHandlerTransactor: IFeeHandlerTransactor{contract: contract}, IFeeHandlerFilterer: IFeeHandlerFilterer{contract: contract}}, nil
+}
+
+// NewIFeeHandlerCaller creates a new read-only instance of IFeeHandler, bound to a specific deployed contract.
+func NewIFeeHandlerCaller(address common.Address, caller bind.ContractCaller) (*IFeeHandlerCaller, error) {
+	contract, err := bindIFeeHandler(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeHandlerCaller{contract: contract}, nil
+}
+
+// NewIFeeHandlerTransactor creates a new write-only instance of IFeeHandler, bound to a specific deployed contract.
+func NewIFeeHandlerTransactor(address common.Address, transactor bind.ContractTransactor) (*IFeeHandlerTransactor, error) {
+	contract, err := bindIFeeHandler(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeHandlerTransactor{contract: contract}, nil
+}
+
+// NewIFeeHandlerFilterer creates a new log filterer instance of IFeeHandler, bound to a specific deployed contract.
+func NewIFeeHandlerFilterer(address common.Address, filterer bind.ContractFilterer) (*IFeeHandlerFilterer, error) {
+	contract, err := bindIFeeHandler(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &IFeeHandlerFilterer{contract: contract}, nil
+}
+
+// bindIFeeHandler binds a generic wrapper to an already deployed contract.
+func bindIFeeHandler(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(IFeeHandlerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IFeeHandler *IFeeHandlerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IFeeHandler.Contract.IFeeHandlerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IFeeHandler *IFeeHandlerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.IFeeHandlerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IFeeHandler *IFeeHandlerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.IFeeHandlerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IFeeHandler *IFeeHandlerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IFeeHandler.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IFeeHandler *IFeeHandlerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IFeeHandler *IFeeHandlerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.contract.Transact(opts, method, params...)
+}
+
+// CalculateFee is a free data retrieval call binding the contract method 0xd88d2f9d.
+//
+// Solidity: function calculateFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) view returns(uint256)
+func (_IFeeHandler *IFeeHandlerCaller) CalculateFee(opts *bind.CallOpts, originChainID uint8, depositNonce uint64, resourceID [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _IFeeHandler.contract.Call(opts, &out, "calculateFee", originChainID, depositNonce, resourceID)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CalculateFee is a free data retrieval call binding the contract method 0xd88d2f9d.
+//
+// Solidity: function calculateFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) view returns(uint256)
+func (_IFeeHandler *IFeeHandlerSession) CalculateFee(originChainID uint8, depositNonce uint64, resourceID [32]byte) (*big.Int, error) {
+	return _IFeeHandler.Contract.CalculateFee(&_IFeeHandler.CallOpts, originChainID, depositNonce, resourceID)
+}
+
+// CalculateFee is a free data retrieval call binding the contract method 0xd88d2f9d.
+//
+// Solidity: function calculateFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) view returns(uint256)
+func (_IFeeHandler *IFeeHandlerCallerSession) CalculateFee(originChainID uint8, depositNonce uint64, resourceID [32]byte) (*big.Int, error) {
+	return _IFeeHandler.Contract.CalculateFee(&_IFeeHandler.CallOpts, originChainID, depositNonce, resourceID)
+}
+
+// CollectFee is a paid mutator transaction binding the contract method 0x760bd0b2.
+//
+// Solidity: function collectFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) payable returns()
+func (_IFeeHandler *IFeeHandlerTransactor) CollectFee(opts *bind.TransactOpts, originChainID uint8, depositNonce uint64, resourceID [32]byte) (*types.Transaction, error) {
+	return _IFeeHandler.contract.Transact(opts, "collectFee", originChainID, depositNonce, resourceID)
+}
+
+// CollectFee is a paid mutator transaction binding the contract method 0x760bd0b2.
+//
+// Solidity: function collectFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) payable returns()
+func (_IFeeHandler *IFeeHandlerSession) CollectFee(originChainID uint8, depositNonce uint64, resourceID [32]byte) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.CollectFee(&_IFeeHandler.TransactOpts, originChainID, depositNonce, resourceID)
+}
+
+// CollectFee is a paid mutator transaction binding the contract method 0x760bd0b2.
+//
+// Solidity: function collectFee(uint8 originChainID, uint64 depositNonce, bytes32 resourceID) payable returns()
+func (_IFeeHandler *IFeeHandlerTransactorSession) CollectFee(originChainID uint8, depositNonce uint64, resourceID [32]byte) (*types.Transaction, error) {
+	return _IFeeHandler.Contract.CollectFee(&_IFeeHandler.TransactOpts, originChainID, depositNonce, resourceID)
+}
@@ -0,0 +1,232 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package IRelayerFees
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// IRelayerFeesMetaData contains all meta data concerning the IRelayerFees contract.
+var IRelayerFeesMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"relayer\",\"type\":\"address\"}],\"name\":\"getAvailableFees\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"claimFee\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// IRelayerFeesABI is the input ABI used to generate the binding from.
+// Deprecated: Use IRelayerFeesMetaData.ABI instead.
+var IRelayerFeesABI = IRelayerFeesMetaData.ABI
+
+// IRelayerFees is an auto generated Go binding around an Ethereum contract.
+type IRelayerFees struct {
+	IRelayerFeesCaller     // Read-only binding to the contract
+	IRelayerFeesTransactor // Write-only binding to the contract
+	IRelayerFeesFilterer   // Log filterer for contract events
+}
+
+// IRelayerFeesCaller is an auto generated read-only Go binding around an Ethereum contract.
+type IRelayerFeesCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IRelayerFeesTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type IRelayerFeesTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IRelayerFeesFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type IRelayerFeesFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IRelayerFeesSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type IRelayerFeesSession struct {
+	Contract     *IRelayerFees     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// IRelayerFeesCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type IRelayerFeesCallerSession struct {
+	Contract *IRelayerFeesCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// IRelayerFeesTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type IRelayerFeesTransactorSession struct {
+	Contract     *IRelayerFeesTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// IRelayerFeesRaw is an auto generated low-level Go binding around an Ethereum contract.
+type IRelayerFeesRaw struct {
+	Contract *IRelayerFees // Generic contract binding to access the raw methods on
+}
+
+// IRelayerFeesCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type IRelayerFeesCallerRaw struct {
+	Contract *IRelayerFeesCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// IRelayerFeesTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type IRelayerFeesTransactorRaw struct {
+	Contract *IRelayerFeesTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewIRelayerFees creates a new instance of IRelayerFees, bound to a specific deployed contract.
+func NewIRelayerFees(address common.Address, backend bind.ContractBackend) (*IRelayerFees, error) {
+	contract, err := bindIRelayerFees(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &IRelayerFees{IRelayerFeesCaller: IRelayerFeesCaller{contract: contract}, IRelayerFeesTransactor: IRelayerFeesTransactor{contract: contract}, IRelayerFeesFilterer: IRelayerFeesFilterer{contract: contract}}, nil
+}
+
+// NewIRelayerFeesCaller creates a new read-only instance of IRelayerFees, bound to a specific deployed contract.
+func NewIRelayerFeesCaller(address common.Address, caller bind.ContractCaller) (*IRelayerFeesCaller, error) {
+	contract, err := bindIRelayerFees(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IRelayerFeesCaller{contract: contract}, nil
+}
+
+// NewIRelayerFeesTransactor creates a new write-only instance of IRelayerFees, bound to a specific deployed contract.
+func NewIRelayerFeesTransactor(address common.Address, transactor bind.ContractTransactor) (*IRelayerFeesTransactor, error) {
+	contract, err := bindIRelayerFees(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IRelayerFeesTransactor{contract: contract}, nil
+}
+
+// NewIRelayerFeesFilterer creates a new log filterer instance of IRelayerFees, bound to a specific deployed contract.
+func NewIRelayerFeesFilterer(address common.Address, filterer bind.ContractFilterer) (*IRelayerFeesFilterer, error) {
+	contract, err := bindIRelayerFees(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &IRelayerFeesFilterer{contract: contract}, nil
+}
+
+// bindIRelayerFees binds a generic wrapper to an already deployed contract.
+func bindIRelayerFees(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(IRelayerFeesABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IRelayerFees *IRelayerFeesRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IRelayerFees.Contract.IRelayerFeesCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IRelayerFees *IRelayerFeesRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IRelayerFees.Contract.IRelayerFeesTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IRelayerFees *IRelayerFeesRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IRelayerFees.Contract.IRelayerFeesTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IRelayerFees *IRelayerFeesCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IRelayerFees.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IRelayerFees *IRelayerFeesTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IRelayerFees.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IRelayerFees *IRelayerFeesTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IRelayerFees.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetAvailableFees is a free data retrieval call binding the contract method 0x007a94c1.
+//
+// Solidity: function getAvailableFees(address relayer) view returns(uint256)
+func (_IRelayerFees *IRelayerFeesCaller) GetAvailableFees(opts *bind.CallOpts, relayer common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _IRelayerFees.contract.Call(opts, &out, "getAvailableFees", relayer)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetAvailableFees is a free data retrieval call binding the contract method 0x007a94c1.
+//
+// Solidity: function getAvailableFees(address relayer) view returns(uint256)
+func (_IRelayerFees *IRelayerFeesSession) GetAvailableFees(relayer common.Address) (*big.Int, error) {
+	return _IRelayerFees.Contract.GetAvailableFees(&_IRelayerFees.CallOpts, relayer)
+}
+
+// GetAvailableFees is a free data retrieval call binding the contract method 0x007a94c1.
+//
+// Solidity: function getAvailableFees(address relayer) view returns(uint256)
+func (_IRelayerFees *IRelayerFeesCallerSession) GetAvailableFees(relayer common.Address) (*big.Int, error) {
+	return _IRelayerFees.Contract.GetAvailableFees(&_IRelayerFees.CallOpts, relayer)
+}
+
+// ClaimFee is a paid mutator transaction binding the contract method 0x99d32fc4.
+//
+// Solidity: function claimFee() returns()
+func (_IRelayerFees *IRelayerFeesTransactor) ClaimFee(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IRelayerFees.contract.Transact(opts, "claimFee")
+}
+
+// ClaimFee is a paid mutator transaction binding the contract method 0x99d32fc4.
+//
+// Solidity: function claimFee() returns()
+func (_IRelayerFees *IRelayerFeesSession) ClaimFee() (*types.Transaction, error) {
+	return _IRelayerFees.Contract.ClaimFee(&_IRelayerFees.TransactOpts)
+}
+
+// ClaimFee is a paid mutator transaction binding the contract method 0x99d32fc4.
+//
+// Solidity: function claimFee() returns()
+func (_IRelayerFees *IRelayerFeesTransactorSession) ClaimFee() (*types.Transaction, error) {
+	return _IRelayerFees.Contract.ClaimFee(&_IRelayerFees.TransactOpts)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The blockstore package selects between the file backed blockstore and an in-memory one.
+
+Passing MemoryPath as the blockstore path keeps the latest block in memory only, which is useful for tests and
+relayers that always start fresh.
+*/
+package blockstore
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ChainSafe/chainbridge-utils/blockstore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// MemoryPath selects the in-memory blockstore instead of a path on disk
+const MemoryPath = ":memory:"
+
+const latestBlockKey = "latest"
+
+// Blockstore records and loads the latest processed block
+type Blockstore interface {
+	blockstore.Blockstorer
+	TryLoadLatestBlock() (*big.Int, error)
+}
+
+var _ Blockstore = &FileBlockstore{}
+var _ Blockstore = &MemBlockstore{}
+
+// NewBlockstore returns an in-memory blockstore if path is MemoryPath, otherwise a file backed blockstore at path
+func NewBlockstore(path string, chain msg.ChainId, relayer string) (Blockstore, error) {
+	if path == MemoryPath {
+		return NewMemBlockstore(), nil
+	}
+	return NewFileBlockstore(path, chain, relayer)
+}
+
+// FileBlockstore is the file backed blockstore of a chain and relayer, which can be checkpointed
+type FileBlockstore struct {
+	*blockstore.Blockstore
+	path    string
+	chain   msg.ChainId
+	relayer string
+}
+
+// NewFileBlockstore returns the blockstore stored in the directory at path. An empty path is the default directory.
+func NewFileBlockstore(path string, chain msg.ChainId, relayer string) (*FileBlockstore, error) {
+	path, err := dirPath(path)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := blockstore.NewBlockstore(path, chain, relayer)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBlockstore{Blockstore: bs, path: path, chain: chain, relayer: relayer}, nil
+}
+
+// MemBlockstore implements Blockstore without persisting anything to disk
+type MemBlockstore struct {
+	blocks      sync.Map
+	checkpoints sync.Map
+}
+
+func NewMemBlockstore() *MemBlockstore {
+	return &MemBlockstore{}
+}
+
+// StoreBlock records the block number in memory
+func (b *MemBlockstore) StoreBlock(block *big.Int) error {
+	b.blocks.Store(latestBlockKey, new(big.Int).Set(block))
+	return nil
+}
+
+// TryLoadLatestBlock returns the last stored block, or 0 if none has been stored
+func (b *MemBlockstore) TryLoadLatestBlock() (*big.Int, error) {
+	block, ok := b.blocks.Load(latestBlockKey)
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).Set(block.(*big.Int)), nil
+}
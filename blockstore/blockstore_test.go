@@ -0,0 +1,133 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func testStoreAndLoad(t *testing.T, bs Blockstore) {
+	latest, err := bs.TryLoadLatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Cmp(big.NewInt(0)) != 0 {
+		t.Fatalf("expected empty blockstore to return 0, got: %s", latest)
+	}
+
+	block := big.NewInt(999)
+	err = bs.StoreBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Later changes to the stored value must not be visible
+	block.SetInt64(1)
+
+	latest, err = bs.TryLoadLatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Cmp(big.NewInt(999)) != 0 {
+		t.Fatalf("expected 999, got: %s", latest)
+	}
+}
+
+func TestMemBlockstore(t *testing.T) {
+	bs, err := NewBlockstore(MemoryPath, msg.ChainId(1), "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bs.(*MemBlockstore); !ok {
+		t.Fatalf("expected in-memory blockstore, got: %T", bs)
+	}
+	testStoreAndLoad(t, bs)
+}
+
+func TestFileBlockstore(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "blockstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bs, err := NewBlockstore(dir, msg.ChainId(1), "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bs.(*MemBlockstore); ok {
+		t.Fatal("expected file blockstore")
+	}
+	testStoreAndLoad(t, bs)
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "blockstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := NewBlockstore(dir, msg.ChainId(1), "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bs := range []Blockstore{file, NewMemBlockstore()} {
+		err = Checkpoint(bs, "pre-upgrade")
+		if _, ok := bs.(*FileBlockstore); ok && err == nil {
+			t.Fatal("expected error checkpointing a blockstore without a stored block")
+		}
+
+		err = bs.StoreBlock(big.NewInt(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Checkpoint(bs, "pre-upgrade")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bs.StoreBlock(big.NewInt(150))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = Restore(bs, "pre-upgrade")
+		if err != nil {
+			t.Fatal(err)
+		}
+		latest, err := bs.TryLoadLatestBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest.Cmp(big.NewInt(100)) != 0 {
+			t.Fatalf("%T: expected restored block 100, got: %s", bs, latest)
+		}
+
+		err = Restore(bs, "missing")
+		if !errors.Is(err, ErrCheckpointNotFound) {
+			t.Fatalf("%T: expected ErrCheckpointNotFound, got: %v", bs, err)
+		}
+		err = Checkpoint(bs, "../escape")
+		if err == nil {
+			t.Fatalf("%T: expected error for a label with a path separator", bs)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "1_relayer_pre-upgrade.bak")); err != nil {
+		t.Fatalf("expected checkpoint file: %v", err)
+	}
+	checkpoints, err := Checkpoints(dir, "pre-upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].Chain != 1 || checkpoints[0].Relayer != "relayer" || checkpoints[0].Block.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected checkpoints: %+v", checkpoints)
+	}
+}
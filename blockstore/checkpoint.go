@@ -0,0 +1,186 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+const checkpointFileExt = ".bak"
+
+var ErrCheckpointNotFound = errors.New("blockstore checkpoint not found")
+
+// Labels are part of checkpoint file names, so they are limited to characters that are safe in a file name
+var labelRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// checkpointer is implemented by the blockstores that support checkpoints
+type checkpointer interface {
+	checkpoint(label string) error
+	restore(label string) error
+}
+
+// Checkpoint saves the latest block of bs under label, replacing an earlier checkpoint with the same label. File
+// backed blockstores are copied to <path>/<chainId>_<relayer>_<label>.bak.
+func Checkpoint(bs Blockstore, label string) error {
+	c, err := checkpointerOf(bs, label)
+	if err != nil {
+		return err
+	}
+	return c.checkpoint(label)
+}
+
+// Restore replaces the latest block of bs with the one saved by Checkpoint under label. The checkpoint is kept, so
+// it can be restored again.
+func Restore(bs Blockstore, label string) error {
+	c, err := checkpointerOf(bs, label)
+	if err != nil {
+		return err
+	}
+	return c.restore(label)
+}
+
+func checkpointerOf(bs Blockstore, label string) (checkpointer, error) {
+	if !labelRegexp.MatchString(label) {
+		return nil, fmt.Errorf("invalid checkpoint label %q, only letters, digits, '.', '_' and '-' are allowed", label)
+	}
+	c, ok := bs.(checkpointer)
+	if !ok {
+		return nil, fmt.Errorf("blockstore %T does not support checkpoints", bs)
+	}
+	return c, nil
+}
+
+// blockFile returns the file the latest block is stored in, named <relayer>-<chain>.block
+func (b *FileBlockstore) blockFile() string {
+	return filepath.Join(b.path, fmt.Sprintf("%s-%d%s", b.relayer, b.chain, blockFileExt))
+}
+
+// CheckpointFile returns the file the checkpoint with label is stored in
+func (b *FileBlockstore) CheckpointFile(label string) string {
+	return filepath.Join(b.path, fmt.Sprintf("%d_%s_%s%s", b.chain, b.relayer, label, checkpointFileExt))
+}
+
+func (b *FileBlockstore) checkpoint(label string) error {
+	data, err := ioutil.ReadFile(b.blockFile())
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no block stored for chain %d and relayer %s", b.chain, b.relayer)
+	} else if err != nil {
+		return err
+	}
+	return writeFileAtomic(b.CheckpointFile(label), data)
+}
+
+func (b *FileBlockstore) restore(label string) error {
+	data, err := ioutil.ReadFile(b.CheckpointFile(label))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrCheckpointNotFound, b.CheckpointFile(label))
+	} else if err != nil {
+		return err
+	}
+	block, ok := new(big.Int).SetString(string(data), 10)
+	if !ok || block.Sign() < 0 {
+		return fmt.Errorf("invalid block number in %s", b.CheckpointFile(label))
+	}
+	return writeFileAtomic(b.blockFile(), data)
+}
+
+func (b *MemBlockstore) checkpoint(label string) error {
+	block, err := b.TryLoadLatestBlock()
+	if err != nil {
+		return err
+	}
+	b.checkpoints.Store(label, block)
+	return nil
+}
+
+func (b *MemBlockstore) restore(label string) error {
+	block, ok := b.checkpoints.Load(label)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCheckpointNotFound, label)
+	}
+	return b.StoreBlock(block.(*big.Int))
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path and renames it to path, so readers
+// never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmp.Name(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Checkpoints returns an entry for each checkpoint with label in the file backed blockstore directory at path,
+// sorted by chain and relayer. The timestamp of each entry is when the checkpoint was made. An empty path is the
+// default directory.
+func Checkpoints(path, label string) ([]Entry, error) {
+	path, err := dirPath(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := "_" + label + checkpointFileExt
+	var entries []Entry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		// Files are named <chain>_<relayer>_<label>.bak
+		parts := strings.SplitN(strings.TrimSuffix(name, suffix), "_", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		chain, err := strconv.ParseUint(parts[0], 10, 8)
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		block, ok := new(big.Int).SetString(string(data), 10)
+		if !ok || block.Sign() < 0 {
+			return nil, fmt.Errorf("invalid block number in %s", name)
+		}
+		entries = append(entries, Entry{Chain: msg.ChainId(chain), Relayer: parts[1], Block: block, Timestamp: file.ModTime()})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
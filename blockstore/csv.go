@@ -0,0 +1,190 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/blockstore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+const blockFileExt = ".block"
+
+var csvHeader = []string{"chainId", "relayerAddress", "blockNumber", "timestamp"}
+
+// Entry is the latest block stored for a chain and relayer pair
+type Entry struct {
+	Chain     msg.ChainId
+	Relayer   string
+	Block     *big.Int
+	Timestamp time.Time // When the block was stored
+}
+
+// ReadDir returns an entry for each file in the file backed blockstore directory at path, sorted by chain and
+// relayer. The timestamp of each entry is the modification time of its file. An empty path is the default directory.
+func ReadDir(path string) ([]Entry, error) {
+	path, err := dirPath(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, blockFileExt) {
+			continue
+		}
+		// Files are named <relayer>-<chain>.block
+		name = strings.TrimSuffix(name, blockFileExt)
+		sep := strings.LastIndex(name, "-")
+		if sep < 0 {
+			continue
+		}
+		chain, err := strconv.ParseUint(name[sep+1:], 10, 8)
+		if err != nil {
+			continue
+		}
+
+		bs, err := blockstore.NewBlockstore(path, msg.ChainId(chain), name[:sep])
+		if err != nil {
+			return nil, err
+		}
+		block, err := bs.TryLoadLatestBlock()
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("invalid block number in %s", file.Name())
+		}
+		entries = append(entries, Entry{Chain: msg.ChainId(chain), Relayer: name[:sep], Block: block, Timestamp: file.ModTime()})
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// sortEntries sorts entries by chain and relayer
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Chain != entries[j].Chain {
+			return entries[i].Chain < entries[j].Chain
+		}
+		return entries[i].Relayer < entries[j].Relayer
+	})
+}
+
+// WriteDir stores each entry in the file backed blockstore directory at path, setting the modification time
+// of its file to the entry's timestamp. An empty path is the default directory.
+func WriteDir(path string, entries []Entry) error {
+	path, err := dirPath(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		bs, err := blockstore.NewBlockstore(path, e.Chain, e.Relayer)
+		if err != nil {
+			return err
+		}
+		err = bs.StoreBlock(e.Block)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(path, fmt.Sprintf("%s-%d%s", e.Relayer, e.Chain, blockFileExt))
+		err = os.Chtimes(file, e.Timestamp, e.Timestamp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportCSV writes a header and a chainId,relayerAddress,blockNumber,timestamp row for each entry to w.
+// Timestamps are in RFC3339 format.
+func ExportCSV(entries []Entry, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err = cw.Write([]string{
+			strconv.Itoa(int(e.Chain)),
+			e.Relayer,
+			e.Block.String(),
+			e.Timestamp.UTC().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportCSV reads the entries written by ExportCSV
+func ImportCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	if strings.Join(header, ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("unexpected csv header: %s", strings.Join(header, ","))
+	}
+
+	var entries []Entry
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chain, err := strconv.ParseUint(record[0], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid chainId: %w", row, err)
+		}
+		if record[1] == "" {
+			return nil, fmt.Errorf("row %d: empty relayerAddress", row)
+		}
+		block, ok := new(big.Int).SetString(record[2], 10)
+		if !ok || block.Sign() < 0 {
+			return nil, fmt.Errorf("row %d: invalid blockNumber: %s", row, record[2])
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, record[3])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid timestamp: %w", row, err)
+		}
+		entries = append(entries, Entry{Chain: msg.ChainId(chain), Relayer: record[1], Block: block, Timestamp: timestamp})
+	}
+}
+
+// dirPath returns the default blockstore directory if path is empty
+func dirPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, blockstore.PathPostfix), nil
+}
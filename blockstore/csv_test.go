@@ -0,0 +1,121 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// testEntries returns n entries with distinct chain and relayer pairs, in the order ReadDir sorts them
+func testEntries(n int) []Entry {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := make([]Entry, n)
+	for i := range entries {
+		chain := i * 10 / n
+		entries[i] = Entry{
+			Chain:     msg.ChainId(chain),
+			Relayer:   fmt.Sprintf("0x%040x", i),
+			Block:     big.NewInt(int64(i) * 17),
+			Timestamp: start.Add(time.Duration(i)*time.Minute + time.Duration(i)*time.Microsecond),
+		}
+	}
+	return entries
+}
+
+func assertEntries(t *testing.T, expected, actual []Entry) {
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d entries, got: %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if a.Chain != e.Chain || a.Relayer != e.Relayer || a.Block.Cmp(e.Block) != 0 || !a.Timestamp.Equal(e.Timestamp) {
+			t.Fatalf("entry %d mismatch. Expected: %+v Got: %+v", i, e, a)
+		}
+	}
+}
+
+func TestCSVRoundtrip(t *testing.T) {
+	entries := testEntries(1000)
+
+	var buf bytes.Buffer
+	err := ExportCSV(entries, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "chainId,relayerAddress,blockNumber,timestamp\n") {
+		t.Fatalf("missing csv header: %q", buf.String()[:60])
+	}
+
+	imported, err := ImportCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, entries, imported)
+}
+
+func TestDirRoundtrip(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "blockstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := testEntries(1000)
+	err = WriteDir(dir, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Other files are ignored
+	err = ioutil.WriteFile(dir+"/notes.txt", []byte("123"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, entries, read)
+
+	// The files are readable by the blockstore the relayer uses
+	bs, err := NewBlockstore(dir, entries[500].Chain, entries[500].Relayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := bs.TryLoadLatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Cmp(entries[500].Block) != 0 {
+		t.Fatalf("expected %s, got: %s", entries[500].Block, latest)
+	}
+}
+
+func TestImportCSV_Invalid(t *testing.T) {
+	header := "chainId,relayerAddress,blockNumber,timestamp\n"
+	for _, input := range []string{
+		"",
+		"chain,relayer,block,time\n",
+		header + "256,0x01,1,2020-06-01T12:00:00Z\n",
+		header + "1,,1,2020-06-01T12:00:00Z\n",
+		header + "1,0x01,-1,2020-06-01T12:00:00Z\n",
+		header + "1,0x01,1,yesterday\n",
+		header + "1,0x01,1\n",
+	} {
+		_, err := ImportCSV(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for input: %q", input)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// FileBackend is the backend of file backed blockstore directories, the only one OpenStore supports
+const FileBackend = "file"
+
+// Store holds the entries of every chain and relayer kept by a blockstore backend
+type Store interface {
+	// Iterator returns every entry, sorted by chain and relayer
+	Iterator() ([]Entry, error)
+	// Put stores the entries, replacing existing ones for the same chain and relayer
+	Put(entries []Entry) error
+}
+
+var _ Store = &FileStore{}
+var _ Store = &MemStore{}
+
+// OpenStore returns the Store of backend at path. For the file backend the path is the blockstore directory,
+// where an empty path is the default directory.
+func OpenStore(backend, path string) (Store, error) {
+	switch backend {
+	case FileBackend:
+		return NewFileStore(path), nil
+	default:
+		return nil, fmt.Errorf("unsupported blockstore backend: %s", backend)
+	}
+}
+
+// Migrate copies every entry of from to to, for moving relayers to a different blockstore backend
+func Migrate(from, to Store) error {
+	entries, err := from.Iterator()
+	if err != nil {
+		return fmt.Errorf("failed to read blockstore: %w", err)
+	}
+	err = to.Put(entries)
+	if err != nil {
+		return fmt.Errorf("failed to write blockstore: %w", err)
+	}
+	return nil
+}
+
+// FileStore is the Store of a file backed blockstore directory
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns the Store of the blockstore directory at path. An empty path is the default directory.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Iterator() ([]Entry, error) {
+	return ReadDir(s.path)
+}
+
+func (s *FileStore) Put(entries []Entry) error {
+	return WriteDir(s.path, entries)
+}
+
+// memKey identifies the entry of a chain and relayer in a MemStore
+type memKey struct {
+	chain   msg.ChainId
+	relayer string
+}
+
+// MemStore is a Store that keeps its entries in memory only
+type MemStore struct {
+	entries map[memKey]Entry
+	lock    sync.RWMutex
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{entries: make(map[memKey]Entry)}
+}
+
+func (s *MemStore) Iterator() ([]Entry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		e.Block = new(big.Int).Set(e.Block)
+		entries = append(entries, e)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (s *MemStore) Put(entries []Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, e := range entries {
+		e.Block = new(big.Int).Set(e.Block)
+		s.entries[memKey{chain: e.Chain, relayer: e.Relayer}] = e
+	}
+	return nil
+}
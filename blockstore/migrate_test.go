@@ -0,0 +1,58 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "blockstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := testEntries(1000)
+	from := NewFileStore(dir)
+	err = from.Put(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := NewMemStore()
+	err = Migrate(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := to.Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, entries, migrated)
+
+	// Migrating again replaces the existing entries instead of duplicating them
+	err = Migrate(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err = to.Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, entries, migrated)
+}
+
+func TestOpenStore(t *testing.T) {
+	_, err := OpenStore(FileBackend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = OpenStore("postgres", "")
+	if err == nil {
+		t.Fatal("expected error opening an unsupported backend")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The abi package encodes and decodes the data the bridge passes to its handlers, for deposits and the proposals that
+execute them.
+
+The handlers read the data by offset rather than ABI decoding it, so it is packed as 32 byte words followed by the
+raw bytes they give the length of:
+
+	erc20     amount | len(recipient) | recipient
+	erc721    tokenId | len(recipient) | recipient | len(metadata) | metadata, metadata is only in proposals
+	generic   len(metadata) | metadata
+*/
+package abi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// wordLength is the length of each number in handler data
+const wordLength = 32
+
+var ErrInvalidData = errors.New("invalid handler data")
+
+// EncodeERC20DepositData returns the data of an erc20 deposit or proposal of amount to recipient
+func EncodeERC20DepositData(recipient []byte, amount *big.Int) []byte {
+	data := word(amount)
+	return append(data, encodeBytes(recipient)...)
+}
+
+// DecodeERC20DepositData returns the recipient and amount of erc20 deposit or proposal data
+func DecodeERC20DepositData(data []byte) (recipient []byte, amount *big.Int, err error) {
+	amount, data, err = readWord(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: amount: %s", ErrInvalidData, err)
+	}
+	recipient, data, err = readBytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: recipient: %s", ErrInvalidData, err)
+	}
+	if len(data) != 0 {
+		return nil, nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidData, len(data))
+	}
+	return recipient, amount, nil
+}
+
+// EncodeERC721DepositData returns the data of an erc721 deposit of tokenId to recipient
+func EncodeERC721DepositData(tokenId *big.Int, recipient []byte) []byte {
+	data := word(tokenId)
+	return append(data, encodeBytes(recipient)...)
+}
+
+// EncodeERC721ProposalData returns the data of an erc721 proposal of tokenId to recipient, with the token's metadata
+func EncodeERC721ProposalData(tokenId *big.Int, recipient []byte, metadata []byte) []byte {
+	data := EncodeERC721DepositData(tokenId, recipient)
+	return append(data, encodeBytes(metadata)...)
+}
+
+// EncodeGenericDepositData returns the data of a generic deposit or proposal of metadata
+func EncodeGenericDepositData(metadata []byte) []byte {
+	return encodeBytes(metadata)
+}
+
+// DecodeGenericDepositData returns the metadata of generic deposit or proposal data
+func DecodeGenericDepositData(data []byte) ([]byte, error) {
+	metadata, data, err := readBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata: %s", ErrInvalidData, err)
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidData, len(data))
+	}
+	return metadata, nil
+}
+
+func word(n *big.Int) []byte {
+	return math.PaddedBigBytes(n, wordLength)
+}
+
+func encodeBytes(b []byte) []byte {
+	return append(word(big.NewInt(int64(len(b)))), b...)
+}
+
+// readWord returns the number in the first word of data and the data after it
+func readWord(data []byte) (*big.Int, []byte, error) {
+	if len(data) < wordLength {
+		return nil, nil, fmt.Errorf("%d bytes is shorter than a word", len(data))
+	}
+	return new(big.Int).SetBytes(data[:wordLength]), data[wordLength:], nil
+}
+
+// readBytes returns the bytes at the start of data, after their length, and the data after them
+func readBytes(data []byte) ([]byte, []byte, error) {
+	length, data, err := readWord(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !length.IsUint64() || length.Uint64() > uint64(len(data)) {
+		return nil, nil, fmt.Errorf("length %s is longer than the %d bytes left", length, len(data))
+	}
+	n := length.Uint64()
+	return data[:n], data[n:], nil
+}
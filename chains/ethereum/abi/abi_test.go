@@ -0,0 +1,97 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package abi
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var recipient = common.HexToAddress("0x8e0a907331554AF72563Bd8D43051C2E64Be5d35").Bytes()
+
+func TestEncodeERC20DepositData(t *testing.T) {
+	data := EncodeERC20DepositData(recipient, big.NewInt(100))
+	expected := "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000064" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"8e0a907331554af72563bd8d43051c2e64be5d35"
+	if hexutil.Encode(data) != expected {
+		t.Fatalf("expected %s, got: %s", expected, hexutil.Encode(data))
+	}
+
+	decodedRecipient, amount, err := DecodeERC20DepositData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decodedRecipient, recipient) || amount.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("expected 100 to %x, got: %s to %x", recipient, amount, decodedRecipient)
+	}
+}
+
+func TestDecodeERC20DepositData_Invalid(t *testing.T) {
+	data := EncodeERC20DepositData(recipient, big.NewInt(100))
+	for name, invalid := range map[string][]byte{
+		"empty":             {},
+		"short amount":      data[:31],
+		"missing length":    data[:32],
+		"short recipient":   data[:len(data)-1],
+		"trailing bytes":    append(append([]byte{}, data...), 0),
+		"overflowed length": append(append([]byte{}, data[:32]...), bytes.Repeat([]byte{0xff}, 32)...),
+	} {
+		_, _, err := DecodeERC20DepositData(invalid)
+		if !errors.Is(err, ErrInvalidData) {
+			t.Fatalf("%s: expected ErrInvalidData, got: %v", name, err)
+		}
+	}
+}
+
+func TestEncodeERC721Data(t *testing.T) {
+	deposit := EncodeERC721DepositData(big.NewInt(7), recipient)
+	expected := "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000007" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"8e0a907331554af72563bd8d43051c2e64be5d35"
+	if hexutil.Encode(deposit) != expected {
+		t.Fatalf("expected %s, got: %s", expected, hexutil.Encode(deposit))
+	}
+
+	proposal := EncodeERC721ProposalData(big.NewInt(7), recipient, []byte("uri"))
+	expected += "0000000000000000000000000000000000000000000000000000000000000003" + "757269"
+	if hexutil.Encode(proposal) != expected {
+		t.Fatalf("expected %s, got: %s", expected, hexutil.Encode(proposal))
+	}
+}
+
+func TestEncodeGenericDepositData(t *testing.T) {
+	data := EncodeGenericDepositData([]byte("metadata"))
+	expected := "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000008" +
+		"6d65746164617461"
+	if hexutil.Encode(data) != expected {
+		t.Fatalf("expected %s, got: %s", expected, hexutil.Encode(data))
+	}
+
+	metadata, err := DecodeGenericDepositData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(metadata) != "metadata" {
+		t.Fatalf("expected metadata, got: %q", metadata)
+	}
+
+	// Empty metadata is a zero length
+	data = EncodeGenericDepositData(nil)
+	if hexutil.Encode(data) != "0x"+"0000000000000000000000000000000000000000000000000000000000000000" {
+		t.Fatalf("unexpected empty metadata encoding: %s", hexutil.Encode(data))
+	}
+	_, err = DecodeGenericDepositData(data[:31])
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData, got: %v", err)
+	}
+}
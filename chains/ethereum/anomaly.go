@@ -0,0 +1,167 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Rules of the DepositAnomalyDetector, used as the rule label of chainbridge_anomalous_deposits_total
+const (
+	RuleAmountPerBlock    = "maxAmountPerBlock"
+	RuleDepositsPerMinute = "maxDepositsPerAddressPerMinute"
+)
+
+// depositRateWindow is the period maxDepositsPerAddressPerMinute counts deposits over
+const depositRateWindow = time.Minute
+
+var anomalousDeposits = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_anomalous_deposits_total",
+	Help: "Number of deposits that broke an anomaly rule, which are still relayed",
+}, []string{"chain", "rule"})
+
+func init() {
+	prometheus.MustRegister(anomalousDeposits)
+}
+
+// DepositAnomalyDetector flags deposits that may be an attack on the bridge, such as a large amount deposited in one
+// block or many deposits in quick succession by one address. Deposits must be checked in block order.
+type DepositAnomalyDetector struct {
+	maxAmountPerBlock              *big.Int // nil to disable
+	maxDepositsPerAddressPerMinute int      // 0 to disable
+
+	block        uint64                            // Block the amounts were deposited in
+	blockAmounts map[msg.ResourceId]*big.Int       // Fungible amount of each resource ID deposited in block
+	deposits     map[ethcommon.Address][]time.Time // Times of each address's deposits within the last minute
+	lock         sync.Mutex
+}
+
+func NewDepositAnomalyDetector(maxAmountPerBlock *big.Int, maxDepositsPerAddressPerMinute int) *DepositAnomalyDetector {
+	return &DepositAnomalyDetector{
+		maxAmountPerBlock:              maxAmountPerBlock,
+		maxDepositsPerAddressPerMinute: maxDepositsPerAddressPerMinute,
+		blockAmounts:                   make(map[msg.ResourceId]*big.Int),
+		deposits:                       make(map[ethcommon.Address][]time.Time),
+	}
+}
+
+// tracksDepositors returns whether Check needs the depositor and time of deposits
+func (d *DepositAnomalyDetector) tracksDepositors() bool {
+	return d.maxDepositsPerAddressPerMinute > 0
+}
+
+// Check records deposit m, made by depositor in block at time, and returns the rules it breaks. A deposit breaks
+// maxAmountPerBlock once the fungible amount of its resource ID deposited in the block exceeds the limit, and
+// maxDepositsPerAddressPerMinute once its depositor has made more than the limit within the last minute. Depositors
+// are not tracked if depositor is the zero address.
+func (d *DepositAnomalyDetector) Check(m msg.Message, depositor ethcommon.Address, block uint64, at time.Time) []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var broken []string
+	if block != d.block {
+		d.block = block
+		d.blockAmounts = make(map[msg.ResourceId]*big.Int)
+	}
+	if d.maxAmountPerBlock != nil && (m.Type == msg.FungibleTransfer || m.Type == PermitFungibleTransfer) && len(m.Payload) > 0 {
+		if amount, ok := m.Payload[0].([]byte); ok {
+			total, ok := d.blockAmounts[m.ResourceId]
+			if !ok {
+				total = new(big.Int)
+				d.blockAmounts[m.ResourceId] = total
+			}
+			total.Add(total, new(big.Int).SetBytes(amount))
+			if total.Cmp(d.maxAmountPerBlock) > 0 {
+				broken = append(broken, RuleAmountPerBlock)
+			}
+		}
+	}
+
+	if d.tracksDepositors() && depositor != (ethcommon.Address{}) {
+		d.expireDeposits(at)
+		d.deposits[depositor] = append(d.deposits[depositor], at)
+		if len(d.deposits[depositor]) > d.maxDepositsPerAddressPerMinute {
+			broken = append(broken, RuleDepositsPerMinute)
+		}
+	}
+	return broken
+}
+
+// expireDeposits forgets deposits made a minute or more before now
+func (d *DepositAnomalyDetector) expireDeposits(now time.Time) {
+	for addr, times := range d.deposits {
+		i := 0
+		for i < len(times) && !times[i].After(now.Add(-depositRateWindow)) {
+			i++
+		}
+		if i == len(times) {
+			delete(d.deposits, addr)
+		} else {
+			d.deposits[addr] = times[i:]
+		}
+	}
+}
+
+// checkAnomalies warns about and counts deposits that break an anomaly rule. The deposits are still routed, as the
+// rules only indicate an attack and the relayer must not censor deposits.
+func (l *listener) checkAnomalies(m msg.Message, log ethtypes.Log) {
+	if l.anomalies == nil {
+		return
+	}
+	var depositor ethcommon.Address
+	var at time.Time
+	if l.anomalies.tracksDepositors() {
+		var err error
+		depositor, at, err = l.depositorAndTime(m, log.BlockNumber)
+		if err != nil {
+			l.log.Warn("Unable to get the depositor, the deposit rate is not checked", "dest", m.Destination, "nonce", m.DepositNonce, "err", err)
+			depositor = ethcommon.Address{}
+		}
+	}
+	for _, rule := range l.anomalies.Check(m, depositor, log.BlockNumber, at) {
+		anomalousDeposits.WithLabelValues(l.cfg.name, rule).Inc()
+		l.log.Warn("Anomalous deposit", "rule", rule, "dest", m.Destination, "nonce", m.DepositNonce, "rId", m.ResourceId.Hex(), "depositor", depositor, "block", log.BlockNumber, "tx", log.TxHash)
+	}
+}
+
+// depositorAndTime returns the address that made deposit m, from its handler's deposit record, and the time of the
+// block it was made in
+func (l *listener) depositorAndTime(m msg.Message, block uint64) (ethcommon.Address, time.Time, error) {
+	var depositor ethcommon.Address
+	conn, contracts := l.connection(), l.contracts()
+	switch m.Type {
+	case msg.FungibleTransfer, PermitFungibleTransfer:
+		record, err := contracts.erc20Handler.GetDepositRecord(conn.CallOpts(), uint64(m.DepositNonce), uint8(m.Destination))
+		if err != nil {
+			return depositor, time.Time{}, err
+		}
+		depositor = record.Depositer
+	case msg.NonFungibleTransfer:
+		record, err := contracts.erc721Handler.GetDepositRecord(conn.CallOpts(), uint64(m.DepositNonce), uint8(m.Destination))
+		if err != nil {
+			return depositor, time.Time{}, err
+		}
+		depositor = record.Depositer
+	case msg.GenericTransfer:
+		record, err := contracts.genericHandler.GetDepositRecord(conn.CallOpts(), uint64(m.DepositNonce), uint8(m.Destination))
+		if err != nil {
+			return depositor, time.Time{}, err
+		}
+		depositor = record.Depositer
+	}
+
+	header, err := conn.Backend().HeaderByNumber(context.Background(), new(big.Int).SetUint64(block))
+	if err != nil {
+		return depositor, time.Time{}, err
+	}
+	return depositor, time.Unix(int64(header.Time), 0), nil
+}
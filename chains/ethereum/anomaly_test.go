@@ -0,0 +1,105 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func fungibleDeposit(nonce msg.Nonce, amount int64, rId msg.ResourceId) msg.Message {
+	return msg.NewFungibleTransfer(1, 2, nonce, big.NewInt(amount), rId, []byte{0xab})
+}
+
+func TestDepositAnomalyDetector_AmountPerBlock(t *testing.T) {
+	d := NewDepositAnomalyDetector(big.NewInt(100), 0)
+	rIdA, rIdB := msg.ResourceIdFromSlice([]byte{1}), msg.ResourceIdFromSlice([]byte{2})
+	for i, test := range []struct {
+		amount int64
+		rId    msg.ResourceId
+		block  uint64
+		broken []string
+	}{
+		{amount: 60, rId: rIdA, block: 10},
+		// A total of exactly the limit is allowed
+		{amount: 40, rId: rIdA, block: 10},
+		{amount: 1, rId: rIdA, block: 10, broken: []string{RuleAmountPerBlock}},
+		// Resource IDs are totalled separately
+		{amount: 100, rId: rIdB, block: 10},
+		// Totals reset in the next block
+		{amount: 100, rId: rIdA, block: 11},
+		{amount: 101, rId: rIdB, block: 12, broken: []string{RuleAmountPerBlock}},
+	} {
+		broken := d.Check(fungibleDeposit(msg.Nonce(i), test.amount, test.rId), ethcommon.Address{}, test.block, time.Time{})
+		if !reflect.DeepEqual(broken, test.broken) {
+			t.Fatalf("deposit %d: expected rules %v to be broken, got: %v", i, test.broken, broken)
+		}
+	}
+
+	// Only fungible amounts are totalled
+	nft := msg.NewNonFungibleTransfer(1, 2, 10, rIdA, big.NewInt(1000), []byte{0xab}, nil)
+	if broken := d.Check(nft, ethcommon.Address{}, 12, time.Time{}); len(broken) != 0 {
+		t.Fatalf("expected a nonfungible deposit to break no rules, got: %v", broken)
+	}
+}
+
+func TestDepositAnomalyDetector_DepositsPerMinute(t *testing.T) {
+	d := NewDepositAnomalyDetector(nil, 2)
+	alice, bob := AliceKp.CommonAddress(), BobKp.CommonAddress()
+	start := time.Unix(1700000000, 0)
+	for i, test := range []struct {
+		depositor ethcommon.Address
+		at        time.Duration
+		broken    []string
+	}{
+		{depositor: alice, at: 0},
+		// Exactly the limit is allowed
+		{depositor: alice, at: 10 * time.Second},
+		{depositor: alice, at: 59 * time.Second, broken: []string{RuleDepositsPerMinute}},
+		// Addresses are counted separately
+		{depositor: bob, at: 59 * time.Second},
+		// The first deposit is a minute old, but the two after it are still counted
+		{depositor: alice, at: time.Minute, broken: []string{RuleDepositsPerMinute}},
+		// The deposit at 10s has expired, but those at 59s and 60s have not
+		{depositor: alice, at: 70 * time.Second, broken: []string{RuleDepositsPerMinute}},
+		{depositor: alice, at: 130 * time.Second},
+		// Deposits without a known depositor are not counted
+		{depositor: ethcommon.Address{}, at: 130 * time.Second},
+		{depositor: ethcommon.Address{}, at: 130 * time.Second},
+		{depositor: ethcommon.Address{}, at: 130 * time.Second},
+	} {
+		broken := d.Check(fungibleDeposit(msg.Nonce(i), 1, msg.ResourceId{}), test.depositor, uint64(i), start.Add(test.at))
+		if !reflect.DeepEqual(broken, test.broken) {
+			t.Fatalf("deposit %d: expected rules %v to be broken, got: %v", i, test.broken, broken)
+		}
+	}
+}
+
+func TestListener_CheckAnomalies(t *testing.T) {
+	cfg := createConfig("anomalies", big.NewInt(0), nil)
+	cfg.maxAmountPerBlock = big.NewInt(100)
+	l := NewListener(nil, cfg, newTestLogger(cfg.name), nil, make(chan int), make(chan error, 1), nil)
+
+	l.checkAnomalies(fungibleDeposit(1, 100, msg.ResourceId{}), ethtypes.Log{BlockNumber: 1})
+	if count := testutil.ToFloat64(anomalousDeposits.WithLabelValues("anomalies", RuleAmountPerBlock)); count != 0 {
+		t.Fatalf("expected no anomalous deposits, got: %v", count)
+	}
+	l.checkAnomalies(fungibleDeposit(2, 1, msg.ResourceId{}), ethtypes.Log{BlockNumber: 1})
+	if count := testutil.ToFloat64(anomalousDeposits.WithLabelValues("anomalies", RuleAmountPerBlock)); count != 1 {
+		t.Fatalf("expected 1 anomalous deposit, got: %v", count)
+	}
+
+	// Without rules no deposits are checked
+	l = NewListener(nil, createConfig("anomalies", big.NewInt(0), nil), newTestLogger(cfg.name), nil, make(chan int), make(chan error, 1), nil)
+	if l.anomalies != nil {
+		t.Fatal("expected no anomaly detector without rules")
+	}
+}
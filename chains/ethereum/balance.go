@@ -0,0 +1,74 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var insufficientBalanceHolds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_insufficient_balance_holds_total",
+	Help: "Number of proposal executions held because the relayer's balance could not pay for them",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(insufficientBalanceHolds)
+}
+
+// executionCost returns the estimated cost of executing m's proposal, its gas limit at the current gas price, plus
+// the minRelayerBalance the relayer must keep
+func (w *writer) executionCost(m msg.Message, data []byte) (*big.Int, error) {
+	gasLimit := w.estimateGasLimit(bridgeABI, w.cfg.bridgeContract, nil, "executeProposal", uint8(m.Source), uint64(m.DepositNonce), data, [32]byte(m.ResourceId))
+	gasPrice, err := w.conn.EffectiveGasPrice(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	if w.cfg.minRelayerBalance != nil {
+		cost.Add(cost, w.cfg.minRelayerBalance)
+	}
+	return cost, nil
+}
+
+// waitForBalance holds the execution of m until the relayer's pending balance covers its cost, checking the balance
+// every balanceCheckInterval. If the balance or cost cannot be queried the execution is not held, so a node error
+// does not stop proposals being executed. It returns false if the writer is stopped while holding.
+func (w *writer) waitForBalance(m msg.Message, data []byte) bool {
+	relayer := w.conn.CallOpts().From
+	held := false
+	for {
+		cost, err := w.executionCost(m, data)
+		if err != nil {
+			w.log.Warn("Unable to estimate execution cost, not checking balance", "src", m.Source, "nonce", m.DepositNonce, "err", err)
+			return true
+		}
+		balance, err := w.conn.GetPendingBalance(relayer)
+		if err != nil {
+			w.log.Warn("Unable to get relayer balance, not checking balance", "src", m.Source, "nonce", m.DepositNonce, "err", err)
+			return true
+		}
+		if balance.Cmp(cost) >= 0 {
+			if held {
+				w.log.Info("Relayer balance replenished, resuming execution", "src", m.Source, "nonce", m.DepositNonce, "balance", balance)
+			}
+			return true
+		}
+
+		if !held {
+			held = true
+			insufficientBalanceHolds.WithLabelValues(w.cfg.name).Inc()
+			w.log.Error("Relayer balance too low to execute proposal, holding execution", "relayer", relayer, "src", m.Source, "nonce", m.DepositNonce, "balance", balance, "required", cost)
+		}
+		select {
+		case <-w.stop:
+			return false
+		case <-time.After(w.cfg.balanceCheckInterval):
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWriter_HoldsExecutionUntilBalanceReplenished(t *testing.T) {
+	backend := newMockBackend()
+	backend.setBalance(big.NewInt(1))
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("balance", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 0
+	cfg.balanceCheckInterval = 10 * time.Millisecond
+	stop := make(chan int)
+	defer close(stop)
+	w := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.setContract(bridge)
+
+	m := msg.NewGenericTransfer(1, 0, 1, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	data := ConstructGenericProposalData([]byte("metadata"))
+	done := make(chan struct{})
+	go func() {
+		w.executeProposal(m, data, [32]byte{1})
+		close(done)
+	}()
+
+	// The balance cannot pay for the execution, so it is held
+	time.Sleep(100 * time.Millisecond)
+	if sent := backend.called("SendTransaction"); sent != 0 {
+		t.Fatalf("expected the execution to be held, got %d transactions", sent)
+	}
+	if backend.called("PendingBalanceAt") < 2 {
+		t.Fatal("expected the balance to be polled while the execution is held")
+	}
+	if holds := testutil.ToFloat64(insufficientBalanceHolds.WithLabelValues(cfg.name)); holds != 1 {
+		t.Fatalf("expected 1 hold, got: %v", holds)
+	}
+
+	// Once the balance is replenished the proposal is executed
+	backend.setBalance(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	select {
+	case <-done:
+	case <-time.After(TestTimeout):
+		t.Fatal("execution was not resumed")
+	}
+	if sent := backend.called("SendTransaction"); sent != 1 {
+		t.Fatalf("expected the execution to be submitted once, got: %d", sent)
+	}
+
+	// The execution's gas is reported by resource ID once it is mined, the series is removed so it is not counted by
+	// other tests
+	for i := 0; i < 100 && !resourceGas.DeleteLabelValues(cfg.name, m.ResourceId.Hex()); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWriter_ExecutionCostIncludesMinBalance(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("mincost", big.NewInt(0), nil)
+	cfg.minRelayerBalance = big.NewInt(1000)
+	w := NewWriter(conn, cfg, newTestLogger(cfg.name), nil, nil, nil)
+
+	cost, err := w.executionCost(msg.Message{Source: 1, DepositNonce: 1}, []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas := backend.gas + uint64(float64(backend.gas)*GasLimitBuffer)
+	expected := new(big.Int).Mul(new(big.Int).SetUint64(gas), backend.gasPrice)
+	expected.Add(expected, big.NewInt(1000))
+	if cost.Cmp(expected) != 0 {
+		t.Fatalf("expected cost %s, got: %s", expected, cost)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// circuitBreaker opens after threshold consecutive messages exhaust their transaction retries. While it is open the
+// writer does not submit transactions. Once cooldown has passed the next message is attempted, closing the breaker
+// if it succeeds and opening it again if it fails.
+type circuitBreaker struct {
+	threshold int // Failures that open the breaker, 0 to never open
+	cooldown  time.Duration
+	now       func() time.Time
+
+	lock     sync.Mutex
+	failures int       // Consecutive failures
+	openedAt time.Time // When the breaker opened, zero while it is closed
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// isOpen returns true if the breaker opened less than cooldown ago
+func (b *circuitBreaker) isOpen() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return !b.openedAt.IsZero() && b.now().Sub(b.openedAt) < b.cooldown
+}
+
+// success closes the breaker
+func (b *circuitBreaker) success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.failures = 0
+	b.openedAt = time.Time{}
+}
+
+// failure records a failed message, opening the breaker once threshold consecutive messages have failed
+func (b *circuitBreaker) failure() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// SetFallbackWriter sets the writer messages are passed to while the circuit breaker is open, or once their
+// transactions have exhausted TxRetryLimit, such as one that stores them to be replayed later. Without a fallback
+// writer these messages are not resolved, and exhausting the retries is a fatal error.
+func (w *writer) SetFallbackWriter(fallback chains.Writer) {
+	w.fallback = fallback
+}
+
+// CircuitBreakerOpen returns true while the writer is not submitting transactions
+func (w *writer) CircuitBreakerOpen() bool {
+	return w.breaker.isOpen()
+}
+
+// resolveWithFallback passes m to the fallback writer, returning false if there is none
+func (w *writer) resolveWithFallback(m msg.Message, reason string) bool {
+	if w.fallback == nil {
+		w.log.Error("Message not resolved, no fallback writer is set", "src", m.Source, "nonce", m.DepositNonce, "reason", reason)
+		return false
+	}
+	w.log.Warn("Passing message to the fallback writer", "src", m.Source, "nonce", m.DepositNonce, "reason", reason)
+	return w.fallback.ResolveMessage(m)
+}
+
+// retriesExhausted records a message whose transactions failed TxRetryLimit times, passing it to the fallback
+// writer if one is set. Otherwise the failure is fatal.
+func (w *writer) retriesExhausted(m msg.Message) {
+	w.breaker.failure()
+	if w.fallback == nil {
+		w.sysErr <- ErrFatalTx
+		return
+	}
+	w.resolveWithFallback(m, "transaction retries exhausted")
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newCircuitBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	b.failure()
+	if b.isOpen() {
+		t.Fatal("expected the breaker to be closed below the threshold")
+	}
+	b.success()
+	b.failure()
+	b.failure()
+	if b.isOpen() {
+		t.Fatal("expected a success to reset the failures")
+	}
+	b.failure()
+	if !b.isOpen() {
+		t.Fatal("expected the breaker to open at the threshold")
+	}
+
+	// Half open after the cooldown, a failure opens it again
+	now = now.Add(time.Minute)
+	if b.isOpen() {
+		t.Fatal("expected the breaker to allow an attempt after the cooldown")
+	}
+	b.failure()
+	if !b.isOpen() {
+		t.Fatal("expected a failure after the cooldown to open the breaker")
+	}
+	now = now.Add(time.Minute)
+	b.success()
+	if b.isOpen() {
+		t.Fatal("expected a success to close the breaker")
+	}
+
+	disabled := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		disabled.failure()
+	}
+	if disabled.isOpen() {
+		t.Fatal("expected a breaker with no threshold to stay closed")
+	}
+}
+
+func TestWriter_FallbackWriter(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("fallback", big.NewInt(0), nil)
+	cfg.circuitBreakerThreshold = 1
+	cfg.circuitBreakerCooldown = time.Minute
+	stop := make(chan int)
+	defer close(stop)
+	sysErr := make(chan error, 1)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, sysErr, nil)
+	m := msg.NewFungibleTransfer(1, 0, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes())
+
+	// Without a fallback writer messages are not resolved while the breaker is open
+	writer.breaker.failure()
+	if !writer.CircuitBreakerOpen() {
+		t.Fatal("expected the circuit breaker to be open")
+	}
+	if writer.ResolveMessage(m) {
+		t.Fatal("expected the message not to be resolved")
+	}
+
+	fallback := chains.NewFallbackWriter()
+	writer.SetFallbackWriter(fallback)
+	if !writer.ResolveMessage(m) {
+		t.Fatal("expected the fallback writer to resolve the message")
+	}
+	received := fallback.ReceivedMessages()
+	if len(received) != 1 || received[0].DepositNonce != m.DepositNonce {
+		t.Fatalf("expected the message to be passed to the fallback writer, got: %v", received)
+	}
+	if backend.called("CallContract") != 0 || len(backend.transactions()) != 0 {
+		t.Fatal("expected the writer not to query the chain or send transactions while the breaker is open")
+	}
+
+	// Messages whose transactions exhaust their retries go to the fallback writer instead of stopping the bridge
+	writer.retriesExhausted(m)
+	if len(fallback.ReceivedMessages()) != 2 {
+		t.Fatalf("expected 2 messages, got: %d", len(fallback.ReceivedMessages()))
+	}
+	select {
+	case err := <-sysErr:
+		t.Fatalf("unexpected fatal error: %v", err)
+	default:
+	}
+}
@@ -21,142 +21,495 @@ The writer recieves the message and creates a proposals on-chain. Once a proposa
 package ethereum
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	bridge "github.com/ChainSafe/ChainBridge/bindings/Bridge"
 	erc20Handler "github.com/ChainSafe/ChainBridge/bindings/ERC20Handler"
 	erc721Handler "github.com/ChainSafe/ChainBridge/bindings/ERC721Handler"
 	"github.com/ChainSafe/ChainBridge/bindings/GenericHandler"
+	"github.com/ChainSafe/ChainBridge/bindings/IFeeHandler"
+	"github.com/ChainSafe/ChainBridge/bindings/IPermitHandler"
+	"github.com/ChainSafe/ChainBridge/blockstore"
+	"github.com/ChainSafe/ChainBridge/chains"
 	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/ChainBridge/connections/ethereum/tenderly"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/ChainBridge/crypto/fireblocks"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ChainSafe/ChainBridge/feelog"
+	"github.com/ChainSafe/ChainBridge/ipfs"
+	"github.com/ChainSafe/ChainBridge/keystore"
+	"github.com/ChainSafe/ChainBridge/lock"
+	"github.com/ChainSafe/ChainBridge/router"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
-	"github.com/ChainSafe/chainbridge-utils/blockstore"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
-	"github.com/ChainSafe/chainbridge-utils/keystore"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 var _ core.Chain = &Chain{}
+var _ core.Readier = &Chain{}
+var _ core.CircuitBreaker = &Chain{}
+var _ core.RangeReporter = &Chain{}
+
+// ChainType identifies ethereum chains in the bridge config
+const ChainType = "ethereum"
+
+func init() {
+	core.RegisterChainFactory(ChainType, newChain)
+	core.RegisterOptsSchema(ChainType, Config{})
+}
+
+// newChain initializes a chain for the core.ChainFactory registry
+func newChain(cfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (core.Chain, error) {
+	chain, err := InitializeChain(cfg, logger, sysErr, m)
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
 
 var _ Connection = &connection.Connection{}
 
 type Connection interface {
 	Connect() error
+	ConnectWithContext(ctx context.Context) error
 	Keypair() *secp256k1.Keypair
+	RotateKeypair(kp *secp256k1.Keypair) error
 	Opts() *bind.TransactOpts
 	CallOpts() *bind.CallOpts
 	LockAndUpdateOpts() error
 	UnlockOpts()
-	Client() *ethclient.Client
+	Backend() connection.Backend
 	EnsureHasBytecode(address common.Address) error
+	IsMinimalProxy(addr common.Address) (common.Address, bool, error)
 	LatestBlock() (*big.Int, error)
+	BlockByTimestamp(ctx context.Context, ts time.Time) (*big.Int, error)
+	GetContractDeploymentBlock(ctx context.Context, addr common.Address) (*big.Int, error)
 	WaitForBlock(block *big.Int, delay *big.Int) error
+	EffectiveGasPrice(ctx context.Context) (*big.Int, error)
+	SimulateTransaction(ctx context.Context, call eth.CallMsg) (uint64, error)
+	EstimateGasWithBuffer(ctx context.Context, call eth.CallMsg, pct float64) (uint64, error)
+	BatchGetBlockHeaders(ctx context.Context, from, to *big.Int) ([]*types.Header, error)
+	GetProof(ctx context.Context, account common.Address, keys []string, block *big.Int) (*connection.AccountProof, error)
+	GetContractStorageProof(ctx context.Context, addr common.Address, keys []common.Hash, block *big.Int) (*connection.ContractStorageProof, error)
+	GetTransactionTrace(ctx context.Context, txHash common.Hash) (*connection.TransactionTrace, error)
+	GetPendingBalance(addr common.Address) (*big.Int, error)
+	GetPeerCount() (uint64, error)
+	CheckSync() (bool, connection.SyncProgress, error)
+	GetTxPoolContent() (pending, queued map[string]map[string]*types.Transaction, err error)
+	SubscribePendingTxs(ctx context.Context, hashes chan<- common.Hash) (eth.Subscription, error)
+	WatchBlockHeaders(ctx context.Context, headers chan<- *types.Header) error
+	ResolveENS(ctx context.Context, name string) (common.Address, error)
+	ClearENSCache()
 	Close()
 }
 
 type Chain struct {
-	cfg      *core.ChainConfig // The config of the chain
-	conn     Connection        // THe chains connection
-	listener *listener         // The listener of this chain
-	writer   *writer           // The writer of the chain
+	cfg      *core.ChainConfig            // The config of the chain
+	conn     Connection                   // THe chains connection
+	listener *listener                    // The listener of this chain
+	writer   *writer                      // The writer of the chain
+	priority *writer                      // Writes priorityResourceIds messages, nil if none are configured
+	reader   *Reader                      // Queries the bridge using conn
+	fees     *FeeCollector                // nil if no claimThreshold is configured
+	ens      *ensWatcher                  // nil if no contracts are configured by ENS name
+	gas      *connection.GasConfigWatcher // nil if no gas config file is configured
+	peers    *peerMonitor                 // Reports the peer count of conn's node
+	health   *healthChecker               // Reconnects the writer if its node is unhealthy, nil if healthCheckInterval is 0
+	unlocks  *unlockWatcher               // nil unless watchUnlocks is set
+	router   *router.Router               // The router the writer is registered with
 	stop     chan<- int
+	running  int32 // 1 while started, accessed atomically
+
+	// restartLock is held while the writers are restarted or rotated, and by core while the chain is removed
+	restartLock sync.Mutex
+	// writerLock guards writer and priority, which are replaced by Restart
+	writerLock sync.RWMutex
+
+	// fireblocks signs transactions in place of a keystore key, nil unless fireblocksVaultId is configured
+	fireblocks *fireblocks.FireblocksKeypair
+}
+
+// boundContracts holds the contract bindings for a single connection
+type boundContracts struct {
+	bridge         *bridge.Bridge
+	erc20Handler   *erc20Handler.ERC20Handler
+	erc721Handler  *erc721Handler.ERC721Handler
+	genericHandler *GenericHandler.GenericHandler
+	feeHandler     *IFeeHandler.IFeeHandler       // nil if no fee handler is configured
+	permitHandler  *IPermitHandler.IPermitHandler // erc20 handler bound to its permit deposit interface
 }
 
-// checkBlockstore queries the blockstore for the latest known block. If the latest block is
-// greater than cfg.startBlock, then cfg.startBlock is replaced with the latest known block.
-func setupBlockstore(cfg *Config, kp *secp256k1.Keypair) (*blockstore.Blockstore, error) {
-	bs, err := blockstore.NewBlockstore(cfg.blockstorePath, cfg.id, kp.Address())
+// bindContracts binds the configured contracts to the connection's client, verifying the bridge chain ID matches id
+func bindContracts(cfg *Config, conn Connection, id msg.ChainId) (*boundContracts, error) {
+	bridgeContract, err := bridge.NewBridge(cfg.bridgeContract, conn.Backend())
 	if err != nil {
 		return nil, err
 	}
 
+	chainId, err := bridgeContract.ChainID(conn.CallOpts())
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err), id)
+	}
+
+	if chainId != uint8(id) {
+		err = fmt.Errorf("chainId (%d) and configuration chainId (%d) do not match", chainId, id)
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeChainIdMismatch, false, err), id)
+	}
+
+	erc20HandlerContract, err := erc20Handler.NewERC20Handler(cfg.erc20HandlerContract, conn.Backend())
+	if err != nil {
+		return nil, err
+	}
+
+	erc721HandlerContract, err := erc721Handler.NewERC721Handler(cfg.erc721HandlerContract, conn.Backend())
+	if err != nil {
+		return nil, err
+	}
+
+	genericHandlerContract, err := GenericHandler.NewGenericHandler(cfg.genericHandlerContract, conn.Backend())
+	if err != nil {
+		return nil, err
+	}
+
+	permitHandlerContract, err := IPermitHandler.NewIPermitHandler(cfg.erc20HandlerContract, conn.Backend())
+	if err != nil {
+		return nil, err
+	}
+
+	var feeHandlerContract *IFeeHandler.IFeeHandler
+	if cfg.feeHandlerContract != utils.ZeroAddress {
+		feeHandlerContract, err = IFeeHandler.NewIFeeHandler(cfg.feeHandlerContract, conn.Backend())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &boundContracts{
+		bridge:         bridgeContract,
+		erc20Handler:   erc20HandlerContract,
+		erc721Handler:  erc721HandlerContract,
+		genericHandler: genericHandlerContract,
+		feeHandler:     feeHandlerContract,
+		permitHandler:  permitHandlerContract,
+	}, nil
+}
+
+// ChainIDMismatchError is returned when the node's chain ID differs from the configured evmChainId
+type ChainIDMismatchError struct {
+	Configured *big.Int
+	Actual     *big.Int
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("node chain ID (%s) does not match %s (%s), check the endpoint is on the intended network", e.Actual, EvmChainIdOpt, e.Configured)
+}
+
+// checkEvmChainId compares the node's eth_chainId with cfg.evmChainId. A mismatch is only logged if
+// cfg.allowChainIdMismatch is set.
+func checkEvmChainId(ctx context.Context, cfg *Config, conn Connection, log log15.Logger) error {
+	actual, err := conn.Backend().ChainID(ctx)
+	if err != nil {
+		return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	if actual.Cmp(cfg.evmChainId) == 0 {
+		return nil
+	}
+
+	err = &ChainIDMismatchError{Configured: cfg.evmChainId, Actual: actual}
+	if cfg.allowChainIdMismatch {
+		log.Warn("Ignoring chain ID mismatch", "err", err)
+		return nil
+	}
+	return bridgeErrors.NewConfigError(bridgeErrors.CodeChainIdMismatch, false, err)
+}
+
+// NotARelayerError is returned when the relayer is not in the bridge's relayer set, so its votes would be rejected
+type NotARelayerError struct {
+	Relayer common.Address
+	Bridge  common.Address
+	Err     error // Why the relayer could not be registered, nil if autoRegister is not set
+}
+
+func (e *NotARelayerError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s is not a relayer on bridge %s and could not be registered: %s", e.Relayer.Hex(), e.Bridge.Hex(), e.Err)
+	}
+	return fmt.Sprintf("%s is not a relayer on bridge %s, add it to the relayer set or set %s", e.Relayer.Hex(), e.Bridge.Hex(), AutoRegisterOpt)
+}
+
+func (e *NotARelayerError) Unwrap() error {
+	return e.Err
+}
+
+// checkRelayer returns a NotARelayerError if the relayer sending transactions on conn is not in the bridge's relayer
+// set. If cfg.autoRegister is set the relayer is added instead, which requires it to be a bridge admin.
+func checkRelayer(cfg *Config, conn Connection, bridgeContract *bridge.Bridge, log log15.Logger) error {
+	relayer := conn.CallOpts().From
+	isRelayer, err := bridgeContract.IsRelayer(conn.CallOpts(), relayer)
+	if err != nil {
+		return bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err)
+	}
+	if isRelayer {
+		return nil
+	}
+
+	log.Error("Relayer is not in the bridge relayer set, its votes will be rejected", "relayer", relayer.Hex(), "bridge", cfg.bridgeContract.Hex())
+	if !cfg.autoRegister {
+		return bridgeErrors.NewConfigError(bridgeErrors.CodeNotRelayer, false, &NotARelayerError{Relayer: relayer, Bridge: cfg.bridgeContract})
+	}
+
+	err = registerRelayer(conn, bridgeContract, relayer)
+	if err != nil {
+		return bridgeErrors.NewConfigError(bridgeErrors.CodeNotRelayer, false, &NotARelayerError{Relayer: relayer, Bridge: cfg.bridgeContract, Err: err})
+	}
+	log.Info("Registered relayer with the bridge", "relayer", relayer.Hex(), "bridge", cfg.bridgeContract.Hex())
+	return nil
+}
+
+// registerRelayer adds relayer to the bridge's relayer set, which it must be an admin of, and waits for the
+// transaction to be mined
+func registerRelayer(conn Connection, bridgeContract *bridge.Bridge, relayer common.Address) error {
+	adminRole, err := bridgeContract.DEFAULTADMINROLE(conn.CallOpts())
+	if err != nil {
+		return err
+	}
+	isAdmin, err := bridgeContract.HasRole(conn.CallOpts(), adminRole, relayer)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return fmt.Errorf("%s is not a bridge admin", relayer.Hex())
+	}
+
+	err = conn.LockAndUpdateOpts()
+	if err != nil {
+		return err
+	}
+	tx, err := bridgeContract.AdminAddRelayer(conn.Opts(), relayer)
+	conn.UnlockOpts()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DeployTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, conn.Backend(), tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("adminAddRelayer transaction %s reverted", tx.Hash().Hex())
+	}
+	return nil
+}
+
+// setupBlockstore opens the blockstore and sets the start block to the block after the latest block stored, as blocks
+// are only stored once all their deposits have been routed. On a fresh start without a start block, the chain is read
+// from the block the bridge was deployed at instead of the genesis block.
+func setupBlockstore(ctx context.Context, cfg *Config, relayer string, conn Connection, log log15.Logger) (blockstore.Blockstore, error) {
+	bs, err := blockstore.NewBlockstore(cfg.blockstorePath, cfg.id, relayer)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewBlockstoreError(bridgeErrors.CodeBlockstoreOpen, false, err), cfg.id)
+	}
+
 	if !cfg.freshStart {
 		latestBlock, err := bs.TryLoadLatestBlock()
 		if err != nil {
-			return nil, err
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewBlockstoreError(bridgeErrors.CodeBlockstoreLoad, false, err), cfg.id)
 		}
 
-		if latestBlock.Cmp(cfg.startBlock) == 1 {
-			cfg.startBlock = latestBlock
+		// The blockstore returns 0 if no block has been stored
+		if latestBlock.Sign() > 0 {
+			next := new(big.Int).Add(latestBlock, big.NewInt(1))
+			if next.Cmp(cfg.startBlock) == 1 {
+				cfg.startBlock = next
+			}
+		}
+	} else if cfg.startBlock.Sign() == 0 {
+		// Nodes that do not keep historical state cannot find the deployment, the chain is then read from genesis
+		deployment, err := conn.GetContractDeploymentBlock(ctx, cfg.bridgeContract)
+		if err != nil {
+			log.Warn("Unable to find the bridge deployment block, starting from block 0", "bridge", cfg.bridgeContract.Hex(), "err", err)
+		} else {
+			log.Info("Detected bridge deployment block", "bridge", cfg.bridgeContract.Hex(), "block", deployment)
+			cfg.startBlock = deployment
 		}
 	}
 
 	return bs, nil
 }
 
-func InitializeChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (*Chain, error) {
-	cfg, err := parseChainConfig(chainCfg)
+// loadKeypair loads the from key from the keystore, deriving the child key at the derivation path if one is set
+func loadKeypair(cfg *Config, insecure bool) (*secp256k1.Keypair, error) {
+	kpI, err := keystore.KeypairFromAddress(cfg.from, keystore.EthChain, cfg.keystorePath, insecure)
 	if err != nil {
 		return nil, err
 	}
+	kp, _ := kpI.(*secp256k1.Keypair)
+	if cfg.derivationPath == "" {
+		return kp, nil
+	}
+	return DeriveChild(kp, cfg.derivationPath)
+}
 
-	kpI, err := keystore.KeypairFromAddress(cfg.from, keystore.EthChain, cfg.keystorePath, chainCfg.Insecure)
+// loadFireblocksKeypair returns the key of the fireblocksVaultId vault account, which must have the from address
+func loadFireblocksKeypair(ctx context.Context, cfg *Config) (*fireblocks.FireblocksKeypair, error) {
+	secret, err := fireblocks.LoadSecretKey(cfg.fireblocksSecretKey)
 	if err != nil {
 		return nil, err
 	}
-	kp, _ := kpI.(*secp256k1.Keypair)
-
-	bs, err := setupBlockstore(cfg, kp)
+	client := fireblocks.NewClient(cfg.fireblocksBaseURL, cfg.fireblocksAPIKey, secret)
+	kp, err := fireblocks.NewFireblocksKeypair(ctx, client, cfg.fireblocksVaultId)
 	if err != nil {
 		return nil, err
 	}
+	if kp.CommonAddress() != common.HexToAddress(cfg.from) {
+		return nil, fmt.Errorf("fireblocks vault account %s has address %s, expected from %s", cfg.fireblocksVaultId, kp.Address(), cfg.from)
+	}
+	return kp, nil
+}
+
+func InitializeChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (*Chain, error) {
+	cfg, err := parseChainConfig(chainCfg)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+	}
+
+	// Transactions are signed by either a keystore key or a Fireblocks vault account
+	var kp *secp256k1.Keypair
+	var fireblocksKp *fireblocks.FireblocksKeypair
+	var relayer string
+	if cfg.fireblocksVaultId != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout)
+		fireblocksKp, err = loadFireblocksKeypair(ctx, cfg)
+		cancel()
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeKeystore, false, err), chainCfg.Id)
+		}
+		relayer = fireblocksKp.Address()
+	} else {
+		kp, err = loadKeypair(cfg, chainCfg.Insecure)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeKeystore, false, err), chainCfg.Id)
+		}
+		relayer = kp.Address()
+	}
+
+	// No HeaderOracle implementation is available yet to provide trusted block hashes
+	if cfg.trustlessMode {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, ErrNoHeaderOracle), chainCfg.Id)
+	}
+
+	locker, err := lock.NewLocker(cfg.lockBackend, cfg.lockPath, cfg.lockUrl, lock.RedisOptions{Password: cfg.lockPassword, TLS: cfg.lockTLS}, lock.DefaultTTL)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+	}
 
 	stop := make(chan int)
+	var gas *connection.GasConfigWatcher
+	if cfg.gasConfigFile != "" {
+		gas, err = connection.NewGasConfigWatcher(cfg.gasConfigFile, logger, stop)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+		}
+	}
+	var tokens TokenMap
+	if cfg.tokenMapFile != "" {
+		tokens, err = LoadTokenMap(cfg.tokenMapFile)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+		}
+	}
 	conn := connection.NewConnection(cfg.endpoint, cfg.http, kp, logger, cfg.gasLimit, cfg.maxGasPrice, cfg.minGasPrice, cfg.gasMultiplier, cfg.egsApiKey, cfg.egsSpeed)
-	err = conn.Connect()
+	conn.SetBatchRPC(cfg.useBatchRPC)
+	conn.SetMinPeerCount(cfg.minPeerCount)
+	conn.SetWaitForSync(cfg.waitForSync)
+	conn.SetENS(cfg.ensRegistry, cfg.ensCacheTTL)
+	if cfg.safeAddress != utils.ZeroAddress {
+		conn.SetSafe(cfg.safeAddress, cfg.safeTxServiceURL)
+	}
+	if fireblocksKp != nil {
+		conn.SetFireblocks(fireblocksKp)
+	}
+	if gas != nil {
+		conn.SetGasConfigWatcher(gas)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout)
+	defer cancel()
+	err = conn.ConnectWithContext(ctx)
 	if err != nil {
-		return nil, err
+		return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+	}
+
+	if cfg.evmChainId != nil {
+		err = checkEvmChainId(ctx, cfg, conn, logger)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+		}
 	}
+
+	err = resolveENSNames(ctx, cfg, conn, logger)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+	}
+
+	if cfg.deployMissing {
+		err = deployMissingContracts(cfg, conn, logger)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+		}
+	}
+
 	err = conn.EnsureHasBytecode(cfg.bridgeContract)
 	if err != nil {
-		return nil, err
+		return nil, bridgeErrors.WithChain(err, chainCfg.Id)
 	}
 
 	if cfg.erc20HandlerContract != utils.ZeroAddress {
 		err = conn.EnsureHasBytecode(cfg.erc20HandlerContract)
 		if err != nil {
-			return nil, err
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
 		}
 	}
 
 	if cfg.genericHandlerContract != utils.ZeroAddress {
 		err = conn.EnsureHasBytecode(cfg.genericHandlerContract)
 		if err != nil {
-			return nil, err
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
 		}
 	}
 
-	bridgeContract, err := bridge.NewBridge(cfg.bridgeContract, conn.Client())
-	if err != nil {
-		return nil, err
-	}
-
-	chainId, err := bridgeContract.ChainID(conn.CallOpts())
-	if err != nil {
-		return nil, err
-	}
-
-	if chainId != uint8(chainCfg.Id) {
-		return nil, fmt.Errorf("chainId (%d) and configuration chainId (%d) do not match", chainId, chainCfg.Id)
+	if cfg.feeHandlerContract != utils.ZeroAddress {
+		err = conn.EnsureHasBytecode(cfg.feeHandlerContract)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+		}
 	}
 
-	erc20HandlerContract, err := erc20Handler.NewERC20Handler(cfg.erc20HandlerContract, conn.Client())
+	contracts, err := bindContracts(cfg, conn, chainCfg.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	erc721HandlerContract, err := erc721Handler.NewERC721Handler(cfg.erc721HandlerContract, conn.Client())
+	err = checkRelayer(cfg, conn, contracts.bridge, logger)
 	if err != nil {
-		return nil, err
+		return nil, bridgeErrors.WithChain(err, chainCfg.Id)
 	}
 
-	genericHandlerContract, err := GenericHandler.NewGenericHandler(cfg.genericHandlerContract, conn.Client())
+	bs, err := setupBlockstore(ctx, cfg, relayer, conn, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -170,23 +523,249 @@ func InitializeChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr cha
 	}
 
 	listener := NewListener(conn, cfg, logger, bs, stop, sysErr, m)
-	listener.setContracts(bridgeContract, erc20HandlerContract, erc721HandlerContract, genericHandlerContract)
+	listener.setContracts(contracts.bridge, contracts.erc20Handler, contracts.erc721Handler, contracts.genericHandler)
+	if !chainCfg.StartTime.IsZero() {
+		err = listener.SetStartBlockByTimestamp(chainCfg.StartTime)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(err, chainCfg.Id)
+		}
+	}
+	if len(cfg.skipBlocks) != 0 {
+		listener.SetBlockFilter(skipBlocksFilter(cfg.skipBlocks))
+	}
+	if tokens != nil {
+		listener.setDecimalNormalizer(NewDecimalNormalizer(tokens))
+	}
+	listener.setReconnect(func() (Connection, *boundContracts, error) {
+		return connectReadOnly(cfg, logger)
+	})
 
+	var priority *writer
 	writer := NewWriter(conn, cfg, logger, stop, sysErr, m)
-	writer.setContract(bridgeContract)
+	writer.setContract(contracts.bridge)
+	writer.setFeeHandler(contracts.feeHandler)
+	writer.setPermitHandler(contracts.permitHandler)
+	writer.setLocker(locker)
+
+	// Priority messages are written by a separate writer, so they are not queued behind other messages
+	if len(cfg.priorityResourceIds) != 0 {
+		priority = NewWriter(conn, cfg, logger.New("lane", "priority"), stop, sysErr, m)
+		priority.setContract(contracts.bridge)
+		priority.setFeeHandler(contracts.feeHandler)
+		priority.setPermitHandler(contracts.permitHandler)
+		priority.setLocker(locker)
+	}
+
+	if cfg.simulatorBackend == tenderly.Backend {
+		simulator := tenderly.NewTenderlySimulator(cfg.tenderlyProject, cfg.tenderlyAccessKey)
+		writer.setSimulator(simulator)
+		if priority != nil {
+			priority.setSimulator(simulator)
+		}
+	}
+
+	if cfg.ipfsEndpoint != "" {
+		store := ipfs.NewMetadataStore(cfg.ipfsEndpoint)
+		store.SetMaxSize(cfg.maxMessageBytes)
+		listener.setMetadataStore(store)
+		writer.setMetadataStore(store)
+		if priority != nil {
+			priority.setMetadataStore(store)
+		}
+	}
+
+	if cfg.feeLogPath != "" {
+		feeLog, err := feelog.Open(cfg.feeLogPath)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+		}
+		writer.setFeeLog(feeLog)
+		if priority != nil {
+			priority.setFeeLog(feeLog)
+		}
+	}
+
+	var fees *FeeCollector
+	if cfg.claimThreshold != nil {
+		fees, err = NewFeeCollector(conn, cfg, conn.CallOpts().From, cfg.claimThreshold, logger, stop)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var unlocks *unlockWatcher
+	if cfg.watchUnlocks {
+		unlocks = newUnlockWatcher(cfg, conn, logger, stop)
+		writer.setUnlockWatcher(unlocks)
+		if priority != nil {
+			priority.setUnlockWatcher(unlocks)
+		}
+	}
+
+	var ens *ensWatcher
+	if len(cfg.ensNames) != 0 {
+		ens = newENSWatcher(cfg, conn, logger, stop)
+	}
 
 	return &Chain{
 		cfg:      chainCfg,
 		conn:     conn,
 		writer:   writer,
+		priority: priority,
 		listener: listener,
+		reader:   newReader(conn, cfg, contracts.bridge),
+		fees:     fees,
+		ens:      ens,
+		gas:      gas,
+		peers:    newPeerMonitor(cfg, conn, logger, stop),
+		unlocks:  unlocks,
 		stop:     stop,
+
+		fireblocks: fireblocksKp,
 	}, nil
 }
 
-func (c *Chain) SetRouter(r *core.Router) {
+func (c *Chain) SetRouter(r *router.Router) {
 	r.Listen(c.cfg.Id, c.writer)
+	c.writer.setRouter(r)
+	if c.priority != nil {
+		c.priority.setRouter(r)
+		r.ListenPriority(c.cfg.Id, c.priority, c.priority.cfg.priorityResourceIds)
+	}
 	c.listener.setRouter(r)
+	c.router = r
+}
+
+// LockRestarts holds off Restart and RotateKeypair until UnlockRestarts is called
+func (c *Chain) LockRestarts() {
+	c.restartLock.Lock()
+}
+
+// UnlockRestarts allows the writers to be restarted and rotated again
+func (c *Chain) UnlockRestarts() {
+	c.restartLock.Unlock()
+}
+
+// Restart reconnects the writers with new connections and replaces them in the router, passing them any
+// messages the previous writers had not resolved. The listener keeps its existing connection. A chain that has been
+// stopped is not restarted.
+func (c *Chain) Restart() error {
+	c.restartLock.Lock()
+	defer c.restartLock.Unlock()
+	if !c.Running() {
+		return errors.New("chain is not running")
+	}
+
+	old := c.currentWriter()
+	writer, err := c.restartWriter(old, false)
+	if err != nil {
+		return err
+	}
+	c.writerLock.Lock()
+	c.writer = writer
+	c.writerLock.Unlock()
+	go c.closeWhenIdle(old)
+
+	if oldPriority := c.priorityWriter(); oldPriority != nil {
+		priority, err := c.restartWriter(oldPriority, true)
+		if err != nil {
+			return err
+		}
+		c.writerLock.Lock()
+		c.priority = priority
+		c.writerLock.Unlock()
+		go c.closeWhenIdle(oldPriority)
+	}
+
+	old.log.Info("Restarted writer")
+	return nil
+}
+
+// restartWriter starts a copy of old with a new connection and replaces old with it in the router, as the chain's
+// priority writer if priority is set
+func (c *Chain) restartWriter(old *writer, priority bool) (*writer, error) {
+	cfg := old.cfg
+
+	conn := connection.NewConnection(cfg.endpoint, cfg.http, old.conn.Keypair(), old.log, cfg.gasLimit, cfg.maxGasPrice, cfg.minGasPrice, cfg.gasMultiplier, cfg.egsApiKey, cfg.egsSpeed)
+	conn.SetBatchRPC(cfg.useBatchRPC)
+	conn.SetMinPeerCount(cfg.minPeerCount)
+	conn.SetWaitForSync(cfg.waitForSync)
+	conn.SetENS(cfg.ensRegistry, cfg.ensCacheTTL)
+	if cfg.safeAddress != utils.ZeroAddress {
+		conn.SetSafe(cfg.safeAddress, cfg.safeTxServiceURL)
+	}
+	if c.fireblocks != nil {
+		conn.SetFireblocks(c.fireblocks)
+	}
+	if c.gas != nil {
+		conn.SetGasConfigWatcher(c.gas)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout)
+	defer cancel()
+	err := conn.ConnectWithContext(ctx)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(err, c.cfg.Id)
+	}
+
+	contracts, err := bindContracts(&cfg, conn, c.cfg.Id)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	writer := NewWriter(conn, &cfg, old.log, old.stop, old.sysErr, old.metrics)
+	writer.setContract(contracts.bridge)
+	writer.setFeeHandler(contracts.feeHandler)
+	writer.setPermitHandler(contracts.permitHandler)
+	writer.setLocker(old.locker)
+	writer.setMetadataStore(old.metadataStore)
+	writer.setSimulator(old.simulator)
+	writer.setUnlockWatcher(old.unlocks)
+	writer.setFeeLog(old.feeLog)
+	writer.setRouter(old.router)
+	writer.breaker = old.breaker
+	writer.SetFallbackWriter(old.fallback)
+	err = writer.start()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if c.router != nil {
+		replace := c.router.Replace
+		if priority {
+			replace = c.router.ReplacePriority
+		}
+		err = replace(c.cfg.Id, writer)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+// closeWhenIdle closes the connection of a replaced writer once the messages it was resolving and the executions
+// and receipts it is watching are done with it. The connection is left open if it is shared with the listener.
+func (c *Chain) closeWhenIdle(old *writer) {
+	old.waitIdle()
+	if old.conn != c.conn {
+		old.conn.Close()
+	}
+}
+
+// currentWriter returns the writer, which is replaced when the chain restarts
+func (c *Chain) currentWriter() *writer {
+	c.writerLock.RLock()
+	defer c.writerLock.RUnlock()
+	return c.writer
+}
+
+// priorityWriter returns the priority writer, or nil if none is configured
+func (c *Chain) priorityWriter() *writer {
+	c.writerLock.RLock()
+	defer c.writerLock.RUnlock()
+	return c.priority
 }
 
 func (c *Chain) Start() error {
@@ -200,10 +779,67 @@ func (c *Chain) Start() error {
 		return err
 	}
 
+	if c.priority != nil {
+		err = c.priority.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.fees != nil {
+		c.fees.start()
+	}
+
+	if c.ens != nil {
+		c.ens.start()
+	}
+
+	if c.gas != nil {
+		c.gas.Start()
+	}
+
+	if c.peers != nil {
+		c.peers.start()
+	}
+
+	if c.unlocks != nil {
+		c.unlocks.start()
+	}
+
+	if c.writer.cfg.healthCheckInterval > 0 {
+		c.health = newHealthChecker(&c.writer.cfg, func() Connection { return c.currentWriter().conn }, c.Restart, c.writer.log, c.writer.stop)
+		c.health.start()
+	}
+
+	if c.fireblocks == nil {
+		c.startKeyRotation()
+	}
+
+	atomic.StoreInt32(&c.running, 1)
 	c.writer.log.Debug("Successfully started chain")
 	return nil
 }
 
+// Running returns true once the chain has started, until it is stopped
+func (c *Chain) Running() bool {
+	return atomic.LoadInt32(&c.running) == 1
+}
+
+// PauseAt pauses the chain's listener once it has processed block, until Resume is called
+func (c *Chain) PauseAt(block *big.Int) {
+	c.listener.PauseAt(block)
+}
+
+// Resume continues processing blocks after a pause set by PauseAt
+func (c *Chain) Resume() {
+	c.listener.Resume()
+}
+
+// GetReader returns a Reader for querying the bridge, which shares the chain's connection
+func (c *Chain) GetReader() chains.Reader {
+	return c.reader
+}
+
 func (c *Chain) Id() msg.ChainId {
 	return c.cfg.Id
 }
@@ -212,14 +848,63 @@ func (c *Chain) Name() string {
 	return c.cfg.Name
 }
 
+// SetFallbackWriter sets the writer that resolves messages the chain's writers cannot, see writer.SetFallbackWriter
+func (c *Chain) SetFallbackWriter(w chains.Writer) {
+	c.writer.SetFallbackWriter(w)
+	if c.priority != nil {
+		c.priority.SetFallbackWriter(w)
+	}
+}
+
+// GetProcessedRange returns the start block of the listener and the latest block it has stored, see
+// listener.GetProcessedRange
+func (c *Chain) GetProcessedRange() (from, to *big.Int) {
+	return c.listener.GetProcessedRange()
+}
+
+// GetPendingRange returns the blocks the listener is processing but has not stored
+func (c *Chain) GetPendingRange() (from, to *big.Int) {
+	return c.listener.GetPendingRange()
+}
+
+// CircuitBreakerOpen returns true while the writer's circuit breaker is open
+func (c *Chain) CircuitBreakerOpen() bool {
+	return c.currentWriter().CircuitBreakerOpen()
+}
+
 func (c *Chain) LatestBlock() metrics.LatestBlock {
 	return c.listener.latestBlock
 }
 
+// WaitReady blocks until the listener has polled the latest block and the writers have started, or ctx is done
+func (c *Chain) WaitReady(ctx context.Context) error {
+	ready := []<-chan struct{}{c.listener.ready, c.currentWriter().ready}
+	if priority := c.priorityWriter(); priority != nil {
+		ready = append(ready, priority.ready)
+	}
+	for _, r := range ready {
+		select {
+		case <-r:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // Stop signals to any running routines to exit
 func (c *Chain) Stop() {
+	atomic.StoreInt32(&c.running, 0)
 	close(c.stop)
 	if c.conn != nil {
 		c.conn.Close()
 	}
+	for _, w := range []*writer{c.currentWriter(), c.priorityWriter()} {
+		if w != nil && w.conn != c.conn {
+			w.conn.Close()
+		}
+	}
+	if c.listener != nil {
+		c.listener.closeConn()
+	}
 }
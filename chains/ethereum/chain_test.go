@@ -4,16 +4,29 @@
 package ethereum
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	bridge "github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/blockstore"
+	"github.com/ChainSafe/ChainBridge/core"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ChainSafe/ChainBridge/metrics/health"
+	"github.com/ChainSafe/ChainBridge/router"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/keystore"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/goleak"
 )
 
 func TestChain_ListenerShutdownOnFailure(t *testing.T) {
@@ -26,7 +39,7 @@ func TestChain_ListenerShutdownOnFailure(t *testing.T) {
 		From:           keystore.AliceKey,
 		Insecure:       true,
 		KeystorePath:   keystore.AliceKey,
-		BlockstorePath: "",
+		BlockstorePath: blockstore.MemoryPath,
 		FreshStart:     true,
 		Opts: map[string]string{
 			"bridge":         contracts.BridgeAddress.Hex(),
@@ -35,6 +48,7 @@ func TestChain_ListenerShutdownOnFailure(t *testing.T) {
 			"genericHandler": contracts.GenericHandlerAddress.Hex(),
 			"gasLimit":       big.NewInt(DefaultGasLimit).String(),
 			"maxGasPrice":    big.NewInt(DefaultGasPrice).String(),
+			"minPeerCount":   "0",
 		},
 	}
 	sysErr := make(chan error)
@@ -49,7 +63,7 @@ func TestChain_ListenerShutdownOnFailure(t *testing.T) {
 	}
 
 	// Cause critical failure to polling mechanism
-	chain.conn.Client().Close()
+	chain.conn.Backend().Close()
 
 	// Pull expected error
 	select {
@@ -65,6 +79,115 @@ func TestChain_ListenerShutdownOnFailure(t *testing.T) {
 	chain.Stop()
 }
 
+func TestChain_WaitReady(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, msg.ChainId(1))
+	cfg := &core.ChainConfig{
+		Id:             msg.ChainId(1),
+		Name:           "alice",
+		Endpoint:       TestEndpoint,
+		From:           keystore.AliceKey,
+		Insecure:       true,
+		KeystorePath:   keystore.AliceKey,
+		BlockstorePath: blockstore.MemoryPath,
+		FreshStart:     true,
+		Opts: map[string]string{
+			"bridge":         contracts.BridgeAddress.Hex(),
+			"erc20Handler":   contracts.ERC20HandlerAddress.Hex(),
+			"erc721Handler":  contracts.ERC721HandlerAddress.Hex(),
+			"genericHandler": contracts.GenericHandlerAddress.Hex(),
+			"minPeerCount":   "0",
+		},
+	}
+	chain, err := InitializeChain(cfg, TestLogger, make(chan error), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	c := core.NewCore(make(chan error))
+	c.Register(chain)
+	err = chain.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = c.WaitReady(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChain_DeployMissing(t *testing.T) {
+	cfg := &core.ChainConfig{
+		Id:             msg.ChainId(3),
+		Name:           "alice",
+		Endpoint:       TestEndpoint,
+		From:           keystore.AliceKey,
+		Insecure:       true,
+		KeystorePath:   keystore.AliceKey,
+		BlockstorePath: blockstore.MemoryPath,
+		FreshStart:     true,
+		Opts: map[string]string{
+			"deployMissing": "true",
+			"erc721Handler": "0x0000000000000000000000000000000000000001", // No code, replaced
+			"gasLimit":      big.NewInt(DefaultGasLimit).String(),
+			"maxGasPrice":   big.NewInt(DefaultGasPrice).String(),
+			"minPeerCount":  "0",
+		},
+	}
+	chain, err := InitializeChain(cfg, TestLogger, make(chan error), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	deployed := chain.listener.cfg
+	for _, addr := range []common.Address{deployed.bridgeContract, deployed.erc20HandlerContract, deployed.erc721HandlerContract, deployed.genericHandlerContract} {
+		err = chain.conn.EnsureHasBytecode(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if chain.writer.cfg.bridgeContract != deployed.bridgeContract {
+		t.Fatalf("writer has bridge %s, expected %s", chain.writer.cfg.bridgeContract.Hex(), deployed.bridgeContract.Hex())
+	}
+
+	chainId, err := chain.listener.bridgeContract.ChainID(chain.conn.CallOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chainId != uint8(cfg.Id) {
+		t.Fatalf("expected bridge for chain %d, got: %d", cfg.Id, chainId)
+	}
+	isRelayer, err := chain.listener.bridgeContract.IsRelayer(chain.conn.CallOpts(), AliceKp.CommonAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRelayer {
+		t.Fatal("expected the relayer key to be a relayer of the deployed bridge")
+	}
+
+	// Deployed contracts are reused
+	cfg.Opts = map[string]string{
+		"deployMissing":  "true",
+		"bridge":         deployed.bridgeContract.Hex(),
+		"erc20Handler":   deployed.erc20HandlerContract.Hex(),
+		"erc721Handler":  deployed.erc721HandlerContract.Hex(),
+		"genericHandler": deployed.genericHandlerContract.Hex(),
+	}
+	reused, err := InitializeChain(cfg, TestLogger, make(chan error), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reused.Stop()
+	if reused.listener.cfg.bridgeContract != deployed.bridgeContract || reused.listener.cfg.erc20HandlerContract != deployed.erc20HandlerContract {
+		t.Fatal("expected existing contracts to be reused")
+	}
+}
+
 func TestChain_WriterShutdownOnFailure(t *testing.T) {
 	// Setup contracts and params for erc20 transfer
 	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
@@ -85,7 +208,7 @@ func TestChain_WriterShutdownOnFailure(t *testing.T) {
 		From:           keystore.AliceKey,
 		Insecure:       true,
 		KeystorePath:   keystore.AliceKey,
-		BlockstorePath: "",
+		BlockstorePath: blockstore.MemoryPath,
 		FreshStart:     true,
 		Opts: map[string]string{
 			"bridge":         contracts.BridgeAddress.Hex(),
@@ -94,6 +217,7 @@ func TestChain_WriterShutdownOnFailure(t *testing.T) {
 			"genericHandler": contracts.GenericHandlerAddress.Hex(),
 			"gasLimit":       big.NewInt(DefaultGasLimit).String(),
 			"maxGasPrice":    big.NewInt(DefaultGasPrice).String(),
+			"minPeerCount":   "0",
 		},
 	}
 	sysErr := make(chan error)
@@ -102,7 +226,7 @@ func TestChain_WriterShutdownOnFailure(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	r := core.NewRouter(TestLogger)
+	r := router.NewRouter(TestLogger)
 	chain.SetRouter(r)
 
 	err = chain.Start()
@@ -124,7 +248,7 @@ func TestChain_WriterShutdownOnFailure(t *testing.T) {
 
 	time.Sleep(time.Second)
 	// Cause critical failure for submitting txs
-	chain.conn.Client().Close()
+	chain.conn.Backend().Close()
 
 	// Pull expected error
 	select {
@@ -141,3 +265,349 @@ func TestChain_WriterShutdownOnFailure(t *testing.T) {
 	// Tell everyone to shutdown
 	chain.Stop()
 }
+
+func TestChain_Restart(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, msg.ChainId(1))
+	cfg := &core.ChainConfig{
+		Id:             msg.ChainId(1),
+		Name:           "alice",
+		Endpoint:       TestEndpoint,
+		From:           keystore.AliceKey,
+		Insecure:       true,
+		KeystorePath:   keystore.AliceKey,
+		BlockstorePath: blockstore.MemoryPath,
+		FreshStart:     true,
+		Opts: map[string]string{
+			"bridge":         contracts.BridgeAddress.Hex(),
+			"erc20Handler":   contracts.ERC20HandlerAddress.Hex(),
+			"erc721Handler":  contracts.ERC721HandlerAddress.Hex(),
+			"genericHandler": contracts.GenericHandlerAddress.Hex(),
+			"gasLimit":       big.NewInt(DefaultGasLimit).String(),
+			"maxGasPrice":    big.NewInt(DefaultGasPrice).String(),
+			"minPeerCount":   "0",
+		},
+	}
+	sysErr := make(chan error)
+	chain, err := InitializeChain(cfg, TestLogger, sysErr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.SetRouter(router.NewRouter(TestLogger))
+
+	err = chain.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	original := chain.writer
+	err = chain.Restart()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain.writer == original {
+		t.Fatal("expected writer to be replaced")
+	}
+	if chain.writer.conn == chain.conn {
+		t.Fatal("expected restarted writer to have its own connection")
+	}
+
+	// The new writer's contracts must be usable
+	id, err := chain.writer.bridgeContract.ChainID(chain.writer.conn.CallOpts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != uint8(cfg.Id) {
+		t.Fatalf("unexpected chain ID: %d", id)
+	}
+
+	// Restarting again closes the previous writer connection, not the listener's
+	err = chain.Restart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = chain.conn.LatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChain_EvmChainIdMismatch(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, msg.ChainId(1))
+	actual, err := client.Client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCfg := func(evmChainId string, allowMismatch bool) *core.ChainConfig {
+		return &core.ChainConfig{
+			Id:                   msg.ChainId(1),
+			Name:                 "alice",
+			Endpoint:             TestEndpoint,
+			From:                 keystore.AliceKey,
+			Insecure:             true,
+			KeystorePath:         keystore.AliceKey,
+			BlockstorePath:       blockstore.MemoryPath,
+			FreshStart:           true,
+			AllowChainIdMismatch: allowMismatch,
+			Opts: map[string]string{
+				"bridge":       contracts.BridgeAddress.Hex(),
+				"evmChainId":   evmChainId,
+				"minPeerCount": "0",
+			},
+		}
+	}
+
+	// The local node is not on mainnet
+	_, err = InitializeChain(newCfg("1", false), TestLogger, make(chan error), nil)
+	var mismatchErr *ChainIDMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("expected ChainIDMismatchError, got: %v", err)
+	}
+	if mismatchErr.Configured.Int64() != 1 || mismatchErr.Actual.Cmp(actual) != 0 {
+		t.Fatalf("unexpected chain IDs, configured: %s actual: %s", mismatchErr.Configured, mismatchErr.Actual)
+	}
+	var configErr *bridgeErrors.ConfigError
+	if !errors.As(err, &configErr) || configErr.Code != bridgeErrors.CodeChainIdMismatch {
+		t.Fatalf("expected ConfigError with CodeChainIdMismatch, got: %v", err)
+	}
+
+	for _, cfg := range []*core.ChainConfig{newCfg("1", true), newCfg(actual.String(), false)} {
+		chain, err := InitializeChain(cfg, TestLogger, make(chan error), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain.Stop()
+	}
+}
+
+func TestChain_Status(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	sysErr := make(chan error)
+	c := core.NewCore(sysErr)
+	for i, key := range []string{keystore.AliceKey, keystore.BobKey} {
+		id := msg.ChainId(i + 1)
+		contracts := deployTestContracts(t, client, id)
+		cfg := &core.ChainConfig{
+			Id:             id,
+			Name:           key,
+			Endpoint:       TestEndpoint,
+			From:           key,
+			Insecure:       true,
+			KeystorePath:   key,
+			BlockstorePath: blockstore.MemoryPath,
+			LatestBlock:    true,
+			Opts:           map[string]string{"bridge": contracts.BridgeAddress.Hex(), "blockConfirmations": "1", "minPeerCount": "0"},
+		}
+		chain, err := InitializeChain(cfg, TestLogger, sysErr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Register(chain)
+	}
+
+	for _, chain := range c.Registry {
+		if chain.Running() {
+			t.Fatalf("chain %d is running before it was started", chain.Id())
+		}
+		err := chain.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The latest block is set once the listener has processed a block
+	var status core.BridgeStatus
+	for start := time.Now(); time.Since(start) < 30*time.Second; time.Sleep(time.Second) {
+		status = c.Status()
+		if status.Chains[0].LatestBlock != nil && status.Chains[1].LatestBlock != nil {
+			break
+		}
+	}
+	for i, chain := range status.Chains {
+		if chain.Id != msg.ChainId(i+1) || !chain.Running || chain.LatestBlock == nil || chain.LatestBlock.Sign() == 0 {
+			t.Fatalf("unexpected status for chain %d: %+v", i+1, chain)
+		}
+		if chain.PendingMessages != 0 || chain.CircuitBreakerOpen {
+			t.Fatalf("unexpected status for chain %d: %+v", i+1, chain)
+		}
+	}
+
+	// The same status is served as JSON
+	rec := httptest.NewRecorder()
+	health.StatusHandler(c)(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var served core.BridgeStatus
+	err := json.NewDecoder(rec.Body).Decode(&served)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(served.Chains) != 2 || served.Chains[1].Name != keystore.BobKey || !served.Chains[1].Running {
+		t.Fatalf("unexpected served status: %+v", served)
+	}
+
+	for i, chain := range c.Registry {
+		chain.Stop()
+		if c.Status().Chains[i].Running {
+			t.Fatalf("expected chain %d not to be running once stopped", chain.Id())
+		}
+	}
+}
+
+func TestChain_NotARelayer(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	// Alice deploys the bridge, so is its admin, but only Bob is a relayer
+	ethtest.LockNonceAndUpdate(t, client)
+	bridgeAddr, tx, bridgeContract, err := bridge.DeployBridge(client.Opts, client.Client, uint8(1), []common.Address{BobKp.CommonAddress()}, big.NewInt(1), big.NewInt(0), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = utils.WaitForTx(client, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.UnlockNonce()
+
+	newCfg := func(key string, autoRegister bool) *core.ChainConfig {
+		return &core.ChainConfig{
+			Id:             msg.ChainId(1),
+			Name:           key,
+			Endpoint:       TestEndpoint,
+			From:           key,
+			Insecure:       true,
+			KeystorePath:   key,
+			BlockstorePath: blockstore.MemoryPath,
+			FreshStart:     true,
+			Opts: map[string]string{
+				"bridge":       bridgeAddr.Hex(),
+				"autoRegister": strconv.FormatBool(autoRegister),
+				"minPeerCount": "0",
+			},
+		}
+	}
+
+	// Charlie cannot register itself without being an admin
+	for _, cfg := range []*core.ChainConfig{newCfg(keystore.AliceKey, false), newCfg(keystore.CharlieKey, true)} {
+		_, err = InitializeChain(cfg, TestLogger, make(chan error), nil)
+		var notRelayerErr *NotARelayerError
+		if !errors.As(err, &notRelayerErr) {
+			t.Fatalf("expected NotARelayerError, got: %v", err)
+		}
+		var configErr *bridgeErrors.ConfigError
+		if !errors.As(err, &configErr) || configErr.Code != bridgeErrors.CodeNotRelayer {
+			t.Fatalf("expected ConfigError with CodeNotRelayer, got: %v", err)
+		}
+	}
+
+	chain, err := InitializeChain(newCfg(keystore.BobKey, false), TestLogger, make(chan error), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Stop()
+
+	chain, err = InitializeChain(newCfg(keystore.AliceKey, true), TestLogger, make(chan error), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Stop()
+	isRelayer, err := bridgeContract.IsRelayer(client.CallOpts, AliceKp.CommonAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRelayer {
+		t.Fatal("expected alice to be registered as a relayer")
+	}
+}
+
+func TestChain_start_stop_restart(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, msg.ChainId(1))
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), 1))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+	latest, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &core.ChainConfig{
+		Id:             msg.ChainId(1),
+		Name:           "alice",
+		Endpoint:       TestEndpoint,
+		From:           keystore.AliceKey,
+		Insecure:       true,
+		KeystorePath:   keystore.AliceKey,
+		BlockstorePath: blockstore.MemoryPath,
+		FreshStart:     true,
+		Opts: map[string]string{
+			"bridge":         contracts.BridgeAddress.Hex(),
+			"erc20Handler":   contracts.ERC20HandlerAddress.Hex(),
+			"erc721Handler":  contracts.ERC721HandlerAddress.Hex(),
+			"genericHandler": contracts.GenericHandlerAddress.Hex(),
+			"gasLimit":       big.NewInt(DefaultGasLimit).String(),
+			"maxGasPrice":    big.NewInt(DefaultGasPrice).String(),
+			"minPeerCount":   "0",
+			"startBlock":     strconv.FormatUint(latest+1, 10),
+		},
+	}
+
+	// Deposits to chain 2 are recorded by its writer
+	r := router.NewRouter(TestLogger)
+	writer := &fanoutWriter{msgs: make(chan msg.Message, 1)}
+	r.Listen(msg.ChainId(2), writer)
+
+	// Only the test's own routines and the router's chain 2 writer run before the chain starts
+	baseline := goleak.IgnoreCurrent()
+
+	start := func() *Chain {
+		// The config's opts are consumed when it is parsed
+		chainCfg := *cfg
+		chainCfg.Opts = make(map[string]string)
+		for k, v := range cfg.Opts {
+			chainCfg.Opts[k] = v
+		}
+		chain, err := InitializeChain(&chainCfg, TestLogger, make(chan error, 1), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain.SetRouter(r)
+		err = chain.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+		defer cancel()
+		err = chain.WaitReady(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chain
+	}
+	stop := func(chain *Chain) {
+		r.Unregister(chain.Id(), time.Second)
+		chain.Stop()
+		goleak.VerifyNone(t, baseline)
+	}
+
+	chain := start()
+	stop(chain)
+
+	// A restarted chain relays deposits
+	chain = start()
+	defer stop(chain)
+
+	amount := big.NewInt(10)
+	recipient := BobKp.CommonAddress()
+	createErc20Deposit(t, chain.listener.bridgeContract, client, resourceId, recipient, msg.ChainId(2), amount)
+	expected := msg.NewFungibleTransfer(msg.ChainId(1), msg.ChainId(2), 1, amount, resourceId, recipient.Bytes())
+	select {
+	case m := <-writer.msgs:
+		err = compareMessage(expected, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(TestTimeout):
+		t.Fatal("test timed out")
+	}
+}
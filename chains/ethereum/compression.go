@@ -0,0 +1,42 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionMagic prefixes generic metadata the listener has compressed with zstd
+const CompressionMagic byte = 0xc5
+
+// MaxDecompressedSize limits the memory used to decompress metadata
+const MaxDecompressedSize = 64 << 20
+
+var (
+	zstdFrameMagic = []byte{0x28, 0xb5, 0x2f, 0xfd} // Starts every zstd frame
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+)
+
+// compressMetadata returns data compressed and prefixed with CompressionMagic, or data unchanged if compressing
+// it does not make it smaller
+func compressMetadata(data []byte) []byte {
+	compressed := zstdEncoder.EncodeAll(data, []byte{CompressionMagic})
+	if len(compressed) >= len(data) {
+		return data
+	}
+	return compressed
+}
+
+// isCompressed returns true if data starts with CompressionMagic followed by a zstd frame
+func isCompressed(data []byte) bool {
+	return len(data) > 0 && data[0] == CompressionMagic && bytes.HasPrefix(data[1:], zstdFrameMagic)
+}
+
+// decompressMetadata returns the data compressed by compressMetadata
+func decompressMetadata(data []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(data[1:], nil)
+}
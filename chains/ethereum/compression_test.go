@@ -0,0 +1,80 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestCompressMetadata_Roundtrip(t *testing.T) {
+	// Calldata is mostly ABI padding, so repeated values compress well
+	payload := bytes.Repeat(append(make([]byte, 28), 0xde, 0xad, 0xbe, 0xef), 320)
+	if len(payload) != 10240 {
+		t.Fatalf("expected a 10 KB payload, got %d bytes", len(payload))
+	}
+
+	compressed := compressMetadata(payload)
+	if !isCompressed(compressed) {
+		t.Fatal("expected payload to be compressed")
+	}
+	if len(compressed) >= len(payload) {
+		t.Fatalf("compressed size %d is not smaller than %d", len(compressed), len(payload))
+	}
+	t.Logf("Compressed %d bytes to %d", len(payload), len(compressed))
+
+	decompressed, err := decompressMetadata(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, payload) {
+		t.Fatal("decompressed payload does not match")
+	}
+
+	// Random data does not compress, so is left unchanged
+	random := make([]byte, 10240)
+	rand.Read(random)
+	if res := compressMetadata(random); !bytes.Equal(res, random) {
+		t.Fatal("expected incompressible payload to be unchanged")
+	}
+}
+
+func TestWriter_GenericMetadataCompression(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x01}, 4096)
+	m := msg.NewGenericTransfer(1, 0, 1, msg.ResourceId{}, compressMetadata(payload))
+
+	cfg := createConfig("alice", nil, nil)
+	w := NewWriter(nil, cfg, TestLogger, nil, nil, nil)
+	metadata, err := w.genericMetadata(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(metadata, payload) {
+		t.Fatal("expected metadata to be decompressed")
+	}
+
+	// Uncompressed metadata starting with the magic byte is used as is
+	raw := append([]byte{CompressionMagic}, payload...)
+	metadata, err = w.genericMetadata(msg.NewGenericTransfer(1, 0, 1, msg.ResourceId{}, raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(metadata, raw) {
+		t.Fatal("expected uncompressed metadata to be unchanged")
+	}
+
+	// Older relayers relay compressed metadata as is
+	cfg.noCompression = true
+	w = NewWriter(nil, cfg, TestLogger, nil, nil, nil)
+	metadata, err = w.genericMetadata(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(metadata, m.Payload[0].([]byte)) {
+		t.Fatal("expected metadata to be unchanged with noCompression")
+	}
+}
@@ -7,12 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
+	"time"
 
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
 	"github.com/ChainSafe/ChainBridge/connections/ethereum/egs"
+	"github.com/ChainSafe/ChainBridge/connections/ethereum/tenderly"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/ChainBridge/lock"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 const DefaultGasLimit = 6721975
@@ -20,6 +28,21 @@ const DefaultGasPrice = 20000000000
 const DefaultMinGasPrice = 0
 const DefaultBlockConfirmations = 10
 const DefaultGasMultiplier = 1
+const DefaultConnectTimeout = 30 * time.Second
+const DefaultLagAlertThreshold = 100
+const DefaultWatchdogInterval = 60 * time.Second
+const DefaultMaxOnChainBytes = 1024
+const DefaultMaxMessageBytes = 1 << 20 // 1 MB
+const DefaultCompressionThreshold = 1024
+const DefaultMaxConcurrentProposals = 1
+const DefaultMempoolConfirmTimeout = 30 * time.Second
+const DefaultMaxBlocksPerPoll = 500
+const DefaultMinPeerCount = 1
+const DefaultPeerCheckInterval = 30 * time.Second
+const DefaultHealthCheckInterval = 30 * time.Second
+const DefaultMaxBlockAge = 2 * time.Minute
+const DefaultCircuitBreakerCooldown = time.Minute
+const DefaultBalanceCheckInterval = 5 * time.Minute
 
 // Chain specific options
 var (
@@ -27,6 +50,7 @@ var (
 	Erc20HandlerOpt       = "erc20Handler"
 	Erc721HandlerOpt      = "erc721Handler"
 	GenericHandlerOpt     = "genericHandler"
+	FeeHandlerOpt         = "feeHandler"
 	MaxGasPriceOpt        = "maxGasPrice"
 	MinGasPriceOpt        = "minGasPrice"
 	GasLimitOpt           = "gasLimit"
@@ -36,47 +60,223 @@ var (
 	BlockConfirmationsOpt = "blockConfirmations"
 	EGSApiKey             = "egsApiKey"
 	EGSSpeed              = "egsSpeed"
+	ConnectTimeoutOpt     = "connectTimeout"
+	LockBackendOpt        = "lockBackend"
+	LockPathOpt           = "lockPath"
+	LockUrlOpt            = "lockUrl"
+	LockPasswordOpt       = "lockPassword"
+	LockTLSOpt            = "lockTLS"
+	LockFailOpenOpt       = "lockFailOpen"
+	TrustlessModeOpt      = "trustlessMode"
+	LagAlertThresholdOpt  = "lagAlertThreshold"
+	WatchdogIntervalOpt   = "watchdogInterval"
+	IpfsEndpointOpt       = "ipfsEndpoint"
+	MaxOnChainBytesOpt    = "maxOnChainBytes"
+	MaxMessageBytesOpt    = "maxMessageBytes"
+	DeployMissingOpt      = "deployMissing"
+	CompressionThreshold  = "compressionThreshold"
+	NoCompressionOpt      = "noCompression"
+	EvmChainIdOpt         = "evmChainId"
+	ClaimThresholdOpt     = "claimThreshold"
+	UseBatchRPCOpt        = "useBatchRPC"
+	SafeAddressOpt        = "safeAddress"
+	SafeTxServiceURLOpt   = "safeTxServiceURL"
+	ENSRegistryOpt        = "ensRegistry"
+	ENSCacheTTLOpt        = "ensCacheTTL"
+	PriorityResourceOpt   = "priorityResourceIds"
+	MaxProposalsOpt       = "maxConcurrentProposals"
+	SkipBlocksOpt         = "skipBlocks"
+	SimulatorBackendOpt   = "simulatorBackend"
+	TenderlyProjectOpt    = "tenderlyProjectSlug"
+	TenderlyAccessKeyOpt  = "tenderlyAccessKey"
+	MempoolTimeoutOpt     = "mempoolConfirmTimeout"
+	MaxBlocksPerPollOpt   = "maxBlocksPerPoll"
+	AutoRegisterOpt       = "autoRegister"
+	GasConfigFileOpt      = "gasConfigFile"
+	MinPeerCountOpt       = "minPeerCount"
+	PeerCheckIntervalOpt  = "peerCheckInterval"
+	WatchUnlocksOpt       = "watchUnlocks"
+	FeeLogPathOpt         = "feeLogPath"
+	TokenMapFileOpt       = "tokenMapFile"
+	RequireSignedOpt      = "requireSignedMessages"
+	FireblocksVaultIdOpt  = "fireblocksVaultId"
+	FireblocksBaseURLOpt  = "fireblocksBaseURL"
+	FireblocksAPIKeyOpt   = "fireblocksAPIKey"
+	FireblocksSecretOpt   = "fireblocksSecretKey"
+	ForwardDepositsOpt    = "forwardDeposits"
+	HealthCheckOpt        = "healthCheckInterval"
+	MaxBlockAgeOpt        = "maxBlockAge"
+	FetchURIOpt           = "fetchURI"
+	PinTokenURIsOpt       = "pinTokenURIs"
+	BreakerThresholdOpt   = "circuitBreakerThreshold"
+	BreakerCooldownOpt    = "circuitBreakerCooldown"
+	WaitForSyncOpt        = "waitForSync"
+	MaxAmountPerBlockOpt  = "maxAmountPerBlock"
+	MaxAddressRateOpt     = "maxDepositsPerAddressPerMinute"
+	AttachProofOpt        = "attachStorageProof"
+	ForwarderAddressOpt   = "forwarderAddress"
+	MinBalanceOpt         = "minRelayerBalance"
+	BalanceIntervalOpt    = "balanceCheckInterval"
 )
 
+// MissingOptError is returned by parseChainConfig when a required opt is not provided
+type MissingOptError struct {
+	Opt string
+}
+
+func (e *MissingOptError) Error() string {
+	return fmt.Sprintf("must provide opts.%s field for ethereum config", e.Opt)
+}
+
 // Config encapsulates all necessary parameters in ethereum compatible forms
 type Config struct {
 	name                   string      // Human-readable chain name
 	id                     msg.ChainId // ChainID
 	endpoint               string      // url for rpc endpoint
 	from                   string      // address of key to use
+	derivationPath         string      // BIP-32 path of the key derived from the from key, parsed from a suffix of from
 	keystorePath           string      // Location of keyfiles
 	blockstorePath         string
-	freshStart             bool // Disables loading from blockstore at start
-	bridgeContract         common.Address
-	erc20HandlerContract   common.Address
-	erc721HandlerContract  common.Address
-	genericHandlerContract common.Address
-	gasLimit               *big.Int
-	maxGasPrice            *big.Int
-	minGasPrice            *big.Int
-	gasMultiplier          *big.Float
-	http                   bool // Config for type of connection
-	startBlock             *big.Int
-	blockConfirmations     *big.Int
-	egsApiKey              string // API key for ethgasstation to query gas prices
-	egsSpeed               string // The speed which a transaction should be processed: average, fast, fastest. Default: fast
+	freshStart             bool           // Disables loading from blockstore at start
+	bridgeContract         common.Address `opts:"bridge,required,desc=Address or ENS name of the bridge contract, not required with deployMissing"`
+	erc20HandlerContract   common.Address `opts:"erc20Handler,desc=Address or ENS name of the erc20 handler"`
+	erc721HandlerContract  common.Address `opts:"erc721Handler,desc=Address or ENS name of the erc721 handler"`
+	genericHandlerContract common.Address `opts:"genericHandler,desc=Address or ENS name of the generic handler"`
+	feeHandlerContract     common.Address `opts:"feeHandler,desc=Address or ENS name of a fee handler, its fee is paid in the native token before each execution"`
+	gasLimit               *big.Int       `opts:"gasLimit,default=6721975,desc=Maximum gas limit for transactions"`
+	maxGasPrice            *big.Int       `opts:"maxGasPrice,default=20000000000,desc=Maximum gas price for transactions"`
+	minGasPrice            *big.Int       `opts:"minGasPrice,default=0,desc=Minimum gas price for transactions"`
+	gasMultiplier          *big.Float     `opts:"gasMultiplier,default=1,desc=Multiplies the gas price by the supplied value"`
+	http                   bool           `opts:"http,default=false,desc=Connect to the endpoint over http instead of websockets"`
+	startBlock             *big.Int       `opts:"startBlock,default=0,desc=Block to start processing events from"`
+	blockConfirmations     *big.Int       `opts:"blockConfirmations,default=10,desc=Number of blocks to wait before processing a block"`
+	egsApiKey              string         `opts:"egsApiKey,desc=API key for Eth Gas Station"`
+	egsSpeed               string         `opts:"egsSpeed,default=fast,desc=Eth Gas Station speed: average, fast or fastest"`
+	connectTimeout         time.Duration  `opts:"connectTimeout,default=30s,desc=Maximum time to wait for the node when connecting"`
+	lockBackend            string         `opts:"lockBackend,default=none,desc=Lock held while executing a proposal: none, file or redis"`
+	lockPath               string         `opts:"lockPath,desc=Directory for the file lock backend"`
+	lockUrl                string         `opts:"lockUrl,desc=Address of the redis server for the redis lock backend"`
+	lockPassword           string         `opts:"lockPassword,desc=Password sent with AUTH to the redis server of the redis lock backend"`
+	lockTLS                bool           `opts:"lockTLS,default=false,desc=Connect to the redis server of the redis lock backend with TLS"`
+	lockFailOpen           bool           `opts:"lockFailOpen,default=false,desc=Execute proposals without the lock when the lock backend is unavailable"`
+	trustlessMode          bool           `opts:"trustlessMode,default=false,desc=Verify deposits against trusted block hashes before relaying them"`
+	lagAlertThreshold      *big.Int       `opts:"lagAlertThreshold,default=100,desc=Number of blocks the blockstore may fall behind the chain head before an error is logged"`
+	watchdogInterval       time.Duration  `opts:"watchdogInterval,default=60s,desc=Maximum time polling may stall before the listener is restarted"`
+	ipfsEndpoint           string         `opts:"ipfsEndpoint,desc=IPFS node API used to relay large generic metadata"`
+	maxOnChainBytes        int            `opts:"maxOnChainBytes,default=1024,desc=Generic metadata longer than this is stored in IPFS when ipfsEndpoint is set"`
+	maxMessageBytes        uint64         `opts:"maxMessageBytes,default=1048576,desc=Deposits with more data than this are not relayed"`
+	deployMissing          bool           `opts:"deployMissing,default=false,desc=Deploy the bridge and handlers at startup if they have no code, for development chains"`
+	compressionThreshold   int            `opts:"compressionThreshold,default=1024,desc=Generic metadata longer than this is compressed before it is relayed"`
+	noCompression          bool           `opts:"noCompression,default=false,desc=Neither compress nor decompress metadata"`
+	evmChainId             *big.Int       `opts:"evmChainId,desc=Network chain ID the endpoint must report"`
+	allowChainIdMismatch   bool           // Only warn if the node's chain ID differs from evmChainId
+	claimThreshold         *big.Int       `opts:"claimThreshold,desc=Relay fees in wei above which they are claimed"`
+	useBatchRPC            bool           `opts:"useBatchRPC,default=true,desc=Request ranges of block headers in JSON-RPC batches"`
+
+	safeAddress      common.Address `opts:"safeAddress,desc=Safe multisig transactions are sent through, requires safeTxServiceURL"`
+	safeTxServiceURL string         `opts:"safeTxServiceURL,desc=Safe Transaction Service used to propose and confirm Safe transactions"`
+
+	ensNames    map[string]string // ENS names of contracts, by opt, resolved when the chain is initialized
+	ensRegistry common.Address    `opts:"ensRegistry,default=0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e,desc=ENS registry contracts configured by name are resolved with"`
+	ensCacheTTL time.Duration     `opts:"ensCacheTTL,default=10m,desc=How long resolved ENS names are cached"`
+
+	priorityResourceIds []msg.ResourceId `opts:"priorityResourceIds,desc=Comma separated resource IDs written by a separate fast lane writer"`
+
+	maxConcurrentProposals int `opts:"maxConcurrentProposals,default=1,desc=Proposals the writer may submit at once"`
+
+	skipBlocks []uint64 `opts:"skipBlocks,desc=Comma separated blocks the listener does not process"`
+
+	simulatorBackend  string `opts:"simulatorBackend,desc=Service used to simulate reverted transactions for their revert reason: tenderly"`
+	tenderlyProject   string `opts:"tenderlyProjectSlug,desc=Tenderly project simulations run in, as account/project"`
+	tenderlyAccessKey string `opts:"tenderlyAccessKey,desc=Tenderly API access key"`
+
+	mempoolConfirmTimeout time.Duration `opts:"mempoolConfirmTimeout,default=30s,desc=Submitted transactions not seen in the mempool within this are re-submitted, 0s to disable"`
+
+	maxBlocksPerPoll uint64 `opts:"maxBlocksPerPoll,default=500,desc=Most blocks whose events are fetched in a single poll"`
+
+	autoRegister bool `opts:"autoRegister,default=false,desc=Add the relayer to the bridge at startup if it is not a relayer, the from key must be a bridge admin"`
+
+	gasConfigFile string `opts:"gasConfigFile,desc=JSON file overriding gasPrice, gasLimit and gasPriceMultiplier, reloaded on SIGHUP or when modified"`
+
+	minPeerCount      uint64        `opts:"minPeerCount,default=1,desc=Fewest peers the node may have, connecting is retried until it has enough, 0 to disable"`
+	peerCheckInterval time.Duration `opts:"peerCheckInterval,default=30s,desc=Time between updates of the node's peer count metric"`
+
+	watchUnlocks bool `opts:"watchUnlocks,default=false,desc=Confirm transfers delivered to this chain by the bridge's TokensUnlocked events"`
+
+	feeLogPath string `opts:"feeLogPath,desc=File the gas used and fee of each mined proposal transaction is appended to"`
+
+	tokenMapFile string `opts:"tokenMapFile,desc=JSON file mapping erc20 tokens to their destination tokens, whose decimals deposit amounts are scaled to"`
+
+	requireSignedMessages bool `opts:"requireSignedMessages,default=false,desc=Only resolve messages signed by a relayer of this chain's bridge"`
+
+	fireblocksVaultId   string `opts:"fireblocksVaultId,desc=Fireblocks vault account whose ETH key signs transactions in place of the keystore, its address must be from"`
+	fireblocksBaseURL   string `opts:"fireblocksBaseURL,desc=Fireblocks API URL, requires fireblocksVaultId"`
+	fireblocksAPIKey    string `opts:"fireblocksAPIKey,desc=Fireblocks API key, requires fireblocksVaultId"`
+	fireblocksSecretKey string `opts:"fireblocksSecretKey,desc=File holding the PEM encoded RSA secret key of the Fireblocks API user, requires fireblocksVaultId"`
+
+	forwardDeposits bool `opts:"forwardDeposits,default=false,desc=Deposit messages the router forwarded via this chain again for the chain they are forwarded to"`
+
+	healthCheckInterval time.Duration `opts:"healthCheckInterval,default=30s,desc=Time between checks that the node's latest block advances, 0s to disable"`
+	maxBlockAge         time.Duration `opts:"maxBlockAge,default=2m,desc=Longest time the node's latest block may not advance before the writer reconnects"`
+
+	fetchURI     bool `opts:"fetchURI,default=false,desc=Relay the tokenURI of erc721 deposits without metadata, fetched from the token contract"`
+	pinTokenURIs bool `opts:"pinTokenURIs,default=false,desc=Pin the ipfs:// token URIs of erc721 transfers to this chain, requires ipfsEndpoint"`
+
+	circuitBreakerThreshold int           `opts:"circuitBreakerThreshold,default=0,desc=Consecutive messages whose transactions exhaust their retries before the writer stops submitting transactions, 0 to disable"`
+	circuitBreakerCooldown  time.Duration `opts:"circuitBreakerCooldown,default=1m,desc=Time the writer stops submitting transactions for once the circuit breaker opens"`
+
+	waitForSync bool `opts:"waitForSync,default=false,desc=Wait until the node has synced when connecting, bounded by connectTimeout"`
+
+	maxAmountPerBlock              *big.Int `opts:"maxAmountPerBlock,desc=Fungible amount of a resource ID deposited in one block above which deposits are logged as anomalous"`
+	maxDepositsPerAddressPerMinute int      `opts:"maxDepositsPerAddressPerMinute,default=0,desc=Deposits by one address within a minute above which they are logged as anomalous, 0 to disable"`
+
+	attachStorageProof bool `opts:"attachStorageProof,default=false,desc=Relay generic deposits with the storage proof of their deposit record, for destinations that verify it"`
+
+	forwarderAddress common.Address `opts:"forwarderAddress,desc=EIP-2771 trusted forwarder proposals are executed through, so the bridge sees the relayer as the sender. Disabled if unset"`
+
+	minRelayerBalance    *big.Int      `opts:"minRelayerBalance,default=0,desc=Balance in wei the relayer must keep after paying for an execution, executions are held until the balance covers both"`
+	balanceCheckInterval time.Duration `opts:"balanceCheckInterval,default=5m,desc=Time between checks of the relayer's balance while executions are held"`
+}
+
+// parseResourceId parses a 32 byte hex resource ID
+func parseResourceId(id string) (msg.ResourceId, error) {
+	b, err := hexutil.Decode(id)
+	if err != nil {
+		return msg.ResourceId{}, err
+	}
+	if len(b) != 32 {
+		return msg.ResourceId{}, fmt.Errorf("resource ID %s is not 32 bytes", id)
+	}
+	return msg.ResourceIdFromSlice(b), nil
 }
 
 // parseChainConfig uses a core.ChainConfig to construct a corresponding Config
 func parseChainConfig(chainCfg *core.ChainConfig) (*Config, error) {
+	// Captured before the opts are consumed
+	opts := newConfigOpts(chainCfg)
+
+	from, derivationPath := splitDerivationPath(chainCfg.From)
+	if derivationPath != "" {
+		if _, err := accounts.ParseDerivationPath(derivationPath); err != nil {
+			return nil, fmt.Errorf("unable to parse derivation path of from, %w", err)
+		}
+	}
 
 	config := &Config{
 		name:                   chainCfg.Name,
 		id:                     chainCfg.Id,
 		endpoint:               chainCfg.Endpoint,
-		from:                   chainCfg.From,
+		from:                   from,
+		derivationPath:         derivationPath,
 		keystorePath:           chainCfg.KeystorePath,
 		blockstorePath:         chainCfg.BlockstorePath,
 		freshStart:             chainCfg.FreshStart,
+		allowChainIdMismatch:   chainCfg.AllowChainIdMismatch,
 		bridgeContract:         utils.ZeroAddress,
 		erc20HandlerContract:   utils.ZeroAddress,
 		erc721HandlerContract:  utils.ZeroAddress,
 		genericHandlerContract: utils.ZeroAddress,
+		feeHandlerContract:     utils.ZeroAddress,
 		gasLimit:               big.NewInt(DefaultGasLimit),
 		maxGasPrice:            big.NewInt(DefaultGasPrice),
 		minGasPrice:            big.NewInt(DefaultMinGasPrice),
@@ -86,30 +286,45 @@ func parseChainConfig(chainCfg *core.ChainConfig) (*Config, error) {
 		blockConfirmations:     big.NewInt(0),
 		egsApiKey:              "",
 		egsSpeed:               "",
+		useBatchRPC:            true,
 	}
 
+	if deploy, ok := chainCfg.Opts[DeployMissingOpt]; ok && deploy == "true" {
+		config.deployMissing = true
+		delete(chainCfg.Opts, DeployMissingOpt)
+	} else if deploy, ok := chainCfg.Opts[DeployMissingOpt]; ok && deploy == "false" {
+		config.deployMissing = false
+		delete(chainCfg.Opts, DeployMissingOpt)
+	}
+
+	// The bridge is deployed at startup if it is missing, so only needs to be configured to reuse it
 	if contract, ok := chainCfg.Opts[BridgeOpt]; ok && contract != "" {
-		config.bridgeContract = common.HexToAddress(contract)
+		config.bridgeContract = config.parseAddress(BridgeOpt, contract)
 		delete(chainCfg.Opts, BridgeOpt)
-	} else {
-		return nil, fmt.Errorf("must provide opts.bridge field for ethereum config")
+	} else if !config.deployMissing {
+		return nil, &MissingOptError{Opt: BridgeOpt}
 	}
 
 	if contract, ok := chainCfg.Opts[Erc20HandlerOpt]; ok {
-		config.erc20HandlerContract = common.HexToAddress(contract)
+		config.erc20HandlerContract = config.parseAddress(Erc20HandlerOpt, contract)
 		delete(chainCfg.Opts, Erc20HandlerOpt)
 	}
 
 	if contract, ok := chainCfg.Opts[Erc721HandlerOpt]; ok {
-		config.erc721HandlerContract = common.HexToAddress(contract)
+		config.erc721HandlerContract = config.parseAddress(Erc721HandlerOpt, contract)
 		delete(chainCfg.Opts, Erc721HandlerOpt)
 	}
 
 	if contract, ok := chainCfg.Opts[GenericHandlerOpt]; ok {
-		config.genericHandlerContract = common.HexToAddress(contract)
+		config.genericHandlerContract = config.parseAddress(GenericHandlerOpt, contract)
 		delete(chainCfg.Opts, GenericHandlerOpt)
 	}
 
+	if contract, ok := chainCfg.Opts[FeeHandlerOpt]; ok {
+		config.feeHandlerContract = config.parseAddress(FeeHandlerOpt, contract)
+		delete(chainCfg.Opts, FeeHandlerOpt)
+	}
+
 	if gasPrice, ok := chainCfg.Opts[MaxGasPriceOpt]; ok {
 		price, parseErr := utils.ParseUint256OrHex(&gasPrice)
 		if parseErr != nil {
@@ -198,9 +413,488 @@ func parseChainConfig(chainCfg *core.ChainConfig) (*Config, error) {
 		delete(chainCfg.Opts, EGSSpeed)
 	}
 
+	if timeout, ok := chainCfg.Opts[ConnectTimeoutOpt]; ok && timeout != "" {
+		val, err := time.ParseDuration(timeout)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", ConnectTimeoutOpt)
+		}
+		config.connectTimeout = val
+	} else {
+		config.connectTimeout = DefaultConnectTimeout
+	}
+	delete(chainCfg.Opts, ConnectTimeoutOpt)
+
+	if backend, ok := chainCfg.Opts[LockBackendOpt]; ok && backend != "" {
+		if backend != lock.NoneBackend && backend != lock.FileBackend && backend != lock.RedisBackend {
+			return nil, fmt.Errorf("unknown %s: %s", LockBackendOpt, backend)
+		}
+		config.lockBackend = backend
+	} else {
+		config.lockBackend = lock.NoneBackend
+	}
+	delete(chainCfg.Opts, LockBackendOpt)
+
+	if path, ok := chainCfg.Opts[LockPathOpt]; ok {
+		config.lockPath = path
+		delete(chainCfg.Opts, LockPathOpt)
+	}
+
+	if url, ok := chainCfg.Opts[LockUrlOpt]; ok {
+		config.lockUrl = url
+		delete(chainCfg.Opts, LockUrlOpt)
+	}
+
+	if password, ok := chainCfg.Opts[LockPasswordOpt]; ok {
+		config.lockPassword = password
+		delete(chainCfg.Opts, LockPasswordOpt)
+	}
+
+	if tls, ok := chainCfg.Opts[LockTLSOpt]; ok && tls == "true" {
+		config.lockTLS = true
+		delete(chainCfg.Opts, LockTLSOpt)
+	} else if tls, ok := chainCfg.Opts[LockTLSOpt]; ok && tls == "false" {
+		config.lockTLS = false
+		delete(chainCfg.Opts, LockTLSOpt)
+	}
+
+	if failOpen, ok := chainCfg.Opts[LockFailOpenOpt]; ok && failOpen == "true" {
+		config.lockFailOpen = true
+		delete(chainCfg.Opts, LockFailOpenOpt)
+	} else if failOpen, ok := chainCfg.Opts[LockFailOpenOpt]; ok && failOpen == "false" {
+		config.lockFailOpen = false
+		delete(chainCfg.Opts, LockFailOpenOpt)
+	}
+
+	if trustless, ok := chainCfg.Opts[TrustlessModeOpt]; ok && trustless == "true" {
+		config.trustlessMode = true
+		delete(chainCfg.Opts, TrustlessModeOpt)
+	} else if trustless, ok := chainCfg.Opts[TrustlessModeOpt]; ok && trustless == "false" {
+		config.trustlessMode = false
+		delete(chainCfg.Opts, TrustlessModeOpt)
+	}
+
+	if threshold, ok := chainCfg.Opts[LagAlertThresholdOpt]; ok && threshold != "" {
+		val, pass := big.NewInt(0).SetString(threshold, 10)
+		if !pass || val.Sign() < 0 {
+			return nil, fmt.Errorf("unable to parse %s", LagAlertThresholdOpt)
+		}
+		config.lagAlertThreshold = val
+	} else {
+		config.lagAlertThreshold = big.NewInt(DefaultLagAlertThreshold)
+	}
+	delete(chainCfg.Opts, LagAlertThresholdOpt)
+
+	if interval, ok := chainCfg.Opts[WatchdogIntervalOpt]; ok && interval != "" {
+		val, err := time.ParseDuration(interval)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", WatchdogIntervalOpt)
+		}
+		// Polling only sends a heartbeat between retries, so a shorter interval would always fire
+		if val <= BlockRetryInterval {
+			return nil, fmt.Errorf("%s must be longer than the block retry interval of %s", WatchdogIntervalOpt, BlockRetryInterval)
+		}
+		config.watchdogInterval = val
+	} else {
+		config.watchdogInterval = DefaultWatchdogInterval
+	}
+	delete(chainCfg.Opts, WatchdogIntervalOpt)
+
+	if endpoint, ok := chainCfg.Opts[IpfsEndpointOpt]; ok {
+		config.ipfsEndpoint = endpoint
+		delete(chainCfg.Opts, IpfsEndpointOpt)
+	}
+
+	if max, ok := chainCfg.Opts[MaxOnChainBytesOpt]; ok && max != "" {
+		val, err := strconv.Atoi(max)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", MaxOnChainBytesOpt)
+		}
+		config.maxOnChainBytes = val
+	} else {
+		config.maxOnChainBytes = DefaultMaxOnChainBytes
+	}
+	delete(chainCfg.Opts, MaxOnChainBytesOpt)
+
+	if max, ok := chainCfg.Opts[MaxMessageBytesOpt]; ok && max != "" {
+		val, err := strconv.ParseUint(max, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", MaxMessageBytesOpt)
+		}
+		config.maxMessageBytes = val
+	} else {
+		config.maxMessageBytes = DefaultMaxMessageBytes
+	}
+	delete(chainCfg.Opts, MaxMessageBytesOpt)
+
+	if threshold, ok := chainCfg.Opts[CompressionThreshold]; ok && threshold != "" {
+		val, err := strconv.Atoi(threshold)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", CompressionThreshold)
+		}
+		config.compressionThreshold = val
+	} else {
+		config.compressionThreshold = DefaultCompressionThreshold
+	}
+	delete(chainCfg.Opts, CompressionThreshold)
+
+	if noCompression, ok := chainCfg.Opts[NoCompressionOpt]; ok && noCompression == "true" {
+		config.noCompression = true
+		delete(chainCfg.Opts, NoCompressionOpt)
+	} else if noCompression, ok := chainCfg.Opts[NoCompressionOpt]; ok && noCompression == "false" {
+		config.noCompression = false
+		delete(chainCfg.Opts, NoCompressionOpt)
+	}
+
+	if chainId, ok := chainCfg.Opts[EvmChainIdOpt]; ok && chainId != "" {
+		val, pass := big.NewInt(0).SetString(chainId, 10)
+		if !pass || val.Sign() <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", EvmChainIdOpt)
+		}
+		config.evmChainId = val
+	}
+	delete(chainCfg.Opts, EvmChainIdOpt)
+
+	if threshold, ok := chainCfg.Opts[ClaimThresholdOpt]; ok && threshold != "" {
+		val, pass := big.NewInt(0).SetString(threshold, 10)
+		if !pass || val.Sign() < 0 {
+			return nil, fmt.Errorf("unable to parse %s", ClaimThresholdOpt)
+		}
+		config.claimThreshold = val
+	}
+	delete(chainCfg.Opts, ClaimThresholdOpt)
+
+	if batch, ok := chainCfg.Opts[UseBatchRPCOpt]; ok && batch == "true" {
+		config.useBatchRPC = true
+		delete(chainCfg.Opts, UseBatchRPCOpt)
+	} else if batch, ok := chainCfg.Opts[UseBatchRPCOpt]; ok && batch == "false" {
+		config.useBatchRPC = false
+		delete(chainCfg.Opts, UseBatchRPCOpt)
+	}
+
+	if safe, ok := chainCfg.Opts[SafeAddressOpt]; ok {
+		config.safeAddress = common.HexToAddress(safe)
+		delete(chainCfg.Opts, SafeAddressOpt)
+	}
+
+	if url, ok := chainCfg.Opts[SafeTxServiceURLOpt]; ok {
+		config.safeTxServiceURL = url
+		delete(chainCfg.Opts, SafeTxServiceURLOpt)
+	}
+
+	if registry, ok := chainCfg.Opts[ENSRegistryOpt]; ok && registry != "" {
+		config.ensRegistry = common.HexToAddress(registry)
+	} else {
+		config.ensRegistry = connection.DefaultENSRegistry
+	}
+	delete(chainCfg.Opts, ENSRegistryOpt)
+
+	if ttl, ok := chainCfg.Opts[ENSCacheTTLOpt]; ok && ttl != "" {
+		val, err := time.ParseDuration(ttl)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", ENSCacheTTLOpt)
+		}
+		config.ensCacheTTL = val
+	} else {
+		config.ensCacheTTL = connection.DefaultENSCacheTTL
+	}
+	delete(chainCfg.Opts, ENSCacheTTLOpt)
+
+	if ids, ok := chainCfg.Opts[PriorityResourceOpt]; ok && ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			rId, err := parseResourceId(strings.TrimSpace(id))
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s, %w", PriorityResourceOpt, err)
+			}
+			config.priorityResourceIds = append(config.priorityResourceIds, rId)
+		}
+	}
+	delete(chainCfg.Opts, PriorityResourceOpt)
+
+	if max, ok := chainCfg.Opts[MaxProposalsOpt]; ok && max != "" {
+		val, err := strconv.Atoi(max)
+		if err != nil || val < 1 {
+			return nil, fmt.Errorf("unable to parse %s", MaxProposalsOpt)
+		}
+		config.maxConcurrentProposals = val
+	} else {
+		config.maxConcurrentProposals = DefaultMaxConcurrentProposals
+	}
+	delete(chainCfg.Opts, MaxProposalsOpt)
+
+	if blocks, ok := chainCfg.Opts[SkipBlocksOpt]; ok && blocks != "" {
+		for _, block := range strings.Split(blocks, ",") {
+			val, err := strconv.ParseUint(strings.TrimSpace(block), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s, %w", SkipBlocksOpt, err)
+			}
+			config.skipBlocks = append(config.skipBlocks, val)
+		}
+	}
+	delete(chainCfg.Opts, SkipBlocksOpt)
+
+	if backend, ok := chainCfg.Opts[SimulatorBackendOpt]; ok && backend != "" {
+		if backend != tenderly.Backend {
+			return nil, fmt.Errorf("unknown %s: %s", SimulatorBackendOpt, backend)
+		}
+		config.simulatorBackend = backend
+	}
+	delete(chainCfg.Opts, SimulatorBackendOpt)
+
+	if project, ok := chainCfg.Opts[TenderlyProjectOpt]; ok {
+		config.tenderlyProject = project
+		delete(chainCfg.Opts, TenderlyProjectOpt)
+	}
+
+	if key, ok := chainCfg.Opts[TenderlyAccessKeyOpt]; ok {
+		config.tenderlyAccessKey = key
+		delete(chainCfg.Opts, TenderlyAccessKeyOpt)
+	}
+
+	if timeout, ok := chainCfg.Opts[MempoolTimeoutOpt]; ok && timeout != "" {
+		val, err := time.ParseDuration(timeout)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", MempoolTimeoutOpt)
+		}
+		config.mempoolConfirmTimeout = val
+	} else {
+		config.mempoolConfirmTimeout = DefaultMempoolConfirmTimeout
+	}
+	delete(chainCfg.Opts, MempoolTimeoutOpt)
+
+	if max, ok := chainCfg.Opts[MaxBlocksPerPollOpt]; ok && max != "" {
+		val, err := strconv.ParseUint(max, 10, 64)
+		if err != nil || val == 0 {
+			return nil, fmt.Errorf("unable to parse %s", MaxBlocksPerPollOpt)
+		}
+		config.maxBlocksPerPoll = val
+	} else {
+		config.maxBlocksPerPoll = DefaultMaxBlocksPerPoll
+	}
+	delete(chainCfg.Opts, MaxBlocksPerPollOpt)
+
+	if register, ok := chainCfg.Opts[AutoRegisterOpt]; ok && register == "true" {
+		config.autoRegister = true
+		delete(chainCfg.Opts, AutoRegisterOpt)
+	} else if register, ok := chainCfg.Opts[AutoRegisterOpt]; ok && register == "false" {
+		config.autoRegister = false
+		delete(chainCfg.Opts, AutoRegisterOpt)
+	}
+
+	if path, ok := chainCfg.Opts[GasConfigFileOpt]; ok {
+		config.gasConfigFile = path
+		delete(chainCfg.Opts, GasConfigFileOpt)
+	}
+
+	if min, ok := chainCfg.Opts[MinPeerCountOpt]; ok && min != "" {
+		val, err := strconv.ParseUint(min, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", MinPeerCountOpt)
+		}
+		config.minPeerCount = val
+	} else {
+		config.minPeerCount = DefaultMinPeerCount
+	}
+	delete(chainCfg.Opts, MinPeerCountOpt)
+
+	if interval, ok := chainCfg.Opts[PeerCheckIntervalOpt]; ok && interval != "" {
+		val, err := time.ParseDuration(interval)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", PeerCheckIntervalOpt)
+		}
+		config.peerCheckInterval = val
+	} else {
+		config.peerCheckInterval = DefaultPeerCheckInterval
+	}
+	delete(chainCfg.Opts, PeerCheckIntervalOpt)
+
+	if watch, ok := chainCfg.Opts[WatchUnlocksOpt]; ok && watch == "true" {
+		config.watchUnlocks = true
+		delete(chainCfg.Opts, WatchUnlocksOpt)
+	} else if watch, ok := chainCfg.Opts[WatchUnlocksOpt]; ok && watch == "false" {
+		config.watchUnlocks = false
+		delete(chainCfg.Opts, WatchUnlocksOpt)
+	}
+
+	if path, ok := chainCfg.Opts[FeeLogPathOpt]; ok {
+		config.feeLogPath = path
+		delete(chainCfg.Opts, FeeLogPathOpt)
+	}
+
+	if path, ok := chainCfg.Opts[TokenMapFileOpt]; ok {
+		config.tokenMapFile = path
+		delete(chainCfg.Opts, TokenMapFileOpt)
+	}
+
+	if vault, ok := chainCfg.Opts[FireblocksVaultIdOpt]; ok {
+		if derivationPath != "" {
+			return nil, fmt.Errorf("%s cannot be used with a derivation path", FireblocksVaultIdOpt)
+		}
+		config.fireblocksVaultId = vault
+		delete(chainCfg.Opts, FireblocksVaultIdOpt)
+	}
+
+	if url, ok := chainCfg.Opts[FireblocksBaseURLOpt]; ok {
+		config.fireblocksBaseURL = url
+		delete(chainCfg.Opts, FireblocksBaseURLOpt)
+	}
+
+	if key, ok := chainCfg.Opts[FireblocksAPIKeyOpt]; ok {
+		config.fireblocksAPIKey = key
+		delete(chainCfg.Opts, FireblocksAPIKeyOpt)
+	}
+
+	if path, ok := chainCfg.Opts[FireblocksSecretOpt]; ok {
+		config.fireblocksSecretKey = path
+		delete(chainCfg.Opts, FireblocksSecretOpt)
+	}
+
+	if require, ok := chainCfg.Opts[RequireSignedOpt]; ok && require == "true" {
+		config.requireSignedMessages = true
+		delete(chainCfg.Opts, RequireSignedOpt)
+	} else if require, ok := chainCfg.Opts[RequireSignedOpt]; ok && require == "false" {
+		config.requireSignedMessages = false
+		delete(chainCfg.Opts, RequireSignedOpt)
+	}
+
+	if forward, ok := chainCfg.Opts[ForwardDepositsOpt]; ok && forward == "true" {
+		config.forwardDeposits = true
+		delete(chainCfg.Opts, ForwardDepositsOpt)
+	} else if forward, ok := chainCfg.Opts[ForwardDepositsOpt]; ok && forward == "false" {
+		config.forwardDeposits = false
+		delete(chainCfg.Opts, ForwardDepositsOpt)
+	}
+
+	if interval, ok := chainCfg.Opts[HealthCheckOpt]; ok && interval != "" {
+		val, err := time.ParseDuration(interval)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", HealthCheckOpt)
+		}
+		config.healthCheckInterval = val
+	} else {
+		config.healthCheckInterval = DefaultHealthCheckInterval
+	}
+	delete(chainCfg.Opts, HealthCheckOpt)
+
+	if age, ok := chainCfg.Opts[MaxBlockAgeOpt]; ok && age != "" {
+		val, err := time.ParseDuration(age)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", MaxBlockAgeOpt)
+		}
+		config.maxBlockAge = val
+	} else {
+		config.maxBlockAge = DefaultMaxBlockAge
+	}
+	delete(chainCfg.Opts, MaxBlockAgeOpt)
+
+	if fetch, ok := chainCfg.Opts[FetchURIOpt]; ok && fetch == "true" {
+		config.fetchURI = true
+		delete(chainCfg.Opts, FetchURIOpt)
+	} else if fetch, ok := chainCfg.Opts[FetchURIOpt]; ok && fetch == "false" {
+		config.fetchURI = false
+		delete(chainCfg.Opts, FetchURIOpt)
+	}
+
+	if pin, ok := chainCfg.Opts[PinTokenURIsOpt]; ok && pin == "true" {
+		config.pinTokenURIs = true
+		delete(chainCfg.Opts, PinTokenURIsOpt)
+	} else if pin, ok := chainCfg.Opts[PinTokenURIsOpt]; ok && pin == "false" {
+		config.pinTokenURIs = false
+		delete(chainCfg.Opts, PinTokenURIsOpt)
+	}
+
+	if threshold, ok := chainCfg.Opts[BreakerThresholdOpt]; ok && threshold != "" {
+		val, err := strconv.Atoi(threshold)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", BreakerThresholdOpt)
+		}
+		config.circuitBreakerThreshold = val
+	}
+	delete(chainCfg.Opts, BreakerThresholdOpt)
+
+	if cooldown, ok := chainCfg.Opts[BreakerCooldownOpt]; ok && cooldown != "" {
+		val, err := time.ParseDuration(cooldown)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", BreakerCooldownOpt)
+		}
+		config.circuitBreakerCooldown = val
+	} else {
+		config.circuitBreakerCooldown = DefaultCircuitBreakerCooldown
+	}
+	delete(chainCfg.Opts, BreakerCooldownOpt)
+
+	if wait, ok := chainCfg.Opts[WaitForSyncOpt]; ok && wait == "true" {
+		config.waitForSync = true
+		delete(chainCfg.Opts, WaitForSyncOpt)
+	} else if wait, ok := chainCfg.Opts[WaitForSyncOpt]; ok && wait == "false" {
+		config.waitForSync = false
+		delete(chainCfg.Opts, WaitForSyncOpt)
+	}
+
+	if max, ok := chainCfg.Opts[MaxAmountPerBlockOpt]; ok && max != "" {
+		val, pass := big.NewInt(0).SetString(max, 10)
+		if !pass || val.Sign() < 0 {
+			return nil, fmt.Errorf("unable to parse %s", MaxAmountPerBlockOpt)
+		}
+		config.maxAmountPerBlock = val
+	}
+	delete(chainCfg.Opts, MaxAmountPerBlockOpt)
+
+	if max, ok := chainCfg.Opts[MaxAddressRateOpt]; ok && max != "" {
+		val, err := strconv.Atoi(max)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("unable to parse %s", MaxAddressRateOpt)
+		}
+		config.maxDepositsPerAddressPerMinute = val
+	}
+	delete(chainCfg.Opts, MaxAddressRateOpt)
+
+	if attach, ok := chainCfg.Opts[AttachProofOpt]; ok && attach == "true" {
+		config.attachStorageProof = true
+		delete(chainCfg.Opts, AttachProofOpt)
+	} else if attach, ok := chainCfg.Opts[AttachProofOpt]; ok && attach == "false" {
+		config.attachStorageProof = false
+		delete(chainCfg.Opts, AttachProofOpt)
+	}
+
+	if forwarder, ok := chainCfg.Opts[ForwarderAddressOpt]; ok {
+		// Forward requests are signed with the keystore key, which Fireblocks chains do not have
+		if config.fireblocksVaultId != "" {
+			return nil, fmt.Errorf("%s cannot be used with %s", ForwarderAddressOpt, FireblocksVaultIdOpt)
+		}
+		config.forwarderAddress = common.HexToAddress(forwarder)
+		delete(chainCfg.Opts, ForwarderAddressOpt)
+	}
+
+	if min, ok := chainCfg.Opts[MinBalanceOpt]; ok && min != "" {
+		val, pass := big.NewInt(0).SetString(min, 10)
+		if !pass || val.Sign() < 0 {
+			return nil, fmt.Errorf("unable to parse %s", MinBalanceOpt)
+		}
+		config.minRelayerBalance = val
+	} else {
+		config.minRelayerBalance = big.NewInt(0)
+	}
+	delete(chainCfg.Opts, MinBalanceOpt)
+
+	if interval, ok := chainCfg.Opts[BalanceIntervalOpt]; ok && interval != "" {
+		val, err := time.ParseDuration(interval)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", BalanceIntervalOpt)
+		}
+		config.balanceCheckInterval = val
+	} else {
+		config.balanceCheckInterval = DefaultBalanceCheckInterval
+	}
+	delete(chainCfg.Opts, BalanceIntervalOpt)
+
 	if len(chainCfg.Opts) != 0 {
 		return nil, fmt.Errorf("unknown Opts Encountered: %#v", chainCfg.Opts)
 	}
 
+	err := validateConfigOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
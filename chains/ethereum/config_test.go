@@ -4,12 +4,17 @@
 package ethereum
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/ChainBridge/lock"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
-	"github.com/ChainSafe/chainbridge-utils/core"
+	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -24,10 +29,11 @@ func TestParseChainConfig(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":             "0x1234",
-			"erc20Handler":       "0x1234",
-			"erc721Handler":      "0x1234",
-			"genericHandler":     "0x1234",
+			"bridge":             "0x0000000000000000000000000000000000001234",
+			"erc20Handler":       "0x0000000000000000000000000000000000001234",
+			"erc721Handler":      "0x0000000000000000000000000000000000001234",
+			"genericHandler":     "0x0000000000000000000000000000000000001234",
+			"feeHandler":         "0x0000000000000000000000000000000000005678",
 			"gasLimit":           "10",
 			"gasMultiplier":      "1",
 			"maxGasPrice":        "20",
@@ -56,6 +62,7 @@ func TestParseChainConfig(t *testing.T) {
 		erc20HandlerContract:   common.HexToAddress("0x1234"),
 		erc721HandlerContract:  common.HexToAddress("0x1234"),
 		genericHandlerContract: common.HexToAddress("0x1234"),
+		feeHandlerContract:     common.HexToAddress("0x5678"),
 		gasLimit:               big.NewInt(10),
 		maxGasPrice:            big.NewInt(20),
 		minGasPrice:            big.NewInt(0),
@@ -65,6 +72,26 @@ func TestParseChainConfig(t *testing.T) {
 		blockConfirmations:     big.NewInt(50),
 		egsApiKey:              "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -88,10 +115,10 @@ func TestParseChainConfigHex(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":             "0x1234",
-			"erc20Handler":       "0x1234",
-			"erc721Handler":      "0x1234",
-			"genericHandler":     "0x1234",
+			"bridge":             "0x0000000000000000000000000000000000001234",
+			"erc20Handler":       "0x0000000000000000000000000000000000001234",
+			"erc721Handler":      "0x0000000000000000000000000000000000001234",
+			"genericHandler":     "0x0000000000000000000000000000000000001234",
 			"gasLimit":           commonHexValue,
 			"gasMultiplier":      "1",
 			"maxGasPrice":        commonHexValue,
@@ -129,6 +156,26 @@ func TestParseChainConfigHex(t *testing.T) {
 		blockConfirmations:     big.NewInt(50),
 		egsApiKey:              "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -147,10 +194,10 @@ func TestParseChainConfigWithNoBlockConfirmations(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":         "0x1234",
-			"erc20Handler":   "0x1234",
-			"erc721Handler":  "0x1234",
-			"genericHandler": "0x1234",
+			"bridge":         "0x0000000000000000000000000000000000001234",
+			"erc20Handler":   "0x0000000000000000000000000000000000001234",
+			"erc721Handler":  "0x0000000000000000000000000000000000001234",
+			"genericHandler": "0x0000000000000000000000000000000000001234",
 			"gasLimit":       "10",
 			"gasMultiplier":  "1",
 			"maxGasPrice":    "20",
@@ -185,6 +232,26 @@ func TestParseChainConfigWithNoBlockConfirmations(t *testing.T) {
 		blockConfirmations:     big.NewInt(DefaultBlockConfirmations),
 		egsApiKey:              "",
 		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -203,8 +270,8 @@ func TestChainConfigOneContract(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":        "0x1234",
-			"erc20Handler":  "0x1234",
+			"bridge":        "0x0000000000000000000000000000000000001234",
+			"erc20Handler":  "0x0000000000000000000000000000000000001234",
 			"gasLimit":      "10",
 			"maxGasPrice":   "20",
 			"minGasPrice":   "0",
@@ -221,22 +288,42 @@ func TestChainConfigOneContract(t *testing.T) {
 	}
 
 	expected := Config{
-		name:                 "chain",
-		id:                   1,
-		endpoint:             "endpoint",
-		from:                 "0x0",
-		keystorePath:         "./keys",
-		bridgeContract:       common.HexToAddress("0x1234"),
-		erc20HandlerContract: common.HexToAddress("0x1234"),
-		gasLimit:             big.NewInt(10),
-		maxGasPrice:          big.NewInt(20),
-		minGasPrice:          big.NewInt(0),
-		gasMultiplier:        big.NewFloat(1),
-		http:                 true,
-		startBlock:           big.NewInt(10),
-		blockConfirmations:   big.NewInt(DefaultBlockConfirmations),
-		egsApiKey:            "",
-		egsSpeed:             "fast",
+		name:                   "chain",
+		id:                     1,
+		endpoint:               "endpoint",
+		from:                   "0x0",
+		keystorePath:           "./keys",
+		bridgeContract:         common.HexToAddress("0x1234"),
+		erc20HandlerContract:   common.HexToAddress("0x1234"),
+		gasLimit:               big.NewInt(10),
+		maxGasPrice:            big.NewInt(20),
+		minGasPrice:            big.NewInt(0),
+		gasMultiplier:          big.NewFloat(1),
+		http:                   true,
+		startBlock:             big.NewInt(10),
+		blockConfirmations:     big.NewInt(DefaultBlockConfirmations),
+		egsApiKey:              "",
+		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -279,6 +366,46 @@ func TestRequiredOpts(t *testing.T) {
 
 }
 
+// TestChainConfigOptsMissing ensures each required opt produces a MissingOptError when absent or empty
+func TestChainConfigOptsMissing(t *testing.T) {
+	required := []string{BridgeOpt}
+
+	for _, opt := range required {
+		for _, value := range []*string{nil, new(string)} {
+			opts := map[string]string{
+				"bridge":         "0x0000000000000000000000000000000000001234",
+				"erc20Handler":   "0x0000000000000000000000000000000000001234",
+				"erc721Handler":  "0x0000000000000000000000000000000000001234",
+				"genericHandler": "0x0000000000000000000000000000000000001234",
+			}
+			if value == nil {
+				delete(opts, opt)
+			} else {
+				opts[opt] = *value
+			}
+
+			input := core.ChainConfig{
+				Name:         "chain",
+				Id:           1,
+				Endpoint:     "endpoint",
+				From:         "0x0",
+				KeystorePath: "./keys",
+				Opts:         opts,
+			}
+
+			_, err := parseChainConfig(&input)
+
+			var missingErr *MissingOptError
+			if !errors.As(err, &missingErr) {
+				t.Fatalf("expected MissingOptError for opt %s, got: %v", opt, err)
+			}
+			if missingErr.Opt != opt {
+				t.Fatalf("expected missing opt %s, got: %s", opt, missingErr.Opt)
+			}
+		}
+	}
+}
+
 func TestExtraOpts(t *testing.T) {
 	input := core.ChainConfig{
 		Name:         "chain",
@@ -288,7 +415,7 @@ func TestExtraOpts(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":        "0x1234",
+			"bridge":        "0x0000000000000000000000000000000000001234",
 			"gasLimit":      "10",
 			"maxGasPrice":   "20",
 			"gasMultiplier": "1",
@@ -313,10 +440,10 @@ func TestEthGasStationDefaultSpeed(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":         "0x1234",
-			"erc20Handler":   "0x1234",
-			"erc721Handler":  "0x1234",
-			"genericHandler": "0x1234",
+			"bridge":         "0x0000000000000000000000000000000000001234",
+			"erc20Handler":   "0x0000000000000000000000000000000000001234",
+			"erc721Handler":  "0x0000000000000000000000000000000000001234",
+			"genericHandler": "0x0000000000000000000000000000000000001234",
 			"gasLimit":       "10",
 			"gasMultiplier":  "1",
 			"maxGasPrice":    "20",
@@ -353,6 +480,26 @@ func TestEthGasStationDefaultSpeed(t *testing.T) {
 		blockConfirmations:     big.NewInt(DefaultBlockConfirmations),
 		egsApiKey:              "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -369,10 +516,10 @@ func TestEthGasStationCustomSpeed(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":         "0x1234",
-			"erc20Handler":   "0x1234",
-			"erc721Handler":  "0x1234",
-			"genericHandler": "0x1234",
+			"bridge":         "0x0000000000000000000000000000000000001234",
+			"erc20Handler":   "0x0000000000000000000000000000000000001234",
+			"erc721Handler":  "0x0000000000000000000000000000000000001234",
+			"genericHandler": "0x0000000000000000000000000000000000001234",
 			"gasLimit":       "10",
 			"gasMultiplier":  "1",
 			"maxGasPrice":    "20",
@@ -409,6 +556,26 @@ func TestEthGasStationCustomSpeed(t *testing.T) {
 		blockConfirmations:     big.NewInt(DefaultBlockConfirmations),
 		egsApiKey:              "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 		egsSpeed:               "average",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
@@ -425,10 +592,10 @@ func TestEthGasStationInvalidSpeed(t *testing.T) {
 		KeystorePath: "./keys",
 		Insecure:     false,
 		Opts: map[string]string{
-			"bridge":         "0x1234",
-			"erc20Handler":   "0x1234",
-			"erc721Handler":  "0x1234",
-			"genericHandler": "0x1234",
+			"bridge":         "0x0000000000000000000000000000000000001234",
+			"erc20Handler":   "0x0000000000000000000000000000000000001234",
+			"erc721Handler":  "0x0000000000000000000000000000000000001234",
+			"genericHandler": "0x0000000000000000000000000000000000001234",
 			"gasLimit":       "10",
 			"gasMultiplier":  "1",
 			"maxGasPrice":    "20",
@@ -465,9 +632,1147 @@ func TestEthGasStationInvalidSpeed(t *testing.T) {
 		blockConfirmations:     big.NewInt(DefaultBlockConfirmations),
 		egsApiKey:              "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 		egsSpeed:               "fast",
+		connectTimeout:         DefaultConnectTimeout,
+		lockBackend:            lock.NoneBackend,
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		minPeerCount:           DefaultMinPeerCount,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		maxBlockAge:            DefaultMaxBlockAge,
+		circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+		minRelayerBalance:      big.NewInt(0),
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if !reflect.DeepEqual(&expected, out) {
 		t.Fatalf("Output not expected.\n\tExpected: %#v\n\tGot: %#v\n", &expected, out)
 	}
 }
+
+func TestChainConfigConnectTimeout(t *testing.T) {
+	newInput := func(timeout string) *core.ChainConfig {
+		return &core.ChainConfig{
+			Name:         "chain",
+			Id:           1,
+			Endpoint:     "endpoint",
+			From:         "0x0",
+			KeystorePath: "./keys",
+			Opts: map[string]string{
+				"bridge":         "0x0000000000000000000000000000000000001234",
+				"connectTimeout": timeout,
+			},
+		}
+	}
+
+	out, err := parseChainConfig(newInput("5s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.connectTimeout != 5*time.Second {
+		t.Fatalf("expected 5s connect timeout, got: %s", out.connectTimeout)
+	}
+
+	for _, invalid := range []string{"5", "-1s", "soon"} {
+		if _, err = parseChainConfig(newInput(invalid)); err == nil {
+			t.Fatalf("expected error for connectTimeout %q", invalid)
+		}
+	}
+}
+
+func TestChainConfigLockBackend(t *testing.T) {
+	newInput := func(opts map[string]string) *core.ChainConfig {
+		opts["bridge"] = "0x0000000000000000000000000000000000001234"
+		return &core.ChainConfig{
+			Name:         "chain",
+			Id:           1,
+			Endpoint:     "endpoint",
+			From:         "0x0",
+			KeystorePath: "./keys",
+			Opts:         opts,
+		}
+	}
+
+	out, err := parseChainConfig(newInput(map[string]string{"lockBackend": "redis", "lockUrl": "redis://localhost:6380"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.lockBackend != lock.RedisBackend || out.lockUrl != "redis://localhost:6380" {
+		t.Fatalf("unexpected lock config, backend: %s url: %s", out.lockBackend, out.lockUrl)
+	}
+	if out.lockPassword != "" || out.lockTLS || out.lockFailOpen {
+		t.Fatalf("unexpected lock defaults, tls: %t failOpen: %t", out.lockTLS, out.lockFailOpen)
+	}
+
+	out, err = parseChainConfig(newInput(map[string]string{"lockBackend": "redis", "lockPassword": "secret", "lockTLS": "true", "lockFailOpen": "true"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.lockPassword != "secret" || !out.lockTLS || !out.lockFailOpen {
+		t.Fatalf("unexpected lock config, tls: %t failOpen: %t", out.lockTLS, out.lockFailOpen)
+	}
+
+	out, err = parseChainConfig(newInput(map[string]string{"lockBackend": "file", "lockPath": "./locks"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.lockBackend != lock.FileBackend || out.lockPath != "./locks" {
+		t.Fatalf("unexpected lock config, backend: %s path: %s", out.lockBackend, out.lockPath)
+	}
+
+	_, err = parseChainConfig(newInput(map[string]string{"lockBackend": "etcd"}))
+	if err == nil {
+		t.Fatal("expected error for unknown lock backend")
+	}
+}
+
+func TestChainConfigTrustlessMode(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "trustlessMode": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.trustlessMode {
+		t.Fatal("expected trustlessMode to be enabled")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "trustlessMode": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid trustlessMode")
+	}
+}
+
+func TestChainConfigLagAlertThreshold(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "lagAlertThreshold": "500"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.lagAlertThreshold.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("expected lagAlertThreshold of 500, got: %s", out.lagAlertThreshold)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "lagAlertThreshold": "-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for negative lagAlertThreshold")
+	}
+}
+
+func TestChainConfigWatchdogInterval(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "watchdogInterval": "2m"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.watchdogInterval != 2*time.Minute {
+		t.Fatalf("expected watchdogInterval of 2m, got: %s", out.watchdogInterval)
+	}
+
+	for _, interval := range []string{"0s", "invalid", BlockRetryInterval.String()} {
+		input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "watchdogInterval": interval}
+		_, err = parseChainConfig(&input)
+		if err == nil {
+			t.Fatalf("expected error for watchdogInterval of %s", interval)
+		}
+	}
+}
+
+func TestChainConfigIpfs(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "ipfsEndpoint": "http://localhost:5001", "maxOnChainBytes": "256"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.ipfsEndpoint != "http://localhost:5001" {
+		t.Fatalf("unexpected ipfsEndpoint: %s", out.ipfsEndpoint)
+	}
+	if out.maxOnChainBytes != 256 {
+		t.Fatalf("expected maxOnChainBytes of 256, got: %d", out.maxOnChainBytes)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxOnChainBytes": "-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for negative maxOnChainBytes")
+	}
+}
+
+func TestChainConfigDeployMissing(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"deployMissing": "true"},
+	}
+
+	// The bridge is not required as it can be deployed
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.deployMissing || out.bridgeContract != utils.ZeroAddress {
+		t.Fatalf("unexpected config: deployMissing %t, bridge %s", out.deployMissing, out.bridgeContract.Hex())
+	}
+
+	input.Opts = map[string]string{"deployMissing": "false"}
+	_, err = parseChainConfig(&input)
+	var missing *MissingOptError
+	if !errors.As(err, &missing) || missing.Opt != BridgeOpt {
+		t.Fatalf("expected missing bridge error, got: %v", err)
+	}
+}
+
+func TestChainConfigCompression(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "compressionThreshold": "512", "noCompression": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.compressionThreshold != 512 || !out.noCompression {
+		t.Fatalf("unexpected config: compressionThreshold %d, noCompression %t", out.compressionThreshold, out.noCompression)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "compressionThreshold": "none"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid compressionThreshold")
+	}
+}
+
+func TestChainConfigEvmChainId(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "evmChainId": "1337"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.evmChainId.Cmp(big.NewInt(1337)) != 0 {
+		t.Fatalf("expected evmChainId 1337, got: %s", out.evmChainId)
+	}
+
+	for _, invalid := range []string{"0", "-5", "goerli"} {
+		input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "evmChainId": invalid}
+		_, err = parseChainConfig(&input)
+		if err == nil {
+			t.Fatalf("expected error for evmChainId %s", invalid)
+		}
+	}
+}
+
+func TestChainConfigClaimThreshold(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.claimThreshold != nil {
+		t.Fatalf("expected no claimThreshold, got: %s", out.claimThreshold)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "claimThreshold": "1000000000000000000"}
+	out, err = parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.claimThreshold.Cmp(big.NewInt(1000000000000000000)) != 0 {
+		t.Fatalf("expected claimThreshold 1000000000000000000, got: %s", out.claimThreshold)
+	}
+
+	for _, invalid := range []string{"-1", "1.5", "0x10"} {
+		input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "claimThreshold": invalid}
+		_, err = parseChainConfig(&input)
+		if err == nil {
+			t.Fatalf("expected error for claimThreshold %s", invalid)
+		}
+	}
+}
+
+func TestChainConfigUseBatchRPC(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "useBatchRPC": "false"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.useBatchRPC {
+		t.Fatal("expected useBatchRPC to be disabled")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "useBatchRPC": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for useBatchRPC yes")
+	}
+}
+
+func TestChainConfigSafe(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts: map[string]string{
+			"bridge":           "0x0000000000000000000000000000000000001234",
+			"safeAddress":      "0x0000000000000000000000000000000000005678",
+			"safeTxServiceURL": "https://safe-transaction.gnosis.io",
+		},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.safeAddress != common.HexToAddress("0x0000000000000000000000000000000000005678") || out.safeTxServiceURL != "https://safe-transaction.gnosis.io" {
+		t.Fatalf("unexpected safe config: %s %s", out.safeAddress.Hex(), out.safeTxServiceURL)
+	}
+}
+
+func TestChainConfigDerivationPath(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0xff93B45308FD417dF303D6515aB04D9e89a750Ca/m/44'/60'/0'/0/2",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.from != "0xff93B45308FD417dF303D6515aB04D9e89a750Ca" || out.derivationPath != "m/44'/60'/0'/0/2" {
+		t.Fatalf("unexpected from: %s %s", out.from, out.derivationPath)
+	}
+
+	input.From = "0xff93B45308FD417dF303D6515aB04D9e89a750Ca/m/44'/x"
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected an invalid derivation path to fail")
+	}
+}
+
+func TestChainConfigPriorityResourceIds(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts: map[string]string{
+			"bridge":              "0x0000000000000000000000000000000000001234",
+			"priorityResourceIds": "0x000000000000000000000000000000c76ebe4a02bbc34786d860b355f5a5ce00, 0x000000000000000000000000000000e389d61c11e5fe32ec1735b3cd38c69501",
+		},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []msg.ResourceId{
+		msg.ResourceIdFromSlice(common.FromHex("0x000000000000000000000000000000c76ebe4a02bbc34786d860b355f5a5ce00")),
+		msg.ResourceIdFromSlice(common.FromHex("0x000000000000000000000000000000e389d61c11e5fe32ec1735b3cd38c69501")),
+	}
+	if !reflect.DeepEqual(out.priorityResourceIds, expected) {
+		t.Fatalf("unexpected priorityResourceIds: %x", out.priorityResourceIds)
+	}
+
+	input.Opts = map[string]string{
+		"bridge":              "0x0000000000000000000000000000000000001234",
+		"priorityResourceIds": "0x1234",
+	}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected a short resource ID to fail")
+	}
+}
+
+func TestChainConfigMaxConcurrentProposals(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxConcurrentProposals": "5"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.maxConcurrentProposals != 5 {
+		t.Fatalf("unexpected maxConcurrentProposals: %d", out.maxConcurrentProposals)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxConcurrentProposals": "0"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for maxConcurrentProposals 0")
+	}
+}
+
+func TestChainConfigSkipBlocks(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "skipBlocks": "5, 10,11"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out.skipBlocks, []uint64{5, 10, 11}) {
+		t.Fatalf("unexpected skipBlocks: %v", out.skipBlocks)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "skipBlocks": "5,-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for a negative block")
+	}
+}
+
+func TestChainConfigMaxMessageBytes(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxMessageBytes": "2048"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.maxMessageBytes != 2048 {
+		t.Fatalf("expected maxMessageBytes of 2048, got: %d", out.maxMessageBytes)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxMessageBytes": "-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for negative maxMessageBytes")
+	}
+}
+
+func TestChainConfigSimulator(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts: map[string]string{
+			"bridge":              "0x0000000000000000000000000000000000001234",
+			"simulatorBackend":    "tenderly",
+			"tenderlyProjectSlug": "account/project",
+			"tenderlyAccessKey":   "key",
+		},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.simulatorBackend != "tenderly" || out.tenderlyProject != "account/project" || out.tenderlyAccessKey != "key" {
+		t.Fatalf("unexpected simulator config: %s %s %s", out.simulatorBackend, out.tenderlyProject, out.tenderlyAccessKey)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "simulatorBackend": "tenderly"}
+	_, err = parseChainConfig(&input)
+	var invalid *ConfigValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 2 {
+		t.Fatalf("expected validation errors for missing tenderly opts, got: %v", err)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "simulatorBackend": "other"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for unknown simulatorBackend")
+	}
+}
+
+func TestChainConfigMempoolConfirmTimeout(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "mempoolConfirmTimeout": "0s"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.mempoolConfirmTimeout != 0 {
+		t.Fatalf("expected mempool confirmation to be disabled, got: %s", out.mempoolConfirmTimeout)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "mempoolConfirmTimeout": "-1s"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for negative mempoolConfirmTimeout")
+	}
+}
+
+func TestOptsSchema(t *testing.T) {
+	fields := make(map[string]core.OptsField)
+	for _, field := range core.OptsSchema()[ChainType] {
+		fields[field.Key] = field
+	}
+
+	// Opts checked by parseChainConfig or the validator, which are the required ones
+	for _, opt := range []string{BridgeOpt} {
+		if field, ok := fields[opt]; !ok || !field.Required {
+			t.Errorf("expected required opt %s in schema, got: %+v", opt, field)
+		}
+	}
+	for _, opt := range []string{Erc20HandlerOpt, GasLimitOpt, MempoolTimeoutOpt} {
+		if field, ok := fields[opt]; !ok || field.Required {
+			t.Errorf("expected optional opt %s in schema, got: %+v", opt, field)
+		}
+	}
+
+	// Setting every opt to its documented default must not change the config
+	base := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{BridgeOpt: "0x0000000000000000000000000000000000001234"},
+	}
+	expected, err := parseChainConfig(&base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withDefaults := base
+	withDefaults.Opts = map[string]string{BridgeOpt: "0x0000000000000000000000000000000000001234"}
+	for key, field := range fields {
+		if field.Default != "" {
+			withDefaults.Opts[key] = field.Default
+		}
+	}
+	out, err := parseChainConfig(&withDefaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("documented defaults differ from the parsed defaults.\n\tExpected: %#v\n\tGot: %#v", expected, out)
+	}
+}
+
+func TestChainConfigMaxBlocksPerPoll(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxBlocksPerPoll": "10"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.maxBlocksPerPoll != 10 {
+		t.Fatalf("expected maxBlocksPerPoll of 10, got: %d", out.maxBlocksPerPoll)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxBlocksPerPoll": "0"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for maxBlocksPerPoll of 0")
+	}
+}
+
+func TestChainConfigAutoRegister(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "autoRegister": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.autoRegister {
+		t.Fatal("expected autoRegister to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234"}
+	out, err = parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.autoRegister {
+		t.Fatal("expected autoRegister to default to false")
+	}
+}
+
+func TestChainConfigGasConfigFile(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "gasConfigFile": "gas.json"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.gasConfigFile != "gas.json" {
+		t.Fatalf("unexpected gas config file: %s", out.gasConfigFile)
+	}
+}
+
+func TestChainConfigMinPeerCount(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "minPeerCount": "0", "peerCheckInterval": "1m"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.minPeerCount != 0 {
+		t.Fatalf("expected minPeerCount of 0, got: %d", out.minPeerCount)
+	}
+	if out.peerCheckInterval != time.Minute {
+		t.Fatalf("expected peerCheckInterval of 1m, got: %s", out.peerCheckInterval)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "minPeerCount": "-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for negative minPeerCount")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "peerCheckInterval": "0s"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for peerCheckInterval of 0s")
+	}
+}
+
+func TestChainConfigWatchUnlocks(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "watchUnlocks": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.watchUnlocks {
+		t.Fatal("expected watchUnlocks to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "watchUnlocks": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid watchUnlocks")
+	}
+}
+
+func TestChainConfigFeeLogPath(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "feeLogPath": "fees.jsonl"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.feeLogPath != "fees.jsonl" {
+		t.Fatalf("unexpected fee log path: %s", out.feeLogPath)
+	}
+}
+
+func TestChainConfigTokenMapFile(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "tokenMapFile": "tokens.json"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.tokenMapFile != "tokens.json" {
+		t.Fatalf("unexpected token map file: %s", out.tokenMapFile)
+	}
+}
+
+func TestChainConfigRequireSignedMessages(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "requireSignedMessages": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.requireSignedMessages {
+		t.Fatal("expected requireSignedMessages to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "requireSignedMessages": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid requireSignedMessages")
+	}
+}
+
+func TestChainConfigFireblocks(t *testing.T) {
+	fireblocksOpts := func() map[string]string {
+		return map[string]string{
+			"bridge":              "0x0000000000000000000000000000000000001234",
+			"fireblocksVaultId":   "3",
+			"fireblocksBaseURL":   "https://api.fireblocks.io",
+			"fireblocksAPIKey":    "key",
+			"fireblocksSecretKey": "fireblocks_secret.key",
+		}
+	}
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0xff93B45308FD417dF303D6515aB04D9e89a750Ca",
+		KeystorePath: "./keys",
+		Opts:         fireblocksOpts(),
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.fireblocksVaultId != "3" || out.fireblocksBaseURL != "https://api.fireblocks.io" || out.fireblocksAPIKey != "key" || out.fireblocksSecretKey != "fireblocks_secret.key" {
+		t.Fatalf("unexpected fireblocks config: %s %s %s %s", out.fireblocksVaultId, out.fireblocksBaseURL, out.fireblocksAPIKey, out.fireblocksSecretKey)
+	}
+
+	input.Opts = fireblocksOpts()
+	delete(input.Opts, "fireblocksAPIKey")
+	_, err = parseChainConfig(&input)
+	var invalid *ConfigValidationError
+	if !errors.As(err, &invalid) || invalid.Fields[0].Field != "fireblocksAPIKey" {
+		t.Fatalf("expected fireblocksAPIKey to be required, got: %v", err)
+	}
+
+	input.Opts = fireblocksOpts()
+	input.Opts["safeAddress"] = "0x0000000000000000000000000000000000005678"
+	input.Opts["safeTxServiceURL"] = "https://safe-transaction.gnosis.io"
+	_, err = parseChainConfig(&input)
+	if !errors.As(err, &invalid) || invalid.Fields[0].Field != "fireblocksVaultId" {
+		t.Fatalf("expected fireblocksVaultId to be invalid with a safe, got: %v", err)
+	}
+
+	input.Opts = fireblocksOpts()
+	input.From = "0xff93B45308FD417dF303D6515aB04D9e89a750Ca/m/44'/60'/0'/0/2"
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for fireblocksVaultId with a derivation path")
+	}
+
+	input.From = "0xff93B45308FD417dF303D6515aB04D9e89a750Ca"
+	input.Opts = fireblocksOpts()
+	input.Opts["deployMissing"] = "true"
+	_, err = parseChainConfig(&input)
+	if !errors.As(err, &invalid) || invalid.Fields[0].Field != "fireblocksVaultId" {
+		t.Fatalf("expected fireblocksVaultId to be invalid with deployMissing, got: %v", err)
+	}
+}
+
+func TestChainConfigForwardDeposits(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "forwardDeposits": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.forwardDeposits {
+		t.Fatal("expected forwardDeposits to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "forwardDeposits": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid forwardDeposits")
+	}
+}
+
+func TestChainConfigHealthCheck(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.healthCheckInterval != DefaultHealthCheckInterval || out.maxBlockAge != DefaultMaxBlockAge {
+		t.Fatalf("expected the default health check, got: %s, %s", out.healthCheckInterval, out.maxBlockAge)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "healthCheckInterval": "0s", "maxBlockAge": "5m"}
+	out, err = parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.healthCheckInterval != 0 || out.maxBlockAge != 5*time.Minute {
+		t.Fatalf("unexpected health check: %s, %s", out.healthCheckInterval, out.maxBlockAge)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxBlockAge": "0s"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for a maxBlockAge of 0s")
+	}
+}
+
+func TestChainConfigTokenURIs(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "fetchURI": "true", "pinTokenURIs": "true", "ipfsEndpoint": "http://localhost:5001"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.fetchURI || !out.pinTokenURIs {
+		t.Fatal("expected fetchURI and pinTokenURIs to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "pinTokenURIs": "true"}
+	_, err = parseChainConfig(&input)
+	var invalid *ConfigValidationError
+	if !errors.As(err, &invalid) || invalid.Fields[0].Field != "ipfsEndpoint" {
+		t.Fatalf("expected ipfsEndpoint to be required, got: %v", err)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "fetchURI": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for invalid fetchURI")
+	}
+}
+
+func TestChainConfigCircuitBreaker(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "circuitBreakerThreshold": "5", "circuitBreakerCooldown": "5m"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.circuitBreakerThreshold != 5 || out.circuitBreakerCooldown != 5*time.Minute {
+		t.Fatalf("unexpected circuit breaker config: %d, %s", out.circuitBreakerThreshold, out.circuitBreakerCooldown)
+	}
+
+	for _, opts := range []map[string]string{
+		{"circuitBreakerThreshold": "-1"},
+		{"circuitBreakerCooldown": "0s"},
+	} {
+		opts["bridge"] = "0x0000000000000000000000000000000000001234"
+		input.Opts = opts
+		_, err = parseChainConfig(&input)
+		if err == nil {
+			t.Fatalf("expected error for %v", opts)
+		}
+	}
+}
+
+func TestChainConfigWaitForSync(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "waitForSync": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.waitForSync {
+		t.Fatal("expected waitForSync to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "waitForSync": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for an invalid waitForSync")
+	}
+}
+
+func TestChainConfigAnomalyRules(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "maxAmountPerBlock": "1000", "maxDepositsPerAddressPerMinute": "5"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.maxAmountPerBlock.Cmp(big.NewInt(1000)) != 0 || out.maxDepositsPerAddressPerMinute != 5 {
+		t.Fatalf("unexpected anomaly rules: %s, %d", out.maxAmountPerBlock, out.maxDepositsPerAddressPerMinute)
+	}
+
+	for _, opts := range []map[string]string{
+		{"maxAmountPerBlock": "-1"},
+		{"maxAmountPerBlock": "lots"},
+		{"maxDepositsPerAddressPerMinute": "-1"},
+	} {
+		opts["bridge"] = "0x0000000000000000000000000000000000001234"
+		input.Opts = opts
+		_, err = parseChainConfig(&input)
+		if err == nil {
+			t.Fatalf("expected error for %v", opts)
+		}
+	}
+}
+
+func TestChainConfigAttachStorageProof(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "attachStorageProof": "true"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.attachStorageProof {
+		t.Fatal("expected attachStorageProof to be set")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "attachStorageProof": "yes"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for an invalid attachStorageProof")
+	}
+}
+
+func TestChainConfigForwarderAddress(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "forwarderAddress": "0x0000000000000000000000000000000000005678"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.forwarderAddress != common.HexToAddress("0x0000000000000000000000000000000000005678") {
+		t.Fatalf("unexpected forwarderAddress %s", out.forwarderAddress.Hex())
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "forwarderAddress": "forwarder"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for an invalid forwarderAddress")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "forwarderAddress": "0x0000000000000000000000000000000000005678", "fireblocksVaultId": "0"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for a forwarderAddress with fireblocksVaultId")
+	}
+}
+
+func TestChainConfigRelayerBalance(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts:         map[string]string{"bridge": "0x0000000000000000000000000000000000001234"},
+	}
+
+	out, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.minRelayerBalance.Sign() != 0 || out.balanceCheckInterval != DefaultBalanceCheckInterval {
+		t.Fatalf("unexpected defaults %s, %s", out.minRelayerBalance, out.balanceCheckInterval)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "minRelayerBalance": "1000000000000000000", "balanceCheckInterval": "1m"}
+	out, err = parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.minRelayerBalance.String() != "1000000000000000000" || out.balanceCheckInterval != time.Minute {
+		t.Fatalf("unexpected minRelayerBalance %s or balanceCheckInterval %s", out.minRelayerBalance, out.balanceCheckInterval)
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "minRelayerBalance": "-1"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for a negative minRelayerBalance")
+	}
+
+	input.Opts = map[string]string{"bridge": "0x0000000000000000000000000000000000001234", "balanceCheckInterval": "0s"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected error for a zero balanceCheckInterval")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/feelog"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var proposalFees = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "chainbridge_proposal_fee_eth",
+	Help:    "Fee paid for each mined proposal transaction, in ether",
+	Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16),
+}, []string{"chain"})
+
+var resourceGas = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "chainbridge_gas_per_resource_id",
+	Help:    "Gas used by each mined executeProposal transaction, by the resource ID of the proposal",
+	Buckets: prometheus.ExponentialBuckets(25000, 2, 8),
+}, []string{"chain", "resource_id"})
+
+func init() {
+	prometheus.MustRegister(proposalFees, resourceGas)
+}
+
+// recordFee reports the fee paid for a mined proposal transaction and appends it to the fee log, if set. The gas used
+// by executions is also reported by resource ID, as it depends on the handler and token.
+func (w *writer) recordFee(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, m msg.Message) {
+	var method string
+	if len(tx.Data()) >= 4 {
+		if abiMethod, err := bridgeABI.MethodById(tx.Data()[:4]); err == nil {
+			method = abiMethod.Name
+		}
+	}
+	if method == "executeProposal" {
+		resourceGas.WithLabelValues(w.cfg.name, m.ResourceId.Hex()).Observe(float64(receipt.GasUsed))
+	}
+
+	gasPrice, err := w.effectiveGasPrice(ctx, tx, receipt)
+	if err != nil {
+		w.log.Debug("Unable to get the effective gas price, the fee is not recorded", "tx", tx.Hash(), "err", err)
+		return
+	}
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	eth, _ := feelog.WeiToEth(fee).Float64()
+	proposalFees.WithLabelValues(w.cfg.name).Observe(eth)
+
+	if w.feeLog == nil {
+		return
+	}
+	err = w.feeLog.Append(feelog.Record{
+		Timestamp:    time.Now(),
+		Chain:        w.cfg.name,
+		Source:       m.Source,
+		DepositNonce: m.DepositNonce,
+		ResourceId:   m.ResourceId.Hex(),
+		Method:       method,
+		GasUsed:      receipt.GasUsed,
+		GasPrice:     gasPrice,
+		TxFeeWei:     fee,
+		TxHash:       tx.Hash(),
+	})
+	if err != nil {
+		w.log.Warn("Failed to record transaction fee", "tx", tx.Hash(), "err", err)
+	}
+}
+
+// effectiveGasPrice returns the price per gas paid by a mined transaction. Dynamic fee transactions pay the base
+// fee of their block plus their tip, up to their fee cap.
+func (w *writer) effectiveGasPrice(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (*big.Int, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice(), nil
+	}
+	header, err := w.conn.Backend().HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return tx.GasFeeCap(), nil
+	}
+	price := new(big.Int).Add(header.BaseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		return tx.GasFeeCap(), nil
+	}
+	return price, nil
+}
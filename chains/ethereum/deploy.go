@@ -0,0 +1,124 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/bindings/ERC20Handler"
+	"github.com/ChainSafe/ChainBridge/bindings/ERC721Handler"
+	"github.com/ChainSafe/ChainBridge/bindings/GenericHandler"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DeployTimeout is the maximum time to wait for a deployment to be mined
+const DeployTimeout = 2 * time.Minute
+
+// Parameters of a bridge deployed by deployMissingContracts
+var (
+	DeployRelayerThreshold = big.NewInt(1)
+	DeployFee              = big.NewInt(0)
+	DeployExpiry           = big.NewInt(100)
+)
+
+// deployMissingContracts deploys the bridge and handlers that have no code at their configured address and
+// updates cfg with the new addresses. The bridge is deployed with the connection's sender as its only relayer.
+// Handlers are bound to a bridge on deployment, so all of them are replaced if a new bridge is deployed.
+// The fee handler is never deployed. This is intended for development chains.
+func deployMissingContracts(cfg *Config, conn Connection, log log15.Logger) error {
+	deployBridge, err := isMissing(conn, cfg.bridgeContract)
+	if err != nil {
+		return err
+	}
+	if deployBridge {
+		relayers := []common.Address{conn.Opts().From}
+		cfg.bridgeContract, err = deployContract(conn, log, "bridge", func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error) {
+			addr, tx, _, err := Bridge.DeployBridge(opts, conn.Backend(), uint8(cfg.id), relayers, DeployRelayerThreshold, DeployFee, DeployExpiry)
+			return addr, tx, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	handlers := []struct {
+		name   string
+		addr   *common.Address
+		deploy func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error)
+	}{
+		{"erc20Handler", &cfg.erc20HandlerContract, func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error) {
+			addr, tx, _, err := ERC20Handler.DeployERC20Handler(opts, conn.Backend(), cfg.bridgeContract, [][32]byte{}, []common.Address{}, []common.Address{})
+			return addr, tx, err
+		}},
+		{"erc721Handler", &cfg.erc721HandlerContract, func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error) {
+			addr, tx, _, err := ERC721Handler.DeployERC721Handler(opts, conn.Backend(), cfg.bridgeContract, [][32]byte{}, []common.Address{}, []common.Address{})
+			return addr, tx, err
+		}},
+		{"genericHandler", &cfg.genericHandlerContract, func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error) {
+			addr, tx, _, err := GenericHandler.DeployGenericHandler(opts, conn.Backend(), cfg.bridgeContract, [][32]byte{}, []common.Address{}, [][4]byte{}, [][4]byte{})
+			return addr, tx, err
+		}},
+	}
+	for _, handler := range handlers {
+		missing := deployBridge
+		if !missing {
+			missing, err = isMissing(conn, *handler.addr)
+			if err != nil {
+				return err
+			}
+		}
+		if !missing {
+			continue
+		}
+		*handler.addr, err = deployContract(conn, log, handler.name, handler.deploy)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMissing returns true if addr is unset or has no code
+func isMissing(conn Connection, addr common.Address) (bool, error) {
+	if addr == utils.ZeroAddress {
+		return true, nil
+	}
+	err := conn.EnsureHasBytecode(addr)
+	var contractErr *bridgeErrors.ContractError
+	if errors.As(err, &contractErr) && contractErr.Code == bridgeErrors.CodeNoBytecode {
+		return true, nil
+	}
+	return false, err
+}
+
+// deployContract sends the deployment using the connection's opts and waits for it to be mined
+func deployContract(conn Connection, log log15.Logger, name string, deploy func(opts *bind.TransactOpts) (common.Address, *types.Transaction, error)) (common.Address, error) {
+	err := conn.LockAndUpdateOpts()
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr, tx, err := deploy(conn.Opts())
+	conn.UnlockOpts()
+	if err != nil {
+		return common.Address{}, bridgeErrors.NewContractError(bridgeErrors.CodeTxFailed, false, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DeployTimeout)
+	defer cancel()
+	_, err = bind.WaitDeployed(ctx, conn.Backend(), tx)
+	if err != nil {
+		return common.Address{}, bridgeErrors.NewContractError(bridgeErrors.CodeTxFailed, true, err)
+	}
+	log.Info("Deployed missing contract", "contract", name, "address", addr.Hex(), "tx", tx.Hash().Hex())
+	return addr, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// DroppedMessage is a message the writer had not resolved when DrainAndShutdown timed out
+type DroppedMessage struct {
+	Message  msg.Message
+	InFlight bool // The writer was resolving the message, which may still complete
+}
+
+// DrainError is returned by DrainAndShutdown when messages were not resolved within the timeout
+type DrainError struct {
+	Dropped []DroppedMessage
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("%d messages were not resolved before the drain timed out", len(e.Dropped))
+}
+
+// setRouter sets the router the writer is registered with
+func (w *writer) setRouter(r *router.Router) {
+	w.router = r
+}
+
+// track records that m is being resolved
+func (w *writer) track(m msg.Message) {
+	w.resolvingLock.Lock()
+	defer w.resolvingLock.Unlock()
+	w.resolving[transferKey{m.Source, m.DepositNonce}] = m
+}
+
+// untrack records that m has been resolved
+func (w *writer) untrack(m msg.Message) {
+	w.resolvingLock.Lock()
+	defer w.resolvingLock.Unlock()
+	delete(w.resolving, transferKey{m.Source, m.DepositNonce})
+}
+
+// background runs f in a new routine that waitIdle waits for
+func (w *writer) background(f func()) {
+	w.routines.Add(1)
+	go func() {
+		defer w.routines.Done()
+		f()
+	}()
+}
+
+// waitIdle blocks until no messages are being resolved and the routines they started, which watch for execution
+// and mined transactions, have returned. These routines return once the writer is stopped.
+func (w *writer) waitIdle() {
+	for len(w.inFlight()) > 0 {
+		time.Sleep(router.DrainPollInterval)
+	}
+	w.routines.Wait()
+}
+
+// inFlight returns the messages being resolved
+func (w *writer) inFlight() []msg.Message {
+	w.resolvingLock.Lock()
+	defer w.resolvingLock.Unlock()
+	msgs := make([]msg.Message, 0, len(w.resolving))
+	for _, m := range w.resolving {
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// DrainAndShutdown stops the router queueing messages for the writer, then waits up to timeout for the writer to
+// resolve those already queued, including proposals submitted concurrently. The writer is unregistered from the
+// router. If any message is not resolved in time a *DrainError listing them is returned, and each is logged so it
+// can be resent, as there is no dead-letter queue to hold them.
+func (w *writer) DrainAndShutdown(timeout time.Duration) error {
+	if w.router == nil {
+		return errors.New("writer is not registered with a router")
+	}
+	deadline := time.Now().Add(timeout)
+	w.log.Info("Draining writer", "timeout", timeout)
+
+	queued := w.router.Drain(w, timeout)
+	for len(w.inFlight()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(router.DrainPollInterval)
+	}
+
+	var dropped []DroppedMessage
+	for _, m := range w.inFlight() {
+		dropped = append(dropped, DroppedMessage{Message: m, InFlight: true})
+	}
+	for _, m := range queued {
+		dropped = append(dropped, DroppedMessage{Message: m})
+	}
+	if len(dropped) == 0 {
+		w.log.Info("Writer drained")
+		return nil
+	}
+	for _, d := range dropped {
+		w.log.Warn("Message not resolved before the drain timed out", "src", d.Message.Source, "dst", d.Message.Destination, "nonce", d.Message.DepositNonce, "rId", d.Message.ResourceId.Hex(), "inFlight", d.InFlight)
+	}
+	return &DrainError{Dropped: dropped}
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// isENSName returns true if an address opt holds a domain name (eg. bridge.chainbridge.eth) instead of a hex address
+func isENSName(value string) bool {
+	if !strings.Contains(value, ".") {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if label == "" || strings.ContainsAny(label, " /\t") {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAddress returns the address of a contract opt. ENS names are recorded in ensNames to be resolved once
+// connected, and the zero address returned until then.
+func (c *Config) parseAddress(opt, value string) common.Address {
+	if !isENSName(value) {
+		return common.HexToAddress(value)
+	}
+	if c.ensNames == nil {
+		c.ensNames = make(map[string]string)
+	}
+	c.ensNames[opt] = value
+	return utils.ZeroAddress
+}
+
+// contractAddress returns the field of the contract opt
+func (c *Config) contractAddress(opt string) *common.Address {
+	switch opt {
+	case BridgeOpt:
+		return &c.bridgeContract
+	case Erc20HandlerOpt:
+		return &c.erc20HandlerContract
+	case Erc721HandlerOpt:
+		return &c.erc721HandlerContract
+	case GenericHandlerOpt:
+		return &c.genericHandlerContract
+	case FeeHandlerOpt:
+		return &c.feeHandlerContract
+	default:
+		panic(fmt.Sprintf("%s is not a contract opt", opt))
+	}
+}
+
+// resolveENSNames sets the address of every contract configured by an ENS name. Names that have already been
+// resolved are skipped, so a reconnecting listener does not modify its config.
+func resolveENSNames(ctx context.Context, cfg *Config, conn Connection, log log15.Logger) error {
+	for opt, name := range cfg.ensNames {
+		if *cfg.contractAddress(opt) != utils.ZeroAddress {
+			continue
+		}
+		addr, err := conn.ResolveENS(ctx, name)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s %s: %w", opt, name, err)
+		}
+		*cfg.contractAddress(opt) = addr
+		log.Info("Resolved ENS name", "opt", opt, "name", name, "address", addr.Hex())
+	}
+	return nil
+}
+
+// ensWatcher resolves the configured ENS names again each time SIGHUP is received
+type ensWatcher struct {
+	cfg  *Config
+	conn Connection
+	log  log15.Logger
+	stop <-chan int
+}
+
+func newENSWatcher(cfg *Config, conn Connection, log log15.Logger, stop <-chan int) *ensWatcher {
+	return &ensWatcher{cfg: cfg, conn: conn, log: log, stop: stop}
+}
+
+func (w *ensWatcher) start() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				w.refresh()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// refresh discards the cached resolutions and resolves the names again. Contracts are bound when the chain is
+// initialized, so a name that now resolves to a different address is only reported.
+func (w *ensWatcher) refresh() {
+	w.conn.ClearENSCache()
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.connectTimeout)
+	defer cancel()
+	for opt, name := range w.cfg.ensNames {
+		addr, err := w.conn.ResolveENS(ctx, name)
+		if err != nil {
+			w.log.Error("Unable to resolve ENS name", "opt", opt, "name", name, "err", err)
+			continue
+		}
+		if current := *w.cfg.contractAddress(opt); addr != current {
+			w.log.Warn("ENS name resolves to a new address, restart the relayer to use it", "opt", opt, "name", name, "address", addr.Hex(), "current", current.Hex())
+		}
+	}
+	w.log.Info("Refreshed ENS names")
+}
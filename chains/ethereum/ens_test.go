@@ -0,0 +1,98 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ensConnection resolves names from a map, counting the names it resolves
+type ensConnection struct {
+	Connection
+	names    map[string]common.Address
+	resolved int
+}
+
+func (c *ensConnection) ResolveENS(_ context.Context, name string) (common.Address, error) {
+	addr, ok := c.names[name]
+	if !ok {
+		return common.Address{}, connection.ErrENSNameNotFound
+	}
+	c.resolved++
+	return addr, nil
+}
+
+func TestChainConfigENS(t *testing.T) {
+	input := core.ChainConfig{
+		Name:         "chain",
+		Id:           1,
+		Endpoint:     "endpoint",
+		From:         "0x0",
+		KeystorePath: "./keys",
+		Opts: map[string]string{
+			"bridge":       "bridge.chainbridge.eth",
+			"erc20Handler": "0x0000000000000000000000000000000000001234",
+			"feeHandler":   "fees.chainbridge.eth",
+			"ensRegistry":  "0x0000000000000000000000000000000000000e25",
+			"ensCacheTTL":  "1m",
+		},
+	}
+
+	cfg, err := parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ensNames) != 2 || cfg.ensNames[BridgeOpt] != "bridge.chainbridge.eth" || cfg.ensNames[FeeHandlerOpt] != "fees.chainbridge.eth" {
+		t.Fatalf("unexpected ENS names: %v", cfg.ensNames)
+	}
+	if cfg.bridgeContract != (common.Address{}) || cfg.erc20HandlerContract != common.HexToAddress("0x0000000000000000000000000000000000001234") {
+		t.Fatalf("unexpected addresses: %s %s", cfg.bridgeContract.Hex(), cfg.erc20HandlerContract.Hex())
+	}
+	if cfg.ensRegistry != common.HexToAddress("0x0000000000000000000000000000000000000e25") || cfg.ensCacheTTL.String() != "1m0s" {
+		t.Fatalf("unexpected ENS config: %s %s", cfg.ensRegistry.Hex(), cfg.ensCacheTTL)
+	}
+
+	bridge := common.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	fees := common.HexToAddress("0x3167776db165D8eA0f51790CA2bbf44Db5105ADF")
+	conn := &ensConnection{names: map[string]common.Address{"bridge.chainbridge.eth": bridge, "fees.chainbridge.eth": fees}}
+	err = resolveENSNames(context.Background(), cfg, conn, log15.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.bridgeContract != bridge || cfg.feeHandlerContract != fees {
+		t.Fatalf("unexpected resolved addresses: %s %s", cfg.bridgeContract.Hex(), cfg.feeHandlerContract.Hex())
+	}
+
+	// Resolved names are not resolved again
+	err = resolveENSNames(context.Background(), cfg, conn, log15.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.resolved != 2 {
+		t.Fatalf("expected 2 resolutions, got %d", conn.resolved)
+	}
+
+	input.Opts = map[string]string{"bridge": "unknown.chainbridge.eth"}
+	cfg, err = parseChainConfig(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = resolveENSNames(context.Background(), cfg, conn, log15.Root())
+	if !errors.Is(err, connection.ErrENSNameNotFound) {
+		t.Fatalf("expected ErrENSNameNotFound, got: %v", err)
+	}
+
+	input.Opts = map[string]string{"bridge": "bridge..eth"}
+	_, err = parseChainConfig(&input)
+	if err == nil {
+		t.Fatal("expected an invalid name to fail validation")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/bindings/ERC20Handler"
+	"github.com/ChainSafe/ChainBridge/bindings/IFeeHandler"
+	"github.com/ChainSafe/ChainBridge/bindings/IPermitHandler"
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/ChainBridge/core"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ExecuteProposalOverhead approximates the gas the bridge uses when executing a proposal in addition to the handler
+const ExecuteProposalOverhead = 30000
+
+var (
+	bridgeABI, _     = abi.JSON(strings.NewReader(Bridge.BridgeABI))
+	handlerABI, _    = abi.JSON(strings.NewReader(ERC20Handler.ERC20HandlerABI)) // executeProposal is shared by all handlers
+	feeHandlerABI, _ = abi.JSON(strings.NewReader(IFeeHandler.IFeeHandlerABI))
+	permitABI, _     = abi.JSON(strings.NewReader(IPermitHandler.IPermitHandlerABI))
+)
+
+// proposalData returns the data passed to executeProposal for m and the handler that executes it
+func (w *writer) proposalData(m msg.Message) ([]byte, common.Address, error) {
+	switch m.Type {
+	case msg.FungibleTransfer, PermitFungibleTransfer:
+		return ConstructErc20ProposalData(m.Payload[0].([]byte), m.Payload[1].([]byte)), w.cfg.erc20HandlerContract, nil
+	case msg.NonFungibleTransfer:
+		return ConstructErc721ProposalData(m.Payload[0].([]byte), m.Payload[1].([]byte), m.Payload[2].([]byte)), w.cfg.erc721HandlerContract, nil
+	case msg.GenericTransfer:
+		metadata, err := w.genericMetadata(m)
+		if err != nil {
+			return nil, common.Address{}, err
+		}
+		return ConstructGenericProposalData(metadata), w.cfg.genericHandlerContract, nil
+	default:
+		return nil, common.Address{}, fmt.Errorf("unknown message type: %s", m.Type)
+	}
+}
+
+// EstimateProposalCost returns the cost in wei of executing the proposal for m, the estimated gas multiplied by
+// the current gas price. The executeProposal call is simulated from the configured relayer address so no key is
+// needed. If the proposal has not yet passed the bridge rejects the call, so the handler's execution is simulated
+// from the bridge instead and ExecuteProposalOverhead added. Any fee charged by the fee handler is included.
+func (w *writer) EstimateProposalCost(m msg.Message) (*big.Int, error) {
+	data, handler, err := w.proposalData(m)
+	if err != nil {
+		return nil, err
+	}
+
+	calldata, err := bridgeABI.Pack("executeProposal", uint8(m.Source), uint64(m.DepositNonce), data, [32]byte(m.ResourceId))
+	if err != nil {
+		return nil, err
+	}
+	gas, err := w.conn.SimulateTransaction(context.Background(), eth.CallMsg{
+		From: common.HexToAddress(w.cfg.from),
+		To:   &w.cfg.bridgeContract,
+		Data: calldata,
+	})
+	if err != nil {
+		w.log.Debug("Unable to simulate proposal execution, simulating handler instead", "err", err)
+		gas, err = w.estimateHandlerExecution(m, data, handler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	price, err := w.conn.EffectiveGasPrice(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gas), price)
+
+	if w.feeHandler != nil {
+		fee, err := w.calculateFee(m)
+		if err != nil {
+			return nil, err
+		}
+		cost.Add(cost, fee)
+	}
+	return cost, nil
+}
+
+// estimateHandlerExecution simulates the handler executing the proposal, as only the bridge may call it
+func (w *writer) estimateHandlerExecution(m msg.Message, data []byte, handler common.Address) (uint64, error) {
+	if handler == utils.ZeroAddress {
+		return 0, fmt.Errorf("no handler configured for message type: %s", m.Type)
+	}
+
+	calldata, err := handlerABI.Pack("executeProposal", [32]byte(m.ResourceId), data)
+	if err != nil {
+		return 0, err
+	}
+	gas, err := w.conn.SimulateTransaction(context.Background(), eth.CallMsg{
+		From: w.cfg.bridgeContract,
+		To:   &handler,
+		Data: calldata,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return gas + ExecuteProposalOverhead, nil
+}
+
+// Estimator estimates the cost of executing deposits from a source chain on a destination chain.
+// It only reads from both chains, so neither a keystore nor a blockstore is needed.
+type Estimator struct {
+	source *listener
+	dest   *writer
+}
+
+// NewEstimator connects to the source and destination chains without a keypair
+func NewEstimator(source, dest *core.ChainConfig, logger log15.Logger) (*Estimator, error) {
+	srcCfg, err := parseChainConfig(source)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), source.Id)
+	}
+	destCfg, err := parseChainConfig(dest)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), dest.Id)
+	}
+
+	srcLog := logger.New("chain", srcCfg.name)
+	srcConn, srcContracts, err := connectReadOnly(srcCfg, srcLog)
+	if err != nil {
+		return nil, err
+	}
+	destLog := logger.New("chain", destCfg.name)
+	destConn, destContracts, err := connectReadOnly(destCfg, destLog)
+	if err != nil {
+		srcConn.Close()
+		return nil, err
+	}
+
+	l := NewListener(srcConn, srcCfg, srcLog, nil, nil, nil, nil)
+	l.setContracts(srcContracts.bridge, srcContracts.erc20Handler, srcContracts.erc721Handler, srcContracts.genericHandler)
+
+	w := NewWriter(destConn, destCfg, destLog, nil, nil, nil)
+	w.setContract(destContracts.bridge)
+	w.setFeeHandler(destContracts.feeHandler)
+
+	return &Estimator{source: l, dest: w}, nil
+}
+
+// connectReadOnly opens a connection without a keypair and binds the configured contracts. It is also used
+// to reconnect stalled listeners, which never sign transactions.
+func connectReadOnly(cfg *Config, logger log15.Logger) (*connection.Connection, *boundContracts, error) {
+	conn := connection.NewConnection(cfg.endpoint, cfg.http, nil, logger, cfg.gasLimit, cfg.maxGasPrice, cfg.minGasPrice, cfg.gasMultiplier, cfg.egsApiKey, cfg.egsSpeed)
+	conn.SetBatchRPC(cfg.useBatchRPC)
+	conn.SetMinPeerCount(cfg.minPeerCount)
+	conn.SetENS(cfg.ensRegistry, cfg.ensCacheTTL)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout)
+	defer cancel()
+	err := conn.ConnectWithContext(ctx)
+	if err != nil {
+		return nil, nil, bridgeErrors.WithChain(err, cfg.id)
+	}
+
+	err = resolveENSNames(ctx, cfg, conn, logger)
+	if err != nil {
+		conn.Close()
+		return nil, nil, bridgeErrors.WithChain(err, cfg.id)
+	}
+
+	contracts, err := bindContracts(cfg, conn, cfg.id)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, contracts, nil
+}
+
+// EstimateDeposit looks up the deposit with nonce on the source chain and returns its message along with
+// the cost in wei of executing it on the destination chain
+func (e *Estimator) EstimateDeposit(nonce msg.Nonce) (msg.Message, *big.Int, error) {
+	m, err := e.source.getDeposit(e.dest.cfg.id, nonce)
+	if err != nil {
+		return msg.Message{}, nil, err
+	}
+
+	cost, err := e.dest.EstimateProposalCost(m)
+	if err != nil {
+		return msg.Message{}, nil, err
+	}
+	return m, cost, nil
+}
+
+// Close closes the connections to both chains
+func (e *Estimator) Close() {
+	e.source.conn.Close()
+	e.dest.conn.Close()
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestWriter_EstimateProposalCost(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	writerA, writerB, stopA, stopB, _, _ := createWriters(t, client, contracts)
+
+	defer stopA()
+	defer stopB()
+	defer writerA.conn.Close()
+	defer writerB.conn.Close()
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	ethtest.FundErc20Handler(t, client, contracts.ERC20HandlerAddress, erc20Address, big.NewInt(100))
+
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	m := msg.NewFungibleTransfer(1, 0, 0, big.NewInt(10), resourceId, recipient.Bytes())
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	// Estimates are simulated from the relayer address rather than signed with its key
+	writerA.cfg.from = keystore.TestKeyRing.EthereumKeys[writerA.cfg.name].Address()
+
+	// Before the proposal passes only the handler's execution can be simulated
+	cost, err := writerA.EstimateProposalCost(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost.Sign() <= 0 {
+		t.Fatalf("expected a positive cost, got: %s", cost)
+	}
+
+	data := ConstructErc20ProposalData(m.Payload[0].([]byte), m.Payload[1].([]byte))
+	dataHash := utils.Hash(append(writerA.cfg.erc20HandlerContract.Bytes(), data...))
+	writerA.voteProposal(m, dataHash)
+	writerB.voteProposal(m, dataHash)
+	for i := 0; !writerA.proposalIsPassed(m.Source, m.DepositNonce, dataHash); i++ {
+		if i == 30 {
+			t.Fatal("proposal did not pass")
+		}
+		time.Sleep(time.Second)
+	}
+
+	cost, err = writerA.EstimateProposalCost(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost.Sign() <= 0 {
+		t.Fatalf("expected a positive cost, got: %s", cost)
+	}
+}
+
+func TestEstimator_EstimateDeposit(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	stop := make(chan int)
+	defer close(stop)
+	errs := make(chan error)
+	l, router := createTestListener(t, aliceTestConfig, contracts, stop, errs)
+	defer l.conn.Close()
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(aliceTestConfig.id)))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	// The same contracts stand in for the destination chain
+	destCfg := createConfig("bob", ethtest.GetLatestBlock(t, client), contracts)
+	destCfg.id = 1
+	w, stopWriter := createTestWriter(t, destCfg, errs)
+	defer stopWriter()
+	defer w.conn.Close()
+
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+	createErc20Deposit(t, l.bridgeContract, client, resourceId, recipient, destCfg.id, amount)
+	// Wait for the deposit to be mined
+	expected := msg.NewFungibleTransfer(aliceTestConfig.id, destCfg.id, 1, amount, resourceId, recipient.Bytes())
+	verifyMessage(t, router, expected, errs)
+
+	e := &Estimator{source: l, dest: w}
+	m, cost, err := e.EstimateDeposit(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("unexpected deposit. Expected: %+v Got: %+v", expected, m)
+	}
+	if cost.Sign() <= 0 {
+		t.Fatalf("expected a positive cost, got: %s", cost)
+	}
+
+	_, _, err = e.EstimateDeposit(2)
+	if !errors.Is(err, ErrDepositNotFound) {
+		t.Fatalf("expected ErrDepositNotFound, got: %v", err)
+	}
+}
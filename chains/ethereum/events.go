@@ -4,38 +4,166 @@
 package ethereum
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/ChainBridge/metadata"
 	"github.com/ChainSafe/chainbridge-utils/msg"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
+// handleResourceIDSetEvent records the token and resource ID from a ResourceIDSet(bytes32 resourceID, address tokenAddress) log
+func (l *listener) handleResourceIDSetEvent(log ethtypes.Log) error {
+	if len(log.Data) != 64 {
+		return fmt.Errorf("unexpected ResourceIDSet data length %d in tx %s", len(log.Data), log.TxHash.Hex())
+	}
+	rId := msg.ResourceIdFromSlice(log.Data[:32])
+	token := common.BytesToAddress(log.Data[32:])
+
+	l.resourceLock.Lock()
+	l.resourceIds[token] = rId
+	l.resourceLock.Unlock()
+
+	l.log.Info("Registered resource ID", "token", token, "rId", rId.Hex(), "block", log.BlockNumber)
+	return nil
+}
+
+// lookupResourceId returns the resource ID registered for token by a ResourceIDSet event
+func (l *listener) lookupResourceId(token common.Address) (msg.ResourceId, bool) {
+	l.resourceLock.RLock()
+	defer l.resourceLock.RUnlock()
+	rId, ok := l.resourceIds[token]
+	return rId, ok
+}
+
 func (l *listener) handleErc20DepositedEvent(destId msg.ChainId, nonce msg.Nonce) (msg.Message, error) {
 	l.log.Info("Handling fungible deposit event", "dest", destId, "nonce", nonce)
 
-	record, err := l.erc20HandlerContract.GetDepositRecord(&bind.CallOpts{From: l.conn.Keypair().CommonAddress()}, uint64(nonce), uint8(destId))
+	record, err := l.contracts().erc20Handler.GetDepositRecord(l.connection().CallOpts(), uint64(nonce), uint8(destId))
 	if err != nil {
 		l.log.Error("Error Unpacking ERC20 Deposit Record", "err", err)
 		return msg.Message{}, err
 	}
 
+	rId, err := l.resolveErc20ResourceId(record.TokenAddress, record.ResourceID)
+	if err != nil {
+		l.log.Error("Error resolving ERC20 resource ID", "token", record.TokenAddress, "err", err)
+		return msg.Message{}, err
+	}
+
+	amount := record.Amount
+	if l.normalizer != nil {
+		var dust *big.Int
+		amount, dust, err = l.normalizer.Normalize(record.TokenAddress, rId, destId, record.Amount)
+		if err != nil {
+			l.log.Error("Error normalizing ERC20 amount", "token", record.TokenAddress, "err", err)
+			return msg.Message{}, err
+		}
+		if dust.Sign() != 0 {
+			l.log.Warn("Deposit amount truncated to the destination token's decimals", "token", record.TokenAddress, "nonce", nonce, "amount", record.Amount, "dust", dust)
+		}
+	}
+
 	return msg.NewFungibleTransfer(
 		l.cfg.id,
 		destId,
 		nonce,
-		record.Amount,
-		record.ResourceID,
+		amount,
+		rId,
 		record.DestinationRecipientAddress,
 	), nil
 }
 
+// handlePermitDepositedEvent builds the message for an erc20 deposit made with an EIP-2612 permit, which is carried
+// by the PermitDeposited log
+func (l *listener) handlePermitDepositedEvent(permit Permit, destId msg.ChainId, nonce msg.Nonce) (msg.Message, error) {
+	m, err := l.handleErc20DepositedEvent(destId, nonce)
+	if err != nil {
+		return msg.Message{}, err
+	}
+
+	return NewPermitFungibleTransfer(
+		m.Source,
+		m.Destination,
+		m.DepositNonce,
+		new(big.Int).SetBytes(m.Payload[0].([]byte)),
+		m.ResourceId,
+		m.Payload[1].([]byte),
+		permit,
+	), nil
+}
+
+// resolveErc20ResourceId returns the resource ID registered for the token, preferring IDs seen in ResourceIDSet events
+// over the erc20 handler. Tokens deployed as EIP-1167 minimal proxy clones are not registered themselves, so the
+// implementation's resource ID is used instead. If neither is registered the provided default is returned.
+func (l *listener) resolveErc20ResourceId(token common.Address, def msg.ResourceId) (msg.ResourceId, error) {
+	if rId, ok := l.lookupResourceId(token); ok {
+		return rId, nil
+	}
+
+	conn, erc20Handler := l.connection(), l.contracts().erc20Handler
+	opts := conn.CallOpts()
+	rId, err := erc20Handler.TokenContractAddressToResourceID(opts, token)
+	if err != nil {
+		return msg.ResourceId{}, err
+	}
+	if rId != [32]byte{} {
+		return rId, nil
+	}
+
+	impl, isProxy, err := conn.IsMinimalProxy(token)
+	if err != nil {
+		return msg.ResourceId{}, err
+	}
+	if !isProxy {
+		return def, nil
+	}
+
+	if rId, ok := l.lookupResourceId(impl); ok {
+		return rId, nil
+	}
+
+	rId, err = erc20Handler.TokenContractAddressToResourceID(opts, impl)
+	if err != nil {
+		return msg.ResourceId{}, err
+	}
+	if rId == [32]byte{} {
+		return def, nil
+	}
+	l.log.Debug("Resolved resource ID from minimal proxy implementation", "token", token, "impl", impl)
+	return rId, nil
+}
+
 func (l *listener) handleErc721DepositedEvent(destId msg.ChainId, nonce msg.Nonce) (msg.Message, error) {
 	l.log.Info("Handling nonfungible deposit event")
 
-	record, err := l.erc721HandlerContract.GetDepositRecord(&bind.CallOpts{From: l.conn.Keypair().CommonAddress()}, uint64(nonce), uint8(destId))
+	record, err := l.contracts().erc721Handler.GetDepositRecord(l.connection().CallOpts(), uint64(nonce), uint8(destId))
 	if err != nil {
 		l.log.Error("Error Unpacking ERC721 Deposit Record", "err", err)
 		return msg.Message{}, err
 	}
 
+	tokenMetadata := record.MetaData
+	if fetcher := l.tokenURIFetcher(); fetcher != nil && len(tokenMetadata) == 0 {
+		// Tokens without the metadata extension are relayed without a URI, other errors are returned so the
+		// deposit is retried
+		uri, err := fetcher.TokenURI(record.TokenAddress, record.TokenID)
+		if errors.Is(err, metadata.ErrNoTokenURI) {
+			l.log.Warn("Token has no URI, relaying it without one", "token", record.TokenAddress, "id", record.TokenID, "err", err)
+		} else if err != nil {
+			return msg.Message{}, err
+		} else {
+			err = l.checkMessageSize(uint64(len(uri)))
+			if err != nil {
+				return msg.Message{}, err
+			}
+			tokenMetadata = []byte(uri)
+			l.log.Debug("Fetched token URI", "token", record.TokenAddress, "id", record.TokenID, "uri", uri)
+		}
+	}
+
 	return msg.NewNonFungibleTransfer(
 		l.cfg.id,
 		destId,
@@ -43,24 +171,52 @@ func (l *listener) handleErc721DepositedEvent(destId msg.ChainId, nonce msg.Nonc
 		record.ResourceID,
 		record.TokenID,
 		record.DestinationRecipientAddress,
-		record.MetaData,
+		tokenMetadata,
 	), nil
 }
 
-func (l *listener) handleGenericDepositedEvent(destId msg.ChainId, nonce msg.Nonce) (msg.Message, error) {
+// handleGenericDepositedEvent builds the message for a generic deposit made in block
+func (l *listener) handleGenericDepositedEvent(destId msg.ChainId, nonce msg.Nonce, block *big.Int) (msg.Message, error) {
 	l.log.Info("Handling generic deposit event")
 
-	record, err := l.genericHandlerContract.GetDepositRecord(&bind.CallOpts{From: l.conn.Keypair().CommonAddress()}, uint64(nonce), uint8(destId))
+	record, err := l.contracts().genericHandler.GetDepositRecord(l.connection().CallOpts(), uint64(nonce), uint8(destId))
 	if err != nil {
 		l.log.Error("Error Unpacking Generic Deposit Record", "err", err)
 		return msg.Message{}, nil
 	}
 
+	// Checked before compression, as the destination relayers decompress it
+	err = l.checkMessageSize(uint64(len(record.MetaData)))
+	if err != nil {
+		return msg.Message{}, err
+	}
+
+	metadata := record.MetaData[:]
+	if l.cfg.attachStorageProof {
+		metadata, err = l.attachStorageProof(metadata, destId, nonce, block)
+		if err != nil {
+			return msg.Message{}, err
+		}
+		l.log.Debug("Attached deposit record proof", "block", block, "size", len(metadata))
+	}
+	if !l.cfg.noCompression && len(metadata) > l.cfg.compressionThreshold {
+		metadata = compressMetadata(metadata)
+		l.log.Debug("Compressed generic metadata", "size", len(record.MetaData), "compressed", len(metadata))
+	}
+	if l.metadataStore != nil && len(metadata) > l.cfg.maxOnChainBytes {
+		size := len(metadata)
+		metadata, err = l.metadataStore.Put(metadata)
+		if err != nil {
+			return msg.Message{}, err
+		}
+		l.log.Debug("Stored generic metadata in IPFS", "size", size, "ref", string(metadata))
+	}
+
 	return msg.NewGenericTransfer(
 		l.cfg.id,
 		destId,
 		nonce,
 		record.ResourceID,
-		record.MetaData[:],
+		metadata,
 	), nil
 }
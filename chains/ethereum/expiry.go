@@ -0,0 +1,76 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var expiredProposals = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_expired_proposals_total",
+	Help: "Number of messages dropped by the writer because their proposal expired",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(expiredProposals)
+}
+
+// proposalExpiry returns the last block the proposal can be voted on in, the block it was proposed in plus the
+// bridge's expiry. nil is returned if the proposal has not been created.
+func (w *writer) proposalExpiry(srcId msg.ChainId, nonce msg.Nonce, dataHash [32]byte) (*big.Int, error) {
+	prop, err := w.bridgeContract.GetProposal(w.conn.CallOpts(), uint8(srcId), uint64(nonce), dataHash)
+	if err != nil {
+		return nil, err
+	}
+	if prop.ProposedBlock == nil || prop.ProposedBlock.Sign() == 0 {
+		return nil, nil
+	}
+	expiry, err := w.bridgeExpiry()
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Add(prop.ProposedBlock, expiry), nil
+}
+
+// bridgeExpiry returns the number of blocks proposals are open for. The bridge cannot change it, so it is cached.
+func (w *writer) bridgeExpiry() (*big.Int, error) {
+	w.expiryLock.Lock()
+	defer w.expiryLock.Unlock()
+	if w.expiry != nil {
+		return w.expiry, nil
+	}
+	expiry, err := w.bridgeContract.Expiry(w.conn.CallOpts())
+	if err != nil {
+		return nil, err
+	}
+	w.expiry = expiry
+	return expiry, nil
+}
+
+// proposalExpired returns true if the latest block is past the proposal's expiry, so transactions for it would only
+// waste gas. The message is counted in chainbridge_expired_proposals_total and should be dropped.
+func (w *writer) proposalExpired(m msg.Message, dataHash [32]byte) bool {
+	expiry, err := w.proposalExpiry(m.Source, m.DepositNonce, dataHash)
+	if err != nil {
+		w.log.Warn("Unable to get proposal expiry", "src", m.Source, "nonce", m.DepositNonce, "err", err)
+		return false
+	}
+	if expiry == nil {
+		return false
+	}
+	latest, err := w.conn.LatestBlock()
+	if err != nil {
+		w.log.Warn("Unable to get latest block", "err", err)
+		return false
+	}
+	if latest.Cmp(expiry) <= 0 {
+		return false
+	}
+	w.log.Warn("Proposal expired, dropping message", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "expiry", expiry, "latest", latest)
+	expiredProposals.WithLabelValues(w.cfg.name).Inc()
+	return true
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWriter_ProposalExpired(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The proposal was created in block 50 and the bridge's proposals expire after 20 blocks
+	var expiryCalls int32
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(call.Data, bridgeABI.Methods["getProposal"].ID):
+			return bridgeABI.Methods["getProposal"].Outputs.Pack(Bridge.BridgeProposal{Status: 1, ProposedBlock: big.NewInt(50)})
+		case bytes.HasPrefix(call.Data, bridgeABI.Methods["_expiry"].ID):
+			atomic.AddInt32(&expiryCalls, 1)
+			return bridgeABI.Methods["_expiry"].Outputs.Pack(big.NewInt(20))
+		}
+		return nil, errMockUnsupported
+	}
+	backend.setHead(70)
+
+	cfg := createConfig("expiry", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 0
+	stop := make(chan int)
+	defer close(stop)
+	sysErr := make(chan error, 1)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, sysErr, nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	expiry, err := writer.proposalExpiry(m.Source, m.DepositNonce, [32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiry.Cmp(big.NewInt(70)) != 0 {
+		t.Fatalf("expected expiry 70, got: %s", expiry)
+	}
+	if writer.proposalExpired(m, [32]byte{1}) {
+		t.Fatal("expected the proposal not to expire until after block 70")
+	}
+
+	// Once the chain passes the expiry, a failed vote is not retried
+	backend.setHead(71)
+	backend.sendErr = errors.New("transaction failed")
+	writer.voteProposal(m, [32]byte{1})
+
+	if backend.called("SendTransaction") != 1 {
+		t.Fatalf("expected the vote to be sent once, got: %d", backend.called("SendTransaction"))
+	}
+	select {
+	case err := <-sysErr:
+		t.Fatalf("expected no fatal error, got: %v", err)
+	default:
+	}
+	if expired := testutil.ToFloat64(expiredProposals.WithLabelValues(cfg.name)); expired != 1 {
+		t.Fatalf("expected 1 expired proposal, got: %v", expired)
+	}
+	if n := atomic.LoadInt32(&expiryCalls); n != 1 {
+		t.Fatalf("expected the bridge expiry to be read once, got: %d", n)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/bindings/IRelayerFees"
+	"github.com/ChainSafe/ChainBridge/core"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// FeeCheckInterval is the time between checks of the relayer's claimable fees
+var FeeCheckInterval = time.Minute
+
+var claimableFees = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_claimable_fees_wei",
+	Help: "Relay fees the relayer can claim from the bridge, in wei",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(claimableFees)
+}
+
+// FeeCollector reports the relay fees accumulated by bridges that support getAvailableFees, and claims them
+// once they exceed a threshold
+type FeeCollector struct {
+	cfg       Config
+	conn      Connection
+	contract  *IRelayerFees.IRelayerFees
+	relayer   common.Address
+	threshold *big.Int // Fees are only reported if nil
+	log       log15.Logger
+	stop      <-chan int
+}
+
+// NewFeeCollector binds the bridge's relay fee functions to conn. Claims are sent with conn's keypair.
+func NewFeeCollector(conn Connection, cfg *Config, relayer common.Address, threshold *big.Int, log log15.Logger, stop <-chan int) (*FeeCollector, error) {
+	contract, err := IRelayerFees.NewIRelayerFees(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		return nil, err
+	}
+	return &FeeCollector{
+		cfg:       *cfg,
+		conn:      conn,
+		contract:  contract,
+		relayer:   relayer,
+		threshold: threshold,
+		log:       log,
+		stop:      stop,
+	}, nil
+}
+
+// QueryClaimableFees connects to the chain without a keypair and returns the fees claimable by the from address.
+// If from has a derivation path the key is loaded to find the address of the derived key.
+func QueryClaimableFees(chainCfg *core.ChainConfig, logger log15.Logger) (*big.Int, error) {
+	cfg, err := parseChainConfig(chainCfg)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+	}
+
+	conn, _, err := connectReadOnly(cfg, logger.New("chain", cfg.name))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	relayer := common.HexToAddress(cfg.from)
+	if cfg.safeAddress != utils.ZeroAddress {
+		relayer = cfg.safeAddress
+	} else if cfg.derivationPath != "" {
+		// The address of a derived key is only known once the from key is decrypted
+		kp, err := loadKeypair(cfg, chainCfg.Insecure)
+		if err != nil {
+			return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeKeystore, false, err), chainCfg.Id)
+		}
+		relayer = kp.CommonAddress()
+	}
+	f, err := NewFeeCollector(conn, cfg, relayer, nil, logger, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.ClaimableFees()
+}
+
+// ClaimableFees returns the fees the relayer can currently claim
+func (f *FeeCollector) ClaimableFees() (*big.Int, error) {
+	fees, err := f.contract.GetAvailableFees(f.conn.CallOpts(), f.relayer)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err), f.cfg.id)
+	}
+	return fees, nil
+}
+
+// start checks the fees every FeeCheckInterval until stop is closed. Failed checks are retried at the next interval.
+func (f *FeeCollector) start() {
+	go func() {
+		ticker := time.NewTicker(FeeCheckInterval)
+		defer ticker.Stop()
+		for {
+			err := f.check()
+			if err != nil {
+				f.log.Warn("Failed to check relay fees", "err", err)
+			}
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// check reports the claimable fees and claims them if they exceed the threshold
+func (f *FeeCollector) check() error {
+	fees, err := f.ClaimableFees()
+	if err != nil {
+		return err
+	}
+	wei, _ := new(big.Float).SetInt(fees).Float64()
+	claimableFees.WithLabelValues(f.cfg.name).Set(wei)
+	f.log.Info("Claimable relay fees", "relayer", f.relayer.Hex(), "wei", fees)
+
+	if f.threshold == nil || fees.Cmp(f.threshold) <= 0 {
+		return nil
+	}
+	return f.claim()
+}
+
+// claim sends a claimFee transaction without waiting for it to be mined
+func (f *FeeCollector) claim() error {
+	err := f.conn.LockAndUpdateOpts()
+	if err != nil {
+		return err
+	}
+	tx, err := f.contract.ClaimFee(f.conn.Opts())
+	f.conn.UnlockOpts()
+	if err != nil {
+		return bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeTxFailed, true, err), f.cfg.id)
+	}
+	f.log.Info("Claimed relay fees", "tx", tx.Hash().Hex())
+	return nil
+}
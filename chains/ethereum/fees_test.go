@@ -0,0 +1,72 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFeeCollector(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	fees := big.NewInt(1000)
+	bridge := ethtest.DeployMockRelayerFees(t, client, fees)
+
+	cfg := createConfig("bob", nil, nil)
+	cfg.bridgeContract = bridge
+	conn := newLocalConnection(t, cfg)
+	defer conn.Close()
+	relayer := conn.Keypair().CommonAddress()
+
+	// The mock stores the address that called claimFee
+	claimedBy := func() common.Address {
+		slot, err := conn.Client().StorageAt(context.Background(), bridge, common.Hash{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return common.BytesToAddress(slot)
+	}
+
+	// Fees at the threshold are not claimed
+	f, err := NewFeeCollector(conn, cfg, relayer, big.NewInt(1000), newTestLogger(cfg.name), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := f.ClaimableFees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Cmp(fees) != 0 {
+		t.Fatalf("expected claimable fees %s, got: %s", fees, actual)
+	}
+	err = f.check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gauge := testutil.ToFloat64(claimableFees.WithLabelValues(cfg.name)); gauge != 1000 {
+		t.Fatalf("expected claimable fees gauge 1000, got: %v", gauge)
+	}
+	if claimedBy() != (common.Address{}) {
+		t.Fatal("fees at the threshold were claimed")
+	}
+
+	// Fees above the threshold are claimed
+	f.threshold = big.NewInt(999)
+	err = f.check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; claimedBy() != relayer; i++ {
+		if i == 30 {
+			t.Fatal("fees were not claimed")
+		}
+		time.Sleep(time.Second)
+	}
+}
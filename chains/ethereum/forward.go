@@ -0,0 +1,56 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// forwardDeposit deposits the generic message the router forwarded via this chain again, for the chain it is
+// forwarded to. original is the message before it was forwarded. Only relayers with forwardDeposits set make the
+// deposit, so it is made once, the writers of other relayers return true without sending a transaction.
+func (w *writer) forwardDeposit(original msg.Message) bool {
+	if !w.cfg.forwardDeposits {
+		w.log.Info("Skipping forwarded message, forwardDeposits is not set", "src", original.Source, "forwardTo", original.Destination, "nonce", original.DepositNonce)
+		return true
+	}
+
+	metadata, err := w.genericMetadata(original)
+	if err != nil {
+		w.log.Error("Unable to get generic metadata", "src", original.Source, "nonce", original.DepositNonce, "err", err)
+		return false
+	}
+	data := ConstructGenericProposalData(metadata)
+
+	fee, err := w.bridgeContract.Fee(w.conn.CallOpts())
+	if err != nil {
+		w.log.Error("Unable to get the bridge deposit fee", "err", err)
+		return false
+	}
+	gasLimit := w.estimateGasLimit(bridgeABI, w.cfg.bridgeContract, fee, "deposit", uint8(original.Destination), [32]byte(original.ResourceId), data)
+	err = w.conn.LockAndUpdateOpts()
+	if err != nil {
+		w.log.Error("Failed to update tx opts", "err", err)
+		return false
+	}
+	// The opts are shared, so the value and gas limit must be reset before they are unlocked
+	opts := w.conn.Opts()
+	configuredGasLimit := opts.GasLimit
+	opts.Value = fee
+	opts.GasLimit = gasLimit
+	tx, err := w.bridgeContract.Deposit(opts, uint8(original.Destination), original.ResourceId, data)
+	opts.Value = big.NewInt(0)
+	opts.GasLimit = configuredGasLimit
+	w.conn.UnlockOpts()
+
+	if err != nil {
+		w.log.Error("Forwarding deposit failed", "src", original.Source, "forwardTo", original.Destination, "nonce", original.DepositNonce, "err", w.txError(err))
+		return false
+	}
+	w.log.Info("Submitted forwarding deposit", "tx", tx.Hash(), "src", original.Source, "forwardTo", original.Destination, "nonce", original.DepositNonce)
+	w.background(func() { w.watchReceipt(tx, original) })
+	return true
+}
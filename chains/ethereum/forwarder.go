@@ -0,0 +1,151 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ForwarderGasOverhead is added to the gas limit of forwarded executions for the forwarder's signature check
+const ForwarderGasOverhead = 50000
+
+// The EIP-712 domain of forward requests, that of OpenZeppelin's MinimalForwarder
+const (
+	ForwarderName    = "MinimalForwarder"
+	ForwarderVersion = "0.0.1"
+)
+
+const forwarderABIJSON = `[{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}],"name":"req","type":"tuple"},{"name":"signature","type":"bytes"}],"name":"execute","outputs":[{"name":"","type":"bool"},{"name":"","type":"bytes"}],"stateMutability":"payable","type":"function"},{"inputs":[{"name":"from","type":"address"}],"name":"getNonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var forwarderABI, _ = abi.JSON(strings.NewReader(forwarderABIJSON))
+
+var (
+	forwarderDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	forwardRequestTypeHash  = crypto.Keccak256Hash([]byte("ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,bytes data)"))
+)
+
+// ForwardRequest is a call the forwarder makes on behalf of From, which signs it. The call's recipient sees From
+// as the sender (EIP-2771).
+type ForwardRequest struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Gas   *big.Int
+	Nonce *big.Int
+	Data  []byte
+}
+
+// ForwardRequestHash returns the EIP-712 hash of req for the forwarder at verifyingContract, which From signs
+func ForwardRequestHash(chainId *big.Int, verifyingContract common.Address, req ForwardRequest) common.Hash {
+	word := func(b []byte) []byte { return common.LeftPadBytes(b, 32) }
+
+	domain := crypto.Keccak256(
+		forwarderDomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(ForwarderName)),
+		crypto.Keccak256([]byte(ForwarderVersion)),
+		word(chainId.Bytes()),
+		word(verifyingContract.Bytes()),
+	)
+	request := crypto.Keccak256(
+		forwardRequestTypeHash.Bytes(),
+		word(req.From.Bytes()),
+		word(req.To.Bytes()),
+		word(req.Value.Bytes()),
+		word(req.Gas.Bytes()),
+		word(req.Nonce.Bytes()),
+		crypto.Keccak256(req.Data),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, request)
+}
+
+// executeViaForwarder executes the proposal of m through the forwarderAddress forwarder, so the bridge sees the
+// relayer as the sender of executeProposal although the transaction is sent to the forwarder. opts must be locked.
+func (w *writer) executeViaForwarder(opts *bind.TransactOpts, m msg.Message, data []byte) (*types.Transaction, error) {
+	forwardOpts := *opts
+	forwardOpts.Signer = func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		req, sig, err := w.buildForwardRequest(tx)
+		if err != nil {
+			return nil, err
+		}
+		calldata, err := forwarderABI.Pack("execute", req, sig)
+		if err != nil {
+			return nil, err
+		}
+		return opts.Signer(from, forwardedTx(tx, w.cfg.forwarderAddress, calldata))
+	}
+	return w.bridgeContract.ExecuteProposal(&forwardOpts, uint8(m.Source), uint64(m.DepositNonce), data, m.ResourceId)
+}
+
+// buildForwardRequest returns the forward request of tx's call, signed by the relayer key with its nonce on the
+// forwarder
+func (w *writer) buildForwardRequest(tx *types.Transaction) (ForwardRequest, []byte, error) {
+	if tx.To() == nil {
+		return ForwardRequest{}, nil, errors.New("contract deployments cannot be forwarded")
+	}
+	kp := w.conn.Keypair()
+	ctx := context.Background()
+	nonce, err := w.forwarderNonce(ctx, kp.CommonAddress())
+	if err != nil {
+		return ForwardRequest{}, nil, err
+	}
+	chainId, err := w.conn.Backend().ChainID(ctx)
+	if err != nil {
+		return ForwardRequest{}, nil, err
+	}
+
+	req := ForwardRequest{
+		From:  kp.CommonAddress(),
+		To:    *tx.To(),
+		Value: tx.Value(),
+		Gas:   new(big.Int).SetUint64(tx.Gas()),
+		Nonce: nonce,
+		Data:  tx.Data(),
+	}
+	sig, err := crypto.Sign(ForwardRequestHash(chainId, w.cfg.forwarderAddress, req).Bytes(), kp.PrivateKey())
+	if err != nil {
+		return ForwardRequest{}, nil, err
+	}
+	// The forwarder verifies signatures with v of 27 or 28
+	sig[64] += 27
+	return req, sig, nil
+}
+
+// forwarderNonce returns the forwarder's nonce of from, which the next request of from must have
+func (w *writer) forwarderNonce(ctx context.Context, from common.Address) (*big.Int, error) {
+	calldata, err := forwarderABI.Pack("getNonce", from)
+	if err != nil {
+		return nil, err
+	}
+	out, err := w.conn.Backend().CallContract(ctx, eth.CallMsg{From: from, To: &w.cfg.forwarderAddress, Data: calldata}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get forwarder nonce: %w", err)
+	}
+	values, err := forwarderABI.Unpack("getNonce", out)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get forwarder nonce: %w", err)
+	}
+	return values[0].(*big.Int), nil
+}
+
+// forwardedTx returns a transaction calling the forwarder with calldata, with tx's nonce and fees. tx's value is
+// sent with the request.
+func forwardedTx(tx *types.Transaction, forwarder common.Address, calldata []byte) *types.Transaction {
+	gas := tx.Gas() + ForwarderGasOverhead
+	if tx.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{ChainID: tx.ChainId(), Nonce: tx.Nonce(), GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap(), Gas: gas, To: &forwarder, Value: tx.Value(), Data: calldata})
+	}
+	return types.NewTx(&types.LegacyTx{Nonce: tx.Nonce(), GasPrice: tx.GasPrice(), Gas: gas, To: &forwarder, Value: tx.Value(), Data: calldata})
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	signercore "github.com/ethereum/go-ethereum/signer/core"
+)
+
+func TestForwardRequestHash(t *testing.T) {
+	forwarder := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	req := ForwardRequest{
+		From:  common.HexToAddress("0xff93B45308FD417dF303D6515aB04D9e89a750Ca"),
+		To:    common.HexToAddress("0x2b6351b3e9b1e3a2cd99f318b4b5a95edc02b6c1"),
+		Value: big.NewInt(0),
+		Gas:   big.NewInt(300000),
+		Nonce: big.NewInt(7),
+		Data:  common.FromHex("0xdeadbeef"),
+	}
+
+	// Forward requests are EIP-712 typed data
+	typedData := signercore.TypedData{
+		Types: signercore.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain:      signercore.TypedDataDomain{Name: ForwarderName, Version: ForwarderVersion, ChainId: math.NewHexOrDecimal256(5), VerifyingContract: forwarder.Hex()},
+		Message: signercore.TypedDataMessage{
+			"from":  req.From.Hex(),
+			"to":    req.To.Hex(),
+			"value": "0",
+			"gas":   "300000",
+			"nonce": "7",
+			"data":  hexutil.Encode(req.Data),
+		},
+	}
+	domain, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+
+	actual := ForwardRequestHash(big.NewInt(5), forwarder, req)
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected.Hex(), actual.Hex())
+	}
+}
+
+// newForwarderWriter returns a writer on a mock connection that executes proposals through forwarder
+func newForwarderWriter(t *testing.T, backend *mockBackend, forwarder common.Address) *writer {
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("forwarder", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.forwarderAddress = forwarder
+	stop := make(chan int)
+	t.Cleanup(func() { close(stop) })
+	w := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.setContract(bridge)
+	return w
+}
+
+// executeForwarded executes m's proposal through the writer's forwarder
+func executeForwarded(t *testing.T, w *writer, m msg.Message, data []byte) *types.Transaction {
+	err := w.conn.LockAndUpdateOpts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w.executeViaForwarder(w.conn.Opts(), m, data)
+	w.conn.UnlockOpts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// unpackForwardRequest returns the request and signature of a call to the forwarder's execute
+func unpackForwardRequest(t *testing.T, calldata []byte) (ForwardRequest, []byte) {
+	execute := forwarderABI.Methods["execute"]
+	if len(calldata) < 4 || !bytes.Equal(calldata[:4], execute.ID) {
+		t.Fatal("expected a call to execute")
+	}
+	args, err := execute.Inputs.Unpack(calldata[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req ForwardRequest
+	err = execute.Inputs.Copy(&[]interface{}{&req, new([]byte)}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req, args[1].([]byte)
+}
+
+func TestWriter_ExecuteViaForwarder(t *testing.T) {
+	forwarder := common.HexToAddress("0x0000000000000000000000000000000000005678")
+	backend := newMockBackend()
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		if call.To == nil || *call.To != forwarder {
+			return nil, errMockUnsupported
+		}
+		return forwarderABI.Methods["getNonce"].Outputs.Pack(big.NewInt(3))
+	}
+	w := newForwarderWriter(t, backend, forwarder)
+
+	m := msg.NewGenericTransfer(1, 0, 2, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	data := ConstructGenericProposalData([]byte("metadata"))
+	tx := executeForwarded(t, w, m, data)
+
+	if tx.To() == nil || *tx.To() != forwarder {
+		t.Fatalf("expected a transaction to the forwarder, got: %v", tx.To())
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(backend.chainID), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != AliceKp.CommonAddress() {
+		t.Fatalf("expected the transaction to be sent by the relayer, got: %s", sender.Hex())
+	}
+
+	req, sig := unpackForwardRequest(t, tx.Data())
+	calldata, err := bridgeABI.Pack("executeProposal", uint8(m.Source), uint64(m.DepositNonce), data, [32]byte(m.ResourceId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.From != AliceKp.CommonAddress() || req.To != w.cfg.bridgeContract || !bytes.Equal(req.Data, calldata) {
+		t.Fatalf("expected a request from the relayer executing the proposal, got: %+v", req)
+	}
+	if req.Nonce.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected the forwarder nonce 3, got: %s", req.Nonce)
+	}
+	if tx.Gas() != req.Gas.Uint64()+ForwarderGasOverhead {
+		t.Fatalf("expected gas %d, got: %d", req.Gas.Uint64()+ForwarderGasOverhead, tx.Gas())
+	}
+
+	// The forwarder recovers the signer from the signature with v of 27 or 28
+	hash := ForwardRequestHash(backend.chainID, forwarder, req)
+	recoverable := append([]byte{}, sig...)
+	recoverable[64] -= 27
+	pub, err := crypto.SigToPub(hash.Bytes(), recoverable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != AliceKp.CommonAddress() {
+		t.Fatalf("expected the request to be signed by the relayer, got: %s", crypto.PubkeyToAddress(*pub).Hex())
+	}
+}
+
+func TestWriter_ExecuteViaMockForwarder(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	forwarder := ethtest.DeployMockForwarder(t, client, big.NewInt(5))
+
+	cfg := createConfig("bob", nil, nil)
+	cfg.bridgeContract = common.HexToAddress("0x0000000000000000000000000000000000001234")
+	cfg.forwarderAddress = forwarder
+	conn := newLocalConnection(t, cfg)
+	defer conn.Close()
+	stop := make(chan int)
+	defer close(stop)
+	w := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.setContract(bridge)
+
+	m := msg.NewGenericTransfer(1, 0, 2, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	tx := executeForwarded(t, w, m, ConstructGenericProposalData([]byte("metadata")))
+	receipt, err := bind.WaitMined(context.Background(), conn.Client(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("expected the forwarded execution to succeed")
+	}
+
+	req, _ := unpackForwardRequest(t, tx.Data())
+	if req.Nonce.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("expected the forwarder nonce 5, got: %s", req.Nonce)
+	}
+	// The mock forwarder stores the address that called execute
+	slot, err := conn.Client().StorageAt(context.Background(), forwarder, common.Hash{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToAddress(slot) != conn.Keypair().CommonAddress() {
+		t.Fatalf("expected execute to be called by the relayer, got: %s", common.BytesToAddress(slot).Hex())
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build go1.18
+// +build go1.18
+
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// FuzzParseDepositLog checks malformed logs are rejected rather than causing a panic. Topics are fuzzed as a single
+// byte slice split into 32 byte hashes. Run with: go test -run '^$' -fuzz=FuzzParseDepositLog ./chains/ethereum
+func FuzzParseDepositLog(f *testing.F) {
+	topics := func(event utils.EventSig, dest, nonce int64) []byte {
+		var b []byte
+		b = append(b, event.GetTopic().Bytes()...)
+		b = append(b, common.BigToHash(big.NewInt(dest)).Bytes()...)
+		b = append(b, bytes.Repeat([]byte{1}, 32)...)
+		return append(b, common.BigToHash(big.NewInt(nonce)).Bytes()...)
+	}
+	permit := make([]byte, 128)
+	permit[31], permit[63] = 100, 27
+
+	f.Add([]byte{}, topics(utils.Deposit, 1, 1))
+	f.Add(permit, topics(utils.PermitDeposited, 2, 100))
+	f.Add(permit[:96], topics(utils.PermitDeposited, 2, 100))
+	f.Add([]byte{}, topics(utils.Deposit, 1, 1)[:96])
+	f.Add([]byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, data, topicBytes []byte) {
+		log := ethtypes.Log{Data: data}
+		for i := 0; i+32 <= len(topicBytes); i += 32 {
+			log.Topics = append(log.Topics, common.BytesToHash(topicBytes[i:i+32]))
+		}
+
+		deposit, err := parseDepositLog(log)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDepositLog) {
+				t.Fatalf("expected ErrInvalidDepositLog, got: %v", err)
+			}
+			return
+		}
+		if len(log.Topics) != 4 {
+			t.Fatalf("parsed a log with %d topics", len(log.Topics))
+		}
+		if uint64(deposit.destId) != log.Topics[1].Big().Uint64() || uint64(deposit.nonce) != log.Topics[3].Big().Uint64() {
+			t.Fatalf("parsed destination %d and nonce %d do not match the topics", deposit.destId, deposit.nonce)
+		}
+		if (deposit.permit != nil) != (log.Topics[0] == utils.PermitDeposited.GetTopic()) {
+			t.Fatal("expected a permit only for PermitDeposited logs")
+		}
+	})
+}
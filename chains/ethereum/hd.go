@@ -0,0 +1,99 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// derivationPathSeparator separates a derivation path from the address in the from field,
+// eg. 0xff93B45308FD417dF303D6515aB04D9e89a750Ca/m/44'/60'/0'/0/1
+const derivationPathSeparator = "/m/"
+
+// ErrInvalidChildKey is returned for the rare indexes that derive an invalid key, the next index should be used instead
+var ErrInvalidChildKey = errors.New("derived key is invalid")
+
+// hardenedIndex is the first index of hardened keys, which are derived from the parent private key
+const hardenedIndex = 0x80000000
+
+var masterKeySecret = []byte("Bitcoin seed")
+
+// splitDerivationPath separates from into the address of the key and the derivation path suffix, if any
+func splitDerivationPath(from string) (string, string) {
+	i := strings.Index(from, derivationPathSeparator)
+	if i < 0 {
+		return from, ""
+	}
+	return from[:i], from[i+1:]
+}
+
+// DeriveChild derives the key at the BIP-32 path (eg. m/44'/60'/0'/0/1) from kp, using kp's private key as the seed
+// of the master key. Different relayers can then share a single root key.
+func DeriveChild(kp *secp256k1.Keypair, path string) (*secp256k1.Keypair, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(kp.Encode())
+	key, chainCode, err := splitExtendedKey(mac.Sum(nil), new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range derivationPath {
+		key, chainCode, err = deriveChildKey(key, chainCode, index)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", derivationPath, err)
+		}
+	}
+	return secp256k1.NewKeypairFromPrivateKey(math.PaddedBigBytes(key, 32))
+}
+
+// deriveChildKey returns the private key and chain code of the child at index
+func deriveChildKey(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= hardenedIndex {
+		data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+	} else {
+		priv, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&priv.PublicKey)
+	}
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	return splitExtendedKey(mac.Sum(nil), key)
+}
+
+// splitExtendedKey returns the key, tweaked by parent, and the chain code of an HMAC-SHA512 output
+func splitExtendedKey(sum []byte, parent *big.Int) (*big.Int, []byte, error) {
+	n := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, nil, ErrInvalidChildKey
+	}
+	key := tweak.Add(tweak, parent)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, nil, ErrInvalidChildKey
+	}
+	return key, sum[32:], nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDeriveChild(t *testing.T) {
+	addresses := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		child, err := DeriveChild(AliceKp, fmt.Sprintf("m/44'/60'/0'/0/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.Address() == AliceKp.Address() {
+			t.Fatalf("child %d has the address of its root key", i)
+		}
+		if addresses[child.Address()] {
+			t.Fatalf("child %d has a duplicate address %s", i, child.Address())
+		}
+		addresses[child.Address()] = true
+	}
+
+	// Derivation is deterministic
+	first, err := DeriveChild(AliceKp, "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := DeriveChild(AliceKp, "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Address() != again.Address() {
+		t.Fatalf("derived %s then %s from the same path", first.Address(), again.Address())
+	}
+
+	second, err := DeriveChild(AliceKp, "m/44'/60'/0'/0/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("message"))
+	sigA, err := crypto.Sign(hash, first.PrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigB, err := crypto.Sign(hash, second.PrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sigA, sigB) {
+		t.Fatal("children 0 and 1 produced the same signature")
+	}
+
+	_, err = DeriveChild(AliceKp, "m/44'/60'/x")
+	if err == nil {
+		t.Fatal("expected an invalid path to fail")
+	}
+}
+
+func TestSplitDerivationPath(t *testing.T) {
+	addr, path := splitDerivationPath("0xff93B45308FD417dF303D6515aB04D9e89a750Ca/m/44'/60'/0'/0/3")
+	if addr != "0xff93B45308FD417dF303D6515aB04D9e89a750Ca" || path != "m/44'/60'/0'/0/3" {
+		t.Fatalf("unexpected split: %s %s", addr, path)
+	}
+
+	addr, path = splitDerivationPath("0xff93B45308FD417dF303D6515aB04D9e89a750Ca")
+	if addr != "0xff93B45308FD417dF303D6515aB04D9e89a750Ca" || path != "" {
+		t.Fatalf("unexpected split: %s %s", addr, path)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var rpcHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_rpc_healthy",
+	Help: "1 if the chain's node passed the last health check, 0 if it is being reconnected",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(rpcHealthy)
+}
+
+// healthChecker checks the node's latest block every healthCheckInterval. The connection is unhealthy if the
+// block cannot be queried, or has not advanced within maxBlockAge, and is then reconnected.
+type healthChecker struct {
+	cfg       *Config
+	conn      func() Connection // Returns the connection to check, which changes when it is reconnected
+	reconnect func() error
+	log       log15.Logger
+	stop      <-chan int
+	now       func() time.Time
+
+	lastBlock    *big.Int  // Latest block seen, nil until the first check after connecting
+	lastAdvanced time.Time // When lastBlock was first seen
+}
+
+func newHealthChecker(cfg *Config, conn func() Connection, reconnect func() error, log log15.Logger, stop <-chan int) *healthChecker {
+	return &healthChecker{cfg: cfg, conn: conn, reconnect: reconnect, log: log, stop: stop, now: time.Now}
+}
+
+// start checks the connection until stop is closed
+func (h *healthChecker) start() {
+	go func() {
+		ticker := time.NewTicker(h.cfg.healthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				h.check()
+			}
+		}
+	}()
+}
+
+// check queries the latest block, reconnecting if the connection is unhealthy. It returns whether it was healthy.
+func (h *healthChecker) check() bool {
+	latest, err := h.conn().LatestBlock()
+	now := h.now()
+	switch {
+	case err != nil:
+		h.log.Warn("Node health check failed, reconnecting", "err", err)
+	case h.lastBlock == nil || latest.Cmp(h.lastBlock) > 0:
+		h.lastBlock = latest
+		h.lastAdvanced = now
+		rpcHealthy.WithLabelValues(h.cfg.name).Set(1)
+		return true
+	case now.Sub(h.lastAdvanced) <= h.cfg.maxBlockAge:
+		rpcHealthy.WithLabelValues(h.cfg.name).Set(1)
+		return true
+	default:
+		h.log.Warn("Node's latest block is stale, reconnecting", "block", latest, "lastSeen", h.lastBlock, "since", now.Sub(h.lastAdvanced).Round(time.Second))
+	}
+
+	rpcHealthy.WithLabelValues(h.cfg.name).Set(0)
+	err = h.reconnect()
+	if err != nil {
+		h.log.Error("Failed to reconnect, retrying at the next health check", "err", err)
+		return false
+	}
+	// The new node may be behind the last, so blocks are compared from the next check
+	h.lastBlock = nil
+	h.log.Info("Reconnected to node")
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHealthChecker(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("health", big.NewInt(0), nil)
+	cfg.healthCheckInterval = 30 * time.Second
+	cfg.maxBlockAge = 2 * time.Minute
+
+	reconnects := 0
+	reconnect := func() error {
+		reconnects++
+		return nil
+	}
+	checker := newHealthChecker(cfg, func() Connection { return conn }, reconnect, newTestLogger(cfg.name), make(chan int))
+	now := time.Unix(1000, 0)
+	checker.now = func() time.Time { return now }
+
+	if !checker.check() {
+		t.Fatal("expected the first check to be healthy")
+	}
+	if healthy := testutil.ToFloat64(rpcHealthy.WithLabelValues(cfg.name)); healthy != 1 {
+		t.Fatalf("expected chainbridge_rpc_healthy 1, got: %v", healthy)
+	}
+
+	// The block is stuck for 5 checks, the last of which is more than maxBlockAge after the block was first seen
+	for i := 1; i <= 5; i++ {
+		now = now.Add(cfg.healthCheckInterval)
+		healthy := checker.check()
+		if i < 5 && (!healthy || reconnects != 0) {
+			t.Fatalf("check %d: expected healthy without reconnecting", i)
+		}
+		if i == 5 && (healthy || reconnects != 1) {
+			t.Fatalf("check %d: expected a reconnect, got %d", i, reconnects)
+		}
+	}
+	if healthy := testutil.ToFloat64(rpcHealthy.WithLabelValues(cfg.name)); healthy != 0 {
+		t.Fatalf("expected chainbridge_rpc_healthy 0, got: %v", healthy)
+	}
+
+	// After reconnecting the block is compared from the next check, and an advancing block is healthy
+	for i := int64(1); i <= 6; i++ {
+		now = now.Add(cfg.healthCheckInterval)
+		backend.setHead(100 + i)
+		if !checker.check() {
+			t.Fatalf("expected an advancing block to be healthy")
+		}
+	}
+	if reconnects != 1 {
+		t.Fatalf("expected 1 reconnect, got: %d", reconnects)
+	}
+
+	// A failed query reconnects at once
+	backend.err = errors.New("connection refused")
+	if checker.check() || reconnects != 2 {
+		t.Fatalf("expected a failed query to reconnect, got %d reconnects", reconnects)
+	}
+}
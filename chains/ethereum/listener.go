@@ -7,7 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
@@ -15,23 +17,52 @@ import (
 	"github.com/ChainSafe/ChainBridge/bindings/ERC721Handler"
 	"github.com/ChainSafe/ChainBridge/bindings/GenericHandler"
 	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/ChainBridge/ipfs"
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/ChainBridge/metadata"
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	"github.com/ChainSafe/chainbridge-utils/blockstore"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
 	eth "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var BlockRetryInterval = time.Second * 5
 var BlockRetryLimit = 5
 var ErrFatalPolling = errors.New("listener block polling failed")
+var ErrUnrecognizedHandler = errors.New("event has unrecognized handler")
+var ErrDepositNotFound = errors.New("deposit not found")
+var ErrOversizedMessage = errors.New("message exceeds maxMessageBytes")
+var ErrInvalidDepositLog = errors.New("invalid deposit log")
+
+var blockstoreLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_blockstore_lag_blocks",
+	Help: "Number of blocks between the chain head and the last block written to the blockstore",
+}, []string{"chain"})
+
+var oversizedMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_oversized_messages_total",
+	Help: "Number of deposits not relayed because their data exceeds maxMessageBytes",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(blockstoreLag)
+	prometheus.MustRegister(oversizedMessages)
+}
 
 type listener struct {
 	cfg                    Config
 	conn                   Connection
+	connLock               sync.RWMutex       // Guards conn, ownsConn, the contracts and uriFetcher, which are replaced on restart
+	kp                     *secp256k1.Keypair // Signs routed messages, nil if the key is held by Fireblocks
+	kpLock                 sync.RWMutex
 	router                 chains.Router
 	bridgeContract         *Bridge.Bridge // instance of bound bridge contract
 	erc20HandlerContract   *ERC20Handler.ERC20Handler
@@ -44,29 +75,114 @@ type listener struct {
 	latestBlock            metrics.LatestBlock
 	metrics                *metrics.ChainMetrics
 	blockConfirmations     *big.Int
+	resourceIds            map[ethcommon.Address]msg.ResourceId // erc20 token to resource ID, from ResourceIDSet events
+	resourceLock           sync.RWMutex
+	verifier               *ProofVerifier // Verifies deposits before routing them in trustless mode
+	lagging                bool           // Whether the blockstore lag is above the alert threshold
+	watchdog               *WatchdogTimer
+	reconnect              func() (Connection, *boundContracts, error) // Opens a new connection when restarting, restarts are disabled if nil
+	ownsConn               bool                                        // Whether conn was opened by a restart rather than shared with the writer
+	pollStop               chan struct{}                               // Closed to abandon the current polling routine
+	nextBlock              *big.Int                                    // Next block to be processed
+	nextBlockLock          sync.Mutex
+	metadataStore          *ipfs.MetadataStore // Stores large generic metadata, which is always relayed in full if nil
+	blockFilter            func(*big.Int) bool // Blocks it returns false for are skipped, all blocks are processed if nil
+	maxBlocksPerPoll       uint64              // Most blocks whose events are fetched in a single poll
+	ready                  chan struct{}       // Closed once the first poll of the latest block succeeds
+	readyOnce              sync.Once
+	pauseAtBlock           *big.Int // Polling waits for Resume once this block is processed, nil if no pause is set
+	pauseLock              sync.Mutex
+	resumeChan             chan struct{}       // Signalled by Resume to wake a paused listener
+	normalizer             *DecimalNormalizer  // Scales erc20 amounts to the destination token's decimals, amounts are unchanged if nil
+	uriFetcher             metadata.URIFetcher // Fetches the URI of erc721 deposits without metadata, URIs are not fetched if nil
+	firstBlock             *big.Int            // The start block when the listener was created
+	storedBlock            *big.Int            // Latest block written to the blockstore, nil until one is stored
+	pendingFrom, pendingTo *big.Int            // Blocks being processed but not yet stored, nil between polls
+	rangeLock              sync.Mutex
+	anomalies              *DepositAnomalyDetector // Flags suspicious deposits, nil if no anomaly rules are configured
 }
 
 // NewListener creates and returns a listener
 func NewListener(conn Connection, cfg *Config, log log15.Logger, bs blockstore.Blockstorer, stop <-chan int, sysErr chan<- error, m *metrics.ChainMetrics) *listener {
-	return &listener{
+	l := &listener{
 		cfg:                *cfg,
 		conn:               conn,
-		log:                log,
+		log:                log.New("chain_name", registry.NameOf(cfg.id)),
 		blockstore:         bs,
 		stop:               stop,
 		sysErr:             sysErr,
 		latestBlock:        metrics.LatestBlock{LastUpdated: time.Now()},
 		metrics:            m,
 		blockConfirmations: cfg.blockConfirmations,
+		resourceIds:        make(map[ethcommon.Address]msg.ResourceId),
+		ready:              make(chan struct{}),
+		resumeChan:         make(chan struct{}, 1),
 	}
+	if conn != nil {
+		l.kp = conn.Keypair()
+	}
+	if cfg.startBlock != nil {
+		l.firstBlock = new(big.Int).Set(cfg.startBlock)
+	}
+	if cfg.maxAmountPerBlock != nil || cfg.maxDepositsPerAddressPerMinute > 0 {
+		l.anomalies = NewDepositAnomalyDetector(cfg.maxAmountPerBlock, cfg.maxDepositsPerAddressPerMinute)
+	}
+	l.SetMaxBlocksPerPoll(cfg.maxBlocksPerPoll)
+	return l
 }
 
 // setContracts sets the listener with the appropriate contracts
 func (l *listener) setContracts(bridge *Bridge.Bridge, erc20Handler *ERC20Handler.ERC20Handler, erc721Handler *ERC721Handler.ERC721Handler, genericHandler *GenericHandler.GenericHandler) {
+	l.connLock.Lock()
+	defer l.connLock.Unlock()
 	l.bridgeContract = bridge
 	l.erc20HandlerContract = erc20Handler
 	l.erc721HandlerContract = erc721Handler
 	l.genericHandlerContract = genericHandler
+	if l.cfg.fetchURI {
+		l.uriFetcher = metadata.NewERC721URIFetcher(l.conn.Backend(), l.conn.CallOpts)
+	}
+}
+
+// connection returns the connection in use, which is replaced when the listener restarts
+func (l *listener) connection() Connection {
+	l.connLock.RLock()
+	defer l.connLock.RUnlock()
+	return l.conn
+}
+
+// contracts returns the contracts bound to the connection in use
+func (l *listener) contracts() boundContracts {
+	l.connLock.RLock()
+	defer l.connLock.RUnlock()
+	return boundContracts{
+		bridge:         l.bridgeContract,
+		erc20Handler:   l.erc20HandlerContract,
+		erc721Handler:  l.erc721HandlerContract,
+		genericHandler: l.genericHandlerContract,
+	}
+}
+
+// tokenURIFetcher returns the fetcher using the connection in use, or nil if URIs are not fetched
+func (l *listener) tokenURIFetcher() metadata.URIFetcher {
+	l.connLock.RLock()
+	defer l.connLock.RUnlock()
+	return l.uriFetcher
+}
+
+// setMetadataStore sets the store used to relay large generic metadata as IPFS references
+func (l *listener) setMetadataStore(store *ipfs.MetadataStore) {
+	l.metadataStore = store
+}
+
+// setDecimalNormalizer sets the normalizer erc20 deposit amounts are scaled with
+func (l *listener) setDecimalNormalizer(n *DecimalNormalizer) {
+	l.normalizer = n
+}
+
+// setVerifier sets the ProofVerifier used in trustless mode
+func (l *listener) setVerifier(v *ProofVerifier) {
+	l.verifier = v
 }
 
 // sets the router
@@ -74,33 +190,251 @@ func (l *listener) setRouter(r chains.Router) {
 	l.router = r
 }
 
+// RotateKeypair is called when the chain's keypair is rotated, messages routed from then on are signed with kp
+func (l *listener) RotateKeypair(kp *secp256k1.Keypair) error {
+	l.kpLock.Lock()
+	defer l.kpLock.Unlock()
+	l.kp = kp
+	l.log.Debug("Listener keypair rotated", "relayer", kp.Address())
+	return nil
+}
+
+// keypair returns the keypair routed messages are signed with. The connection opened by a restart has no keypair,
+// so it is kept by the listener.
+func (l *listener) keypair() *secp256k1.Keypair {
+	l.kpLock.RLock()
+	defer l.kpLock.RUnlock()
+	return l.kp
+}
+
+// setReconnect sets the function used to open a new connection when the listener is restarted
+func (l *listener) setReconnect(reconnect func() (Connection, *boundContracts, error)) {
+	l.reconnect = reconnect
+}
+
+// SetBlockFilter sets a filter that is called with the block number of each event before it is processed. Events
+// in blocks it returns false for are skipped, but the blocks are still stored in the blockstore so they are not
+// scanned again. Must be called before the listener is started.
+func (l *listener) SetBlockFilter(filter func(*big.Int) bool) {
+	l.blockFilter = filter
+}
+
+// SetMaxBlocksPerPoll limits the range of blocks whose events are fetched in a single poll, so catching up does
+// not request tens of thousands of events at once. n is at least 1. Must be called before the listener is started.
+func (l *listener) SetMaxBlocksPerPoll(n uint64) {
+	if n == 0 {
+		n = 1
+	}
+	l.maxBlocksPerPoll = n
+}
+
+// PauseAt makes the listener wait for Resume once it has processed block, eg. to upgrade contracts at a known
+// block. The blocks after it are not processed until then. Replaces any pause already set.
+func (l *listener) PauseAt(block *big.Int) {
+	l.pauseLock.Lock()
+	defer l.pauseLock.Unlock()
+	l.pauseAtBlock = new(big.Int).Set(block)
+	// Discard a Resume sent before this pause was set
+	select {
+	case <-l.resumeChan:
+	default:
+	}
+	l.log.Info("Listener will pause", "block", block)
+}
+
+// Resume clears the pause set by PauseAt, continuing to process blocks if the listener is paused
+func (l *listener) Resume() {
+	l.pauseLock.Lock()
+	defer l.pauseLock.Unlock()
+	if l.pauseAtBlock == nil {
+		return
+	}
+	l.pauseAtBlock = nil
+	select {
+	case l.resumeChan <- struct{}{}:
+	default:
+	}
+	l.log.Info("Listener resumed")
+}
+
+// pauseBlock returns the block the listener pauses after, or nil if no pause is set
+func (l *listener) pauseBlock() *big.Int {
+	l.pauseLock.Lock()
+	defer l.pauseLock.Unlock()
+	return l.pauseAtBlock
+}
+
+// waitPaused blocks while a pause is set at a block before current, sending watchdog heartbeats so the paused
+// listener is not restarted. It returns false if the listener is stopped or abandoned while paused.
+func (l *listener) waitPaused(current *big.Int, abandon <-chan struct{}) bool {
+	logged := false
+	for {
+		pause := l.pauseBlock()
+		if pause == nil || current.Cmp(pause) <= 0 {
+			return true
+		}
+		if !logged {
+			l.log.Info("Listener paused, waiting to resume", "block", pause)
+			logged = true
+		}
+		if l.watchdog != nil {
+			l.watchdog.Heartbeat()
+		}
+		select {
+		case <-l.stop:
+			return false
+		case <-abandon:
+			return false
+		case <-l.resumeChan:
+		case <-time.After(BlockRetryInterval):
+		}
+	}
+}
+
+// filtered returns whether block is skipped by the block filter
+func (l *listener) filtered(block *big.Int) bool {
+	return l.blockFilter != nil && !l.blockFilter(new(big.Int).Set(block))
+}
+
+// skipBlocksFilter returns a block filter that skips the listed blocks
+func skipBlocksFilter(blocks []uint64) func(*big.Int) bool {
+	skip := make(map[uint64]bool, len(blocks))
+	for _, block := range blocks {
+		skip[block] = true
+	}
+	return func(block *big.Int) bool {
+		return !block.IsUint64() || !skip[block.Uint64()]
+	}
+}
+
+// SetStartBlockByTimestamp sets the start block to the last block at or before ts
+func (l *listener) SetStartBlockByTimestamp(ts time.Time) error {
+	block, err := l.connection().BlockByTimestamp(context.Background(), ts)
+	if err != nil {
+		return err
+	}
+	l.log.Info("Starting from block at start time", "startTime", ts, "block", block)
+	l.cfg.startBlock = block
+	return nil
+}
+
 // start registers all subscriptions provided by the config
 func (l *listener) start() error {
 	l.log.Debug("Starting listener...")
 
-	go func() {
-		err := l.pollBlocks()
+	err := l.replayResourceIDEvents()
+	if err != nil {
+		return err
+	}
+
+	// A stalled listener can only be restarted if it can reconnect
+	if l.reconnect != nil {
+		l.watchdog = NewWatchdogTimer(l.cfg.watchdogInterval, l.onWatchdogTimeout)
+		go l.watchdog.Run(l.stop)
+	}
+
+	l.startPolling(l.cfg.startBlock)
+	return nil
+}
+
+// startPolling polls blocks from startBlock in a new routine, watching for new heads on the connection in use
+func (l *listener) startPolling(startBlock *big.Int) {
+	l.pollStop = make(chan struct{})
+	go func(conn Connection, abandon <-chan struct{}) {
+		err := l.pollBlocks(conn, new(big.Int).Set(startBlock), abandon)
 		if err != nil {
 			l.log.Error("Polling blocks failed", "err", err)
 		}
-	}()
+	}(l.connection(), l.pollStop)
+}
 
+// onWatchdogTimeout restarts the listener when polling has not made progress within the watchdog interval
+func (l *listener) onWatchdogTimeout() {
+	l.log.Crit("Listener has stalled, restarting", "interval", l.cfg.watchdogInterval)
+	watchdogRestarts.WithLabelValues(l.cfg.name).Inc()
+	err := l.restart()
+	if err != nil {
+		l.log.Error("Failed to restart listener", "err", err)
+	}
+}
+
+// restart reconnects and resumes polling from the next unprocessed block. The stalled routine is abandoned,
+// if it unblocks it exits without routing deposits or storing blocks.
+func (l *listener) restart() error {
+	conn, contracts, err := l.reconnect()
+	if err != nil {
+		return err
+	}
+
+	close(l.pollStop)
+	l.connLock.Lock()
+	old, owned := l.conn, l.ownsConn
+	l.conn = conn
+	l.ownsConn = true
+	l.connLock.Unlock()
+	l.setContracts(contracts.bridge, contracts.erc20Handler, contracts.erc721Handler, contracts.genericHandler)
+	// The original connection is shared with the writer, so is left open
+	if owned {
+		old.Close()
+	}
+
+	l.nextBlockLock.Lock()
+	next := l.nextBlock
+	l.nextBlockLock.Unlock()
+	if next == nil {
+		next = l.cfg.startBlock
+	}
+
+	l.log.Info("Restarting listener", "block", next)
+	l.startPolling(next)
 	return nil
 }
 
-// pollBlocks will poll for the latest block and proceed to parse the associated events as it sees new blocks.
-// Polling begins at the block defined in `l.cfg.startBlock`. Failed attempts to fetch the latest block or parse
-// a block will be retried up to BlockRetryLimit times before continuing to the next block.
-func (l *listener) pollBlocks() error {
-	var currentBlock = l.cfg.startBlock
+// closeConn closes the connection opened by a restart. The original connection is shared with the writer, so is
+// left open.
+func (l *listener) closeConn() {
+	l.connLock.RLock()
+	defer l.connLock.RUnlock()
+	if l.ownsConn {
+		l.conn.Close()
+	}
+}
+
+// pollBlocks will watch for new heads and proceed to parse the associated events as it sees new blocks.
+// New heads are watched for on conn. Polling begins at currentBlock and stops without error if abandon is closed.
+// Failed attempts to watch for new heads or parse a block will be retried up to BlockRetryLimit times before
+// continuing to the next block.
+func (l *listener) pollBlocks(conn Connection, currentBlock *big.Int, abandon <-chan struct{}) error {
 	l.log.Info("Polling Blocks...", "block", currentBlock)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	headers := make(chan *ethtypes.Header)
+	watchErr := make(chan error, 1)
+	watch := func() {
+		go func() {
+			watchErr <- conn.WatchBlockHeaders(ctx, headers)
+		}()
+	}
+	watch()
+
+	var latestBlock *big.Int
 	var retry = BlockRetryLimit
 	for {
 		select {
 		case <-l.stop:
 			return errors.New("polling terminated")
+		case <-abandon:
+			return nil
 		default:
+			if l.watchdog != nil {
+				l.watchdog.Heartbeat()
+			}
+
+			if !l.waitPaused(currentBlock, abandon) {
+				continue
+			}
+
 			// No more retries, goto next block
 			if retry == 0 {
 				l.log.Error("Polling failed, retries exceeded")
@@ -108,99 +442,455 @@ func (l *listener) pollBlocks() error {
 				return nil
 			}
 
-			latestBlock, err := l.conn.LatestBlock()
-			if err != nil {
-				l.log.Error("Unable to get latest block", "block", currentBlock, "err", err)
-				retry--
-				time.Sleep(BlockRetryInterval)
+			// Wait for a new head if the current block does not have blockConfirmations yet
+			var endBlock *big.Int
+			ready := false
+			if latestBlock != nil {
+				endBlock, ready = l.pollRange(currentBlock, latestBlock)
+			}
+			if !ready {
+				select {
+				case <-l.stop:
+					return errors.New("polling terminated")
+				case <-abandon:
+					return nil
+				case header := <-headers:
+					latestBlock = header.Number
+					l.readyOnce.Do(func() { close(l.ready) })
+					if l.metrics != nil {
+						l.metrics.LatestKnownBlock.Set(float64(latestBlock.Int64()))
+					}
+				case err := <-watchErr:
+					l.log.Error("Unable to get latest block", "block", currentBlock, "err", err)
+					retry--
+					time.Sleep(BlockRetryInterval)
+					watch()
+				case <-time.After(BlockRetryInterval):
+					l.log.Debug("Block not ready, will retry", "target", currentBlock, "latest", latestBlock)
+				}
 				continue
 			}
 
-			if l.metrics != nil {
-				l.metrics.LatestKnownBlock.Set(float64(latestBlock.Int64()))
-			}
+			l.setPendingRange(currentBlock, endBlock)
 
-			// Sleep if the difference is less than BlockDelay; (latest - current) < BlockDelay
-			if big.NewInt(0).Sub(latestBlock, currentBlock).Cmp(l.blockConfirmations) == -1 {
-				l.log.Debug("Block not ready, will retry", "target", currentBlock, "latest", latestBlock)
-				time.Sleep(BlockRetryInterval)
+			// Parse out events, resource IDs first so deposits in the range can use them
+			err := l.getResourceIDEventsForRange(currentBlock, endBlock)
+			if err != nil {
+				l.log.Error("Failed to get resource ID events for blocks", "start", currentBlock, "end", endBlock, "err", err)
+				retry--
 				continue
 			}
 
-			// Parse out events
-			err = l.getDepositEventsForBlock(currentBlock)
+			err = l.getDepositEventsForRange(currentBlock, endBlock, abandon)
 			if err != nil {
-				l.log.Error("Failed to get events for block", "block", currentBlock, "err", err)
+				l.log.Error("Failed to get events for blocks", "start", currentBlock, "end", endBlock, "err", err)
 				retry--
 				continue
 			}
 
+			// The replacement routine processes these blocks again, so they are not stored here
+			if isClosed(abandon) {
+				return nil
+			}
+
 			// Write to block store. Not a critical operation, no need to retry
-			err = l.blockstore.StoreBlock(currentBlock)
+			err = l.blockstore.StoreBlock(endBlock)
 			if err != nil {
-				l.log.Error("Failed to write latest block to blockstore", "block", currentBlock, "err", err)
+				l.log.Error("Failed to write latest block to blockstore", "block", endBlock, "err", err)
+			} else {
+				l.updateBlockstoreLag(latestBlock, endBlock)
+				l.setStoredBlock(endBlock)
 			}
+			l.setPendingRange(nil, nil)
 
+			processed := new(big.Int).Sub(endBlock, currentBlock)
+			processed.Add(processed, big.NewInt(1))
 			if l.metrics != nil {
-				l.metrics.BlocksProcessed.Inc()
+				l.metrics.BlocksProcessed.Add(float64(processed.Int64()))
 				l.metrics.LatestProcessedBlock.Set(float64(latestBlock.Int64()))
 			}
 
 			l.latestBlock.Height = big.NewInt(0).Set(latestBlock)
 			l.latestBlock.LastUpdated = time.Now()
 
-			// Goto next block and reset retry counter
-			currentBlock.Add(currentBlock, big.NewInt(1))
+			// Goto the block after the range and reset retry counter
+			currentBlock.Add(endBlock, big.NewInt(1))
+			l.nextBlockLock.Lock()
+			l.nextBlock = new(big.Int).Set(currentBlock)
+			l.nextBlockLock.Unlock()
 			retry = BlockRetryLimit
 		}
 	}
 }
 
-// getDepositEventsForBlock looks for the deposit event in the latest block
-func (l *listener) getDepositEventsForBlock(latestBlock *big.Int) error {
-	l.log.Debug("Querying block for deposit events", "block", latestBlock)
-	query := buildQuery(l.cfg.bridgeContract, utils.Deposit, latestBlock, latestBlock)
+// GetProcessedRange returns the start block of the listener and the latest block written to the blockstore. to is nil
+// until a block has been stored.
+func (l *listener) GetProcessedRange() (from, to *big.Int) {
+	l.rangeLock.Lock()
+	defer l.rangeLock.Unlock()
+	return copyBig(l.firstBlock), copyBig(l.storedBlock)
+}
+
+// GetPendingRange returns the blocks of the current poll, whose events are being processed but which have not been
+// written to the blockstore. Both are nil between polls.
+func (l *listener) GetPendingRange() (from, to *big.Int) {
+	l.rangeLock.Lock()
+	defer l.rangeLock.Unlock()
+	return copyBig(l.pendingFrom), copyBig(l.pendingTo)
+}
+
+func (l *listener) setPendingRange(from, to *big.Int) {
+	l.rangeLock.Lock()
+	defer l.rangeLock.Unlock()
+	l.pendingFrom, l.pendingTo = copyBig(from), copyBig(to)
+}
+
+func (l *listener) setStoredBlock(block *big.Int) {
+	l.rangeLock.Lock()
+	defer l.rangeLock.Unlock()
+	l.storedBlock = copyBig(block)
+}
+
+// isClosed returns whether ch has been closed, ch is never closed if nil
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// copyBig returns a copy of b, or nil if b is nil
+func copyBig(b *big.Int) *big.Int {
+	if b == nil {
+		return nil
+	}
+	return new(big.Int).Set(b)
+}
+
+// pollRange returns the last block to process in a poll starting at current, which is the latest block with
+// blockConfirmations but no more than maxBlocksPerPoll blocks after current or past the pause block. It returns
+// false if current is not confirmed yet.
+func (l *listener) pollRange(current, latest *big.Int) (*big.Int, bool) {
+	end := new(big.Int).Sub(latest, l.blockConfirmations)
+	if end.Cmp(current) == -1 {
+		return nil, false
+	}
+
+	max := new(big.Int).SetUint64(l.maxBlocksPerPoll - 1)
+	if limit := max.Add(max, current); end.Cmp(limit) == 1 {
+		end = limit
+	}
+	// Stop at the pause block, so the blocks after it are not processed until resumed
+	if pause := l.pauseBlock(); pause != nil && pause.Cmp(current) >= 0 && end.Cmp(pause) == 1 {
+		end = new(big.Int).Set(pause)
+	}
+	return end, true
+}
+
+// updateBlockstoreLag sets the blockstore lag gauge to the number of blocks stored is behind head. An error is
+// logged when the lag first exceeds the alert threshold, rather than for every block while catching up.
+func (l *listener) updateBlockstoreLag(head, stored *big.Int) {
+	lag := new(big.Int).Sub(head, stored)
+	blockstoreLag.WithLabelValues(l.cfg.name).Set(float64(lag.Int64()))
+
+	if lag.Cmp(l.cfg.lagAlertThreshold) == 1 {
+		if !l.lagging {
+			l.log.Error("Blockstore is falling behind the chain head", "lag", lag, "threshold", l.cfg.lagAlertThreshold)
+		}
+		l.lagging = true
+	} else if l.lagging {
+		l.log.Info("Blockstore has caught up with the chain head", "lag", lag)
+		l.lagging = false
+	}
+}
+
+// replayResourceIDEvents rebuilds the resource ID map from all ResourceIDSet events prior to the start block. The
+// events are fetched maxBlocksPerPoll blocks at a time from the erc20 handler's deployment block, or from block 0 if
+// the node cannot find it.
+func (l *listener) replayResourceIDEvents() error {
+	if l.cfg.erc20HandlerContract == utils.ZeroAddress || l.cfg.startBlock.Sign() == 0 {
+		return nil
+	}
+
+	startBlock := big.NewInt(0)
+	deployment, err := l.connection().GetContractDeploymentBlock(context.Background(), l.cfg.erc20HandlerContract)
+	if err != nil {
+		l.log.Warn("Unable to find the erc20 handler deployment block, replaying resource ID events from block 0", "handler", l.cfg.erc20HandlerContract.Hex(), "err", err)
+	} else {
+		startBlock = deployment
+	}
+
+	endBlock := new(big.Int).Sub(l.cfg.startBlock, big.NewInt(1))
+	l.log.Debug("Replaying resource ID events", "start", startBlock, "end", endBlock)
+	window := new(big.Int).SetUint64(l.maxBlocksPerPoll)
+	for from := startBlock; from.Cmp(endBlock) <= 0; from = new(big.Int).Add(from, window) {
+		to := new(big.Int).Add(from, window)
+		to.Sub(to, big.NewInt(1))
+		if to.Cmp(endBlock) == 1 {
+			to = endBlock
+		}
+		err = l.getResourceIDEvents(from, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getResourceIDEventsForRange looks for ResourceIDSet events from the erc20 handler from startBlock to endBlock
+func (l *listener) getResourceIDEventsForRange(startBlock, endBlock *big.Int) error {
+	if l.cfg.erc20HandlerContract == utils.ZeroAddress {
+		return nil
+	}
+	return l.getResourceIDEvents(startBlock, endBlock)
+}
+
+func (l *listener) getResourceIDEvents(startBlock, endBlock *big.Int) error {
+	query := buildQuery(l.cfg.erc20HandlerContract, utils.ResourceIDSet, startBlock, endBlock)
+
+	logs, err := l.connection().Backend().FilterLogs(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("unable to Filter Logs: %w", err)
+	}
+
+	for _, log := range logs {
+		if l.filtered(new(big.Int).SetUint64(log.BlockNumber)) {
+			continue
+		}
+		err = l.handleResourceIDSetEvent(log)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDepositEventsForRange looks for deposit events from startBlock to endBlock. It stops routing deposits once
+// abandon is closed, as the routine replacing this one routes them.
+func (l *listener) getDepositEventsForRange(startBlock, endBlock *big.Int, abandon <-chan struct{}) error {
+	l.log.Debug("Querying blocks for deposit events", "start", startBlock, "end", endBlock)
+	query := buildQuery(l.cfg.bridgeContract, utils.Deposit, startBlock, endBlock)
+	query.Topics[0] = append(query.Topics[0], utils.PermitDeposited.GetTopic())
 
 	// querying for logs
-	logs, err := l.conn.Client().FilterLogs(context.Background(), query)
+	logs, err := l.connection().Backend().FilterLogs(context.Background(), query)
 	if err != nil {
 		return fmt.Errorf("unable to Filter Logs: %w", err)
 	}
 
 	// read through the log events and handle their deposit event if handler is recognized
 	for _, log := range logs {
-		var m msg.Message
-		destId := msg.ChainId(log.Topics[1].Big().Uint64())
-		rId := msg.ResourceIdFromSlice(log.Topics[2].Bytes())
-		nonce := msg.Nonce(log.Topics[3].Big().Uint64())
+		if l.filtered(new(big.Int).SetUint64(log.BlockNumber)) {
+			l.log.Info("Skipping deposit in filtered block", "block", log.BlockNumber, "tx", log.TxHash)
+			continue
+		}
+		m, err := l.handleDepositLog(log)
+		if err == nil {
+			err = l.checkMessageSize(messageSize(m))
+		}
+		if errors.Is(err, ErrUnrecognizedHandler) {
+			l.log.Error("event has unrecognized handler", "err", err)
+			continue
+		} else if errors.Is(err, ErrInvalidDepositLog) {
+			l.log.Error("Deposit log is malformed, not routing", "tx", log.TxHash, "err", err)
+			continue
+		} else if errors.Is(err, ErrOversizedMessage) {
+			l.log.Warn("Deposit is too large, not routing", "tx", log.TxHash, "err", err)
+			oversizedMessages.WithLabelValues(l.cfg.name).Inc()
+			continue
+		} else if err != nil {
+			return err
+		}
 
-		addr, err := l.bridgeContract.ResourceIDToHandlerAddress(&bind.CallOpts{From: l.conn.Keypair().CommonAddress()}, rId)
-		if err != nil {
-			return fmt.Errorf("failed to get handler from resource ID %x", rId)
+		if l.cfg.trustlessMode {
+			err = l.verifier.VerifyDeposit(m, new(big.Int).SetUint64(log.BlockNumber))
+			if errors.Is(err, ErrInvalidProof) {
+				l.log.Error("Deposit failed proof verification, not routing", "dest", m.Destination, "nonce", m.DepositNonce, "err", err)
+				continue
+			} else if err != nil {
+				return err
+			}
 		}
 
-		if addr == l.cfg.erc20HandlerContract {
-			m, err = l.handleErc20DepositedEvent(destId, nonce)
-		} else if addr == l.cfg.erc721HandlerContract {
-			m, err = l.handleErc721DepositedEvent(destId, nonce)
-		} else if addr == l.cfg.genericHandlerContract {
-			m, err = l.handleGenericDepositedEvent(destId, nonce)
-		} else {
-			l.log.Error("event has unrecognized handler", "handler", addr.Hex())
+		if isClosed(abandon) {
 			return nil
 		}
+		l.checkAnomalies(m, log)
+		err = l.sendMessage(m)
+		if err != nil {
+			l.log.Error("subscription error: failed to route message", "err", err)
+		}
+	}
 
+	return nil
+}
+
+// checkMessageSize returns ErrOversizedMessage if size is over the configured maxMessageBytes
+func (l *listener) checkMessageSize(size uint64) error {
+	if size > l.cfg.maxMessageBytes {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrOversizedMessage, size, l.cfg.maxMessageBytes)
+	}
+	return nil
+}
+
+// messageSize returns the number of bytes in the message's payload
+func messageSize(m msg.Message) uint64 {
+	var size uint64
+	for _, item := range m.Payload {
+		if b, ok := item.([]byte); ok {
+			size += uint64(len(b))
+		}
+	}
+	return size
+}
+
+// depositLog holds the fields of a Deposit or PermitDeposited log
+type depositLog struct {
+	destId     msg.ChainId
+	resourceId msg.ResourceId
+	nonce      msg.Nonce
+	permit     *Permit // Set for PermitDeposited logs
+}
+
+// parseDepositLog decodes a Deposit or PermitDeposited log. Logs are returned by the node, so they are checked to
+// have the topics and data of the events rather than trusted to match the query.
+func parseDepositLog(log ethtypes.Log) (depositLog, error) {
+	if len(log.Topics) != 4 {
+		return depositLog{}, fmt.Errorf("%w: %d topics in tx %s", ErrInvalidDepositLog, len(log.Topics), log.TxHash.Hex())
+	}
+	destId := log.Topics[1].Big()
+	if !destId.IsUint64() || destId.Uint64() > math.MaxUint8 {
+		return depositLog{}, fmt.Errorf("%w: destination %s in tx %s", ErrInvalidDepositLog, destId, log.TxHash.Hex())
+	}
+	nonce := log.Topics[3].Big()
+	if !nonce.IsUint64() {
+		return depositLog{}, fmt.Errorf("%w: nonce %s in tx %s", ErrInvalidDepositLog, nonce, log.TxHash.Hex())
+	}
+	deposit := depositLog{
+		destId:     msg.ChainId(destId.Uint64()),
+		resourceId: msg.ResourceIdFromSlice(log.Topics[2].Bytes()),
+		nonce:      msg.Nonce(nonce.Uint64()),
+	}
+
+	switch log.Topics[0] {
+	case utils.Deposit.GetTopic():
+	case utils.PermitDeposited.GetTopic():
+		permit, err := parsePermit(log)
 		if err != nil {
-			return err
+			return depositLog{}, fmt.Errorf("%w: %s", ErrInvalidDepositLog, err)
+		}
+		deposit.permit = &permit
+	default:
+		return depositLog{}, fmt.Errorf("%w: unknown event %s in tx %s", ErrInvalidDepositLog, log.Topics[0].Hex(), log.TxHash.Hex())
+	}
+	return deposit, nil
+}
+
+// handleDepositLog builds the message for a Deposit or PermitDeposited event using the handler registered for its
+// resource ID
+func (l *listener) handleDepositLog(log ethtypes.Log) (msg.Message, error) {
+	deposit, err := parseDepositLog(log)
+	if err != nil {
+		return msg.Message{}, err
+	}
+	destId, rId, nonce := deposit.destId, deposit.resourceId, deposit.nonce
+
+	addr, err := l.contracts().bridge.ResourceIDToHandlerAddress(l.connection().CallOpts(), rId)
+	if err != nil {
+		return msg.Message{}, fmt.Errorf("failed to get handler from resource ID %x", rId)
+	}
+
+	if deposit.permit != nil {
+		if addr != l.cfg.erc20HandlerContract {
+			return msg.Message{}, fmt.Errorf("%w: permit deposit to %s", ErrUnrecognizedHandler, addr.Hex())
 		}
+		return l.handlePermitDepositedEvent(*deposit.permit, destId, nonce)
+	}
+
+	switch addr {
+	case l.cfg.erc20HandlerContract:
+		return l.handleErc20DepositedEvent(destId, nonce)
+	case l.cfg.erc721HandlerContract:
+		return l.handleErc721DepositedEvent(destId, nonce)
+	case l.cfg.genericHandlerContract:
+		return l.handleGenericDepositedEvent(destId, nonce, new(big.Int).SetUint64(log.BlockNumber))
+	default:
+		return msg.Message{}, fmt.Errorf("%w: %s", ErrUnrecognizedHandler, addr.Hex())
+	}
+}
+
+// getDeposit finds the Deposit event for the nonce and destination and builds its message. The logs are searched
+// maxBlocksPerPoll blocks at a time from the bridge's deployment block, or from block 0 if the node cannot find it.
+func (l *listener) getDeposit(destId msg.ChainId, nonce msg.Nonce) (msg.Message, error) {
+	conn := l.connection()
+	startBlock := big.NewInt(0)
+	deployment, err := conn.GetContractDeploymentBlock(context.Background(), l.cfg.bridgeContract)
+	if err != nil {
+		l.log.Warn("Unable to find the bridge deployment block, searching for the deposit from block 0", "bridge", l.cfg.bridgeContract.Hex(), "err", err)
+	} else {
+		startBlock = deployment
+	}
+	endBlock, err := conn.LatestBlock()
+	if err != nil {
+		return msg.Message{}, fmt.Errorf("unable to get latest block: %w", err)
+	}
 
-		err = l.router.Send(m)
+	window := new(big.Int).SetUint64(l.maxBlocksPerPoll)
+	for from := startBlock; from.Cmp(endBlock) <= 0; from = new(big.Int).Add(from, window) {
+		to := new(big.Int).Add(from, window)
+		to.Sub(to, big.NewInt(1))
+		if to.Cmp(endBlock) == 1 {
+			to = endBlock
+		}
+		query := eth.FilterQuery{
+			FromBlock: from,
+			ToBlock:   to,
+			Addresses: []ethcommon.Address{l.cfg.bridgeContract},
+			Topics: [][]ethcommon.Hash{
+				{utils.Deposit.GetTopic(), utils.PermitDeposited.GetTopic()},
+				{ethcommon.BigToHash(big.NewInt(int64(destId)))},
+				nil,
+				{ethcommon.BigToHash(new(big.Int).SetUint64(uint64(nonce)))},
+			},
+		}
+
+		logs, err := conn.Backend().FilterLogs(context.Background(), query)
 		if err != nil {
-			l.log.Error("subscription error: failed to route message", "err", err)
+			return msg.Message{}, fmt.Errorf("unable to Filter Logs: %w", err)
+		}
+		if len(logs) != 0 {
+			return l.handleDepositLog(logs[0])
 		}
 	}
+	return msg.Message{}, fmt.Errorf("%w: nonce %d to chain %d", ErrDepositNotFound, nonce, destId)
+}
 
-	return nil
+// sendMessage passes the message to the router, waiting for room if the destination queue is full. If the router
+// routes signed messages the message is signed with the relayer keypair, unless the key is held by Fireblocks.
+func (l *listener) sendMessage(m msg.Message) error {
+	send := l.router.Send
+	if r, ok := l.router.(chains.SignedRouter); ok && l.keypair() != nil {
+		signed, err := message.Sign(m, l.keypair())
+		if err != nil {
+			return fmt.Errorf("unable to sign message: %w", err)
+		}
+		send = func(msg.Message) error { return r.SendSigned(signed) }
+	}
+
+	for {
+		err := send(m)
+		if !errors.Is(err, router.ErrQueueFull) {
+			return err
+		}
+		l.log.Warn("Router queue full, waiting to resend message", "dest", m.Destination, "nonce", m.DepositNonce)
+		select {
+		case <-l.stop:
+			return err
+		case <-time.After(BlockRetryInterval):
+		}
+	}
 }
 
 // buildQuery constructs a query for the bridgeContract by hashing sig to get the event topic
@@ -4,9 +4,15 @@
 package ethereum
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,13 +20,21 @@ import (
 	"github.com/ChainSafe/ChainBridge/bindings/ERC20Handler"
 	"github.com/ChainSafe/ChainBridge/bindings/ERC721Handler"
 	"github.com/ChainSafe/ChainBridge/bindings/GenericHandler"
+	"github.com/ChainSafe/ChainBridge/bindings/IERC721Metadata"
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
 	"github.com/ChainSafe/chainbridge-utils/blockstore"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 type MockRouter struct {
@@ -33,6 +47,18 @@ func (r *MockRouter) Send(message msg.Message) error {
 }
 
 func createTestListener(t *testing.T, config *Config, contracts *utils.DeployedContracts, stop <-chan int, sysErr chan<- error) (*listener, *MockRouter) {
+	listener, router := newTestListener(t, config, contracts, stop, sysErr)
+	// Start the listener
+	err := listener.start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return listener, router
+}
+
+// newTestListener creates a listener that starts from the latest block, without starting it
+func newTestListener(t *testing.T, config *Config, contracts *utils.DeployedContracts, stop <-chan int, sysErr chan<- error) (*listener, *MockRouter) {
 	// Create copy and add deployed contract addresses
 	newConfig := *config
 	newConfig.bridgeContract = contracts.BridgeAddress
@@ -68,11 +94,6 @@ func createTestListener(t *testing.T, config *Config, contracts *utils.DeployedC
 	listener := NewListener(conn, &newConfig, TestLogger, &blockstore.EmptyStore{}, stop, sysErr, nil)
 	listener.setContracts(bridgeContract, erc20HandlerContract, erc721HandlerContract, genericHandlerContract)
 	listener.setRouter(router)
-	// Start the listener
-	err = listener.start()
-	if err != nil {
-		t.Fatal(err)
-	}
 
 	return listener, router
 }
@@ -171,6 +192,176 @@ func TestListener_Erc20DepositedEvent(t *testing.T) {
 	verifyMessage(t, router, expectedMessage, errs)
 }
 
+func TestListener_SkipBlocks(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	errs := make(chan error)
+	l, router := newTestListener(t, aliceTestConfig, contracts, make(chan int), errs)
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	amount := big.NewInt(10)
+	dst := msg.ChainId(1)
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), 0))
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	// Deposit twice, in separate blocks
+	var blocks []uint64
+	for i := 0; i < 2; i++ {
+		tx := createErc20Deposit(t, l.bridgeContract, client, resourceId, recipient, dst, amount)
+		ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+		receipt, err := bind.WaitMined(ctx, client.Client, tx)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, receipt.BlockNumber.Uint64())
+	}
+
+	// The first deposit is skipped, so the second is the first message routed
+	l.SetBlockFilter(skipBlocksFilter(blocks[:1]))
+	err := l.start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMessage := msg.NewFungibleTransfer(0, dst, 2, amount, resourceId, recipient.Bytes())
+	verifyMessage(t, router, expectedMessage, errs)
+}
+
+func TestListener_Erc20MinimalProxyResourceId(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	errs := make(chan error)
+	l, _ := createTestListener(t, aliceTestConfig, contracts, make(chan int), errs)
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(0)))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	clone := ethtest.DeployMinimalProxy(t, client, erc20Contract)
+
+	impl, isProxy, err := l.conn.IsMinimalProxy(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isProxy || impl != erc20Contract {
+		t.Fatalf("clone not detected. Expected impl: %s Got: %s (proxy: %t)", erc20Contract.Hex(), impl.Hex(), isProxy)
+	}
+
+	rId, err := l.resolveErc20ResourceId(clone, msg.ResourceId{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rId != resourceId {
+		t.Fatalf("resource ID mismatch. Expected: %x Got: %x", resourceId, rId)
+	}
+
+	// The implementation itself should resolve directly
+	rId, err = l.resolveErc20ResourceId(erc20Contract, msg.ResourceId{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rId != resourceId {
+		t.Fatalf("resource ID mismatch. Expected: %x Got: %x", resourceId, rId)
+	}
+}
+
+func TestListener_Erc20ResourceIDSetEvent(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	errs := make(chan error)
+	l, router := createTestListener(t, aliceTestConfig, contracts, make(chan int), errs)
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+
+	amount := big.NewInt(10)
+	src := msg.ChainId(0)
+	dst := msg.ChainId(1)
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(src)))
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	createErc20Deposit(t, l.bridgeContract, client, resourceId, recipient, dst, amount)
+	verifyMessage(t, router, msg.NewFungibleTransfer(src, dst, 1, amount, resourceId, recipient.Bytes()), errs)
+
+	// Register a new resource ID for the token through the event stream
+	newResourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(dst)))
+	err := l.handleResourceIDSetEvent(ethtypes.Log{
+		Address: contracts.ERC20HandlerAddress,
+		Topics:  []common.Hash{utils.ResourceIDSet.GetTopic()},
+		Data:    append(newResourceId[:], common.LeftPadBytes(erc20Contract.Bytes(), 32)...),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Subsequent deposits are routed with the new resource ID
+	createErc20Deposit(t, l.bridgeContract, client, resourceId, recipient, dst, amount)
+	verifyMessage(t, router, msg.NewFungibleTransfer(src, dst, 2, amount, newResourceId, recipient.Bytes()), errs)
+
+	err = l.handleResourceIDSetEvent(ethtypes.Log{Data: []byte{1, 2, 3}})
+	if err == nil {
+		t.Fatal("expected error for malformed event data")
+	}
+}
+
+func TestListener_ReplayResourceIDEvents(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("replay", big.NewInt(1200), &utils.DeployedContracts{ERC20HandlerAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	token := common.HexToAddress("0x0000000000000000000000000000000000005678")
+	rId := msg.ResourceIdFromSlice([]byte("token"))
+	backend.logs = []ethtypes.Log{{
+		Address:     cfg.erc20HandlerContract,
+		Topics:      []common.Hash{utils.ResourceIDSet.GetTopic()},
+		Data:        append(rId[:], common.LeftPadBytes(token.Bytes(), 32)...),
+		BlockNumber: 900,
+	}}
+	stop := make(chan int)
+	defer close(stop)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), &blockstore.EmptyStore{}, stop, make(chan error, 1), nil)
+	l.SetMaxBlocksPerPoll(500)
+
+	// The mock cannot find the handler deployment, so blocks 0 to 1199 are replayed
+	err = l.replayResourceIDEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := backend.called("FilterLogs"); calls != 3 {
+		t.Fatalf("expected the replay to be fetched in 3 windows, got %d requests", calls)
+	}
+	if got, ok := l.lookupResourceId(token); !ok || got != rId {
+		t.Fatalf("expected the replayed resource ID to be registered, got: %x", got)
+	}
+}
+
+func TestListener_GetDepositNotFound(t *testing.T) {
+	backend := newMockBackend()
+	backend.setHead(1300)
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("deposit", big.NewInt(0), nil)
+	stop := make(chan int)
+	defer close(stop)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), &blockstore.EmptyStore{}, stop, make(chan error, 1), nil)
+	l.SetMaxBlocksPerPoll(500)
+
+	// The mock cannot find the bridge deployment, so blocks 0 to 1300 are searched
+	_, err = l.getDeposit(1, 1)
+	if !errors.Is(err, ErrDepositNotFound) {
+		t.Fatalf("expected ErrDepositNotFound, got: %v", err)
+	}
+	if calls := backend.called("FilterLogs"); calls != 3 {
+		t.Fatalf("expected the search to be fetched in 3 windows, got %d requests", calls)
+	}
+}
+
 func TestListener_Erc721DepositedEvent(t *testing.T) {
 	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
 	contracts := deployTestContracts(t, client, aliceTestConfig.id)
@@ -258,6 +449,69 @@ func TestListener_GenericDepositedEvent(t *testing.T) {
 	verifyMessage(t, router, expectedMessage, errs)
 }
 
+func TestListener_GenericDepositCompressed(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	errs := make(chan error)
+	l, router := createTestListener(t, aliceTestConfig, contracts, make(chan int), errs)
+
+	src := msg.ChainId(0)
+	dst := msg.ChainId(1)
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes([]byte{2}, 31), uint8(src)))
+	depositSig := utils.CreateFunctionSignature("")
+	executeSig := utils.CreateFunctionSignature("store()")
+	ethtest.RegisterGenericResource(t, client, contracts.BridgeAddress, contracts.GenericHandlerAddress, resourceId, utils.ZeroAddress, depositSig, executeSig)
+
+	// Over the compression threshold
+	metadata := bytes.Repeat([]byte{0xab}, 2*DefaultCompressionThreshold)
+	createGenericDeposit(t, l.bridgeContract, client, resourceId, dst, metadata)
+
+	expectedMessage := msg.NewGenericTransfer(src, dst, 1, resourceId, compressMetadata(metadata))
+	verifyMessage(t, router, expectedMessage, errs)
+}
+
+func TestListener_OversizedMessage(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	errs := make(chan error)
+	cfg := *aliceTestConfig
+	cfg.maxMessageBytes = 1024
+	l, router := createTestListener(t, &cfg, contracts, make(chan int), errs)
+
+	src := msg.ChainId(0)
+	dst := msg.ChainId(1)
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes([]byte{3}, 31), uint8(src)))
+	depositSig := utils.CreateFunctionSignature("")
+	executeSig := utils.CreateFunctionSignature("store()")
+	ethtest.RegisterGenericResource(t, client, contracts.BridgeAddress, contracts.GenericHandlerAddress, resourceId, utils.ZeroAddress, depositSig, executeSig)
+
+	// Twice the limit, scaled down from megabytes to fit in a block
+	createGenericDeposit(t, l.bridgeContract, client, resourceId, dst, bytes.Repeat([]byte{0xab}, 2*1024))
+	hash := utils.Hash(common.LeftPadBytes([]byte{1}, 32))
+	createGenericDeposit(t, l.bridgeContract, client, resourceId, dst, hash[:])
+
+	// The oversized deposit is not routed, so the second is the first message routed
+	expectedMessage := msg.NewGenericTransfer(src, dst, 2, resourceId, hash[:])
+	verifyMessage(t, router, expectedMessage, errs)
+}
+
+func TestMessageSize(t *testing.T) {
+	m := msg.NewGenericTransfer(0, 1, 1, msg.ResourceId{}, make([]byte, 2<<20))
+	if size := messageSize(m); size != 2<<20 {
+		t.Fatalf("expected size %d, got: %d", 2<<20, size)
+	}
+
+	l := &listener{cfg: Config{maxMessageBytes: DefaultMaxMessageBytes}}
+	err := l.checkMessageSize(messageSize(m))
+	if !errors.Is(err, ErrOversizedMessage) {
+		t.Fatalf("expected ErrOversizedMessage, got: %v", err)
+	}
+	err = l.checkMessageSize(DefaultMaxMessageBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func compareMessage(expected, actual msg.Message) error {
 	if !reflect.DeepEqual(expected, actual) {
 		if !reflect.DeepEqual(expected.Source, actual.Source) {
@@ -272,3 +526,490 @@ func compareMessage(expected, actual msg.Message) error {
 	}
 	return nil
 }
+
+func TestListener_BlockstoreLag(t *testing.T) {
+	cfg := createConfig("lagging", big.NewInt(0), nil)
+	var errorLogs []*log15.Record
+	logger := log15.New("chain", cfg.name)
+	logger.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		if r.Lvl == log15.LvlError {
+			errorLogs = append(errorLogs, r)
+		}
+		return nil
+	}))
+	l := NewListener(nil, cfg, logger, nil, nil, nil, nil)
+
+	l.updateBlockstoreLag(big.NewInt(1200), big.NewInt(1000))
+	if lag := testutil.ToFloat64(blockstoreLag.WithLabelValues(cfg.name)); lag != 200 {
+		t.Fatalf("expected lag of 200, got: %v", lag)
+	}
+	if len(errorLogs) != 1 {
+		t.Fatalf("expected one error log, got: %d", len(errorLogs))
+	}
+	logCtx := fmt.Sprint(errorLogs[0].Ctx)
+	if logCtx != fmt.Sprint([]interface{}{"chain", cfg.name, "chain_name", registry.NameOf(cfg.id), "lag", big.NewInt(200), "threshold", cfg.lagAlertThreshold}) {
+		t.Fatalf("unexpected log context: %s", logCtx)
+	}
+
+	// Only the first block over the threshold is logged
+	l.updateBlockstoreLag(big.NewInt(1201), big.NewInt(1001))
+	if len(errorLogs) != 1 {
+		t.Fatalf("expected one error log, got: %d", len(errorLogs))
+	}
+
+	l.updateBlockstoreLag(big.NewInt(1210), big.NewInt(1200))
+	if lag := testutil.ToFloat64(blockstoreLag.WithLabelValues(cfg.name)); lag != 10 {
+		t.Fatalf("expected lag of 10, got: %v", lag)
+	}
+	if l.lagging {
+		t.Fatal("expected lag to be below the threshold")
+	}
+}
+
+func TestListener_SetStartBlockByTimestamp(t *testing.T) {
+	cfg := createConfig("startTime", big.NewInt(0), nil)
+	conn := newLocalConnection(t, cfg)
+	defer conn.Close()
+	l := NewListener(conn, cfg, TestLogger, nil, nil, nil, nil)
+
+	latest, err := conn.LatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := new(big.Int).Sub(latest, big.NewInt(3))
+	header, err := conn.Client().HeaderByNumber(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Halfway to the next second still selects a block with the target's timestamp
+	err = l.SetStartBlockByTimestamp(time.Unix(int64(header.Time), int64(time.Second/2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.cfg.startBlock.Cmp(target) < 0 {
+		t.Fatalf("expected start block at or after %s, got %s", target, l.cfg.startBlock)
+	}
+	startHeader, err := conn.Client().HeaderByNumber(context.Background(), l.cfg.startBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if startHeader.Time != header.Time {
+		t.Fatalf("expected start block timestamp %d, got %d", header.Time, startHeader.Time)
+	}
+}
+
+func TestListener_PollRange(t *testing.T) {
+	cfg := *aliceTestConfig
+	cfg.blockConfirmations = big.NewInt(3)
+	l := NewListener(nil, &cfg, TestLogger, nil, make(chan int), make(chan error), nil)
+	l.SetMaxBlocksPerPoll(10)
+
+	// Blocks 0 through 99 have enough confirmations
+	latest := big.NewInt(102)
+	current := big.NewInt(0)
+	iterations := 0
+	for {
+		end, ready := l.pollRange(current, latest)
+		if !ready {
+			break
+		}
+		iterations++
+		if processed := new(big.Int).Sub(end, current).Int64() + 1; processed > 10 {
+			t.Fatalf("poll %d processed %d blocks, limit is 10", iterations, processed)
+		}
+		current = new(big.Int).Add(end, big.NewInt(1))
+	}
+	if iterations != 10 {
+		t.Fatalf("expected 100 blocks to be processed in 10 polls, took %d", iterations)
+	}
+	if current.Int64() != 100 {
+		t.Fatalf("expected polling to stop at block 100, got: %s", current)
+	}
+
+	// A partial range is processed once the head is less than maxBlocksPerPoll ahead
+	end, ready := l.pollRange(big.NewInt(95), latest)
+	if !ready || end.Int64() != 99 {
+		t.Fatalf("expected range to end at block 99, got: %v %v", end, ready)
+	}
+}
+
+// fanoutWriter records the messages routed to it
+type fanoutWriter struct {
+	msgs chan msg.Message
+}
+
+func (w *fanoutWriter) ResolveMessage(m msg.Message) bool {
+	w.msgs <- m
+	return true
+}
+
+// errRouter passes messages to a router, recording the errors it returns
+type errRouter struct {
+	router *router.Router
+	errs   chan error
+}
+
+func (r *errRouter) Send(m msg.Message) error {
+	err := r.router.Send(m)
+	if err != nil {
+		r.errs <- err
+	}
+	return err
+}
+
+func TestListener_multipleChains_fanout(t *testing.T) {
+	aliceClient := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	bobClient := ethtest.NewClient(t, TestEndpoint, BobKp)
+	bobTestConfig := createConfig("bob", nil, nil)
+	bobTestConfig.id = msg.ChainId(1)
+	contractsA := deployTestContracts(t, aliceClient, aliceTestConfig.id)
+	contractsB := deployTestContracts(t, bobClient, bobTestConfig.id)
+
+	// Both listeners share a router, which only has a writer for chain 1
+	r := &errRouter{router: router.NewRouter(TestLogger), errs: make(chan error, 1)}
+	writer := &fanoutWriter{msgs: make(chan msg.Message, 1)}
+	r.router.Listen(bobTestConfig.id, writer)
+
+	errs := make(chan error)
+	stop := make(chan int)
+	defer close(stop)
+	listenerA, _ := newTestListener(t, aliceTestConfig, contractsA, stop, errs)
+	listenerB, _ := newTestListener(t, bobTestConfig, contractsB, stop, errs)
+	for _, l := range []*listener{listenerA, listenerB} {
+		l.setRouter(r)
+		err := l.start()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, aliceClient, contractsA.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(aliceTestConfig.id)))
+	ethtest.RegisterResource(t, aliceClient, contractsA.BridgeAddress, contractsA.ERC20HandlerAddress, resourceId, erc20Contract)
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+
+	// A deposit to chain 1 reaches its writer
+	expected := msg.NewFungibleTransfer(aliceTestConfig.id, bobTestConfig.id, 1, amount, resourceId, recipient.Bytes())
+	createErc20Deposit(t, listenerA.bridgeContract, aliceClient, resourceId, recipient, bobTestConfig.id, amount)
+	select {
+	case m := <-writer.msgs:
+		err := compareMessage(expected, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	case err := <-r.errs:
+		t.Fatalf("router rejected message: %s", err)
+	case err := <-errs:
+		t.Fatalf("Fatal error: %s", err)
+	case <-time.After(TestTimeout):
+		t.Fatal("test timed out")
+	}
+
+	// A deposit to chain 2 is rejected by the router instead of waiting for a writer
+	createErc20Deposit(t, listenerA.bridgeContract, aliceClient, resourceId, recipient, msg.ChainId(2), amount)
+	select {
+	case err := <-r.errs:
+		if !strings.Contains(err.Error(), "unknown destination") {
+			t.Fatalf("unexpected router error: %s", err)
+		}
+	case m := <-writer.msgs:
+		t.Fatalf("unexpected message routed to chain 1: %+v", m)
+	case err := <-errs:
+		t.Fatalf("Fatal error: %s", err)
+	case <-time.After(TestTimeout):
+		t.Fatal("test timed out")
+	}
+}
+
+func TestListener_PauseAt(t *testing.T) {
+	retryInterval := BlockRetryInterval
+	BlockRetryInterval = 10 * time.Millisecond
+	defer func() { BlockRetryInterval = retryInterval }()
+
+	backend := newMockBackend()
+	backend.setHead(1)
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("pause", big.NewInt(1), nil)
+	cfg.blockConfirmations = big.NewInt(0)
+	stop := make(chan int)
+	defer close(stop)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), &blockstore.EmptyStore{}, stop, make(chan error, 1), nil)
+	l.SetMaxBlocksPerPoll(5)
+	l.PauseAt(big.NewInt(50))
+
+	next := func() int64 {
+		l.nextBlockLock.Lock()
+		defer l.nextBlockLock.Unlock()
+		if l.nextBlock == nil {
+			return 0
+		}
+		return l.nextBlock.Int64()
+	}
+	waitNext := func(block int64) {
+		for i := 0; i < 200 && next() != block; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if next() != block {
+			t.Fatalf("expected the next block to be %d, got: %d", block, next())
+		}
+	}
+
+	l.startPolling(cfg.startBlock)
+	backend.setHead(60)
+
+	// Blocks up to the pause block are processed, then the listener waits
+	waitNext(51)
+	time.Sleep(BlockRetryInterval * 5)
+	if next() != 51 {
+		t.Fatalf("expected the listener to stay paused after block 50, next block is: %d", next())
+	}
+
+	l.Resume()
+	waitNext(61)
+}
+
+func TestListener_FetchURI(t *testing.T) {
+	handlerABI, _ := abi.JSON(strings.NewReader(ERC721Handler.ERC721HandlerABI))
+	tokenABI, _ := abi.JSON(strings.NewReader(IERC721Metadata.IERC721MetadataABI))
+	token := common.HexToAddress("0x0000000000000000000000000000000000000721")
+	record := ERC721Handler.ERC721HandlerDepositRecord{
+		TokenAddress:                token,
+		DestinationChainID:          2,
+		ResourceID:                  [32]byte{1},
+		DestinationRecipientAddress: BobKp.CommonAddress().Bytes(),
+		TokenID:                     big.NewInt(7),
+	}
+
+	backend := newMockBackend()
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(call.Data, handlerABI.Methods["getDepositRecord"].ID):
+			return handlerABI.Methods["getDepositRecord"].Outputs.Pack(record)
+		case *call.To == token && bytes.HasPrefix(call.Data, tokenABI.Methods["tokenURI"].ID):
+			return tokenABI.Methods["tokenURI"].Outputs.Pack("ipfs://QmToken/7.json")
+		}
+		return nil, errors.New("execution reverted")
+	}
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("uri", big.NewInt(0), nil)
+	cfg.fetchURI = true
+	stop := make(chan int)
+	defer close(stop)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), &blockstore.EmptyStore{}, stop, make(chan error, 1), nil)
+	erc721Handler, err := ERC721Handler.NewERC721Handler(cfg.erc721HandlerContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.setContracts(nil, nil, erc721Handler, nil)
+
+	m, err := l.handleErc721DepositedEvent(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri := string(m.Payload[2].([]byte)); uri != "ipfs://QmToken/7.json" {
+		t.Fatalf("expected the token URI to be relayed, got: %q", uri)
+	}
+
+	// Metadata recorded by the handler is relayed unchanged
+	record.MetaData = []byte("https://example.com/7.json")
+	m, err = l.handleErc721DepositedEvent(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri := string(m.Payload[2].([]byte)); uri != "https://example.com/7.json" {
+		t.Fatalf("expected the deposit metadata to be relayed, got: %q", uri)
+	}
+
+	// Tokens without a URI are relayed without one
+	record.MetaData = nil
+	record.TokenAddress = common.HexToAddress("0x01")
+	m, err = l.handleErc721DepositedEvent(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Payload[2].([]byte)) != 0 {
+		t.Fatalf("expected no metadata, got: %q", m.Payload[2])
+	}
+}
+
+func TestListener_GetProcessedRange(t *testing.T) {
+	retryInterval := BlockRetryInterval
+	BlockRetryInterval = 10 * time.Millisecond
+	defer func() { BlockRetryInterval = retryInterval }()
+
+	backend := newMockBackend()
+	backend.setHead(1)
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("range", big.NewInt(1), nil)
+	cfg.blockConfirmations = big.NewInt(0)
+	stop := make(chan int)
+	defer close(stop)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), &blockstore.EmptyStore{}, stop, make(chan error, 1), nil)
+	l.SetMaxBlocksPerPoll(5)
+
+	from, to := l.GetProcessedRange()
+	if from.Int64() != 1 || to != nil {
+		t.Fatalf("expected no blocks to be processed, got: %v-%v", from, to)
+	}
+
+	// Blocks 1 to 10 are processed in two polls
+	backend.setHead(10)
+	l.startPolling(cfg.startBlock)
+	for i := 0; i < 200; i++ {
+		if _, to = l.GetProcessedRange(); to != nil && to.Int64() == 10 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	from, to = l.GetProcessedRange()
+	if from.Int64() != 1 || to == nil || to.Int64() != 10 {
+		t.Fatalf("expected blocks 1 to 10 to be processed, got: %v-%v", from, to)
+	}
+	if from, to := l.GetPendingRange(); from != nil || to != nil {
+		t.Fatalf("expected no pending blocks, got: %v-%v", from, to)
+	}
+}
+
+func TestListener_blockstore_resumption(t *testing.T) {
+	retryInterval := BlockRetryInterval
+	BlockRetryInterval = 10 * time.Millisecond
+	defer func() { BlockRetryInterval = retryInterval }()
+
+	bridgeAddr := common.HexToAddress("0x0000000000000000000000000000000000001234")
+	handlerAddr := common.HexToAddress("0x0000000000000000000000000000000000005678")
+	token := common.HexToAddress("0x0000000000000000000000000000000000000020")
+	rId := msg.ResourceIdFromSlice([]byte{1})
+	handlerABI, _ := abi.JSON(strings.NewReader(ERC20Handler.ERC20HandlerABI))
+
+	// Deposits 1 to 5 are made in blocks 10 to 20
+	backend := newMockBackend()
+	backend.setHead(14)
+	for i, block := range []uint64{10, 12, 14, 17, 20} {
+		backend.logs = append(backend.logs, ethtypes.Log{
+			Address:     bridgeAddr,
+			BlockNumber: block,
+			Topics: []common.Hash{
+				utils.Deposit.GetTopic(),
+				common.BigToHash(big.NewInt(2)),
+				common.Hash(rId),
+				common.BigToHash(big.NewInt(int64(i + 1))),
+			},
+		})
+	}
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(call.Data, bridgeABI.Methods["_resourceIDToHandlerAddress"].ID):
+			return bridgeABI.Methods["_resourceIDToHandlerAddress"].Outputs.Pack(handlerAddr)
+		case bytes.HasPrefix(call.Data, handlerABI.Methods["getDepositRecord"].ID):
+			return handlerABI.Methods["getDepositRecord"].Outputs.Pack(ERC20Handler.ERC20HandlerDepositRecord{
+				TokenAddress:                token,
+				DestinationChainID:          2,
+				ResourceID:                  rId,
+				DestinationRecipientAddress: BobKp.CommonAddress().Bytes(),
+				Amount:                      big.NewInt(10),
+			})
+		case bytes.HasPrefix(call.Data, handlerABI.Methods["_tokenContractAddressToResourceID"].ID):
+			return handlerABI.Methods["_tokenContractAddressToResourceID"].Outputs.Pack(rId)
+		}
+		return nil, errors.New("execution reverted")
+	}
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "resumption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Each listener opens the blockstore in dir as the chain does at startup
+	var loadLatest func() (*big.Int, error)
+	newResumedListener := func(stop <-chan int) (*listener, *MockRouter, *big.Int) {
+		cfg := createConfig("resumption", big.NewInt(10), &utils.DeployedContracts{BridgeAddress: bridgeAddr, ERC20HandlerAddress: handlerAddr})
+		cfg.blockConfirmations = big.NewInt(0)
+		cfg.blockstorePath = dir
+		cfg.freshStart = false
+		bs, err := setupBlockstore(context.Background(), cfg, AliceKp.Address(), conn, newTestLogger(cfg.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		loadLatest = bs.TryLoadLatestBlock
+		bridge, err := Bridge.NewBridge(bridgeAddr, conn.Backend())
+		if err != nil {
+			t.Fatal(err)
+		}
+		erc20Handler, err := ERC20Handler.NewERC20Handler(handlerAddr, conn.Backend())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &MockRouter{msgs: make(chan msg.Message, 5)}
+		l := NewListener(conn, cfg, newTestLogger(cfg.name), bs, stop, make(chan error, 1), nil)
+		l.setContracts(bridge, erc20Handler, nil, nil)
+		l.setRouter(r)
+		return l, r, cfg.startBlock
+	}
+	receive := func(r *MockRouter, nonces ...msg.Nonce) {
+		for _, nonce := range nonces {
+			select {
+			case m := <-r.msgs:
+				if m.DepositNonce != nonce {
+					t.Fatalf("expected deposit %d, got: %d", nonce, m.DepositNonce)
+				}
+			case <-time.After(TestTimeout):
+				t.Fatalf("deposit %d was not routed", nonce)
+			}
+		}
+	}
+
+	// The first listener processes the blocks of deposits 1 to 3, then stops
+	stop := make(chan int)
+	l, r, start := newResumedListener(stop)
+	if start.Int64() != 10 {
+		t.Fatalf("expected the first listener to start at block 10, got: %s", start)
+	}
+	l.startPolling(start)
+	receive(r, 1, 2, 3)
+	for i := 0; i < 200; i++ {
+		if _, to := l.GetProcessedRange(); to != nil && to.Int64() == 14 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+
+	stored, err := loadLatest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Int64() != 14 {
+		t.Fatalf("expected block 14 to be stored, got: %s", stored)
+	}
+
+	// The second listener resumes after the stored block, so only deposits 4 and 5 are routed
+	stop = make(chan int)
+	defer close(stop)
+	l, r, start = newResumedListener(stop)
+	if start.Int64() != 15 {
+		t.Fatalf("expected the listener to resume at block 15, got: %s", start)
+	}
+	backend.setHead(20)
+	l.startPolling(start)
+	receive(r, 4, 5)
+	select {
+	case m := <-r.msgs:
+		t.Fatalf("expected no more deposits to be routed, got deposit %d", m.DepositNonce)
+	case <-time.After(BlockRetryInterval * 10):
+	}
+}
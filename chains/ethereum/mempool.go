@@ -0,0 +1,190 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var mempoolMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_tx_mempool_misses_total",
+	Help: "Number of submitted transactions not seen in the node's mempool within mempoolConfirmTimeout, which are re-submitted",
+}, []string{"chain"})
+
+var txPoolQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_txpool_queued_count",
+	Help: "Number of the relayer's transactions queued in the node's txpool behind a nonce gap, as of the last inspection",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(mempoolMisses, txPoolQueued)
+}
+
+// pendingWatch receives the hashes of transactions added to the node's mempool. It is started before a transaction
+// is submitted, so the transaction's hash cannot be announced before it is watched for.
+type pendingWatch struct {
+	sub       eth.Subscription
+	hashes    chan common.Hash
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// watchPending subscribes to pending transactions. It returns nil if mempool confirmation is disabled or the node
+// does not support the subscription, in which case submitted transactions are not checked.
+func (w *writer) watchPending() *pendingWatch {
+	if w.cfg.mempoolConfirmTimeout == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hashes := make(chan common.Hash, 128)
+	sub, err := w.conn.SubscribePendingTxs(ctx, hashes)
+	if err != nil {
+		cancel()
+		w.log.Debug("Unable to subscribe to pending transactions, mempool is not checked", "err", err)
+		return nil
+	}
+	return &pendingWatch{sub: sub, hashes: hashes, cancel: cancel}
+}
+
+// wait returns whether hash is announced within timeout, then closes the watch. If the subscription fails the
+// transaction cannot be confirmed either way, so it is assumed to be pending. A nil watch always returns true.
+func (p *pendingWatch) wait(hash common.Hash, timeout time.Duration) bool {
+	if p == nil {
+		return true
+	}
+	defer p.close()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case h := <-p.hashes:
+			if h == hash {
+				return true
+			}
+		case <-p.sub.Err():
+			return true
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// close ends the subscription, it may be called more than once and on a nil watch
+func (p *pendingWatch) close() {
+	if p == nil {
+		return
+	}
+	p.closeOnce.Do(func() {
+		p.sub.Unsubscribe()
+		p.cancel()
+	})
+}
+
+// confirmPending waits for tx to be announced by pending. If it was not the node's txpool is inspected, and false is
+// returned and a miss counted if tx is not in it, so the caller re-submits it.
+func (w *writer) confirmPending(pending *pendingWatch, tx *types.Transaction) bool {
+	if pending.wait(tx.Hash(), w.cfg.mempoolConfirmTimeout) {
+		return true
+	}
+	if w.inspectTxPool(tx) {
+		return true
+	}
+	mempoolMisses.WithLabelValues(w.cfg.name).Inc()
+	w.log.Warn("Transaction not seen in mempool, treating it as dropped", "tx", tx.Hash(), "timeout", w.cfg.mempoolConfirmTimeout)
+	return false
+}
+
+// inspectTxPool returns whether tx is in the node's txpool. Only executable transactions are announced, so a queued
+// tx is waiting for a lower nonce that was dropped, and the gap is filled so it can be mined. False is returned if
+// the txpool cannot be inspected or the gap cannot be filled.
+func (w *writer) inspectTxPool(tx *types.Transaction) bool {
+	pending, queued, err := w.conn.GetTxPoolContent()
+	if err != nil {
+		w.log.Debug("Unable to inspect the txpool", "err", err)
+		return false
+	}
+	from := w.conn.Opts().From
+	ownQueued := txPoolAccount(queued, from)
+	txPoolQueued.WithLabelValues(w.cfg.name).Set(float64(len(ownQueued)))
+
+	if containsTx(txPoolAccount(pending, from), tx.Hash()) {
+		w.log.Debug("Transaction was not announced but is pending", "tx", tx.Hash())
+		return true
+	}
+	if !containsTx(ownQueued, tx.Hash()) {
+		return false
+	}
+	w.log.Warn("Transaction is queued behind a nonce gap, filling the gap", "tx", tx.Hash(), "nonce", tx.Nonce())
+	err = w.fillNonceGap(tx.Nonce(), ownQueued)
+	if err != nil {
+		w.log.Error("Unable to fill nonce gap", "nonce", tx.Nonce(), "err", err)
+		return false
+	}
+	return true
+}
+
+// fillNonceGap sends a transfer of nothing to the relayer at each nonce from the pending nonce up to nonce that is
+// not already queued
+func (w *writer) fillNonceGap(nonce uint64, queued map[string]*types.Transaction) error {
+	err := w.conn.LockAndUpdateOpts()
+	if err != nil {
+		return err
+	}
+	defer w.conn.UnlockOpts()
+
+	opts := w.conn.Opts()
+	// After London the fee cap is a valid legacy gas price
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice = opts.GasFeeCap
+	}
+	for n := opts.Nonce.Uint64(); n < nonce; n++ {
+		if _, ok := queued[strconv.FormatUint(n, 10)]; ok {
+			continue
+		}
+		tx, err := opts.Signer(opts.From, types.NewTransaction(n, opts.From, big.NewInt(0), params.TxGas, gasPrice, nil))
+		if err != nil {
+			return err
+		}
+		err = w.conn.Backend().SendTransaction(context.Background(), tx)
+		if err != nil {
+			return err
+		}
+		w.log.Info("Filled nonce gap", "nonce", n, "tx", tx.Hash())
+	}
+	return nil
+}
+
+// txPoolAccount returns the transactions of addr in a txpool_content section. Nodes differ in the case of the
+// address keys, so they are compared case insensitively.
+func txPoolAccount(content map[string]map[string]*types.Transaction, addr common.Address) map[string]*types.Transaction {
+	for key, txs := range content {
+		if strings.EqualFold(key, addr.Hex()) {
+			return txs
+		}
+	}
+	return nil
+}
+
+func containsTx(txs map[string]*types.Transaction, hash common.Hash) bool {
+	for _, tx := range txs {
+		if tx != nil && tx.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
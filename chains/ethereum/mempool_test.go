@@ -0,0 +1,154 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// mockSubscription is a subscription that only ends when it is unsubscribed
+type mockSubscription struct {
+	err          chan error
+	unsubscribed bool
+}
+
+func (s *mockSubscription) Unsubscribe() {
+	s.unsubscribed = true
+}
+
+func (s *mockSubscription) Err() <-chan error {
+	return s.err
+}
+
+// newMockPendingWatch returns a watch that receives hashes, as if announced by the node
+func newMockPendingWatch(hashes ...common.Hash) (*pendingWatch, *mockSubscription) {
+	sub := &mockSubscription{err: make(chan error, 1)}
+	ch := make(chan common.Hash, len(hashes))
+	for _, h := range hashes {
+		ch <- h
+	}
+	return &pendingWatch{sub: sub, hashes: ch, cancel: func() {}}, sub
+}
+
+// newMempoolWriter returns a writer that checks the mempool of a mock connection signing with AliceKp
+func newMempoolWriter(t *testing.T, name string) (*writer, *mockConnection) {
+	conn, err := newMockConnection(newMockBackend(), AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &writer{cfg: Config{name: name, mempoolConfirmTimeout: 100 * time.Millisecond}, conn: conn, log: TestLogger}
+	return w, conn
+}
+
+// signedTx returns a vote-like transaction from the connection's keypair with nonce
+func signedTx(t *testing.T, conn *mockConnection, nonce uint64) *types.Transaction {
+	tx, err := conn.Opts().Signer(conn.Opts().From, types.NewTransaction(nonce, common.HexToAddress("0x01"), big.NewInt(0), DefaultGasLimit, big.NewInt(DefaultGasPrice), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestWriter_ConfirmPending(t *testing.T) {
+	w, conn := newMempoolWriter(t, "mempool")
+	tx := signedTx(t, conn, 0)
+	submitted := tx.Hash()
+	other := common.HexToHash("0x02")
+
+	pending, sub := newMockPendingWatch(other, submitted)
+	if !w.confirmPending(pending, tx) {
+		t.Fatal("expected announced transaction to be confirmed")
+	}
+	if !sub.unsubscribed {
+		t.Fatal("expected subscription to be closed")
+	}
+
+	pending, sub = newMockPendingWatch(other)
+	if w.confirmPending(pending, tx) {
+		t.Fatal("expected transaction that was not announced to be treated as dropped")
+	}
+	if !sub.unsubscribed {
+		t.Fatal("expected subscription to be closed")
+	}
+	if misses := testutil.ToFloat64(mempoolMisses.WithLabelValues("mempool")); misses != 1 {
+		t.Fatalf("expected 1 mempool miss, got: %v", misses)
+	}
+
+	// The transaction cannot be checked if the subscription fails, or if there is no watch
+	pending, sub = newMockPendingWatch()
+	sub.err <- context.Canceled
+	if !w.confirmPending(pending, tx) {
+		t.Fatal("expected transaction to be assumed pending when the subscription fails")
+	}
+	if !w.confirmPending(nil, tx) {
+		t.Fatal("expected transaction to be assumed pending without a watch")
+	}
+	if misses := testutil.ToFloat64(mempoolMisses.WithLabelValues("mempool")); misses != 1 {
+		t.Fatalf("expected 1 mempool miss, got: %v", misses)
+	}
+}
+
+func TestWriter_ConfirmPendingTxPool(t *testing.T) {
+	w, conn := newMempoolWriter(t, "txpool")
+	from := conn.Opts().From
+	// Nonces 3 and 4 were dropped, so the vote at 5 is queued
+	conn.backend.nonces[from] = 3
+	queuedTx := signedTx(t, conn, 5)
+	content := map[string]map[string]*types.Transaction{from.Hex(): {"5": queuedTx}}
+	conn.txPool = func() (map[string]map[string]*types.Transaction, map[string]map[string]*types.Transaction) {
+		return nil, content
+	}
+
+	pending, _ := newMockPendingWatch()
+	if !w.confirmPending(pending, queuedTx) {
+		t.Fatal("expected queued transaction to be kept once the nonce gap is filled")
+	}
+	if queued := testutil.ToFloat64(txPoolQueued.WithLabelValues("txpool")); queued != 1 {
+		t.Fatalf("expected 1 queued transaction, got: %v", queued)
+	}
+	sent := conn.backend.transactions()
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 transactions filling the gap, got: %d", len(sent))
+	}
+	for i, tx := range sent {
+		if tx.Nonce() != uint64(3+i) || *tx.To() != from || tx.Value().Sign() != 0 {
+			t.Fatalf("expected an empty transfer to the relayer at nonce %d, got: nonce %d to %s of %s", 3+i, tx.Nonce(), tx.To(), tx.Value())
+		}
+	}
+
+	// A transaction that is pending was only missed by the subscription
+	pendingTx := signedTx(t, conn, 6)
+	conn.txPool = func() (map[string]map[string]*types.Transaction, map[string]map[string]*types.Transaction) {
+		return map[string]map[string]*types.Transaction{from.Hex(): {"6": pendingTx}}, nil
+	}
+	pending, _ = newMockPendingWatch()
+	if !w.confirmPending(pending, pendingTx) {
+		t.Fatal("expected pending transaction to be confirmed")
+	}
+
+	// A transaction missing from the txpool was dropped
+	conn.txPool = func() (map[string]map[string]*types.Transaction, map[string]map[string]*types.Transaction) {
+		return nil, nil
+	}
+	pending, _ = newMockPendingWatch()
+	if w.confirmPending(pending, signedTx(t, conn, 7)) {
+		t.Fatal("expected missing transaction to be treated as dropped")
+	}
+	if queued := testutil.ToFloat64(txPoolQueued.WithLabelValues("txpool")); queued != 0 {
+		t.Fatalf("expected no queued transactions, got: %v", queued)
+	}
+	if misses := testutil.ToFloat64(mempoolMisses.WithLabelValues("txpool")); misses != 1 {
+		t.Fatalf("expected 1 mempool miss, got: %v", misses)
+	}
+	if len(conn.backend.transactions()) != 2 {
+		t.Fatal("expected no more transactions to be sent")
+	}
+}
@@ -0,0 +1,489 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var _ Connection = &mockConnection{}
+var _ connection.Backend = &mockBackend{}
+
+// errMockUnsupported is returned by mock methods that have no configured response
+var errMockUnsupported = errors.New("not supported by the mock connection")
+
+// mockBackend is a connection.Backend that returns configured responses instead of querying a node, and records
+// the methods called. The fields may be set before the backend is used.
+type mockBackend struct {
+	chainID  *big.Int
+	head     *types.Header                     // Returned as the latest header
+	code     map[common.Address][]byte         // Code of each contract, other addresses have none
+	call     func(eth.CallMsg) ([]byte, error) // Handles CallContract, which returns nothing if nil
+	logs     []types.Log                       // Returned by FilterLogs if they match the query
+	gas      uint64                            // Returned by EstimateGas
+	gasPrice *big.Int                          // Returned by SuggestGasPrice and SuggestGasTipCap
+	balance  *big.Int                          // Returned by GetPendingBalance
+	err      error                             // Returned by every method if set
+	sendErr  error                             // Returned by SendTransaction if set, without sending the transaction
+	revert   bool                              // Sent transactions are mined with a failed status if set
+	calls    []string                          // Methods called, in order
+	sent     []*types.Transaction              // Transactions passed to SendTransaction, which are mined at once
+	nonces   map[common.Address]uint64         // Pending nonce of each sender
+	lock     sync.Mutex
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		chainID:  big.NewInt(1),
+		head:     &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(1)},
+		code:     make(map[common.Address][]byte),
+		gas:      DefaultGasLimit,
+		gasPrice: big.NewInt(DefaultGasPrice),
+		balance:  new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil),
+		nonces:   make(map[common.Address]uint64),
+	}
+}
+
+// record adds method to the calls and returns the configured error
+func (b *mockBackend) record(method string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.calls = append(b.calls, method)
+	return b.err
+}
+
+// called returns how many times method was called
+func (b *mockBackend) called(method string) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	n := 0
+	for _, c := range b.calls {
+		if c == method {
+			n++
+		}
+	}
+	return n
+}
+
+// setHead sets the number of the latest block
+func (b *mockBackend) setHead(number int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.head.Number = big.NewInt(number)
+}
+
+// setBalance sets the pending balance of every account
+func (b *mockBackend) setBalance(balance *big.Int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.balance = new(big.Int).Set(balance)
+}
+
+// transactions returns the transactions sent so far
+func (b *mockBackend) transactions() []*types.Transaction {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]*types.Transaction{}, b.sent...)
+}
+
+func (b *mockBackend) CodeAt(_ context.Context, contract common.Address, _ *big.Int) ([]byte, error) {
+	if err := b.record("CodeAt"); err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.code[contract], nil
+}
+
+func (b *mockBackend) CallContract(_ context.Context, call eth.CallMsg, _ *big.Int) ([]byte, error) {
+	if err := b.record("CallContract"); err != nil {
+		return nil, err
+	}
+	if b.call == nil {
+		return nil, nil
+	}
+	return b.call(call)
+}
+
+func (b *mockBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if err := b.record("HeaderByNumber"); err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	head := types.CopyHeader(b.head)
+	b.lock.Unlock()
+	if number != nil {
+		head.Number = new(big.Int).Set(number)
+	}
+	return head, nil
+}
+
+func (b *mockBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return b.CodeAt(ctx, account, nil)
+}
+
+func (b *mockBackend) PendingNonceAt(_ context.Context, account common.Address) (uint64, error) {
+	if err := b.record("PendingNonceAt"); err != nil {
+		return 0, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.nonces[account], nil
+}
+
+func (b *mockBackend) SuggestGasPrice(_ context.Context) (*big.Int, error) {
+	if err := b.record("SuggestGasPrice"); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.gasPrice), nil
+}
+
+func (b *mockBackend) SuggestGasTipCap(_ context.Context) (*big.Int, error) {
+	if err := b.record("SuggestGasTipCap"); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.gasPrice), nil
+}
+
+func (b *mockBackend) EstimateGas(_ context.Context, _ eth.CallMsg) (uint64, error) {
+	if err := b.record("EstimateGas"); err != nil {
+		return 0, err
+	}
+	return b.gas, nil
+}
+
+func (b *mockBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	if err := b.record("SendTransaction"); err != nil {
+		return err
+	}
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(b.chainID), tx)
+	if err != nil {
+		return err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.sent = append(b.sent, tx)
+	b.nonces[from] = tx.Nonce() + 1
+	return nil
+}
+
+func (b *mockBackend) FilterLogs(_ context.Context, query eth.FilterQuery) ([]types.Log, error) {
+	if err := b.record("FilterLogs"); err != nil {
+		return nil, err
+	}
+	var logs []types.Log
+	for _, l := range b.logs {
+		if query.FromBlock != nil && l.BlockNumber < query.FromBlock.Uint64() {
+			continue
+		}
+		if query.ToBlock != nil && l.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Addresses) != 0 && !containsAddress(query.Addresses, l.Address) {
+			continue
+		}
+		if len(query.Topics) != 0 && len(query.Topics[0]) != 0 && (len(l.Topics) == 0 || !containsHash(query.Topics[0], l.Topics[0])) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+func (b *mockBackend) SubscribeFilterLogs(_ context.Context, _ eth.FilterQuery, _ chan<- types.Log) (eth.Subscription, error) {
+	if err := b.record("SubscribeFilterLogs"); err != nil {
+		return nil, err
+	}
+	return &mockSubscription{err: make(chan error, 1)}, nil
+}
+
+// TransactionReceipt returns a successful receipt for sent transactions, which are mined in the latest block
+func (b *mockBackend) TransactionReceipt(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+	if err := b.record("TransactionReceipt"); err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, tx := range b.sent {
+		if tx.Hash() == hash {
+			status := types.ReceiptStatusSuccessful
+			if b.revert {
+				status = types.ReceiptStatusFailed
+			}
+			return &types.Receipt{TxHash: hash, Status: status, GasUsed: tx.Gas(), BlockNumber: new(big.Int).Set(b.head.Number)}, nil
+		}
+	}
+	return nil, eth.NotFound
+}
+
+func (b *mockBackend) ChainID(_ context.Context) (*big.Int, error) {
+	if err := b.record("ChainID"); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.chainID), nil
+}
+
+func (b *mockBackend) Close() {
+	_ = b.record("Close")
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// mockConnection is a Connection backed by a mockBackend, for unit testing the listener and writer without a node.
+// Methods the backend cannot answer return errMockUnsupported.
+type mockConnection struct {
+	backend  *mockBackend
+	kp       *secp256k1.Keypair
+	opts     *bind.TransactOpts
+	callOpts *bind.CallOpts
+	optsLock sync.Mutex
+	peers    uint64                   // Returned by GetPeerCount
+	sync     *connection.SyncProgress // Returned by CheckSync, nil if the node is synced
+	// txPool returns the pending and queued transactions of GetTxPoolContent, which is unsupported if it is nil
+	txPool func() (pending, queued map[string]map[string]*types.Transaction)
+	// storageProof is returned by GetContractStorageProof, which is unsupported if it is nil
+	storageProof *connection.ContractStorageProof
+	// dropPending makes SubscribePendingTxs succeed without announcing any transaction, so those sent are dropped
+	dropPending bool
+}
+
+// newMockConnection returns a connection that signs legacy transactions with kp at the backend's suggested gas price
+func newMockConnection(backend *mockBackend, kp *secp256k1.Keypair) (*mockConnection, error) {
+	c := &mockConnection{backend: backend}
+	err := c.RotateKeypair(kp)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *mockConnection) Connect() error {
+	return c.ConnectWithContext(context.Background())
+}
+
+func (c *mockConnection) ConnectWithContext(_ context.Context) error {
+	return c.backend.record("Connect")
+}
+
+func (c *mockConnection) Keypair() *secp256k1.Keypair {
+	return c.kp
+}
+
+func (c *mockConnection) RotateKeypair(kp *secp256k1.Keypair) error {
+	opts, err := bind.NewKeyedTransactorWithChainID(kp.PrivateKey(), c.backend.chainID)
+	if err != nil {
+		return err
+	}
+	opts.Nonce = big.NewInt(0)
+	opts.GasLimit = DefaultGasLimit
+	opts.Context = context.Background()
+	c.optsLock.Lock()
+	defer c.optsLock.Unlock()
+	c.kp = kp
+	c.opts = opts
+	c.callOpts = &bind.CallOpts{From: kp.CommonAddress()}
+	return nil
+}
+
+func (c *mockConnection) Opts() *bind.TransactOpts {
+	return c.opts
+}
+
+func (c *mockConnection) CallOpts() *bind.CallOpts {
+	return c.callOpts
+}
+
+func (c *mockConnection) LockAndUpdateOpts() error {
+	c.optsLock.Lock()
+	price, err := c.backend.SuggestGasPrice(context.Background())
+	if err != nil {
+		c.optsLock.Unlock()
+		return err
+	}
+	nonce, err := c.backend.PendingNonceAt(context.Background(), c.opts.From)
+	if err != nil {
+		c.optsLock.Unlock()
+		return err
+	}
+	c.opts.GasPrice = price
+	c.opts.Nonce.SetUint64(nonce)
+	return nil
+}
+
+func (c *mockConnection) UnlockOpts() {
+	c.optsLock.Unlock()
+}
+
+func (c *mockConnection) Backend() connection.Backend {
+	return c.backend
+}
+
+func (c *mockConnection) EnsureHasBytecode(address common.Address) error {
+	code, err := c.backend.CodeAt(context.Background(), address, nil)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return errors.New("no bytecode found at " + address.Hex())
+	}
+	return nil
+}
+
+func (c *mockConnection) IsMinimalProxy(_ common.Address) (common.Address, bool, error) {
+	return common.Address{}, false, c.backend.record("IsMinimalProxy")
+}
+
+func (c *mockConnection) LatestBlock() (*big.Int, error) {
+	head, err := c.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return head.Number, nil
+}
+
+func (c *mockConnection) BlockByTimestamp(_ context.Context, _ time.Time) (*big.Int, error) {
+	return nil, errMockUnsupported
+}
+
+func (c *mockConnection) GetContractDeploymentBlock(_ context.Context, _ common.Address) (*big.Int, error) {
+	return nil, errMockUnsupported
+}
+
+// WaitForBlock returns once the latest block is at least block plus delay, without waiting for new blocks
+func (c *mockConnection) WaitForBlock(block *big.Int, delay *big.Int) error {
+	latest, err := c.LatestBlock()
+	if err != nil {
+		return err
+	}
+	if latest.Cmp(new(big.Int).Add(block, delay)) < 0 {
+		return errors.New("block not reached")
+	}
+	return nil
+}
+
+func (c *mockConnection) EffectiveGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.backend.SuggestGasPrice(ctx)
+}
+
+func (c *mockConnection) SimulateTransaction(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return c.backend.EstimateGas(ctx, call)
+}
+
+func (c *mockConnection) EstimateGasWithBuffer(ctx context.Context, call eth.CallMsg, pct float64) (uint64, error) {
+	gas, err := c.backend.EstimateGas(ctx, call)
+	if err != nil {
+		return 0, err
+	}
+	return gas + uint64(float64(gas)*pct), nil
+}
+
+func (c *mockConnection) BatchGetBlockHeaders(ctx context.Context, from, to *big.Int) ([]*types.Header, error) {
+	var headers []*types.Header
+	for n := new(big.Int).Set(from); n.Cmp(to) <= 0; n.Add(n, big.NewInt(1)) {
+		header, err := c.backend.HeaderByNumber(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+func (c *mockConnection) GetProof(_ context.Context, _ common.Address, _ []string, _ *big.Int) (*connection.AccountProof, error) {
+	return nil, errMockUnsupported
+}
+
+func (c *mockConnection) GetContractStorageProof(_ context.Context, _ common.Address, _ []common.Hash, _ *big.Int) (*connection.ContractStorageProof, error) {
+	if c.storageProof == nil {
+		return nil, errMockUnsupported
+	}
+	return c.storageProof, c.backend.record("GetContractStorageProof")
+}
+
+func (c *mockConnection) GetTransactionTrace(_ context.Context, _ common.Hash) (*connection.TransactionTrace, error) {
+	return nil, errMockUnsupported
+}
+
+func (c *mockConnection) GetPendingBalance(_ common.Address) (*big.Int, error) {
+	if err := c.backend.record("PendingBalanceAt"); err != nil {
+		return nil, err
+	}
+	c.backend.lock.Lock()
+	defer c.backend.lock.Unlock()
+	return new(big.Int).Set(c.backend.balance), nil
+}
+
+func (c *mockConnection) GetPeerCount() (uint64, error) {
+	return c.peers, c.backend.record("GetPeerCount")
+}
+
+func (c *mockConnection) CheckSync() (bool, connection.SyncProgress, error) {
+	if c.sync == nil {
+		return false, connection.SyncProgress{}, c.backend.record("CheckSync")
+	}
+	return true, *c.sync, c.backend.record("CheckSync")
+}
+
+func (c *mockConnection) GetTxPoolContent() (map[string]map[string]*types.Transaction, map[string]map[string]*types.Transaction, error) {
+	if err := c.backend.record("GetTxPoolContent"); err != nil {
+		return nil, nil, err
+	}
+	if c.txPool == nil {
+		return nil, nil, errMockUnsupported
+	}
+	pending, queued := c.txPool()
+	return pending, queued, nil
+}
+
+func (c *mockConnection) SubscribePendingTxs(_ context.Context, _ chan<- common.Hash) (eth.Subscription, error) {
+	if !c.dropPending {
+		return nil, errMockUnsupported
+	}
+	return &mockSubscription{err: make(chan error, 1)}, nil
+}
+
+// WatchBlockHeaders polls the backend every BlockRetryInterval, as the backend does not support subscriptions
+func (c *mockConnection) WatchBlockHeaders(ctx context.Context, headers chan<- *types.Header) error {
+	return connection.PollBlockHeaders(ctx, c.backend, BlockRetryInterval, headers)
+}
+
+func (c *mockConnection) ResolveENS(_ context.Context, _ string) (common.Address, error) {
+	return common.Address{}, errMockUnsupported
+}
+
+func (c *mockConnection) ClearENSCache() {}
+
+func (c *mockConnection) Close() {
+	c.backend.Close()
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var rpcPeerCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_rpc_peer_count",
+	Help: "Number of peers the chain's node is connected to",
+}, []string{"chain"})
+
+var rpcSyncProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_rpc_sync_progress",
+	Help: "Fraction of the highest known block the chain's node has synced, 1 once it is synced",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(rpcPeerCount, rpcSyncProgress)
+}
+
+// peerMonitor reports the node's peer count and sync progress every peerCheckInterval, warning when the node has no
+// peers
+type peerMonitor struct {
+	cfg  *Config
+	conn Connection
+	log  log15.Logger
+	stop <-chan int
+}
+
+func newPeerMonitor(cfg *Config, conn Connection, log log15.Logger, stop <-chan int) *peerMonitor {
+	return &peerMonitor{cfg: cfg, conn: conn, log: log, stop: stop}
+}
+
+// start checks the peer count until stop is closed. Failed checks are retried at the next interval.
+func (m *peerMonitor) start() {
+	go func() {
+		ticker := time.NewTicker(m.cfg.peerCheckInterval)
+		defer ticker.Stop()
+		for {
+			m.check()
+			m.checkSync()
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (m *peerMonitor) check() {
+	peers, err := m.conn.GetPeerCount()
+	if err != nil {
+		m.log.Debug("Unable to get the node's peer count", "err", err)
+		return
+	}
+	rpcPeerCount.WithLabelValues(m.cfg.name).Set(float64(peers))
+	if peers == 0 {
+		m.log.Warn("Node has no peers and may be isolated from the network")
+	}
+}
+
+func (m *peerMonitor) checkSync() {
+	syncing, progress, err := m.conn.CheckSync()
+	if err != nil {
+		m.log.Debug("Unable to check whether the node is syncing", "err", err)
+		return
+	}
+	if !syncing {
+		rpcSyncProgress.WithLabelValues(m.cfg.name).Set(1)
+		return
+	}
+	rpcSyncProgress.WithLabelValues(m.cfg.name).Set(progress.Ratio())
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPeerMonitor(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.peers = 8
+	cfg := createConfig("peers", big.NewInt(0), nil)
+	monitor := newPeerMonitor(cfg, conn, newTestLogger(cfg.name), make(chan int))
+
+	monitor.check()
+	if peers := testutil.ToFloat64(rpcPeerCount.WithLabelValues(cfg.name)); peers != 8 {
+		t.Fatalf("expected a peer count of 8, got: %v", peers)
+	}
+
+	conn.peers = 0
+	monitor.check()
+	if peers := testutil.ToFloat64(rpcPeerCount.WithLabelValues(cfg.name)); peers != 0 {
+		t.Fatalf("expected a peer count of 0, got: %v", peers)
+	}
+	if backend.called("GetPeerCount") != 2 {
+		t.Fatalf("expected the peer count to be checked twice, got: %d", backend.called("GetPeerCount"))
+	}
+}
+
+func TestPeerMonitor_SyncProgress(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.sync = &connection.SyncProgress{CurrentBlock: 300, HighestBlock: 400}
+	cfg := createConfig("sync", big.NewInt(0), nil)
+	monitor := newPeerMonitor(cfg, conn, newTestLogger(cfg.name), make(chan int))
+
+	monitor.checkSync()
+	if progress := testutil.ToFloat64(rpcSyncProgress.WithLabelValues(cfg.name)); progress != 0.75 {
+		t.Fatalf("expected a sync progress of 0.75, got: %v", progress)
+	}
+
+	conn.sync = nil
+	monitor.checkSync()
+	if progress := testutil.ToFloat64(rpcSyncProgress.WithLabelValues(cfg.name)); progress != 1 {
+		t.Fatalf("expected a sync progress of 1 once synced, got: %v", progress)
+	}
+}
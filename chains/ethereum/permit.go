@@ -0,0 +1,85 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// PermitFungibleTransfer is an erc20 deposit made with an EIP-2612 permit instead of an approval. Its proposal is
+// voted on like a FungibleTransfer, but it is executed by the erc20 handler's depositWithPermit.
+var PermitFungibleTransfer msg.TransferType = "PermitFungibleTransfer"
+
+// Permit is the EIP-2612 permit signature carried by a PermitDeposited event
+type Permit struct {
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+// NewPermitFungibleTransfer returns a PermitFungibleTransfer message. The payload starts with the amount and
+// recipient of a FungibleTransfer, followed by the permit's deadline, v, r and s.
+func NewPermitFungibleTransfer(source, dest msg.ChainId, nonce msg.Nonce, amount *big.Int, resourceId msg.ResourceId, recipient []byte, permit Permit) msg.Message {
+	return msg.Message{
+		Source:       source,
+		Destination:  dest,
+		Type:         PermitFungibleTransfer,
+		DepositNonce: nonce,
+		ResourceId:   resourceId,
+		Payload: []interface{}{
+			amount.Bytes(),
+			recipient,
+			permit.Deadline.Bytes(),
+			[]byte{permit.V},
+			permit.R[:],
+			permit.S[:],
+		},
+	}
+}
+
+// parsePermit decodes the permit from the data of a PermitDeposited(uint8 indexed destinationChainID,
+// bytes32 indexed resourceID, uint64 indexed depositNonce, uint256 deadline, uint8 v, bytes32 r, bytes32 s) log
+func parsePermit(log ethtypes.Log) (Permit, error) {
+	if len(log.Data) != 128 {
+		return Permit{}, fmt.Errorf("unexpected PermitDeposited data length %d in tx %s", len(log.Data), log.TxHash.Hex())
+	}
+	var permit Permit
+	permit.Deadline = new(big.Int).SetBytes(log.Data[:32])
+	permit.V = log.Data[63]
+	copy(permit.R[:], log.Data[64:96])
+	copy(permit.S[:], log.Data[96:])
+	return permit, nil
+}
+
+// messagePermit returns the permit from the payload of a PermitFungibleTransfer message
+func messagePermit(m msg.Message) Permit {
+	var permit Permit
+	permit.Deadline = new(big.Int).SetBytes(m.Payload[2].([]byte))
+	permit.V = m.Payload[3].([]byte)[0]
+	copy(permit.R[:], m.Payload[4].([]byte))
+	copy(permit.S[:], m.Payload[5].([]byte))
+	return permit
+}
+
+// depositWithPermit submits the erc20 handler's depositWithPermit for m, in place of executing its proposal
+func (w *writer) depositWithPermit(opts *bind.TransactOpts, m msg.Message, token common.Address) (*ethtypes.Transaction, error) {
+	permit := messagePermit(m)
+	return w.permitHandler.DepositWithPermit(
+		opts,
+		token,
+		new(big.Int).SetBytes(m.Payload[0].([]byte)),
+		common.BytesToAddress(m.Payload[1].([]byte)),
+		permit.Deadline,
+		permit.V,
+		permit.R,
+		permit.S,
+	)
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParsePermit(t *testing.T) {
+	expected := Permit{
+		Deadline: big.NewInt(1700000000),
+		V:        27,
+		R:        common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111"),
+		S:        common.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222"),
+	}
+
+	var data []byte
+	data = append(data, math.PaddedBigBytes(expected.Deadline, 32)...)
+	data = append(data, common.LeftPadBytes([]byte{expected.V}, 32)...)
+	data = append(data, expected.R[:]...)
+	data = append(data, expected.S[:]...)
+
+	permit, err := parsePermit(ethtypes.Log{Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(permit, expected) {
+		t.Fatalf("unexpected permit.\n\tExpected: %#v\n\tGot: %#v\n", expected, permit)
+	}
+
+	_, err = parsePermit(ethtypes.Log{Data: data[:96]})
+	if err == nil {
+		t.Fatal("expected error for short data")
+	}
+}
+
+func TestNewPermitFungibleTransfer(t *testing.T) {
+	permit := Permit{
+		Deadline: big.NewInt(1700000000),
+		V:        28,
+		R:        common.HexToHash("0x01"),
+		S:        common.HexToHash("0x02"),
+	}
+	amount := big.NewInt(10)
+	recipient := common.HexToAddress("0x0000000000000000000000000000000000000abc").Bytes()
+	m := NewPermitFungibleTransfer(0, 1, 2, amount, msg.ResourceId{1}, recipient, permit)
+
+	// The permit deposit is voted on with the same proposal data as a fungible transfer
+	transfer := msg.NewFungibleTransfer(0, 1, 2, amount, msg.ResourceId{1}, recipient)
+	if !reflect.DeepEqual(m.Payload[:2], transfer.Payload) {
+		t.Fatalf("unexpected transfer payload: %v", m.Payload[:2])
+	}
+	if got := messagePermit(m); !reflect.DeepEqual(got, permit) {
+		t.Fatalf("unexpected permit.\n\tExpected: %#v\n\tGot: %#v\n", permit, got)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// DepositRecordsSlot is the storage slot of the bridge's _depositRecords mapping, which is keyed by deposit nonce
+// and then destination chain ID
+const DepositRecordsSlot = 10
+
+var ErrInvalidProof = errors.New("deposit failed proof verification")
+var ErrNoHeaderOracle = errors.New("trustlessMode requires a header oracle")
+
+// HeaderOracle provides block hashes of a source chain from a source other than the chain's own node,
+// such as a light client contract on the destination chain
+type HeaderOracle interface {
+	// BlockHash returns the trusted hash of the block at number on chain
+	BlockHash(chain msg.ChainId, number *big.Int) (common.Hash, error)
+}
+
+// ProofVerifier checks that a deposit was recorded by the bridge in the source chain's state. The block header
+// is checked against the HeaderOracle, then the EIP-1186 proof of the deposit record's storage slot is verified
+// against the header's state root, so the relayer does not have to trust the node it listens to.
+type ProofVerifier struct {
+	conn   Connection
+	oracle HeaderOracle
+	bridge common.Address
+}
+
+// NewProofVerifier returns a ProofVerifier for deposits made to bridge
+func NewProofVerifier(conn Connection, oracle HeaderOracle, bridge common.Address) *ProofVerifier {
+	return &ProofVerifier{conn: conn, oracle: oracle, bridge: bridge}
+}
+
+// depositRecordSlot returns the storage slot of _depositRecords[nonce][dest]
+func depositRecordSlot(nonce msg.Nonce, dest msg.ChainId) common.Hash {
+	nonceSlot := crypto.Keccak256(
+		common.LeftPadBytes(new(big.Int).SetUint64(uint64(nonce)).Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(DepositRecordsSlot).Bytes(), 32),
+	)
+	return crypto.Keccak256Hash(common.LeftPadBytes(big.NewInt(int64(dest)).Bytes(), 32), nonceSlot)
+}
+
+// VerifyDeposit verifies the deposit for m was recorded in block. Errors wrapping ErrInvalidProof mean the
+// deposit could not be proven, any other error may succeed on retry.
+func (v *ProofVerifier) VerifyDeposit(m msg.Message, block *big.Int) error {
+	trusted, err := v.oracle.BlockHash(m.Source, block)
+	if err != nil {
+		return fmt.Errorf("unable to get trusted block hash: %w", err)
+	}
+
+	header, err := v.conn.Backend().HeaderByNumber(context.Background(), block)
+	if err != nil {
+		return fmt.Errorf("unable to get block header: %w", err)
+	}
+	if header.Hash() != trusted {
+		return fmt.Errorf("%w: block %s has hash %s, expected %s", ErrInvalidProof, block, header.Hash().Hex(), trusted.Hex())
+	}
+
+	slot := depositRecordSlot(m.DepositNonce, m.Destination)
+	proof, err := v.conn.GetProof(context.Background(), v.bridge, []string{slot.Hex()}, block)
+	if err != nil {
+		return err
+	}
+
+	value, err := verifyProof(header.Root, crypto.Keccak256(v.bridge.Bytes()), proof.AccountProof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %s", ErrInvalidProof, err)
+	}
+	if value == nil {
+		return fmt.Errorf("%w: bridge %s not found in state", ErrInvalidProof, v.bridge.Hex())
+	}
+	var account types.StateAccount
+	err = rlp.DecodeBytes(value, &account)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %s", ErrInvalidProof, err)
+	}
+
+	if len(proof.StorageProof) != 1 {
+		return fmt.Errorf("%w: expected one storage proof, got %d", ErrInvalidProof, len(proof.StorageProof))
+	}
+	value, err = verifyProof(account.Root, crypto.Keccak256(slot.Bytes()), proof.StorageProof[0].Proof)
+	if err != nil {
+		return fmt.Errorf("%w: storage proof: %s", ErrInvalidProof, err)
+	}
+	// Zero values are not stored, so a missing slot means there is no deposit record
+	if value == nil {
+		return fmt.Errorf("%w: no deposit record for nonce %d to chain %d", ErrInvalidProof, m.DepositNonce, m.Destination)
+	}
+	return nil
+}
+
+// verifyProof returns the value for key in the trie with root, nil if the proof shows key is absent
+func verifyProof(root common.Hash, key []byte, proof []string) ([]byte, error) {
+	db := memorydb.New()
+	for _, encoded := range proof {
+		node, err := hexutil.Decode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		err = db.Put(crypto.Keccak256(node), node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return trie.VerifyProof(root, key, db)
+}
+
+// provenMetadataArgs is the ABI encoding of ProvenMetadata
+var provenMetadataArgs = func() abi.Arguments {
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	uintType, _ := abi.NewType("uint256", "", nil)
+	hashType, _ := abi.NewType("bytes32", "", nil)
+	nodesType, _ := abi.NewType("bytes[]", "", nil)
+	return abi.Arguments{{Type: bytesType}, {Type: uintType}, {Type: hashType}, {Type: nodesType}, {Type: nodesType}}
+}()
+
+// ProvenMetadata is the metadata of a generic deposit with the EIP-1186 proof of its deposit record, relayed when
+// attachStorageProof is set. It is ABI encoded as
+//
+//	(bytes metadata, uint256 blockNumber, bytes32 slot, bytes[] accountProof, bytes[] storageProof)
+//
+// so a destination that trusts the source chain's block hashes can verify the deposit without trusting the relayer.
+type ProvenMetadata struct {
+	Metadata     []byte
+	Block        *big.Int
+	Slot         common.Hash // Storage slot of the bridge's deposit record
+	AccountProof [][]byte    // Trie nodes from the block's state root to the bridge account
+	StorageProof [][]byte    // Trie nodes from the bridge's storage root to the slot
+}
+
+// Encode returns the ABI encoding of p
+func (p ProvenMetadata) Encode() ([]byte, error) {
+	return provenMetadataArgs.Pack(p.Metadata, p.Block, p.Slot, p.AccountProof, p.StorageProof)
+}
+
+// DecodeProvenMetadata decodes the metadata of a generic deposit relayed with attachStorageProof
+func DecodeProvenMetadata(data []byte) (ProvenMetadata, error) {
+	values, err := provenMetadataArgs.Unpack(data)
+	if err != nil {
+		return ProvenMetadata{}, err
+	}
+	return ProvenMetadata{
+		Metadata:     values[0].([]byte),
+		Block:        values[1].(*big.Int),
+		Slot:         values[2].([32]byte),
+		AccountProof: values[3].([][]byte),
+		StorageProof: values[4].([][]byte),
+	}, nil
+}
+
+// attachStorageProof returns metadata encoded with the proof of the deposit record for nonce to destId at block
+func (l *listener) attachStorageProof(metadata []byte, destId msg.ChainId, nonce msg.Nonce, block *big.Int) ([]byte, error) {
+	slot := depositRecordSlot(nonce, destId)
+	proof, err := l.conn.GetContractStorageProof(context.Background(), l.cfg.bridgeContract, []common.Hash{slot}, block)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get deposit record proof: %w", err)
+	}
+	return ProvenMetadata{
+		Metadata:     metadata,
+		Block:        block,
+		Slot:         slot,
+		AccountProof: proofNodes(proof.AccountProof),
+		StorageProof: proofNodes(proof.StorageProofs[0].Proof),
+	}.Encode()
+}
+
+func proofNodes(nodes []hexutil.Bytes) [][]byte {
+	b := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		b[i] = node
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// mockHeaderOracle trusts the hashes reported by the node, or returns hash for every block if it is set
+type mockHeaderOracle struct {
+	conn Connection
+	hash *common.Hash
+}
+
+func (o *mockHeaderOracle) BlockHash(chain msg.ChainId, number *big.Int) (common.Hash, error) {
+	if o.hash != nil {
+		return *o.hash, nil
+	}
+	header, err := o.conn.Backend().HeaderByNumber(context.Background(), number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+// createVerifiedDeposit makes an erc20 deposit and returns the listener that received it along with the
+// deposit's message and block
+func createVerifiedDeposit(t *testing.T, stop chan int, errs chan error) (*listener, msg.Message, *big.Int) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	l, router := createTestListener(t, aliceTestConfig, contracts, stop, errs)
+
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(aliceTestConfig.id)))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+	createErc20Deposit(t, l.bridgeContract, client, resourceId, recipient, 1, amount)
+	m := msg.NewFungibleTransfer(aliceTestConfig.id, 1, 1, amount, resourceId, recipient.Bytes())
+	verifyMessage(t, router, m, errs)
+
+	logs, err := client.Client.FilterLogs(context.Background(), buildQuery(contracts.BridgeAddress, utils.Deposit, big.NewInt(0), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected one deposit, got: %d", len(logs))
+	}
+	return l, m, new(big.Int).SetUint64(logs[0].BlockNumber)
+}
+
+func TestProofVerifier_VerifyDeposit(t *testing.T) {
+	stop := make(chan int)
+	defer close(stop)
+	errs := make(chan error)
+	l, m, block := createVerifiedDeposit(t, stop, errs)
+	defer l.conn.Close()
+
+	v := NewProofVerifier(l.conn, &mockHeaderOracle{conn: l.conn}, l.cfg.bridgeContract)
+	err := v.VerifyDeposit(m, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The deposit record is only written by the deposit, so the previous block cannot prove it
+	err = v.VerifyDeposit(m, new(big.Int).Sub(block, big.NewInt(1)))
+	if !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidProof for block before deposit, got: %v", err)
+	}
+
+	missing := m
+	missing.DepositNonce = 2
+	err = v.VerifyDeposit(missing, block)
+	if !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidProof for missing deposit, got: %v", err)
+	}
+
+	// A node reporting a different chain than the oracle cannot prove any deposit
+	untrusted := common.HexToHash("0x01")
+	v = NewProofVerifier(l.conn, &mockHeaderOracle{hash: &untrusted}, l.cfg.bridgeContract)
+	err = v.VerifyDeposit(m, block)
+	if !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidProof for untrusted header, got: %v", err)
+	}
+}
+
+func TestListener_TrustlessMode(t *testing.T) {
+	stop := make(chan int)
+	defer close(stop)
+	errs := make(chan error)
+	l, m, block := createVerifiedDeposit(t, stop, errs)
+	defer l.conn.Close()
+
+	cfg := l.cfg
+	cfg.trustlessMode = true
+	untrusted := common.HexToHash("0x01")
+	for _, test := range []struct {
+		oracle HeaderOracle
+		routed bool
+	}{
+		{oracle: &mockHeaderOracle{conn: l.conn}, routed: true},
+		{oracle: &mockHeaderOracle{hash: &untrusted}, routed: false},
+	} {
+		router := &MockRouter{msgs: make(chan msg.Message, 1)}
+		trustless := NewListener(l.conn, &cfg, TestLogger, nil, stop, errs, nil)
+		trustless.setContracts(l.bridgeContract, l.erc20HandlerContract, l.erc721HandlerContract, l.genericHandlerContract)
+		trustless.setRouter(router)
+		trustless.setVerifier(NewProofVerifier(l.conn, test.oracle, cfg.bridgeContract))
+
+		err := trustless.getDepositEventsForRange(block, block, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case routed := <-router.msgs:
+			if !test.routed {
+				t.Fatalf("unverified deposit was routed: %+v", routed)
+			}
+			err = compareMessage(m, routed)
+			if err != nil {
+				t.Fatal(err)
+			}
+		default:
+			if test.routed {
+				t.Fatal("verified deposit was not routed")
+			}
+		}
+	}
+}
+
+func TestListener_AttachStorageProof(t *testing.T) {
+	conn, err := newMockConnection(newMockBackend(), AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.storageProof = &connection.ContractStorageProof{
+		AccountProof:  []hexutil.Bytes{{0x01}, {0x02, 0x03}},
+		StorageProofs: []connection.StorageProofEntry{{Key: depositRecordSlot(5, 2), Value: big.NewInt(1), Proof: []hexutil.Bytes{{0x04}}}},
+	}
+	cfg := createConfig("proofs", big.NewInt(0), nil)
+	l := NewListener(conn, cfg, newTestLogger(cfg.name), nil, make(chan int), make(chan error, 1), nil)
+
+	data, err := l.attachStorageProof([]byte("metadata"), 2, 5, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proven, err := DecodeProvenMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ProvenMetadata{
+		Metadata:     []byte("metadata"),
+		Block:        big.NewInt(10),
+		Slot:         depositRecordSlot(5, 2),
+		AccountProof: [][]byte{{0x01}, {0x02, 0x03}},
+		StorageProof: [][]byte{{0x04}},
+	}
+	if !reflect.DeepEqual(proven, expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, proven)
+	}
+
+	conn.storageProof = nil
+	_, err = l.attachStorageProof([]byte("metadata"), 2, 5, big.NewInt(10))
+	if err == nil {
+		t.Fatal("expected error when the proof cannot be fetched")
+	}
+}
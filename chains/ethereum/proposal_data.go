@@ -6,42 +6,20 @@ package ethereum
 import (
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
+	handlerabi "github.com/ChainSafe/ChainBridge/chains/ethereum/abi"
 )
 
-// constructErc20ProposalData returns the bytes to construct a proposal suitable for Erc20
+// ConstructErc20ProposalData returns the bytes to construct a proposal suitable for Erc20
 func ConstructErc20ProposalData(amount []byte, recipient []byte) []byte {
-	var data []byte
-	data = append(data, common.LeftPadBytes(amount, 32)...) // amount (uint256)
-
-	recipientLen := big.NewInt(int64(len(recipient))).Bytes()
-	data = append(data, common.LeftPadBytes(recipientLen, 32)...) // length of recipient (uint256)
-	data = append(data, recipient...)                             // recipient ([]byte)
-	return data
+	return handlerabi.EncodeERC20DepositData(recipient, new(big.Int).SetBytes(amount))
 }
 
-// constructErc721ProposalData returns the bytes to construct a proposal suitable for Erc721
+// ConstructErc721ProposalData returns the bytes to construct a proposal suitable for Erc721
 func ConstructErc721ProposalData(tokenId []byte, recipient []byte, metadata []byte) []byte {
-	var data []byte
-	data = append(data, common.LeftPadBytes(tokenId, 32)...) // tokenId ([]byte)
-
-	recipientLen := big.NewInt(int64(len(recipient))).Bytes()
-	data = append(data, common.LeftPadBytes(recipientLen, 32)...) // length of recipient
-	data = append(data, recipient...)                             // recipient ([]byte)
-
-	metadataLen := big.NewInt(int64(len(metadata))).Bytes()
-	data = append(data, common.LeftPadBytes(metadataLen, 32)...) // length of metadata (uint256)
-	data = append(data, metadata...)                             // metadata ([]byte)
-	return data
+	return handlerabi.EncodeERC721ProposalData(new(big.Int).SetBytes(tokenId), recipient, metadata)
 }
 
-// constructGenericProposalData returns the bytes to construct a generic proposal
+// ConstructGenericProposalData returns the bytes to construct a generic proposal
 func ConstructGenericProposalData(metadata []byte) []byte {
-	var data []byte
-
-	metadataLen := big.NewInt(int64(len(metadata)))
-	data = append(data, math.PaddedBigBytes(metadataLen, 32)...) // length of metadata (uint256)
-	data = append(data, metadata...)                             // metadata ([]byte)
-	return data
+	return handlerabi.EncodeGenericDepositData(metadata)
 }
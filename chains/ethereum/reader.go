@@ -0,0 +1,105 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/ChainBridge/core"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var _ chains.Reader = &Reader{}
+
+// Reader queries deposits and proposals with the bridge contract's view functions
+type Reader struct {
+	cfg            Config
+	conn           Connection
+	bridgeContract *Bridge.Bridge
+	ownsConn       bool // Whether Close closes conn
+}
+
+// newReader creates a Reader that shares conn
+func newReader(conn Connection, cfg *Config, bridge *Bridge.Bridge) *Reader {
+	return &Reader{cfg: *cfg, conn: conn, bridgeContract: bridge}
+}
+
+// NewReader connects to the chain without a keypair, so neither a keystore nor a blockstore is needed
+func NewReader(chainCfg *core.ChainConfig, logger log15.Logger) (*Reader, error) {
+	cfg, err := parseChainConfig(chainCfg)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewConfigError(bridgeErrors.CodeInvalidConfig, false, err), chainCfg.Id)
+	}
+
+	conn, contracts, err := connectReadOnly(cfg, logger.New("chain", cfg.name))
+	if err != nil {
+		return nil, err
+	}
+	r := newReader(conn, cfg, contracts.bridge)
+	r.ownsConn = true
+	return r, nil
+}
+
+// GetDepositRecord returns the deposit data stored by the bridge, or ErrDepositNotFound if there is none
+func (r *Reader) GetDepositRecord(destination msg.ChainId, nonce uint64) (*chains.DepositRecord, error) {
+	data, err := r.bridgeContract.DepositRecords(r.conn.CallOpts(), nonce, uint8(destination))
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err), r.cfg.id)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: nonce %d to chain %d", ErrDepositNotFound, nonce, destination)
+	}
+	return &chains.DepositRecord{Destination: destination, Nonce: nonce, Data: data}, nil
+}
+
+// GetProposalStatus returns the status of the proposal for the deposit. Proposals are stored by their data hash,
+// so it is read from the latest ProposalEvent for the deposit. Without any events the proposal is inactive.
+func (r *Reader) GetProposalStatus(source msg.ChainId, nonce uint64) (chains.ProposalStatus, error) {
+	query := eth.FilterQuery{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{r.cfg.bridgeContract},
+		Topics: [][]common.Hash{
+			{utils.ProposalEvent.GetTopic()},
+			{common.BigToHash(big.NewInt(int64(source)))},
+			{common.BigToHash(new(big.Int).SetUint64(nonce))},
+		},
+	}
+	logs, err := r.conn.Backend().FilterLogs(context.Background(), query)
+	if err != nil {
+		return 0, bridgeErrors.WithChain(bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err), r.cfg.id)
+	}
+	if len(logs) == 0 {
+		return chains.ProposalStatusInactive, nil
+	}
+
+	// The event data is the resource ID followed by the data hash
+	data := logs[len(logs)-1].Data
+	if len(data) != 64 {
+		return 0, fmt.Errorf("unexpected ProposalEvent data length %d", len(data))
+	}
+	var dataHash [32]byte
+	copy(dataHash[:], data[32:])
+
+	prop, err := r.bridgeContract.GetProposal(r.conn.CallOpts(), uint8(source), nonce, dataHash)
+	if err != nil {
+		return 0, bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err), r.cfg.id)
+	}
+	return chains.ProposalStatus(prop.Status), nil
+}
+
+// Close closes the connection if it was opened by NewReader
+func (r *Reader) Close() {
+	if r.ownsConn {
+		r.conn.Close()
+	}
+}
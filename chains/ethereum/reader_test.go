@@ -0,0 +1,93 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/chains"
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestReader(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	writerA, writerB, stopA, stopB, errA, errB := createWriters(t, client, contracts)
+
+	defer stopA()
+	defer stopB()
+	defer writerA.conn.Close()
+	defer writerB.conn.Close()
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	// Funding uses the handler's allowance, so approve the deposit afterwards
+	ethtest.FundErc20Handler(t, client, contracts.ERC20HandlerAddress, erc20Address, big.NewInt(50))
+	ethtest.Erc20Approve(t, client, erc20Address, contracts.ERC20HandlerAddress, big.NewInt(50))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	bridge, err := Bridge.NewBridge(contracts.BridgeAddress, writerA.conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newReader(writerA.conn, &writerA.cfg, bridge)
+
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+	createErc20Deposit(t, bridge, client, resourceId, recipient, 1, amount)
+
+	// Wait for the deposit to be mined
+	var record *chains.DepositRecord
+	for i := 0; ; i++ {
+		record, err = r.GetDepositRecord(1, 1)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrDepositNotFound) || i == 30 {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Second)
+	}
+	expected := &chains.DepositRecord{
+		Destination: 1,
+		Nonce:       1,
+		Data:        utils.ConstructErc20DepositData(recipient.Bytes(), amount),
+	}
+	if !reflect.DeepEqual(record, expected) {
+		t.Fatalf("unexpected deposit record. Expected: %+v Got: %+v", expected, record)
+	}
+
+	_, err = r.GetDepositRecord(1, 2)
+	if !errors.Is(err, ErrDepositNotFound) {
+		t.Fatalf("expected ErrDepositNotFound, got: %v", err)
+	}
+
+	// Execute a proposal for a deposit from chain 1
+	m := msg.NewFungibleTransfer(1, 0, 0, amount, resourceId, recipient.Bytes())
+	status, err := r.GetProposalStatus(m.Source, uint64(m.DepositNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != chains.ProposalStatusInactive {
+		t.Fatalf("expected an inactive proposal, got: %s", status)
+	}
+
+	routeMessageAndWait(t, client, writerA, writerB, m, errA, errB)
+
+	status, err = r.GetProposalStatus(m.Source, uint64(m.DepositNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != chains.ProposalStatusExecuted {
+		t.Fatalf("expected an executed proposal, got: %s", status)
+	}
+}
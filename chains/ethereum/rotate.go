@@ -0,0 +1,84 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+)
+
+// RotateKeypair replaces the keypair the chain signs with. The writers finish the messages they are resolving with
+// the current keypair first, and messages routed in the meantime wait for the rotation.
+func (c *Chain) RotateKeypair(kp *secp256k1.Keypair) error {
+	if c.fireblocks != nil {
+		return connection.ErrFireblocksRotation
+	}
+	c.restartLock.Lock()
+	defer c.restartLock.Unlock()
+	writers := []*writer{c.writer}
+	if c.priority != nil {
+		writers = append(writers, c.priority)
+	}
+	for _, w := range writers {
+		w.rotation.Lock()
+		defer w.rotation.Unlock()
+	}
+
+	// The writers, listener and fee collector may share connections, each is only rotated once
+	conns := []Connection{c.conn}
+	for _, w := range writers {
+		conns = append(conns, w.conn)
+	}
+	rotated := make(map[Connection]bool)
+	for _, conn := range conns {
+		if rotated[conn] {
+			continue
+		}
+		rotated[conn] = true
+		err := conn.RotateKeypair(kp)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.listener.RotateKeypair(kp)
+	if err != nil {
+		return err
+	}
+	c.writer.log.Info("Rotated keypair", "relayer", kp.Address())
+	return nil
+}
+
+// startKeyRotation reloads the keypair from the keystore and rotates to it each time SIGUSR2 is received, until
+// the writer is stopped. The key file of the from address can be replaced with a new key before signalling.
+func (c *Chain) startKeyRotation() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	cfg := c.writer.cfg
+	stop := c.writer.stop
+	log := c.writer.log
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				kp, err := loadKeypair(&cfg, c.cfg.Insecure)
+				if err != nil {
+					log.Error("Unable to reload keypair", "err", err)
+					continue
+				}
+				err = c.RotateKeypair(kp)
+				if err != nil {
+					log.Error("Unable to rotate keypair", "err", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/ChainBridge/message"
+)
+
+var ErrUnsignedMessage = errors.New("message is not signed by a relayer")
+
+// verifySignature checks that a signed message was signed by a relayer of the bridge, so a message changed after it
+// was signed is rejected. Unsigned messages are accepted unless requireSignedMessages is set.
+func (w *writer) verifySignature(m message.SignedMessage) error {
+	if m.RelayerSignature == nil {
+		if w.cfg.requireSignedMessages {
+			return ErrUnsignedMessage
+		}
+		return nil
+	}
+
+	signer, err := m.Signer()
+	if err != nil {
+		return err
+	}
+	isRelayer, err := w.bridgeContract.IsRelayer(w.conn.CallOpts(), signer)
+	if err != nil {
+		return fmt.Errorf("unable to check signer %s is a relayer: %w", signer.Hex(), err)
+	}
+	if !isRelayer {
+		return fmt.Errorf("%w: signed by %s, which is not a relayer", message.ErrInvalidSignature, signer.Hex())
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package ethereum
 import (
 	"fmt"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
 	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 const TestEndpoint = "ws://localhost:8545"
@@ -31,6 +33,13 @@ var TestChainId = msg.ChainId(0)
 
 var aliceTestConfig = createConfig("alice", nil, nil)
 
+func TestMain(m *testing.M) {
+	// The test chain produces a block every second, waiting longer for blocks only slows the tests down
+	BlockRetryInterval = time.Second
+	connection.BlockRetryInterval = time.Second
+	os.Exit(m.Run())
+}
+
 func createConfig(name string, startBlock *big.Int, contracts *utils.DeployedContracts) *Config {
 	cfg := &Config{
 		name:                   name,
@@ -50,6 +59,19 @@ func createConfig(name string, startBlock *big.Int, contracts *utils.DeployedCon
 		http:                   false,
 		startBlock:             startBlock,
 		blockConfirmations:     big.NewInt(3),
+		lagAlertThreshold:      big.NewInt(DefaultLagAlertThreshold),
+		watchdogInterval:       DefaultWatchdogInterval,
+		maxOnChainBytes:        DefaultMaxOnChainBytes,
+		maxMessageBytes:        DefaultMaxMessageBytes,
+		compressionThreshold:   DefaultCompressionThreshold,
+		useBatchRPC:            true,
+		ensRegistry:            connection.DefaultENSRegistry,
+		ensCacheTTL:            connection.DefaultENSCacheTTL,
+		maxConcurrentProposals: DefaultMaxConcurrentProposals,
+		mempoolConfirmTimeout:  DefaultMempoolConfirmTimeout,
+		maxBlocksPerPoll:       DefaultMaxBlocksPerPoll,
+		peerCheckInterval:      DefaultPeerCheckInterval,
+		balanceCheckInterval:   DefaultBalanceCheckInterval,
 	}
 
 	if contracts != nil {
@@ -107,20 +129,22 @@ func createErc20Deposit(
 	destRecipient common.Address,
 	destId msg.ChainId,
 	amount *big.Int,
-) {
+) *ethtypes.Transaction {
 
 	data := utils.ConstructErc20DepositData(destRecipient.Bytes(), amount)
 
 	// Incrememnt Nonce by one
 	client.Opts.Nonce = client.Opts.Nonce.Add(client.Opts.Nonce, big.NewInt(1))
-	if _, err := contract.Deposit(
+	tx, err := contract.Deposit(
 		client.Opts,
 		uint8(destId),
 		rId,
 		data,
-	); err != nil {
+	)
+	if err != nil {
 		t.Fatal(err)
 	}
+	return tx
 }
 
 func createErc721Deposit(
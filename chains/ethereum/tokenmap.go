@@ -0,0 +1,111 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrTokenMapping = errors.New("deposit does not match the token map")
+
+// DestinationToken is a token a source token is bridged to
+type DestinationToken struct {
+	ChainId  *msg.ChainId `json:"chainId"` // Chain the token is on, may be omitted if the source token has one destination
+	Decimals uint8        `json:"decimals"`
+}
+
+// TokenMapping configures a source chain token and the tokens it is bridged to, keyed by their address
+type TokenMapping struct {
+	ResourceId       string                      `json:"resourceId"`
+	Decimals         uint8                       `json:"decimals"`
+	DestinationToken map[string]DestinationToken `json:"destinationToken"`
+}
+
+// TokenMap holds the mappings of the source chain's tokens, keyed by token address
+type TokenMap map[common.Address]TokenMapping
+
+// LoadTokenMap reads a TokenMap from the JSON file at path
+func LoadTokenMap(path string) (TokenMap, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(TokenMap)
+	err = json.Unmarshal(data, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse token map %s: %w", path, err)
+	}
+	for token, mapping := range tokens {
+		_, err = parseResourceId(mapping.ResourceId)
+		if err != nil {
+			return nil, fmt.Errorf("token map %s: token %s: %w", path, token.Hex(), err)
+		}
+		if len(mapping.DestinationToken) == 0 {
+			return nil, fmt.Errorf("token map %s: token %s has no destinationToken", path, token.Hex())
+		}
+		for dest, destToken := range mapping.DestinationToken {
+			if !common.IsHexAddress(dest) {
+				return nil, fmt.Errorf("token map %s: destination token %s is not an address", path, dest)
+			}
+			if destToken.ChainId == nil && len(mapping.DestinationToken) > 1 {
+				return nil, fmt.Errorf("token map %s: token %s has several destination tokens, each requires a chainId", path, token.Hex())
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// DecimalNormalizer converts the amounts of fungible deposits from the decimals of the source token to those of the
+// token it is bridged to
+type DecimalNormalizer struct {
+	tokens TokenMap
+}
+
+func NewDecimalNormalizer(tokens TokenMap) *DecimalNormalizer {
+	return &DecimalNormalizer{tokens: tokens}
+}
+
+// Normalize returns the amount of token deposited to destId with resource ID rId, multiplied by
+// 10^(destDecimals - srcDecimals) if the destination token has more decimals and divided otherwise. Division rounds
+// down, the amount lost is returned as dust. Amounts of tokens not in the map, or not mapped to a token on destId,
+// are returned unchanged.
+func (n *DecimalNormalizer) Normalize(token common.Address, rId msg.ResourceId, destId msg.ChainId, amount *big.Int) (*big.Int, *big.Int, error) {
+	dust := new(big.Int)
+	mapping, ok := n.tokens[token]
+	if !ok {
+		return amount, dust, nil
+	}
+	mapped, err := parseResourceId(mapping.ResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mapped != rId {
+		return nil, nil, fmt.Errorf("%w: token %s has resource ID %x, the deposit has %x", ErrTokenMapping, token.Hex(), mapped, rId)
+	}
+
+	for _, dest := range mapping.DestinationToken {
+		if dest.ChainId != nil && *dest.ChainId != destId {
+			continue
+		}
+		if dest.Decimals == mapping.Decimals {
+			return amount, dust, nil
+		}
+		if dest.Decimals > mapping.Decimals {
+			scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dest.Decimals-mapping.Decimals)), nil)
+			return new(big.Int).Mul(amount, scale), dust, nil
+		}
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(mapping.Decimals-dest.Decimals)), nil)
+		normalized, _ := new(big.Int).QuoRem(amount, scale, dust)
+		return normalized, dust, nil
+	}
+	return amount, dust, nil
+}
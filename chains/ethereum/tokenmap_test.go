@@ -0,0 +1,111 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	usdc = common.HexToAddress("0x000000000000000000000000000000000000a0b8")
+	weth = common.HexToAddress("0x000000000000000000000000000000000000c02a")
+)
+
+const testTokenMap = `{
+	"0x000000000000000000000000000000000000a0b8": {
+		"resourceId": "0x0000000000000000000000000000000000000000000000000000000000000001",
+		"decimals": 6,
+		"destinationToken": {"0x0000000000000000000000000000000000000b01": {"decimals": 18}}
+	},
+	"0x000000000000000000000000000000000000c02a": {
+		"resourceId": "0x0000000000000000000000000000000000000000000000000000000000000002",
+		"decimals": 18,
+		"destinationToken": {
+			"0x0000000000000000000000000000000000000b02": {"chainId": 1, "decimals": 18},
+			"0x0000000000000000000000000000000000000b03": {"chainId": 2, "decimals": 6}
+		}
+	}
+}`
+
+func loadTestTokenMap(t *testing.T, contents string) (TokenMap, error) {
+	dir, err := ioutil.TempDir(os.TempDir(), "tokenmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tokens.json")
+	err = ioutil.WriteFile(path, []byte(contents), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return LoadTokenMap(path)
+}
+
+func TestDecimalNormalizer(t *testing.T) {
+	tokens, err := loadTestTokenMap(t, testTokenMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalizer := NewDecimalNormalizer(tokens)
+	usdcId := msg.ResourceIdFromSlice(common.LeftPadBytes([]byte{1}, 32))
+	wethId := msg.ResourceIdFromSlice(common.LeftPadBytes([]byte{2}, 32))
+
+	for _, test := range []struct {
+		name     string
+		token    common.Address
+		rId      msg.ResourceId
+		dest     msg.ChainId
+		amount   *big.Int
+		expected *big.Int
+		dust     *big.Int
+	}{
+		{name: "USDC 6 to 18 decimals", token: usdc, rId: usdcId, dest: 1, amount: big.NewInt(1500000), expected: new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17)), dust: big.NewInt(0)},
+		{name: "WETH 18 to 18 decimals", token: weth, rId: wethId, dest: 1, amount: big.NewInt(1e18), expected: big.NewInt(1e18), dust: big.NewInt(0)},
+		{name: "WETH 18 to 6 decimals", token: weth, rId: wethId, dest: 2, amount: big.NewInt(1234567890123456789), expected: big.NewInt(1234567), dust: big.NewInt(890123456789)},
+		{name: "zero amount", token: usdc, rId: usdcId, dest: 1, amount: big.NewInt(0), expected: big.NewInt(0), dust: big.NewInt(0)},
+		{name: "amount below one destination unit", token: weth, rId: wethId, dest: 2, amount: big.NewInt(999999999999), expected: big.NewInt(0), dust: big.NewInt(999999999999)},
+		{name: "unmapped token", token: common.HexToAddress("0x01"), rId: usdcId, dest: 1, amount: big.NewInt(42), expected: big.NewInt(42), dust: big.NewInt(0)},
+		{name: "unmapped destination", token: weth, rId: wethId, dest: 3, amount: big.NewInt(42), expected: big.NewInt(42), dust: big.NewInt(0)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			amount, dust, err := normalizer.Normalize(test.token, test.rId, test.dest, test.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if amount.Cmp(test.expected) != 0 {
+				t.Fatalf("expected %s, got: %s", test.expected, amount)
+			}
+			if dust.Cmp(test.dust) != 0 {
+				t.Fatalf("expected dust of %s, got: %s", test.dust, dust)
+			}
+		})
+	}
+
+	_, _, err = normalizer.Normalize(usdc, wethId, 1, big.NewInt(1))
+	if !errors.Is(err, ErrTokenMapping) {
+		t.Fatalf("expected ErrTokenMapping for a mismatched resource ID, got: %v", err)
+	}
+}
+
+func TestLoadTokenMap_Invalid(t *testing.T) {
+	for _, contents := range []string{
+		`not json`,
+		`{"0x000000000000000000000000000000000000a0b8": {"resourceId": "0x01", "decimals": 6, "destinationToken": {"0x0000000000000000000000000000000000000b01": {"decimals": 18}}}}`,
+		`{"0x000000000000000000000000000000000000a0b8": {"resourceId": "0x0000000000000000000000000000000000000000000000000000000000000001", "decimals": 6}}`,
+		`{"0x000000000000000000000000000000000000a0b8": {"resourceId": "0x0000000000000000000000000000000000000000000000000000000000000001", "decimals": 6, "destinationToken": {"0x0000000000000000000000000000000000000b01": {"decimals": 18}, "0x0000000000000000000000000000000000000b02": {"decimals": 18}}}}`,
+	} {
+		_, err := loadTestTokenMap(t, contents)
+		if err == nil {
+			t.Fatalf("expected an error loading %s", contents)
+		}
+	}
+}
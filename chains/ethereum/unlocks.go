@@ -0,0 +1,161 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnlockPollInterval is the time between queries for TokensUnlocked events
+var UnlockPollInterval = time.Second * 15
+
+var confirmedTransfers = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_confirmed_transfers_total",
+	Help: "Number of fungible transfers confirmed delivered by a TokensUnlocked event on the destination chain",
+}, []string{"source", "destination"})
+
+var unexpectedUnlocks = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_unexpected_unlocks_total",
+	Help: "Number of TokensUnlocked events for transfers the relayer never received a deposit for",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(confirmedTransfers)
+	prometheus.MustRegister(unexpectedUnlocks)
+}
+
+// transferKey identifies a transfer by its source chain and deposit nonce
+type transferKey struct {
+	source msg.ChainId
+	nonce  msg.Nonce
+}
+
+// unlockWatcher confirms the delivery of fungible transfers to this chain. Bridges that emit
+// TokensUnlocked(address token, address recipient, uint256 amount) when an ERC20 proposal is executed also emit
+// the proposal's Executed ProposalEvent in the same transaction, which identifies the transfer.
+type unlockWatcher struct {
+	cfg      *Config
+	conn     Connection
+	log      log15.Logger
+	stop     <-chan int
+	next     *big.Int // First block not yet queried, nil until the first poll
+	expected map[transferKey]msg.Message
+	lock     sync.Mutex
+}
+
+func newUnlockWatcher(cfg *Config, conn Connection, log log15.Logger, stop <-chan int) *unlockWatcher {
+	return &unlockWatcher{
+		cfg:      cfg,
+		conn:     conn,
+		log:      log,
+		stop:     stop,
+		expected: make(map[transferKey]msg.Message),
+	}
+}
+
+// expect records a fungible transfer routed to this chain, so its unlock is confirmed rather than unexpected
+func (w *unlockWatcher) expect(m msg.Message) {
+	if m.Type != msg.FungibleTransfer {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.expected[transferKey{m.Source, m.DepositNonce}] = m
+}
+
+// start polls for unlocks from the latest block every UnlockPollInterval until stop is closed. Failed polls are
+// retried at the next interval.
+func (w *unlockWatcher) start() {
+	go func() {
+		ticker := time.NewTicker(UnlockPollInterval)
+		defer ticker.Stop()
+		for {
+			err := w.poll()
+			if err != nil {
+				w.log.Warn("Failed to check for unlocked tokens", "err", err)
+			}
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// poll handles the unlocks in the blocks since the last poll
+func (w *unlockWatcher) poll() error {
+	latest, err := w.conn.LatestBlock()
+	if err != nil {
+		return err
+	}
+	if w.next == nil {
+		w.next = latest
+	}
+	if latest.Cmp(w.next) < 0 {
+		return nil
+	}
+
+	query := buildQuery(w.cfg.bridgeContract, utils.TokensUnlocked, w.next, latest)
+	query.Topics[0] = append(query.Topics[0], utils.ProposalEvent.GetTopic())
+	logs, err := w.conn.Backend().FilterLogs(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("unable to Filter Logs: %w", err)
+	}
+	w.handleLogs(logs)
+	w.next = new(big.Int).Add(latest, big.NewInt(1))
+	return nil
+}
+
+// handleLogs matches each TokensUnlocked log with the Executed ProposalEvent of the same transaction
+func (w *unlockWatcher) handleLogs(logs []ethtypes.Log) {
+	executed := make(map[common.Hash]transferKey)
+	for _, log := range logs {
+		if log.Topics[0] == utils.ProposalEvent.GetTopic() && len(log.Topics) == 4 && utils.IsExecuted(uint8(log.Topics[3].Big().Uint64())) {
+			executed[log.TxHash] = transferKey{
+				source: msg.ChainId(log.Topics[1].Big().Uint64()),
+				nonce:  msg.Nonce(log.Topics[2].Big().Uint64()),
+			}
+		}
+	}
+
+	for _, log := range logs {
+		if log.Topics[0] != utils.TokensUnlocked.GetTopic() {
+			continue
+		}
+		if len(log.Data) != 96 {
+			w.log.Error("Unexpected TokensUnlocked data length", "length", len(log.Data), "tx", log.TxHash)
+			continue
+		}
+		token := common.BytesToAddress(log.Data[:32])
+		recipient := common.BytesToAddress(log.Data[32:64])
+		amount := new(big.Int).SetBytes(log.Data[64:])
+
+		key, ok := executed[log.TxHash]
+		w.lock.Lock()
+		m, expected := w.expected[key]
+		delete(w.expected, key)
+		w.lock.Unlock()
+		if !ok || !expected {
+			w.log.Warn("Tokens unlocked without a deposit", "token", token, "recipient", recipient, "amount", amount, "tx", log.TxHash)
+			unexpectedUnlocks.WithLabelValues(w.cfg.name).Inc()
+			continue
+		}
+
+		w.log.Info("Transfer confirmed", "status", "Confirmed", "source", m.Source, "dest", m.Destination, "depositNonce", m.DepositNonce, "token", token, "recipient", recipient, "amount", amount, "tx", log.TxHash)
+		confirmedTransfers.WithLabelValues(strconv.Itoa(int(m.Source)), strconv.Itoa(int(m.Destination))).Inc()
+	}
+}
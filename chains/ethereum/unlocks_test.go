@@ -0,0 +1,91 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// unlockLogs returns the logs of a transaction executing the proposal for a transfer, which unlocks amount
+func unlockLogs(bridge common.Address, block uint64, tx common.Hash, source msg.ChainId, nonce msg.Nonce, amount int64) []ethtypes.Log {
+	data := append(common.LeftPadBytes(common.HexToAddress("0x1").Bytes(), 32), common.LeftPadBytes(common.HexToAddress("0x2").Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(amount).Bytes(), 32)...)
+	return []ethtypes.Log{
+		{
+			Address:     bridge,
+			BlockNumber: block,
+			TxHash:      tx,
+			Topics: []common.Hash{
+				utils.ProposalEvent.GetTopic(),
+				common.BigToHash(big.NewInt(int64(source))),
+				common.BigToHash(big.NewInt(int64(nonce))),
+				common.BigToHash(big.NewInt(int64(utils.Executed))),
+			},
+			Data: make([]byte, 64),
+		},
+		{
+			Address:     bridge,
+			BlockNumber: block,
+			TxHash:      tx,
+			Topics:      []common.Hash{utils.TokensUnlocked.GetTopic()},
+			Data:        data,
+		},
+	}
+}
+
+func TestUnlockWatcher(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("unlocks", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.id = 2
+	watcher := newUnlockWatcher(cfg, conn, newTestLogger(cfg.name), make(chan int))
+
+	// The first poll starts from the latest block
+	err = watcher.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := msg.NewFungibleTransfer(1, cfg.id, 5, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	watcher.expect(m)
+	backend.logs = append(backend.logs, unlockLogs(cfg.bridgeContract, 101, common.HexToHash("0xa"), 1, 5, 10)...)
+	backend.logs = append(backend.logs, unlockLogs(cfg.bridgeContract, 101, common.HexToHash("0xb"), 1, 6, 20)...)
+	backend.setHead(101)
+
+	confirmed := confirmedTransfers.WithLabelValues("1", "2")
+	unexpected := unexpectedUnlocks.WithLabelValues(cfg.name)
+	confirmedBefore, unexpectedBefore := testutil.ToFloat64(confirmed), testutil.ToFloat64(unexpected)
+	err = watcher.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := testutil.ToFloat64(confirmed) - confirmedBefore; count != 1 {
+		t.Fatalf("expected 1 confirmed transfer, got: %v", count)
+	}
+	if count := testutil.ToFloat64(unexpected) - unexpectedBefore; count != 1 {
+		t.Fatalf("expected 1 unexpected unlock, got: %v", count)
+	}
+	if len(watcher.expected) != 0 {
+		t.Fatalf("expected the confirmed transfer to be removed, %d remain", len(watcher.expected))
+	}
+
+	// Blocks already queried are not handled again
+	err = watcher.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := testutil.ToFloat64(unexpected) - unexpectedBefore; count != 1 {
+		t.Fatalf("expected 1 unexpected unlock, got: %v", count)
+	}
+}
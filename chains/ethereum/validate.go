@@ -0,0 +1,124 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-playground/validator/v10"
+)
+
+// configOpts holds the config values checked with validate tags. The validator only reads exported fields, so
+// these are copied from the ChainConfig before its opts are parsed. Fields are reported by their opt tag.
+type configOpts struct {
+	Endpoint       string `opt:"endpoint" validate:"required"`
+	From           string `opt:"from" validate:"required"`
+	Bridge         string `opt:"bridge" validate:"required_without=DeployMissing,omitempty,eth_addr|ens_name"`
+	Erc20Handler   string `opt:"erc20Handler" validate:"omitempty,eth_addr|ens_name"`
+	Erc721Handler  string `opt:"erc721Handler" validate:"omitempty,eth_addr|ens_name"`
+	GenericHandler string `opt:"genericHandler" validate:"omitempty,eth_addr|ens_name"`
+	FeeHandler     string `opt:"feeHandler" validate:"omitempty,eth_addr|ens_name"`
+	IpfsEndpoint   string `opt:"ipfsEndpoint" validate:"required_if=PinTokenURIs true,omitempty,url"`
+	PinTokenURIs   bool   `opt:"pinTokenURIs"`
+	DeployMissing  bool   `opt:"deployMissing"`
+	SafeAddress    string `opt:"safeAddress" validate:"required_with=SafeTxService,omitempty,eth_addr"`
+	SafeTxService  string `opt:"safeTxServiceURL" validate:"required_with=SafeAddress,omitempty,url"`
+	ENSRegistry    string `opt:"ensRegistry" validate:"omitempty,eth_addr"`
+	Forwarder      string `opt:"forwarderAddress" validate:"excluded_with=SafeAddress FireblocksVaultId,omitempty,eth_addr"`
+
+	FireblocksVaultId   string `opt:"fireblocksVaultId" validate:"excluded_with=SafeAddress DeployMissing"`
+	FireblocksBaseURL   string `opt:"fireblocksBaseURL" validate:"required_with=FireblocksVaultId,omitempty,url"`
+	FireblocksAPIKey    string `opt:"fireblocksAPIKey" validate:"required_with=FireblocksVaultId"`
+	FireblocksSecretKey string `opt:"fireblocksSecretKey" validate:"required_with=FireblocksVaultId"`
+
+	SimulatorBackend  string `opt:"simulatorBackend"`
+	TenderlyProject   string `opt:"tenderlyProjectSlug" validate:"required_if=SimulatorBackend tenderly,omitempty,contains=/"`
+	TenderlyAccessKey string `opt:"tenderlyAccessKey" validate:"required_if=SimulatorBackend tenderly"`
+}
+
+func newConfigOpts(chainCfg *core.ChainConfig) *configOpts {
+	return &configOpts{
+		Endpoint:       chainCfg.Endpoint,
+		From:           chainCfg.From,
+		Bridge:         chainCfg.Opts[BridgeOpt],
+		Erc20Handler:   chainCfg.Opts[Erc20HandlerOpt],
+		Erc721Handler:  chainCfg.Opts[Erc721HandlerOpt],
+		GenericHandler: chainCfg.Opts[GenericHandlerOpt],
+		FeeHandler:     chainCfg.Opts[FeeHandlerOpt],
+		IpfsEndpoint:   chainCfg.Opts[IpfsEndpointOpt],
+		PinTokenURIs:   chainCfg.Opts[PinTokenURIsOpt] == "true",
+		DeployMissing:  chainCfg.Opts[DeployMissingOpt] == "true",
+		SafeAddress:    chainCfg.Opts[SafeAddressOpt],
+		SafeTxService:  chainCfg.Opts[SafeTxServiceURLOpt],
+		ENSRegistry:    chainCfg.Opts[ENSRegistryOpt],
+		Forwarder:      chainCfg.Opts[ForwarderAddressOpt],
+
+		FireblocksVaultId:   chainCfg.Opts[FireblocksVaultIdOpt],
+		FireblocksBaseURL:   chainCfg.Opts[FireblocksBaseURLOpt],
+		FireblocksAPIKey:    chainCfg.Opts[FireblocksAPIKeyOpt],
+		FireblocksSecretKey: chainCfg.Opts[FireblocksSecretOpt],
+
+		SimulatorBackend:  chainCfg.Opts[SimulatorBackendOpt],
+		TenderlyProject:   chainCfg.Opts[TenderlyProjectOpt],
+		TenderlyAccessKey: chainCfg.Opts[TenderlyAccessKeyOpt],
+	}
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("opt")
+	})
+	// Replaces the builtin eth_addr, so addresses are checked the same way they are parsed
+	_ = v.RegisterValidation("eth_addr", func(fl validator.FieldLevel) bool {
+		return common.IsHexAddress(fl.Field().String())
+	})
+	// Contracts may be configured by ENS name, which is resolved when the chain is initialized
+	_ = v.RegisterValidation("ens_name", func(fl validator.FieldLevel) bool {
+		return isENSName(fl.Field().String())
+	})
+	return v
+}
+
+// FieldError describes a config field that failed validation
+type FieldError struct {
+	Field string      // Name of the field or opt
+	Tag   string      // Validation that failed, such as required or eth_addr
+	Value interface{} // The invalid value
+}
+
+// ConfigValidationError is returned by parseChainConfig when any config fields are invalid, listing all of them
+type ConfigValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ConfigValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = fmt.Sprintf("%s failed %s validation (value: %q)", f.Field, f.Tag, fmt.Sprint(f.Value))
+	}
+	return "invalid ethereum config: " + strings.Join(fields, ", ")
+}
+
+// validateConfigOpts checks opts against their validate tags
+func validateConfigOpts(opts *configOpts) error {
+	err := validate.Struct(opts)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fields := make([]FieldError, len(invalid))
+	for i, f := range invalid {
+		fields[i] = FieldError{Field: f.Field(), Tag: f.Tag(), Value: f.Value()}
+	}
+	return &ConfigValidationError{Fields: fields}
+}
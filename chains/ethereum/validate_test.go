@@ -0,0 +1,119 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/core"
+)
+
+const validAddress = "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B"
+
+func TestParseChainConfig_Validation(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		endpoint string
+		from     string
+		opts     map[string]string
+		invalid  []FieldError
+	}{
+		{
+			name:     "valid",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"bridge": validAddress, "erc20Handler": validAddress, "ipfsEndpoint": "http://localhost:5001"},
+		},
+		{
+			name: "required",
+			opts: map[string]string{"bridge": validAddress},
+			invalid: []FieldError{
+				{Field: "endpoint", Tag: "required", Value: ""},
+				{Field: "from", Tag: "required", Value: ""},
+			},
+		},
+		{
+			name:     "required_without",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"deployMissing": "true"},
+		},
+		{
+			name:     "eth_addr",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts: map[string]string{
+				"bridge":         "0x1234",
+				"erc20Handler":   validAddress,
+				"erc721Handler":  "62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B", // Prefix is optional
+				"genericHandler": "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88Z",
+				"feeHandler":     "fee",
+			},
+			invalid: []FieldError{
+				{Field: "bridge", Tag: "eth_addr|ens_name", Value: "0x1234"},
+				{Field: "genericHandler", Tag: "eth_addr|ens_name", Value: "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88Z"},
+				{Field: "feeHandler", Tag: "eth_addr|ens_name", Value: "fee"},
+			},
+		},
+		{
+			name:     "url",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"bridge": validAddress, "ipfsEndpoint": "localhost"},
+			invalid: []FieldError{
+				{Field: "ipfsEndpoint", Tag: "url", Value: "localhost"},
+			},
+		},
+		{
+			name:     "safe",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"bridge": validAddress, "safeAddress": validAddress, "safeTxServiceURL": "https://safe-transaction.gnosis.io"},
+		},
+		{
+			name:     "required_with",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"bridge": validAddress, "safeAddress": validAddress},
+			invalid: []FieldError{
+				{Field: "safeTxServiceURL", Tag: "required_with", Value: ""},
+			},
+		},
+		{
+			name:     "forwarder",
+			endpoint: "endpoint",
+			from:     "alice",
+			opts:     map[string]string{"bridge": validAddress, "forwarderAddress": validAddress, "safeAddress": validAddress, "safeTxServiceURL": "https://safe-transaction.gnosis.io"},
+			invalid: []FieldError{
+				{Field: "forwarderAddress", Tag: "excluded_with", Value: validAddress},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseChainConfig(&core.ChainConfig{
+				Name:     "chain",
+				Id:       1,
+				Endpoint: test.endpoint,
+				From:     test.from,
+				Opts:     test.opts,
+			})
+			if test.invalid == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var validationErr *ConfigValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ConfigValidationError, got: %v", err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, test.invalid) {
+				t.Fatalf("expected invalid fields %+v, got: %+v", test.invalid, validationErr.Fields)
+			}
+		})
+	}
+}
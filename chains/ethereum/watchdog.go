@@ -0,0 +1,63 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var watchdogRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_watchdog_restarts_total",
+	Help: "Number of times the watchdog restarted a stalled listener",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(watchdogRestarts)
+}
+
+// WatchdogTimer calls onTimeout whenever an interval passes without a heartbeat
+type WatchdogTimer struct {
+	interval  time.Duration
+	heartbeat chan struct{}
+	onTimeout func()
+}
+
+func NewWatchdogTimer(interval time.Duration, onTimeout func()) *WatchdogTimer {
+	return &WatchdogTimer{
+		interval:  interval,
+		heartbeat: make(chan struct{}, 1),
+		onTimeout: onTimeout,
+	}
+}
+
+// Heartbeat resets the timer. It never blocks.
+func (w *WatchdogTimer) Heartbeat() {
+	select {
+	case w.heartbeat <- struct{}{}:
+	default:
+	}
+}
+
+// Run waits for heartbeats until stop is closed. The timer restarts after onTimeout returns.
+func (w *WatchdogTimer) Run(stop <-chan int) {
+	timer := time.NewTimer(w.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-w.heartbeat:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(w.interval)
+		case <-timer.C:
+			w.onTimeout()
+			timer.Reset(w.interval)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/blockstore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// blockingConnection never returns from LatestBlock until release is closed, freezing the listener using it
+type blockingConnection struct {
+	Connection
+	release chan struct{}
+}
+
+func (c *blockingConnection) LatestBlock() (*big.Int, error) {
+	<-c.release
+	return c.Connection.LatestBlock()
+}
+
+func TestWatchdogTimer(t *testing.T) {
+	stop := make(chan int)
+	defer close(stop)
+	timeouts := make(chan struct{}, 1)
+	w := NewWatchdogTimer(100*time.Millisecond, func() {
+		timeouts <- struct{}{}
+	})
+	go w.Run(stop)
+
+	// Regular heartbeats prevent a timeout
+	for i := 0; i < 10; i++ {
+		w.Heartbeat()
+		time.Sleep(20 * time.Millisecond)
+	}
+	select {
+	case <-timeouts:
+		t.Fatal("watchdog fired while receiving heartbeats")
+	default:
+	}
+
+	select {
+	case <-timeouts:
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not fire without heartbeats")
+	}
+}
+
+func TestListener_WatchdogRestart(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	cfg := createConfig("alice", nil, contracts)
+	cfg.connectTimeout = DefaultConnectTimeout
+	cfg.watchdogInterval = time.Second
+	// Waiting for new blocks must not trigger the watchdog
+	retryInterval := BlockRetryInterval
+	BlockRetryInterval = 100 * time.Millisecond
+	defer func() { BlockRetryInterval = retryInterval }()
+
+	conn := newLocalConnection(t, cfg)
+	defer conn.Close()
+	latestBlock, err := conn.LatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.startBlock = latestBlock
+
+	crit := make(chan *log15.Record, 1)
+	logger := log15.New("chain", cfg.name)
+	logger.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		if r.Lvl == log15.LvlCrit {
+			select {
+			case crit <- r:
+			default:
+			}
+		}
+		return nil
+	}))
+
+	stop := make(chan int)
+	defer close(stop)
+	errs := make(chan error)
+	frozen := &blockingConnection{Connection: conn, release: make(chan struct{})}
+	defer close(frozen.release)
+	contractsConn, bound, err := connectReadOnly(cfg, TestLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer contractsConn.Close()
+
+	router := &MockRouter{msgs: make(chan msg.Message)}
+	l := NewListener(frozen, cfg, logger, &blockstore.EmptyStore{}, stop, errs, nil)
+	l.setContracts(bound.bridge, bound.erc20Handler, bound.erc721Handler, bound.genericHandler)
+	l.setRouter(router)
+	reconnected := make(chan struct{}, 1)
+	l.setReconnect(func() (Connection, *boundContracts, error) {
+		reconnected <- struct{}{}
+		return connectReadOnly(cfg, TestLogger)
+	})
+	restarts := testutil.ToFloat64(watchdogRestarts.WithLabelValues(cfg.name))
+
+	err = l.start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-crit:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchdog did not fire for frozen listener")
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not reconnect")
+	}
+	if count := testutil.ToFloat64(watchdogRestarts.WithLabelValues(cfg.name)); count != restarts+1 {
+		t.Fatalf("expected %v restarts, got: %v", restarts+1, count)
+	}
+
+	// The restarted listener routes deposits using the new connection
+	erc20Contract := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Contract.Bytes(), 31), uint8(aliceTestConfig.id)))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Contract)
+
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+	createErc20Deposit(t, bound.bridge, client, resourceId, recipient, 1, amount)
+	verifyMessage(t, router, msg.NewFungibleTransfer(aliceTestConfig.id, 1, 1, amount, resourceId, recipient.Bytes()), errs)
+}
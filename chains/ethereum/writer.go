@@ -4,24 +4,68 @@
 package ethereum
 
 import (
+	"errors"
+	"math/big"
+	"sync"
+
 	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
-	"github.com/ChainSafe/chainbridge-utils/core"
+	"github.com/ChainSafe/ChainBridge/bindings/IFeeHandler"
+	"github.com/ChainSafe/ChainBridge/bindings/IPermitHandler"
+	"github.com/ChainSafe/ChainBridge/chains"
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ChainSafe/ChainBridge/feelog"
+	"github.com/ChainSafe/ChainBridge/ipfs"
+	"github.com/ChainSafe/ChainBridge/lock"
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-var _ core.Writer = &writer{}
+var _ chains.SignedWriter = &writer{}
 
 // https://github.com/ChainSafe/chainbridge-solidity/blob/b5ed13d9798feb7c340e737a726dd415b8815366/contracts/Bridge.sol#L20
 var PassedStatus uint8 = 2
 var TransferredStatus uint8 = 3
 var CancelledStatus uint8 = 4
 
+var concurrentProposals = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chainbridge_concurrent_proposals",
+	Help: "Number of proposals the writer is submitting, until their votes are mined",
+}, []string{"chain"})
+
+func init() {
+	prometheus.MustRegister(concurrentProposals)
+}
+
 type writer struct {
 	cfg            Config
 	conn           Connection
-	bridgeContract *Bridge.Bridge // instance of bound receiver bridgeContract
+	bridgeContract *Bridge.Bridge                 // instance of bound receiver bridgeContract
+	feeHandler     *IFeeHandler.IFeeHandler       // optional, fees are collected before execution when set
+	permitHandler  *IPermitHandler.IPermitHandler // executes permit deposits in place of the bridge
+	locker         lock.Locker                    // prevents other relayers executing a proposal at the same time
+	metadataStore  *ipfs.MetadataStore            // optional, resolves generic metadata relayed as IPFS references
+	simulator      connection.Simulator           // optional, finds revert reasons when the node cannot trace transactions
+	proposals      *proposalSlots                 // limits concurrent proposals, nil if messages are resolved one at a time
+	unlocks        *unlockWatcher                 // optional, confirms the delivery of the transfers resolved
+	feeLog         *feelog.Log                    // optional, records the fee of each mined proposal transaction
+	rotation       sync.RWMutex                   // held for reading while a message is resolved, RotateKeypair waits for them
+	router         *router.Router                 // the router the writer is registered with, used to drain it
+	resolving      map[transferKey]msg.Message    // messages being resolved, reported if a drain times out
+	resolvingLock  sync.Mutex                     // guards resolving
+	routines       sync.WaitGroup                 // routines using conn after their message is resolved, see waitIdle
+	ready          chan struct{}                  // closed once the writer is started
+	breaker        *circuitBreaker                // stops transactions being submitted after repeated failures
+	fallback       chains.Writer                  // optional, resolves messages the writer cannot
+	expiry         *big.Int                       // number of blocks proposals are open for, cached once read from the bridge
+	expiryLock     sync.Mutex                     // guards expiry
 	log            log15.Logger
 	stop           <-chan int
 	sysErr         chan<- error // Reports fatal error to core
@@ -30,33 +74,162 @@ type writer struct {
 
 // NewWriter creates and returns writer
 func NewWriter(conn Connection, cfg *Config, log log15.Logger, stop <-chan int, sysErr chan<- error, m *metrics.ChainMetrics) *writer {
-	return &writer{
-		cfg:     *cfg,
-		conn:    conn,
-		log:     log,
-		stop:    stop,
-		sysErr:  sysErr,
-		metrics: m,
+	w := &writer{
+		cfg:       *cfg,
+		conn:      conn,
+		locker:    lock.NewNoopLocker(),
+		resolving: make(map[transferKey]msg.Message),
+		ready:     make(chan struct{}),
+		breaker:   newCircuitBreaker(cfg.circuitBreakerThreshold, cfg.circuitBreakerCooldown),
+		log:       log.New("chain_name", registry.NameOf(cfg.id)),
+		stop:      stop,
+		sysErr:    sysErr,
+		metrics:   m,
 	}
+	w.SetMaxConcurrentProposals(cfg.maxConcurrentProposals)
+	return w
 }
 
 func (w *writer) start() error {
 	w.log.Debug("Starting ethereum writer...")
+	close(w.ready)
 	return nil
 }
 
 // setContract adds the bound receiver bridgeContract to the writer
 func (w *writer) setContract(bridge *Bridge.Bridge) {
 	w.bridgeContract = bridge
+	w.expiryLock.Lock()
+	w.expiry = nil
+	w.expiryLock.Unlock()
+}
+
+// setFeeHandler adds the bound fee handler contract to the writer
+func (w *writer) setFeeHandler(feeHandler *IFeeHandler.IFeeHandler) {
+	w.feeHandler = feeHandler
+}
+
+// setPermitHandler adds the erc20 handler, bound to its permit deposit interface, to the writer
+func (w *writer) setPermitHandler(permitHandler *IPermitHandler.IPermitHandler) {
+	w.permitHandler = permitHandler
+}
+
+// setMetadataStore sets the store used to download generic metadata relayed as IPFS references
+func (w *writer) setMetadataStore(store *ipfs.MetadataStore) {
+	w.metadataStore = store
+}
+
+// setFeeLog sets the log the fee of each mined proposal transaction is appended to
+func (w *writer) setFeeLog(log *feelog.Log) {
+	w.feeLog = log
+}
+
+// setUnlockWatcher sets the watcher confirming the delivery of transfers resolved by the writer
+func (w *writer) setUnlockWatcher(unlocks *unlockWatcher) {
+	w.unlocks = unlocks
+}
+
+// setSimulator sets the simulator used to find the revert reason of reverted transactions
+func (w *writer) setSimulator(simulator connection.Simulator) {
+	w.simulator = simulator
+}
+
+// setLocker replaces the locker used to guard proposal execution
+func (w *writer) setLocker(locker lock.Locker) {
+	w.locker = locker
+}
+
+// SetMaxConcurrentProposals sets how many proposals may be submitted at once. Each holds a slot until its vote is
+// mined, so a relayer that has fallen behind is not limited to one vote per block. If n is 1 or less messages are
+// resolved one at a time, without waiting for votes to be mined. Must be called before the writer is started.
+func (w *writer) SetMaxConcurrentProposals(n int) {
+	if n <= 1 {
+		w.proposals = nil
+		return
+	}
+	w.proposals = newProposalSlots(n, concurrentProposals.WithLabelValues(w.cfg.name))
 }
 
 // ResolveMessage handles any given message based on type
 // A bool is returned to indicate failure/success, this should be ignored except for within tests.
+// If concurrent proposals are enabled the message is resolved in the background once a slot is free, and true is
+// returned unless the writer is stopped first. The message is rejected if requireSignedMessages is set.
 func (w *writer) ResolveMessage(m msg.Message) bool {
+	return w.ResolveSignedMessage(message.SignedMessage{Message: m})
+}
+
+// ResolveSignedMessage verifies the relayer signature of m, if it has one, then resolves it as ResolveMessage does.
+// Messages with an invalid signature are rejected, as are unsigned messages if requireSignedMessages is set.
+func (w *writer) ResolveSignedMessage(m message.SignedMessage) bool {
+	err := w.verifySignature(m)
+	if err != nil {
+		w.log.Error("Rejecting message", "src", m.Source, "nonce", m.DepositNonce, "err", err)
+		return false
+	}
+	return w.resolve(m.Message)
+}
+
+// resolve resolves a message that has passed verifySignature. Messages are passed to the fallback writer while the
+// circuit breaker is open.
+func (w *writer) resolve(m msg.Message) bool {
+	if w.breaker.isOpen() {
+		return w.resolveWithFallback(m, "circuit breaker open")
+	}
+	if w.unlocks != nil {
+		w.unlocks.expect(m)
+	}
+	w.track(m)
+	w.rotation.RLock()
+	if w.proposals != nil {
+		ok := w.proposals.run(w.stop, func() {
+			defer w.untrack(m)
+			defer w.rotation.RUnlock()
+			w.resolveMessage(m)
+		})
+		if !ok {
+			w.rotation.RUnlock()
+			w.untrack(m)
+		}
+		return ok
+	}
+	defer w.untrack(m)
+	defer w.rotation.RUnlock()
+
+	inUse := concurrentProposals.WithLabelValues(w.cfg.name)
+	inUse.Inc()
+	defer inUse.Dec()
+	return w.resolveMessage(m)
+}
+
+// RotateKeypair waits for the messages being resolved to finish, then signs with kp from the next message on.
+// Messages passed to ResolveMessage in the meantime wait for the rotation. Proposals watched for execution
+// are executed with kp.
+func (w *writer) RotateKeypair(kp *secp256k1.Keypair) error {
+	w.rotation.Lock()
+	defer w.rotation.Unlock()
+	return w.rotateKeypair(kp)
+}
+
+// rotateKeypair replaces the keypair of the connection, the rotation lock must be held
+func (w *writer) rotateKeypair(kp *secp256k1.Keypair) error {
+	old := w.conn.Keypair().Address()
+	err := w.conn.RotateKeypair(kp)
+	if err != nil {
+		return err
+	}
+	w.log.Info("Rotated writer keypair", "old", old, "new", kp.Address())
+	return nil
+}
+
+func (w *writer) resolveMessage(m msg.Message) bool {
 	w.log.Info("Attempting to resolve message", "type", m.Type, "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "rId", m.ResourceId.Hex())
 
+	if original, forwarded := message.Unforward(m); forwarded {
+		return w.forwardDeposit(original)
+	}
+
 	switch m.Type {
-	case msg.FungibleTransfer:
+	case msg.FungibleTransfer, PermitFungibleTransfer:
 		return w.createErc20Proposal(m)
 	case msg.NonFungibleTransfer:
 		return w.createErc721Proposal(m)
@@ -67,3 +240,53 @@ func (w *writer) ResolveMessage(m msg.Message) bool {
 		return false
 	}
 }
+
+// txError classifies a failed transaction submission. Nonce and pricing errors are expected when
+// transactions race and are retryable, an unauthorized signer is not.
+func (w *writer) txError(err error) error {
+	var typed error
+	switch {
+	case errors.Is(err, bind.ErrNotAuthorized):
+		typed = bridgeErrors.NewSigningError(bridgeErrors.CodeSignFailed, false, err)
+	case err.Error() == ErrNonceTooLow.Error() || err.Error() == ErrTxUnderpriced.Error():
+		typed = bridgeErrors.NewContractError(bridgeErrors.CodeTxNonce, true, err)
+	default:
+		typed = bridgeErrors.NewContractError(bridgeErrors.CodeTxFailed, true, err)
+	}
+	return bridgeErrors.WithChain(typed, w.cfg.id)
+}
+
+// isNonceError returns true if err is a retryable nonce or pricing error
+func isNonceError(err error) bool {
+	var contractErr *bridgeErrors.ContractError
+	return errors.As(err, &contractErr) && contractErr.Code == bridgeErrors.CodeTxNonce
+}
+
+// proposalSlots limits the number of proposals submitted at once
+type proposalSlots struct {
+	slots chan struct{}
+	inUse prometheus.Gauge // Number of slots in use
+}
+
+func newProposalSlots(n int, inUse prometheus.Gauge) *proposalSlots {
+	return &proposalSlots{slots: make(chan struct{}, n), inUse: inUse}
+}
+
+// run waits for a free slot, then calls f in a new goroutine that holds the slot until f returns. False is
+// returned if stop is closed before a slot is free.
+func (s *proposalSlots) run(stop <-chan int, f func()) bool {
+	select {
+	case s.slots <- struct{}{}:
+	case <-stop:
+		return false
+	}
+	s.inUse.Inc()
+	go func() {
+		defer func() {
+			s.inUse.Dec()
+			<-s.slots
+		}()
+		f()
+	}()
+	return true
+}
@@ -6,27 +6,45 @@ package ethereum
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ChainSafe/ChainBridge/ipfs"
+	"github.com/ChainSafe/ChainBridge/lock"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	log "github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // Number of blocks to wait for an finalization event
 const ExecuteBlockWatchLimit = 100
 
 // Time between retrying a failed tx
-const TxRetryInterval = time.Second * 2
+var TxRetryInterval = time.Second * 2
 
 // Maximum number of tx retries before exiting
 const TxRetryLimit = 10
 
+// Fraction of the estimated gas added to the gas limit of each tx
+const GasLimitBuffer = 0.2
+
+// ReceiptTimeout is the maximum time to wait for a vote or execution to be mined before its receipt is checked
+const ReceiptTimeout = time.Minute * 5
+
 var ErrNonceTooLow = errors.New("nonce too low")
 var ErrTxUnderpriced = errors.New("replacement transaction underpriced")
 var ErrFatalTx = errors.New("submission of transaction failed")
 var ErrFatalQuery = errors.New("query of chain state failed")
+var ErrNoMetadataStore = errors.New("metadata is an ipfs reference but no ipfsEndpoint is configured")
+var ErrFeeReverted = errors.New("fee payment reverted")
 
 // proposalIsComplete returns true if the proposal state is either Passed, Transferred or Cancelled
 func (w *writer) proposalIsComplete(srcId msg.ChainId, nonce msg.Nonce, dataHash [32]byte) bool {
@@ -59,7 +77,7 @@ func (w *writer) proposalIsPassed(srcId msg.ChainId, nonce msg.Nonce, dataHash [
 
 // hasVoted checks if this relayer has already voted
 func (w *writer) hasVoted(srcId msg.ChainId, nonce msg.Nonce, dataHash [32]byte) bool {
-	hasVoted, err := w.bridgeContract.HasVotedOnProposal(w.conn.CallOpts(), utils.IDAndNonce(srcId, nonce), dataHash, w.conn.Opts().From)
+	hasVoted, err := w.bridgeContract.HasVotedOnProposal(w.conn.CallOpts(), utils.IDAndNonce(srcId, nonce), dataHash, w.conn.CallOpts().From)
 	if err != nil {
 		w.log.Error("Failed to check proposal existence", "err", err)
 		return false
@@ -110,7 +128,7 @@ func (w *writer) createErc20Proposal(m msg.Message) bool {
 	}
 
 	// watch for execution event
-	go w.watchThenExecute(m, data, dataHash, latestBlock)
+	w.background(func() { w.watchThenExecute(m, data, dataHash, latestBlock) })
 
 	w.voteProposal(m, dataHash)
 
@@ -125,6 +143,10 @@ func (w *writer) createErc721Proposal(m msg.Message) bool {
 	data := ConstructErc721ProposalData(m.Payload[0].([]byte), m.Payload[1].([]byte), m.Payload[2].([]byte))
 	dataHash := utils.Hash(append(w.cfg.erc721HandlerContract.Bytes(), data...))
 
+	if w.cfg.pinTokenURIs {
+		go w.pinTokenURI(m, m.Payload[2].([]byte))
+	}
+
 	if !w.shouldVote(m, dataHash) {
 		if w.proposalIsPassed(m.Source, m.DepositNonce, dataHash) {
 			// We should not vote for this proposal but it is ready to be executed
@@ -143,19 +165,37 @@ func (w *writer) createErc721Proposal(m msg.Message) bool {
 	}
 
 	// watch for execution event
-	go w.watchThenExecute(m, data, dataHash, latestBlock)
+	w.background(func() { w.watchThenExecute(m, data, dataHash, latestBlock) })
 
 	w.voteProposal(m, dataHash)
 
 	return true
 }
 
+// pinTokenURI pins the metadata of a transferred token to the IPFS node if its URI is an ipfs:// link. The handler
+// sets the metadata as the token's URI when it mints the token, so the metadata must remain available.
+func (w *writer) pinTokenURI(m msg.Message, uri []byte) {
+	if w.metadataStore == nil || !ipfs.IsURI(uri) {
+		return
+	}
+	err := w.metadataStore.Pin(string(uri))
+	if err != nil {
+		w.log.Warn("Unable to pin token URI", "src", m.Source, "nonce", m.DepositNonce, "uri", string(uri), "err", err)
+		return
+	}
+	w.log.Debug("Pinned token URI", "src", m.Source, "nonce", m.DepositNonce, "uri", string(uri))
+}
+
 // createGenericDepositProposal creates a generic proposal
 // returns true if the proposal is complete or is succesfully created
 func (w *writer) createGenericDepositProposal(m msg.Message) bool {
 	w.log.Info("Creating generic proposal", "src", m.Source, "nonce", m.DepositNonce)
 
-	metadata := m.Payload[0].([]byte)
+	metadata, err := w.genericMetadata(m)
+	if err != nil {
+		w.log.Error("Unable to get generic metadata", "src", m.Source, "nonce", m.DepositNonce, "err", err)
+		return false
+	}
 	data := ConstructGenericProposalData(metadata)
 	toHash := append(w.cfg.genericHandlerContract.Bytes(), data...)
 	dataHash := utils.Hash(toHash)
@@ -178,13 +218,38 @@ func (w *writer) createGenericDepositProposal(m msg.Message) bool {
 	}
 
 	// watch for execution event
-	go w.watchThenExecute(m, data, dataHash, latestBlock)
+	w.background(func() { w.watchThenExecute(m, data, dataHash, latestBlock) })
 
 	w.voteProposal(m, dataHash)
 
 	return true
 }
 
+// genericMetadata returns the metadata of a generic transfer, downloading it if it was relayed as an IPFS reference
+// and decompressing it if the listener compressed it
+func (w *writer) genericMetadata(m msg.Message) ([]byte, error) {
+	metadata := m.Payload[0].([]byte)
+	if ipfs.IsReference(metadata) {
+		if w.metadataStore == nil {
+			return nil, ErrNoMetadataStore
+		}
+		var err error
+		metadata, err = w.metadataStore.Get(metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !w.cfg.noCompression && isCompressed(metadata) {
+		decompressed, err := decompressMetadata(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decompress metadata: %w", err)
+		}
+		metadata = decompressed
+	}
+	return metadata, nil
+}
+
 // watchThenExecute watches for the latest block and executes once the matching finalized event is found
 func (w *writer) watchThenExecute(m msg.Message, data []byte, dataHash [32]byte, latestBlock *big.Int) {
 	w.log.Info("Watching for finalization event", "src", m.Source, "nonce", m.DepositNonce)
@@ -213,7 +278,7 @@ func (w *writer) watchThenExecute(m msg.Message, data []byte, dataHash [32]byte,
 
 			// query for logs
 			query := buildQuery(w.cfg.bridgeContract, utils.ProposalEvent, latestBlock, latestBlock)
-			evts, err := w.conn.Client().FilterLogs(context.Background(), query)
+			evts, err := w.conn.Backend().FilterLogs(context.Background(), query)
 			if err != nil {
 				w.log.Error("Failed to fetch logs", "err", err)
 				return
@@ -249,34 +314,66 @@ func (w *writer) voteProposal(m msg.Message, dataHash [32]byte) {
 		case <-w.stop:
 			return
 		default:
+			gasLimit := w.estimateGasLimit(bridgeABI, w.cfg.bridgeContract, nil, "voteProposal", uint8(m.Source), uint64(m.DepositNonce), [32]byte(m.ResourceId), dataHash)
 			err := w.conn.LockAndUpdateOpts()
 			if err != nil {
 				w.log.Error("Failed to update tx opts", "err", err)
+				if !bridgeErrors.IsRetryable(err) {
+					w.sysErr <- ErrFatalTx
+					return
+				}
+				time.Sleep(TxRetryInterval)
 				continue
 			}
-			// These store the gas limit and price before a transaction is sent for logging in case of a failure
+			// This stores the gas price before a transaction is sent for logging in case of a failure
 			// This declaration is necessary as tx will be nil in the case of an error when sending VoteProposal()
 			// We must also declare variables instead of using w.conn.Opts() directly as the opts are currently locked
 			// here but for all the logging after line 272 the w.conn.Opts() is unlocked and could be changed by another process
-			gasLimit := w.conn.Opts().GasLimit
 			gasPrice := w.conn.Opts().GasPrice
 
+			// The opts are shared, so the gas limit must be reset before they are unlocked
+			opts := w.conn.Opts()
+			configuredGasLimit := opts.GasLimit
+			opts.GasLimit = gasLimit
+			pending := w.watchPending()
 			tx, err := w.bridgeContract.VoteProposal(
-				w.conn.Opts(),
+				opts,
 				uint8(m.Source),
 				uint64(m.DepositNonce),
 				m.ResourceId,
 				dataHash,
 			)
+			opts.GasLimit = configuredGasLimit
 			w.conn.UnlockOpts()
 
+			// A vote that never reaches the mempool was dropped by the node, so it is submitted again unless it was
+			// mined after all or the proposal no longer needs the vote
+			if err == nil && !w.confirmPending(pending, tx) {
+				if !w.shouldVote(m, dataHash) {
+					return
+				}
+				continue
+			}
+			pending.close()
+
 			if err == nil {
 				w.log.Info("Submitted proposal vote", "tx", tx.Hash(), "src", m.Source, "depositNonce", m.DepositNonce, "gasPrice", tx.GasPrice().String())
+				w.breaker.success()
 				if w.metrics != nil {
 					w.metrics.VotesSubmitted.Inc()
 				}
+				if w.proposals != nil {
+					// The proposal's slot is held until the vote is mined
+					w.watchReceipt(tx, m)
+				} else {
+					w.background(func() { w.watchReceipt(tx, m) })
+				}
+				return
+			} else if err = w.txError(err); !bridgeErrors.IsRetryable(err) {
+				w.log.Error("Voting failed and cannot be retried", "source", m.Source, "dest", m.Destination, "depositNonce", m.DepositNonce, "err", err)
+				w.sysErr <- ErrFatalTx
 				return
-			} else if err.Error() == ErrNonceTooLow.Error() || err.Error() == ErrTxUnderpriced.Error() {
+			} else if isNonceError(err) {
 				w.log.Debug("Nonce too low, will retry")
 				time.Sleep(TxRetryInterval)
 			} else {
@@ -284,6 +381,9 @@ func (w *writer) voteProposal(m msg.Message, dataHash [32]byte) {
 				time.Sleep(TxRetryInterval)
 			}
 
+			if w.proposalExpired(m, dataHash) {
+				return
+			}
 			// Verify proposal is still open for voting, otherwise no need to retry
 			if w.proposalIsComplete(m.Source, m.DepositNonce, dataHash) {
 				w.log.Info("Proposal voting complete on chain", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce)
@@ -292,39 +392,298 @@ func (w *writer) voteProposal(m msg.Message, dataHash [32]byte) {
 		}
 	}
 	w.log.Error("Submission of Vote transaction failed", "source", m.Source, "dest", m.Destination, "depositNonce", m.DepositNonce)
-	w.sysErr <- ErrFatalTx
+	w.retriesExhausted(m)
+}
+
+// estimateGasLimit estimates the gas used by calling method on contract and adds GasLimitBuffer. If the estimate
+// fails the configured gas limit is returned, so the tx is still sent and any failure handled by its retries.
+func (w *writer) estimateGasLimit(contractABI abi.ABI, contract common.Address, value *big.Int, method string, args ...interface{}) uint64 {
+	calldata, err := contractABI.Pack(method, args...)
+	if err != nil {
+		w.log.Debug("Unable to pack call for gas estimate, using the gas limit", "method", method, "err", err)
+		return w.cfg.gasLimit.Uint64()
+	}
+	gas, err := w.conn.EstimateGasWithBuffer(context.Background(), eth.CallMsg{
+		From:  w.conn.CallOpts().From,
+		To:    &contract,
+		Value: value,
+		Data:  calldata,
+	}, GasLimitBuffer)
+	if err != nil {
+		w.log.Debug("Unable to estimate gas, using the gas limit", "method", method, "gasLimit", w.cfg.gasLimit, "err", err)
+		return w.cfg.gasLimit.Uint64()
+	}
+	return gas
+}
+
+// calculateFee queries the fee handler for the native token fee to execute the message
+func (w *writer) calculateFee(m msg.Message) (*big.Int, error) {
+	fee, err := w.feeHandler.CalculateFee(w.conn.CallOpts(), uint8(m.Source), uint64(m.DepositNonce), m.ResourceId)
+	if err != nil {
+		return nil, bridgeErrors.WithChain(bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, true, err), w.cfg.id)
+	}
+	return fee, nil
+}
+
+// collectFee pays the current fee for the message to the fee handler, and waits for the payment to be mined so the
+// proposal is only executed once the fee has been paid. ErrFeeReverted is returned if the payment reverts.
+func (w *writer) collectFee(m msg.Message) error {
+	fee, err := w.calculateFee(m)
+	if err != nil {
+		return err
+	}
+
+	gasLimit := w.estimateGasLimit(feeHandlerABI, w.cfg.feeHandlerContract, fee, "collectFee", uint8(m.Source), uint64(m.DepositNonce), [32]byte(m.ResourceId))
+	err = w.conn.LockAndUpdateOpts()
+	if err != nil {
+		return err
+	}
+	// The opts are shared, so the value and gas limit must be reset before they are unlocked
+	opts := w.conn.Opts()
+	configuredGasLimit := opts.GasLimit
+	opts.Value = fee
+	opts.GasLimit = gasLimit
+	tx, err := w.feeHandler.CollectFee(opts, uint8(m.Source), uint64(m.DepositNonce), m.ResourceId)
+	opts.Value = big.NewInt(0)
+	opts.GasLimit = configuredGasLimit
+	w.conn.UnlockOpts()
+
+	if err != nil {
+		return w.txError(err)
+	}
+	w.log.Info("Submitted fee payment", "tx", tx.Hash(), "src", m.Source, "nonce", m.DepositNonce, "fee", fee)
+
+	ctx, cancel := w.stopContext(ReceiptTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, w.conn.Backend(), tx)
+	if err != nil {
+		return fmt.Errorf("fee payment %s was not mined: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: %s", ErrFeeReverted, tx.Hash().Hex())
+	}
+	return nil
+}
+
+// lockExecution acquires the execution lock for the proposal. It returns false if another relayer
+// holds the lock. If the lock backend is unavailable the proposal is not executed, unless lockFailOpen
+// is set and execution proceeds without the lock.
+func (w *writer) lockExecution(key lock.Key) (locked bool, proceed bool) {
+	if _, noop := w.locker.(*lock.NoopLocker); noop {
+		return false, true
+	}
+
+	acquired, err := w.locker.TryLock(key)
+	if err != nil && w.cfg.lockFailOpen {
+		w.log.Warn("Failed to acquire execution lock, executing without it", "src", key.Source, "dst", key.Destination, "nonce", key.Nonce, "err", err)
+		return false, true
+	} else if err != nil {
+		w.log.Error("Failed to acquire execution lock, skipping execution", "src", key.Source, "dst", key.Destination, "nonce", key.Nonce, "err", err)
+		return false, false
+	}
+	if !acquired {
+		w.log.Info("Proposal is being executed by another relayer", "src", key.Source, "dst", key.Destination, "nonce", key.Nonce)
+		return false, false
+	}
+	return true, true
+}
+
+// unlockExecution releases the execution lock once tx has been mined, or immediately if no tx was submitted
+func (w *writer) unlockExecution(key lock.Key, m msg.Message, tx *types.Transaction) {
+	unlock := func() {
+		err := w.locker.Unlock(key)
+		if err != nil {
+			w.log.Warn("Failed to release execution lock", "src", key.Source, "dst", key.Destination, "nonce", key.Nonce, "err", err)
+		}
+	}
+
+	if tx == nil {
+		unlock()
+		return
+	}
+
+	w.background(func() {
+		ctx, cancel := w.stopContext(lock.DefaultTTL)
+		defer cancel()
+		w.checkReceipt(ctx, tx, m)
+		unlock()
+	})
+}
+
+// stopContext returns a context that is done after timeout, or once the writer is stopped so routines waiting on it
+// do not outlive the writer
+func (w *writer) stopContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	go func() {
+		select {
+		case <-w.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
 }
 
-// executeProposal executes the proposal
+// watchReceipt records the fee of tx once mined, or logs its revert reason if it fails
+func (w *writer) watchReceipt(tx *types.Transaction, m msg.Message) {
+	ctx, cancel := w.stopContext(ReceiptTimeout)
+	defer cancel()
+	w.checkReceipt(ctx, tx, m)
+}
+
+// checkReceipt waits for tx to be mined. If it succeeded its fee is recorded. If it reverted, the transaction is traced and the revert reason of
+// the top-level call is logged. If the node does not support tracing, the transaction is simulated instead when a
+// simulator is set.
+func (w *writer) checkReceipt(ctx context.Context, tx *types.Transaction, m msg.Message) {
+	receipt, err := bind.WaitMined(ctx, w.conn.Backend(), tx)
+	if err != nil {
+		w.log.Warn("Failed waiting for transaction to be mined", "tx", tx.Hash(), "err", err)
+		return
+	}
+	if receipt.Status != types.ReceiptStatusFailed {
+		w.recordFee(ctx, tx, receipt, m)
+		return
+	}
+
+	var reason string
+	trace, err := w.conn.GetTransactionTrace(ctx, tx.Hash())
+	if errors.Is(err, connection.ErrTraceUnsupported) && w.simulator != nil {
+		reason = w.simulateRevert(tx)
+	} else if errors.Is(err, connection.ErrTraceUnsupported) {
+		w.log.Debug("Node does not support tracing, revert reason is unknown", "tx", tx.Hash())
+	} else if err != nil {
+		w.log.Warn("Unable to trace reverted transaction", "tx", tx.Hash(), "err", err)
+	} else {
+		reason = trace.RevertReason()
+	}
+	w.log.Error("Transaction reverted", "tx", tx.Hash(), "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "reason", reason)
+}
+
+// simulateRevert simulates tx with the writer's simulator and returns its revert reason, or an empty string if
+// the simulation fails
+func (w *writer) simulateRevert(tx *types.Transaction) string {
+	res, err := w.simulator.Simulate(tx)
+	if err != nil {
+		w.log.Warn("Unable to simulate reverted transaction", "tx", tx.Hash(), "err", err)
+		return ""
+	}
+	if res.Success {
+		w.log.Debug("Reverted transaction succeeded in simulation", "tx", tx.Hash(), "gasUsed", res.GasUsed)
+	}
+	return res.RevertReason
+}
+
+// executeProposal executes the proposal, holding the execution lock until the transaction is mined. The execution is
+// held until the relayer's balance can pay for it, before the lock is acquired so other relayers can execute it.
 func (w *writer) executeProposal(m msg.Message, data []byte, dataHash [32]byte) {
+	if !w.waitForBalance(m, data) {
+		return
+	}
+
+	key := lock.Key{Source: m.Source, Destination: m.Destination, Nonce: m.DepositNonce}
+	locked, proceed := w.lockExecution(key)
+	if !proceed {
+		return
+	}
+
+	tx := w.submitExecution(m, data, dataHash)
+	if locked {
+		w.unlockExecution(key, m, tx)
+	} else if tx != nil {
+		w.background(func() { w.watchReceipt(tx, m) })
+	}
+}
+
+// submitExecution pays any fee and submits the execution, retrying until it succeeds or the proposal is
+// finalized. Permit deposits are executed with the erc20 handler's depositWithPermit instead of the bridge, and
+// proposals are executed through the forwarder if forwarderAddress is set.
+// The submitted transaction is returned, or nil if none was submitted.
+func (w *writer) submitExecution(m msg.Message, data []byte, dataHash [32]byte) *types.Transaction {
+	if w.feeHandler != nil {
+		err := w.collectFee(m)
+		if err != nil {
+			w.log.Error("Failed to pay fee, skipping execution", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+			return nil
+		}
+	}
+
+	// Permit deposits are executed by the erc20 handler, which needs the token of the resource ID
+	var token common.Address
+	if m.Type == PermitFungibleTransfer {
+		var err error
+		token, err = w.permitHandler.ResourceIDToTokenContractAddress(w.conn.CallOpts(), m.ResourceId)
+		if err != nil {
+			w.log.Error("Failed to get token for permit deposit, skipping execution", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+			return nil
+		}
+	}
+
 	for i := 0; i < TxRetryLimit; i++ {
 		select {
 		case <-w.stop:
-			return
+			return nil
 		default:
+			var gasLimit uint64
+			if m.Type == PermitFungibleTransfer {
+				permit := messagePermit(m)
+				gasLimit = w.estimateGasLimit(permitABI, w.cfg.erc20HandlerContract, nil, "depositWithPermit", token, new(big.Int).SetBytes(m.Payload[0].([]byte)), common.BytesToAddress(m.Payload[1].([]byte)), permit.Deadline, permit.V, permit.R, permit.S)
+			} else {
+				gasLimit = w.estimateGasLimit(bridgeABI, w.cfg.bridgeContract, nil, "executeProposal", uint8(m.Source), uint64(m.DepositNonce), data, [32]byte(m.ResourceId))
+			}
 			err := w.conn.LockAndUpdateOpts()
 			if err != nil {
 				w.log.Error("Failed to update nonce", "err", err)
-				return
+				if !bridgeErrors.IsRetryable(err) {
+					return nil
+				}
+				time.Sleep(TxRetryInterval)
+				continue
 			}
-			// These store the gas limit and price before a transaction is sent for logging in case of a failure
+			// This stores the gas price before a transaction is sent for logging in case of a failure
 			// This is necessary as tx will be nil in the case of an error when sending VoteProposal()
-			gasLimit := w.conn.Opts().GasLimit
 			gasPrice := w.conn.Opts().GasPrice
 
-			tx, err := w.bridgeContract.ExecuteProposal(
-				w.conn.Opts(),
-				uint8(m.Source),
-				uint64(m.DepositNonce),
-				data,
-				m.ResourceId,
-			)
+			// The opts are shared, so the gas limit must be reset before they are unlocked
+			opts := w.conn.Opts()
+			configuredGasLimit := opts.GasLimit
+			opts.GasLimit = gasLimit
+			pending := w.watchPending()
+			var tx *types.Transaction
+			if m.Type == PermitFungibleTransfer {
+				tx, err = w.depositWithPermit(opts, m, token)
+			} else if w.cfg.forwarderAddress != utils.ZeroAddress {
+				tx, err = w.executeViaForwarder(opts, m, data)
+			} else {
+				tx, err = w.bridgeContract.ExecuteProposal(
+					opts,
+					uint8(m.Source),
+					uint64(m.DepositNonce),
+					data,
+					m.ResourceId,
+				)
+			}
+			opts.GasLimit = configuredGasLimit
 			w.conn.UnlockOpts()
 
+			// An execution dropped by the node is submitted again, unless it was mined after all
+			if err == nil && !w.confirmPending(pending, tx) {
+				if w.proposalIsFinalized(m.Source, m.DepositNonce, dataHash) {
+					w.log.Info("Proposal finalized on chain", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce)
+					return nil
+				}
+				continue
+			}
+			pending.close()
+
 			if err == nil {
 				w.log.Info("Submitted proposal execution", "tx", tx.Hash(), "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "gasPrice", tx.GasPrice().String())
-				return
-			} else if err.Error() == ErrNonceTooLow.Error() || err.Error() == ErrTxUnderpriced.Error() {
+				w.breaker.success()
+				return tx
+			} else if err = w.txError(err); !bridgeErrors.IsRetryable(err) {
+				w.log.Error("Execution failed and cannot be retried", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+				w.sysErr <- ErrFatalTx
+				return nil
+			} else if isNonceError(err) {
 				w.log.Error("Nonce too low, will retry")
 				time.Sleep(TxRetryInterval)
 			} else {
@@ -332,14 +691,18 @@ func (w *writer) executeProposal(m msg.Message, data []byte, dataHash [32]byte)
 				time.Sleep(TxRetryInterval)
 			}
 
+			if w.proposalExpired(m, dataHash) {
+				return nil
+			}
 			// Verify proposal is still open for execution, tx will fail if we aren't the first to execute,
 			// but there is no need to retry
 			if w.proposalIsFinalized(m.Source, m.DepositNonce, dataHash) {
 				w.log.Info("Proposal finalized on chain", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce)
-				return
+				return nil
 			}
 		}
 	}
 	w.log.Error("Submission of Execute transaction failed", "source", m.Source, "dest", m.Destination, "depositNonce", m.DepositNonce)
-	w.sysErr <- ErrFatalTx
+	w.retriesExhausted(m)
+	return nil
 }
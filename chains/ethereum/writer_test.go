@@ -4,20 +4,39 @@
 package ethereum
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/bindings/IFeeHandler"
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ChainSafe/ChainBridge/feelog"
+	"github.com/ChainSafe/ChainBridge/ipfs"
+	ipfstest "github.com/ChainSafe/ChainBridge/ipfs/testing"
+	"github.com/ChainSafe/ChainBridge/lock"
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/ChainBridge/router"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/keystore"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
 	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/goleak"
 )
 
 func createWriters(t *testing.T, client *utils.Client, contracts *utils.DeployedContracts) (*writer, *writer, func(), func(), chan error, chan error) {
@@ -123,6 +142,96 @@ func TestWriter_start_stop(t *testing.T) {
 	close(stop)
 }
 
+func TestWriter_CollectFee(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	fee := big.NewInt(12345)
+	feeHandlerAddr := ethtest.DeployMockFeeHandler(t, client, fee)
+
+	cfg := createConfig("bob", ethtest.GetLatestBlock(t, client), contracts)
+	cfg.feeHandlerContract = feeHandlerAddr
+	errs := make(chan error)
+	writer, stop := createTestWriter(t, cfg, errs)
+	defer stop()
+
+	feeHandler, err := IFeeHandler.NewIFeeHandler(feeHandlerAddr, writer.conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setFeeHandler(feeHandler)
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+
+	calculated, err := writer.calculateFee(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calculated.Cmp(fee) != 0 {
+		t.Fatalf("unexpected fee. Expected: %s Got: %s", fee, calculated)
+	}
+
+	err = writer.collectFee(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock fee handler stores the value it received in slot 0
+	for i := 0; i < 10; i++ {
+		stored, err := client.Client.StorageAt(context.Background(), feeHandlerAddr, common.Hash{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if new(big.Int).SetBytes(stored).Cmp(fee) == 0 {
+			break
+		}
+		if i == 9 {
+			t.Fatalf("fee handler did not receive fee. Expected: %s Got: %s", fee, new(big.Int).SetBytes(stored))
+		}
+		time.Sleep(time.Second)
+	}
+
+	balance, err := client.Client.BalanceAt(context.Background(), feeHandlerAddr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(fee) != 0 {
+		t.Fatalf("unexpected fee handler balance. Expected: %s Got: %s", fee, balance)
+	}
+
+	// Opts must not carry the fee into later transactions
+	if writer.conn.Opts().Value.Sign() != 0 {
+		t.Fatalf("expected opts value to be reset, got: %s", writer.conn.Opts().Value)
+	}
+	if writer.conn.Opts().GasLimit != cfg.gasLimit.Uint64() {
+		t.Fatalf("expected opts gas limit to be reset, got: %d", writer.conn.Opts().GasLimit)
+	}
+}
+
+func TestWriter_EstimateGasLimit(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	errs := make(chan error)
+	writer, stop := createTestWriter(t, createConfig("alice", nil, contracts), errs)
+	defer stop()
+
+	// Votes are only accepted for resources registered with a handler
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), resourceId, []byte{})
+	gas := writer.estimateGasLimit(bridgeABI, contracts.BridgeAddress, nil, "voteProposal", uint8(m.Source), uint64(m.DepositNonce), [32]byte(m.ResourceId), [32]byte{1})
+	if gas == 0 || gas >= writer.cfg.gasLimit.Uint64() {
+		t.Fatalf("expected an estimate below the gas limit of %s, got: %d", writer.cfg.gasLimit, gas)
+	}
+
+	// Falls back to the gas limit if the call cannot be estimated
+	gas = writer.estimateGasLimit(bridgeABI, contracts.BridgeAddress, nil, "executeProposal", uint8(m.Source), uint64(m.DepositNonce), []byte{}, [32]byte(m.ResourceId))
+	if gas != writer.cfg.gasLimit.Uint64() {
+		t.Fatalf("expected the gas limit %s for a reverting call, got: %d", writer.cfg.gasLimit, gas)
+	}
+}
+
 func TestCreateAndExecuteErc20DepositProposal(t *testing.T) {
 	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
 	contracts := deployTestContracts(t, client, TestChainId)
@@ -150,6 +259,141 @@ func TestCreateAndExecuteErc20DepositProposal(t *testing.T) {
 	ethtest.Erc20AssertBalance(t, client, amount, erc20Address, recipient)
 }
 
+func TestCreateAndExecuteErc20DepositProposal_ExecutionLock(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	writerA, writerB, stopA, stopB, errA, errB := createWriters(t, client, contracts)
+
+	defer stopA()
+	defer stopB()
+	defer writerA.conn.Close()
+	defer writerB.conn.Close()
+
+	// Both writers share a lock directory, as relayers on the same host would
+	lockDir, err := ioutil.TempDir(os.TempDir(), "locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lockDir)
+	for _, w := range []*writer{writerA, writerB} {
+		locker, err := lock.NewFileLocker(lockDir, lock.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.setLocker(locker)
+	}
+
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	ethtest.FundErc20Handler(t, client, contracts.ERC20HandlerAddress, erc20Address, big.NewInt(100))
+
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	amount := big.NewInt(10)
+	m := msg.NewFungibleTransfer(1, 0, 0, amount, resourceId, recipient.Bytes())
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	routeMessageAndWait(t, client, writerA, writerB, m, errA, errB)
+
+	ethtest.Erc20AssertBalance(t, client, amount, erc20Address, recipient)
+
+	// The lock is released once the execution has been mined
+	for i := 0; i < 10; i++ {
+		files, err := ioutil.ReadDir(lockDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 0 {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+	t.Fatal("execution lock was not released")
+}
+
+func TestWriter_ExecutionLockHeld(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	cfg := createConfig("bob", ethtest.GetLatestBlock(t, client), contracts)
+	errs := make(chan error, 1)
+	writer, stop := createTestWriter(t, cfg, errs)
+	defer stop()
+	defer writer.conn.Close()
+
+	lockDir, err := ioutil.TempDir(os.TempDir(), "locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lockDir)
+	locker, err := lock.NewFileLocker(lockDir, lock.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setLocker(locker)
+
+	// Another relayer is already executing the proposal
+	other, err := lock.NewFileLocker(lockDir, lock.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	acquired, err := other.TryLock(lock.Key{Source: m.Source, Destination: m.Destination, Nonce: m.DepositNonce})
+	if err != nil || !acquired {
+		t.Fatalf("failed to acquire lock, err: %v", err)
+	}
+
+	from := writer.conn.Keypair().CommonAddress()
+	before, err := client.Client.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer.executeProposal(m, []byte{}, [32]byte{})
+
+	after, err := client.Client.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Fatalf("writer submitted a transaction while the lock was held, nonce %d -> %d", before, after)
+	}
+	select {
+	case err = <-errs:
+		t.Fatalf("unexpected error: %v", err)
+	default:
+	}
+}
+
+func TestWriter_ExecutionLockUnavailable(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("lock", big.NewInt(0), nil)
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+
+	// Nothing listens on the port, so the lock cannot be acquired
+	locker, err := lock.NewRedisLocker("127.0.0.1:1", lock.RedisOptions{}, lock.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setLocker(locker)
+	key := lock.Key{Source: 1, Destination: cfg.id, Nonce: 1}
+
+	locked, proceed := writer.lockExecution(key)
+	if locked || proceed {
+		t.Fatalf("expected execution to be skipped, locked: %t proceed: %t", locked, proceed)
+	}
+
+	writer.cfg.lockFailOpen = true
+	locked, proceed = writer.lockExecution(key)
+	if locked || !proceed {
+		t.Fatalf("expected execution without the lock, locked: %t proceed: %t", locked, proceed)
+	}
+}
+
 func TestCreateAndExecuteErc721Proposal(t *testing.T) {
 	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
 	contracts := deployTestContracts(t, client, TestChainId)
@@ -221,6 +465,87 @@ func TestCreateAndExecuteGenericProposal(t *testing.T) {
 	ethtest.AssertHashExistence(t, client, hash, assetStoreAddr)
 }
 
+func TestCreateAndExecuteGenericProposal_IpfsMetadata(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, aliceTestConfig.id)
+	server := ipfstest.NewServer(t)
+	store := ipfs.NewMetadataStore(server.URL)
+
+	assetStoreAddr, err := utils.DeployAssetStore(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rId := msg.ResourceIdFromSlice(common.LeftPadBytes(assetStoreAddr.Bytes(), 32))
+	// No deposit function is called, the asset store only stores the hash on execution
+	executeSig := utils.CreateFunctionSignature("store(bytes32)")
+	ethtest.RegisterGenericResource(t, client, contracts.BridgeAddress, contracts.GenericHandlerAddress, rId, assetStoreAddr, [4]byte{}, executeSig)
+
+	// The 32 byte metadata is over the limit, so the listener uploads it
+	cfg := *aliceTestConfig
+	cfg.maxOnChainBytes = 16
+	stop := make(chan int)
+	defer close(stop)
+	errs := make(chan error)
+	l, router := createTestListener(t, &cfg, contracts, stop, errs)
+	defer l.conn.Close()
+	l.setMetadataStore(store)
+
+	hash := common.HexToHash("0x3c6e7a9c3cc1f82c2aa3a1d8aa1827bc1d69b2ab0b235b2c3ec8c2568e1c4a3f")
+	createGenericDeposit(t, l.bridgeContract, client, rId, 1, hash.Bytes())
+
+	var m msg.Message
+	select {
+	case m = <-router.msgs:
+	case err = <-errs:
+		t.Fatal(err)
+	case <-time.After(TestTimeout):
+		t.Fatal("test timed out")
+	}
+	if !ipfs.IsReference(m.Payload[0].([]byte)) {
+		t.Fatalf("expected metadata to be relayed as an ipfs reference, got: %x", m.Payload[0])
+	}
+	if server.Files() != 1 {
+		t.Fatalf("expected metadata to be added to ipfs, got %d files", server.Files())
+	}
+
+	// A writer without a metadata store cannot resolve the reference
+	writerA, writerB, stopA, stopB, errA, errB := createWriters(t, client, contracts)
+	defer stopA()
+	defer stopB()
+	defer writerA.conn.Close()
+	defer writerB.conn.Close()
+	_, err = writerA.genericMetadata(m)
+	if !errors.Is(err, ErrNoMetadataStore) {
+		t.Fatalf("expected ErrNoMetadataStore, got: %v", err)
+	}
+
+	writerA.setMetadataStore(store)
+	writerB.setMetadataStore(store)
+	routeMessageAndWait(t, client, writerA, writerB, m, errA, errB)
+
+	ethtest.AssertHashExistence(t, client, hash, assetStoreAddr)
+}
+
+func TestWriter_GenericMetadataIpfsURI(t *testing.T) {
+	cfg := createConfig("alice", nil, nil)
+	w := NewWriter(nil, cfg, TestLogger, nil, nil, nil)
+
+	// Metadata that is an ipfs:// URI was not uploaded by the listener, so it is relayed as is
+	uri := []byte("ipfs://QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG/1.json")
+	metadata, err := w.genericMetadata(msg.NewGenericTransfer(1, 0, 1, msg.ResourceId{}, uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(metadata, uri) {
+		t.Fatalf("expected the URI to be unchanged, got: %s", metadata)
+	}
+
+	_, err = w.genericMetadata(msg.NewGenericTransfer(1, 0, 1, msg.ResourceId{}, ipfs.Reference("QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG")))
+	if !errors.Is(err, ErrNoMetadataStore) {
+		t.Fatalf("expected ErrNoMetadataStore, got: %v", err)
+	}
+}
+
 func TestDuplicateMessage(t *testing.T) {
 	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
 	contracts := deployTestContracts(t, client, TestChainId)
@@ -254,11 +579,11 @@ func TestDuplicateMessage(t *testing.T) {
 	ethtest.Erc20AssertBalance(t, client, amount, erc20Address, recipient)
 
 	// Capture nonces
-	nonceAPre, err := writerA.conn.Client().PendingNonceAt(context.Background(), writerA.conn.Keypair().CommonAddress())
+	nonceAPre, err := writerA.conn.Backend().PendingNonceAt(context.Background(), writerA.conn.Keypair().CommonAddress())
 	if err != nil {
 		t.Fatal(err)
 	}
-	nonceBPre, err := writerA.conn.Client().PendingNonceAt(context.Background(), writerB.conn.Keypair().CommonAddress())
+	nonceBPre, err := writerA.conn.Backend().PendingNonceAt(context.Background(), writerB.conn.Keypair().CommonAddress())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -280,11 +605,11 @@ func TestDuplicateMessage(t *testing.T) {
 	}
 
 	// Capture new nonces
-	nonceAPost, err := writerA.conn.Client().PendingNonceAt(context.Background(), writerA.conn.Keypair().CommonAddress())
+	nonceAPost, err := writerA.conn.Backend().PendingNonceAt(context.Background(), writerA.conn.Keypair().CommonAddress())
 	if err != nil {
 		t.Fatal(err)
 	}
-	nonceBPost, err := writerA.conn.Client().PendingNonceAt(context.Background(), writerB.conn.Keypair().CommonAddress())
+	nonceBPost, err := writerA.conn.Backend().PendingNonceAt(context.Background(), writerB.conn.Keypair().CommonAddress())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -298,3 +623,884 @@ func TestDuplicateMessage(t *testing.T) {
 	}
 
 }
+
+func TestWriter_CheckReceipt(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	errs := make(chan error)
+	writer, stop := createTestWriter(t, createConfig("alice", nil, contracts), errs)
+	defer stop()
+
+	var errorLogs []*log15.Record
+	writer.log = log15.New()
+	writer.log.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		if r.Lvl == log15.LvlError {
+			errorLogs = append(errorLogs, r)
+		}
+		return nil
+	}))
+
+	// Executing a proposal that does not exist reverts, the gas limit is set so it is not estimated
+	bridgeInstance, err := Bridge.NewBridge(contracts.BridgeAddress, client.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = utils.UpdateNonce(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := bridgeInstance.ExecuteProposal(client.Opts, 1, 1, []byte{}, [32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	writer.checkReceipt(ctx, tx, m)
+
+	if len(errorLogs) != 1 || errorLogs[0].Msg != "Transaction reverted" {
+		t.Fatalf("expected a revert to be logged, got: %v", errorLogs)
+	}
+	logCtx := errorLogs[0].Ctx
+	if reason := logCtx[len(logCtx)-1]; logCtx[len(logCtx)-2] != "reason" || reason == "" {
+		t.Fatalf("expected a revert reason, got: %v", logCtx)
+	}
+}
+
+func TestWriter_executeProposal_revert(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	writerA, writerB, stopA, stopB, errA, errB := createWriters(t, client, contracts)
+
+	defer stopA()
+	defer stopB()
+	defer writerA.conn.Close()
+	defer writerB.conn.Close()
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	ethtest.FundErc20Handler(t, client, contracts.ERC20HandlerAddress, erc20Address, big.NewInt(100))
+
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	recipient := ethcrypto.PubkeyToAddress(BobKp.PrivateKey().PublicKey)
+	m := msg.NewFungibleTransfer(1, 0, 0, big.NewInt(10), resourceId, recipient.Bytes())
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	routeMessageAndWait(t, client, writerA, writerB, m, errA, errB)
+
+	// A new writer, so no logs of the first execution are captured
+	errs := make(chan error, 1)
+	writer, stop := createTestWriter(t, createConfig("bob", nil, contracts), errs)
+	defer stop()
+	defer writer.conn.Close()
+
+	records := make(chan *log15.Record, 10)
+	writer.log = log15.New()
+	writer.log.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		if r.Lvl <= log15.LvlInfo {
+			records <- r
+		}
+		return nil
+	}))
+
+	// Executing the same nonce again reverts, as the proposal has already been executed
+	data := ConstructErc20ProposalData(m.Payload[0].([]byte), m.Payload[1].([]byte))
+	dataHash := utils.Hash(append(writer.cfg.erc20HandlerContract.Bytes(), data...))
+	writer.executeProposal(m, data, dataHash)
+
+	submitted := 0
+	for {
+		select {
+		case r := <-records:
+			switch r.Msg {
+			case "Submitted proposal execution":
+				submitted++
+			case "Transaction reverted":
+				if reason := r.Ctx[len(r.Ctx)-1]; r.Ctx[len(r.Ctx)-2] != "reason" || reason == "" {
+					t.Fatalf("expected a revert reason, got: %v", r.Ctx)
+				}
+				if submitted != 1 {
+					t.Fatalf("expected a single execution to be submitted, got %d", submitted)
+				}
+
+				// The revert is terminal, the execution is not retried
+				select {
+				case r := <-records:
+					t.Fatalf("unexpected log after the revert: %s %v", r.Msg, r.Ctx)
+				case err := <-errs:
+					t.Fatalf("unexpected error after the revert: %s", err)
+				case <-time.After(2 * TxRetryInterval):
+				}
+				return
+			default:
+				t.Fatalf("unexpected log: %s %v", r.Msg, r.Ctx)
+			}
+		case err := <-errs:
+			t.Fatalf("Fatal error: %s", err)
+		case <-time.After(TestTimeout):
+			t.Fatal("test timed out")
+		}
+	}
+}
+
+func TestProposalSlots(t *testing.T) {
+	inUse := concurrentProposals.WithLabelValues("slots")
+	slots := newProposalSlots(2, inUse)
+	stop := make(chan int)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		if !slots.run(stop, func() {
+			started <- struct{}{}
+			<-release
+		}) {
+			t.Fatal("expected a free slot")
+		}
+		<-started
+	}
+	if count := testutil.ToFloat64(inUse); count != 2 {
+		t.Fatalf("expected 2 slots in use, got %v", count)
+	}
+
+	// No slot is free, so run waits until stopped
+	close(stop)
+	if slots.run(stop, func() {}) {
+		t.Fatal("expected run to stop waiting for a slot")
+	}
+
+	close(release)
+	for deadline := time.Now().Add(time.Second); testutil.ToFloat64(inUse) != 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected slots to be released, %v in use", testutil.ToFloat64(inUse))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// BenchmarkProposalSlots submits proposals that each hold their slot for a simulated block time, as a vote does
+// until it is mined. With 5 slots each operation takes about a fifth of the time it takes with 1.
+func BenchmarkProposalSlots(b *testing.B) {
+	const blockTime = 10 * time.Millisecond
+	for _, n := range []int{1, 5} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			slots := newProposalSlots(n, concurrentProposals.WithLabelValues("benchmark"))
+			stop := make(chan int)
+			wg := new(sync.WaitGroup)
+			for i := 0; i < b.N; i++ {
+				wg.Add(1)
+				slots.run(stop, func() {
+					defer wg.Done()
+					time.Sleep(blockTime)
+				})
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestWriter_GasConfigReload(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	dir, err := ioutil.TempDir(os.TempDir(), "gas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gas.json")
+	err = ioutil.WriteFile(path, []byte(`{"gasPrice": 1000000000}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := createConfig("bob", ethtest.GetLatestBlock(t, client), contracts)
+	stop := make(chan int)
+	defer close(stop)
+	gas, err := connection.NewGasConfigWatcher(path, TestLogger, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas.Start()
+	conn := connection.NewConnection(TestEndpoint, false, BobKp, TestLogger, big.NewInt(DefaultGasLimit), big.NewInt(DefaultGasPrice), big.NewInt(DefaultMinGasPrice), big.NewFloat(DefaultGasMultiplier), "", "")
+	conn.SetGasConfigWatcher(gas)
+	err = conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	errs := make(chan error)
+	writer := NewWriter(conn, cfg, TestLogger, stop, errs, nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+	err = writer.start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := eth.FilterQuery{
+		Addresses: []common.Address{contracts.BridgeAddress},
+		Topics:    [][]common.Hash{{utils.ProposalVote.GetTopic()}},
+	}
+	votes := make(chan ethtypes.Log)
+	sub, err := client.Client.SubscribeFilterLogs(context.Background(), query, votes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// voteGasPrice resolves a message and returns the gas price of the vote the writer submits for it
+	voteGasPrice := func(nonce msg.Nonce) *big.Int {
+		m := msg.NewFungibleTransfer(1, TestChainId, nonce, big.NewInt(10), resourceId, BobKp.CommonAddress().Bytes())
+		if ok := writer.ResolveMessage(m); !ok {
+			t.Fatal("writer failed to resolve the message")
+		}
+		for {
+			select {
+			case vote := <-votes:
+				if vote.Topics[2].Big().Uint64() != uint64(nonce) {
+					continue
+				}
+				tx, _, err := client.Client.TransactionByHash(context.Background(), vote.TxHash)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return tx.GasPrice()
+			case err := <-sub.Err():
+				t.Fatal(err)
+			case err := <-errs:
+				t.Fatalf("Fatal error: %s", err)
+			case <-time.After(TestTimeout):
+				t.Fatal("test timed out")
+			}
+		}
+	}
+
+	if price := voteGasPrice(1); price.Cmp(big.NewInt(1000000000)) != 0 {
+		t.Fatalf("expected a gas price of 1 gwei, got: %s", price)
+	}
+
+	err = ioutil.WriteFile(path, []byte(`{"gasPrice": 2000000000}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for gas.Load().GasPrice.Cmp(big.NewInt(2000000000)) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("gas config was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if price := voteGasPrice(2); price.Cmp(big.NewInt(2000000000)) != 0 {
+		t.Fatalf("expected a gas price of 2 gwei, got: %s", price)
+	}
+}
+
+func TestWriter_RotateKeypair(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts := deployTestContracts(t, client, TestChainId)
+	erc20Address := ethtest.DeployMintApproveErc20(t, client, contracts.ERC20HandlerAddress, big.NewInt(100))
+	resourceId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20Address.Bytes(), 31), 0))
+	ethtest.RegisterResource(t, client, contracts.BridgeAddress, contracts.ERC20HandlerAddress, resourceId, erc20Address)
+
+	// Concurrent proposals are resolved in the background, so the first is still in flight when rotating
+	cfg := createConfig("bob", ethtest.GetLatestBlock(t, client), contracts)
+	cfg.maxConcurrentProposals = 2
+	writer, stop := createTestWriter(t, cfg, make(chan error))
+	defer stop()
+	defer writer.conn.Close()
+
+	charlieKp := keystore.TestKeyRing.EthereumKeys[keystore.CharlieKey]
+	recipient := BobKp.CommonAddress().Bytes()
+	hasVoted := func(nonce msg.Nonce, relayer common.Address) bool {
+		data := ConstructErc20ProposalData(big.NewInt(10).Bytes(), recipient)
+		dataHash := utils.Hash(append(contracts.ERC20HandlerAddress.Bytes(), data...))
+		voted, err := writer.bridgeContract.HasVotedOnProposal(client.CallOpts, utils.IDAndNonce(1, nonce), dataHash, relayer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return voted
+	}
+
+	if ok := writer.ResolveMessage(msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), resourceId, recipient)); !ok {
+		t.Fatal("writer failed to resolve the message")
+	}
+	err := writer.RotateKeypair(charlieKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasVoted(1, BobKp.CommonAddress()) || hasVoted(1, charlieKp.CommonAddress()) {
+		t.Fatal("expected the proposal in flight when rotating to be voted on with the old key")
+	}
+
+	if ok := writer.ResolveMessage(msg.NewFungibleTransfer(1, TestChainId, 2, big.NewInt(10), resourceId, recipient)); !ok {
+		t.Fatal("writer failed to resolve the message")
+	}
+	deadline := time.Now().Add(TestTimeout)
+	for !hasVoted(2, charlieKp.CommonAddress()) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the proposal after rotating to be voted on with the new key")
+		}
+		time.Sleep(time.Second)
+	}
+	if hasVoted(2, BobKp.CommonAddress()) {
+		t.Fatal("expected the proposal after rotating not to be voted on with the old key")
+	}
+}
+
+func TestWriter_voteProposal_MockConnection(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("bob", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 0
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	writer.voteProposal(m, [32]byte{1})
+
+	sent := backend.transactions()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 transaction, got: %d", len(sent))
+	}
+	if *sent[0].To() != cfg.bridgeContract {
+		t.Fatalf("expected the vote to be sent to %s, got: %s", cfg.bridgeContract.Hex(), sent[0].To().Hex())
+	}
+	if !bytes.HasPrefix(sent[0].Data(), bridgeABI.Methods["voteProposal"].ID) {
+		t.Fatal("expected the transaction to call voteProposal")
+	}
+	if backend.called("EstimateGas") != 1 {
+		t.Fatalf("expected the gas to be estimated once, got: %d", backend.called("EstimateGas"))
+	}
+}
+
+func TestWriter_voteProposal_MockConnectionError(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.err = errors.New("connection lost")
+	cfg := createConfig("bob", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 0
+	stop := make(chan int)
+	defer close(stop)
+	sysErr := make(chan error, 1)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, sysErr, nil)
+
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	interval := TxRetryInterval
+	TxRetryInterval = 10 * time.Millisecond
+	defer func() { TxRetryInterval = interval }()
+
+	// Failing to update the opts is retried without sending anything, then reported as fatal
+	start := time.Now()
+	writer.voteProposal(msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{}), [32]byte{1})
+	if elapsed := time.Since(start); elapsed < TxRetryLimit*TxRetryInterval {
+		t.Fatalf("expected each retry to wait %s, all took %s", TxRetryInterval, elapsed)
+	}
+	select {
+	case err := <-sysErr:
+		if err != ErrFatalTx {
+			t.Fatalf("expected %v, got: %v", ErrFatalTx, err)
+		}
+	default:
+		t.Fatal("expected a fatal error")
+	}
+	if backend.called("SuggestGasPrice") != TxRetryLimit {
+		t.Fatalf("expected the opts to be updated %d times, got: %d", TxRetryLimit, backend.called("SuggestGasPrice"))
+	}
+	if len(backend.transactions()) != 0 {
+		t.Fatal("expected no transactions to be sent")
+	}
+}
+
+func TestWriter_voteProposal_DroppedThenMined(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.dropPending = true
+	cfg := createConfig("bob", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 10 * time.Millisecond
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	// The vote is never announced, but is mined late
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		if bytes.HasPrefix(call.Data, bridgeABI.Methods["getProposal"].ID) {
+			return bridgeABI.Methods["getProposal"].Outputs.Pack(Bridge.BridgeProposal{Status: 1, ProposedBlock: big.NewInt(1)})
+		}
+		return bridgeABI.Methods["_hasVotedOnProposal"].Outputs.Pack(len(backend.transactions()) != 0)
+	}
+
+	m := msg.NewFungibleTransfer(1, TestChainId, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	writer.voteProposal(m, [32]byte{1})
+
+	if sent := backend.transactions(); len(sent) != 1 {
+		t.Fatalf("expected the mined vote not to be submitted again, got %d transactions", len(sent))
+	}
+}
+
+func TestWriter_concurrent_proposals(t *testing.T) {
+	const goroutines = 20
+	const proposalsEach = 5
+
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("bob", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.mempoolConfirmTimeout = 0
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, goroutines*proposalsEach), nil)
+
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var panics []interface{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					lock.Lock()
+					panics = append(panics, r)
+					lock.Unlock()
+				}
+			}()
+			for j := 0; j < proposalsEach; j++ {
+				nonce := msg.Nonce(i*proposalsEach + j + 1)
+				m := msg.NewFungibleTransfer(1, TestChainId, nonce, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+				writer.voteProposal(m, [32]byte{byte(i), byte(j)})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(panics) != 0 {
+		t.Fatalf("expected no goroutine to panic, got: %v", panics)
+	}
+	sent := backend.transactions()
+	if len(sent) != goroutines*proposalsEach {
+		t.Fatalf("expected %d transactions, got: %d", goroutines*proposalsEach, len(sent))
+	}
+	nonces := make(map[uint64]common.Hash)
+	for _, tx := range sent {
+		if other, ok := nonces[tx.Nonce()]; ok {
+			t.Fatalf("nonce %d used by transactions %s and %s", tx.Nonce(), other.Hex(), tx.Hash().Hex())
+		}
+		nonces[tx.Nonce()] = tx.Hash()
+	}
+}
+
+func TestWriter_RecordFee(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir(os.TempDir(), "feelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fees.jsonl")
+	feeLog, err := feelog.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feeLog.Close()
+
+	cfg := createConfig("fees", big.NewInt(0), nil)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), make(chan int), make(chan error, 1), nil)
+	writer.setFeeLog(feeLog)
+
+	data, err := bridgeABI.Pack("voteProposal", uint8(1), uint64(3), [32]byte{1}, [32]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := conn.Opts().Signer(conn.Opts().From, ethtypes.NewTransaction(0, cfg.bridgeContract, big.NewInt(0), 100000, big.NewInt(2e9), data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.SendTransaction(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := msg.NewFungibleTransfer(1, 0, 3, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{})
+	writer.checkReceipt(context.Background(), tx, m)
+
+	records, err := feelog.Read(path, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 fee record, got: %d", len(records))
+	}
+	r := records[0]
+	// The mock mines transactions using all their gas
+	if r.Method != "voteProposal" || r.DepositNonce != 3 || r.Source != 1 || r.ResourceId != m.ResourceId.Hex() || r.GasUsed != 100000 || r.TxHash != tx.Hash() {
+		t.Fatalf("unexpected fee record: %+v", r)
+	}
+	if r.TxFeeWei.Cmp(big.NewInt(2e14)) != 0 {
+		t.Fatalf("expected a fee of 2e14 wei, got: %s", r.TxFeeWei)
+	}
+	if count := testutil.CollectAndCount(proposalFees); count == 0 {
+		t.Fatal("expected the fee to be observed")
+	}
+	// Only executions are reported by resource ID
+	if count := testutil.CollectAndCount(resourceGas); count != 0 {
+		t.Fatalf("expected no gas to be reported by resource ID, got: %d", count)
+	}
+
+	data, err = bridgeABI.Pack("executeProposal", uint8(1), uint64(3), []byte{}, [32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = conn.Opts().Signer(conn.Opts().From, ethtypes.NewTransaction(1, cfg.bridgeContract, big.NewInt(0), 200000, big.NewInt(2e9), data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.SendTransaction(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.checkReceipt(context.Background(), tx, m)
+	if count := testutil.CollectAndCount(resourceGas); count != 1 {
+		t.Fatalf("expected the execution's gas to be reported by resource ID, got: %d", count)
+	}
+}
+
+func TestWriter_DrainAndShutdown(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every proposal is already transferred, so each message is resolved without sending a transaction
+	backend.call = func(eth.CallMsg) ([]byte, error) {
+		return bridgeABI.Methods["getProposal"].Outputs.Pack(Bridge.BridgeProposal{Status: TransferredStatus, ProposedBlock: big.NewInt(1)})
+	}
+	cfg := createConfig("drain", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	r := router.NewRouterWithConfig(newTestLogger("router"), router.QueueConfig{})
+	r.Listen(cfg.id, writer)
+	writer.setRouter(r)
+	for i := 1; i <= 10; i++ {
+		err = r.Send(msg.NewFungibleTransfer(1, cfg.id, msg.Nonce(i), big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = writer.DrainAndShutdown(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected every message to be resolved, got: %v", err)
+	}
+	if pending := r.Pending(cfg.id); pending != 0 {
+		t.Fatalf("expected no pending messages, got: %d", pending)
+	}
+	if backend.called("CallContract") < 10 {
+		t.Fatalf("expected each proposal to be checked, got %d calls", backend.called("CallContract"))
+	}
+	if len(backend.transactions()) != 0 {
+		t.Fatal("expected no transactions to be sent")
+	}
+	err = r.Send(msg.NewFungibleTransfer(1, cfg.id, 11, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes()))
+	if err == nil {
+		t.Fatal("expected the drained writer to be unregistered")
+	}
+}
+
+func TestWriter_CollectFeeReverted(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.call = func(eth.CallMsg) ([]byte, error) {
+		return feeHandlerABI.Methods["calculateFee"].Outputs.Pack(big.NewInt(10))
+	}
+	cfg := createConfig("fee", big.NewInt(0), nil)
+	cfg.feeHandlerContract = common.HexToAddress("0x0000000000000000000000000000000000001234")
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	feeHandler, err := IFeeHandler.NewIFeeHandler(cfg.feeHandlerContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setFeeHandler(feeHandler)
+	m := msg.NewFungibleTransfer(1, cfg.id, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes())
+
+	err = writer.collectFee(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend.revert = true
+	err = writer.collectFee(m)
+	if !errors.Is(err, ErrFeeReverted) {
+		t.Fatalf("expected ErrFeeReverted, got: %v", err)
+	}
+	if sent := len(backend.transactions()); sent != 2 {
+		t.Fatalf("expected 2 fee payments, got: %d", sent)
+	}
+}
+
+func TestWriter_WaitIdle(t *testing.T) {
+	cfg := createConfig("idle", big.NewInt(0), nil)
+	writer := NewWriter(nil, cfg, newTestLogger(cfg.name), make(chan int), make(chan error, 1), nil)
+
+	m := msg.NewFungibleTransfer(1, cfg.id, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes())
+	writer.track(m)
+	watching := make(chan struct{})
+	writer.background(func() { <-watching })
+
+	idle := make(chan struct{})
+	go func() {
+		writer.waitIdle()
+		close(idle)
+	}()
+
+	writer.untrack(m)
+	select {
+	case <-idle:
+		t.Fatal("expected waitIdle to wait for the background routine")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(watching)
+	select {
+	case <-idle:
+	case <-time.After(time.Second):
+		t.Fatal("expected the writer to be idle once the background routine returned")
+	}
+}
+
+func TestWriter_ResolveSignedMessage(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only Bob is a relayer, and every proposal is already transferred so no transaction is sent
+	proposalChecks := 0
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		isRelayer := bridgeABI.Methods["isRelayer"]
+		if bytes.Equal(call.Data[:4], isRelayer.ID) {
+			args, err := isRelayer.Inputs.Unpack(call.Data[4:])
+			if err != nil {
+				return nil, err
+			}
+			return isRelayer.Outputs.Pack(args[0].(common.Address) == BobKp.CommonAddress())
+		}
+		proposalChecks++
+		return bridgeABI.Methods["getProposal"].Outputs.Pack(Bridge.BridgeProposal{Status: TransferredStatus, ProposedBlock: big.NewInt(1)})
+	}
+	cfg := createConfig("signed", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	cfg.requireSignedMessages = true
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	m := msg.NewFungibleTransfer(1, cfg.id, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), BobKp.CommonAddress().Bytes())
+	signed, err := message.Sign(m, BobKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.ResolveSignedMessage(signed)
+	if proposalChecks == 0 {
+		t.Fatal("expected the proposal of the signed message to be checked")
+	}
+	checked := proposalChecks
+
+	// Raising the amount after signing recovers a different signer, which is not a relayer
+	tampered := signed
+	tampered.Payload = []interface{}{big.NewInt(1000).Bytes(), m.Payload[1]}
+	if writer.ResolveSignedMessage(tampered) {
+		t.Fatal("expected the tampered message to be rejected")
+	}
+
+	notRelayer, err := message.Sign(m, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writer.ResolveSignedMessage(notRelayer) {
+		t.Fatal("expected a message signed by another key to be rejected")
+	}
+	if writer.ResolveMessage(m) {
+		t.Fatal("expected an unsigned message to be rejected")
+	}
+	if proposalChecks != checked {
+		t.Fatalf("expected no proposal to be checked for rejected messages, got %d more checks", proposalChecks-checked)
+	}
+}
+
+func TestWriter_ForwardDeposit(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fee := big.NewInt(100)
+	backend.call = func(call eth.CallMsg) ([]byte, error) {
+		return bridgeABI.Methods["_fee"].Outputs.Pack(fee)
+	}
+	cfg := createConfig("forward", big.NewInt(0), &utils.DeployedContracts{BridgeAddress: common.HexToAddress("0x0000000000000000000000000000000000001234")})
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	bridge, err := Bridge.NewBridge(cfg.bridgeContract, conn.Backend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.setContract(bridge)
+
+	m := msg.NewGenericTransfer(0, 2, 1, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	forwarded, err := message.Forward(m, cfg.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without forwardDeposits the deposit is left to another relayer
+	if !writer.ResolveMessage(forwarded) {
+		t.Fatal("expected the forwarded message to be skipped")
+	}
+	if len(backend.transactions()) != 0 {
+		t.Fatalf("expected no transactions, got: %d", len(backend.transactions()))
+	}
+
+	writer.cfg.forwardDeposits = true
+	if !writer.ResolveMessage(forwarded) {
+		t.Fatal("expected the forwarded message to be deposited")
+	}
+	txs := backend.transactions()
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got: %d", len(txs))
+	}
+	deposit := bridgeABI.Methods["deposit"]
+	if txs[0].To() == nil || *txs[0].To() != cfg.bridgeContract || !bytes.Equal(txs[0].Data()[:4], deposit.ID) {
+		t.Fatal("expected a deposit to the bridge")
+	}
+	if txs[0].Value().Cmp(fee) != 0 {
+		t.Fatalf("expected the deposit to pay the fee %s, got: %s", fee, txs[0].Value())
+	}
+	args, err := deposit.Inputs.Unpack(txs[0].Data()[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(uint8) != 2 || args[1].([32]byte) != m.ResourceId || !bytes.Equal(args[2].([]byte), ConstructGenericProposalData([]byte("metadata"))) {
+		t.Fatalf("unexpected deposit arguments: %v", args)
+	}
+}
+
+func TestWriter_PinTokenURI(t *testing.T) {
+	server := ipfstest.NewServer(t)
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("pin", big.NewInt(0), nil)
+	stop := make(chan int)
+	defer close(stop)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+	m := msg.NewNonFungibleTransfer(1, 0, 1, msg.ResourceIdFromSlice([]byte{1}), big.NewInt(7), BobKp.CommonAddress().Bytes(), []byte("ipfs://QmToken/7.json"))
+
+	// Nothing is pinned without a metadata store
+	writer.pinTokenURI(m, []byte("ipfs://QmToken/7.json"))
+	writer.setMetadataStore(ipfs.NewMetadataStore(server.URL))
+	writer.pinTokenURI(m, []byte("https://example.com/7.json"))
+	writer.pinTokenURI(m, []byte("ipfs://QmToken/7.json"))
+
+	pinned := server.Pinned()
+	if len(pinned) != 1 || pinned[0] != "/ipfs/QmToken/7.json" {
+		t.Fatalf("expected only the ipfs URI to be pinned, got: %v", pinned)
+	}
+}
+
+func TestWriter_WatchReceiptStops(t *testing.T) {
+	backend := newMockBackend()
+	conn, err := newMockConnection(backend, AliceKp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := createConfig("receipt", big.NewInt(0), nil)
+	stop := make(chan int)
+	writer := NewWriter(conn, cfg, newTestLogger(cfg.name), stop, make(chan error, 1), nil)
+
+	// The transaction is never mined, so the receipt is waited for until the writer stops
+	baseline := goleak.IgnoreCurrent()
+	tx := ethtypes.NewTransaction(0, BobKp.CommonAddress(), big.NewInt(0), 21000, big.NewInt(1), nil)
+	done := make(chan struct{})
+	go func() {
+		writer.watchReceipt(tx, msg.Message{})
+		close(done)
+	}()
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(TestTimeout):
+		t.Fatal("expected the receipt watcher to return once the writer stopped")
+	}
+	goleak.VerifyNone(t, baseline)
+}
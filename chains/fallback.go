@@ -0,0 +1,38 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package chains
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+var _ Writer = &FallbackWriter{}
+
+// FallbackWriter is a Writer that keeps the messages it receives, so those a destination could not resolve can be
+// replayed once it recovers
+type FallbackWriter struct {
+	lock sync.Mutex
+	msgs []msg.Message
+}
+
+func NewFallbackWriter() *FallbackWriter {
+	return &FallbackWriter{}
+}
+
+// ResolveMessage stores the message
+func (w *FallbackWriter) ResolveMessage(m msg.Message) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.msgs = append(w.msgs, m)
+	return true
+}
+
+// ReceivedMessages returns the messages received so far, in the order they were received
+func (w *FallbackWriter) ReceivedMessages() []msg.Message {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]msg.Message{}, w.msgs...)
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package chains
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestFallbackWriter(t *testing.T) {
+	w := NewFallbackWriter()
+	for i := 1; i <= 3; i++ {
+		if !w.ResolveMessage(msg.Message{Source: 1, Destination: 2, DepositNonce: msg.Nonce(i)}) {
+			t.Fatal("expected the message to be stored")
+		}
+	}
+
+	received := w.ReceivedMessages()
+	if len(received) != 3 {
+		t.Fatalf("expected 3 messages, got: %d", len(received))
+	}
+	for i, m := range received {
+		if m.DepositNonce != msg.Nonce(i+1) {
+			t.Fatalf("expected nonce %d, got: %d", i+1, m.DepositNonce)
+		}
+	}
+	// The returned slice is a copy
+	received[0].DepositNonce = 10
+	if w.ReceivedMessages()[0].DepositNonce != 1 {
+		t.Fatal("expected the received messages to be unchanged")
+	}
+}
@@ -4,6 +4,9 @@
 package chains
 
 import (
+	"fmt"
+
+	"github.com/ChainSafe/ChainBridge/message"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 )
 
@@ -11,6 +14,64 @@ type Router interface {
 	Send(message msg.Message) error
 }
 
-//type Writer interface {
-//	ResolveMessage(message msg.Message) bool
-//}
+// SignedRouter is a Router that also routes messages signed by the relayer
+type SignedRouter interface {
+	Router
+	SendSigned(message message.SignedMessage) error
+}
+
+// Writer consumes a message and makes the required on-chain interactions.
+type Writer interface {
+	ResolveMessage(message msg.Message) bool
+}
+
+// SignedWriter is a Writer that verifies the relayer signature of messages. A SignedRouter passes it every message,
+// signed or not, with ResolveSignedMessage.
+type SignedWriter interface {
+	Writer
+	ResolveSignedMessage(message message.SignedMessage) bool
+}
+
+// Reader queries the bridge state of a chain.
+type Reader interface {
+	// GetDepositRecord returns the deposit made on this chain to destination with nonce
+	GetDepositRecord(destination msg.ChainId, nonce uint64) (*DepositRecord, error)
+	// GetProposalStatus returns the status of the proposal on this chain for the deposit made on source with nonce
+	GetProposalStatus(source msg.ChainId, nonce uint64) (ProposalStatus, error)
+}
+
+// DepositRecord is a deposit as stored by the bridge it was made on
+type DepositRecord struct {
+	Destination msg.ChainId
+	Nonce       uint64
+	Data        []byte // Deposit data passed to the handler, in the handler's format
+}
+
+// ProposalStatus is the state of a proposal to execute a deposit from another chain
+type ProposalStatus uint8
+
+// Proposal states, in the order of the bridge contract's ProposalStatus enum
+const (
+	ProposalStatusInactive ProposalStatus = iota // No votes yet
+	ProposalStatusActive
+	ProposalStatusPassed
+	ProposalStatusExecuted
+	ProposalStatusCancelled
+)
+
+func (s ProposalStatus) String() string {
+	switch s {
+	case ProposalStatusInactive:
+		return "inactive"
+	case ProposalStatusActive:
+		return "active"
+	case ProposalStatusPassed:
+		return "passed"
+	case ProposalStatusExecuted:
+		return "executed"
+	case ProposalStatusCancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+}
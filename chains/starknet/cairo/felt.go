@@ -0,0 +1,255 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The cairo package translates between Go values and the felt arrays Cairo contracts take as calldata and emit as
+event keys and data.
+
+A felt is an element of the StarkNet field, an integer below P. Values are serialized as Cairo's Serde does:
+
+	uint8, uint64, bool and Felt   one felt
+	*big.Int                       a u256, as its low and high 128 bits
+	[]byte                         a ByteArray, as the number of full 31 byte words, the words, the pending word and its length
+*/
+package cairo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// P is the prime of the StarkNet field, 2^251 + 17 * 2^192 + 1
+var P, _ = new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+
+// bytesPerWord is the number of bytes in each full word of a ByteArray
+const bytesPerWord = 31
+
+var ErrOverflow = errors.New("value does not fit in a felt")
+var ErrShortInput = errors.New("not enough felts to decode")
+
+var u128Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// Felt is a field element, big-endian encoded
+type Felt [32]byte
+
+// FeltFromBig returns the felt of b, which must be in [0, P)
+func FeltFromBig(b *big.Int) (Felt, error) {
+	var f Felt
+	if b.Sign() < 0 || b.Cmp(P) >= 0 {
+		return f, fmt.Errorf("%w: %s", ErrOverflow, b)
+	}
+	b.FillBytes(f[:])
+	return f, nil
+}
+
+// FeltFromUint64 returns the felt of v
+func FeltFromUint64(v uint64) Felt {
+	f, _ := FeltFromBig(new(big.Int).SetUint64(v))
+	return f
+}
+
+// ParseFelt parses a 0x prefixed hex felt, as used by the StarkNet JSON-RPC API
+func ParseFelt(s string) (Felt, error) {
+	b, err := hexutil.DecodeBig(s)
+	if err != nil {
+		return Felt{}, fmt.Errorf("invalid felt %s: %w", s, err)
+	}
+	return FeltFromBig(b)
+}
+
+// Selector returns the selector of a function or event name, its starknet keccak. This is the keccak256 hash of the
+// name truncated to 250 bits.
+func Selector(name string) Felt {
+	var f Felt
+	copy(f[:], crypto.Keccak256([]byte(name)))
+	f[0] &= 0x03
+	return f
+}
+
+// Big returns the felt as a big.Int
+func (f Felt) Big() *big.Int {
+	return new(big.Int).SetBytes(f[:])
+}
+
+// Uint64 returns the felt as a uint64, or ErrOverflow if it is larger
+func (f Felt) Uint64() (uint64, error) {
+	b := f.Big()
+	if !b.IsUint64() {
+		return 0, fmt.Errorf("%w: %s is not a uint64", ErrOverflow, b)
+	}
+	return b.Uint64(), nil
+}
+
+// String returns the 0x prefixed hex felt without leading zeros
+func (f Felt) String() string {
+	return hexutil.EncodeBig(f.Big())
+}
+
+func (f Felt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f *Felt) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	*f, err = ParseFelt(s)
+	return err
+}
+
+// Encode serializes the values in order. Supported types are listed in the package documentation.
+func Encode(values ...interface{}) ([]Felt, error) {
+	var felts []Felt
+	for i, value := range values {
+		switch v := value.(type) {
+		case Felt:
+			felts = append(felts, v)
+		case uint8:
+			felts = append(felts, FeltFromUint64(uint64(v)))
+		case uint64:
+			felts = append(felts, FeltFromUint64(v))
+		case bool:
+			if v {
+				felts = append(felts, FeltFromUint64(1))
+			} else {
+				felts = append(felts, FeltFromUint64(0))
+			}
+		case *big.Int:
+			if v.Sign() < 0 || v.BitLen() > 256 {
+				return nil, fmt.Errorf("%w: value %d is not a u256", ErrOverflow, i)
+			}
+			low, _ := FeltFromBig(new(big.Int).And(v, u128Max))
+			high, _ := FeltFromBig(new(big.Int).Rsh(v, 128))
+			felts = append(felts, low, high)
+		case []byte:
+			felts = append(felts, encodeByteArray(v)...)
+		default:
+			return nil, fmt.Errorf("unable to encode value %d of type %T", i, value)
+		}
+	}
+	return felts, nil
+}
+
+func encodeByteArray(b []byte) []Felt {
+	full := len(b) / bytesPerWord
+	felts := []Felt{FeltFromUint64(uint64(full))}
+	for i := 0; i < full; i++ {
+		var f Felt
+		copy(f[32-bytesPerWord:], b[i*bytesPerWord:(i+1)*bytesPerWord])
+		felts = append(felts, f)
+	}
+	pending := b[full*bytesPerWord:]
+	var f Felt
+	copy(f[32-len(pending):], pending)
+	return append(felts, f, FeltFromUint64(uint64(len(pending))))
+}
+
+// Decode deserializes felts into targets in order, which must be pointers to the types Encode supports. Every felt
+// must be decoded.
+func Decode(felts []Felt, targets ...interface{}) error {
+	next := func() (Felt, error) {
+		if len(felts) == 0 {
+			return Felt{}, ErrShortInput
+		}
+		f := felts[0]
+		felts = felts[1:]
+		return f, nil
+	}
+
+	for i, target := range targets {
+		var err error
+		switch t := target.(type) {
+		case *Felt:
+			*t, err = next()
+		case *uint8:
+			var v uint64
+			v, err = decodeUint64(next)
+			if err == nil && v > 0xff {
+				err = fmt.Errorf("%w: %d is not a uint8", ErrOverflow, v)
+			}
+			*t = uint8(v)
+		case *uint64:
+			*t, err = decodeUint64(next)
+		case *bool:
+			var v uint64
+			v, err = decodeUint64(next)
+			if err == nil && v > 1 {
+				err = fmt.Errorf("%w: %d is not a bool", ErrOverflow, v)
+			}
+			*t = v == 1
+		case **big.Int:
+			*t, err = decodeU256(next)
+		case *[]byte:
+			*t, err = decodeByteArray(next)
+		default:
+			return fmt.Errorf("unable to decode target %d of type %T", i, target)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to decode target %d: %w", i, err)
+		}
+	}
+	if len(felts) != 0 {
+		return fmt.Errorf("%d felts were not decoded", len(felts))
+	}
+	return nil
+}
+
+func decodeUint64(next func() (Felt, error)) (uint64, error) {
+	f, err := next()
+	if err != nil {
+		return 0, err
+	}
+	return f.Uint64()
+}
+
+func decodeU256(next func() (Felt, error)) (*big.Int, error) {
+	low, err := next()
+	if err != nil {
+		return nil, err
+	}
+	high, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if low.Big().BitLen() > 128 || high.Big().BitLen() > 128 {
+		return nil, fmt.Errorf("%w: u256 limbs must be 128 bits", ErrOverflow)
+	}
+	return new(big.Int).Or(new(big.Int).Lsh(high.Big(), 128), low.Big()), nil
+}
+
+func decodeByteArray(next func() (Felt, error)) ([]byte, error) {
+	full, err := decodeUint64(next)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	for i := uint64(0); i < full; i++ {
+		word, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if word.Big().BitLen() > bytesPerWord*8 {
+			return nil, fmt.Errorf("%w: word %d is longer than %d bytes", ErrOverflow, i, bytesPerWord)
+		}
+		b = append(b, word[32-bytesPerWord:]...)
+	}
+	pending, err := next()
+	if err != nil {
+		return nil, err
+	}
+	length, err := decodeUint64(next)
+	if err != nil {
+		return nil, err
+	}
+	if length >= bytesPerWord || pending.Big().BitLen() > int(length)*8 {
+		return nil, fmt.Errorf("%w: pending word does not fit in %d bytes", ErrOverflow, length)
+	}
+	return append(b, pending[32-length:]...), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cairo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	u256 := new(big.Int).Lsh(big.NewInt(3), 200)
+	u256.Add(u256, big.NewInt(7))
+	long := bytes.Repeat([]byte("chainbridge"), 7) // 77 bytes, two full words and a 15 byte pending word
+
+	felts, err := Encode(uint8(2), uint64(1<<40), true, FeltFromUint64(9), u256, long, []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 + 1 + 1 + 1 + 2 + (1 + 2 + 2) + (1 + 2)
+	if len(felts) != 14 {
+		t.Fatalf("expected 14 felts, got: %d", len(felts))
+	}
+	if felts[4].Big().Cmp(big.NewInt(7)) != 0 || felts[5].Big().Cmp(new(big.Int).Lsh(big.NewInt(3), 72)) != 0 {
+		t.Fatalf("expected the u256 to be split into 128 bit limbs, got: %s, %s", felts[4], felts[5])
+	}
+
+	var (
+		u8     uint8
+		u64    uint64
+		b      bool
+		f      Felt
+		amount *big.Int
+		data   []byte
+		empty  []byte
+	)
+	err = Decode(felts, &u8, &u64, &b, &f, &amount, &data, &empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u8 != 2 || u64 != 1<<40 || !b || f != FeltFromUint64(9) || amount.Cmp(u256) != 0 || !bytes.Equal(data, long) || len(empty) != 0 {
+		t.Fatalf("unexpected decoded values: %d %d %v %s %s %x %x", u8, u64, b, f, amount, data, empty)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	var u8 uint8
+	var amount *big.Int
+	err := Decode([]Felt{FeltFromUint64(1)}, &amount)
+	if !errors.Is(err, ErrShortInput) {
+		t.Fatalf("expected ErrShortInput, got: %v", err)
+	}
+	err = Decode([]Felt{FeltFromUint64(256)}, &u8)
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow, got: %v", err)
+	}
+	err = Decode([]Felt{FeltFromUint64(1), FeltFromUint64(2)}, &u8)
+	if err == nil {
+		t.Fatal("expected error for felts that were not decoded")
+	}
+	var data []byte
+	err = Decode([]Felt{FeltFromUint64(0), FeltFromUint64(0x1234), FeltFromUint64(1)}, &data)
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow for a pending word longer than its length, got: %v", err)
+	}
+}
+
+func TestFelt(t *testing.T) {
+	_, err := FeltFromBig(P)
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow for P, got: %v", err)
+	}
+
+	// The selector of the ERC20 transfer event
+	expected := "0x99cd8bde557814842a3121e8ddfd433a539b8c9f14bf31ebf108d12e6196e9"
+	if s := Selector("Transfer").String(); s != expected {
+		t.Fatalf("expected selector %s, got: %s", expected, s)
+	}
+
+	var f Felt
+	err = json.Unmarshal([]byte(`"0x1f"`), &f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.Uint64(); v != 31 {
+		t.Fatalf("expected 31, got: %d", v)
+	}
+	out, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `"0x1f"` {
+		t.Fatalf("expected \"0x1f\", got: %s", out)
+	}
+}
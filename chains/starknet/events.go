@@ -0,0 +1,75 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The starknet package translates between bridge messages and the events and calldata of a Cairo bridge contract.
+
+Deposits are read from the bridge's Deposit events. The event's only key is its selector, and its data is
+
+	destination_chain_id: u8, resource_id: u256, deposit_nonce: u64, amount: u256, recipient: ByteArray
+
+Proposals are executed by invoking the handler's execute_proposal function with the calldata returned by
+ProposalCalldata.
+*/
+package starknet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/ChainBridge/chains/starknet/cairo"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+const (
+	DepositEvent            = "Deposit"
+	ExecuteProposalFunction = "execute_proposal"
+)
+
+var ErrInvalidDepositEvent = errors.New("invalid deposit event")
+
+// Event is an event emitted by a contract, as returned by starknet_getEvents
+type Event struct {
+	FromAddress cairo.Felt   `json:"from_address"`
+	Keys        []cairo.Felt `json:"keys"`
+	Data        []cairo.Felt `json:"data"`
+}
+
+// DecodeDeposit returns the fungible transfer of a Deposit event emitted by the bridge of chain source
+func DecodeDeposit(source msg.ChainId, e Event) (msg.Message, error) {
+	if len(e.Keys) != 1 || e.Keys[0] != cairo.Selector(DepositEvent) {
+		return msg.Message{}, fmt.Errorf("%w: not a %s event", ErrInvalidDepositEvent, DepositEvent)
+	}
+	var (
+		dest       uint8
+		resourceId *big.Int
+		nonce      uint64
+		amount     *big.Int
+		recipient  []byte
+	)
+	err := cairo.Decode(e.Data, &dest, &resourceId, &nonce, &amount, &recipient)
+	if err != nil {
+		return msg.Message{}, fmt.Errorf("%w: %s", ErrInvalidDepositEvent, err)
+	}
+	var rId msg.ResourceId
+	resourceId.FillBytes(rId[:])
+	return msg.NewFungibleTransfer(source, msg.ChainId(dest), msg.Nonce(nonce), amount, rId, recipient), nil
+}
+
+// ProposalCalldata returns the execute_proposal calldata of a fungible transfer to this chain,
+// (source_chain_id: u8, deposit_nonce: u64, resource_id: u256, amount: u256, recipient: ByteArray)
+func ProposalCalldata(m msg.Message) ([]cairo.Felt, error) {
+	if m.Type != msg.FungibleTransfer || len(m.Payload) != 2 {
+		return nil, fmt.Errorf("unsupported message type %s", m.Type)
+	}
+	amount, ok := m.Payload[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount of type %T", m.Payload[0])
+	}
+	recipient, ok := m.Payload[1].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid recipient of type %T", m.Payload[1])
+	}
+	return cairo.Encode(uint8(m.Source), uint64(m.DepositNonce), new(big.Int).SetBytes(m.ResourceId[:]), new(big.Int).SetBytes(amount), recipient)
+}
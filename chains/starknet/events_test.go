@@ -0,0 +1,70 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package starknet
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/chains/starknet/cairo"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestDecodeDeposit(t *testing.T) {
+	rId := msg.ResourceIdFromSlice([]byte{0xab, 0xcd})
+	recipient := []byte("0x0000000000000000000000000000000000001234")
+	data, err := cairo.Encode(uint8(1), new(big.Int).SetBytes(rId[:]), uint64(42), big.NewInt(1000), recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := DecodeDeposit(3, Event{Keys: []cairo.Felt{cairo.Selector(DepositEvent)}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := msg.NewFungibleTransfer(3, 1, 42, big.NewInt(1000), rId, recipient)
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, m)
+	}
+
+	_, err = DecodeDeposit(3, Event{Keys: []cairo.Felt{cairo.Selector("Transfer")}, Data: data})
+	if !errors.Is(err, ErrInvalidDepositEvent) {
+		t.Fatalf("expected ErrInvalidDepositEvent for another event, got: %v", err)
+	}
+	_, err = DecodeDeposit(3, Event{Keys: []cairo.Felt{cairo.Selector(DepositEvent)}, Data: data[:3]})
+	if !errors.Is(err, ErrInvalidDepositEvent) {
+		t.Fatalf("expected ErrInvalidDepositEvent for truncated data, got: %v", err)
+	}
+}
+
+func TestProposalCalldata(t *testing.T) {
+	rId := msg.ResourceIdFromSlice([]byte{0xab, 0xcd})
+	m := msg.NewFungibleTransfer(1, 3, 42, big.NewInt(1000), rId, []byte{0x12, 0x34})
+	calldata, err := ProposalCalldata(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		source    uint8
+		nonce     uint64
+		resource  *big.Int
+		amount    *big.Int
+		recipient []byte
+	)
+	err = cairo.Decode(calldata, &source, &nonce, &resource, &amount, &recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != 1 || nonce != 42 || msg.ResourceIdFromSlice(resource.Bytes()) != rId || amount.Int64() != 1000 || string(recipient) != "\x12\x34" {
+		t.Fatalf("unexpected calldata: %d %d %x %s %x", source, nonce, resource, amount, recipient)
+	}
+
+	_, err = ProposalCalldata(msg.NewGenericTransfer(1, 3, 42, rId, []byte{1}))
+	if err == nil {
+		t.Fatal("expected error for a generic transfer")
+	}
+}
@@ -24,16 +24,38 @@ As the writer receives messages from the router, it constructs proposals. If a p
 package substrate
 
 import (
-	"github.com/ChainSafe/chainbridge-utils/blockstore"
-	"github.com/ChainSafe/chainbridge-utils/core"
+	goctx "context" // The package tests declare a context variable
+	"sync/atomic"
+
+	"github.com/ChainSafe/ChainBridge/blockstore"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/ChainBridge/keystore"
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
 	"github.com/ChainSafe/chainbridge-utils/crypto/sr25519"
-	"github.com/ChainSafe/chainbridge-utils/keystore"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
 )
 
 var _ core.Chain = &Chain{}
+var _ core.Readier = &Chain{}
+
+// ChainType identifies substrate chains in the bridge config
+const ChainType = "substrate"
+
+func init() {
+	core.RegisterChainFactory(ChainType, newChain)
+}
+
+// newChain initializes a chain for the core.ChainFactory registry
+func newChain(cfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (core.Chain, error) {
+	chain, err := InitializeChain(cfg, logger, sysErr, m)
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
 
 type Chain struct {
 	cfg      *core.ChainConfig // The config of the chain
@@ -41,11 +63,12 @@ type Chain struct {
 	listener *listener         // The listener of this chain
 	writer   *writer           // The writer of the chain
 	stop     chan<- int
+	running  int32 // 1 while started, accessed atomically
 }
 
 // checkBlockstore queries the blockstore for the latest known block. If the latest block is
 // greater than startBlock, then the latest block is returned, otherwise startBlock is.
-func checkBlockstore(bs *blockstore.Blockstore, startBlock uint64) (uint64, error) {
+func checkBlockstore(bs blockstore.Blockstore, startBlock uint64) (uint64, error) {
 	latestBlock, err := bs.TryLoadLatestBlock()
 	if err != nil {
 		return 0, err
@@ -100,11 +123,15 @@ func InitializeChain(cfg *core.ChainConfig, logger log15.Logger, sysErr chan<- e
 		startBlock = uint64(curr.Number)
 	}
 
+	if !cfg.StartTime.IsZero() {
+		logger.Warn("Start time is not supported on substrate chains, ignoring it", "startTime", cfg.StartTime)
+	}
+
 	ue := parseUseExtended(cfg)
 
 	// Setup listener & writer
 	l := NewListener(conn, cfg.Name, cfg.Id, startBlock, logger, bs, stop, sysErr, m)
-	w := NewWriter(conn, logger, sysErr, m, ue)
+	w := NewWriter(conn, logger.New("chain_name", registry.NameOf(cfg.Id)), sysErr, m, ue)
 	return &Chain{
 		cfg:      cfg,
 		conn:     conn,
@@ -119,11 +146,12 @@ func (c *Chain) Start() error {
 	if err != nil {
 		return err
 	}
+	atomic.StoreInt32(&c.running, 1)
 	c.conn.log.Debug("Successfully started chain", "chainId", c.cfg.Id)
 	return nil
 }
 
-func (c *Chain) SetRouter(r *core.Router) {
+func (c *Chain) SetRouter(r *router.Router) {
 	r.Listen(c.cfg.Id, c.writer)
 	c.listener.setRouter(r)
 }
@@ -140,6 +168,22 @@ func (c *Chain) Name() string {
 	return c.cfg.Name
 }
 
+// Running returns true once the chain has started, until it is stopped
+func (c *Chain) Running() bool {
+	return atomic.LoadInt32(&c.running) == 1
+}
+
+// WaitReady blocks until the listener has polled the finalized head, or ctx is done
+func (c *Chain) WaitReady(ctx goctx.Context) error {
+	select {
+	case <-c.listener.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (c *Chain) Stop() {
+	atomic.StoreInt32(&c.running, 0)
 	close(c.stop)
 }
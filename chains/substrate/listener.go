@@ -7,9 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
 	utils "github.com/ChainSafe/ChainBridge/shared/substrate"
 	"github.com/ChainSafe/chainbridge-utils/blockstore"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
@@ -31,6 +34,8 @@ type listener struct {
 	sysErr        chan<- error
 	latestBlock   metrics.LatestBlock
 	metrics       *metrics.ChainMetrics
+	ready         chan struct{} // Closed once the first poll of the finalized head succeeds
+	readyOnce     sync.Once
 }
 
 // Frequency of polling for a new block
@@ -45,11 +50,12 @@ func NewListener(conn *Connection, name string, id msg.ChainId, startBlock uint6
 		blockstore:    bs,
 		conn:          conn,
 		subscriptions: make(map[eventName]eventHandler),
-		log:           log,
+		log:           log.New("chain_name", registry.NameOf(id)),
 		stop:          stop,
 		sysErr:        sysErr,
 		latestBlock:   metrics.LatestBlock{LastUpdated: time.Now()},
 		metrics:       m,
+		ready:         make(chan struct{}),
 	}
 }
 
@@ -131,6 +137,8 @@ func (l *listener) pollBlocks() error {
 				continue
 			}
 
+			l.readyOnce.Do(func() { close(l.ready) })
+
 			if l.metrics != nil {
 				l.metrics.LatestKnownBlock.Set(float64(finalizedHeader.Number))
 			}
@@ -244,7 +252,18 @@ func (l *listener) submitMessage(m msg.Message, err error) {
 		return
 	}
 	m.Source = l.chainId
-	err = l.router.Send(m)
+	for {
+		err = l.router.Send(m)
+		if !errors.Is(err, router.ErrQueueFull) {
+			break
+		}
+		l.log.Warn("Router queue full, waiting to resend message", "dest", m.Destination, "nonce", m.DepositNonce)
+		select {
+		case <-l.stop:
+			return
+		case <-time.After(BlockRetryInterval):
+		}
+	}
 	if err != nil {
 		log15.Error("failed to process event", "err", err)
 	}
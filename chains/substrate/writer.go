@@ -9,7 +9,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/ChainSafe/chainbridge-utils/core"
+	"github.com/ChainSafe/ChainBridge/chains"
 
 	utils "github.com/ChainSafe/ChainBridge/shared/substrate"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
@@ -18,7 +18,7 @@ import (
 	"github.com/centrifuge/go-substrate-rpc-client/types"
 )
 
-var _ core.Writer = &writer{}
+var _ chains.Writer = &writer{}
 
 var AcknowledgeProposal utils.Method = utils.BridgePalletName + ".acknowledge_proposal"
 var TerminatedError = errors.New("terminated")
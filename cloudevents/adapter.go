@@ -0,0 +1,72 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The cloudevents package publishes the messages the bridge routes as CloudEvents (https://cloudevents.io), for
+event-driven infrastructure outside the bridge.
+
+Each message is published once per destination it is queued for, as an event with the attributes
+
+	type              com.chainbridge.deposit
+	source            the source chain ID and its bridge address, eg. 0/0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B
+	subject           the destination chain ID
+	id                source/destination/nonce, which is unique for each deposit and destination
+	datacontenttype   application/json
+
+and the message in the JSON format of the message package as its data. Events are sent over HTTP in binary mode.
+*/
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	sdk "github.com/cloudevents/sdk-go/v2"
+)
+
+// DepositEventType is the type of the events of routed messages
+const DepositEventType = "com.chainbridge.deposit"
+
+// Adapter converts messages to CloudEvents
+type Adapter struct {
+	bridges map[msg.ChainId]string // Bridge contract address of each chain, used in the event source
+}
+
+// NewAdapter returns an adapter using the bridge addresses of the chains in the event source. Events of chains
+// without an address have only the chain ID as their source.
+func NewAdapter(bridges map[msg.ChainId]string) *Adapter {
+	return &Adapter{bridges: bridges}
+}
+
+// Event returns the CloudEvent of m, as described in the package documentation
+func (a *Adapter) Event(m msg.Message) (sdk.Event, error) {
+	data, err := message.MarshalFormat(message.FormatJSON, m)
+	if err != nil {
+		return sdk.Event{}, err
+	}
+
+	e := sdk.NewEvent()
+	e.SetID(fmt.Sprintf("%d/%d/%d", m.Source, m.Destination, m.DepositNonce))
+	e.SetType(DepositEventType)
+	e.SetSource(a.source(m.Source))
+	e.SetSubject(strconv.Itoa(int(m.Destination)))
+	e.SetTime(time.Now())
+	// Raw JSON is used as the data as is, where bytes would be base64 encoded
+	err = e.SetData(sdk.ApplicationJSON, json.RawMessage(data))
+	if err != nil {
+		return sdk.Event{}, err
+	}
+	return e, nil
+}
+
+// source returns the event source of messages from chain id
+func (a *Adapter) source(id msg.ChainId) string {
+	if bridge, ok := a.bridges[id]; ok && bridge != "" {
+		return fmt.Sprintf("%d/%s", id, bridge)
+	}
+	return strconv.Itoa(int(id))
+}
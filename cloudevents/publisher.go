@@ -0,0 +1,116 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cloudevents
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	sdk "github.com/cloudevents/sdk-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueueSize is the number of events that may wait to be sent before further messages are dropped
+const QueueSize = 1000
+
+// SendTimeout is the longest an event is sent for
+var SendTimeout = 10 * time.Second
+
+var publishErrors = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "chainbridge_cloudevents_publish_errors_total",
+	Help: "Number of routed messages that could not be published as CloudEvents",
+})
+
+func init() {
+	prometheus.MustRegister(publishErrors)
+}
+
+var _ router.Sink = &Publisher{}
+
+// Publisher sends routed messages as CloudEvents to an HTTP endpoint
+type Publisher struct {
+	client   sdk.Client
+	adapter  *Adapter
+	endpoint string
+	events   chan sdk.Event
+	stop     chan struct{} // Closed by Close, the events already queued are still sent
+	done     chan struct{} // Closed once the queued events are sent after Close
+	log      log15.Logger
+}
+
+// NewPublisher returns a publisher sending the events of adapter to endpoint. Events are sent in the background so
+// routing is not delayed, and failures are logged.
+func NewPublisher(endpoint string, adapter *Adapter, log log15.Logger) (*Publisher, error) {
+	client, err := sdk.NewClientHTTP()
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		client:   client,
+		adapter:  adapter,
+		endpoint: endpoint,
+		events:   make(chan sdk.Event, QueueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		log:      log,
+	}
+	go p.send()
+	return p, nil
+}
+
+// Publish queues the event of m to be sent, dropping it if QueueSize events are already waiting
+func (p *Publisher) Publish(m msg.Message) {
+	e, err := p.adapter.Event(m)
+	if err != nil {
+		publishErrors.Inc()
+		p.log.Error("Unable to create cloud event", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+		return
+	}
+	select {
+	case p.events <- e:
+	default:
+		publishErrors.Inc()
+		p.log.Error("Cloud event queue full, dropping event", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce)
+	}
+}
+
+// send sends the queued events in order until the publisher is closed, then sends those still queued
+func (p *Publisher) send() {
+	defer close(p.done)
+	for {
+		select {
+		case e := <-p.events:
+			p.sendEvent(e)
+		case <-p.stop:
+			for {
+				select {
+				case e := <-p.events:
+					p.sendEvent(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Publisher) sendEvent(e sdk.Event) {
+	ctx, cancel := context.WithTimeout(sdk.ContextWithTarget(context.Background(), p.endpoint), SendTimeout)
+	defer cancel()
+	res := p.client.Send(ctx, e)
+	if !sdk.IsACK(res) {
+		publishErrors.Inc()
+		p.log.Error("Failed to publish cloud event", "id", e.ID(), "endpoint", p.endpoint, "err", res)
+	}
+}
+
+// Close sends the events waiting to be published. Messages published after Close are not sent.
+func (p *Publisher) Close() error {
+	close(p.stop)
+	<-p.done
+	return nil
+}
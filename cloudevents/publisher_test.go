@@ -0,0 +1,102 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cloudevents
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var bridge = "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B"
+
+// receivedEvent is an event as received over HTTP in binary mode
+type receivedEvent struct {
+	header http.Header
+	body   []byte
+}
+
+func newEventServer(t *testing.T, status int, events chan<- receivedEvent) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		events <- receivedEvent{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	events := make(chan receivedEvent, 1)
+	server := newEventServer(t, http.StatusAccepted, events)
+	defer server.Close()
+
+	p, err := NewPublisher(server.URL, NewAdapter(map[msg.ChainId]string{1: bridge}), log15.New("test", "cloudevents"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := msg.NewFungibleTransfer(1, 2, 42, big.NewInt(100), msg.ResourceIdFromSlice([]byte{1}), []byte("recipient"))
+	p.Publish(m)
+	err = p.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := <-events
+	for header, expected := range map[string]string{
+		"Ce-Specversion": "1.0",
+		"Ce-Type":        DepositEventType,
+		"Ce-Source":      "1/" + bridge,
+		"Ce-Subject":     "2",
+		"Ce-Id":          "1/2/42",
+		"Content-Type":   "application/json",
+	} {
+		if got := e.header.Get(header); got != expected {
+			t.Fatalf("expected %s %q, got: %q", header, expected, got)
+		}
+	}
+	if e.header.Get("Ce-Time") == "" {
+		t.Fatal("expected the event time to be set")
+	}
+	decoded, err := message.UnmarshalFormat(message.FormatJSON, e.body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, m) {
+		t.Fatalf("expected %+v, got: %+v", m, decoded)
+	}
+}
+
+func TestPublisher_PublishError(t *testing.T) {
+	events := make(chan receivedEvent, 1)
+	server := newEventServer(t, http.StatusInternalServerError, events)
+	defer server.Close()
+
+	p, err := NewPublisher(server.URL, NewAdapter(nil), log15.New("test", "cloudevents"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := testutil.ToFloat64(publishErrors)
+	p.Publish(msg.NewGenericTransfer(1, 2, 1, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata")))
+	err = p.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := <-events; e.header.Get("Ce-Source") != "1" {
+		t.Fatalf("expected the source of a chain without a bridge to be its ID, got: %q", e.header.Get("Ce-Source"))
+	}
+	if got := testutil.ToFloat64(publishErrors) - before; got != 1 {
+		t.Fatalf("expected one publish error, got: %v", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/ChainBridge/blockstore"
+	"github.com/ChainSafe/ChainBridge/config"
+	log "github.com/ChainSafe/log15"
+	"github.com/urfave/cli/v2"
+)
+
+// handleBlockstoreExportCmd writes every entry of the blockstore directory to stdout
+func handleBlockstoreExportCmd(ctx *cli.Context, _ *dataHandler) error {
+	err := checkBlockstoreFormat(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries, err := blockstore.ReadDir(ctx.String(config.BlockstorePathFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to read blockstore: %w", err)
+	}
+	return blockstore.ExportCSV(entries, os.Stdout)
+}
+
+// handleBlockstoreImportCmd stores the entries read from a file, or stdin if none is given, in the blockstore directory
+func handleBlockstoreImportCmd(ctx *cli.Context, _ *dataHandler) error {
+	err := checkBlockstoreFormat(ctx)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if path := ctx.Args().First(); path != "" {
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	entries, err := blockstore.ImportCSV(in)
+	if err != nil {
+		return fmt.Errorf("failed to import blockstore: %w", err)
+	}
+	err = blockstore.WriteDir(ctx.String(config.BlockstorePathFlag.Name), entries)
+	if err != nil {
+		return err
+	}
+	log.Info("Imported blockstore", "entries", len(entries))
+	return nil
+}
+
+// handleBlockstoreMigrateCmd copies every entry of the blockstore backend given by --from to the one given by --to
+func handleBlockstoreMigrateCmd(ctx *cli.Context, _ *dataHandler) error {
+	from, err := blockstore.OpenStore(ctx.String(config.MigrateFromFlag.Name), ctx.String(config.MigrateFromPathFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool(config.DryRunFlag.Name) {
+		entries, err := from.Iterator()
+		if err != nil {
+			return fmt.Errorf("failed to read blockstore: %w", err)
+		}
+		return blockstore.ExportCSV(entries, os.Stdout)
+	}
+
+	if ctx.String(config.MigrateFromFlag.Name) == ctx.String(config.MigrateToFlag.Name) &&
+		ctx.String(config.MigrateFromPathFlag.Name) == ctx.String(config.MigrateToPathFlag.Name) {
+		return fmt.Errorf("cannot migrate a blockstore to itself")
+	}
+	to, err := blockstore.OpenStore(ctx.String(config.MigrateToFlag.Name), ctx.String(config.MigrateToPathFlag.Name))
+	if err != nil {
+		return err
+	}
+	err = blockstore.Migrate(from, to)
+	if err != nil {
+		return err
+	}
+	log.Info("Migrated blockstore", "from", ctx.String(config.MigrateFromFlag.Name), "to", ctx.String(config.MigrateToFlag.Name))
+	return nil
+}
+
+// handleBlockstoreCheckpointCmd checkpoints the blockstore of every chain and relayer in the blockstore directory
+func handleBlockstoreCheckpointCmd(ctx *cli.Context, _ *dataHandler) error {
+	path, label := ctx.String(config.BlockstorePathFlag.Name), ctx.String(config.CheckpointLabelFlag.Name)
+	entries, err := blockstore.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read blockstore: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no blocks stored in the blockstore")
+	}
+
+	for _, e := range entries {
+		bs, err := blockstore.NewFileBlockstore(path, e.Chain, e.Relayer)
+		if err != nil {
+			return err
+		}
+		err = blockstore.Checkpoint(bs, label)
+		if err != nil {
+			return fmt.Errorf("failed to checkpoint chain %d relayer %s: %w", e.Chain, e.Relayer, err)
+		}
+		log.Info("Checkpointed blockstore", "chain", e.Chain, "relayer", e.Relayer, "block", e.Block, "file", bs.CheckpointFile(label))
+	}
+	return nil
+}
+
+// handleBlockstoreRestoreCmd restores the blockstore of every chain and relayer with a checkpoint with the label
+func handleBlockstoreRestoreCmd(ctx *cli.Context, _ *dataHandler) error {
+	path, label := ctx.String(config.BlockstorePathFlag.Name), ctx.String(config.CheckpointLabelFlag.Name)
+	checkpoints, err := blockstore.Checkpoints(path, label)
+	if err != nil {
+		return fmt.Errorf("failed to read blockstore: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		return fmt.Errorf("%w: %s", blockstore.ErrCheckpointNotFound, label)
+	}
+
+	for _, e := range checkpoints {
+		bs, err := blockstore.NewFileBlockstore(path, e.Chain, e.Relayer)
+		if err != nil {
+			return err
+		}
+		err = blockstore.Restore(bs, label)
+		if err != nil {
+			return fmt.Errorf("failed to restore chain %d relayer %s: %w", e.Chain, e.Relayer, err)
+		}
+		log.Info("Restored blockstore", "chain", e.Chain, "relayer", e.Relayer, "block", e.Block, "label", label)
+	}
+	return nil
+}
+
+func checkBlockstoreFormat(ctx *cli.Context) error {
+	if format := ctx.String(config.BlockstoreFormatFlag.Name); format != config.CsvFormat {
+		return fmt.Errorf("unsupported blockstore format: %s", format)
+	}
+	return nil
+}
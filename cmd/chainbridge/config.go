@@ -0,0 +1,118 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/ChainBridge/config"
+	"github.com/ChainSafe/ChainBridge/core"
+	log "github.com/ChainSafe/log15"
+	"github.com/urfave/cli/v2"
+)
+
+// handleGenerateConfigCmd builds a bridge config, either by prompting for each field or from the chain flags
+func handleGenerateConfigCmd(ctx *cli.Context, _ *dataHandler) error {
+	output := ctx.String(config.OutputFlag.Name)
+
+	var out io.Writer = os.Stdout
+	// Keep prompts out of the generated JSON when writing to stdout
+	var prompts io.Writer = os.Stderr
+	if output != "" {
+		prompts = os.Stdout
+	}
+
+	var cfg *config.Config
+	var err error
+	if ctx.Bool(config.NonInteractiveFlag.Name) {
+		cfg, err = config.GenerateFromChains(chainsFromFlags(ctx))
+	} else {
+		cfg, err = config.Generate(os.Stdin, prompts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate config: %w", err)
+	}
+
+	if output != "" {
+		f, err := os.Create(filepath.Clean(output))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	err = cfg.Encode(out)
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		log.Info("Config written", "path", output)
+	}
+	return nil
+}
+
+// handleConfigSchemaCmd prints the opts schema of the chain type given by --chain-type, or of every chain type
+func handleConfigSchemaCmd(ctx *cli.Context, _ *dataHandler) error {
+	return writeOptsSchema(os.Stdout, ctx.String(config.SchemaChainTypeFlag.Name))
+}
+
+// writeOptsSchema writes the opts schema of chainType as indented JSON, or of every chain type if chainType is empty
+func writeOptsSchema(w io.Writer, chainType string) error {
+	schema := core.OptsSchema()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if chainType == "" {
+		return enc.Encode(schema)
+	}
+
+	fields, ok := schema[chainType]
+	if !ok {
+		return fmt.Errorf("%w: %s", core.ErrUnknownChainType, chainType)
+	}
+	return enc.Encode(fields)
+}
+
+// chainsFromFlags builds one chain per --name flag, taking the value at the same position from each other chain flag
+func chainsFromFlags(ctx *cli.Context) []config.RawChainConfig {
+	names := ctx.StringSlice(config.ChainNameFlag.Name)
+	chains := make([]config.RawChainConfig, len(names))
+
+	valueAt := func(flag string, i int) string {
+		values := ctx.StringSlice(flag)
+		if i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+
+	for i, name := range names {
+		chains[i] = config.RawChainConfig{
+			Name:     name,
+			Type:     valueAt(config.ChainTypeFlag.Name, i),
+			Id:       valueAt(config.ChainIdFlag.Name, i),
+			Endpoint: valueAt(config.ChainEndpointFlag.Name, i),
+			From:     valueAt(config.ChainFromFlag.Name, i),
+			Opts:     map[string]string{},
+		}
+		if chains[i].Type == config.EthereumType {
+			for _, flag := range []string{
+				config.BridgeAddressFlag.Name,
+				config.Erc20HandlerAddressFlag.Name,
+				config.Erc721HandlerAddressFlag.Name,
+				config.GenericHandlerAddressFlag.Name,
+			} {
+				if value := valueAt(flag, i); value != "" {
+					chains[i].Opts[flag] = value
+				}
+			}
+		}
+	}
+	return chains
+}
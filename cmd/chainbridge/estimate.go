@@ -0,0 +1,180 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/chains/ethereum"
+	"github.com/ChainSafe/ChainBridge/config"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	log "github.com/ChainSafe/log15"
+	"github.com/urfave/cli/v2"
+)
+
+var oracleTimeout = 10 * time.Second
+
+// handleEstimateCmd prints the cost of executing a deposit on the destination chain in gwei and USD
+func handleEstimateCmd(ctx *cli.Context, _ *dataHandler) error {
+	cfg, err := config.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	source, dest, err := selectChains(cfg, ctx.Int(config.SourceChainFlag.Name), ctx.Int(config.DestChainFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	estimator, err := ethereum.NewEstimator(source, dest, log.Root())
+	if err != nil {
+		return err
+	}
+	defer estimator.Close()
+
+	nonce := msg.Nonce(ctx.Uint64(config.NonceFlag.Name))
+	_, cost, err := estimator.EstimateDeposit(nonce)
+	if err != nil {
+		return fmt.Errorf("failed to estimate deposit %d: %w", nonce, err)
+	}
+
+	fmt.Printf("Estimated cost of executing deposit %d from chain %d on chain %d: %s gwei\n", nonce, source.Id, dest.Id, weiToGwei(cost).Text('f', 9))
+
+	price, err := fetchUsdPrice(ctx.String(config.PriceOracleFlag.Name))
+	if err != nil {
+		log.Warn("Unable to fetch token price, skipping USD estimate", "err", err)
+		return nil
+	}
+	fmt.Printf("Estimated cost in USD: $%s\n", weiToUsd(cost, price).Text('f', 2))
+	return nil
+}
+
+// selectChains returns the source and destination ethereum chain configs. If destId is negative
+// the destination is the only configured chain other than the source.
+func selectChains(cfg *config.Config, sourceId, destId int) (*core.ChainConfig, *core.ChainConfig, error) {
+	var source, dest *core.ChainConfig
+	var others []*core.ChainConfig
+	types := make(map[msg.ChainId]string)
+	for _, chain := range cfg.Chains {
+		chainConfig, err := newChainConfig(chain)
+		if err != nil {
+			return nil, nil, err
+		}
+		id := int(chainConfig.Id)
+		types[chainConfig.Id] = chain.Type
+
+		switch {
+		case id == sourceId:
+			source = chainConfig
+		case id == destId:
+			dest = chainConfig
+		default:
+			others = append(others, chainConfig)
+		}
+	}
+
+	if source == nil {
+		return nil, nil, fmt.Errorf("source chain %d not found in config", sourceId)
+	}
+	if dest == nil {
+		if destId >= 0 {
+			return nil, nil, fmt.Errorf("destination chain %d not found in config", destId)
+		}
+		if len(others) != 1 {
+			return nil, nil, errors.New("unable to determine destination chain, specify --dest-chain")
+		}
+		dest = others[0]
+	}
+
+	for _, chain := range []*core.ChainConfig{source, dest} {
+		if types[chain.Id] != config.EthereumType {
+			return nil, nil, fmt.Errorf("only ethereum chains are supported, chain %d is %s", chain.Id, types[chain.Id])
+		}
+	}
+	return source, dest, nil
+}
+
+// selectChain returns the config of the ethereum chain with id
+func selectChain(cfg *config.Config, id int) (*core.ChainConfig, error) {
+	for _, chain := range cfg.Chains {
+		chainConfig, err := newChainConfig(chain)
+		if err != nil {
+			return nil, err
+		}
+		if int(chainConfig.Id) != id {
+			continue
+		}
+		if chain.Type != config.EthereumType {
+			return nil, fmt.Errorf("only ethereum chains are supported, chain %d is %s", id, chain.Type)
+		}
+		return chainConfig, nil
+	}
+	return nil, fmt.Errorf("chain %d not found in config", id)
+}
+
+// newChainConfig builds the config passed to a read-only chain connection
+func newChainConfig(chain config.RawChainConfig) (*core.ChainConfig, error) {
+	id, err := strconv.Atoi(chain.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &core.ChainConfig{
+		Name:     chain.Name,
+		Id:       msg.ChainId(id),
+		Endpoint: chain.Endpoint,
+		From:     chain.From,
+		Opts:     chain.Opts,
+	}, nil
+}
+
+// fetchUsdPrice queries an oracle returning prices in the CoinGecko simple price format, {"<token>":{"usd":<price>}}
+func fetchUsdPrice(url string) (*big.Float, error) {
+	client := &http.Client{Timeout: oracleTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price oracle returned status %d", resp.StatusCode)
+	}
+
+	var prices map[string]map[string]json.Number
+	err = json.NewDecoder(resp.Body).Decode(&prices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode price oracle response: %w", err)
+	}
+	if len(prices) != 1 {
+		return nil, fmt.Errorf("expected a price for one token, got %d", len(prices))
+	}
+	for _, price := range prices {
+		usd, ok := price["usd"]
+		if !ok {
+			return nil, errors.New("price oracle response has no usd price")
+		}
+		f, ok := new(big.Float).SetString(usd.String())
+		if !ok {
+			return nil, fmt.Errorf("invalid usd price: %s", usd)
+		}
+		return f, nil
+	}
+	return nil, nil
+}
+
+func weiToGwei(wei *big.Int) *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9))
+}
+
+func weiToUsd(wei *big.Int, price *big.Float) *big.Float {
+	eth := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+	return eth.Mul(eth, price)
+}
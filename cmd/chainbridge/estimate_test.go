@@ -0,0 +1,95 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/config"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchUsdPrice(t *testing.T) {
+	oracle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ethereum":{"usd":2500.5}}`)
+	}))
+	defer oracle.Close()
+
+	price, err := fetchUsdPrice(oracle.URL)
+	require.NoError(t, err)
+	require.Equal(t, "2500.50", price.Text('f', 2))
+
+	// 0.002 ETH
+	cost := big.NewInt(2000000000000000)
+	require.Equal(t, "2000000.000000000", weiToGwei(cost).Text('f', 9))
+	require.Equal(t, "5.00", weiToUsd(cost, price).Text('f', 2))
+}
+
+func TestFetchUsdPrice_Invalid(t *testing.T) {
+	for _, body := range []string{`{}`, `{"ethereum":{"eur":1}}`, `not json`} {
+		oracle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		_, err := fetchUsdPrice(oracle.URL)
+		oracle.Close()
+		require.Error(t, err, body)
+	}
+
+	oracle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer oracle.Close()
+	_, err := fetchUsdPrice(oracle.URL)
+	require.Error(t, err)
+}
+
+func TestSelectChains(t *testing.T) {
+	cfg := &config.Config{Chains: []config.RawChainConfig{
+		{Name: "eth", Type: config.EthereumType, Id: "0"},
+		{Name: "goerli", Type: config.EthereumType, Id: "1"},
+	}}
+
+	// The destination defaults to the only other chain
+	source, dest, err := selectChains(cfg, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, msg.ChainId(0), source.Id)
+	require.Equal(t, msg.ChainId(1), dest.Id)
+
+	_, _, err = selectChains(cfg, 2, -1)
+	require.Error(t, err)
+
+	cfg.Chains = append(cfg.Chains, config.RawChainConfig{Name: "sub", Type: config.SubstrateType, Id: "2"})
+	_, _, err = selectChains(cfg, 0, -1)
+	require.Error(t, err)
+
+	source, dest, err = selectChains(cfg, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, msg.ChainId(1), source.Id)
+	require.Equal(t, msg.ChainId(0), dest.Id)
+
+	_, _, err = selectChains(cfg, 0, 2)
+	require.Error(t, err)
+}
+
+func TestSelectChain(t *testing.T) {
+	cfg := &config.Config{Chains: []config.RawChainConfig{
+		{Name: "eth", Type: config.EthereumType, Id: "0"},
+		{Name: "sub", Type: config.SubstrateType, Id: "1"},
+	}}
+
+	chain, err := selectChain(cfg, 0)
+	require.NoError(t, err)
+	require.Equal(t, "eth", chain.Name)
+
+	_, err = selectChain(cfg, 1)
+	require.Error(t, err)
+
+	_, err = selectChain(cfg, 2)
+	require.Error(t, err)
+}
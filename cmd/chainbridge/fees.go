@@ -0,0 +1,114 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/chains/ethereum"
+	"github.com/ChainSafe/ChainBridge/config"
+	"github.com/ChainSafe/ChainBridge/feelog"
+	log "github.com/ChainSafe/log15"
+	"github.com/urfave/cli/v2"
+)
+
+// handleFeesCmd prints the relay fees claimable by the from address of a chain
+func handleFeesCmd(ctx *cli.Context, _ *dataHandler) error {
+	if !ctx.IsSet(config.ChainFlag.Name) {
+		return fmt.Errorf("required flag %q not set", config.ChainFlag.Name)
+	}
+	cfg, err := config.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	chain, err := selectChain(cfg, ctx.Int(config.ChainFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	fees, err := ethereum.QueryClaimableFees(chain, log.Root())
+	if err != nil {
+		return fmt.Errorf("failed to query claimable fees: %w", err)
+	}
+
+	fmt.Printf("Claimable fees for %s on chain %d: %s wei (%s gwei)\n", chain.From, chain.Id, fees, weiToGwei(fees).Text('f', 9))
+	return nil
+}
+
+// handleFeesReportCmd prints the total, average and highest fee of the proposal transactions in a fee log
+func handleFeesReportCmd(ctx *cli.Context, _ *dataHandler) error {
+	path := ctx.String(config.FeeLogFlag.Name)
+	if path == "" {
+		return errors.New("a fee log must be given with --feelog")
+	}
+	from, err := parseReportDate(ctx.String(config.ReportFromFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	to, err := parseReportDate(ctx.String(config.ReportToFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	records, err := feelog.Read(path, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to read fee log: %w", err)
+	}
+	report := feelog.Summarize(records)
+	fmt.Printf("Proposal transactions: %d\n", report.Count)
+	fmt.Printf("Total spent: %s ETH\n", feelog.WeiToEth(report.TotalWei).Text('f', 18))
+	fmt.Printf("Average per transaction: %s ETH\n", feelog.WeiToEth(report.AverageWei).Text('f', 18))
+	if r := report.MostExpensive; r != nil {
+		fmt.Printf("Most expensive: %s ETH for %s of deposit %d from chain %d on %s (tx %s, %d gas at %s gwei)\n",
+			feelog.WeiToEth(r.TxFeeWei).Text('f', 18), r.Method, r.DepositNonce, r.Source, r.Chain, r.TxHash.Hex(), r.GasUsed, weiToGwei(r.GasPrice).Text('f', 9))
+	}
+	return nil
+}
+
+// handleGasReportCmd prints the average gas used to execute proposals for each resource ID in a fee log, highest first
+func handleGasReportCmd(ctx *cli.Context, _ *dataHandler) error {
+	path := ctx.String(config.FeeLogFlag.Name)
+	if path == "" {
+		return errors.New("a fee log must be given with --feelog")
+	}
+	from, err := parseReportDate(ctx.String(config.ReportFromFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	to, err := parseReportDate(ctx.String(config.ReportToFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	records, err := feelog.Read(path, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to read fee log: %w", err)
+	}
+	var averages feelog.GasAverages
+	for _, r := range records {
+		averages.Add(r)
+	}
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "RESOURCE ID\tEXECUTIONS\tAVERAGE GAS")
+	for _, g := range averages.Averages() {
+		fmt.Fprintf(out, "0x%s\t%d\t%.0f\n", g.ResourceId, g.Count, g.AverageGas)
+	}
+	return out.Flush()
+}
+
+// parseReportDate parses a YYYY-MM-DD date in UTC or an RFC3339 time. An empty value is the zero time.
+func parseReportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
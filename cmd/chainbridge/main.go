@@ -12,14 +12,23 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 
 	"strconv"
-
-	"github.com/ChainSafe/ChainBridge/chains/ethereum"
-	"github.com/ChainSafe/ChainBridge/chains/substrate"
+	"syscall"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/alerts"
+	"github.com/ChainSafe/ChainBridge/cloudevents"
+	// Chain packages register their chain types with core
+	_ "github.com/ChainSafe/ChainBridge/chains/ethereum"
+	_ "github.com/ChainSafe/ChainBridge/chains/substrate"
 	"github.com/ChainSafe/ChainBridge/config"
-	"github.com/ChainSafe/chainbridge-utils/core"
-	"github.com/ChainSafe/chainbridge-utils/metrics/health"
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/ChainBridge/kafka"
+	"github.com/ChainSafe/ChainBridge/metrics/health"
+	"github.com/ChainSafe/ChainBridge/profile"
+	"github.com/ChainSafe/ChainBridge/tracing"
 	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	log "github.com/ChainSafe/log15"
@@ -36,8 +45,12 @@ var cliFlags = []cli.Flag{
 	config.BlockstorePathFlag,
 	config.FreshStartFlag,
 	config.LatestBlockFlag,
+	config.StartTimeFlag,
+	config.AllowChainIdMismatchFlag,
 	config.MetricsFlag,
 	config.MetricsPort,
+	config.ProfileFlag,
+	config.ProfileDurationFlag,
 }
 
 var generateFlags = []cli.Flag{
@@ -97,6 +110,213 @@ var accountCommand = cli.Command{
 	},
 }
 
+var generateConfigFlags = []cli.Flag{
+	config.NonInteractiveFlag,
+	config.OutputFlag,
+	config.ChainNameFlag,
+	config.ChainTypeFlag,
+	config.ChainIdFlag,
+	config.ChainEndpointFlag,
+	config.ChainFromFlag,
+	config.BridgeAddressFlag,
+	config.Erc20HandlerAddressFlag,
+	config.Erc721HandlerAddressFlag,
+	config.GenericHandlerAddressFlag,
+}
+
+var configCommand = cli.Command{
+	Name:  "config",
+	Usage: "manage bridge configuration",
+	Description: "The config command is used to create bridge configuration files.\n" +
+		"\tTo interactively generate a config: chainbridge config generate\n" +
+		"\tTo generate a config from flags: chainbridge config generate --non-interactive --name eth --type ethereum ...\n" +
+		"\tTo list the opts of a chain type: chainbridge config schema --chain-type ethereum",
+	Subcommands: []*cli.Command{
+		{
+			Action: wrapHandler(handleGenerateConfigCmd),
+			Name:   "generate",
+			Usage:  "generate a bridge config",
+			Flags:  generateConfigFlags,
+			Description: "The generate subcommand prompts for each required field per chain and writes the resulting JSON config.\n" +
+				"\tUse --output to write to a file instead of stdout.\n" +
+				"\tUse --non-interactive to skip prompts and read values from the chain flags, repeating them once per chain.",
+		},
+		{
+			Action: wrapHandler(handleConfigSchemaCmd),
+			Name:   "schema",
+			Usage:  "print the opts accepted by each chain type",
+			Flags:  []cli.Flag{config.SchemaChainTypeFlag},
+			Description: "The schema subcommand prints the opts keys of each chain type as JSON, with their type, default and whether they are required.\n" +
+				"\tUse --chain-type to print a single chain type.",
+		},
+	},
+}
+
+var estimateFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	config.SourceChainFlag,
+	config.DestChainFlag,
+	config.NonceFlag,
+	config.PriceOracleFlag,
+}
+
+var estimateCommand = cli.Command{
+	Action: wrapHandler(handleEstimateCmd),
+	Name:   "estimate",
+	Usage:  "estimate the cost of executing a deposit",
+	Flags:  estimateFlags,
+	Description: "The estimate command prints the cost of executing a deposit on its destination chain in gwei and USD.\n" +
+		"\tNo keystore is required, the execution is simulated from the relayer address in the config.\n" +
+		"\tTo estimate deposit 1 made on chain 0: chainbridge estimate --source-chain 0 --nonce 1\n" +
+		"\tUse --dest-chain when more than two chains are configured and --price-oracle to change the USD price source.",
+}
+
+var queryFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	config.SourceChainFlag,
+	config.DestChainFlag,
+	config.NonceFlag,
+}
+
+var queryCommand = cli.Command{
+	Name:  "query",
+	Usage: "query deposits and proposals",
+	Description: "The query command reads the state of deposits and proposals from the bridge contracts.\n" +
+		"\tNo keystore is required.\n" +
+		"\tTo show deposit 1 made on chain 0: chainbridge query deposit --source-chain 0 --nonce 1\n" +
+		"\tTo show the status of its proposal on the destination: chainbridge query proposal --source-chain 0 --nonce 1",
+	Subcommands: []*cli.Command{
+		{
+			Action: wrapHandler(handleQueryDepositCmd),
+			Name:   "deposit",
+			Usage:  "show a deposit record",
+			Flags:  queryFlags,
+			Description: "The deposit subcommand prints the data of a deposit stored by the source chain's bridge.\n" +
+				"\tUse --dest-chain when more than two chains are configured.",
+		},
+		{
+			Action: wrapHandler(handleQueryProposalCmd),
+			Name:   "proposal",
+			Usage:  "show the status of a proposal",
+			Flags:  queryFlags,
+			Description: "The proposal subcommand prints the status of the proposal for a deposit on the destination chain.\n" +
+				"\tStatuses are inactive, active, passed, executed and cancelled.\n" +
+				"\tUse --dest-chain when more than two chains are configured.",
+		},
+	},
+}
+
+var feesFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	config.ChainFlag,
+}
+
+var feesReportFlags = []cli.Flag{
+	config.FeeLogFlag,
+	config.ReportFromFlag,
+	config.ReportToFlag,
+}
+
+var feesCommand = cli.Command{
+	Action: wrapHandler(handleFeesCmd),
+	Name:   "fees",
+	Usage:  "show claimable relay fees",
+	Flags:  feesFlags,
+	Description: "The fees command prints the relay fees the from address can claim from the bridge, without claiming them.\n" +
+		"\tNo keystore is required. The bridge must support getAvailableFees.\n" +
+		"\tTo show the fees claimable on chain 0: chainbridge fees --chain 0",
+	Subcommands: []*cli.Command{
+		{
+			Action: wrapHandler(handleFeesReportCmd),
+			Name:   "report",
+			Usage:  "summarize transaction fees paid",
+			Flags:  feesReportFlags,
+			Description: "The report subcommand totals the fees paid for proposal transactions recorded in a fee log.\n" +
+				"\tTo report the fees paid in January: chainbridge fees report --feelog fees.jsonl --from 2024-01-01 --to 2024-02-01",
+		},
+	},
+}
+
+var gasReportCommand = cli.Command{
+	Action: wrapHandler(handleGasReportCmd),
+	Name:   "gas-report",
+	Usage:  "summarize gas used per resource ID",
+	Flags:  feesReportFlags,
+	Description: "The gas-report command prints the average gas used by the executeProposal transactions recorded in a fee log\n" +
+		"\tfor each resource ID, highest first.\n" +
+		"\tTo report the gas used in January: chainbridge gas-report --feelog fees.jsonl --from 2024-01-01 --to 2024-02-01",
+}
+
+var blockstoreFlags = []cli.Flag{
+	config.BlockstorePathFlag,
+	config.BlockstoreFormatFlag,
+}
+
+var blockstoreMigrateFlags = []cli.Flag{
+	config.MigrateFromFlag,
+	config.MigrateToFlag,
+	config.MigrateFromPathFlag,
+	config.MigrateToPathFlag,
+	config.DryRunFlag,
+}
+
+var blockstoreCheckpointFlags = []cli.Flag{
+	config.BlockstorePathFlag,
+	config.CheckpointLabelFlag,
+}
+
+var blockstoreCommand = cli.Command{
+	Name:  "blockstore",
+	Usage: "export, import and checkpoint blockstores",
+	Description: "The blockstore command copies the latest block stored for each chain and relayer, for auditing or moving relayers.\n" +
+		"\tRows are chainId,relayerAddress,blockNumber,timestamp where the timestamp is when the block was stored.\n" +
+		"\tTo export the default blockstore: chainbridge blockstore export --format csv > blocks.csv\n" +
+		"\tTo import it into another directory: chainbridge blockstore import --blockstore path/to/dir blocks.csv\n" +
+		"\tTo migrate it to another backend: chainbridge blockstore migrate --from file --to <backend>\n" +
+		"\tTo checkpoint it before an upgrade: chainbridge blockstore checkpoint --label pre-upgrade",
+	Subcommands: []*cli.Command{
+		{
+			Action:      wrapHandler(handleBlockstoreExportCmd),
+			Name:        "export",
+			Usage:       "export a blockstore",
+			Flags:       blockstoreFlags,
+			Description: "The export subcommand writes every block file in the blockstore directory to stdout.",
+		},
+		{
+			Action: wrapHandler(handleBlockstoreImportCmd),
+			Name:   "import",
+			Usage:  "import a blockstore",
+			Flags:  blockstoreFlags,
+			Description: "The import subcommand stores the exported blocks in the blockstore directory, replacing existing ones.\n" +
+				"\tThe blocks are read from the given file, or from stdin if none is given.",
+		},
+		{
+			Action: wrapHandler(handleBlockstoreMigrateCmd),
+			Name:   "migrate",
+			Usage:  "migrate a blockstore to another backend",
+			Flags:  blockstoreMigrateFlags,
+			Description: "The migrate subcommand copies every block from one blockstore backend to another, replacing existing ones.\n" +
+				"\tWith --dry-run the blocks are written to stdout in csv format instead.",
+		},
+		{
+			Action: wrapHandler(handleBlockstoreCheckpointCmd),
+			Name:   "checkpoint",
+			Usage:  "checkpoint a blockstore",
+			Flags:  blockstoreCheckpointFlags,
+			Description: "The checkpoint subcommand copies every block file in the blockstore directory to <chainId>_<relayer>_<label>.bak,\n" +
+				"\treplacing an earlier checkpoint with the same label.",
+		},
+		{
+			Action: wrapHandler(handleBlockstoreRestoreCmd),
+			Name:   "restore",
+			Usage:  "restore a blockstore checkpoint",
+			Flags:  blockstoreCheckpointFlags,
+			Description: "The restore subcommand replaces the block files in the blockstore directory with the checkpoint with the label.\n" +
+				"\tRelayers must be stopped first, or they will overwrite the restored blocks.",
+		},
+	},
+}
+
 var (
 	Version = "0.0.1"
 )
@@ -112,6 +332,12 @@ func init() {
 	app.EnableBashCompletion = true
 	app.Commands = []*cli.Command{
 		&accountCommand,
+		&configCommand,
+		&estimateCommand,
+		&queryCommand,
+		&feesCommand,
+		&gasReportCommand,
+		&blockstoreCommand,
 	}
 
 	app.Flags = append(app.Flags, cliFlags...)
@@ -165,28 +391,88 @@ func run(ctx *cli.Context) error {
 		ks = cfg.KeystorePath
 	}
 
+	var startTime time.Time
+	if s := ctx.String(config.StartTimeFlag.Name); s != "" {
+		if ctx.Bool(config.LatestBlockFlag.Name) {
+			return fmt.Errorf("--%s and --%s cannot be used together", config.LatestBlockFlag.Name, config.StartTimeFlag.Name)
+		}
+		startTime, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %w", config.StartTimeFlag.Name, err)
+		}
+	}
+
 	// Used to signal core shutdown due to fatal error
 	sysErr := make(chan error)
 	c := core.NewCore(sysErr)
 
+	if cfg.AlertWebhook != "" {
+		notifier, err := alerts.NewNotifier(cfg.AlertProvider, cfg.AlertWebhook, cfg.AlertMinLevel)
+		if err != nil {
+			return err
+		}
+		c.SetNotifier(notifier)
+	}
+
+	if cfg.TracingBackend != "" {
+		sampleRate := tracing.DefaultSampleRate
+		if cfg.TracingSampleRate != nil {
+			sampleRate = *cfg.TracingSampleRate
+		}
+		tp, err := tracing.Setup(cfg.TracingBackend, cfg.JaegerEndpoint, sampleRate)
+		if err != nil {
+			return err
+		}
+		c.SetTracerProvider(tp)
+	}
+
+	if len(cfg.KafkaBrokers) != 0 {
+		producer := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaTLSEnabled, log.Root().New("system", "kafka"))
+		defer producer.Close()
+		c.AddSink(producer)
+	}
+
+	if cfg.CloudEventsEndpoint != "" {
+		bridges := make(map[msg.ChainId]string)
+		for _, chain := range cfg.Chains {
+			id, err := strconv.Atoi(chain.Id)
+			if err != nil {
+				return err
+			}
+			bridges[msg.ChainId(id)] = chain.Opts["bridge"]
+		}
+		publisher, err := cloudevents.NewPublisher(cfg.CloudEventsEndpoint, cloudevents.NewAdapter(bridges), log.Root().New("system", "cloudevents"))
+		if err != nil {
+			return err
+		}
+		defer publisher.Close()
+		c.AddSink(publisher)
+	}
+
+	if ctx.Bool(config.MetricsFlag.Name) {
+		c.EnableChainMetrics()
+	}
+
 	for _, chain := range cfg.Chains {
 		chainId, errr := strconv.Atoi(chain.Id)
 		if errr != nil {
 			return errr
 		}
 		chainConfig := &core.ChainConfig{
-			Name:           chain.Name,
-			Id:             msg.ChainId(chainId),
-			Endpoint:       chain.Endpoint,
-			From:           chain.From,
-			KeystorePath:   ks,
-			Insecure:       insecure,
-			BlockstorePath: ctx.String(config.BlockstorePathFlag.Name),
-			FreshStart:     ctx.Bool(config.FreshStartFlag.Name),
-			LatestBlock:    ctx.Bool(config.LatestBlockFlag.Name),
-			Opts:           chain.Opts,
+			Type:                 chain.Type,
+			Name:                 chain.Name,
+			Id:                   msg.ChainId(chainId),
+			Endpoint:             chain.Endpoint,
+			From:                 chain.From,
+			KeystorePath:         ks,
+			Insecure:             insecure,
+			BlockstorePath:       ctx.String(config.BlockstorePathFlag.Name),
+			FreshStart:           ctx.Bool(config.FreshStartFlag.Name),
+			LatestBlock:          ctx.Bool(config.LatestBlockFlag.Name),
+			StartTime:            startTime,
+			AllowChainIdMismatch: ctx.Bool(config.AllowChainIdMismatchFlag.Name),
+			Opts:                 chain.Opts,
 		}
-		var newChain core.Chain
 		var m *metrics.ChainMetrics
 
 		logger := log.Root().New("chain", chainConfig.Name)
@@ -195,19 +481,23 @@ func run(ctx *cli.Context) error {
 			m = metrics.NewChainMetrics(chain.Name)
 		}
 
-		if chain.Type == "ethereum" {
-			newChain, err = ethereum.InitializeChain(chainConfig, logger, sysErr, m)
-		} else if chain.Type == "substrate" {
-			newChain, err = substrate.InitializeChain(chainConfig, logger, sysErr, m)
-		} else {
-			return errors.New("unrecognized Chain Type")
+		newChain, err := core.NewChain(chain.Type, chainConfig, logger, sysErr, m)
+		if err != nil {
+			return err
 		}
+		c.Register(newChain)
 
+	}
+
+	routes, err := cfg.Routes.Routes()
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		err = c.SetRoute(route.Source, route.Destination, route.Via)
 		if err != nil {
 			return err
 		}
-		c.AddChain(newChain)
-
 	}
 
 	// Start prometheus and health server
@@ -226,6 +516,8 @@ func run(ctx *cli.Context) error {
 		go func() {
 			http.Handle("/metrics", promhttp.Handler())
 			http.HandleFunc("/health", h.HealthStatus)
+			http.HandleFunc("/status", health.StatusHandler(c))
+			http.HandleFunc("/chains/", health.QueueHandler(c))
 			err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
 			if errors.Is(err, http.ErrServerClosed) {
 				log.Info("Health status server is shutting down", err)
@@ -235,7 +527,39 @@ func run(ctx *cli.Context) error {
 		}()
 	}
 
+	if dir := ctx.String(config.ProfileFlag.Name); dir != "" {
+		p := profile.NewProfiler(dir, ctx.Duration(config.ProfileDurationFlag.Name), log.Root())
+		err = p.Start()
+		if err != nil {
+			return err
+		}
+		defer p.Stop()
+	}
+
+	logConfigChanges(ctx, cfg)
 	c.Start()
 
 	return nil
 }
+
+// logConfigChanges loads the config file again each time SIGHUP is received and logs what changed since it was last
+// loaded. Chains reload their gas config files and ENS names on SIGHUP, other changes take effect on restart.
+func logConfigChanges(ctx *cli.Context, cfg *config.Config) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			reloaded, err := config.GetConfig(ctx)
+			if err != nil {
+				log.Error("Unable to reload config", "err", err)
+				continue
+			}
+			changes := config.Diff(cfg, reloaded)
+			if len(changes) == 0 {
+				log.Info("Config reloaded, no changes")
+			}
+			config.LogChanges(log.Root(), changes)
+			cfg = reloaded
+		}
+	}()
+}
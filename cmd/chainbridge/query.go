@@ -0,0 +1,70 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ChainSafe/ChainBridge/chains/ethereum"
+	"github.com/ChainSafe/ChainBridge/config"
+	log "github.com/ChainSafe/log15"
+	"github.com/urfave/cli/v2"
+)
+
+// handleQueryDepositCmd prints the deposit record stored by the source chain's bridge
+func handleQueryDepositCmd(ctx *cli.Context, _ *dataHandler) error {
+	cfg, err := config.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	source, dest, err := selectChains(cfg, ctx.Int(config.SourceChainFlag.Name), ctx.Int(config.DestChainFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	reader, err := ethereum.NewReader(source, log.Root())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	nonce := ctx.Uint64(config.NonceFlag.Name)
+	record, err := reader.GetDepositRecord(dest.Id, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to query deposit %d: %w", nonce, err)
+	}
+
+	fmt.Printf("Deposit %d from chain %d to chain %d: 0x%s\n", nonce, source.Id, dest.Id, hex.EncodeToString(record.Data))
+	return nil
+}
+
+// handleQueryProposalCmd prints the status of the proposal for a deposit on the destination chain
+func handleQueryProposalCmd(ctx *cli.Context, _ *dataHandler) error {
+	cfg, err := config.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	source, dest, err := selectChains(cfg, ctx.Int(config.SourceChainFlag.Name), ctx.Int(config.DestChainFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	reader, err := ethereum.NewReader(dest, log.Root())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	nonce := ctx.Uint64(config.NonceFlag.Name)
+	status, err := reader.GetProposalStatus(source.Id, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to query proposal %d: %w", nonce, err)
+	}
+
+	fmt.Printf("Proposal for deposit %d from chain %d on chain %d: %s\n", nonce, source.Id, dest.Id, status)
+	return nil
+}
@@ -6,8 +6,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli/v2"
@@ -16,10 +18,26 @@ import (
 const DefaultConfigPath = "./config.json"
 const DefaultKeystorePath = "./keys"
 const DefaultBlockTimeout = int64(180) // 3 minutes
+const DefaultProfileDuration = 30 * time.Second
+const DefaultPriceOracle = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd"
 
 type Config struct {
-	Chains       []RawChainConfig `json:"chains"`
-	KeystorePath string           `json:"keystorePath,omitempty"`
+	Chains        []RawChainConfig `json:"chains"`
+	KeystorePath  string           `json:"keystorePath,omitempty"`
+	AlertWebhook  string           `json:"alertWebhook,omitempty"`  // Slack webhook URL or PagerDuty routing key, alerts are disabled if empty
+	AlertProvider string           `json:"alertProvider,omitempty"` // Service alerts are sent to, "slack" or "pagerduty"
+	AlertMinLevel string           `json:"alertMinLevel,omitempty"` // Least severe level of alert sent, defaults to ERROR
+	Routes        RouteMap         `json:"routes,omitempty"`        // Hops of messages to chains the relayer is not connected to
+
+	TracingBackend    string   `json:"tracingBackend,omitempty"`    // Backend traces are exported to, "jaeger". Tracing is disabled if empty
+	JaegerEndpoint    string   `json:"jaegerEndpoint,omitempty"`    // Jaeger collector HTTP endpoint, eg. http://localhost:14268/api/traces
+	TracingSampleRate *float64 `json:"tracingSampleRate,omitempty"` // Fraction of traces recorded, defaults to 0.1
+
+	KafkaBrokers    []string `json:"kafkaBrokers,omitempty"`    // Brokers routed messages are published to, publishing is disabled if empty
+	KafkaTopic      string   `json:"kafkaTopic,omitempty"`      // Topic routed messages are published to
+	KafkaTLSEnabled bool     `json:"kafkaTLSEnabled,omitempty"` // Connect to the brokers with TLS
+
+	CloudEventsEndpoint string `json:"cloudEventsEndpoint,omitempty"` // HTTP endpoint routed messages are sent to as CloudEvents, disabled if empty
 }
 
 // RawChainConfig is parsed directly from the config file and should be using to construct the core.ChainConfig
@@ -66,6 +84,27 @@ func (c *Config) ToJSON(file string) *os.File {
 }
 
 func (c *Config) validate() error {
+	if c.AlertWebhook != "" && c.AlertProvider == "" {
+		return fmt.Errorf("required field alertProvider empty when alertWebhook is set")
+	}
+	if c.TracingBackend != "" && c.TracingBackend != "jaeger" {
+		return fmt.Errorf("unsupported tracingBackend %s", c.TracingBackend)
+	}
+	if c.TracingBackend == "jaeger" && c.JaegerEndpoint == "" {
+		return fmt.Errorf("required field jaegerEndpoint empty when tracingBackend is jaeger")
+	}
+	if len(c.KafkaBrokers) != 0 && c.KafkaTopic == "" {
+		return fmt.Errorf("required field kafkaTopic empty when kafkaBrokers is set")
+	}
+	if c.CloudEventsEndpoint != "" {
+		u, err := url.Parse(c.CloudEventsEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("cloudEventsEndpoint must be an http or https URL")
+		}
+	}
+	if c.TracingSampleRate != nil && (*c.TracingSampleRate < 0 || *c.TracingSampleRate > 1) {
+		return fmt.Errorf("tracingSampleRate must be between 0 and 1")
+	}
 	for _, chain := range c.Chains {
 		if chain.Type == "" {
 			return fmt.Errorf("required field chain.Type empty for chain %s", chain.Id)
@@ -83,6 +122,36 @@ func (c *Config) validate() error {
 			return fmt.Errorf("required field chain.From empty for chain %s", chain.Id)
 		}
 	}
+	err := validateSignedMessages(c.Chains)
+	if err != nil {
+		return err
+	}
+	_, err = c.Routes.Routes()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateSignedMessages rejects requireSignedMessages on a chain if another chain routes it messages without
+// signing them. Substrate listeners do not sign messages, nor do ethereum listeners whose key is held by Fireblocks.
+func validateSignedMessages(chains []RawChainConfig) error {
+	for _, dest := range chains {
+		if dest.Opts["requireSignedMessages"] != "true" {
+			continue
+		}
+		for _, src := range chains {
+			if src.Id == dest.Id {
+				continue
+			}
+			if src.Type == "substrate" {
+				return fmt.Errorf("requireSignedMessages cannot be set for chain %s, substrate chain %s does not sign messages", dest.Id, src.Id)
+			}
+			if src.Opts["fireblocksVaultId"] != "" {
+				return fmt.Errorf("requireSignedMessages cannot be set for chain %s, chain %s signs with fireblocksVaultId so does not sign messages", dest.Id, src.Id)
+			}
+		}
+	}
 	return nil
 }
 
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -11,6 +13,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/urfave/cli/v2"
 )
 
@@ -147,4 +150,175 @@ func TestValdiateConfig(t *testing.T) {
 	if err == nil {
 		t.Fatal("must require name field")
 	}
+
+	cfg = Config{
+		Chains:       []RawChainConfig{valid},
+		AlertWebhook: "https://hooks.slack.com/services/xxx",
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must require alertProvider field with alertWebhook")
+	}
+
+	cfg = Config{
+		Chains:         []RawChainConfig{valid},
+		TracingBackend: "jaeger",
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must require jaegerEndpoint field with the jaeger tracingBackend")
+	}
+
+	rate := 1.5
+	cfg = Config{
+		Chains:            []RawChainConfig{valid},
+		TracingBackend:    "jaeger",
+		JaegerEndpoint:    "http://localhost:14268/api/traces",
+		TracingSampleRate: &rate,
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must require tracingSampleRate between 0 and 1")
+	}
+
+	cfg = Config{
+		Chains:       []RawChainConfig{valid},
+		KafkaBrokers: []string{"localhost:9092"},
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must require kafkaTopic field with kafkaBrokers")
+	}
+
+	cfg = Config{
+		Chains:              []RawChainConfig{valid},
+		CloudEventsEndpoint: "localhost:8080",
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must require cloudEventsEndpoint to be an http URL")
+	}
+
+	signed := valid
+	signed.Opts = map[string]string{"requireSignedMessages": "true"}
+	fireblocks := valid
+	fireblocks.Id = "2"
+	fireblocks.Opts = map[string]string{"fireblocksVaultId": "3"}
+	cfg = Config{Chains: []RawChainConfig{signed, fireblocks}}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must reject requireSignedMessages with a source chain signing with fireblocks")
+	}
+
+	substrate := valid
+	substrate.Id = "2"
+	substrate.Type = "substrate"
+	cfg = Config{Chains: []RawChainConfig{signed, substrate}}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Fatal("must reject requireSignedMessages with a substrate source chain")
+	}
+}
+
+func TestBridgeConfigRoundTrip(t *testing.T) {
+	cfg := &Config{
+		Chains: []RawChainConfig{
+			{
+				Name:     "goerli",
+				Type:     "ethereum",
+				Id:       "0",
+				Endpoint: "ws://localhost:8545",
+				From:     "0xff93B45308FD417dF303D6515aB04D9e89a750Ca",
+				Opts: map[string]string{
+					"bridge":             "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B",
+					"erc20Handler":       "0x3167776db165D8eA0f51790CA2bbf44Db5105ADF",
+					"erc721Handler":      "0x3f709398808af36ADBA86ACC617FeB7F5B7B193E",
+					"genericHandler":     "0x2B6Ab4b880A45a07d83Cf4d664Df4Ab85705Bc07",
+					"gasLimit":           "1000000",
+					"maxGasPrice":        "20000000000",
+					"minGasPrice":        "0",
+					"gasMultiplier":      "1.5",
+					"http":               "false",
+					"startBlock":         "100",
+					"blockConfirmations": "10",
+					"egsApiKey":          "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx", // fake key
+					"egsSpeed":           "fast",
+				},
+			},
+			{
+				Name:     "substrate",
+				Type:     "substrate",
+				Id:       "1",
+				Endpoint: "ws://localhost:9944",
+				From:     "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY",
+				Opts: map[string]string{
+					"startBlock":      "10",
+					"useExtendedCall": "true",
+				},
+			},
+		},
+		KeystorePath: "./keys",
+	}
+
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := NewConfig()
+	err = json.Unmarshal(raw, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(res, cfg) {
+		t.Fatalf("did not match\ngot: %+v\nexpected: %+v", res, cfg)
+	}
+
+	again, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(raw, again) {
+		t.Fatalf("serialized configs differ\nfirst: %s\nsecond: %s", raw, again)
+	}
+}
+
+func TestRouteMap(t *testing.T) {
+	var cfg Config
+	err := json.Unmarshal([]byte(`{"routes": {"3->4": [{"via": 5}, {"via": 6}], "0→2": [{"via": 1}]}}`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, err := cfg.Routes.Routes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Route{
+		{Source: 0, Destination: 2, Via: []msg.ChainId{1}},
+		{Source: 3, Destination: 4, Via: []msg.ChainId{5, 6}},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, routes)
+	}
+
+	for _, invalid := range []RouteMap{
+		{"0-2": {{Via: 1}}},
+		{"0→x": {{Via: 1}}},
+		{"2→2": {{Via: 1}}},
+		{"0→2": {}},
+	} {
+		_, err = invalid.Routes()
+		if err == nil {
+			t.Fatalf("expected error for route map %v", invalid)
+		}
+	}
 }
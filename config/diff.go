@@ -0,0 +1,188 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/ChainSafe/log15"
+)
+
+// redacted replaces the values of secrets in config changes
+const redacted = "<redacted>"
+
+// ConfigChange is a value that differs between two configs. Path identifies the value, eg. "chains[1].opts.gasPrice".
+// OldValue is nil for added values and NewValue is nil for removed ones.
+type ConfigChange struct {
+	Path     string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// Diff returns the changes from old to new, sorted by path. Chains are matched by ID, a chain that is added or removed
+// is a single change of the whole chain. Webhooks and opts that look like API keys or secrets are redacted.
+func Diff(old, new *Config) []ConfigChange {
+	var changes []ConfigChange
+	add := func(path string, oldValue, newValue interface{}) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, ConfigChange{Path: path, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	// Secrets are compared before they are redacted, so a changed secret is reported without its value
+	addSecret := func(path, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, ConfigChange{Path: path, OldValue: secret(oldValue), NewValue: secret(newValue)})
+		}
+	}
+
+	add("keystorePath", optional(old.KeystorePath), optional(new.KeystorePath))
+	addSecret("alertWebhook", old.AlertWebhook, new.AlertWebhook)
+	add("alertProvider", optional(old.AlertProvider), optional(new.AlertProvider))
+	add("alertMinLevel", optional(old.AlertMinLevel), optional(new.AlertMinLevel))
+	add("tracingBackend", optional(old.TracingBackend), optional(new.TracingBackend))
+	add("jaegerEndpoint", optional(old.JaegerEndpoint), optional(new.JaegerEndpoint))
+	add("tracingSampleRate", sampleRate(old.TracingSampleRate), sampleRate(new.TracingSampleRate))
+	add("kafkaBrokers", optional(strings.Join(old.KafkaBrokers, ",")), optional(strings.Join(new.KafkaBrokers, ",")))
+	add("kafkaTopic", optional(old.KafkaTopic), optional(new.KafkaTopic))
+	add("kafkaTLSEnabled", old.KafkaTLSEnabled, new.KafkaTLSEnabled)
+	add("cloudEventsEndpoint", optional(old.CloudEventsEndpoint), optional(new.CloudEventsEndpoint))
+	for _, key := range unionKeys(routeKeys(old.Routes), routeKeys(new.Routes)) {
+		add(fmt.Sprintf("routes[%s]", key), routeValue(old.Routes, key), routeValue(new.Routes, key))
+	}
+
+	oldChains, newChains := chainsById(old.Chains), chainsById(new.Chains)
+	for _, id := range unionKeys(chainIds(oldChains), chainIds(newChains)) {
+		path := fmt.Sprintf("chains[%s]", id)
+		o, inOld := oldChains[id]
+		n, inNew := newChains[id]
+		if !inOld {
+			add(path, nil, n.Name)
+			continue
+		}
+		if !inNew {
+			add(path, o.Name, nil)
+			continue
+		}
+		add(path+".name", o.Name, n.Name)
+		add(path+".type", o.Type, n.Type)
+		add(path+".endpoint", o.Endpoint, n.Endpoint)
+		add(path+".from", o.From, n.From)
+		for _, key := range unionKeys(optKeys(o.Opts), optKeys(n.Opts)) {
+			if isSecretOpt(key) {
+				addSecret(path+".opts."+key, o.Opts[key], n.Opts[key])
+			} else {
+				add(path+".opts."+key, optValue(o.Opts, key), optValue(n.Opts, key))
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// LogChanges logs each change at INFO
+func LogChanges(logger log.Logger, changes []ConfigChange) {
+	for _, c := range changes {
+		logger.Info("Config changed", "path", c.Path, "old", c.OldValue, "new", c.NewValue)
+	}
+}
+
+// isSecretOpt returns true for opts whose values should not be logged, such as fireblocksAPIKey
+func isSecretOpt(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "key") || strings.Contains(key, "secret") || strings.Contains(key, "password")
+}
+
+// optional returns nil for an empty value, so setting or clearing a field reads as adding or removing it
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// secret returns redacted for a set value, or nil if it is empty
+func secret(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return redacted
+}
+
+// sampleRate returns nil for an unset rate, so the changes show the value rather than a pointer
+func sampleRate(rate *float64) interface{} {
+	if rate == nil {
+		return nil
+	}
+	return *rate
+}
+
+func optValue(opts map[string]string, key string) interface{} {
+	v, ok := opts[key]
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+func routeValue(routes RouteMap, key string) interface{} {
+	hops, ok := routes[key]
+	if !ok {
+		return nil
+	}
+	via := make([]string, len(hops))
+	for i, hop := range hops {
+		via[i] = fmt.Sprint(hop.Via)
+	}
+	return strings.Join(via, ",")
+}
+
+func routeKeys(routes RouteMap) []string {
+	k := make([]string, 0, len(routes))
+	for key := range routes {
+		k = append(k, key)
+	}
+	return k
+}
+
+func chainsById(chains []RawChainConfig) map[string]RawChainConfig {
+	byId := make(map[string]RawChainConfig, len(chains))
+	for _, c := range chains {
+		byId[c.Id] = c
+	}
+	return byId
+}
+
+func chainIds(chains map[string]RawChainConfig) []string {
+	ids := make([]string, 0, len(chains))
+	for id := range chains {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func optKeys(opts map[string]string) []string {
+	k := make([]string, 0, len(opts))
+	for key := range opts {
+		k = append(k, key)
+	}
+	return k
+}
+
+// unionKeys returns the keys in either a or b, sorted
+func unionKeys(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var union []string
+	for _, key := range append(a, b...) {
+		if !seen[key] {
+			seen[key] = true
+			union = append(union, key)
+		}
+	}
+	sort.Strings(union)
+	return union
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		Chains: []RawChainConfig{
+			{Name: "alice", Type: "ethereum", Id: "1", Endpoint: "ws://localhost:8545", From: "0x1", Opts: map[string]string{"gasPrice": "1000", "bridge": "0x2"}},
+		},
+		KeystorePath: "./keys",
+	}
+	new := &Config{
+		Chains: []RawChainConfig{
+			{Name: "alice", Type: "ethereum", Id: "1", Endpoint: "ws://localhost:8545", From: "0x1", Opts: map[string]string{"gasPrice": "2000", "bridge": "0x2"}},
+			{Name: "bob", Type: "ethereum", Id: "2", Endpoint: "ws://localhost:8546", From: "0x1"},
+		},
+		KeystorePath: "./keys",
+	}
+
+	expected := []ConfigChange{
+		{Path: "chains[1].opts.gasPrice", OldValue: "1000", NewValue: "2000"},
+		{Path: "chains[2]", OldValue: nil, NewValue: "bob"},
+	}
+	if changes := Diff(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, changes)
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Fatalf("expected no changes, got: %+v", changes)
+	}
+}
+
+func TestDiff_Secrets(t *testing.T) {
+	old := &Config{
+		Chains:       []RawChainConfig{{Id: "1", Opts: map[string]string{"fireblocksAPIKey": "old-key"}}},
+		AlertWebhook: "https://hooks.slack.com/services/old",
+	}
+	new := &Config{
+		Chains:       []RawChainConfig{{Id: "1", Opts: map[string]string{"fireblocksAPIKey": "new-key"}}},
+		AlertWebhook: "https://hooks.slack.com/services/new",
+	}
+
+	expected := []ConfigChange{
+		{Path: "alertWebhook", OldValue: redacted, NewValue: redacted},
+		{Path: "chains[1].opts.fireblocksAPIKey", OldValue: redacted, NewValue: redacted},
+	}
+	if changes := Diff(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, changes)
+	}
+}
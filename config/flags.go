@@ -33,7 +33,7 @@ var (
 
 	BlockstorePathFlag = &cli.StringFlag{
 		Name:  "blockstore",
-		Usage: "Specify path for blockstore",
+		Usage: "Specify path for blockstore, or :memory: to keep it in memory only",
 		Value: "", // Empty will use home dir
 	}
 
@@ -46,6 +46,16 @@ var (
 		Name:  "latest",
 		Usage: "Overrides blockstore and start block, starts from latest block",
 	}
+
+	StartTimeFlag = &cli.StringFlag{
+		Name:  "start-time",
+		Usage: "Overrides blockstore and start block, starts from the last block at or before this RFC3339 time. Ethereum chains only.",
+	}
+
+	AllowChainIdMismatchFlag = &cli.BoolFlag{
+		Name:  "allow-chain-id-mismatch",
+		Usage: "Only warn if an ethereum node's chain ID differs from its evmChainId option, for intentional overrides on private networks",
+	}
 )
 
 // Metrics flags
@@ -62,6 +72,20 @@ var (
 	}
 )
 
+// Profiling flags
+var (
+	ProfileFlag = &cli.StringFlag{
+		Name:  "profile",
+		Usage: "Directory to write a CPU and heap profile to when SIGUSR1 is received",
+	}
+
+	ProfileDurationFlag = &cli.DurationFlag{
+		Name:  "profile-duration",
+		Usage: "Length of the CPU profile recorded on SIGUSR1, used with --profile",
+		Value: DefaultProfileDuration,
+	}
+)
+
 // Generate subcommand flags
 var (
 	PasswordFlag = &cli.StringFlag{
@@ -101,3 +125,148 @@ var (
 		Usage: "Applies a predetermined test keystore to the chains.",
 	}
 )
+
+// Config generate subcommand flags
+var (
+	NonInteractiveFlag = &cli.BoolFlag{
+		Name:  "non-interactive",
+		Usage: "Skip prompts and build the config from the chain flags. Each chain flag may be repeated once per chain.",
+	}
+	OutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the generated config to. Defaults to stdout.",
+	}
+	ChainNameFlag = &cli.StringSliceFlag{
+		Name:  "name",
+		Usage: "Chain name",
+	}
+	ChainTypeFlag = &cli.StringSliceFlag{
+		Name:  "type",
+		Usage: "Chain type (ethereum/substrate)",
+	}
+	ChainIdFlag = &cli.StringSliceFlag{
+		Name:  "id",
+		Usage: "Chain ID",
+	}
+	ChainEndpointFlag = &cli.StringSliceFlag{
+		Name:  "endpoint",
+		Usage: "Chain endpoint URL",
+	}
+	ChainFromFlag = &cli.StringSliceFlag{
+		Name:  "from",
+		Usage: "Relayer address used on the chain",
+	}
+	BridgeAddressFlag = &cli.StringSliceFlag{
+		Name:  "bridge",
+		Usage: "Bridge contract address (ethereum only)",
+	}
+	Erc20HandlerAddressFlag = &cli.StringSliceFlag{
+		Name:  "erc20Handler",
+		Usage: "ERC20 handler contract address (ethereum only)",
+	}
+	Erc721HandlerAddressFlag = &cli.StringSliceFlag{
+		Name:  "erc721Handler",
+		Usage: "ERC721 handler contract address (ethereum only)",
+	}
+	GenericHandlerAddressFlag = &cli.StringSliceFlag{
+		Name:  "genericHandler",
+		Usage: "Generic handler contract address (ethereum only)",
+	}
+)
+
+// Config schema subcommand flags
+var (
+	SchemaChainTypeFlag = &cli.StringFlag{
+		Name:  "chain-type",
+		Usage: "Chain type to print the opts of (ethereum). Defaults to all chain types.",
+	}
+)
+
+// Estimate command flags
+var (
+	SourceChainFlag = &cli.IntFlag{
+		Name:     "source-chain",
+		Usage:    "ID of the chain the deposit was made on",
+		Required: true,
+	}
+	DestChainFlag = &cli.IntFlag{
+		Name:  "dest-chain",
+		Usage: "ID of the chain the deposit is executed on. Defaults to the only other configured chain.",
+		Value: -1,
+	}
+	NonceFlag = &cli.Uint64Flag{
+		Name:     "nonce",
+		Usage:    "Deposit nonce",
+		Required: true,
+	}
+	PriceOracleFlag = &cli.StringFlag{
+		Name:  "price-oracle",
+		Usage: "URL returning the USD price of the destination chain's native token, in the CoinGecko simple price format",
+		Value: DefaultPriceOracle,
+	}
+)
+
+// CsvFormat is the only format supported by the blockstore command
+const CsvFormat = "csv"
+
+// Blockstore command flags
+var (
+	BlockstoreFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Format of the exported blockstore. Only csv is supported.",
+		Value: CsvFormat,
+	}
+	MigrateFromFlag = &cli.StringFlag{
+		Name:  "from",
+		Usage: "Backend of the blockstore to migrate from. Only file is supported.",
+		Value: "file",
+	}
+	MigrateToFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "Backend of the blockstore to migrate to. Only file is supported.",
+		Value: "file",
+	}
+	MigrateFromPathFlag = &cli.StringFlag{
+		Name:  "from-path",
+		Usage: "Path of the file blockstore to migrate from",
+		Value: "", // Empty will use home dir
+	}
+	MigrateToPathFlag = &cli.StringFlag{
+		Name:  "to-path",
+		Usage: "Path of the file blockstore to migrate to",
+	}
+	DryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the blocks that would be migrated to stdout without writing them",
+	}
+	CheckpointLabelFlag = &cli.StringFlag{
+		Name:     "label",
+		Usage:    "Name of the checkpoint, letters, digits, '.', '_' and '-' only",
+		Required: true,
+	}
+)
+
+// Fee report flags
+var (
+	FeeLogFlag = &cli.StringFlag{
+		Name:  "feelog",
+		Usage: "Fee log written by a chain's feeLogPath option",
+	}
+	ReportFromFlag = &cli.StringFlag{
+		Name:  "from",
+		Usage: "Only report fees from this date (YYYY-MM-DD or RFC3339), inclusive",
+	}
+	ReportToFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "Only report fees before this date (YYYY-MM-DD or RFC3339), exclusive",
+	}
+)
+
+// Fees command flags
+var (
+	// ChainFlag is checked by the fees command itself, so its subcommands can be run without it
+	ChainFlag = &cli.IntFlag{
+		Name:  "chain",
+		Usage: "ID of the chain to query (required)",
+	}
+)
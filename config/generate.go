@@ -0,0 +1,254 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const EthereumType = "ethereum"
+const SubstrateType = "substrate"
+
+var defaultEndpoints = map[string]string{
+	EthereumType:  "ws://localhost:8545",
+	SubstrateType: "ws://localhost:9944",
+}
+
+// ethereumAddressOpts are the contract address opts prompted for ethereum chains, in prompt order
+var ethereumAddressOpts = []struct {
+	key      string
+	required bool
+}{
+	{"bridge", true},
+	{"erc20Handler", false},
+	{"erc721Handler", false},
+	{"genericHandler", false},
+}
+
+// prompter reads answers line by line, re-asking until the answer passes validation
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func (p *prompter) ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		if !p.in.Scan() {
+			if err := p.in.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+
+		answer := strings.TrimSpace(p.in.Text())
+		if answer == "" {
+			answer = def
+		}
+
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "Invalid value: %s\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// Generate interactively builds a Config, writing prompts to out and reading answers from in.
+// Empty answers accept the default shown in brackets.
+func Generate(in io.Reader, out io.Writer) (*Config, error) {
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+	cfg := NewConfig()
+
+	countStr, err := p.ask("Number of chains", "2", validatePositiveInt)
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(countStr)
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(out, "Chain %d\n", i)
+		chain, err := promptChain(p, i)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Chains = append(cfg.Chains, chain)
+	}
+
+	return cfg, cfg.validate()
+}
+
+func promptChain(p *prompter, index int) (RawChainConfig, error) {
+	chain := RawChainConfig{Opts: map[string]string{}}
+	var err error
+
+	chain.Type, err = p.ask("Chain type (ethereum/substrate)", EthereumType, validateChainType)
+	if err != nil {
+		return chain, err
+	}
+	chain.Name, err = p.ask("Chain name", fmt.Sprintf("%s-%d", chain.Type, index), validateRequired)
+	if err != nil {
+		return chain, err
+	}
+	chain.Id, err = p.ask("Chain ID", strconv.Itoa(index), validateChainId)
+	if err != nil {
+		return chain, err
+	}
+	chain.Endpoint, err = p.ask("Endpoint URL", defaultEndpoints[chain.Type], validateEndpoint)
+	if err != nil {
+		return chain, err
+	}
+	chain.From, err = p.ask("Relayer address (keystore key)", "", validateRequired)
+	if err != nil {
+		return chain, err
+	}
+
+	if chain.Type == EthereumType {
+		for _, opt := range ethereumAddressOpts {
+			validate := validateOptionalAddress
+			question := fmt.Sprintf("%s contract address (optional)", opt.key)
+			if opt.required {
+				validate = validateAddress
+				question = fmt.Sprintf("%s contract address", opt.key)
+			}
+
+			addr, err := p.ask(question, "", validate)
+			if err != nil {
+				return chain, err
+			}
+			if addr != "" {
+				chain.Opts[opt.key] = addr
+			}
+		}
+	}
+
+	return chain, nil
+}
+
+// GenerateFromChains builds a Config from pre-supplied chain values, applying the same validation as Generate
+func GenerateFromChains(chains []RawChainConfig) (*Config, error) {
+	if len(chains) == 0 {
+		return nil, errors.New("at least one chain must be provided")
+	}
+
+	cfg := NewConfig()
+	for _, chain := range chains {
+		if chain.Opts == nil {
+			chain.Opts = map[string]string{}
+		}
+		if chain.Endpoint == "" {
+			chain.Endpoint = defaultEndpoints[chain.Type]
+		}
+		if err := validateChain(chain); err != nil {
+			return nil, fmt.Errorf("invalid chain %s: %w", chain.Name, err)
+		}
+		cfg.Chains = append(cfg.Chains, chain)
+	}
+
+	return cfg, cfg.validate()
+}
+
+// Encode writes the config to w as indented JSON
+func (c *Config) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+func validateChain(chain RawChainConfig) error {
+	if err := validateChainType(chain.Type); err != nil {
+		return err
+	}
+	if err := validateChainId(chain.Id); err != nil {
+		return err
+	}
+	if err := validateEndpoint(chain.Endpoint); err != nil {
+		return err
+	}
+	if chain.Type == EthereumType {
+		for _, opt := range ethereumAddressOpts {
+			validate := validateOptionalAddress
+			if opt.required {
+				validate = validateAddress
+			}
+			if err := validate(chain.Opts[opt.key]); err != nil {
+				return fmt.Errorf("opts.%s: %w", opt.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRequired(value string) error {
+	if value == "" {
+		return errors.New("value is required")
+	}
+	return nil
+}
+
+func validatePositiveInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("%q is not a positive integer", value)
+	}
+	return nil
+}
+
+func validateChainType(value string) error {
+	if value != EthereumType && value != SubstrateType {
+		return fmt.Errorf("unrecognized chain type %q", value)
+	}
+	return nil
+}
+
+func validateChainId(value string) error {
+	if _, err := strconv.ParseUint(value, 10, 8); err != nil {
+		return fmt.Errorf("chain ID %q must be between 0 and 255", value)
+	}
+	return nil
+}
+
+func validateEndpoint(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+	default:
+		return fmt.Errorf("endpoint %q must use ws, wss, http or https", value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("endpoint %q is missing a host", value)
+	}
+	return nil
+}
+
+func validateAddress(value string) error {
+	if !common.IsHexAddress(value) {
+		return fmt.Errorf("%q is not a valid address", value)
+	}
+	return nil
+}
+
+func validateOptionalAddress(value string) error {
+	if value == "" {
+		return nil
+	}
+	return validateAddress(value)
+}
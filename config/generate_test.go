@@ -0,0 +1,141 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	input := strings.Join([]string{
+		"2", // number of chains
+		// Chain 0, accepting defaults where possible
+		"",
+		"",
+		"",
+		"",
+		"0xff93B45308FD417dF303D6515aB04D9e89a750Ca",
+		"not-an-address", // rejected, prompt is repeated
+		"0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B",
+		"0x3167776db165D8eA0f51790CA2bbf44Db5105ADF",
+		"",
+		"",
+		// Chain 1
+		"substrate",
+		"sub",
+		"1",
+		"ftp://localhost", // rejected, prompt is repeated
+		"ws://localhost:9944",
+		"5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY",
+	}, "\n")
+
+	out := &bytes.Buffer{}
+	cfg, err := Generate(strings.NewReader(input), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Config{
+		Chains: []RawChainConfig{
+			{
+				Name:     "ethereum-0",
+				Type:     "ethereum",
+				Id:       "0",
+				Endpoint: "ws://localhost:8545",
+				From:     "0xff93B45308FD417dF303D6515aB04D9e89a750Ca",
+				Opts: map[string]string{
+					"bridge":       "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B",
+					"erc20Handler": "0x3167776db165D8eA0f51790CA2bbf44Db5105ADF",
+				},
+			},
+			{
+				Name:     "sub",
+				Type:     "substrate",
+				Id:       "1",
+				Endpoint: "ws://localhost:9944",
+				From:     "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY",
+				Opts:     map[string]string{},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("did not match\ngot: %+v\nexpected: %+v", cfg, expected)
+	}
+
+	if strings.Count(out.String(), "Invalid value") != 2 {
+		t.Fatalf("expected two rejected values, prompts were:\n%s", out.String())
+	}
+
+	// The generated file should load like any other config
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	err = cfg.Encode(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+
+	var loaded Config
+	err = loadConfig(tmpFile.Name(), &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&loaded, expected) {
+		t.Fatalf("loaded config did not match\ngot: %+v\nexpected: %+v", loaded, expected)
+	}
+}
+
+func TestGenerate_UnexpectedEOF(t *testing.T) {
+	_, err := Generate(strings.NewReader("1\nethereum\n"), &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error for truncated input")
+	}
+}
+
+func TestGenerateFromChains(t *testing.T) {
+	valid := RawChainConfig{
+		Name: "eth",
+		Type: "ethereum",
+		Id:   "0",
+		From: "0xff93B45308FD417dF303D6515aB04D9e89a750Ca",
+		Opts: map[string]string{"bridge": "0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B"},
+	}
+
+	cfg, err := GenerateFromChains([]RawChainConfig{valid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Chains[0].Endpoint != "ws://localhost:8545" {
+		t.Fatalf("expected default endpoint, got: %s", cfg.Chains[0].Endpoint)
+	}
+
+	missingBridge := valid
+	missingBridge.Opts = map[string]string{}
+	if _, err = GenerateFromChains([]RawChainConfig{missingBridge}); err == nil {
+		t.Fatal("must require bridge address")
+	}
+
+	invalidId := valid
+	invalidId.Id = "256"
+	if _, err = GenerateFromChains([]RawChainConfig{invalidId}); err == nil {
+		t.Fatal("must reject chain ID out of range")
+	}
+
+	if _, err = GenerateFromChains(nil); err == nil {
+		t.Fatal("must require at least one chain")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// routeSeparators separate the source and destination of RouteMap keys, "->" is accepted as well as the arrow
+var routeSeparators = []string{"→", "->"}
+
+// Hop is a chain messages are sent via to reach a chain the relayer is not connected to
+type Hop struct {
+	Via msg.ChainId `json:"via"`
+}
+
+// RouteMap sets the hops of messages between chains that are not directly connected, keyed by
+// "<source>→<destination>", eg. {"0→2": [{"via": 1}]}
+type RouteMap map[string][]Hop
+
+// Route is a parsed RouteMap entry
+type Route struct {
+	Source      msg.ChainId
+	Destination msg.ChainId
+	Via         []msg.ChainId
+}
+
+// Routes returns the routes of the map, sorted by source and destination
+func (r RouteMap) Routes() ([]Route, error) {
+	routes := make([]Route, 0, len(r))
+	for key, hops := range r {
+		source, destination, err := parseRouteKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(hops) == 0 {
+			return nil, fmt.Errorf("route %s has no hops", key)
+		}
+		route := Route{Source: source, Destination: destination}
+		for _, hop := range hops {
+			route.Via = append(route.Via, hop.Via)
+		}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Source != routes[j].Source {
+			return routes[i].Source < routes[j].Source
+		}
+		return routes[i].Destination < routes[j].Destination
+	})
+	return routes, nil
+}
+
+func parseRouteKey(key string) (msg.ChainId, msg.ChainId, error) {
+	for _, sep := range routeSeparators {
+		parts := strings.Split(key, sep)
+		if len(parts) != 2 {
+			continue
+		}
+		source, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 8)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid source of route %s: %w", key, err)
+		}
+		destination, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 8)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid destination of route %s: %w", key, err)
+		}
+		if source == destination {
+			return 0, 0, fmt.Errorf("route %s has the same source and destination", key)
+		}
+		return msg.ChainId(source), msg.ChainId(destination), nil
+	}
+	return 0, 0, fmt.Errorf("invalid route %s, expected <source>→<destination>", key)
+}
@@ -4,18 +4,27 @@
 package ethereum
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/ChainSafe/ChainBridge/connections/ethereum/egs"
+	"github.com/ChainSafe/ChainBridge/connections/ethereum/safe"
+	"github.com/ChainSafe/ChainBridge/crypto/fireblocks"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
 	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
 	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -23,6 +32,58 @@ import (
 
 var BlockRetryInterval = time.Second * 5
 
+// PeerRetryInterval is the initial wait before reconnecting to a node with too few peers. It doubles after each
+// attempt, up to MaxPeerRetryInterval.
+var PeerRetryInterval = time.Second * 5
+
+// MaxPeerRetryInterval is the longest wait between reconnecting to a node with too few peers
+var MaxPeerRetryInterval = time.Minute
+
+var ErrBeforeGenesis = errors.New("timestamp is before the genesis block")
+
+// MaxBatchSize is the maximum number of requests sent in a single JSON-RPC batch
+const MaxBatchSize = 100
+
+// EIP-1167 minimal proxy runtime code is the implementation address wrapped by this prefix and suffix
+var (
+	minimalProxyPrefix = ethcommon.FromHex("0x363d3d373d3d3d363d73")
+	minimalProxySuffix = ethcommon.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// NonceState selects the block a nonce is read at
+type NonceState int
+
+const (
+	NonceStatePending  NonceState = iota // Includes transactions in the node's pool, used while sending transactions
+	NonceStateLatest                     // Only includes mined transactions, used on startup to recover from dropped transactions
+	NonceStateEarliest                   // The nonce at genesis
+)
+
+// blockTag returns the eth_getTransactionCount block parameter for the state
+func (s NonceState) blockTag() (string, error) {
+	switch s {
+	case NonceStatePending:
+		return "pending", nil
+	case NonceStateLatest:
+		return "latest", nil
+	case NonceStateEarliest:
+		return "earliest", nil
+	default:
+		return "", fmt.Errorf("unknown nonce state: %d", s)
+	}
+}
+
+// Backend is the part of the ethclient used to bind contracts and query the chain. It is satisfied by
+// *ethclient.Client, and allows a test double to be used in its place.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	Close()
+}
+
+var _ Backend = &ethclient.Client{}
+
 type Connection struct {
 	endpoint      string
 	http          bool
@@ -34,6 +95,7 @@ type Connection struct {
 	egsApiKey     string
 	egsSpeed      string
 	conn          *ethclient.Client
+	rpc           *rpc.Client // Used for methods not provided by the ethclient, such as eth_getProof
 	// signer    ethtypes.Signer
 	opts     *bind.TransactOpts
 	callOpts *bind.CallOpts
@@ -41,81 +103,265 @@ type Connection struct {
 	optsLock sync.Mutex
 	log      log15.Logger
 	stop     chan int // All routines should exit when this channel is closed
+	// proxies caches the implementation address of known minimal proxies, non-proxies map to the zero address
+	proxies   map[ethcommon.Address]ethcommon.Address
+	proxyLock sync.RWMutex
+	batchRPC  bool // Request ranges of headers in JSON-RPC batches
+	// safe and safeServiceURL are set if transactions are sent through a Safe the keypair is an owner of
+	safe           ethcommon.Address
+	safeServiceURL string
+	// fireblocks signs transactions in place of kp if set
+	fireblocks *fireblocks.FireblocksKeypair
+	// ENS names are resolved with ensRegistry and cached for ensCacheTTL
+	ensRegistry ethcommon.Address
+	ensCacheTTL time.Duration
+	ensCache    map[string]ensEntry
+	ensLock     sync.Mutex
+	gasConfig   *GasConfigWatcher // Overrides the gas parameters above if set
+	// minPeerCount is the fewest peers the node may have, connecting is retried until it has enough
+	minPeerCount uint64
+	waitForSync  bool // Connecting waits until the node has synced
+	// pollingInterval is the time between requests for the latest header if the node does not support subscriptions
+	pollingInterval time.Duration
 }
 
 // NewConnection returns an uninitialized connection, must call Connection.Connect() before using.
+// If kp is nil the connection can only be used for calls and cannot send transactions.
 func NewConnection(endpoint string, http bool, kp *secp256k1.Keypair, log log15.Logger, gasLimit, maxGasPrice, minGasPrice *big.Int, gasMultiplier *big.Float, gsnApiKey, gsnSpeed string) *Connection {
 	return &Connection{
-		endpoint:      endpoint,
-		http:          http,
-		kp:            kp,
-		gasLimit:      gasLimit,
-		maxGasPrice:   maxGasPrice,
-		minGasPrice:   minGasPrice,
-		gasMultiplier: gasMultiplier,
-		egsApiKey:     gsnApiKey,
-		egsSpeed:      gsnSpeed,
-		log:           log,
-		stop:          make(chan int),
+		endpoint:        endpoint,
+		http:            http,
+		kp:              kp,
+		gasLimit:        gasLimit,
+		maxGasPrice:     maxGasPrice,
+		minGasPrice:     minGasPrice,
+		gasMultiplier:   gasMultiplier,
+		egsApiKey:       gsnApiKey,
+		egsSpeed:        gsnSpeed,
+		log:             log,
+		stop:            make(chan int),
+		proxies:         make(map[ethcommon.Address]ethcommon.Address),
+		batchRPC:        true,
+		ensRegistry:     DefaultENSRegistry,
+		ensCacheTTL:     DefaultENSCacheTTL,
+		ensCache:        make(map[string]ensEntry),
+		pollingInterval: BlockRetryInterval,
 	}
 }
 
+// SetBatchRPC enables or disables JSON-RPC batches, for endpoints that do not support them. Enabled by default.
+func (c *Connection) SetBatchRPC(enabled bool) {
+	c.batchRPC = enabled
+}
+
+// SetSafe sends all transactions through the Safe at address, which the keypair must be an owner of. Each
+// transaction is proposed to the Safe Transaction Service at serviceURL and executed once confirmed by enough
+// owners. Calls are made from the Safe's address. Must be called before Connect.
+func (c *Connection) SetSafe(address ethcommon.Address, serviceURL string) {
+	c.safe = address
+	c.safeServiceURL = serviceURL
+}
+
+// SetFireblocks signs transactions with kp, a key held by Fireblocks, instead of the connection's keypair, which may
+// be nil. Cannot be used with a Safe. Must be called before Connect.
+func (c *Connection) SetFireblocks(kp *fireblocks.FireblocksKeypair) {
+	c.fireblocks = kp
+}
+
+// SetMinPeerCount makes Connect retry until the node has at least count peers, as a node with fewer is likely to be
+// isolated from the network. Disabled by default. Must be called before Connect.
+func (c *Connection) SetMinPeerCount(count uint64) {
+	c.minPeerCount = count
+}
+
+// SetGasConfigWatcher overrides the connection's gas parameters with the config held by w each time the opts are
+// updated, so changes to the config apply to the next transaction. Must be called before Connect.
+func (c *Connection) SetGasConfigWatcher(w *GasConfigWatcher) {
+	c.gasConfig = w
+}
+
+// currentGasConfig returns the gas config overriding the connection's parameters, or nil if there is none
+func (c *Connection) currentGasConfig() *GasConfig {
+	if c.gasConfig == nil {
+		return nil
+	}
+	return c.gasConfig.Load()
+}
+
+// currentGasLimit returns the gas limit of the gas config if it sets one, otherwise the configured limit
+func (c *Connection) currentGasLimit() uint64 {
+	if cfg := c.currentGasConfig(); cfg != nil && cfg.GasLimit != 0 {
+		return cfg.GasLimit
+	}
+	return c.gasLimit.Uint64()
+}
+
+// currentGasMultiplier returns the multiplier of the gas config if it sets one, otherwise the configured multiplier
+func (c *Connection) currentGasMultiplier() *big.Float {
+	if cfg := c.currentGasConfig(); cfg != nil && cfg.GasPriceMultiplier != 0 {
+		return big.NewFloat(cfg.GasPriceMultiplier)
+	}
+	return c.gasMultiplier
+}
+
 // Connect starts the ethereum WS connection
 func (c *Connection) Connect() error {
-	c.log.Info("Connecting to ethereum chain...", "url", c.endpoint)
-	var rpcClient *rpc.Client
-	var err error
-	// Start http or ws client
-	if c.http {
-		rpcClient, err = rpc.DialHTTP(c.endpoint)
-	} else {
-		rpcClient, err = rpc.DialContext(context.Background(), c.endpoint)
+	return c.ConnectWithContext(context.Background())
+}
+
+// ConnectWithContext starts the ethereum connection, aborting if ctx is done before the node responds.
+// The context only bounds connecting, it is not used by the connection afterwards.
+func (c *Connection) ConnectWithContext(ctx context.Context) error {
+	interval := PeerRetryInterval
+	for {
+		rpcClient, err := c.dial(ctx)
+		if err != nil {
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeConnectFailed, true, err)
+		}
+		c.conn = ethclient.NewClient(rpcClient)
+		c.rpc = rpcClient
+
+		peers, err := c.peerCount(ctx)
+		if err != nil {
+			// Some providers do not serve the net namespace, so the node is assumed to be connected
+			c.log.Warn("Unable to get the node's peer count", "err", err)
+			break
+		}
+		if peers == 0 {
+			c.log.Warn("Node has no peers and may be isolated from the network", "url", c.endpoint)
+		}
+		if peers >= c.minPeerCount {
+			break
+		}
+
+		rpcClient.Close()
+		c.log.Warn("Node has too few peers, reconnecting", "peers", peers, "minPeerCount", c.minPeerCount, "retryIn", interval)
+		select {
+		case <-ctx.Done():
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeConnectFailed, true, fmt.Errorf("node has %d peers, at least %d are required: %w", peers, c.minPeerCount, ctx.Err()))
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > MaxPeerRetryInterval {
+			interval = MaxPeerRetryInterval
+		}
 	}
+
+	err := c.awaitSync(ctx)
 	if err != nil {
+		c.conn.Close()
 		return err
 	}
-	c.conn = ethclient.NewClient(rpcClient)
+
+	if c.kp == nil && c.fireblocks == nil {
+		c.callOpts = &bind.CallOpts{}
+		return nil
+	}
 
 	// Construct tx opts, call opts, and nonce mechanism
-	opts, _, err := c.newTransactOpts(big.NewInt(0), c.gasLimit, c.maxGasPrice)
+	opts, _, err := c.newTransactOpts(ctx, big.NewInt(0), c.gasLimit, c.maxGasPrice)
 	if err != nil {
 		return err
 	}
 	c.opts = opts
 	c.nonce = 0
-	c.callOpts = &bind.CallOpts{From: c.kp.CommonAddress()}
+	c.callOpts = &bind.CallOpts{From: opts.From}
+	if c.safe != (ethcommon.Address{}) {
+		c.callOpts.From = c.safe
+	}
 	return nil
 }
 
-// newTransactOpts builds the TransactOpts for the connection's keypair.
-func (c *Connection) newTransactOpts(value, gasLimit, gasPrice *big.Int) (*bind.TransactOpts, uint64, error) {
-	privateKey := c.kp.PrivateKey()
-	address := ethcrypto.PubkeyToAddress(privateKey.PublicKey)
+// dial opens an http or ws client to the endpoint
+func (c *Connection) dial(ctx context.Context) (*rpc.Client, error) {
+	c.log.Info("Connecting to ethereum chain...", "url", c.endpoint)
+	if c.http {
+		return rpc.DialHTTPWithClient(c.endpoint, &http.Client{Transport: newRequestIdTransport(http.DefaultTransport, c.log)})
+	}
+	return rpc.DialContext(ctx, c.endpoint)
+}
 
-	nonce, err := c.conn.PendingNonceAt(context.Background(), address)
-	if err != nil {
-		return nil, 0, err
+// newTransactOpts builds the TransactOpts for the connection's keypair, or its Fireblocks keypair if set. The gas
+// limit and price are replaced by those of the gas config, if set.
+func (c *Connection) newTransactOpts(ctx context.Context, value, gasLimit, gasPrice *big.Int) (*bind.TransactOpts, uint64, error) {
+	var address ethcommon.Address
+	if c.fireblocks != nil {
+		address = c.fireblocks.CommonAddress()
+	} else {
+		address = ethcrypto.PubkeyToAddress(c.kp.PrivateKey().PublicKey)
 	}
 
-	id, err := c.conn.ChainID(context.Background())
+	// Transactions sent before a restart may have been dropped, so only count those that were mined
+	nonce, err := c.GetNonce(ctx, address, NonceStateLatest)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, id)
+	id, err := c.conn.ChainID(ctx)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+
+	var auth *bind.TransactOpts
+	if c.fireblocks != nil {
+		auth = newFireblocksTransactor(c.fireblocks, id)
+	} else {
+		auth, err = bind.NewKeyedTransactorWithChainID(c.kp.PrivateKey(), id)
+		if err != nil {
+			return nil, 0, bridgeErrors.NewSigningError(bridgeErrors.CodeSignerUnavailable, false, err)
+		}
 	}
 
 	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = value
 	auth.GasLimit = uint64(gasLimit.Int64())
 	auth.GasPrice = gasPrice
+	if cfg := c.currentGasConfig(); cfg != nil {
+		if cfg.GasLimit != 0 {
+			auth.GasLimit = cfg.GasLimit
+		}
+		if cfg.GasPrice != nil {
+			auth.GasPrice = new(big.Int).Set(cfg.GasPrice)
+		}
+	}
 	auth.Context = context.Background()
 
+	if c.safe != (ethcommon.Address{}) {
+		safeKp := safe.NewSafeKeypair(c.kp, c.safe, c.safeServiceURL, id, c.log)
+		auth.Signer = func(_ ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return safeKp.SignTransaction(tx)
+		}
+	}
+
 	return auth, nonce, nil
 }
 
+// RotateKeypair replaces the keypair transactions are signed with, taking the nonce of the new address. It waits
+// for any transaction being sent with the current keypair to be submitted. Calls are made from the new address
+// unless a Safe is set.
+func (c *Connection) RotateKeypair(kp *secp256k1.Keypair) error {
+	if c.fireblocks != nil {
+		return ErrFireblocksRotation
+	}
+	c.optsLock.Lock()
+	defer c.optsLock.Unlock()
+
+	old := c.kp
+	c.kp = kp
+	opts, _, err := c.newTransactOpts(context.Background(), big.NewInt(0), c.gasLimit, c.maxGasPrice)
+	if err != nil {
+		c.kp = old
+		return err
+	}
+	c.opts = opts
+	c.nonce = 0
+	if c.safe == (ethcommon.Address{}) {
+		c.callOpts = &bind.CallOpts{From: kp.CommonAddress()}
+	}
+	return nil
+}
+
+// Keypair returns the connection's keypair, which is nil if transactions are signed by Fireblocks
 func (c *Connection) Keypair() *secp256k1.Keypair {
 	return c.kp
 }
@@ -124,6 +370,11 @@ func (c *Connection) Client() *ethclient.Client {
 	return c.conn
 }
 
+// Backend returns the client as a Backend
+func (c *Connection) Backend() Backend {
+	return c.conn
+}
+
 func (c *Connection) Opts() *bind.TransactOpts {
 	return c.opts
 }
@@ -157,7 +408,7 @@ func (c *Connection) SafeEstimateGas(ctx context.Context) (*big.Int, error) {
 		}
 	}
 
-	gasPrice := multiplyGasPrice(suggestedGasPrice, c.gasMultiplier)
+	gasPrice := multiplyGasPrice(suggestedGasPrice, c.currentGasMultiplier())
 
 	// Check we aren't exceeding our limit
 	if gasPrice.Cmp(c.minGasPrice) == -1 {
@@ -201,6 +452,150 @@ func (c *Connection) EstimateGasLondon(ctx context.Context, baseFee *big.Int) (*
 	return maxPriorityFeePerGas, maxFeePerGas, nil
 }
 
+// EffectiveGasPrice returns the price per gas a transaction sent now would pay, within the configured limits
+func (c *Connection) EffectiveGasPrice(ctx context.Context) (*big.Int, error) {
+	head, err := c.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+
+	if head.BaseFee == nil {
+		price, err := c.SafeEstimateGas(ctx)
+		if err != nil {
+			return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+		}
+		return price, nil
+	}
+
+	tip, feeCap, err := c.EstimateGasLondon(ctx, head.BaseFee)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	price := new(big.Int).Add(head.BaseFee, tip)
+	if price.Cmp(feeCap) == 1 {
+		price = feeCap
+	}
+	return price, nil
+}
+
+// AccountProof is the EIP-1186 proof of an account and some of its storage slots
+type AccountProof struct {
+	Address      ethcommon.Address `json:"address"`
+	AccountProof []string          `json:"accountProof"`
+	StorageHash  ethcommon.Hash    `json:"storageHash"`
+	StorageProof []StorageProof    `json:"storageProof"`
+}
+
+// StorageProof is the EIP-1186 proof of a single storage slot
+type StorageProof struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// GetProof returns the proofs of account and its storage slots keys at block, nil for the latest block
+func (c *Connection) GetProof(ctx context.Context, account ethcommon.Address, keys []string, block *big.Int) (*AccountProof, error) {
+	blockArg := "latest"
+	if block != nil {
+		blockArg = hexutil.EncodeBig(block)
+	}
+
+	var proof AccountProof
+	err := c.rpc.CallContext(ctx, &proof, "eth_getProof", account, keys, blockArg)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return &proof, nil
+}
+
+// ContractStorageProof is the EIP-1186 proof of some of a contract's storage slots, with its trie nodes decoded
+type ContractStorageProof struct {
+	Address       ethcommon.Address
+	AccountProof  []hexutil.Bytes // Trie nodes from the state root to the account
+	StorageHash   ethcommon.Hash
+	StorageProofs []StorageProofEntry // In the order of the requested keys
+}
+
+// StorageProofEntry is the proof of a single storage slot, its value is zero if the slot is empty
+type StorageProofEntry struct {
+	Key   ethcommon.Hash
+	Value *big.Int
+	Proof []hexutil.Bytes // Trie nodes from the storage root to the slot
+}
+
+// GetContractStorageProof returns the proofs of the storage slots keys of addr at block, nil for the latest block
+func (c *Connection) GetContractStorageProof(ctx context.Context, addr ethcommon.Address, keys []ethcommon.Hash, block *big.Int) (*ContractStorageProof, error) {
+	hexKeys := make([]string, len(keys))
+	for i, key := range keys {
+		hexKeys[i] = key.Hex()
+	}
+	proof, err := c.GetProof(ctx, addr, hexKeys, block)
+	if err != nil {
+		return nil, err
+	}
+	if len(proof.StorageProof) != len(keys) {
+		return nil, fmt.Errorf("expected %d storage proofs, got %d", len(keys), len(proof.StorageProof))
+	}
+
+	accountProof, err := decodeProofNodes(proof.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof: %w", err)
+	}
+	storage := &ContractStorageProof{Address: proof.Address, AccountProof: accountProof, StorageHash: proof.StorageHash}
+	for i, p := range proof.StorageProof {
+		nodes, err := decodeProofNodes(p.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage proof of %s: %w", keys[i].Hex(), err)
+		}
+		value := new(big.Int)
+		if p.Value != nil {
+			value = p.Value.ToInt()
+		}
+		// Nodes may return keys without leading zeros, so the requested key is used
+		storage.StorageProofs = append(storage.StorageProofs, StorageProofEntry{Key: keys[i], Value: value, Proof: nodes})
+	}
+	return storage, nil
+}
+
+func decodeProofNodes(encoded []string) ([]hexutil.Bytes, error) {
+	nodes := make([]hexutil.Bytes, len(encoded))
+	for i, node := range encoded {
+		b, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = b
+	}
+	return nodes, nil
+}
+
+// SimulateTransaction estimates the gas used by call without sending a transaction
+func (c *Connection) SimulateTransaction(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	gas, err := c.conn.EstimateGas(ctx, call)
+	if err != nil {
+		return 0, bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, false, err)
+	}
+	return gas, nil
+}
+
+// EstimateGasWithBuffer estimates the gas used by call and adds pct of it, so the transaction does not run out of
+// gas if state changes before it is mined. For example, 0.2 adds 20%. The result is capped at the gas limit.
+func (c *Connection) EstimateGasWithBuffer(ctx context.Context, call eth.CallMsg, pct float64) (uint64, error) {
+	if pct < 0 {
+		return 0, fmt.Errorf("gas buffer must not be negative, got %v", pct)
+	}
+	gas, err := c.conn.EstimateGas(ctx, call)
+	if err != nil {
+		return 0, bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, false, err)
+	}
+
+	gas += uint64(math.Round(float64(gas) * pct))
+	if max := c.currentGasLimit(); gas > max {
+		return max, nil
+	}
+	return gas, nil
+}
+
 func multiplyGasPrice(gasEstimate *big.Int, gasMultiplier *big.Float) *big.Int {
 
 	gasEstimateFloat := new(big.Float).SetInt(gasEstimate)
@@ -215,21 +610,29 @@ func multiplyGasPrice(gasEstimate *big.Int, gasMultiplier *big.Float) *big.Int {
 }
 
 // LockAndUpdateOpts acquires a lock on the opts before updating the nonce
-// and gas price.
+// and gas price. A gas price set by the gas config is used instead of an estimate.
 func (c *Connection) LockAndUpdateOpts() error {
 	c.optsLock.Lock()
 
+	c.opts.GasLimit = c.currentGasLimit()
+	if cfg := c.currentGasConfig(); cfg != nil && cfg.GasPrice != nil {
+		c.opts.GasPrice = new(big.Int).Set(cfg.GasPrice)
+		c.opts.GasTipCap = nil
+		c.opts.GasFeeCap = nil
+		return c.updateNonce()
+	}
+
 	head, err := c.conn.HeaderByNumber(context.TODO(), nil)
 	if err != nil {
 		c.UnlockOpts()
-		return err
+		return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
 	}
 
 	if head.BaseFee != nil {
 		c.opts.GasTipCap, c.opts.GasFeeCap, err = c.EstimateGasLondon(context.TODO(), head.BaseFee)
 		if err != nil {
 			c.UnlockOpts()
-			return err
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
 		}
 
 		// Both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) cannot be specified: https://github.com/ethereum/go-ethereum/blob/95bbd46eabc5d95d9fb2108ec232dd62df2f44ab/accounts/abi/bind/base.go#L254
@@ -239,12 +642,17 @@ func (c *Connection) LockAndUpdateOpts() error {
 		gasPrice, err = c.SafeEstimateGas(context.TODO())
 		if err != nil {
 			c.UnlockOpts()
-			return err
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
 		}
 		c.opts.GasPrice = gasPrice
 	}
 
-	nonce, err := c.conn.PendingNonceAt(context.Background(), c.opts.From)
+	return c.updateNonce()
+}
+
+// updateNonce sets the nonce of the opts to the pending nonce, releasing the opts lock on failure
+func (c *Connection) updateNonce() error {
+	nonce, err := c.GetNonce(context.Background(), c.opts.From, NonceStatePending)
 	if err != nil {
 		c.optsLock.Unlock()
 		return err
@@ -253,6 +661,44 @@ func (c *Connection) LockAndUpdateOpts() error {
 	return nil
 }
 
+// GetPendingBalance returns the balance of addr in the pending state, after the transactions in the node's pool
+func (c *Connection) GetPendingBalance(addr ethcommon.Address) (*big.Int, error) {
+	balance, err := c.conn.PendingBalanceAt(context.Background(), addr)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return balance, nil
+}
+
+// GetPeerCount returns the number of peers the node is connected to
+func (c *Connection) GetPeerCount() (uint64, error) {
+	return c.peerCount(context.Background())
+}
+
+func (c *Connection) peerCount(ctx context.Context) (uint64, error) {
+	var peers hexutil.Uint64
+	err := c.rpc.CallContext(ctx, &peers, "net_peerCount")
+	if err != nil {
+		return 0, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return uint64(peers), nil
+}
+
+// GetNonce returns the transaction count of addr in the given state
+func (c *Connection) GetNonce(ctx context.Context, addr ethcommon.Address, state NonceState) (uint64, error) {
+	tag, err := state.blockTag()
+	if err != nil {
+		return 0, err
+	}
+
+	var nonce hexutil.Uint64
+	err = c.rpc.CallContext(ctx, &nonce, "eth_getTransactionCount", addr, tag)
+	if err != nil {
+		return 0, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return uint64(nonce), nil
+}
+
 func (c *Connection) UnlockOpts() {
 	c.optsLock.Unlock()
 }
@@ -261,24 +707,213 @@ func (c *Connection) UnlockOpts() {
 func (c *Connection) LatestBlock() (*big.Int, error) {
 	header, err := c.conn.HeaderByNumber(context.Background(), nil)
 	if err != nil {
-		return nil, err
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
 	}
 	return header.Number, nil
 }
 
+// BlockByTimestamp returns the latest block with a timestamp at or before ts
+func (c *Connection) BlockByTimestamp(ctx context.Context, ts time.Time) (*big.Int, error) {
+	latest, err := c.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return searchBlockByTimestamp(ctx, latest.Number.Uint64(), latest.Time, ts, func(ctx context.Context, block uint64) (uint64, error) {
+		header, err := c.conn.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		if err != nil {
+			return 0, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+		}
+		return header.Time, nil
+	})
+}
+
+// searchBlockByTimestamp binary searches blocks 0 to latest for the last one with a timestamp at or before ts.
+// Block timestamps never decrease, so this takes log2(latest) header lookups.
+func searchBlockByTimestamp(ctx context.Context, latest, latestTime uint64, ts time.Time, blockTime func(ctx context.Context, block uint64) (uint64, error)) (*big.Int, error) {
+	if ts.Unix() < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBeforeGenesis, ts.UTC().Format(time.RFC3339))
+	}
+	target := uint64(ts.Unix())
+	if latestTime <= target {
+		return new(big.Int).SetUint64(latest), nil
+	}
+	genesisTime, err := blockTime(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if genesisTime > target {
+		return nil, fmt.Errorf("%w: %s", ErrBeforeGenesis, ts.UTC().Format(time.RFC3339))
+	}
+
+	// Block low is at or before the target, block high is after it
+	low, high := uint64(0), latest
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		midTime, err := blockTime(ctx, mid)
+		if err != nil {
+			return nil, err
+		}
+		if midTime <= target {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return new(big.Int).SetUint64(low), nil
+}
+
+// GetContractDeploymentBlock returns the first block at which addr has code. The code of addr is checked at
+// historical blocks, so the node must keep their state (eg. an archive node).
+func (c *Connection) GetContractDeploymentBlock(ctx context.Context, addr ethcommon.Address) (*big.Int, error) {
+	latest, err := c.conn.BlockNumber(ctx)
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	hasCode := func(ctx context.Context, block uint64) (bool, error) {
+		code, err := c.conn.CodeAt(ctx, addr, new(big.Int).SetUint64(block))
+		if err != nil {
+			return false, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+		}
+		return len(code) != 0, nil
+	}
+
+	deployed, err := hasCode(ctx, latest)
+	if err != nil {
+		return nil, err
+	}
+	if !deployed {
+		return nil, bridgeErrors.NewContractError(bridgeErrors.CodeNoBytecode, false, fmt.Errorf("no bytecode found at %s", addr.Hex()))
+	}
+	return searchDeploymentBlock(ctx, latest, hasCode)
+}
+
+// searchDeploymentBlock binary searches blocks 0 to latest for the first one at which a contract has code, given
+// it has code at latest. This assumes the contract has not self destructed, and takes log2(latest) code lookups.
+func searchDeploymentBlock(ctx context.Context, latest uint64, hasCode func(ctx context.Context, block uint64) (bool, error)) (*big.Int, error) {
+	// The contract has no code before block low, and has code at block high
+	low, high := uint64(0), latest
+	for low < high {
+		mid := low + (high-low)/2
+		deployed, err := hasCode(ctx, mid)
+		if err != nil {
+			return nil, err
+		}
+		if deployed {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return new(big.Int).SetUint64(high), nil
+}
+
+// BatchGetBlockHeaders returns the headers of the blocks from from to to, inclusive. If batching is enabled they
+// are requested in JSON-RPC batches of up to MaxBatchSize, falling back to one request per block if a batch fails.
+func (c *Connection) BatchGetBlockHeaders(ctx context.Context, from, to *big.Int) ([]*types.Header, error) {
+	if from.Sign() < 0 || from.Cmp(to) > 0 {
+		return nil, fmt.Errorf("invalid block range %s to %s", from, to)
+	}
+	count := new(big.Int).Sub(to, from).Int64() + 1
+
+	if c.batchRPC {
+		headers, err := c.batchHeaders(ctx, from, count)
+		if err == nil {
+			return headers, nil
+		}
+		c.log.Debug("Batch request failed, requesting headers individually", "from", from, "to", to, "err", err)
+	}
+
+	headers := make([]*types.Header, count)
+	for i := range headers {
+		header, err := c.conn.HeaderByNumber(ctx, new(big.Int).Add(from, big.NewInt(int64(i))))
+		if err != nil {
+			return nil, err
+		}
+		headers[i] = header
+	}
+	return headers, nil
+}
+
+// batchHeaders requests count headers starting at from with eth_getBlockByNumber batches
+func (c *Connection) batchHeaders(ctx context.Context, from *big.Int, count int64) ([]*types.Header, error) {
+	headers := make([]*types.Header, count)
+	for start := int64(0); start < count; start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > count {
+			end = count
+		}
+
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeBig(new(big.Int).Add(from, big.NewInt(i))), false},
+				Result: &headers[i],
+			})
+		}
+		err := c.rpc.BatchCallContext(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+			if headers[start+int64(i)] == nil {
+				return nil, eth.NotFound
+			}
+		}
+	}
+	return headers, nil
+}
+
 // EnsureHasBytecode asserts if contract code exists at the specified address
 func (c *Connection) EnsureHasBytecode(addr ethcommon.Address) error {
 	code, err := c.conn.CodeAt(context.Background(), addr, nil)
 	if err != nil {
-		return err
+		return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
 	}
 
 	if len(code) == 0 {
-		return fmt.Errorf("no bytecode found at %s", addr.Hex())
+		return bridgeErrors.NewContractError(bridgeErrors.CodeNoBytecode, false, fmt.Errorf("no bytecode found at %s", addr.Hex()))
 	}
 	return nil
 }
 
+// IsMinimalProxy checks if the contract at addr is an EIP-1167 minimal proxy, returning the implementation address
+// if so. Results are cached, as runtime code of a deployed contract does not change.
+func (c *Connection) IsMinimalProxy(addr ethcommon.Address) (ethcommon.Address, bool, error) {
+	c.proxyLock.RLock()
+	impl, ok := c.proxies[addr]
+	c.proxyLock.RUnlock()
+	if ok {
+		return impl, impl != (ethcommon.Address{}), nil
+	}
+
+	code, err := c.conn.CodeAt(context.Background(), addr, nil)
+	if err != nil {
+		return ethcommon.Address{}, false, err
+	}
+	impl, isProxy := parseMinimalProxy(code)
+
+	c.proxyLock.Lock()
+	c.proxies[addr] = impl
+	c.proxyLock.Unlock()
+
+	return impl, isProxy, nil
+}
+
+// parseMinimalProxy extracts the implementation address from EIP-1167 minimal proxy runtime code
+func parseMinimalProxy(code []byte) (ethcommon.Address, bool) {
+	if len(code) != len(minimalProxyPrefix)+ethcommon.AddressLength+len(minimalProxySuffix) {
+		return ethcommon.Address{}, false
+	}
+	if !bytes.HasPrefix(code, minimalProxyPrefix) || !bytes.HasSuffix(code, minimalProxySuffix) {
+		return ethcommon.Address{}, false
+	}
+	return ethcommon.BytesToAddress(code[len(minimalProxyPrefix) : len(minimalProxyPrefix)+ethcommon.AddressLength]), true
+}
+
 // WaitForBlock will poll for the block number until the current block is equal or greater.
 // If delay is provided it will wait until currBlock - delay = targetBlock
 func (c *Connection) WaitForBlock(targetBlock *big.Int, delay *big.Int) error {
@@ -307,6 +942,83 @@ func (c *Connection) WaitForBlock(targetBlock *big.Int, delay *big.Int) error {
 	}
 }
 
+// SubscribePendingTxs sends the hash of each transaction added to the node's mempool to hashes, using
+// eth_subscribe("newPendingTransactions"). Subscriptions are not supported by http connections.
+func (c *Connection) SubscribePendingTxs(ctx context.Context, hashes chan<- ethcommon.Hash) (eth.Subscription, error) {
+	sub, err := c.rpc.EthSubscribe(ctx, hashes, "newPendingTransactions")
+	if err != nil {
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return sub, nil
+}
+
+// WatchBlockHeaders sends the current head and then each new head of the chain to headers until ctx is done. Heads
+// are received from an eth_subscribe("newHeads") subscription, or by polling for the latest header every
+// pollingInterval if the node does not support subscriptions, such as over http. Polling also replaces a
+// subscription that fails. It returns nil once ctx is done, or the error if requesting a header fails.
+func (c *Connection) WatchBlockHeaders(ctx context.Context, headers chan<- *types.Header) error {
+	// A subscription only sends heads once the next block is produced, so the current head is sent first
+	header, err := c.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	select {
+	case headers <- header:
+	case <-ctx.Done():
+		return nil
+	}
+
+	sub, err := c.conn.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		c.log.Debug("Unable to subscribe to new heads, polling", "interval", c.pollingInterval, "err", err)
+		return PollBlockHeaders(ctx, c.conn, c.pollingInterval, headers)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err = <-sub.Err():
+		c.log.Warn("New head subscription failed, polling", "interval", c.pollingInterval, "err", err)
+		return PollBlockHeaders(ctx, c.conn, c.pollingInterval, headers)
+	}
+}
+
+// PollBlockHeaders requests the latest header from backend every interval and sends it to headers if it is newer than
+// the last one sent, until ctx is done. It returns nil once ctx is done, or the error if a request fails.
+func PollBlockHeaders(ctx context.Context, backend Backend, interval time.Duration, headers chan<- *types.Header) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *big.Int
+	for {
+		header, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+		}
+		if last == nil || header.Number.Cmp(last) > 0 {
+			select {
+			case headers <- header:
+				last = header.Number
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 // Close terminates the client connection and stops any running routines
 func (c *Connection) Close() {
 	if c.conn != nil {
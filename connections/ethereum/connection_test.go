@@ -4,15 +4,32 @@
 package ethereum
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
 	ethutils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
 	"github.com/ChainSafe/chainbridge-utils/keystore"
 	"github.com/ChainSafe/log15"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var TestEndpoint = "ws://localhost:8545"
@@ -32,6 +49,83 @@ func TestConnect(t *testing.T) {
 	conn.Close()
 }
 
+func TestConnection_SetSafe(t *testing.T) {
+	// The transaction service is unavailable, so signing must fail instead of sending from the keypair
+	service := httptest.NewServer(http.NotFoundHandler())
+	defer service.Close()
+
+	safe := ethcmn.HexToAddress("0x1234567890123456789012345678901234567890")
+	conn := NewConnection(TestEndpoint, false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetSafe(safe, service.URL)
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if conn.CallOpts().From != safe {
+		t.Fatalf("expected calls from the safe, got: %s", conn.CallOpts().From.Hex())
+	}
+	to := ethcmn.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	_, err = conn.Opts().Signer(conn.Opts().From, types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 21000, To: &to}))
+	if err == nil {
+		t.Fatal("expected signing to fail without the transaction service")
+	}
+}
+
+func TestConnect_Failure(t *testing.T) {
+	// Nothing is listening on port 1
+	conn := NewConnection("ws://localhost:1", false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err == nil {
+		t.Fatal("expected connection to fail")
+	}
+
+	var connErr *bridgeErrors.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected ConnectionError, got: %v", err)
+	}
+	if connErr.Code != bridgeErrors.CodeConnectFailed || !connErr.Retryable {
+		t.Fatalf("unexpected error fields: %+v", connErr.BridgeError)
+	}
+}
+
+func TestConnectWithContext_Timeout(t *testing.T) {
+	// Accept connections but never complete the websocket handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	conn := NewConnection("ws://"+l.Addr().String(), false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err = conn.ConnectWithContext(ctx)
+	if err == nil {
+		t.Fatal("expected connection to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("connect did not respect context deadline, took %s", elapsed)
+	}
+
+	var connErr *bridgeErrors.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected ConnectionError, got: %v", err)
+	}
+}
+
 // TestContractCode is used to make sure the contracts are deployed correctly.
 // This is probably the least intrusive way to check if the contracts exists
 func TestContractCode(t *testing.T) {
@@ -246,3 +340,1293 @@ func TestConnection_EstimateGasLondonMin(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMinimalProxy(t *testing.T) {
+	impl := ethcmn.HexToAddress("0xbebebebebebebebebebebebebebebebebebebebe")
+	code := append(append(append([]byte{}, minimalProxyPrefix...), impl.Bytes()...), minimalProxySuffix...)
+
+	res, yes := parseMinimalProxy(code)
+	if !yes {
+		t.Fatal("failed to detect minimal proxy")
+	}
+	if res != impl {
+		t.Fatalf("implementation mismatch. Expected: %s Got: %s", impl.Hex(), res.Hex())
+	}
+
+	// Trailing bytes should not be accepted
+	if _, yes = parseMinimalProxy(append(code, 0x00)); yes {
+		t.Fatal("code with trailing bytes should not be a minimal proxy")
+	}
+
+	// Modified suffix should not be accepted
+	code[len(code)-1] = 0x00
+	if _, yes = parseMinimalProxy(code); yes {
+		t.Fatal("code with invalid suffix should not be a minimal proxy")
+	}
+
+	if _, yes = parseMinimalProxy([]byte{}); yes {
+		t.Fatal("empty code should not be a minimal proxy")
+	}
+}
+
+func TestConnection_SimulateTransaction(t *testing.T) {
+	// No keypair is needed to simulate transactions
+	conn := NewConnection(TestEndpoint, false, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	to := ethcmn.HexToAddress(AliceKp.Address())
+	gas, err := conn.SimulateTransaction(context.Background(), eth.CallMsg{
+		From:  to,
+		To:    &to,
+		Value: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gas != 21000 {
+		t.Fatalf("expected transfer to use 21000 gas, got: %d", gas)
+	}
+
+	price, err := conn.EffectiveGasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Sign() <= 0 {
+		t.Fatalf("expected a positive gas price, got: %s", price)
+	}
+}
+
+func TestConnection_GetNonce(t *testing.T) {
+	addr := ethcmn.HexToAddress(AliceKp.Address())
+	counts := map[string]string{"pending": "0x7", "latest": "0x5", "earliest": "0x0"}
+
+	// Mock RPC serving eth_getTransactionCount for each block tag
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.Method != "eth_getTransactionCount" || len(req.Params) != 2 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var account ethcmn.Address
+		var tag string
+		if json.Unmarshal(req.Params[0], &account) != nil || json.Unmarshal(req.Params[1], &tag) != nil || account != addr {
+			http.Error(w, "unexpected params", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s"}`, req.ID, counts[tag])
+	}))
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for state, expected := range map[NonceState]uint64{
+		NonceStatePending:  7,
+		NonceStateLatest:   5,
+		NonceStateEarliest: 0,
+	} {
+		nonce, err := conn.GetNonce(context.Background(), addr, state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce != expected {
+			t.Fatalf("nonce state %d: expected %d, got %d", state, expected, nonce)
+		}
+	}
+
+	_, err = conn.GetNonce(context.Background(), addr, NonceState(-1))
+	if err == nil {
+		t.Fatal("expected error for unknown nonce state")
+	}
+}
+
+func newPeerCountServer(peers func(call int64) uint64, calls *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.Method != "net_peerCount" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		call := atomic.AddInt64(calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s"}`, req.ID, hexutil.EncodeUint64(peers(call)))
+	}))
+}
+
+func TestConnection_GetPeerCount(t *testing.T) {
+	var calls int64
+	server := newPeerCountServer(func(int64) uint64 { return 25 }, &calls)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	peers, err := conn.GetPeerCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peers != 25 {
+		t.Fatalf("expected 25 peers, got: %d", peers)
+	}
+}
+
+func TestConnection_MinPeerCount(t *testing.T) {
+	interval := PeerRetryInterval
+	PeerRetryInterval = time.Millisecond
+	defer func() { PeerRetryInterval = interval }()
+
+	// The node has no peers for the first two attempts
+	var calls int64
+	server := newPeerCountServer(func(call int64) uint64 {
+		if call <= 2 {
+			return 0
+		}
+		return 3
+	}, &calls)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetMinPeerCount(1)
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if atomic.LoadInt64(&calls) != 3 {
+		t.Fatalf("expected to connect on the third attempt, got: %d", atomic.LoadInt64(&calls))
+	}
+
+	// Connecting fails if the node never has enough peers
+	atomic.StoreInt64(&calls, 0)
+	conn = NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetMinPeerCount(5)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err = conn.ConnectWithContext(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the connection to time out, got: %v", err)
+	}
+	if atomic.LoadInt64(&calls) < 2 {
+		t.Fatalf("expected the connection to be retried, got %d attempts", atomic.LoadInt64(&calls))
+	}
+}
+
+// newSyncingServer returns a node that reports it is syncing for the first synced calls to eth_syncing
+func newSyncingServer(synced int64, calls *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "net_peerCount":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x1"}`, req.ID)
+		case "eth_syncing":
+			if atomic.AddInt64(calls, 1) > synced {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":false}`, req.ID)
+				return
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"startingBlock":"0x0","currentBlock":"0x64","highestBlock":"0x190"}}`, req.ID)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestConnection_CheckSync(t *testing.T) {
+	var calls int64
+	server := newSyncingServer(2, &calls)
+	defer server.Close()
+
+	// A syncing node is only logged unless waitForSync is set
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	syncing, progress, err := conn.CheckSync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !syncing || progress.CurrentBlock != 100 || progress.HighestBlock != 400 {
+		t.Fatalf("expected the node to be syncing at block 100 of 400, got: %v, %+v", syncing, progress)
+	}
+	if progress.Ratio() != 0.25 {
+		t.Fatalf("expected a sync progress of 0.25, got: %v", progress.Ratio())
+	}
+
+	syncing, _, err = conn.CheckSync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syncing {
+		t.Fatal("expected the node to be synced")
+	}
+}
+
+func TestConnection_WaitForSync(t *testing.T) {
+	interval := SyncRetryInterval
+	SyncRetryInterval = time.Millisecond
+	defer func() { SyncRetryInterval = interval }()
+
+	var calls int64
+	server := newSyncingServer(2, &calls)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetWaitForSync(true)
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if atomic.LoadInt64(&calls) != 3 {
+		t.Fatalf("expected to connect once the node synced on the third check, got: %d", atomic.LoadInt64(&calls))
+	}
+
+	// Connecting fails if the node does not sync in time
+	server = newSyncingServer(1000, &calls)
+	defer server.Close()
+	conn = NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetWaitForSync(true)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err = conn.ConnectWithContext(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the connection to time out, got: %v", err)
+	}
+}
+
+func TestConnection_RequestId(t *testing.T) {
+	var lock sync.Mutex
+	received := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		received[r.Header.Get(RequestIdHeader)] = true
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x10"}`, req.ID)
+	}))
+	defer server.Close()
+
+	var records []*log15.Record
+	logger := log15.New()
+	logger.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, r)
+		return nil
+	}))
+
+	conn := NewConnection(server.URL, true, nil, logger, GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Ignore the peer count requested while connecting
+	lock.Lock()
+	received = make(map[string]bool)
+	records = nil
+	lock.Unlock()
+
+	const calls = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := conn.GetNonce(context.Background(), ethcmn.HexToAddress(AliceKp.Address()), NonceStatePending)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each call is logged once with the ID the node received
+	logged := make(map[string]bool)
+	for _, r := range records {
+		if r.Msg != "RPC request" {
+			continue
+		}
+		ctx := make(map[interface{}]interface{})
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			ctx[r.Ctx[i]] = r.Ctx[i+1]
+		}
+		requestId, _ := ctx["requestId"].(string)
+		if !received[requestId] {
+			t.Fatalf("logged request ID %q was not received by the node", requestId)
+		}
+		if logged[requestId] {
+			t.Fatalf("request ID %s logged more than once", requestId)
+		}
+		if ctx["status"] != http.StatusOK {
+			t.Fatalf("expected status %d, got: %v", http.StatusOK, ctx["status"])
+		}
+		logged[requestId] = true
+	}
+	if len(logged) != calls || len(received) != calls {
+		t.Fatalf("expected %d unique request IDs, logged %d and received %d", calls, len(logged), len(received))
+	}
+}
+
+func TestSearchBlockByTimestamp(t *testing.T) {
+	// Timestamps can repeat between blocks, the last matching block is returned
+	times := []uint64{100, 112, 112, 130, 131, 150, 200}
+	latest := uint64(len(times) - 1)
+	blockTime := func(ctx context.Context, block uint64) (uint64, error) {
+		return times[block], nil
+	}
+
+	for _, test := range []struct {
+		ts       int64
+		expected int64
+	}{
+		{100, 0},
+		{111, 0},
+		{112, 2},
+		{129, 2},
+		{130, 3},
+		{199, 5},
+		{200, 6},
+		{1000, 6},
+	} {
+		block, err := searchBlockByTimestamp(context.Background(), latest, times[latest], time.Unix(test.ts, 0), blockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Int64() != test.expected {
+			t.Fatalf("timestamp %d: expected block %d, got %s", test.ts, test.expected, block)
+		}
+	}
+
+	_, err := searchBlockByTimestamp(context.Background(), latest, times[latest], time.Unix(99, 0), blockTime)
+	if !errors.Is(err, ErrBeforeGenesis) {
+		t.Fatalf("expected ErrBeforeGenesis, got: %v", err)
+	}
+}
+
+func TestConnection_BlockByTimestamp(t *testing.T) {
+	conn := NewConnection(TestEndpoint, false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	latest, err := conn.LatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := new(big.Int).Sub(latest, big.NewInt(2))
+	header, err := conn.Client().HeaderByNumber(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := conn.BlockByTimestamp(context.Background(), time.Unix(int64(header.Time), 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A later block may share the target's timestamp
+	if block.Cmp(target) < 0 || block.Cmp(latest) > 0 {
+		t.Fatalf("expected a block from %s to %s, got %s", target, latest, block)
+	}
+	blockHeader, err := conn.Client().HeaderByNumber(context.Background(), block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockHeader.Time != header.Time {
+		t.Fatalf("expected block timestamp %d, got %d", header.Time, blockHeader.Time)
+	}
+}
+
+func TestSearchDeploymentBlock(t *testing.T) {
+	// Deployed at block 50 of 200
+	var lookups int
+	hasCode := func(ctx context.Context, block uint64) (bool, error) {
+		lookups++
+		return block >= 50, nil
+	}
+
+	block, err := searchDeploymentBlock(context.Background(), 200, hasCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 50 {
+		t.Fatalf("expected block 50, got %s", block)
+	}
+	if lookups > 8 {
+		t.Fatalf("expected at most 8 lookups, got %d", lookups)
+	}
+
+	for _, deployment := range []uint64{0, 1, 199, 200} {
+		block, err := searchDeploymentBlock(context.Background(), 200, func(ctx context.Context, block uint64) (bool, error) {
+			return block >= deployment, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Uint64() != deployment {
+			t.Fatalf("expected block %d, got %s", deployment, block)
+		}
+	}
+}
+
+// newCodeServer serves a chain of latest blocks, with code at addr from block deployment
+func newCodeServer(latest, deployment uint64, addr ethcmn.Address) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = hexutil.Uint64(latest)
+		case "eth_getCode":
+			var account ethcmn.Address
+			var block hexutil.Uint64
+			if len(req.Params) != 2 || json.Unmarshal(req.Params[0], &account) != nil || json.Unmarshal(req.Params[1], &block) != nil {
+				http.Error(w, "invalid params", http.StatusBadRequest)
+				return
+			}
+			code := hexutil.Bytes{}
+			if account == addr && uint64(block) >= deployment {
+				code = hexutil.Bytes{0x60, 0x80}
+			}
+			result = code
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "unexpected request"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestConnection_GetContractDeploymentBlock(t *testing.T) {
+	bridge := ethcmn.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	server := newCodeServer(200, 50, bridge)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	block, err := conn.GetContractDeploymentBlock(context.Background(), bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Int64() != 50 {
+		t.Fatalf("expected block 50, got %s", block)
+	}
+
+	_, err = conn.GetContractDeploymentBlock(context.Background(), ethcmn.HexToAddress("0x1"))
+	var contractErr *bridgeErrors.ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != bridgeErrors.CodeNoBytecode {
+		t.Fatalf("expected a missing bytecode error, got: %v", err)
+	}
+}
+
+func TestConnection_EstimateGasWithBuffer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.Method != "eth_estimateGas" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0xc350"}`, req.ID) // 50000
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		gasLimit int64
+		pct      float64
+		expected uint64
+	}{
+		{GasLimit.Int64(), 0.2, 60000},
+		{GasLimit.Int64(), 0, 50000},
+		{55000, 0.2, 55000}, // Capped at the gas limit
+	} {
+		conn := NewConnection(server.URL, true, nil, log15.Root(), big.NewInt(test.gasLimit), MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+		err := conn.Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gas, err := conn.EstimateGasWithBuffer(context.Background(), eth.CallMsg{}, test.pct)
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gas != test.expected {
+			t.Fatalf("gas limit %d, buffer %v: expected %d, got %d", test.gasLimit, test.pct, test.expected, gas)
+		}
+	}
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.EstimateGasWithBuffer(context.Background(), eth.CallMsg{}, -0.1)
+	if err == nil {
+		t.Fatal("expected error for negative buffer")
+	}
+}
+
+// newHeaderServer returns a mock RPC serving eth_getBlockByNumber, which waits for latency before each HTTP
+// response. Batches are rejected if batches is false. The number of HTTP requests is added to requests.
+func newHeaderServer(latency time.Duration, batches bool, requests *int64) *httptest.Server {
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	respond := func(req request) interface{} {
+		var number hexutil.Big
+		if req.Method != "eth_getBlockByNumber" || len(req.Params) != 2 || json.Unmarshal(req.Params[0], &number) != nil {
+			return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "unexpected request"}}
+		}
+		header := &types.Header{Number: number.ToInt(), Difficulty: big.NewInt(1), Time: number.ToInt().Uint64() * 15}
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": header}
+	}
+
+	var lock sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		*requests++
+		lock.Unlock()
+		time.Sleep(latency)
+
+		var body json.RawMessage
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		var req request
+		if json.Unmarshal(body, &req) == nil {
+			_ = json.NewEncoder(w).Encode(respond(req))
+			return
+		}
+		var batch []request
+		if !batches || json.Unmarshal(body, &batch) != nil {
+			http.Error(w, "batches not supported", http.StatusBadRequest)
+			return
+		}
+		responses := make([]interface{}, len(batch))
+		for i := range batch {
+			responses[i] = respond(batch[i])
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func TestConnection_BatchGetBlockHeaders(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		batches  bool // Whether the server supports batches
+		batchRPC bool
+		count    int64
+		requests int64
+	}{
+		{"batch", true, true, 10, 1},
+		{"multiple batches", true, true, MaxBatchSize + 1, 2},
+		{"fallback", false, true, 10, 11},
+		{"disabled", true, false, 10, 10},
+	} {
+		var requests int64
+		server := newHeaderServer(0, test.batches, &requests)
+		conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+		conn.SetBatchRPC(test.batchRPC)
+		err := conn.Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests = 0 // Ignore the peer count requested while connecting
+
+		from := big.NewInt(5)
+		headers, err := conn.BatchGetBlockHeaders(context.Background(), from, big.NewInt(5+test.count-1))
+		conn.Close()
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if int64(len(headers)) != test.count {
+			t.Fatalf("%s: expected %d headers, got %d", test.name, test.count, len(headers))
+		}
+		for i, header := range headers {
+			if header.Number.Int64() != from.Int64()+int64(i) {
+				t.Fatalf("%s: expected header %d, got %s", test.name, from.Int64()+int64(i), header.Number)
+			}
+		}
+		if requests != test.requests {
+			t.Fatalf("%s: expected %d requests, got %d", test.name, test.requests, requests)
+		}
+	}
+
+	conn := NewConnection(TestEndpoint, false, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	_, err := conn.BatchGetBlockHeaders(context.Background(), big.NewInt(2), big.NewInt(1))
+	if err == nil {
+		t.Fatal("expected error for invalid range")
+	}
+}
+
+func benchmarkGetBlockHeaders(b *testing.B, batchRPC bool) {
+	var requests int64
+	server := newHeaderServer(time.Millisecond, true, &requests)
+	defer server.Close()
+	// Logging each request would dominate the sequential benchmark
+	log := log15.New()
+	log.SetHandler(log15.DiscardHandler())
+	conn := NewConnection(server.URL, true, nil, log, GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetBatchRPC(batchRPC)
+	err := conn.Connect()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = conn.BatchGetBlockHeaders(context.Background(), big.NewInt(1), big.NewInt(100))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// The mock RPC adds 1ms of latency to each request
+func BenchmarkGetBlockHeaders_Batched(b *testing.B) {
+	benchmarkGetBlockHeaders(b, true)
+}
+
+func BenchmarkGetBlockHeaders_Sequential(b *testing.B) {
+	benchmarkGetBlockHeaders(b, false)
+}
+
+func TestConnection_GetTransactionTrace(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts, err := ethutils.DeployContracts(client, 0, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bridgeInstance, err := Bridge.NewBridge(contracts.BridgeAddress, client.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Executing a proposal that does not exist reverts, the gas limit is set so it is not estimated
+	err = ethutils.UpdateNonce(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := bridgeInstance.ExecuteProposal(client.Opts, 1, 1, []byte{}, [32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, client.Client, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusFailed {
+		t.Fatal("expected transaction to revert")
+	}
+
+	conn := NewConnection(TestEndpoint, false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err = conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	trace, err := conn.GetTransactionTrace(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Calls) == 0 || *trace.Calls[0].To != contracts.BridgeAddress {
+		t.Fatalf("unexpected trace: %+v", trace)
+	}
+	if trace.RevertReason() == "" {
+		t.Fatalf("expected a revert reason, got: %+v", trace.Calls[0])
+	}
+}
+
+func TestConnection_GetTransactionTrace_Unsupported(t *testing.T) {
+	// The mock server rejects every method other than eth_getBlockByNumber as not found
+	var requests int64
+	server := newHeaderServer(0, false, &requests)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.GetTransactionTrace(context.Background(), ethcmn.Hash{1})
+	if !errors.Is(err, ErrTraceUnsupported) {
+		t.Fatalf("expected ErrTraceUnsupported, got: %v", err)
+	}
+}
+
+func TestNameHash(t *testing.T) {
+	// Test vectors from EIP-137
+	for name, expected := range map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	} {
+		if node := NameHash(name); node.Hex() != expected {
+			t.Errorf("namehash of %q: expected %s, got %s", name, expected, node.Hex())
+		}
+	}
+}
+
+// newENSServer serves eth_call for a mock ENS registry that maps name to addr with a single resolver
+func newENSServer(registry, resolver ethcmn.Address, name string, addr ethcmn.Address, calls *int64) *httptest.Server {
+	node := NameHash(name)
+	selector := func(method string) []byte { return ensABI.Methods[method].ID }
+	var lock sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		var call struct {
+			To   ethcmn.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		if req.Method != "eth_call" || len(req.Params) == 0 || json.Unmarshal(req.Params[0], &call) != nil {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "unexpected request"}})
+			return
+		}
+		lock.Lock()
+		*calls++
+		lock.Unlock()
+
+		// Unknown nodes resolve to the zero address
+		var result ethcmn.Address
+		known := len(call.Data) == 36 && ethcmn.BytesToHash(call.Data[4:]) == node
+		if call.To == registry && known && bytes.Equal(call.Data[:4], selector("resolver")) {
+			result = resolver
+		} else if call.To == resolver && known && bytes.Equal(call.Data[:4], selector("addr")) {
+			result = addr
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Bytes(ethcmn.LeftPadBytes(result[:], 32))})
+	}))
+}
+
+func TestConnection_ResolveENS(t *testing.T) {
+	registry := ethcmn.HexToAddress("0x0000000000000000000000000000000000000e25")
+	resolver := ethcmn.HexToAddress("0x000000000000000000000000000000000000abcd")
+	bridge := ethcmn.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	var calls int64
+	server := newENSServer(registry, resolver, "bridge.chainbridge.eth", bridge, &calls)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	conn.SetENS(registry, time.Minute)
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	addr, err := conn.ResolveENS(context.Background(), "Bridge.ChainBridge.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != bridge {
+		t.Fatalf("expected %s, got %s", bridge.Hex(), addr.Hex())
+	}
+	if calls != 2 {
+		t.Fatalf("expected a registry and a resolver call, got %d calls", calls)
+	}
+
+	// Cached until the cache is cleared
+	_, err = conn.ResolveENS(context.Background(), "bridge.chainbridge.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the cached address, got %d calls", calls)
+	}
+	conn.ClearENSCache()
+	_, err = conn.ResolveENS(context.Background(), "bridge.chainbridge.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected the name to be resolved again, got %d calls", calls)
+	}
+
+	_, err = conn.ResolveENS(context.Background(), "unknown.chainbridge.eth")
+	if !errors.Is(err, ErrENSNameNotFound) {
+		t.Fatalf("expected ErrENSNameNotFound, got: %v", err)
+	}
+}
+
+func TestConnection_SubscribePendingTxs(t *testing.T) {
+	conn := NewConnection(TestEndpoint, false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	hashes := make(chan ethcmn.Hash, 16)
+	sub, err := conn.SubscribePendingTxs(ctx, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	err = ethutils.UpdateNonce(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := keystore.TestKeyRing.EthereumKeys[keystore.BobKey].CommonAddress()
+	tx := types.NewTransaction(client.Opts.Nonce.Uint64(), bob, big.NewInt(1), 21000, client.Opts.GasPrice, nil)
+	signed, err := client.Opts.Signer(AliceKp.CommonAddress(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Client.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		select {
+		case hash := <-hashes:
+			if hash == signed.Hash() {
+				return
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("transaction was not announced")
+		}
+	}
+}
+
+func TestConnection_SubscribePendingTxs_Http(t *testing.T) {
+	var requests int64
+	server := newHeaderServer(0, false, &requests)
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.SubscribePendingTxs(context.Background(), make(chan ethcmn.Hash))
+	if err == nil {
+		t.Fatal("expected error subscribing over http")
+	}
+}
+
+// headService serves the latest header and new head subscriptions from an in-process RPC server. Each request for
+// the latest header returns the next block, and subscribers are sent the 3 blocks after the latest one requested.
+type headService struct {
+	polls int64
+}
+
+func (s *headService) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	n := atomic.AddInt64(&s.polls, 1)
+	return &types.Header{Number: big.NewInt(n), Difficulty: big.NewInt(1)}, nil
+}
+
+func (s *headService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	latest := atomic.LoadInt64(&s.polls)
+	go func() {
+		for i := latest + 1; i <= latest+3; i++ {
+			_ = notifier.Notify(sub.ID, &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(1)})
+		}
+	}()
+	return sub, nil
+}
+
+func TestConnection_WatchBlockHeaders(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		http  bool
+		polls bool
+	}{
+		{name: "subscription", http: false, polls: false},
+		{name: "poll", http: true, polls: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			service := &headService{}
+			server := rpc.NewServer()
+			err := server.RegisterName("eth", service)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer server.Stop()
+			handler := http.Handler(server)
+			if !test.http {
+				handler = server.WebsocketHandler([]string{"*"})
+			}
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+			endpoint := httpServer.URL
+			if !test.http {
+				endpoint = "ws" + endpoint[len("http"):]
+			}
+
+			conn := NewConnection(endpoint, test.http, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+			conn.pollingInterval = 10 * time.Millisecond
+			err = conn.Connect()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			headers := make(chan *types.Header)
+			done := make(chan error)
+			go func() {
+				done <- conn.WatchBlockHeaders(ctx, headers)
+			}()
+
+			for i := int64(1); i <= 3; i++ {
+				select {
+				case header := <-headers:
+					if header.Number.Int64() != i {
+						t.Fatalf("expected block %d, got: %s", i, header.Number)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("block %d was not delivered", i)
+				}
+			}
+			cancel()
+			err = <-done
+			if err != nil {
+				t.Fatalf("expected no error once cancelled, got: %v", err)
+			}
+			// The current head is requested once before subscribing
+			if polled := atomic.LoadInt64(&service.polls) > 1; polled != test.polls {
+				t.Fatalf("expected polling to be %v, got: %v", test.polls, polled)
+			}
+		})
+	}
+}
+
+// txPoolService serves txpool_content from an in-process RPC server
+type txPoolService struct {
+	content map[string]map[string]map[string]*types.Transaction
+}
+
+func (s *txPoolService) Content() map[string]map[string]map[string]*types.Transaction {
+	return s.content
+}
+
+func TestConnection_GetTxPoolContent(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(AliceKp.PrivateKey(), signer, &types.LegacyTx{Nonce: 5, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := AliceKp.CommonAddress().Hex()
+	service := &txPoolService{content: map[string]map[string]map[string]*types.Transaction{
+		"pending": {},
+		"queued":  {from: {"5": tx}},
+	}}
+	server := rpc.NewServer()
+	err = server.RegisterName("txpool", service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	conn := NewConnection(httpServer.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err = conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	pending, queued, err := conn.GetTxPoolContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending transactions, got: %v", pending)
+	}
+	if got := queued[from]["5"]; got == nil || got.Hash() != tx.Hash() {
+		t.Fatalf("expected queued transaction %s, got: %v", tx.Hash(), queued)
+	}
+}
+
+func TestConnection_GetTxPoolContentUnsupported(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	conn := NewConnection(httpServer.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, _, err = conn.GetTxPoolContent()
+	if !errors.Is(err, ErrTxPoolUnsupported) {
+		t.Fatalf("expected ErrTxPoolUnsupported, got: %v", err)
+	}
+}
+
+func TestConnection_GetContractStorageProof(t *testing.T) {
+	client := ethtest.NewClient(t, TestEndpoint, AliceKp)
+	contracts, err := ethutils.DeployContracts(client, 1, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := NewConnection(TestEndpoint, false, AliceKp, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err = conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	block, err := conn.LatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bridge's first slots hold its chain ID, relayer threshold and other settings, so some are set
+	keys := make([]ethcmn.Hash, 16)
+	for i := range keys {
+		keys[i] = ethcmn.BigToHash(big.NewInt(int64(i)))
+	}
+	proof, err := conn.GetContractStorageProof(context.Background(), contracts.BridgeAddress, keys, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Address != contracts.BridgeAddress || len(proof.AccountProof) == 0 {
+		t.Fatalf("unexpected account proof: %+v", proof)
+	}
+	if len(proof.StorageProofs) != len(keys) {
+		t.Fatalf("expected %d storage proofs, got: %d", len(keys), len(proof.StorageProofs))
+	}
+
+	set := 0
+	for i, entry := range proof.StorageProofs {
+		stored, err := conn.Client().StorageAt(context.Background(), contracts.BridgeAddress, keys[i], block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Key != keys[i] || entry.Value.Cmp(new(big.Int).SetBytes(stored)) != 0 {
+			t.Fatalf("slot %d: expected value %x, got: %+v", i, stored, entry)
+		}
+		if entry.Value.Sign() != 0 {
+			set++
+			if len(entry.Proof) == 0 {
+				t.Fatalf("slot %d: expected a proof of the set value", i)
+			}
+		}
+	}
+	if set == 0 {
+		t.Fatal("expected some of the bridge's slots to be set")
+	}
+}
+
+// newTokenServer serves eth_call for a token contract, with the result returned by respond for the call's data
+func newTokenServer(token ethcmn.Address, respond func(data []byte) []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		var call struct {
+			To   ethcmn.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		if req.Method != "eth_call" || len(req.Params) == 0 || json.Unmarshal(req.Params[0], &call) != nil {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "unexpected request"}})
+			return
+		}
+
+		// Calls to other addresses have no code
+		var result []byte
+		if call.To == token {
+			result = respond(call.Data)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Bytes(result)})
+	}))
+}
+
+func TestConnection_GetTokenBalance(t *testing.T) {
+	token := ethcmn.HexToAddress("0x21605f71845f372A9ed84253d2D024B7B10999f4")
+	holder := ethcmn.HexToAddress("0xff93B45308FD417dF303D6515aB04D9e89a750Ca")
+	balance, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	server := newTokenServer(token, func(data []byte) []byte {
+		if !bytes.Equal(data[:4], tokenABI.Methods["balanceOf"].ID) {
+			return nil
+		}
+		// Only the holder has a balance
+		if ethcmn.BytesToAddress(data[4:]) != holder {
+			return make([]byte, 32)
+		}
+		return ethcmn.LeftPadBytes(balance.Bytes(), 32)
+	})
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	got, err := conn.GetTokenBalance(context.Background(), token, holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(balance) != 0 {
+		t.Fatalf("expected balance %s, got: %s", balance, got)
+	}
+	got, err = conn.GetTokenBalance(context.Background(), token, ethcmn.HexToAddress("0x1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sign() != 0 {
+		t.Fatalf("expected no balance, got: %s", got)
+	}
+
+	_, err = conn.GetTokenBalance(context.Background(), ethcmn.HexToAddress("0x2"), holder)
+	var contractErr *bridgeErrors.ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != bridgeErrors.CodeNoBytecode {
+		t.Fatalf("expected a no bytecode error, got: %v", err)
+	}
+}
+
+func TestConnection_GetNFTOwner(t *testing.T) {
+	token := ethcmn.HexToAddress("0xd7E33e1bbf65dC001A0Eb1552613106CD7e40C31")
+	owner := ethcmn.HexToAddress("0xff93B45308FD417dF303D6515aB04D9e89a750Ca")
+	server := newTokenServer(token, func(data []byte) []byte {
+		if !bytes.Equal(data[:4], tokenABI.Methods["ownerOf"].ID) || new(big.Int).SetBytes(data[4:]).Cmp(big.NewInt(42)) != 0 {
+			return []byte{0x01}
+		}
+		return ethcmn.LeftPadBytes(owner.Bytes(), 32)
+	})
+	defer server.Close()
+
+	conn := NewConnection(server.URL, true, nil, log15.Root(), GasLimit, MaxGasPrice, MinGasPrice, GasMultipler, "", "")
+	err := conn.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	got, err := conn.GetNFTOwner(context.Background(), token, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != owner {
+		t.Fatalf("expected owner %s, got: %s", owner.Hex(), got.Hex())
+	}
+
+	// Output that is not an address can not be unpacked
+	_, err = conn.GetNFTOwner(context.Background(), token, big.NewInt(43))
+	var contractErr *bridgeErrors.ContractError
+	if !errors.As(err, &contractErr) || contractErr.Code != bridgeErrors.CodeCallFailed {
+		t.Fatalf("expected a call failed error, got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultENSRegistry is the address of the ENS registry on mainnet and the public testnets
+var DefaultENSRegistry = ethcommon.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// DefaultENSCacheTTL is how long resolved ENS names are cached
+const DefaultENSCacheTTL = 10 * time.Minute
+
+// ErrENSNameNotFound is returned by ResolveENS if the name has no resolver or no address
+var ErrENSNameNotFound = errors.New("ENS name has no address")
+
+// The registry's resolver and the resolver's addr methods
+var ensABI, _ = abi.JSON(strings.NewReader(`[
+	{"name":"resolver","type":"function","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"name":"addr","type":"function","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]}
+]`))
+
+// ensEntry is a cached resolution
+type ensEntry struct {
+	addr    ethcommon.Address
+	expires time.Time
+}
+
+// SetENS sets the ENS registry names are resolved with and how long resolutions are cached. Defaults to
+// DefaultENSRegistry and DefaultENSCacheTTL.
+func (c *Connection) SetENS(registry ethcommon.Address, ttl time.Duration) {
+	c.ensLock.Lock()
+	defer c.ensLock.Unlock()
+	c.ensRegistry = registry
+	c.ensCacheTTL = ttl
+}
+
+// ClearENSCache discards all cached resolutions, so names are resolved again
+func (c *Connection) ClearENSCache() {
+	c.ensLock.Lock()
+	defer c.ensLock.Unlock()
+	c.ensCache = make(map[string]ensEntry)
+}
+
+// ResolveENS returns the address an ENS name (eg. bridge.chainbridge.eth) resolves to. Names are only lower
+// cased, not fully normalized. ErrENSNameNotFound is returned if the name is not registered or has no address.
+func (c *Connection) ResolveENS(ctx context.Context, name string) (ethcommon.Address, error) {
+	name = strings.ToLower(name)
+
+	c.ensLock.Lock()
+	registry := c.ensRegistry
+	ttl := c.ensCacheTTL
+	entry, ok := c.ensCache[name]
+	c.ensLock.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addr, nil
+	}
+
+	node := NameHash(name)
+	resolver, err := c.callENS(ctx, registry, "resolver", node)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	if resolver == (ethcommon.Address{}) {
+		return ethcommon.Address{}, fmt.Errorf("%w: %s", ErrENSNameNotFound, name)
+	}
+	addr, err := c.callENS(ctx, resolver, "addr", node)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	if addr == (ethcommon.Address{}) {
+		return ethcommon.Address{}, fmt.Errorf("%w: %s", ErrENSNameNotFound, name)
+	}
+
+	c.ensLock.Lock()
+	c.ensCache[name] = ensEntry{addr: addr, expires: time.Now().Add(ttl)}
+	c.ensLock.Unlock()
+	return addr, nil
+}
+
+// callENS calls a method of the registry or a resolver that takes a node and returns an address
+func (c *Connection) callENS(ctx context.Context, contract ethcommon.Address, method string, node ethcommon.Hash) (ethcommon.Address, error) {
+	data, err := ensABI.Pack(method, node)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	out, err := c.conn.CallContract(ctx, eth.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return ethcommon.Address{}, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	if len(out) == 0 {
+		return ethcommon.Address{}, bridgeErrors.NewContractError(bridgeErrors.CodeNoBytecode, false, fmt.Errorf("no ENS contract at %s", contract.Hex()))
+	}
+	addr := new(ethcommon.Address)
+	err = ensABI.UnpackIntoInterface(addr, method, out)
+	if err != nil {
+		return ethcommon.Address{}, bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, false, err)
+	}
+	return *addr, nil
+}
+
+// NameHash returns the ENS node of a name, as specified by EIP-137
+func NameHash(name string) ethcommon.Hash {
+	var node ethcommon.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = ethcrypto.Keccak256Hash(node[:], ethcrypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
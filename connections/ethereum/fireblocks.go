@@ -0,0 +1,37 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ChainSafe/ChainBridge/crypto/fireblocks"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrFireblocksRotation = errors.New("keys held by fireblocks cannot be rotated")
+
+// newFireblocksTransactor returns TransactOpts that sign transactions for chainId with a Fireblocks raw signing
+// request. Signing waits for the request to be approved and completed.
+func newFireblocksTransactor(kp *fireblocks.FireblocksKeypair, chainId *big.Int) *bind.TransactOpts {
+	signer := types.LatestSignerForChainID(chainId)
+	return &bind.TransactOpts{
+		From: kp.CommonAddress(),
+		Signer: func(address ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != kp.CommonAddress() {
+				return nil, bind.ErrNotAuthorized
+			}
+			signature, err := kp.SignRecoverable(signer.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, signature)
+		},
+		Context: context.Background(),
+	}
+}
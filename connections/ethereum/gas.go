@@ -0,0 +1,130 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+// GasConfigPollInterval is how often a GasConfigWatcher checks its file for changes
+var GasConfigPollInterval = time.Second * 5
+
+// GasConfig overrides the gas parameters of the transactions a connection sends. Zero fields keep the values the
+// connection was created with.
+type GasConfig struct {
+	GasPrice           *big.Int `json:"gasPrice"`           // Price per gas in wei, used instead of estimating it
+	GasLimit           uint64   `json:"gasLimit"`           // Most gas a transaction may use
+	GasPriceMultiplier float64  `json:"gasPriceMultiplier"` // Applied to estimated gas prices
+}
+
+// LoadGasConfig reads a GasConfig from the JSON file at path
+func LoadGasConfig(path string) (*GasConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &GasConfig{}
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse gas config %s: %w", path, err)
+	}
+	if cfg.GasPrice != nil && cfg.GasPrice.Sign() < 0 {
+		return nil, fmt.Errorf("gas config %s: gasPrice must not be negative", path)
+	}
+	if cfg.GasPriceMultiplier < 0 {
+		return nil, fmt.Errorf("gas config %s: gasPriceMultiplier must not be negative", path)
+	}
+	return cfg, nil
+}
+
+// GasConfigWatcher holds the GasConfig loaded from a file, reloading it each time SIGHUP is received or the file
+// is modified. Connections read the current config with Load each time they update their opts.
+type GasConfigWatcher struct {
+	path    string
+	current atomic.Value // Holds a *GasConfig
+	modTime time.Time    // Modification time of the file when it was last read, only used by the watch routine
+	log     log15.Logger
+	stop    <-chan int
+}
+
+// NewGasConfigWatcher loads the gas config at path, returning an error if it cannot be read. Once started the
+// watcher runs until stop is closed.
+func NewGasConfigWatcher(path string, log log15.Logger, stop <-chan int) (*GasConfigWatcher, error) {
+	w := &GasConfigWatcher{path: path, log: log, stop: stop}
+	err := w.reload()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Load returns the most recently loaded config
+func (w *GasConfigWatcher) Load() *GasConfig {
+	return w.current.Load().(*GasConfig)
+}
+
+// Start reloads the config on SIGHUP or when the file is modified. The file is checked every GasConfigPollInterval.
+// If a reload fails the previous config is kept.
+func (w *GasConfigWatcher) Start() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	ticker := time.NewTicker(GasConfigPollInterval)
+	go func() {
+		defer signal.Stop(signals)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-signals:
+				w.logReload(w.reload())
+			case <-ticker.C:
+				info, err := os.Stat(w.path)
+				if err != nil {
+					w.log.Error("Unable to stat gas config", "path", w.path, "err", err)
+					continue
+				}
+				if !info.ModTime().Equal(w.modTime) {
+					w.logReload(w.reload())
+				}
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// reload reads the file and replaces the current config. The modification time is recorded even if the file is
+// invalid, so it is not read again until it changes.
+func (w *GasConfigWatcher) reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	w.modTime = info.ModTime()
+	cfg, err := LoadGasConfig(w.path)
+	if err != nil {
+		return err
+	}
+	w.current.Store(cfg)
+	return nil
+}
+
+func (w *GasConfigWatcher) logReload(err error) {
+	if err != nil {
+		w.log.Error("Unable to reload gas config, keeping the previous config", "path", w.path, "err", err)
+		return
+	}
+	cfg := w.Load()
+	w.log.Info("Reloaded gas config", "path", w.path, "gasPrice", cfg.GasPrice, "gasLimit", cfg.GasLimit, "gasPriceMultiplier", cfg.GasPriceMultiplier)
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+func writeGasConfig(t *testing.T, path, data string) {
+	err := ioutil.WriteFile(path, []byte(data), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitForGasPrice(t *testing.T, w *GasConfigWatcher, expected *big.Int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Load().GasPrice.Cmp(expected) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected gas price %s, got: %s", expected, w.Load().GasPrice)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoadGasConfig(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "gas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gas.json")
+
+	writeGasConfig(t, path, `{"gasPrice": 1000000000, "gasLimit": 500000, "gasPriceMultiplier": 1.5}`)
+	cfg, err := LoadGasConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GasPrice.Cmp(big.NewInt(1000000000)) != 0 || cfg.GasLimit != 500000 || cfg.GasPriceMultiplier != 1.5 {
+		t.Fatalf("unexpected gas config: %+v", cfg)
+	}
+
+	for _, data := range []string{`{"gasPrice": "1 gwei"}`, `{"gasPrice": -1}`, `{"gasPriceMultiplier": -1}`} {
+		writeGasConfig(t, path, data)
+		_, err = LoadGasConfig(path)
+		if err == nil {
+			t.Fatalf("expected error loading %s", data)
+		}
+	}
+}
+
+func TestGasConfigWatcher_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "gas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gas.json")
+
+	// Only reload on SIGHUP until the poll interval is shortened below
+	interval := GasConfigPollInterval
+	GasConfigPollInterval = time.Hour
+	defer func() { GasConfigPollInterval = interval }()
+
+	writeGasConfig(t, path, `{"gasPrice": 1000000000}`)
+	stop := make(chan int)
+	defer close(stop)
+	w, err := NewGasConfigWatcher(path, log15.Root(), stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Start()
+	waitForGasPrice(t, w, big.NewInt(1000000000))
+
+	writeGasConfig(t, path, `{"gasPrice": 2000000000}`)
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForGasPrice(t, w, big.NewInt(2000000000))
+
+	// An invalid config is ignored
+	writeGasConfig(t, path, `{"gasPrice": -1}`)
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	waitForGasPrice(t, w, big.NewInt(2000000000))
+}
+
+func TestGasConfigWatcher_Modified(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "gas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gas.json")
+
+	interval := GasConfigPollInterval
+	GasConfigPollInterval = 10 * time.Millisecond
+	defer func() { GasConfigPollInterval = interval }()
+
+	writeGasConfig(t, path, `{"gasPrice": 1000000000}`)
+	stop := make(chan int)
+	defer close(stop)
+	w, err := NewGasConfigWatcher(path, log15.Root(), stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Start()
+
+	// Modification times may have a coarse resolution, so make sure the new one differs
+	writeGasConfig(t, path, `{"gasPrice": 2000000000}`)
+	later := time.Now().Add(time.Minute)
+	err = os.Chtimes(path, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForGasPrice(t, w, big.NewInt(2000000000))
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+// RequestIdHeader identifies each http RPC request, so it can be correlated with the node's logs
+const RequestIdHeader = "X-Request-ID"
+
+// requestIdTransport sets a new request ID on every request and logs it with the response status
+type requestIdTransport struct {
+	base http.RoundTripper
+	log  log15.Logger
+}
+
+func newRequestIdTransport(base http.RoundTripper, log log15.Logger) *requestIdTransport {
+	return &requestIdTransport{base: base, log: log}
+}
+
+func (t *requestIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestId, err := newRequestId()
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(RequestIdHeader, requestId)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.log.Debug("RPC request failed", "requestId", requestId, "duration", time.Since(start), "err", err)
+		return nil, err
+	}
+	t.log.Trace("RPC request", "requestId", requestId, "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
+
+// newRequestId returns a random (version 4) UUID
+func newRequestId() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]), nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The safe package sends relayer transactions through a Gnosis Safe (v1.3.0), so the Safe is the relayer address
+registered with the bridge.
+
+Transactions are proposed to the Safe Transaction Service, signed by the relayer key as one of the Safe's owners.
+Once the other owners have confirmed them the relayer key sends them with execTransaction.
+*/
+package safe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ExecGasOverhead is added to the gas limit of each transaction for execTransaction's signature checks
+const ExecGasOverhead = 100000
+
+// Origin identifies the relayer in proposals to the transaction service
+const Origin = "chainbridge"
+
+// PollInterval is the time between checks for confirmations
+var PollInterval = 5 * time.Second
+
+// ConfirmationTimeout is the maximum time to wait for the other owners to confirm a transaction
+var ConfirmationTimeout = 30 * time.Minute
+
+var ErrAlreadyExecuted = errors.New("safe transaction was executed by another owner")
+var ErrConfirmationTimeout = errors.New("timed out waiting for safe transaction confirmations")
+
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+const safeABI = `[{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"","type":"bool"}],"stateMutability":"payable","type":"function"}]`
+
+var parsedSafeABI, _ = abi.JSON(strings.NewReader(safeABI))
+
+// SafeKeypair signs transactions for a Safe that kp is an owner of
+type SafeKeypair struct {
+	kp      *secp256k1.Keypair
+	safe    common.Address
+	chainId *big.Int
+	service *Service
+	log     log15.Logger
+}
+
+func NewSafeKeypair(kp *secp256k1.Keypair, safe common.Address, serviceURL string, chainId *big.Int, log log15.Logger) *SafeKeypair {
+	return &SafeKeypair{kp: kp, safe: safe, chainId: chainId, service: NewService(serviceURL), log: log}
+}
+
+// Address returns the address of the Safe
+func (s *SafeKeypair) Address() common.Address {
+	return s.safe
+}
+
+// SignTransaction proposes tx as a Safe transaction and waits until enough owners have confirmed it. It returns
+// a transaction from kp executing it through the Safe, with tx's nonce and fees. tx's value is sent by the Safe.
+func (s *SafeKeypair) SignTransaction(tx *types.Transaction) (*types.Transaction, error) {
+	if tx.To() == nil {
+		return nil, errors.New("contract deployments cannot be sent through a safe")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ConfirmationTimeout)
+	defer cancel()
+
+	info, err := s.service.Info(ctx, s.safe)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get safe nonce: %w", err)
+	}
+	hash := TransactionHash(s.chainId, s.safe, *tx.To(), tx.Value(), tx.Data(), info.Nonce)
+	signature, err := s.sign(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.Propose(ctx, s.safe, &Proposal{
+		To:                      *tx.To(),
+		Value:                   tx.Value().String(),
+		Data:                    hexutil.Encode(tx.Data()),
+		SafeTxGas:               "0",
+		BaseGas:                 "0",
+		GasPrice:                "0",
+		Nonce:                   info.Nonce,
+		ContractTransactionHash: hash,
+		Sender:                  s.kp.CommonAddress(),
+		Signature:               hexutil.Encode(signature),
+		Origin:                  Origin,
+	})
+	// A retried transaction may already have been proposed
+	if err != nil {
+		if _, getErr := s.service.Transaction(ctx, hash); getErr != nil {
+			return nil, fmt.Errorf("unable to propose safe transaction: %w", err)
+		}
+	}
+	s.log.Info("Proposed safe transaction, waiting for confirmations", "safeTxHash", hash.Hex(), "nonce", info.Nonce)
+
+	signatures, err := s.waitForConfirmations(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parsedSafeABI.Pack("execTransaction", *tx.To(), tx.Value(), tx.Data(), uint8(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, signatures)
+	if err != nil {
+		return nil, err
+	}
+	var exec types.TxData
+	if tx.Type() == types.DynamicFeeTxType {
+		exec = &types.DynamicFeeTx{ChainID: s.chainId, Nonce: tx.Nonce(), GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap(), Gas: tx.Gas() + ExecGasOverhead, To: &s.safe, Data: data}
+	} else {
+		exec = &types.LegacyTx{Nonce: tx.Nonce(), GasPrice: tx.GasPrice(), Gas: tx.Gas() + ExecGasOverhead, To: &s.safe, Data: data}
+	}
+	return types.SignNewTx(s.kp.PrivateKey(), types.LatestSignerForChainID(s.chainId), exec)
+}
+
+// waitForConfirmations polls the transaction service until the transaction has the required confirmations and
+// returns their signatures in the order execTransaction expects
+func (s *SafeKeypair) waitForConfirmations(ctx context.Context, hash common.Hash) ([]byte, error) {
+	for {
+		tx, err := s.service.Transaction(ctx, hash)
+		if err != nil {
+			s.log.Warn("Unable to get safe transaction confirmations", "safeTxHash", hash.Hex(), "err", err)
+		} else if tx.IsExecuted {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyExecuted, hash.Hex())
+		} else if uint64(len(tx.Confirmations)) >= tx.ConfirmationsRequired {
+			return encodeSignatures(tx.Confirmations)
+		} else {
+			s.log.Debug("Waiting for safe transaction confirmations", "safeTxHash", hash.Hex(), "confirmations", len(tx.Confirmations), "required", tx.ConfirmationsRequired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s", ErrConfirmationTimeout, hash.Hex())
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+// sign returns kp's signature of hash in the format the Safe verifies, with v of 27 or 28
+func (s *SafeKeypair) sign(hash common.Hash) ([]byte, error) {
+	signature, err := crypto.Sign(hash.Bytes(), s.kp.PrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+// encodeSignatures concatenates the signatures sorted by owner, as execTransaction requires
+func encodeSignatures(confirmations []Confirmation) ([]byte, error) {
+	sorted := make([]Confirmation, len(confirmations))
+	copy(sorted, confirmations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Owner.Hex()) < strings.ToLower(sorted[j].Owner.Hex())
+	})
+
+	var signatures []byte
+	for _, c := range sorted {
+		signature, err := hexutil.Decode(c.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature from owner %s: %w", c.Owner.Hex(), err)
+		}
+		signatures = append(signatures, signature...)
+	}
+	return signatures, nil
+}
+
+// TransactionHash returns the EIP-712 hash of a Safe call to to, which the owners sign. Gas refunds are not used.
+func TransactionHash(chainId *big.Int, safe, to common.Address, value *big.Int, data []byte, nonce uint64) common.Hash {
+	word := func(b []byte) []byte { return common.LeftPadBytes(b, 32) }
+
+	domain := crypto.Keccak256(domainTypeHash.Bytes(), word(chainId.Bytes()), word(safe.Bytes()))
+	safeTx := crypto.Keccak256(
+		safeTxTypeHash.Bytes(),
+		word(to.Bytes()),
+		word(value.Bytes()),
+		crypto.Keccak256(data),
+		word(nil), // operation: call
+		word(nil), // safeTxGas
+		word(nil), // baseGas
+		word(nil), // gasPrice
+		word(nil), // gasToken
+		word(nil), // refundReceiver
+		word(new(big.Int).SetUint64(nonce).Bytes()),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, safeTx)
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package safe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+var (
+	AliceKp = keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+	BobKp   = keystore.TestKeyRing.EthereumKeys[keystore.BobKey]
+)
+
+// mockService implements the transaction service endpoints used by SafeKeypair. Once a transaction is
+// proposed, Bob confirms it after confirmAfter polls.
+type mockService struct {
+	safe         common.Address
+	chainId      *big.Int
+	nonce        uint64
+	threshold    uint64
+	confirmAfter int
+	executed     bool // Reports transactions as executed by another owner
+
+	lock     sync.Mutex
+	proposal *Proposal
+	polls    int
+}
+
+func (m *mockService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/api/v1/safes/%s/", m.safe.Hex()):
+		_ = json.NewEncoder(w).Encode(Info{Nonce: m.nonce, Threshold: m.threshold})
+	case r.Method == http.MethodPost && r.URL.Path == fmt.Sprintf("/api/v1/safes/%s/multisig-transactions/", m.safe.Hex()):
+		var p Proposal
+		err := json.NewDecoder(r.Body).Decode(&p)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		value, _ := new(big.Int).SetString(p.Value, 10)
+		data, _ := hexutil.Decode(p.Data)
+		if p.Nonce != m.nonce || p.ContractTransactionHash != TransactionHash(m.chainId, m.safe, p.To, value, data, p.Nonce) {
+			http.Error(w, "invalid contractTransactionHash", http.StatusUnprocessableEntity)
+			return
+		}
+		m.proposal = &p
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && m.proposal != nil && r.URL.Path == fmt.Sprintf("/api/v1/multisig-transactions/%s/", m.proposal.ContractTransactionHash.Hex()):
+		m.polls++
+		confirmations := []Confirmation{{Owner: m.proposal.Sender, Signature: m.proposal.Signature}}
+		if m.polls > m.confirmAfter {
+			signature, _ := crypto.Sign(m.proposal.ContractTransactionHash.Bytes(), BobKp.PrivateKey())
+			signature[64] += 27
+			confirmations = append(confirmations, Confirmation{Owner: BobKp.CommonAddress(), Signature: hexutil.Encode(signature)})
+		}
+		_ = json.NewEncoder(w).Encode(MultisigTransaction{
+			SafeTxHash:            m.proposal.ContractTransactionHash,
+			ConfirmationsRequired: m.threshold,
+			Confirmations:         confirmations,
+			IsExecuted:            m.executed,
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestTransactionHash(t *testing.T) {
+	chainId := big.NewInt(5)
+	safe := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	to := common.HexToAddress("0x2b6351b3e9b1e3a2cd99f318b4b5a95edc02b6c1")
+	data := common.FromHex("0xdeadbeef")
+
+	// Safe transactions are EIP-712 typed data
+	typedData := core.TypedData{
+		Types: core.Types{
+			"EIP712Domain": {{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain:      core.TypedDataDomain{ChainId: math.NewHexOrDecimal256(5), VerifyingContract: safe.Hex()},
+		Message: core.TypedDataMessage{
+			"to":             to.Hex(),
+			"value":          "1000",
+			"data":           hexutil.Encode(data),
+			"operation":      "0",
+			"safeTxGas":      "0",
+			"baseGas":        "0",
+			"gasPrice":       "0",
+			"gasToken":       common.Address{}.Hex(),
+			"refundReceiver": common.Address{}.Hex(),
+			"nonce":          "7",
+		},
+	}
+	domain, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+
+	actual := TransactionHash(chainId, safe, to, big.NewInt(1000), data, 7)
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected.Hex(), actual.Hex())
+	}
+}
+
+func TestSafeKeypair_SignTransaction(t *testing.T) {
+	PollInterval = 10 * time.Millisecond
+	chainId := big.NewInt(5)
+	service := &mockService{
+		safe:         common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		chainId:      chainId,
+		nonce:        3,
+		threshold:    2,
+		confirmAfter: 2,
+	}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	s := NewSafeKeypair(AliceKp, service.safe, server.URL, chainId, log15.Root())
+	bridge := common.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	data := common.FromHex("0x1ff013f1")
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 9, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 50000, To: &bridge, Value: big.NewInt(10), Data: data})
+
+	exec, err := s.SignTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The proposal is signed by the relayer key
+	hash := TransactionHash(chainId, service.safe, bridge, big.NewInt(10), data, 3)
+	if service.proposal.ContractTransactionHash != hash || service.proposal.Sender != AliceKp.CommonAddress() {
+		t.Fatalf("unexpected proposal: %+v", service.proposal)
+	}
+	signature := hexutil.MustDecode(service.proposal.Signature)
+	signature[64] -= 27
+	pub, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != AliceKp.CommonAddress() {
+		t.Fatal("proposal is not signed by the relayer key")
+	}
+
+	// The relayer key executes the transaction through the safe with the same nonce and fees
+	sender, err := types.Sender(types.LatestSignerForChainID(chainId), exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != AliceKp.CommonAddress() || *exec.To() != service.safe || exec.Nonce() != 9 || exec.Value().Sign() != 0 ||
+		exec.GasFeeCap().Cmp(big.NewInt(2)) != 0 || exec.Gas() != 50000+ExecGasOverhead {
+		t.Fatalf("unexpected exec transaction: %+v", exec)
+	}
+
+	method, err := parsedSafeABI.MethodById(exec.Data()[:4])
+	if err != nil || method.Name != "execTransaction" {
+		t.Fatalf("expected execTransaction call, got: %x", exec.Data()[:4])
+	}
+	args, err := method.Inputs.Unpack(exec.Data()[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(common.Address) != bridge || args[1].(*big.Int).Cmp(big.NewInt(10)) != 0 || !bytes.Equal(args[2].([]byte), data) {
+		t.Fatalf("unexpected execTransaction args: %v", args)
+	}
+	// Signatures are ordered by owner
+	signatures := args[9].([]byte)
+	if len(signatures) != 130 {
+		t.Fatalf("expected 2 signatures, got %d bytes", len(signatures))
+	}
+	first := crypto.Keccak256Hash(signatures[:65]) == crypto.Keccak256Hash(hexutil.MustDecode(service.proposal.Signature))
+	aliceFirst := strings.ToLower(AliceKp.CommonAddress().Hex()) < strings.ToLower(BobKp.CommonAddress().Hex())
+	if first != aliceFirst {
+		t.Fatal("signatures are not sorted by owner")
+	}
+}
+
+func TestSafeKeypair_ConfirmationTimeout(t *testing.T) {
+	PollInterval = 10 * time.Millisecond
+	ConfirmationTimeout = 100 * time.Millisecond
+	defer func() { ConfirmationTimeout = 30 * time.Minute }()
+
+	service := &mockService{
+		safe:         common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		chainId:      big.NewInt(5),
+		threshold:    2,
+		confirmAfter: 1000,
+	}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	s := NewSafeKeypair(AliceKp, service.safe, server.URL, service.chainId, log15.Root())
+	to := common.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	_, err := s.SignTransaction(types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 50000, To: &to}))
+	if !errors.Is(err, ErrConfirmationTimeout) {
+		t.Fatalf("expected ErrConfirmationTimeout, got: %v", err)
+	}
+}
+
+func TestSafeKeypair_AlreadyExecuted(t *testing.T) {
+	PollInterval = 10 * time.Millisecond
+	service := &mockService{
+		safe:      common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		chainId:   big.NewInt(5),
+		threshold: 2,
+		executed:  true,
+	}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	s := NewSafeKeypair(AliceKp, service.safe, server.URL, service.chainId, log15.Root())
+	to := common.HexToAddress("0x62877dDCd49aD22f5eDfc6ac108e9a4b5D2bD88B")
+	_, err := s.SignTransaction(types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 50000, To: &to}))
+	if !errors.Is(err, ErrAlreadyExecuted) {
+		t.Fatalf("expected ErrAlreadyExecuted, got: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package safe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Timeout is the maximum time to wait for a response from the transaction service
+const Timeout = 10 * time.Second
+
+var ErrNotFound = errors.New("not found by the safe transaction service")
+
+// Info is the state of a Safe as reported by the transaction service
+type Info struct {
+	Nonce     uint64           `json:"nonce"`
+	Threshold uint64           `json:"threshold"`
+	Owners    []common.Address `json:"owners"`
+}
+
+// Proposal is a Safe transaction proposed to the transaction service, with the proposer's signature
+type Proposal struct {
+	To                      common.Address `json:"to"`
+	Value                   string         `json:"value"`
+	Data                    string         `json:"data"`
+	Operation               uint8          `json:"operation"`
+	SafeTxGas               string         `json:"safeTxGas"`
+	BaseGas                 string         `json:"baseGas"`
+	GasPrice                string         `json:"gasPrice"`
+	GasToken                common.Address `json:"gasToken"`
+	RefundReceiver          common.Address `json:"refundReceiver"`
+	Nonce                   uint64         `json:"nonce"`
+	ContractTransactionHash common.Hash    `json:"contractTransactionHash"`
+	Sender                  common.Address `json:"sender"`
+	Signature               string         `json:"signature"`
+	Origin                  string         `json:"origin"`
+}
+
+// Confirmation is an owner's signature of a Safe transaction
+type Confirmation struct {
+	Owner     common.Address `json:"owner"`
+	Signature string         `json:"signature"`
+}
+
+// MultisigTransaction is the state of a proposed Safe transaction
+type MultisigTransaction struct {
+	SafeTxHash            common.Hash    `json:"safeTxHash"`
+	ConfirmationsRequired uint64         `json:"confirmationsRequired"`
+	Confirmations         []Confirmation `json:"confirmations"`
+	IsExecuted            bool           `json:"isExecuted"`
+}
+
+// Service is a client for the Safe Transaction Service API
+type Service struct {
+	url    string
+	client *http.Client
+}
+
+func NewService(url string) *Service {
+	return &Service{url: strings.TrimSuffix(url, "/"), client: &http.Client{Timeout: Timeout}}
+}
+
+// Info returns the nonce, threshold and owners of the Safe
+func (s *Service) Info(ctx context.Context, safe common.Address) (*Info, error) {
+	var info Info
+	err := s.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/safes/%s/", safe.Hex()), nil, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Propose submits a transaction for the Safe's owners to confirm
+func (s *Service) Propose(ctx context.Context, safe common.Address, p *Proposal) error {
+	return s.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/safes/%s/multisig-transactions/", safe.Hex()), p, nil)
+}
+
+// Transaction returns the proposed transaction with safeTxHash and its confirmations
+func (s *Service) Transaction(ctx context.Context, safeTxHash common.Hash) (*MultisigTransaction, error) {
+	var tx MultisigTransaction
+	err := s.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/multisig-transactions/%s/", safeTxHash.Hex()), nil, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// do sends the request body as JSON and decodes the response into result, if it is not nil
+func (s *Service) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("safe transaction service returned status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resBody, result)
+}
@@ -0,0 +1,21 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SimulationResult is the outcome of simulating a transaction
+type SimulationResult struct {
+	Success      bool
+	GasUsed      uint64
+	Logs         []types.Log
+	RevertReason string // Empty if the transaction succeeded or the reason is unknown
+}
+
+// Simulator simulates transactions with a service other than the node, for nodes that do not support tracing
+type Simulator interface {
+	Simulate(tx *types.Transaction) (*SimulationResult, error)
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SyncRetryInterval is the time between checks of a syncing node when waiting for it to sync
+var SyncRetryInterval = time.Second * 15
+
+// SyncProgress is the progress of a syncing node, as reported by eth_syncing
+type SyncProgress struct {
+	CurrentBlock uint64
+	HighestBlock uint64
+}
+
+// Ratio returns the fraction of the highest known block the node has synced
+func (p SyncProgress) Ratio() float64 {
+	if p.HighestBlock == 0 {
+		return 0
+	}
+	return float64(p.CurrentBlock) / float64(p.HighestBlock)
+}
+
+// SetWaitForSync makes Connect wait until the node has finished syncing, as a syncing node does not have the latest
+// state of the chain
+func (c *Connection) SetWaitForSync(wait bool) {
+	c.waitForSync = wait
+}
+
+// CheckSync returns whether the node is syncing, and its progress if it is
+func (c *Connection) CheckSync() (bool, SyncProgress, error) {
+	return c.checkSync(context.Background())
+}
+
+func (c *Connection) checkSync(ctx context.Context) (bool, SyncProgress, error) {
+	var raw json.RawMessage
+	err := c.rpc.CallContext(ctx, &raw, "eth_syncing")
+	if err != nil {
+		return false, SyncProgress{}, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	// The node responds with false once it is synced
+	if bytes.Equal(raw, []byte("false")) {
+		return false, SyncProgress{}, nil
+	}
+	var progress struct {
+		CurrentBlock hexutil.Uint64 `json:"currentBlock"`
+		HighestBlock hexutil.Uint64 `json:"highestBlock"`
+	}
+	err = json.Unmarshal(raw, &progress)
+	if err != nil {
+		return false, SyncProgress{}, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, fmt.Errorf("invalid eth_syncing response %s: %w", raw, err))
+	}
+	return true, SyncProgress{CurrentBlock: uint64(progress.CurrentBlock), HighestBlock: uint64(progress.HighestBlock)}, nil
+}
+
+// awaitSync warns if the node is syncing, and if waitForSync is set checks it every SyncRetryInterval until it has
+// synced or ctx is done. Nodes that do not serve eth_syncing are assumed to be synced.
+func (c *Connection) awaitSync(ctx context.Context) error {
+	for {
+		syncing, progress, err := c.checkSync(ctx)
+		if err != nil && ctx.Err() != nil {
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeConnectFailed, true, fmt.Errorf("unable to check whether the node is syncing: %w", ctx.Err()))
+		}
+		if err != nil {
+			c.log.Warn("Unable to check whether the node is syncing", "err", err)
+			return nil
+		}
+		if !syncing {
+			return nil
+		}
+		c.log.Warn("Node is syncing and may not have the latest blocks", "url", c.endpoint, "currentBlock", progress.CurrentBlock, "highestBlock", progress.HighestBlock)
+		if !c.waitForSync {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return bridgeErrors.NewConnectionError(bridgeErrors.CodeConnectFailed, true, fmt.Errorf("node is syncing, at block %d of %d: %w", progress.CurrentBlock, progress.HighestBlock, ctx.Err()))
+		case <-time.After(SyncRetryInterval):
+		}
+	}
+}
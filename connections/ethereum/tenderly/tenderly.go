@@ -0,0 +1,143 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The tenderly package simulates transactions with the Tenderly Simulation API, which reports the revert reason
+and logs of a transaction without the node supporting debug_traceTransaction.
+*/
+package tenderly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	connection "github.com/ChainSafe/ChainBridge/connections/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend is the simulatorBackend opt that selects the Tenderly simulator
+const Backend = "tenderly"
+
+// DefaultURL is the Tenderly API endpoint
+const DefaultURL = "https://api.tenderly.co/api/v1"
+
+// Timeout is the maximum time to wait for a simulation
+const Timeout = 30 * time.Second
+
+var _ connection.Simulator = &TenderlySimulator{}
+
+type simulationRequest struct {
+	NetworkID string         `json:"network_id"`
+	From      common.Address `json:"from"`
+	To        string         `json:"to"`
+	Input     string         `json:"input"`
+	Gas       uint64         `json:"gas"`
+	GasPrice  string         `json:"gas_price"`
+	Value     string         `json:"value"`
+	Save      bool           `json:"save"`
+}
+
+type rawLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type simulationResponse struct {
+	Transaction struct {
+		Status          bool   `json:"status"`
+		GasUsed         uint64 `json:"gas_used"`
+		ErrorMessage    string `json:"error_message"`
+		TransactionInfo struct {
+			Logs []struct {
+				Raw rawLog `json:"raw"`
+			} `json:"logs"`
+		} `json:"transaction_info"`
+	} `json:"transaction"`
+}
+
+// TenderlySimulator simulates transactions in a Tenderly project
+type TenderlySimulator struct {
+	url       string
+	project   string // Slug of the project, including its account as "account/project"
+	accessKey string
+	client    *http.Client
+}
+
+// NewTenderlySimulator returns a simulator for the project, given as "account/project", authenticated by accessKey
+func NewTenderlySimulator(project, accessKey string) *TenderlySimulator {
+	return &TenderlySimulator{url: DefaultURL, project: project, accessKey: accessKey, client: &http.Client{Timeout: Timeout}}
+}
+
+// Simulate simulates tx from its sender at the latest block. tx must be signed so its sender can be recovered.
+func (s *TenderlySimulator) Simulate(tx *types.Transaction) (*connection.SimulationResult, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to recover transaction sender: %w", err)
+	}
+	if tx.To() == nil {
+		return nil, fmt.Errorf("contract deployments cannot be simulated")
+	}
+
+	req := simulationRequest{
+		NetworkID: tx.ChainId().String(),
+		From:      from,
+		To:        tx.To().Hex(),
+		Input:     hexutil.Encode(tx.Data()),
+		Gas:       tx.Gas(),
+		GasPrice:  tx.GasPrice().String(),
+		Value:     tx.Value().String(),
+	}
+	var res simulationResponse
+	err = s.post(fmt.Sprintf("/account/%s/simulate", strings.Replace(s.project, "/", "/project/", 1)), req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &connection.SimulationResult{
+		Success:      res.Transaction.Status,
+		GasUsed:      res.Transaction.GasUsed,
+		RevertReason: res.Transaction.ErrorMessage,
+	}
+	for _, l := range res.Transaction.TransactionInfo.Logs {
+		result.Logs = append(result.Logs, types.Log{Address: l.Raw.Address, Topics: l.Raw.Topics, Data: l.Raw.Data})
+	}
+	return result, nil
+}
+
+// post sends body as JSON and decodes the response into result
+func (s *TenderlySimulator) post(path string, body, result interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Access-Key", s.accessKey)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("tenderly returned status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+	return json.Unmarshal(resBody, result)
+}
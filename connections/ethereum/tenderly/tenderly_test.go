@@ -0,0 +1,124 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package tenderly
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const simulationResponseBody = `{
+	"transaction": {
+		"status": false,
+		"gas_used": 52341,
+		"error_message": "execution reverted: proposal already executed",
+		"transaction_info": {
+			"logs": [{"raw": {"address": "0x0000000000000000000000000000000000001234", "topics": ["0x0000000000000000000000000000000000000000000000000000000000000001"], "data": "0xabcd"}}]
+		}
+	}
+}`
+
+func TestTenderlySimulator_Simulate(t *testing.T) {
+	kp := keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+	to := common.HexToAddress("0x0000000000000000000000000000000000005678")
+	chainId := big.NewInt(5)
+	tx, err := types.SignNewTx(kp.PrivateKey(), types.LatestSignerForChainID(chainId), &types.LegacyTx{
+		Nonce:    1,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Gas:      100000,
+		GasPrice: big.NewInt(20000000000),
+		Data:     []byte{0x12, 0x34},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path, accessKey string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		accessKey = r.Header.Get("X-Access-Key")
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		err = json.Unmarshal(data, &body)
+		if err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(simulationResponseBody))
+	}))
+	defer srv.Close()
+
+	s := NewTenderlySimulator("account/project", "key")
+	s.url = srv.URL
+	res, err := s.Simulate(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/account/account/project/project/simulate" {
+		t.Fatalf("unexpected path: %s", path)
+	}
+	if accessKey != "key" {
+		t.Fatalf("unexpected access key: %s", accessKey)
+	}
+	expectedBody := map[string]interface{}{
+		"network_id": "5",
+		"from":       strings.ToLower(kp.Address()),
+		"to":         to.Hex(),
+		"input":      "0x1234",
+		"gas":        float64(100000),
+		"gas_price":  "20000000000",
+		"value":      "0",
+		"save":       false,
+	}
+	if !reflect.DeepEqual(body, expectedBody) {
+		t.Fatalf("unexpected payload.\n\tExpected: %#v\n\tGot: %#v\n", expectedBody, body)
+	}
+
+	if res.Success || res.GasUsed != 52341 || res.RevertReason != "execution reverted: proposal already executed" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	expectedLogs := []types.Log{{
+		Address: common.HexToAddress("0x1234"),
+		Topics:  []common.Hash{common.HexToHash("0x01")},
+		Data:    []byte{0xab, 0xcd},
+	}}
+	if !reflect.DeepEqual(res.Logs, expectedLogs) {
+		t.Fatalf("unexpected logs: %+v", res.Logs)
+	}
+}
+
+func TestTenderlySimulator_Failed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	kp := keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+	to := common.HexToAddress("0x5678")
+	tx, err := types.SignNewTx(kp.PrivateKey(), types.LatestSignerForChainID(big.NewInt(5)), &types.LegacyTx{To: &to, Value: big.NewInt(0), GasPrice: big.NewInt(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewTenderlySimulator("account/project", "key")
+	s.url = srv.URL
+	_, err = s.Simulate(tx)
+	if err == nil {
+		t.Fatal("expected error for unsuccessful response")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// The ERC20 balanceOf and ERC721 ownerOf methods
+var tokenABI, _ = abi.JSON(strings.NewReader(`[
+	{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"name":"ownerOf","type":"function","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]}
+]`))
+
+// GetTokenBalance returns the balance of holder in the ERC20 token contract
+func (c *Connection) GetTokenBalance(ctx context.Context, token, holder ethcommon.Address) (*big.Int, error) {
+	balance := new(*big.Int)
+	err := c.callToken(ctx, token, "balanceOf", balance, holder)
+	if err != nil {
+		return nil, err
+	}
+	return *balance, nil
+}
+
+// GetNFTOwner returns the owner of tokenId in the ERC721 token contract. Tokens that have not been minted revert.
+func (c *Connection) GetNFTOwner(ctx context.Context, token ethcommon.Address, tokenId *big.Int) (ethcommon.Address, error) {
+	owner := new(ethcommon.Address)
+	err := c.callToken(ctx, token, "ownerOf", owner, tokenId)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	return *owner, nil
+}
+
+// callToken calls a view method of the token contract and unpacks its single output into out
+func (c *Connection) callToken(ctx context.Context, token ethcommon.Address, method string, out interface{}, args ...interface{}) error {
+	data, err := tokenABI.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	res, err := c.conn.CallContract(ctx, eth.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	if len(res) == 0 {
+		return bridgeErrors.NewContractError(bridgeErrors.CodeNoBytecode, false, fmt.Errorf("no token contract at %s", token.Hex()))
+	}
+	err = tokenABI.UnpackIntoInterface(out, method, res)
+	if err != nil {
+		return bridgeErrors.NewContractError(bridgeErrors.CodeCallFailed, false, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrTraceUnsupported is returned by GetTransactionTrace if the node does not provide debug_traceTransaction
+var ErrTraceUnsupported = errors.New("debug_traceTransaction is not supported by the node")
+
+// methodNotFoundCode is the JSON-RPC error code for unknown or disabled methods
+const methodNotFoundCode = -32601
+
+// CallFrame is a single call made by a transaction, as reported by the callTracer
+type CallFrame struct {
+	Type         string             `json:"type"`
+	From         ethcommon.Address  `json:"from"`
+	To           *ethcommon.Address `json:"to,omitempty"`
+	Input        hexutil.Bytes      `json:"input"`
+	Output       hexutil.Bytes      `json:"output,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	RevertReason string             `json:"revertReason,omitempty"`
+	Calls        []CallFrame        `json:"calls,omitempty"`
+}
+
+// TransactionTrace lists the calls made by a transaction
+type TransactionTrace struct {
+	Calls []CallFrame // The top-level call followed by its subcalls depth first, with their Calls cleared
+}
+
+// RevertReason returns the revert reason of the top-level call, or an empty string if it has none
+func (t *TransactionTrace) RevertReason() string {
+	if len(t.Calls) == 0 {
+		return ""
+	}
+	return t.Calls[0].RevertReason
+}
+
+// GetTransactionTrace traces the transaction with txHash using debug_traceTransaction and the callTracer.
+// ErrTraceUnsupported is returned if the node does not provide it.
+func (c *Connection) GetTransactionTrace(ctx context.Context, txHash ethcommon.Hash) (*TransactionTrace, error) {
+	var root CallFrame
+	err := c.rpc.CallContext(ctx, &root, "debug_traceTransaction", txHash, map[string]string{"tracer": "callTracer"})
+	if err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+			return nil, ErrTraceUnsupported
+		}
+		return nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+
+	trace := &TransactionTrace{}
+	flattenCalls(&root, trace)
+	return trace, nil
+}
+
+// flattenCalls appends frame and its subcalls to trace, setting the revert reasons the node did not decode
+func flattenCalls(frame *CallFrame, trace *TransactionTrace) {
+	calls := frame.Calls
+	frame.Calls = nil
+	if frame.RevertReason == "" && strings.Contains(frame.Error, "reverted") {
+		frame.RevertReason, _ = abi.UnpackRevert(frame.Output)
+	}
+	trace.Calls = append(trace.Calls, *frame)
+	for i := range calls {
+		flattenCalls(&calls[i], trace)
+	}
+}
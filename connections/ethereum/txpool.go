@@ -0,0 +1,39 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+
+	bridgeErrors "github.com/ChainSafe/ChainBridge/errors"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrTxPoolUnsupported is returned by GetTxPoolContent if the node does not provide txpool_content
+var ErrTxPoolUnsupported = errors.New("txpool_content is not supported by the node")
+
+// GetTxPoolContent returns the transactions in the node's pool using txpool_content. Pending transactions can be
+// mined, queued ones are waiting for a lower nonce of their sender. Both are keyed by sender address, then by nonce
+// in decimal. ErrTxPoolUnsupported is returned if the node does not provide it.
+func (c *Connection) GetTxPoolContent() (pending, queued map[string]map[string]*types.Transaction, err error) {
+	return c.txPoolContent(context.Background())
+}
+
+func (c *Connection) txPoolContent(ctx context.Context) (map[string]map[string]*types.Transaction, map[string]map[string]*types.Transaction, error) {
+	var content struct {
+		Pending map[string]map[string]*types.Transaction `json:"pending"`
+		Queued  map[string]map[string]*types.Transaction `json:"queued"`
+	}
+	err := c.rpc.CallContext(ctx, &content, "txpool_content")
+	if err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+			return nil, nil, ErrTxPoolUnsupported
+		}
+		return nil, nil, bridgeErrors.NewConnectionError(bridgeErrors.CodeQueryFailed, true, err)
+	}
+	return content.Pending, content.Queued, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/router"
+	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+type Chain interface {
+	Start() error // Start chain
+	SetRouter(*router.Router)
+	Id() msg.ChainId
+	Name() string
+	LatestBlock() metrics.LatestBlock
+	Running() bool // True once the chain has started, until it is stopped
+	Stop()
+}
+
+// Readier is implemented by chains that report when they are ready to process messages, eg. once they have
+// polled their first block. Chains that do not implement it are ready once they are running.
+type Readier interface {
+	WaitReady(ctx context.Context) error // Blocks until the chain is ready or ctx is done
+}
+
+// CircuitBreaker is implemented by chains that stop relaying while their circuit breaker is open
+type CircuitBreaker interface {
+	CircuitBreakerOpen() bool
+}
+
+// RestartLocker is implemented by chains that restart parts of themselves at runtime, such as reconnecting their
+// writer. The chain is removed with the lock held, so a restart cannot overlap its removal.
+type RestartLocker interface {
+	LockRestarts()
+	UnlockRestarts()
+}
+
+// RangeReporter is implemented by chains that report the blocks their listener has processed, for monitoring
+type RangeReporter interface {
+	GetProcessedRange() (from, to *big.Int) // The start block and the latest block stored in the blockstore
+	GetPendingRange() (from, to *big.Int)   // Blocks being processed that have not been stored
+}
+
+type ChainConfig struct {
+	Type                 string            // Chain type, the factory Core.AddChain initializes the chain with
+	Name                 string            // Human-readable chain name
+	Id                   msg.ChainId       // ChainID
+	Endpoint             string            // url for rpc endpoint
+	From                 string            // address of key to use
+	KeystorePath         string            // Location of key files
+	Insecure             bool              // Indicated whether the test keyring should be used
+	BlockstorePath       string            // Location of blockstore
+	FreshStart           bool              // If true, blockstore is ignored at start.
+	LatestBlock          bool              // If true, overrides blockstore or latest block in config and starts from current block
+	StartTime            time.Time         // If non-zero, overrides blockstore or latest block in config and starts from the last block at or before this time
+	AllowChainIdMismatch bool              // If true, a node on a different network than configured only logs a warning
+	Opts                 map[string]string // Per chain options
+}
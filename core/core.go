@@ -0,0 +1,270 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/alerts"
+	"github.com/ChainSafe/ChainBridge/router"
+	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var ErrChainAlreadyExists = errors.New("chain already exists")
+var ErrChainNotFound = errors.New("chain not found")
+
+// TracingShutdownTimeout limits the time Start waits for the remaining spans to be exported when it returns
+var TracingShutdownTimeout = 5 * time.Second
+
+// RemoveChainTimeout is how long RemoveChain waits for the chain's writer to resolve the messages queued for it
+var RemoveChainTimeout = 30 * time.Second
+
+type Core struct {
+	Registry     []Chain
+	registryLock sync.RWMutex
+	changeLock   sync.Mutex // Held while a chain is added or removed at runtime
+	route        *router.Router
+	log          log15.Logger
+	sysErr       chan error
+	notifier     alerts.Notifier          // Alerts operators of fatal errors, nil if alerts are disabled
+	chainMetrics bool                     // Whether chains added by AddChain report metrics
+	tracer       *sdktrace.TracerProvider // Exports spans, nil if tracing is disabled
+}
+
+func NewCore(sysErr chan error) *Core {
+	return &Core{
+		Registry: make([]Chain, 0),
+		route:    router.NewRouter(log15.New("system", "router")),
+		log:      log15.New("system", "core"),
+		sysErr:   sysErr,
+	}
+}
+
+// Register adds the chain to the Registry and calls Chain.SetRouter(). Registered chains are started by Start.
+func (c *Core) Register(chain Chain) {
+	c.registryLock.Lock()
+	defer c.registryLock.Unlock()
+	c.Registry = append(c.Registry, chain)
+	chain.SetRouter(c.route)
+}
+
+// EnableChainMetrics makes AddChain create metrics for the chains it adds
+func (c *Core) EnableChainMetrics() {
+	c.chainMetrics = true
+}
+
+// AddChain initializes a chain with the factory registered for cfg.Type while the bridge is running. The chain is
+// registered with the router, started and added to the Registry. ErrChainAlreadyExists is returned if a chain
+// with the same ID is registered.
+func (c *Core) AddChain(cfg *ChainConfig) error {
+	c.changeLock.Lock()
+	defer c.changeLock.Unlock()
+
+	if c.chain(cfg.Id) != nil {
+		return fmt.Errorf("%w: %d", ErrChainAlreadyExists, cfg.Id)
+	}
+
+	var m *metrics.ChainMetrics
+	if c.chainMetrics {
+		m = metrics.NewChainMetrics(cfg.Name)
+	}
+	chain, err := NewChain(cfg.Type, cfg, log15.Root().New("chain", cfg.Name), c.sysErr, m)
+	if err != nil {
+		return err
+	}
+
+	chain.SetRouter(c.route)
+	err = chain.Start()
+	if err != nil {
+		c.unregisterAndStop(chain, 0)
+		return fmt.Errorf("failed to start chain %d: %w", cfg.Id, err)
+	}
+
+	c.registryLock.Lock()
+	c.Registry = append(c.Registry, chain)
+	c.registryLock.Unlock()
+	c.log.Info(fmt.Sprintf("Added %s chain", chain.Name()), "chain", chain.Id())
+	return nil
+}
+
+// RemoveChain removes a chain from the router, waiting up to RemoveChainTimeout for its writer to resolve the
+// messages queued for it, then stops it and removes it from the Registry. Messages it did not resolve are dropped.
+func (c *Core) RemoveChain(id msg.ChainId) error {
+	c.changeLock.Lock()
+	defer c.changeLock.Unlock()
+
+	chain := c.chain(id)
+	if chain == nil {
+		return fmt.Errorf("%w: %d", ErrChainNotFound, id)
+	}
+
+	dropped := c.unregisterAndStop(chain, RemoveChainTimeout)
+	for _, m := range dropped {
+		c.log.Warn("Dropped message to removed chain", "chain", id, "src", m.Source, "nonce", m.DepositNonce)
+	}
+
+	c.registryLock.Lock()
+	for i, registered := range c.Registry {
+		if registered == chain {
+			c.Registry = append(c.Registry[:i:i], c.Registry[i+1:]...)
+			break
+		}
+	}
+	c.registryLock.Unlock()
+	c.log.Info(fmt.Sprintf("Removed %s chain", chain.Name()), "chain", id, "dropped", len(dropped))
+	return nil
+}
+
+// unregisterAndStop removes chain from the router, waiting up to timeout for its writer as Router.Unregister does,
+// then stops it and returns the messages its writer did not resolve. Restarts of the chain are held off meanwhile.
+func (c *Core) unregisterAndStop(chain Chain, timeout time.Duration) []msg.Message {
+	if l, ok := chain.(RestartLocker); ok {
+		l.LockRestarts()
+		defer l.UnlockRestarts()
+	}
+	dropped := c.route.Unregister(chain.Id(), timeout)
+	chain.Stop()
+	return dropped
+}
+
+// chain returns the registered chain with id, or nil
+func (c *Core) chain(id msg.ChainId) Chain {
+	c.registryLock.RLock()
+	defer c.registryLock.RUnlock()
+	for _, chain := range c.Registry {
+		if chain.Id() == id {
+			return chain
+		}
+	}
+	return nil
+}
+
+// chains returns a copy of the Registry
+func (c *Core) chains() []Chain {
+	c.registryLock.RLock()
+	defer c.registryLock.RUnlock()
+	return append([]Chain{}, c.Registry...)
+}
+
+// SetRoute sends messages from source to destination, a chain that is not registered, via the chains in via
+func (c *Core) SetRoute(source, destination msg.ChainId, via []msg.ChainId) error {
+	return c.route.SetRoute(source, destination, via)
+}
+
+// AddSink passes every message routed between chains to s
+func (c *Core) AddSink(s router.Sink) {
+	c.route.AddSink(s)
+}
+
+// SetNotifier sets the notifier operators are alerted with when a chain fails to start or a fatal error occurs
+func (c *Core) SetNotifier(notifier alerts.Notifier) {
+	c.notifier = notifier
+}
+
+// SetTracerProvider sets the provider that exports spans. It is shut down when Start returns, exporting the
+// remaining spans.
+func (c *Core) SetTracerProvider(tp *sdktrace.TracerProvider) {
+	c.tracer = tp
+}
+
+// alert sends an alert if a notifier is set. Failing to send is logged, as the alert must not stop the shutdown.
+func (c *Core) alert(level string, message string, context map[string]string) {
+	if c.notifier == nil {
+		return
+	}
+	err := c.notifier.Notify(level, message, context)
+	if err != nil {
+		c.log.Error("Failed to send alert", "message", message, "err", err)
+	}
+}
+
+// Start will call all registered chains' Start methods and block forever (or until signal is received)
+func (c *Core) Start() {
+	defer c.shutdownTracing()
+	for _, chain := range c.chains() {
+		err := chain.Start()
+		if err != nil {
+			c.log.Error(
+				"failed to start chain",
+				"chain", chain.Id(),
+				"err", err,
+			)
+			c.alert(alerts.LevelError, "Failed to start chain", map[string]string{"chain": strconv.Itoa(int(chain.Id())), "err": err.Error()})
+			return
+		}
+		c.log.Info(fmt.Sprintf("Started %s chain", chain.Name()))
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	// Block here and wait for a signal
+	select {
+	case err := <-c.sysErr:
+		c.log.Error("FATAL ERROR. Shutting down.", "err", err)
+		c.alert(alerts.LevelCritical, "Bridge shutting down on fatal error", map[string]string{"err": err.Error()})
+	case <-sigc:
+		c.log.Warn("Interrupt received, shutting down now.")
+	}
+
+	// Signal chains to shutdown
+	for _, chain := range c.chains() {
+		chain.Stop()
+	}
+}
+
+// shutdownTracing exports the remaining spans if tracing is enabled
+func (c *Core) shutdownTracing() {
+	if c.tracer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), TracingShutdownTimeout)
+	defer cancel()
+	err := c.tracer.Shutdown(ctx)
+	if err != nil {
+		c.log.Error("Failed to export remaining spans", "err", err)
+	}
+}
+
+// readyPollInterval is how often WaitReady checks whether chains that do not implement Readier are running
+var readyPollInterval = 100 * time.Millisecond
+
+// WaitReady blocks until every chain in the Registry is ready to process messages, or returns the error of ctx if
+// it is done first. Chains are started by Start, so it must be called from another routine.
+func (c *Core) WaitReady(ctx context.Context) error {
+	for _, chain := range c.chains() {
+		if readier, ok := chain.(Readier); ok {
+			err := readier.WaitReady(ctx)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for !chain.Running() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(readyPollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Core) Errors() <-chan error {
+	return c.sysErr
+}
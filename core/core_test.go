@@ -0,0 +1,263 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/alerts"
+	"github.com/ChainSafe/ChainBridge/router"
+	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+)
+
+type alert struct {
+	level   string
+	message string
+	context map[string]string
+}
+
+type mockNotifier struct {
+	alerts []alert
+}
+
+func (n *mockNotifier) Notify(level string, message string, context map[string]string) error {
+	n.alerts = append(n.alerts, alert{level, message, context})
+	return nil
+}
+
+func TestCore_FatalErrorAlert(t *testing.T) {
+	sysErr := make(chan error)
+	c := NewCore(sysErr)
+	notifier := &mockNotifier{}
+	c.SetNotifier(notifier)
+	chain := &mockChain{cfg: &ChainConfig{Name: "mock", Id: 1}, started: make(chan struct{}), stopped: make(chan struct{})}
+	c.Register(chain)
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+	sysErr <- errors.New("fatal")
+	<-done
+
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("expected 1 alert, got: %v", notifier.alerts)
+	}
+	if a := notifier.alerts[0]; a.level != alerts.LevelCritical || a.context["err"] != "fatal" {
+		t.Fatalf("unexpected alert: %v", a)
+	}
+}
+
+// readyChain is a mockChain that is ready once ready is closed
+type readyChain struct {
+	mockChain
+	ready chan struct{}
+}
+
+func (c *readyChain) WaitReady(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestCore_WaitReady(t *testing.T) {
+	sysErr := make(chan error)
+	c := NewCore(sysErr)
+	ready := &readyChain{
+		mockChain: mockChain{cfg: &ChainConfig{Name: "ready", Id: 1}, started: make(chan struct{}), stopped: make(chan struct{})},
+		ready:     make(chan struct{}),
+	}
+	// Does not implement Readier, so it is ready once running
+	running := &mockChain{cfg: &ChainConfig{Name: "running", Id: 2}, started: make(chan struct{}), stopped: make(chan struct{})}
+	c.Register(ready)
+	c.Register(running)
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+	defer func() {
+		sysErr <- errors.New("shutdown")
+		<-done
+	}()
+	go func() {
+		<-ready.started
+		time.Sleep(100 * time.Millisecond)
+		close(ready.ready)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := c.WaitReady(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCore_WaitReady_Timeout(t *testing.T) {
+	c := NewCore(make(chan error))
+	c.Register(&readyChain{
+		mockChain: mockChain{cfg: &ChainConfig{Name: "ready", Id: 1}, started: make(chan struct{}), stopped: make(chan struct{})},
+		ready:     make(chan struct{}),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := c.WaitReady(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+// relayChain is a mockChain whose writer records the messages routed to it
+type relayChain struct {
+	mockChain
+	received chan msg.Message
+}
+
+func newRelayChain(cfg *ChainConfig) *relayChain {
+	return &relayChain{
+		mockChain: mockChain{cfg: cfg, started: make(chan struct{}), stopped: make(chan struct{})},
+		received:  make(chan msg.Message, 1),
+	}
+}
+
+func (c *relayChain) SetRouter(r *router.Router) {
+	r.Listen(c.cfg.Id, c)
+}
+
+func (c *relayChain) ResolveMessage(m msg.Message) bool {
+	c.received <- m
+	return true
+}
+
+func TestCore_AddChain(t *testing.T) {
+	var added *relayChain
+	RegisterChainFactory("relay", func(cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error) {
+		added = newRelayChain(cfg)
+		return added, nil
+	})
+
+	sysErr := make(chan error)
+	c := NewCore(sysErr)
+	source := newRelayChain(&ChainConfig{Name: "source", Id: 1})
+	c.Register(source)
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+	defer func() {
+		sysErr <- errors.New("shutdown")
+		<-done
+	}()
+	<-source.started
+
+	deposit := msg.NewFungibleTransfer(1, 2, 1, big.NewInt(10), msg.ResourceIdFromSlice([]byte{1}), []byte{0xab})
+	err := c.route.Send(deposit)
+	if err == nil {
+		t.Fatal("expected an error for a destination that is not added")
+	}
+
+	err = c.AddChain(&ChainConfig{Type: "relay", Name: "added", Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !added.Running() {
+		t.Fatal("expected the added chain to be started")
+	}
+	err = c.route.Send(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-added.received:
+		if m.DepositNonce != deposit.DepositNonce {
+			t.Fatalf("expected nonce %d, got: %d", deposit.DepositNonce, m.DepositNonce)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the added chain did not relay the deposit")
+	}
+	if status := c.Status(); len(status.Chains) != 2 || status.Chains[1].Id != 2 {
+		t.Fatalf("expected the added chain in the status, got: %v", status.Chains)
+	}
+
+	err = c.AddChain(&ChainConfig{Type: "relay", Name: "duplicate", Id: 2})
+	if !errors.Is(err, ErrChainAlreadyExists) {
+		t.Fatalf("expected ErrChainAlreadyExists, got: %v", err)
+	}
+
+	err = c.RemoveChain(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Running() {
+		t.Fatal("expected the removed chain to be stopped")
+	}
+	err = c.route.Send(deposit)
+	if err == nil {
+		t.Fatal("expected an error for a removed destination")
+	}
+	if len(c.Status().Chains) != 1 {
+		t.Fatalf("expected 1 chain, got: %d", len(c.Status().Chains))
+	}
+	err = c.RemoveChain(2)
+	if !errors.Is(err, ErrChainNotFound) {
+		t.Fatalf("expected ErrChainNotFound, got: %v", err)
+	}
+}
+
+// restartChain is a relayChain that records whether restarts are held off
+type restartChain struct {
+	*relayChain
+	locked        bool
+	lockedOnStop  bool
+	unlockedAfter bool
+}
+
+func (c *restartChain) LockRestarts() {
+	c.locked = true
+}
+
+func (c *restartChain) UnlockRestarts() {
+	c.locked = false
+	c.unlockedAfter = c.lockedOnStop
+}
+
+func (c *restartChain) Stop() {
+	c.lockedOnStop = c.locked
+	c.relayChain.Stop()
+}
+
+func TestCore_RemoveChainHoldsRestarts(t *testing.T) {
+	var added *restartChain
+	RegisterChainFactory("restart", func(cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error) {
+		added = &restartChain{relayChain: newRelayChain(cfg)}
+		return added, nil
+	})
+
+	c := NewCore(make(chan error))
+	err := c.AddChain(&ChainConfig{Type: "restart", Name: "added", Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.RemoveChain(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !added.lockedOnStop || !added.unlockedAfter {
+		t.Fatalf("expected restarts to be held off until the chain stopped, locked on stop: %t, unlocked after: %t", added.lockedOnStop, added.unlockedAfter)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/ChainBridge/registry"
+	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
+	"github.com/ChainSafe/log15"
+)
+
+var ErrUnknownChainType = errors.New("unrecognized chain type")
+
+// ChainFactory initializes a chain from its config. Fatal errors are reported on sysErr, m is nil if metrics are disabled.
+type ChainFactory func(cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error)
+
+var (
+	factories    = make(map[string]ChainFactory)
+	factoriesMtx sync.RWMutex
+)
+
+// RegisterChainFactory makes a chain type available to NewChain. It is intended to be called from the init
+// function of the package implementing the chain and panics if chainType is already registered.
+func RegisterChainFactory(chainType string, factory ChainFactory) {
+	factoriesMtx.Lock()
+	defer factoriesMtx.Unlock()
+	if factory == nil {
+		panic("core: RegisterChainFactory factory is nil")
+	}
+	if _, dup := factories[chainType]; dup {
+		panic("core: RegisterChainFactory called twice for chain type " + chainType)
+	}
+	factories[chainType] = factory
+}
+
+// NewChain initializes a chain using the factory registered for chainType. The chain's name is registered with the
+// registry package before it is initialized.
+func NewChain(chainType string, cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error) {
+	factoriesMtx.RLock()
+	factory, ok := factories[chainType]
+	factoriesMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChainType, chainType)
+	}
+	registry.Register(cfg.Id, cfg.Name)
+	return factory(cfg, logger, sysErr, m)
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/registry"
+	"github.com/ChainSafe/ChainBridge/router"
+	metrics "github.com/ChainSafe/chainbridge-utils/metrics/types"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+)
+
+type mockChain struct {
+	cfg     *ChainConfig
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (c *mockChain) Start() error {
+	close(c.started)
+	return nil
+}
+
+func (c *mockChain) SetRouter(*router.Router) {}
+
+func (c *mockChain) Id() msg.ChainId {
+	return c.cfg.Id
+}
+
+func (c *mockChain) Name() string {
+	return c.cfg.Name
+}
+
+func (c *mockChain) LatestBlock() metrics.LatestBlock {
+	return metrics.LatestBlock{}
+}
+
+func (c *mockChain) Running() bool {
+	select {
+	case <-c.stopped:
+		return false
+	default:
+	}
+	select {
+	case <-c.started:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *mockChain) Stop() {
+	close(c.stopped)
+}
+
+func TestRegisterChainFactory(t *testing.T) {
+	var chain *mockChain
+	RegisterChainFactory("mock", func(cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error) {
+		chain = &mockChain{cfg: cfg, started: make(chan struct{}), stopped: make(chan struct{})}
+		return chain, nil
+	})
+
+	sysErr := make(chan error)
+	c := NewCore(sysErr)
+	newChain, err := NewChain("mock", &ChainConfig{Name: "mock", Id: 1}, log15.Root(), sysErr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := registry.NameOf(1); name != "mock" {
+		t.Fatalf("expected the chain name to be registered, got: %s", name)
+	}
+	c.Register(newChain)
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+
+	select {
+	case <-chain.started:
+	case <-time.After(time.Second):
+		t.Fatal("mock chain was not started")
+	}
+
+	// A fatal error shuts down all chains
+	sysErr <- errors.New("fatal")
+	select {
+	case <-chain.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("mock chain was not stopped")
+	}
+	<-done
+}
+
+func TestNewChain_Unknown(t *testing.T) {
+	_, err := NewChain("unknown", &ChainConfig{}, log15.Root(), nil, nil)
+	if !errors.Is(err, ErrUnknownChainType) {
+		t.Fatalf("expected ErrUnknownChainType, got: %v", err)
+	}
+}
+
+func TestRegisterChainFactory_Duplicate(t *testing.T) {
+	factory := func(cfg *ChainConfig, logger log15.Logger, sysErr chan<- error, m *metrics.ChainMetrics) (Chain, error) {
+		return nil, nil
+	}
+	RegisterChainFactory("duplicate", factory)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic registering a chain type twice")
+		}
+	}()
+	RegisterChainFactory("duplicate", factory)
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OptsField describes a key of ChainConfig.Opts accepted by a chain type
+type OptsField struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	schemas    = make(map[string][]OptsField)
+	schemasMtx sync.RWMutex
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	bigIntType   = reflect.TypeOf(&big.Int{})
+	bigFloatType = reflect.TypeOf(&big.Float{})
+)
+
+// RegisterOptsSchema makes the opts of a chain type available to OptsSchema. The schema is read from the opts tags
+// of the fields of config, which must be a struct. A tag has the form
+//
+//	opts:"key,required,default=X,desc=Y"
+//
+// where only the key is mandatory and desc, which may contain commas, must come last. Fields without an opts tag
+// are not opts. Like RegisterChainFactory it is intended to be called from an init function, and panics if the tags
+// are malformed or chainType is already registered.
+func RegisterOptsSchema(chainType string, config interface{}) {
+	fields, err := parseOptsSchema(reflect.TypeOf(config))
+	if err != nil {
+		panic("core: RegisterOptsSchema: " + err.Error())
+	}
+
+	schemasMtx.Lock()
+	defer schemasMtx.Unlock()
+	if _, dup := schemas[chainType]; dup {
+		panic("core: RegisterOptsSchema called twice for chain type " + chainType)
+	}
+	schemas[chainType] = fields
+}
+
+// OptsSchema returns the opts of each registered chain type, in the order their fields are declared
+func OptsSchema() map[string][]OptsField {
+	schemasMtx.RLock()
+	defer schemasMtx.RUnlock()
+	res := make(map[string][]OptsField, len(schemas))
+	for chainType, fields := range schemas {
+		res[chainType] = append([]OptsField{}, fields...)
+	}
+	return res
+}
+
+// parseOptsSchema reads the opts tags of the fields of t
+func parseOptsSchema(t reflect.Type) ([]OptsField, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct, got %v", t)
+	}
+
+	var fields []OptsField
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("opts")
+		if !ok {
+			continue
+		}
+		field, err := parseOptsTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", t.Field(i).Name, err)
+		}
+		if keys[field.Key] {
+			return nil, fmt.Errorf("field %s: duplicate key %s", t.Field(i).Name, field.Key)
+		}
+		keys[field.Key] = true
+		field.Type = optsType(t.Field(i).Type)
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseOptsTag parses a tag of the form key,required,default=X,desc=Y
+func parseOptsTag(tag string) (OptsField, error) {
+	var field OptsField
+	if i := strings.Index(tag, ",desc="); i >= 0 {
+		field.Description = tag[i+len(",desc="):]
+		tag = tag[:i]
+	}
+
+	parts := strings.Split(tag, ",")
+	field.Key = parts[0]
+	if field.Key == "" {
+		return OptsField{}, fmt.Errorf("missing key in tag %q", tag)
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case part == "required":
+			field.Required = true
+		case strings.HasPrefix(part, "default="):
+			field.Default = strings.TrimPrefix(part, "default=")
+		default:
+			return OptsField{}, fmt.Errorf("unknown option %q in tag %q", part, tag)
+		}
+	}
+	return field, nil
+}
+
+// optsType names the kind of value expected for a field of type t
+func optsType(t reflect.Type) string {
+	switch t {
+	case durationType:
+		return "duration"
+	case bigIntType:
+		return "integer"
+	case bigFloatType:
+		return "float"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "hex"
+		}
+		return "list"
+	case reflect.Slice:
+		return "list"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testOptsConfig struct {
+	name     string
+	address  [20]byte      `opts:"address,required,desc=Contract address, checksummed or not"`
+	timeout  time.Duration `opts:"timeout,default=30s"`
+	limit    *big.Int      `opts:"limit,default=100,desc=Maximum value"`
+	enabled  bool          `opts:"enabled"`
+	skipList []uint64      `opts:"skip"`
+}
+
+func TestParseOptsSchema(t *testing.T) {
+	fields, err := parseOptsSchema(reflect.TypeOf(testOptsConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []OptsField{
+		{Key: "address", Type: "hex", Required: true, Description: "Contract address, checksummed or not"},
+		{Key: "timeout", Type: "duration", Default: "30s"},
+		{Key: "limit", Type: "integer", Default: "100", Description: "Maximum value"},
+		{Key: "enabled", Type: "bool"},
+		{Key: "skip", Type: "list"},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("unexpected schema.\n\tExpected: %+v\n\tGot: %+v", expected, fields)
+	}
+}
+
+func TestParseOptsSchema_Invalid(t *testing.T) {
+	for name, config := range map[string]interface{}{
+		"notStruct": "config",
+		"noKey": struct {
+			a string `opts:",required"`
+		}{},
+		"unknownOption": struct {
+			a string `opts:"a,optional"`
+		}{},
+		"duplicate": struct {
+			a string `opts:"a"`
+			b string `opts:"a"`
+		}{},
+	} {
+		_, err := parseOptsSchema(reflect.TypeOf(config))
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRegisterOptsSchema(t *testing.T) {
+	RegisterOptsSchema("test", testOptsConfig{})
+	defer func() {
+		schemasMtx.Lock()
+		delete(schemas, "test")
+		schemasMtx.Unlock()
+	}()
+
+	fields := OptsSchema()["test"]
+	if len(fields) != 5 || fields[0].Key != "address" {
+		t.Fatalf("unexpected schema: %+v", fields)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic registering a chain type twice")
+		}
+	}()
+	RegisterOptsSchema("test", testOptsConfig{})
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// ChainStatus is the state of a single chain of the bridge
+type ChainStatus struct {
+	Id                 msg.ChainId `json:"id"`
+	Name               string      `json:"name"`
+	Running            bool        `json:"running"`
+	LatestBlock        *big.Int    `json:"latestBlock"`        // Latest block seen by the listener, nil until it has processed a block
+	PendingMessages    int         `json:"pendingMessages"`    // Messages routed to the chain that its writer has not resolved
+	CircuitBreakerOpen bool        `json:"circuitBreakerOpen"` // Always false for chains that do not implement CircuitBreaker
+}
+
+// BridgeStatus is the state of every chain in the Registry and of the router between them
+type BridgeStatus struct {
+	Chains []ChainStatus        `json:"chains"`
+	Router router.RouterMetrics `json:"router"`
+}
+
+// Status returns the current state of the registered chains, in the order they were added
+func (c *Core) Status() BridgeStatus {
+	registry := c.chains()
+	status := BridgeStatus{
+		Chains: make([]ChainStatus, len(registry)),
+		Router: c.route.Metrics(),
+	}
+	for i, chain := range registry {
+		status.Chains[i] = ChainStatus{
+			Id:              chain.Id(),
+			Name:            chain.Name(),
+			Running:         chain.Running(),
+			LatestBlock:     chain.LatestBlock().Height,
+			PendingMessages: c.route.Pending(chain.Id()),
+		}
+		if breaker, ok := chain.(CircuitBreaker); ok {
+			status.Chains[i].CircuitBreakerOpen = breaker.CircuitBreakerOpen()
+		}
+	}
+	return status
+}
+
+// Queue returns the messages routed to chain id that its writer has not resolved, as described by router.Queue
+func (c *Core) Queue(id msg.ChainId) ([]router.QueuedMessage, error) {
+	if c.chain(id) == nil {
+		return nil, fmt.Errorf("%w: %d", ErrChainNotFound, id)
+	}
+	return c.route.Queue(id), nil
+}
+
+// DrainNonce removes the message from source with nonce from the queue of chain id, so it is not resolved, and
+// returns whether it was queued
+func (c *Core) DrainNonce(id msg.ChainId, source msg.ChainId, nonce msg.Nonce) bool {
+	return c.route.DrainNonce(id, source, nonce)
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The ed25519 package provides a crypto.Keypair for chains that sign with Ed25519, the Edwards-curve signature scheme.
+
+Keypairs are encoded as their 32 byte seed. The address of a keypair is its hex encoded public key, chains that use
+another address format derive it from PublicKeyBytes.
+*/
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Ed25519Type is the key type of ed25519 keypairs in key files
+const Ed25519Type crypto.KeyType = "ed25519"
+
+var _ crypto.Keypair = &Keypair{}
+
+type Keypair struct {
+	private ed25519.PrivateKey
+}
+
+// GenerateKeypair returns a new random keypair
+func GenerateKeypair() (*Keypair, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Keypair{private: private}, nil
+}
+
+// NewKeypairFromSeed returns the keypair of a 32 byte seed
+func NewKeypairFromSeed(seed []byte) (*Keypair, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 seed length %d, expected %d", len(seed), ed25519.SeedSize)
+	}
+	return &Keypair{private: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// Encode returns the seed of the keypair
+func (kp *Keypair) Encode() []byte {
+	return kp.private.Seed()
+}
+
+// Decode initializes the keypair from its seed
+func (kp *Keypair) Decode(in []byte) error {
+	decoded, err := NewKeypairFromSeed(in)
+	if err != nil {
+		return err
+	}
+	kp.private = decoded.private
+	return nil
+}
+
+// Address returns the hex encoded public key
+func (kp *Keypair) Address() string {
+	return kp.PublicKey()
+}
+
+// PublicKey returns the hex encoded public key
+func (kp *Keypair) PublicKey() string {
+	return hexutil.Encode(kp.PublicKeyBytes())
+}
+
+// PublicKeyBytes returns the 32 byte public key
+func (kp *Keypair) PublicKeyBytes() []byte {
+	return []byte(kp.private.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the signature of msg
+func (kp *Keypair) Sign(msg []byte) []byte {
+	return ed25519.Sign(kp.private, msg)
+}
+
+// Verify returns whether sig is a valid signature of msg by the holder of the public key
+func Verify(publicKey, msg, sig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(publicKey, msg, sig)
+}
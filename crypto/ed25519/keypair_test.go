@@ -0,0 +1,69 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ed25519
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+)
+
+func TestKeypair_SignVerify(t *testing.T) {
+	kp, err := GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("deposit")
+	sig := kp.Sign(msg)
+	if !Verify(kp.PublicKeyBytes(), msg, sig) {
+		t.Fatal("expected the signature to verify")
+	}
+	if Verify(kp.PublicKeyBytes(), []byte("another deposit"), sig) {
+		t.Fatal("expected the signature of another message not to verify")
+	}
+
+	other, err := GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(other.PublicKeyBytes(), msg, sig) {
+		t.Fatal("expected the signature not to verify with another key")
+	}
+}
+
+func TestKeypair_EncodeDecode(t *testing.T) {
+	kp, err := GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Keypair{}
+	err = decoded.Decode(kp.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Address() != kp.Address() {
+		t.Fatalf("expected address %s, got: %s", kp.Address(), decoded.Address())
+	}
+	if !bytes.Equal(decoded.Sign([]byte("deposit")), kp.Sign([]byte("deposit"))) {
+		t.Fatal("expected the decoded keypair to sign as the original")
+	}
+
+	err = decoded.Decode([]byte{1, 2, 3})
+	if err == nil {
+		t.Fatal("expected an error for a short seed")
+	}
+}
+
+func TestKeypair_NotSecp256k1(t *testing.T) {
+	kp, err := GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keypair crypto.Keypair = kp
+	if _, ok := keypair.(*secp256k1.Keypair); ok {
+		t.Fatal("expected an ed25519 keypair not to be a secp256k1 keypair")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package fireblocks
+
+// publicKeyInfo is the public key of a vault account's address
+type publicKeyInfo struct {
+	Algorithm      string `json:"algorithm"`
+	DerivationPath []int  `json:"derivationPath"`
+	PublicKey      string `json:"publicKey"` // Hex encoded, without a 0x prefix
+}
+
+// transferPeer identifies the vault account a transaction is created by
+type transferPeer struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type rawMessage struct {
+	Content string `json:"content"` // Hex encoded hash, without a 0x prefix
+}
+
+type rawMessageData struct {
+	Messages []rawMessage `json:"messages"`
+}
+
+type extraParameters struct {
+	RawMessageData rawMessageData `json:"rawMessageData"`
+}
+
+// transactionRequest creates a transaction, a raw signing request for the RAW operation
+type transactionRequest struct {
+	Operation       string          `json:"operation"`
+	AssetId         string          `json:"assetId"`
+	Source          transferPeer    `json:"source"`
+	Note            string          `json:"note,omitempty"`
+	ExtraParameters extraParameters `json:"extraParameters"`
+}
+
+type signature struct {
+	FullSig string `json:"fullSig"`
+	R       string `json:"r"`
+	S       string `json:"s"`
+	V       int    `json:"v"`
+}
+
+type signedMessage struct {
+	Content   string    `json:"content"`
+	Signature signature `json:"signature"`
+	PublicKey string    `json:"publicKey"`
+}
+
+// transaction is the state of a transaction
+type transaction struct {
+	Id             string          `json:"id"`
+	Status         string          `json:"status"`
+	SubStatus      string          `json:"subStatus"`
+	SignedMessages []signedMessage `json:"signedMessages"`
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package fireblocks
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeout is the maximum time to wait for a response from the Fireblocks API
+const Timeout = 10 * time.Second
+
+// tokenLifetime is how long the JWT authenticating each request is valid for
+const tokenLifetime = 55 * time.Second
+
+// MaxRetries is the number of times a rate limited request is retried
+var MaxRetries = 5
+
+// InitialBackoff is the wait before retrying a rate limited request, doubled for each retry unless the API sets
+// Retry-After
+var InitialBackoff = 500 * time.Millisecond
+
+var ErrRateLimited = errors.New("fireblocks API rate limit exceeded")
+
+// Client is a client for the Fireblocks API. Each request is authenticated by the API key and a JWT signed with the
+// API user's RSA secret key.
+type Client struct {
+	url    string
+	apiKey string
+	secret *rsa.PrivateKey
+	client *http.Client
+}
+
+func NewClient(url, apiKey string, secret *rsa.PrivateKey) *Client {
+	return &Client{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, secret: secret, client: &http.Client{Timeout: Timeout}}
+}
+
+// LoadSecretKey reads the PEM encoded RSA secret key of an API user from path
+func LoadSecretKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse secret key %s: %w", path, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("secret key %s is not an RSA key", path)
+	}
+	return rsaKey, nil
+}
+
+// do sends the request body as JSON and decodes the response into result, if it is not nil. Rate limited requests
+// are retried with exponential backoff.
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	backoff := InitialBackoff
+	for retry := 0; ; retry++ {
+		err := c.send(ctx, method, path, reqBody, result)
+		if !errors.Is(err, ErrRateLimited) || retry >= MaxRetries {
+			return err
+		}
+
+		wait := backoff
+		var limited *rateLimitError
+		if errors.As(err, &limited) && limited.retryAfter > 0 {
+			wait = limited.retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// rateLimitError is returned by send when the API responds with 429 Too Many Requests
+type rateLimitError struct {
+	retryAfter time.Duration // Wait requested by the API, zero if it did not set Retry-After
+}
+
+func (e *rateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// send makes a single request
+func (c *Client) send(ctx context.Context, method, path string, reqBody []byte, result interface{}) error {
+	token, err := c.token(path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		seconds, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		return &rateLimitError{retryAfter: time.Duration(seconds) * time.Second}
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("fireblocks API returned status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resBody, result)
+}
+
+// token returns the RS256 JWT authenticating a request to path with body
+func (c *Client) token(path string, body []byte) (string, error) {
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	bodyHash := sha256.Sum256(body)
+	now := time.Now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"uri":      path,
+		"nonce":    hex.EncodeToString(nonce),
+		"iat":      now.Unix(),
+		"exp":      now.Add(tokenLifetime).Unix(),
+		"sub":      c.apiKey,
+		"bodyHash": hex.EncodeToString(bodyHash[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.secret, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The fireblocks package signs relayer transactions with a key held in Fireblocks MPC custody, for operators that
+cannot hold the private key of their relayer.
+
+Each hash is signed by creating a raw signing transaction from the vault account, which is approved by the
+workspace's transaction authorization policy. The signature is returned once the transaction is completed.
+*/
+package fireblocks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// AssetId is the Fireblocks asset whose key signs, ETH uses the BIP44 ethereum derivation path
+const AssetId = "ETH"
+
+// PollInterval is the time between checks for the completion of a signing request
+var PollInterval = time.Second
+
+// SignTimeout is the maximum time to wait for a signing request to be approved and completed
+var SignTimeout = 10 * time.Minute
+
+var ErrSigningFailed = errors.New("fireblocks signing request did not complete")
+var ErrNotExportable = errors.New("fireblocks keys cannot be exported")
+
+// Transaction statuses that will not complete
+var failedStatuses = map[string]bool{
+	"CANCELLED": true,
+	"REJECTED":  true,
+	"BLOCKED":   true,
+	"FAILED":    true,
+	"TIMEOUT":   true,
+}
+
+var _ crypto.Keypair = &FireblocksKeypair{}
+
+// FireblocksKeypair is a secp256k1 key held by a Fireblocks vault account
+type FireblocksKeypair struct {
+	client  *Client
+	vaultId string
+	public  *ecdsa.PublicKey
+}
+
+// NewFireblocksKeypair returns the keypair of the vault account's ETH key, fetching its public key
+func NewFireblocksKeypair(ctx context.Context, client *Client, vaultId string) (*FireblocksKeypair, error) {
+	var info publicKeyInfo
+	err := client.do(ctx, http.MethodGet, fmt.Sprintf("/v1/vault/accounts/%s/%s/0/0/public_key_info", vaultId, AssetId), nil, &info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get public key of vault account %s: %w", vaultId, err)
+	}
+	b, err := hexutil.Decode("0x" + info.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of vault account %s: %w", vaultId, err)
+	}
+	public, err := ethcrypto.DecompressPubkey(b)
+	if err != nil {
+		public, err = ethcrypto.UnmarshalPubkey(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of vault account %s: %w", vaultId, err)
+	}
+	return &FireblocksKeypair{client: client, vaultId: vaultId, public: public}, nil
+}
+
+// Encode returns nil, the private key never leaves Fireblocks
+func (kp *FireblocksKeypair) Encode() []byte {
+	return nil
+}
+
+// Decode always returns ErrNotExportable
+func (kp *FireblocksKeypair) Decode([]byte) error {
+	return ErrNotExportable
+}
+
+// Address returns the Ethereum address format
+func (kp *FireblocksKeypair) Address() string {
+	return kp.CommonAddress().String()
+}
+
+// CommonAddress returns the Ethereum address in the common.Address Format
+func (kp *FireblocksKeypair) CommonAddress() common.Address {
+	return ethcrypto.PubkeyToAddress(*kp.public)
+}
+
+// PublicKey returns the public key hex encoded
+func (kp *FireblocksKeypair) PublicKey() string {
+	return hexutil.Encode(ethcrypto.CompressPubkey(kp.public))
+}
+
+// Sign signs the 32 byte hash and returns the DER-encoded signature
+func (kp *FireblocksKeypair) Sign(hash []byte) ([]byte, error) {
+	sig, err := kp.SignRecoverable(hash)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])})
+}
+
+// SignRecoverable signs the 32 byte hash and returns the signature in the [R || S || V] format, with V of 0 or 1,
+// as used to sign ethereum transactions. The signing request is created and polled until it completes. V is found
+// by recovering the public key, so the signature is checked against the vault's key.
+func (kp *FireblocksKeypair) SignRecoverable(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), SignTimeout)
+	defer cancel()
+
+	var created transaction
+	err := kp.client.do(ctx, http.MethodPost, "/v1/transactions", &transactionRequest{
+		Operation: "RAW",
+		AssetId:   AssetId,
+		Source:    transferPeer{Type: "VAULT_ACCOUNT", Id: kp.vaultId},
+		Note:      "chainbridge relayer",
+		ExtraParameters: extraParameters{RawMessageData: rawMessageData{
+			Messages: []rawMessage{{Content: common.Bytes2Hex(hash)}},
+		}},
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create signing request: %w", err)
+	}
+
+	tx, err := kp.waitForCompletion(ctx, created.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.SignedMessages) != 1 {
+		return nil, fmt.Errorf("%w: transaction %s has %d signatures", ErrSigningFailed, tx.Id, len(tx.SignedMessages))
+	}
+	signed := tx.SignedMessages[0].Signature
+	r, ok := new(big.Int).SetString(signed.R, 16)
+	if !ok || r.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid signature r: %s", signed.R)
+	}
+	s, ok := new(big.Int).SetString(signed.S, 16)
+	if !ok || s.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid signature s: %s", signed.S)
+	}
+
+	sig := make([]byte, ethcrypto.SignatureLength)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		pub, err := ethcrypto.SigToPub(hash, sig)
+		if err == nil && ethcrypto.PubkeyToAddress(*pub) == kp.CommonAddress() {
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: transaction %s was not signed by the vault key", ErrSigningFailed, tx.Id)
+}
+
+// waitForCompletion polls the transaction until it is completed
+func (kp *FireblocksKeypair) waitForCompletion(ctx context.Context, id string) (*transaction, error) {
+	for {
+		var tx transaction
+		err := kp.client.do(ctx, http.MethodGet, "/v1/transactions/"+id, nil, &tx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get signing request %s: %w", id, err)
+		}
+		if tx.Status == "COMPLETED" {
+			return &tx, nil
+		}
+		if failedStatuses[tx.Status] {
+			return nil, fmt.Errorf("%w: transaction %s is %s (%s)", ErrSigningFailed, id, tx.Status, tx.SubStatus)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: timed out waiting for transaction %s, status %s", ErrSigningFailed, id, tx.Status)
+		case <-time.After(PollInterval):
+		}
+	}
+}
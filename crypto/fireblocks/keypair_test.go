@@ -0,0 +1,321 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package fireblocks
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var AliceKp = keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+
+const testApiKey = "test-api-key"
+
+// mockFireblocks implements the Fireblocks API endpoints used by FireblocksKeypair, signing with Alice's key.
+// Requests are rejected unless they are authenticated by the API key and a JWT signed by secret.
+type mockFireblocks struct {
+	secret       *rsa.PrivateKey
+	vaultId      string
+	completeAt   int    // Polls of a transaction before it is completed
+	status       string // Status reported once completed
+	rateLimited  int    // Number of requests answered with 429 before the API responds
+	retryAfter   string // Retry-After header of rate limited responses
+	lock         sync.Mutex
+	transactions map[string][]byte // Hashes to sign, by transaction ID
+	polls        int
+	requests     int
+}
+
+func newMockFireblocks(t *testing.T) *mockFireblocks {
+	secret, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &mockFireblocks{secret: secret, vaultId: "3", status: "COMPLETED", transactions: make(map[string][]byte)}
+}
+
+func (m *mockFireblocks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.requests++
+
+	body, _ := ioutil.ReadAll(r.Body)
+	err := m.authenticate(r, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if m.rateLimited > 0 {
+		m.rateLimited--
+		w.Header().Set("Retry-After", m.retryAfter)
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/v1/vault/accounts/%s/ETH/0/0/public_key_info", m.vaultId):
+		pub := ethcrypto.CompressPubkey(&AliceKp.PrivateKey().PublicKey)
+		_ = json.NewEncoder(w).Encode(publicKeyInfo{Algorithm: "MPC_ECDSA_SECP256K1", DerivationPath: []int{44, 60, 0, 0, 0}, PublicKey: hex.EncodeToString(pub)})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/transactions":
+		var req transactionRequest
+		err := json.Unmarshal(body, &req)
+		if err != nil || req.Operation != "RAW" || req.Source.Id != m.vaultId || len(req.ExtraParameters.RawMessageData.Messages) != 1 {
+			http.Error(w, "invalid transaction request", http.StatusBadRequest)
+			return
+		}
+		id := fmt.Sprintf("tx-%d", len(m.transactions))
+		m.transactions[id] = common.FromHex(req.ExtraParameters.RawMessageData.Messages[0].Content)
+		_ = json.NewEncoder(w).Encode(transaction{Id: id, Status: "SUBMITTED"})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/transactions/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+		hash, ok := m.transactions[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		m.polls++
+		if m.polls <= m.completeAt {
+			_ = json.NewEncoder(w).Encode(transaction{Id: id, Status: "PENDING_SIGNATURE"})
+			return
+		}
+		sig, _ := ethcrypto.Sign(hash, AliceKp.PrivateKey())
+		_ = json.NewEncoder(w).Encode(transaction{Id: id, Status: m.status, SignedMessages: []signedMessage{{
+			Content:   hex.EncodeToString(hash),
+			Signature: signature{FullSig: hex.EncodeToString(sig[:64]), R: hex.EncodeToString(sig[:32]), S: hex.EncodeToString(sig[32:64]), V: int(sig[64])},
+		}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authenticate checks the request has the API key and a JWT for its path and body, signed by the secret key
+func (m *mockFireblocks) authenticate(r *http.Request, body []byte) error {
+	if r.Header.Get("X-API-Key") != testApiKey {
+		return errors.New("invalid API key")
+	}
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		return errors.New("invalid token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(&m.secret.PublicKey, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Uri      string `json:"uri"`
+		Sub      string `json:"sub"`
+		BodyHash string `json:"bodyHash"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return err
+	}
+	bodyHash := sha256.Sum256(body)
+	if claims.Uri != r.URL.Path || claims.Sub != testApiKey || claims.BodyHash != hex.EncodeToString(bodyHash[:]) {
+		return errors.New("invalid token claims")
+	}
+	return nil
+}
+
+func newTestKeypair(t *testing.T, m *mockFireblocks) *FireblocksKeypair {
+	server := httptest.NewServer(m)
+	t.Cleanup(server.Close)
+	kp, err := NewFireblocksKeypair(context.Background(), NewClient(server.URL, testApiKey, m.secret), m.vaultId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func setPollInterval(t *testing.T, interval time.Duration) {
+	old := PollInterval
+	PollInterval = interval
+	t.Cleanup(func() { PollInterval = old })
+}
+
+func TestFireblocksKeypair_Sign(t *testing.T) {
+	setPollInterval(t, time.Millisecond)
+	m := newMockFireblocks(t)
+	m.completeAt = 2
+	kp := newTestKeypair(t, m)
+
+	if kp.CommonAddress() != AliceKp.CommonAddress() {
+		t.Fatalf("expected address %s, got: %s", AliceKp.Address(), kp.Address())
+	}
+	if kp.PublicKey() != AliceKp.PublicKey() {
+		t.Fatalf("expected public key %s, got: %s", AliceKp.PublicKey(), kp.PublicKey())
+	}
+
+	hash := ethcrypto.Keccak256([]byte("message"))
+	der, err := kp.Sign(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sig struct{ R, S *big.Int }
+	_, err = asn1.Unmarshal(der, &sig)
+	if err != nil {
+		t.Fatalf("expected a DER encoded signature: %v", err)
+	}
+	rs := make([]byte, 64)
+	sig.R.FillBytes(rs[:32])
+	sig.S.FillBytes(rs[32:])
+	if !ethcrypto.VerifySignature(ethcrypto.CompressPubkey(&AliceKp.PrivateKey().PublicKey), hash, rs) {
+		t.Fatal("expected the signature to be valid")
+	}
+
+	recoverable, err := kp.SignRecoverable(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ethcrypto.SigToPub(hash, recoverable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethcrypto.PubkeyToAddress(*pub) != AliceKp.CommonAddress() {
+		t.Fatal("expected the recoverable signature to recover the vault address")
+	}
+}
+
+func TestFireblocksKeypair_SignFailed(t *testing.T) {
+	setPollInterval(t, time.Millisecond)
+	m := newMockFireblocks(t)
+	m.status = "REJECTED"
+	kp := newTestKeypair(t, m)
+
+	_, err := kp.Sign(ethcrypto.Keccak256([]byte("message")))
+	if !errors.Is(err, ErrSigningFailed) {
+		t.Fatalf("expected ErrSigningFailed, got: %v", err)
+	}
+	_, err = kp.Sign([]byte("short"))
+	if err == nil {
+		t.Fatal("expected an error for a hash that is not 32 bytes")
+	}
+	if kp.Decode(nil) != ErrNotExportable {
+		t.Fatal("expected Decode to return ErrNotExportable")
+	}
+}
+
+func TestClient_RateLimited(t *testing.T) {
+	oldBackoff, oldRetries := InitialBackoff, MaxRetries
+	InitialBackoff, MaxRetries = 10*time.Millisecond, 3
+	defer func() { InitialBackoff, MaxRetries = oldBackoff, oldRetries }()
+
+	m := newMockFireblocks(t)
+	m.rateLimited = 3
+	server := httptest.NewServer(m)
+	defer server.Close()
+	client := NewClient(server.URL, testApiKey, m.secret)
+
+	// Retried after 10, 20 and 40ms
+	start := time.Now()
+	_, err := NewFireblocksKeypair(context.Background(), client, m.vaultId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Fatalf("expected exponential backoff, retried after %s", elapsed)
+	}
+	if m.requests != 4 {
+		t.Fatalf("expected 4 requests, got: %d", m.requests)
+	}
+
+	// Retry-After is respected, and requests fail once the retries are exhausted
+	m.rateLimited = 4
+	m.retryAfter = "0"
+	_, err = NewFireblocksKeypair(context.Background(), client, m.vaultId)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	m := newMockFireblocks(t)
+	server := httptest.NewServer(m)
+	defer server.Close()
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewFireblocksKeypair(context.Background(), NewClient(server.URL, testApiKey, other), m.vaultId)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected the request to be unauthorized, got: %v", err)
+	}
+}
+
+func TestLoadSecretKey(t *testing.T) {
+	m := newMockFireblocks(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(m.secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "fireblocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, block := range map[string]*pem.Block{
+		"pkcs1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(m.secret)},
+		"pkcs8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		path := filepath.Join(dir, name+".key")
+		err = ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := LoadSecretKey(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !key.Equal(m.secret) {
+			t.Fatalf("%s: loaded a different key", name)
+		}
+	}
+
+	path := filepath.Join(dir, "invalid.key")
+	err = ioutil.WriteFile(path, []byte("not a key"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadSecretKey(path)
+	if err == nil {
+		t.Fatal("expected error for a file without a PEM block")
+	}
+}
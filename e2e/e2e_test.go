@@ -13,6 +13,7 @@ import (
 
 	ethChain "github.com/ChainSafe/ChainBridge/chains/ethereum"
 	subChain "github.com/ChainSafe/ChainBridge/chains/substrate"
+	"github.com/ChainSafe/ChainBridge/core"
 	eth "github.com/ChainSafe/ChainBridge/e2e/ethereum"
 	sub "github.com/ChainSafe/ChainBridge/e2e/substrate"
 	"github.com/ChainSafe/ChainBridge/shared"
@@ -20,7 +21,6 @@ import (
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
 	subutils "github.com/ChainSafe/ChainBridge/shared/substrate"
 	subtest "github.com/ChainSafe/ChainBridge/shared/substrate/testing"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	log "github.com/ChainSafe/log15"
 	"github.com/centrifuge/go-substrate-rpc-client/types"
@@ -89,9 +89,9 @@ func createAndStartBridge(t *testing.T, name string, contractsA, contractsB *eth
 	}
 
 	bridge := core.NewCore(sysErr)
-	bridge.AddChain(ethA)
-	bridge.AddChain(subA)
-	bridge.AddChain(ethB)
+	bridge.Register(ethA)
+	bridge.Register(subA)
+	bridge.Register(ethB)
 
 	err = ethA.Start()
 	if err != nil {
@@ -7,15 +7,15 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"os"
 	"testing"
 	"time"
 
 	bridge "github.com/ChainSafe/ChainBridge/bindings/Bridge"
+	"github.com/ChainSafe/ChainBridge/blockstore"
 	"github.com/ChainSafe/ChainBridge/chains/ethereum"
+	"github.com/ChainSafe/ChainBridge/core"
 	utils "github.com/ChainSafe/ChainBridge/shared/ethereum"
 	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
 	"github.com/ChainSafe/chainbridge-utils/keystore"
 	"github.com/ChainSafe/chainbridge-utils/msg"
@@ -60,17 +60,18 @@ func CreateConfig(key string, chain msg.ChainId, contracts *utils.DeployedContra
 		Name:           fmt.Sprintf("ethereum(%s,%d)", key, chain),
 		Id:             chain,
 		Endpoint:       endpoint,
-		From:           "",
+		From:           keystore.TestKeyRing.EthereumKeys[key].Address(), // Insecure keys are loaded by name, from is only validated
 		KeystorePath:   key,
 		Insecure:       true,
 		FreshStart:     true,
-		BlockstorePath: os.TempDir(),
+		BlockstorePath: blockstore.MemoryPath,
 		Opts: map[string]string{
 			"bridge":             contracts.BridgeAddress.String(),
 			"erc20Handler":       contracts.ERC20HandlerAddress.String(),
 			"erc721Handler":      contracts.ERC721HandlerAddress.String(),
 			"genericHandler":     contracts.GenericHandlerAddress.String(),
 			"blockConfirmations": "3",
+			"minPeerCount":       "0", // Development nodes have no peers
 		},
 	}
 }
@@ -5,12 +5,12 @@ package substrate
 
 import (
 	"fmt"
-	"os"
 	"testing"
 	"time"
 
+	"github.com/ChainSafe/ChainBridge/blockstore"
+	"github.com/ChainSafe/ChainBridge/core"
 	utils "github.com/ChainSafe/ChainBridge/shared/substrate"
-	"github.com/ChainSafe/chainbridge-utils/core"
 	"github.com/ChainSafe/chainbridge-utils/keystore"
 	"github.com/ChainSafe/chainbridge-utils/msg"
 	"github.com/ChainSafe/log15"
@@ -44,7 +44,7 @@ func CreateConfig(key string, chain msg.ChainId) *core.ChainConfig {
 		KeystorePath:   key,
 		Insecure:       true,
 		FreshStart:     true,
-		BlockstorePath: os.TempDir(),
+		BlockstorePath: blockstore.MemoryPath,
 		Opts:           map[string]string{"useExtendedCall": "true"},
 	}
 }
@@ -0,0 +1,139 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The errors package provides typed errors for classifying bridge failures.
+
+Each error type carries a Code identifying the failure, the Chain it occurred on and whether the
+operation is Retryable. Use errors.As to match a type, and IsRetryable to decide whether to retry.
+*/
+package errors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// Connection failures
+const (
+	CodeConnectFailed = 1000 + iota
+	CodeQueryFailed
+)
+
+// Contract failures
+const (
+	CodeNoBytecode = 2000 + iota
+	CodeCallFailed
+	CodeTxFailed
+	CodeTxNonce
+)
+
+// Signing failures
+const (
+	CodeSignerUnavailable = 3000 + iota
+	CodeSignFailed
+)
+
+// Blockstore failures
+const (
+	CodeBlockstoreOpen = 4000 + iota
+	CodeBlockstoreLoad
+)
+
+// Config failures
+const (
+	CodeInvalidConfig = 5000 + iota
+	CodeKeystore
+	CodeChainIdMismatch
+	CodeNotRelayer
+)
+
+// BridgeError holds the fields common to all bridge error types
+type BridgeError struct {
+	Code      int
+	Chain     msg.ChainId
+	Retryable bool
+	Err       error
+}
+
+func (e *BridgeError) format(kind string) string {
+	return fmt.Sprintf("%s error on chain %d (code %d): %v", kind, e.Chain, e.Code, e.Err)
+}
+
+func (e *BridgeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BridgeError) retryable() bool {
+	return e.Retryable
+}
+
+func (e *BridgeError) setChain(id msg.ChainId) {
+	e.Chain = id
+}
+
+// ConnectionError indicates a failure communicating with a chain's node
+type ConnectionError struct{ BridgeError }
+
+func (e *ConnectionError) Error() string { return e.format("connection") }
+
+// ContractError indicates a contract call or transaction failed
+type ContractError struct{ BridgeError }
+
+func (e *ContractError) Error() string { return e.format("contract") }
+
+// SigningError indicates the relayer key could not sign a transaction
+type SigningError struct{ BridgeError }
+
+func (e *SigningError) Error() string { return e.format("signing") }
+
+// BlockstoreError indicates the blockstore could not be read or written
+type BlockstoreError struct{ BridgeError }
+
+func (e *BlockstoreError) Error() string { return e.format("blockstore") }
+
+// ConfigError indicates invalid or mismatched configuration
+type ConfigError struct{ BridgeError }
+
+func (e *ConfigError) Error() string { return e.format("config") }
+
+func NewConnectionError(code int, retryable bool, err error) *ConnectionError {
+	return &ConnectionError{BridgeError{Code: code, Retryable: retryable, Err: err}}
+}
+
+func NewContractError(code int, retryable bool, err error) *ContractError {
+	return &ContractError{BridgeError{Code: code, Retryable: retryable, Err: err}}
+}
+
+func NewSigningError(code int, retryable bool, err error) *SigningError {
+	return &SigningError{BridgeError{Code: code, Retryable: retryable, Err: err}}
+}
+
+func NewBlockstoreError(code int, retryable bool, err error) *BlockstoreError {
+	return &BlockstoreError{BridgeError{Code: code, Retryable: retryable, Err: err}}
+}
+
+func NewConfigError(code int, retryable bool, err error) *ConfigError {
+	return &ConfigError{BridgeError{Code: code, Retryable: retryable, Err: err}}
+}
+
+// IsRetryable reports whether the first bridge error in err's chain is retryable.
+// Errors that are not bridge errors are treated as retryable.
+func IsRetryable(err error) bool {
+	var r interface{ retryable() bool }
+	if errors.As(err, &r) {
+		return r.retryable()
+	}
+	return true
+}
+
+// WithChain sets the chain ID on the first bridge error in err's chain, returning err
+func WithChain(err error, id msg.ChainId) error {
+	var c interface{ setChain(msg.ChainId) }
+	if errors.As(err, &c) {
+		c.setChain(id)
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestErrorsAs(t *testing.T) {
+	cause := errors.New("dial failed")
+	err := fmt.Errorf("initializing chain: %w", WithChain(NewConnectionError(CodeConnectFailed, true, cause), msg.ChainId(3)))
+
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected ConnectionError, got: %v", err)
+	}
+	if connErr.Code != CodeConnectFailed || connErr.Chain != 3 || !connErr.Retryable {
+		t.Fatalf("unexpected fields: %+v", connErr.BridgeError)
+	}
+
+	var contractErr *ContractError
+	if errors.As(err, &contractErr) {
+		t.Fatal("should not match ContractError")
+	}
+
+	if !errors.Is(err, cause) {
+		t.Fatal("should unwrap to cause")
+	}
+
+	expected := "initializing chain: connection error on chain 3 (code 1000): dial failed"
+	if err.Error() != expected {
+		t.Fatalf("got: %s expected: %s", err.Error(), expected)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cause := errors.New("cause")
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"connection", NewConnectionError(CodeQueryFailed, true, cause), true},
+		{"contract", NewContractError(CodeNoBytecode, false, cause), false},
+		{"signing", NewSigningError(CodeSignerUnavailable, false, cause), false},
+		{"blockstore", NewBlockstoreError(CodeBlockstoreLoad, false, cause), false},
+		{"config", NewConfigError(CodeInvalidConfig, false, cause), false},
+		{"wrapped", fmt.Errorf("wrapped: %w", NewSigningError(CodeSignFailed, false, cause)), false},
+		{"plain", cause, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if IsRetryable(tc.err) != tc.retryable {
+				t.Fatalf("expected retryable=%t for %v", tc.retryable, tc.err)
+			}
+		})
+	}
+}
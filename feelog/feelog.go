@@ -0,0 +1,171 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package feelog records the gas used and fee paid for each proposal transaction a relayer sends, and summarizes
+// the records for reporting relay costs.
+package feelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Record is the cost of a mined proposal transaction
+type Record struct {
+	Timestamp    time.Time   `json:"timestamp"` // When the receipt was received
+	Chain        string      `json:"chain"`     // Name of the chain the transaction was sent on
+	Source       msg.ChainId `json:"source"`
+	DepositNonce msg.Nonce   `json:"depositNonce"`
+	ResourceId   string      `json:"resourceId,omitempty"` // Hex resource ID of the deposit, empty in older records
+	Method       string      `json:"method"`               // Contract method called, eg. voteProposal or executeProposal
+	GasUsed      uint64      `json:"gasUsed"`
+	GasPrice     *big.Int    `json:"gasPrice"` // Effective price paid per gas, in wei
+	TxFeeWei     *big.Int    `json:"txFeeWei"`
+	TxHash       common.Hash `json:"txHash"`
+}
+
+// Log appends records to a file as JSON lines. It is safe for concurrent use.
+type Log struct {
+	file *os.File
+	lock sync.Mutex
+}
+
+// Open opens the log at path for appending, creating it if it does not exist
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{file: file}, nil
+}
+
+// Append writes r to the end of the log
+func (l *Log) Append(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the log's file
+func (l *Log) Close() error {
+	return l.file.Close()
+}
+
+// Read returns the records in the log at path with a timestamp from from (inclusive) to to (exclusive), in the
+// order they were appended. A zero from or to leaves the range unbounded on that side.
+func Read(path string, from, to time.Time) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		err = json.Unmarshal(scanner.Bytes(), &r)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		if (!from.IsZero() && r.Timestamp.Before(from)) || (!to.IsZero() && !r.Timestamp.Before(to)) {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// Report aggregates the cost of a set of records
+type Report struct {
+	Count         int
+	TotalWei      *big.Int
+	AverageWei    *big.Int // Rounded down, zero if there are no records
+	MostExpensive *Record  // nil if there are no records
+}
+
+// Summarize totals the fees of records. If several records have the highest fee the first is the most expensive.
+func Summarize(records []Record) Report {
+	report := Report{Count: len(records), TotalWei: new(big.Int), AverageWei: new(big.Int)}
+	for i := range records {
+		fee := records[i].TxFeeWei
+		if fee == nil {
+			continue
+		}
+		report.TotalWei.Add(report.TotalWei, fee)
+		if report.MostExpensive == nil || fee.Cmp(report.MostExpensive.TxFeeWei) > 0 {
+			report.MostExpensive = &records[i]
+		}
+	}
+	if report.Count != 0 {
+		report.AverageWei.Div(report.TotalWei, big.NewInt(int64(report.Count)))
+	}
+	return report
+}
+
+// ResourceGas is the average gas used to execute the proposals of a resource ID
+type ResourceGas struct {
+	ResourceId string
+	Count      int
+	AverageGas float64
+}
+
+// GasAverages keeps the average gas used by executeProposal transactions for each resource ID. The zero value has
+// no records.
+type GasAverages struct {
+	gas map[string]*ResourceGas
+}
+
+// Add updates the average of r's resource ID. Records of other methods or without a resource ID are ignored.
+func (a *GasAverages) Add(r Record) {
+	if r.Method != "executeProposal" || r.ResourceId == "" {
+		return
+	}
+	if a.gas == nil {
+		a.gas = make(map[string]*ResourceGas)
+	}
+	g, ok := a.gas[r.ResourceId]
+	if !ok {
+		g = &ResourceGas{ResourceId: r.ResourceId}
+		a.gas[r.ResourceId] = g
+	}
+	g.Count++
+	g.AverageGas += (float64(r.GasUsed) - g.AverageGas) / float64(g.Count)
+}
+
+// Averages returns the average of each resource ID, highest first
+func (a *GasAverages) Averages() []ResourceGas {
+	averages := make([]ResourceGas, 0, len(a.gas))
+	for _, g := range a.gas {
+		averages = append(averages, *g)
+	}
+	sort.Slice(averages, func(i, j int) bool {
+		if averages[i].AverageGas != averages[j].AverageGas {
+			return averages[i].AverageGas > averages[j].AverageGas
+		}
+		return averages[i].ResourceId < averages[j].ResourceId
+	})
+	return averages
+}
+
+// WeiToEth converts an amount in wei to ether
+func WeiToEth(wei *big.Int) *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package feelog
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "feelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fees.jsonl")
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		// Fees of 1 to 10 gwei, one record per day
+		gasPrice := big.NewInt(int64(i+1) * 1e9)
+		err = log.Append(Record{
+			Timestamp:    start.AddDate(0, 0, i),
+			Chain:        "eth",
+			Source:       1,
+			DepositNonce: msg.Nonce(i),
+			Method:       "voteProposal",
+			GasUsed:      1,
+			GasPrice:     gasPrice,
+			TxFeeWei:     gasPrice,
+			TxHash:       common.BigToHash(big.NewInt(int64(i))),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = log.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := Read(path, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Summarize(records)
+	if report.Count != 10 {
+		t.Fatalf("expected 10 records, got: %d", report.Count)
+	}
+	if report.TotalWei.Cmp(big.NewInt(55e9)) != 0 {
+		t.Fatalf("expected a total of 55 gwei, got: %s", report.TotalWei)
+	}
+	if report.AverageWei.Cmp(big.NewInt(55e8)) != 0 {
+		t.Fatalf("expected an average of 5.5 gwei, got: %s", report.AverageWei)
+	}
+	if report.MostExpensive.DepositNonce != 9 {
+		t.Fatalf("expected nonce 9 to be the most expensive, got: %d", report.MostExpensive.DepositNonce)
+	}
+
+	// The range includes from and excludes to
+	records, err = Read(path, start.AddDate(0, 0, 2), start.AddDate(0, 0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = Summarize(records)
+	if report.Count != 3 || report.TotalWei.Cmp(big.NewInt(12e9)) != 0 || report.AverageWei.Cmp(big.NewInt(4e9)) != 0 {
+		t.Fatalf("unexpected report for nonces 2 to 4: %+v", report)
+	}
+	if report.MostExpensive.DepositNonce != 4 {
+		t.Fatalf("expected nonce 4 to be the most expensive, got: %d", report.MostExpensive.DepositNonce)
+	}
+
+	report = Summarize(nil)
+	if report.Count != 0 || report.TotalWei.Sign() != 0 || report.AverageWei.Sign() != 0 || report.MostExpensive != nil {
+		t.Fatalf("unexpected report for no records: %+v", report)
+	}
+}
+
+func TestGasAverages(t *testing.T) {
+	var averages GasAverages
+	if len(averages.Averages()) != 0 {
+		t.Fatal("expected no averages without records")
+	}
+
+	erc20, erc721 := "01", "02"
+	for i, gas := range []uint64{100000, 200000, 600000} {
+		averages.Add(Record{Method: "executeProposal", ResourceId: erc20, GasUsed: gas})
+		expected := []float64{100000, 150000, 300000}[i]
+		if got := averages.Averages()[0]; got.Count != i+1 || got.AverageGas != expected {
+			t.Fatalf("expected an average of %v after %d executions, got: %+v", expected, i+1, got)
+		}
+	}
+	averages.Add(Record{Method: "executeProposal", ResourceId: erc721, GasUsed: 400000})
+	// Votes and records written before resource IDs were recorded are ignored
+	averages.Add(Record{Method: "voteProposal", ResourceId: erc721, GasUsed: 50000})
+	averages.Add(Record{Method: "executeProposal", GasUsed: 50000})
+
+	got := averages.Averages()
+	expected := []ResourceGas{
+		{ResourceId: erc721, Count: 1, AverageGas: 400000},
+		{ResourceId: erc20, Count: 3, AverageGas: 300000},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d averages, got: %+v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %+v at %d, got: %+v", expected[i], i, got[i])
+		}
+	}
+}
@@ -7,9 +7,19 @@ require (
 	github.com/ChainSafe/chainbridge-utils v1.0.6
 	github.com/ChainSafe/log15 v1.0.0
 	github.com/centrifuge/go-substrate-rpc-client v2.0.0+incompatible
+	github.com/cloudevents/sdk-go/v2 v2.6.1
 	github.com/deckarep/golang-set v1.7.1 // indirect
 	github.com/ethereum/go-ethereum v1.10.11
+	github.com/go-playground/validator/v10 v10.9.0
+	github.com/klauspost/compress v1.13.6
 	github.com/prometheus/client_golang v1.4.1
+	github.com/segmentio/kafka-go v0.4.25
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli/v2 v2.3.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/exporters/jaeger v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.uber.org/goleak v1.1.12
+	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
+	google.golang.org/protobuf v1.27.1
 )
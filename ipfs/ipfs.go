@@ -0,0 +1,196 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The ipfs package stores large generic transfer metadata in IPFS so it does not have to be relayed in full.
+
+A listener uploads metadata longer than its maxOnChainBytes limit and relays a reference in its place, the
+ReferenceMagic byte followed by ipfs://<CID>. The magic byte keeps metadata that is itself an ipfs:// URI, such as
+the URI of an NFT, from being mistaken for a reference. The writer on the destination chain downloads the data for any reference it receives and uses it to
+build the proposal, so every relayer votes on the same proposal whether or not the source relayer replaced the
+metadata. The data is added to and read from an IPFS node through its HTTP API.
+*/
+package ipfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Prefix starts ipfs:// URIs, and follows ReferenceMagic in metadata references
+const Prefix = "ipfs://"
+
+// ReferenceMagic starts every metadata reference
+const ReferenceMagic byte = 0xc6
+
+// DefaultMaxSize limits the metadata read by Get, unless set with SetMaxSize
+const DefaultMaxSize = 1 << 20
+
+// DefaultTimeout limits each request to the IPFS node
+const DefaultTimeout = 30 * time.Second
+
+var ErrInvalidReference = errors.New("invalid ipfs reference")
+
+var ErrMetadataTooLarge = errors.New("ipfs metadata exceeds the size limit")
+
+// IsReference returns true if data is a metadata reference rather than the metadata itself
+func IsReference(data []byte) bool {
+	return len(data) > 0 && data[0] == ReferenceMagic && bytes.HasPrefix(data[1:], []byte(Prefix))
+}
+
+// IsURI returns true if uri is an ipfs:// URI
+func IsURI(uri []byte) bool {
+	return bytes.HasPrefix(uri, []byte(Prefix))
+}
+
+// Reference returns the metadata reference for cid
+func Reference(cid string) []byte {
+	return append([]byte{ReferenceMagic}, Prefix+cid...)
+}
+
+// ParseReference returns the CID from a metadata reference
+func ParseReference(data []byte) (string, error) {
+	if !IsReference(data) {
+		return "", fmt.Errorf("%w: missing reference prefix", ErrInvalidReference)
+	}
+	cid := string(data[1+len(Prefix):])
+	if cid == "" || strings.ContainsAny(cid, "/?# ") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, cid)
+	}
+	return cid, nil
+}
+
+// MetadataStore adds and retrieves metadata using the HTTP API of an IPFS node
+type MetadataStore struct {
+	endpoint string
+	client   *http.Client
+	maxSize  uint64 // Most bytes read by Get
+}
+
+// NewMetadataStore returns a MetadataStore for the node API at endpoint, such as http://localhost:5001
+func NewMetadataStore(endpoint string) *MetadataStore {
+	return &MetadataStore{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: DefaultTimeout},
+		maxSize:  DefaultMaxSize,
+	}
+}
+
+// SetMaxSize sets the most bytes of metadata Get reads, larger metadata is rejected
+func (s *MetadataStore) SetMaxSize(n uint64) {
+	s.maxSize = n
+}
+
+// addResponse is the response to /api/v0/add
+type addResponse struct {
+	Name string
+	Hash string
+	Size string
+}
+
+// Put adds data to IPFS and returns a reference to it
+func (s *MetadataStore) Put(data []byte) ([]byte, error) {
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+	file, err := form.CreateFormFile("file", "metadata")
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = form.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(s.endpoint+"/api/v0/add?pin=true", form.FormDataContentType(), body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add metadata to ipfs: %w", err)
+	}
+	defer resp.Body.Close()
+	err = checkResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add metadata to ipfs: %w", err)
+	}
+
+	var added addResponse
+	err = json.NewDecoder(resp.Body).Decode(&added)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode ipfs add response: %w", err)
+	}
+	if added.Hash == "" {
+		return nil, errors.New("ipfs add response has no hash")
+	}
+	return Reference(added.Hash), nil
+}
+
+// Get returns the data for a reference returned by Put. ErrMetadataTooLarge is returned for data larger than
+// the store's maximum size.
+func (s *MetadataStore) Get(ref []byte) ([]byte, error) {
+	cid, err := ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(s.endpoint+"/api/v0/cat?arg="+url.QueryEscape(cid), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s from ipfs: %w", cid, err)
+	}
+	defer resp.Body.Close()
+	err = checkResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s from ipfs: %w", cid, err)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(s.maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s from ipfs: %w", cid, err)
+	}
+	if uint64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrMetadataTooLarge, cid, s.maxSize)
+	}
+	return data, nil
+}
+
+// Pin pins the content of an ipfs:// URI, such as the URI of a token's metadata, to the node. The URI may include a
+// path within the content, as in ipfs://<CID>/1.json.
+func (s *MetadataStore) Pin(uri string) error {
+	if !strings.HasPrefix(uri, Prefix) {
+		return fmt.Errorf("%w: missing %s prefix", ErrInvalidReference, Prefix)
+	}
+	path := strings.TrimPrefix(uri, Prefix)
+	if path == "" || strings.HasPrefix(path, "/") || strings.ContainsAny(path, "?# ") {
+		return fmt.Errorf("%w: %q", ErrInvalidReference, path)
+	}
+
+	resp, err := s.client.Post(s.endpoint+"/api/v0/pin/add?arg="+url.QueryEscape("/ipfs/"+path), "", nil)
+	if err != nil {
+		return fmt.Errorf("unable to pin %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	err = checkResponse(resp)
+	if err != nil {
+		return fmt.Errorf("unable to pin %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkResponse returns an error including the node's message if the request failed
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
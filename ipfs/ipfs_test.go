@@ -0,0 +1,87 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ipfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	ipfstest "github.com/ChainSafe/ChainBridge/ipfs/testing"
+)
+
+func TestMetadataStore(t *testing.T) {
+	server := ipfstest.NewServer(t)
+	store := NewMetadataStore(server.URL + "/")
+
+	data := bytes.Repeat([]byte{0xab}, 4096)
+	ref, err := store.Put(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsReference(ref) {
+		t.Fatalf("expected an ipfs reference, got: %s", ref)
+	}
+	if server.Files() != 1 {
+		t.Fatalf("expected one file to be added, got: %d", server.Files())
+	}
+
+	res, err := store.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res, data) {
+		t.Fatalf("data mismatch, expected %d bytes got %d", len(data), len(res))
+	}
+
+	_, err = store.Get(Reference("QmMissing"))
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+
+	store.SetMaxSize(1024)
+	_, err = store.Get(ref)
+	if !errors.Is(err, ErrMetadataTooLarge) {
+		t.Fatalf("expected ErrMetadataTooLarge, got: %v", err)
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	cid, err := ParseReference(Reference("QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cid != "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG" {
+		t.Fatalf("unexpected cid: %s", cid)
+	}
+
+	// A plain ipfs:// URI is metadata, not a reference
+	for _, ref := range [][]byte{[]byte("QmNoPrefix"), []byte("ipfs://QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"), Reference(""), Reference("Qm/path")} {
+		_, err = ParseReference(ref)
+		if !errors.Is(err, ErrInvalidReference) {
+			t.Fatalf("expected ErrInvalidReference for %s, got: %v", ref, err)
+		}
+	}
+}
+
+func TestMetadataStore_Pin(t *testing.T) {
+	server := ipfstest.NewServer(t)
+	store := NewMetadataStore(server.URL)
+
+	err := store.Pin("ipfs://QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG/1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := server.Pinned()
+	if len(pinned) != 1 || pinned[0] != "/ipfs/QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG/1.json" {
+		t.Fatalf("unexpected pins: %v", pinned)
+	}
+
+	for _, uri := range []string{"https://example.com/1.json", "ipfs://", "ipfs:///1.json"} {
+		err = store.Pin(uri)
+		if !errors.Is(err, ErrInvalidReference) {
+			t.Fatalf("expected ErrInvalidReference for %s, got: %v", uri, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ipfstest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server is a mock of the IPFS HTTP API that supports add, cat and pin/add, content is kept in memory
+type Server struct {
+	*httptest.Server
+	lock   sync.Mutex
+	files  map[string][]byte
+	pinned []string
+}
+
+// NewServer starts a mock IPFS server that is closed when the test completes
+func NewServer(t *testing.T) *Server {
+	s := &Server{files: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v0/add", s.add)
+	mux.HandleFunc("/api/v0/cat", s.cat)
+	mux.HandleFunc("/api/v0/pin/add", s.pin)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Files returns the number of files that have been added
+func (s *Server) Files() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.files)
+}
+
+// Pinned returns the paths that have been pinned with pin/add, in order
+func (s *Server) Pinned() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.pinned...)
+}
+
+func (s *Server) add(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Content addressed like IPFS, but not a real CID
+	cid := fmt.Sprintf("Qm%x", sha256.Sum256(data))
+	s.lock.Lock()
+	s.files[cid] = data
+	s.lock.Unlock()
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Name": "metadata",
+		"Hash": cid,
+		"Size": fmt.Sprint(len(data)),
+	})
+}
+
+func (s *Server) cat(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	data, ok := s.files[r.URL.Query().Get("arg")]
+	s.lock.Unlock()
+	if !ok {
+		http.Error(w, "file not found", http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func (s *Server) pin(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("arg")
+	if !strings.HasPrefix(path, "/ipfs/") {
+		http.Error(w, "invalid path", http.StatusInternalServerError)
+		return
+	}
+	s.lock.Lock()
+	s.pinned = append(s.pinned, path)
+	s.lock.Unlock()
+	_ = json.NewEncoder(w).Encode(map[string][]string{"Pins": {path}})
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The kafka package publishes the messages the bridge routes to an Apache Kafka topic, for consumers outside the
+bridge.
+
+Each message is published once per destination it is queued for, encoded in the JSON format of the message package.
+Kafka messages are keyed by deposit nonce, so the messages of a nonce are delivered to consumers in order.
+*/
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"strconv"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// BatchTimeout is the longest a message waits to be sent with others in a batch
+var BatchTimeout = 100 * time.Millisecond
+
+var publishErrors = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "chainbridge_kafka_publish_errors_total",
+	Help: "Number of routed messages that could not be published to Kafka",
+})
+
+func init() {
+	prometheus.MustRegister(publishErrors)
+}
+
+var _ router.Sink = &Producer{}
+
+// messageWriter is the part of kafka-go's Writer the producer uses
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+// Producer publishes routed messages to a Kafka topic
+type Producer struct {
+	writer messageWriter
+	log    log15.Logger
+}
+
+// NewProducer returns a producer publishing to topic on brokers, connecting with TLS if tlsEnabled is set. Messages
+// are sent asynchronously so routing is not delayed, and failures are logged.
+func NewProducer(brokers []string, topic string, tlsEnabled bool, log log15.Logger) *Producer {
+	w := &kafkago.Writer{
+		Addr:         kafkago.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafkago.Hash{},
+		RequiredAcks: kafkago.RequireAll,
+		BatchTimeout: BatchTimeout,
+		Async:        true,
+		Completion: func(messages []kafkago.Message, err error) {
+			if err != nil {
+				publishErrors.Add(float64(len(messages)))
+				log.Error("Failed to publish messages to kafka", "topic", topic, "count", len(messages), "err", err)
+			}
+		},
+	}
+	if tlsEnabled {
+		w.Transport = &kafkago.Transport{TLS: &tls.Config{MinVersion: tls.VersionTLS12}}
+	}
+	return &Producer{writer: w, log: log}
+}
+
+// Publish sends m to the topic, keyed by its deposit nonce
+func (p *Producer) Publish(m msg.Message) {
+	value, err := message.MarshalFormat(message.FormatJSON, m)
+	if err != nil {
+		publishErrors.Inc()
+		p.log.Error("Unable to encode message for kafka", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+		return
+	}
+	err = p.writer.WriteMessages(context.Background(), kafkago.Message{
+		Key:   []byte(strconv.FormatUint(uint64(m.DepositNonce), 10)),
+		Value: value,
+	})
+	if err != nil {
+		publishErrors.Inc()
+		p.log.Error("Failed to publish message to kafka", "src", m.Source, "dst", m.Destination, "nonce", m.DepositNonce, "err", err)
+	}
+}
+
+// Close sends the messages waiting to be published and closes the connections to the brokers
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
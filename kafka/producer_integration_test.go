@@ -0,0 +1,98 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+// +build integration
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/ChainBridge/router"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// BrokersEnv sets the broker the integration test runs against, such as the one in docker-compose-kafka.yml
+const BrokersEnv = "KAFKA_BROKERS"
+
+const defaultBroker = "localhost:9092"
+
+type resolvingWriter struct{}
+
+func (resolvingWriter) ResolveMessage(msg.Message) bool {
+	return true
+}
+
+func TestProducer_OneMessagePerDeposit(t *testing.T) {
+	broker := os.Getenv(BrokersEnv)
+	if broker == "" {
+		broker = defaultBroker
+	}
+	topic := fmt.Sprintf("chainbridge-test-%d", time.Now().UnixNano())
+
+	// Creates the topic with a single partition, so the messages are read in the order they were published
+	conn, err := kafkago.DialLeader(context.Background(), "tcp", broker, topic, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	log := log15.New("test", "kafka")
+	producer := NewProducer([]string{broker}, topic, false, log)
+	r := router.NewRouter(log)
+	r.Listen(msg.ChainId(2), resolvingWriter{})
+	r.AddSink(producer)
+
+	deposits := 5
+	for i := 1; i <= deposits; i++ {
+		m := msg.NewFungibleTransfer(1, 2, msg.Nonce(i), big.NewInt(int64(i)), msg.ResourceIdFromSlice([]byte{1}), []byte("recipient"))
+		err = r.Send(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Close sends the messages waiting in the batch
+	err = producer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := kafkago.NewReader(kafkago.ReaderConfig{Brokers: []string{broker}, Topic: topic, Partition: 0})
+	defer reader.Close()
+
+	seen := make(map[msg.Nonce]int)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for {
+		km, err := reader.ReadMessage(ctx)
+		if err != nil {
+			break
+		}
+		m, err := message.UnmarshalFormat(message.FormatJSON, km.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(km.Key) != fmt.Sprint(uint64(m.DepositNonce)) {
+			t.Fatalf("expected key %d, got: %s", m.DepositNonce, km.Key)
+		}
+		seen[m.DepositNonce]++
+	}
+
+	for i := 1; i <= deposits; i++ {
+		if seen[msg.Nonce(i)] != 1 {
+			t.Fatalf("expected one message for deposit %d, got: %d", i, seen[msg.Nonce(i)])
+		}
+	}
+	if len(seen) != deposits {
+		t.Fatalf("expected messages for %d deposits, got: %d", deposits, len(seen))
+	}
+}
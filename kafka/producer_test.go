@@ -0,0 +1,68 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+type mockMessageWriter struct {
+	msgs []kafkago.Message
+	err  error
+}
+
+func (w *mockMessageWriter) WriteMessages(_ context.Context, msgs ...kafkago.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *mockMessageWriter) Close() error {
+	return nil
+}
+
+func TestProducer_Publish(t *testing.T) {
+	writer := &mockMessageWriter{}
+	p := &Producer{writer: writer, log: log15.New("test", "kafka")}
+
+	m := msg.NewFungibleTransfer(1, 2, 42, big.NewInt(100), msg.ResourceIdFromSlice([]byte{1}), []byte("recipient"))
+	p.Publish(m)
+
+	if len(writer.msgs) != 1 {
+		t.Fatalf("expected one kafka message, got: %d", len(writer.msgs))
+	}
+	if string(writer.msgs[0].Key) != "42" {
+		t.Fatalf("expected the message to be keyed by nonce, got: %s", writer.msgs[0].Key)
+	}
+	decoded, err := message.UnmarshalFormat(message.FormatJSON, writer.msgs[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, m) {
+		t.Fatalf("expected %+v, got: %+v", m, decoded)
+	}
+}
+
+func TestProducer_PublishError(t *testing.T) {
+	writer := &mockMessageWriter{err: errors.New("broker unavailable")}
+	p := &Producer{writer: writer, log: log15.New("test", "kafka")}
+
+	before := testutil.ToFloat64(publishErrors)
+	p.Publish(msg.Message{Source: 1, Destination: 2, DepositNonce: 1})
+	if got := testutil.ToFloat64(publishErrors) - before; got != 1 {
+		t.Fatalf("expected one publish error, got: %v", got)
+	}
+}
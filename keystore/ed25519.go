@@ -0,0 +1,84 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/ChainBridge/crypto/ed25519"
+	utilskeystore "github.com/ChainSafe/chainbridge-utils/keystore"
+)
+
+// Ed25519Chain is the chain type of chains that sign with ed25519 keys
+const Ed25519Chain = "ed25519"
+
+var ErrNoEd25519TestKeys = errors.New("there are no ed25519 test keys")
+
+// ed25519KeyFile returns the path of the key file for addr, <address>_ed25519.json
+func ed25519KeyFile(path, addr string) string {
+	return filepath.Clean(fmt.Sprintf("%s/%s_ed25519.json", path, addr))
+}
+
+// WriteEd25519Keypair encrypts kp with password and writes it to the keystore at path, returning the file written.
+// The file has the same format as chainbridge-utils key files, with type ed25519.
+func WriteEd25519Keypair(path string, kp *ed25519.Keypair, password []byte) (string, error) {
+	ciphertext, err := utilskeystore.EncryptKeypair(kp, password)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(&utilskeystore.EncryptedKeystore{
+		Type:       ed25519.Ed25519Type,
+		PublicKey:  kp.PublicKey(),
+		Address:    kp.Address(),
+		Ciphertext: ciphertext,
+	}, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	file := ed25519KeyFile(path, kp.Address())
+	return file, ioutil.WriteFile(file, append(data, '\n'), 0600)
+}
+
+// ed25519KeypairFromAddress loads the ed25519 key file for addr in the keystore at path
+func ed25519KeypairFromAddress(addr, path string) (*ed25519.Keypair, error) {
+	file := ed25519KeyFile(path, addr)
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("key file not found: %s", file)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var keydata utilskeystore.EncryptedKeystore
+	err = json.Unmarshal(data, &keydata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", file, err)
+	}
+	if keydata.Type != ed25519.Ed25519Type {
+		return nil, fmt.Errorf("key file %s has type %s, expected %s", file, keydata.Type, ed25519.Ed25519Type)
+	}
+
+	password := []byte(os.Getenv(utilskeystore.EnvPassword))
+	if len(password) == 0 {
+		password = utilskeystore.GetPassword(fmt.Sprintf("Enter password for key %s:", file))
+	}
+	seed, err := utilskeystore.Decrypt(keydata.Ciphertext, password)
+	if err != nil {
+		return nil, err
+	}
+	kp := &ed25519.Keypair{}
+	err = kp.Decode(seed)
+	if err != nil {
+		return nil, err
+	}
+	if kp.PublicKey() != keydata.PublicKey {
+		return nil, fmt.Errorf("unexpected key file data, file may be corrupt or have been tampered with")
+	}
+	return kp, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The keystore package loads relayer keys from key files, extending the chainbridge-utils keystore with ethereum JSON
+keystore v3 files.
+
+Key files in the chainbridge-utils format are loaded by that package. Keystore v3 files, such as those written by
+geth, are loaded as a KeystoreWallet. Their key may be derived with scrypt or pbkdf2, or with Argon2id, which needs
+far less CPU than scrypt on low-power hardware. Keys of the ed25519 chain type are loaded from <address>_ed25519.json
+files, which have the chainbridge-utils format.
+*/
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto"
+	utilskeystore "github.com/ChainSafe/chainbridge-utils/keystore"
+)
+
+const (
+	EthChain = utilskeystore.EthChain
+	SubChain = utilskeystore.SubChain
+)
+
+var ErrUnsupportedChain = errors.New("keystore v3 files only hold ethereum keys")
+
+// KeypairFromAddress loads the key file for addr in the keystore at path, prompting for its password unless it is
+// set by the KEYSTORE_PASSWORD environment variable. Keystore v3 files are decrypted with the key derivation function
+// named in the file. If insecure is set the test key named addr is returned instead.
+func KeypairFromAddress(addr, chainType, path string, insecure bool) (crypto.Keypair, error) {
+	if chainType == Ed25519Chain {
+		if insecure {
+			return nil, ErrNoEd25519TestKeys
+		}
+		return ed25519KeypairFromAddress(addr, path)
+	}
+	if insecure {
+		return utilskeystore.KeypairFromAddress(addr, chainType, path, insecure)
+	}
+
+	file := filepath.Clean(fmt.Sprintf("%s/%s.key", path, addr))
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("key file not found: %s", file)
+	} else if err != nil {
+		return nil, err
+	}
+
+	wallet, ok := ParseKeystoreWallet(data)
+	if !ok {
+		return utilskeystore.KeypairFromAddress(addr, chainType, path, insecure)
+	}
+	if chainType != EthChain {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedChain, file)
+	}
+
+	password := []byte(os.Getenv(utilskeystore.EnvPassword))
+	if len(password) == 0 {
+		password = utilskeystore.GetPassword(fmt.Sprintf("Enter password for key %s:", file))
+	}
+	return wallet.Decrypt(string(password))
+}
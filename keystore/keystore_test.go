@@ -0,0 +1,180 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/crypto/ed25519"
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	utilskeystore "github.com/ChainSafe/chainbridge-utils/keystore"
+	gokeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+const testPassword = "password"
+
+// testArgon2Params keeps the tests fast, they are far weaker than DefaultArgon2Params
+var testArgon2Params = Argon2Params{Time: 1, Memory: 1024, Threads: 1}
+
+func writeKeyFile(t *testing.T, dir, addr string, data []byte) {
+	err := ioutil.WriteFile(filepath.Join(dir, addr+".key"), data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newKeystoreDir(t *testing.T) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Setenv(utilskeystore.EnvPassword, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestKeypairFromAddress_Argon2(t *testing.T) {
+	dir := newKeystoreDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(utilskeystore.EnvPassword)
+
+	kp, err := secp256k1.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet, err := NewArgon2Wallet(kp, testPassword, testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKeyFile(t, dir, kp.Address(), data)
+
+	loaded, err := KeypairFromAddress(kp.Address(), EthChain, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Address() != kp.Address() {
+		t.Fatalf("expected address %s, got: %s", kp.Address(), loaded.Address())
+	}
+
+	parsed, ok := ParseKeystoreWallet(data)
+	if !ok {
+		t.Fatal("expected the file to be parsed as a keystore v3 wallet")
+	}
+	_, err = parsed.Decrypt("wrong")
+	if !errors.Is(err, gokeystore.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got: %v", err)
+	}
+	_, err = KeypairFromAddress(kp.Address(), SubChain, dir, false)
+	if !errors.Is(err, ErrUnsupportedChain) {
+		t.Fatalf("expected ErrUnsupportedChain, got: %v", err)
+	}
+}
+
+func TestKeypairFromAddress_Scrypt(t *testing.T) {
+	dir := newKeystoreDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(utilskeystore.EnvPassword)
+
+	kp, err := secp256k1.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &gokeystore.Key{Address: kp.CommonAddress(), PrivateKey: kp.PrivateKey()}
+	data, err := gokeystore.EncryptKey(key, testPassword, gokeystore.LightScryptN, gokeystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKeyFile(t, dir, kp.Address(), data)
+
+	loaded, err := KeypairFromAddress(kp.Address(), EthChain, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Address() != kp.Address() {
+		t.Fatalf("expected address %s, got: %s", kp.Address(), loaded.Address())
+	}
+}
+
+func TestKeypairFromAddress_Chainbridge(t *testing.T) {
+	dir := newKeystoreDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(utilskeystore.EnvPassword)
+
+	kp, err := secp256k1.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(dir, kp.Address()+".key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = utilskeystore.EncryptAndWriteToFile(file, kp, []byte(testPassword))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := KeypairFromAddress(kp.Address(), EthChain, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Address() != kp.Address() {
+		t.Fatalf("expected address %s, got: %s", kp.Address(), loaded.Address())
+	}
+
+	_, err = KeypairFromAddress("0x0000000000000000000000000000000000000001", EthChain, dir, false)
+	if err == nil {
+		t.Fatal("expected an error for a missing key file")
+	}
+}
+
+func TestKeypairFromAddress_Ed25519(t *testing.T) {
+	dir := newKeystoreDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(utilskeystore.EnvPassword)
+
+	kp, err := ed25519.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := WriteEd25519Keypair(dir, kp, []byte(testPassword))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(file) != kp.Address()+"_ed25519.json" {
+		t.Fatalf("unexpected key file %s", file)
+	}
+
+	loaded, err := KeypairFromAddress(kp.Address(), Ed25519Chain, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.PublicKey() != kp.PublicKey() {
+		t.Fatalf("expected public key %s, got: %s", kp.PublicKey(), loaded.PublicKey())
+	}
+	if _, ok := loaded.(*ed25519.Keypair); !ok {
+		t.Fatalf("expected an ed25519 keypair, got: %T", loaded)
+	}
+
+	_, err = KeypairFromAddress(kp.Address(), EthChain, dir, false)
+	if err == nil {
+		t.Fatal("expected an error loading an ed25519 key as an ethereum key")
+	}
+
+	_, err = KeypairFromAddress("alice", Ed25519Chain, dir, true)
+	if !errors.Is(err, ErrNoEd25519TestKeys) {
+		t.Fatalf("expected ErrNoEd25519TestKeys, got: %v", err)
+	}
+}
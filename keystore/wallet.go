@@ -0,0 +1,211 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	gokeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/argon2"
+)
+
+// KDFArgon2 is the kdf of keystore v3 files whose key is derived with Argon2id
+const KDFArgon2 = "argon2"
+
+const argon2KeyLen = 32
+
+// Argon2Params configures Argon2id key derivation
+type Argon2Params struct {
+	Time    uint32 // Number of passes over the memory
+	Memory  uint32 // Memory used, in KiB
+	Threads uint8
+}
+
+// DefaultArgon2Params is the second recommended option of RFC 9106, which uses 64 MiB of memory
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+// KeystoreWallet is a secp256k1 key in a JSON keystore v3 file. Keys derived with scrypt or pbkdf2 are decrypted
+// by go-ethereum, keys derived with Argon2id are decrypted here.
+type KeystoreWallet struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	Id      string     `json:"id,omitempty"`
+	Version int        `json:"version"`
+	raw     []byte     // The file the wallet was parsed from, nil if it was created
+}
+
+// ParseKeystoreWallet parses data as a keystore v3 file, returning false if it is not one
+func ParseKeystoreWallet(data []byte) (*KeystoreWallet, bool) {
+	w := &KeystoreWallet{}
+	err := json.Unmarshal(data, w)
+	if err != nil || w.Version != 3 || w.Crypto.KDF == "" {
+		return nil, false
+	}
+	w.raw = data
+	return w, true
+}
+
+// NewArgon2Wallet encrypts kp with a key derived from password with Argon2id
+func NewArgon2Wallet(kp *secp256k1.Keypair, password string, params Argon2Params) (*KeystoreWallet, error) {
+	salt := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	_, err = io.ReadFull(rand.Reader, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	derived := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, argon2KeyLen)
+	ciphertext, err := aesCTRXOR(derived[:16], ethcrypto.FromECDSA(kp.PrivateKey()), iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeystoreWallet{
+		Address: hex.EncodeToString(kp.CommonAddress().Bytes()),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          KDFArgon2,
+			// Numbers are held as float64, as they are when the wallet is parsed
+			KDFParams: map[string]interface{}{
+				"dklen":   float64(argon2KeyLen),
+				"salt":    hex.EncodeToString(salt),
+				"time":    float64(params.Time),
+				"memory":  float64(params.Memory),
+				"threads": float64(params.Threads),
+			},
+			MAC: hex.EncodeToString(ethcrypto.Keccak256(derived[16:32], ciphertext)),
+		},
+		Version: 3,
+	}, nil
+}
+
+// Decrypt returns the wallet's key, decrypted with password. An error is returned if the key does not match the
+// wallet's address.
+func (w *KeystoreWallet) Decrypt(password string) (*secp256k1.Keypair, error) {
+	var priv []byte
+	var err error
+	if w.Crypto.KDF == KDFArgon2 {
+		priv, err = w.decryptArgon2(password)
+	} else {
+		priv, err = w.decryptV3(password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kp, err := secp256k1.NewKeypairFromPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if w.Address != "" && common.HexToAddress(w.Address) != kp.CommonAddress() {
+		return nil, fmt.Errorf("key does not match the keystore address %s, the file may be corrupt", w.Address)
+	}
+	return kp, nil
+}
+
+// decryptV3 decrypts the private key with go-ethereum, which supports the scrypt and pbkdf2 kdfs
+func (w *KeystoreWallet) decryptV3(password string) ([]byte, error) {
+	raw := w.raw
+	if raw == nil {
+		var err error
+		raw, err = json.Marshal(w)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := gokeystore.DecryptKey(raw, password)
+	if err != nil {
+		return nil, err
+	}
+	return ethcrypto.FromECDSA(key.PrivateKey), nil
+}
+
+// decryptArgon2 derives the key with Argon2id, checks the MAC and decrypts the private key
+func (w *KeystoreWallet) decryptArgon2(password string) ([]byte, error) {
+	if w.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("cipher not supported: %s", w.Crypto.Cipher)
+	}
+	mac, err := hex.DecodeString(w.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(w.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv length %d", len(iv))
+	}
+	ciphertext, err := hex.DecodeString(w.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	salt, ok := w.Crypto.KDFParams["salt"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid argon2 param salt")
+	}
+	saltBytes, err := hex.DecodeString(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]uint32)
+	for _, name := range []string{"dklen", "time", "memory", "threads"} {
+		v, ok := w.Crypto.KDFParams[name].(float64)
+		if !ok || v < 1 || v > math.MaxUint32 || v != math.Trunc(v) {
+			return nil, fmt.Errorf("invalid argon2 param %s", name)
+		}
+		params[name] = uint32(v)
+	}
+	if params["dklen"] < argon2KeyLen || params["threads"] > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid argon2 params")
+	}
+
+	derived := argon2.IDKey([]byte(password), saltBytes, params["time"], params["memory"], uint8(params["threads"]), params["dklen"])
+	if !bytes.Equal(ethcrypto.Keccak256(derived[16:32], ciphertext), mac) {
+		return nil, gokeystore.ErrDecrypt
+	}
+	return aesCTRXOR(derived[:16], ciphertext, iv)
+}
+
+// aesCTRXOR encrypts or decrypts in with AES-128 in counter mode
+func aesCTRXOR(key, in, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package lock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileLocker holds each lock as a file in dir, created exclusively so only one process can acquire it
+type FileLocker struct {
+	dir   string
+	ttl   time.Duration
+	token string // Written to lock files held by this relayer
+}
+
+func NewFileLocker(dir string, ttl time.Duration) (*FileLocker, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	return &FileLocker{dir: dir, ttl: ttl, token: token}, nil
+}
+
+func (l *FileLocker) path(key Key) string {
+	return filepath.Join(l.dir, key.String()+".lock")
+}
+
+// TryLock creates the lock file for key. A lock file older than the TTL is taken over.
+func (l *FileLocker) TryLock(key Key) (bool, error) {
+	acquired, err := l.create(key)
+	if err != nil || acquired {
+		return acquired, err
+	}
+
+	info, err := os.Stat(l.path(key))
+	if os.IsNotExist(err) {
+		// Released since we tried to create it
+		return l.create(key)
+	} else if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) < l.ttl {
+		return false, nil
+	}
+	return l.takeOver(key)
+}
+
+// takeOver removes the stale lock file and creates the lock again. The file is first renamed to a name unique to
+// this relayer, so only one of the relayers that found it stale removes it, and the lock file is created exclusively
+// so only one of them acquires the lock. A lock file created since it was found stale is put back.
+func (l *FileLocker) takeOver(key Key) (bool, error) {
+	moved := l.path(key) + "." + l.token
+	err := os.Rename(l.path(key), moved)
+	if os.IsNotExist(err) {
+		// Taken over or released by another relayer
+		return l.create(key)
+	} else if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(moved)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) < l.ttl {
+		// Linking fails rather than replacing a lock file created since it was moved
+		err = os.Link(moved, l.path(key))
+		if err != nil && !os.IsExist(err) {
+			return false, err
+		}
+		return false, os.Remove(moved)
+	}
+
+	err = os.Remove(moved)
+	if err != nil {
+		return false, err
+	}
+	return l.create(key)
+}
+
+func (l *FileLocker) create(key Key) (bool, error) {
+	f, err := os.OpenFile(l.path(key), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	_, err = f.WriteString(l.token)
+	if err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// Unlock removes the lock file for key if it is still held by this relayer
+func (l *FileLocker) Unlock(key Key) error {
+	token, err := ioutil.ReadFile(l.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if string(token) != l.token {
+		return nil
+	}
+	return os.Remove(l.path(key))
+}
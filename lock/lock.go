@@ -0,0 +1,111 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The lock package prevents multiple relayers from submitting the same proposal execution at once.
+
+Before executing a proposal a writer acquires the lock for that proposal and releases it once the transaction
+has been mined. A relayer that fails to acquire the lock leaves execution to the current holder. Locks expire
+after a TTL so a relayer that crashes while holding one does not block execution indefinitely.
+
+The file backend only coordinates relayers sharing a filesystem, the redis backend coordinates any relayers
+with access to the same redis server.
+*/
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// Supported lock backends
+const (
+	NoneBackend  = "none"
+	FileBackend  = "file"
+	RedisBackend = "redis"
+)
+
+// DefaultTTL is how long a lock is held before it is considered stale
+const DefaultTTL = 5 * time.Minute
+
+// DefaultRedisAddr is used by the redis backend when no address is configured
+const DefaultRedisAddr = "localhost:6379"
+
+// DefaultFilePath is used by the file backend when no directory is configured
+var DefaultFilePath = filepath.Join(os.TempDir(), "chainbridge-locks")
+
+// Key identifies a proposal. The source chain is included as deposit nonces are only unique per source and destination.
+type Key struct {
+	Source      msg.ChainId
+	Destination msg.ChainId
+	Nonce       msg.Nonce
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d-%d-%d", k.Source, k.Destination, k.Nonce)
+}
+
+// Locker guards the execution of a proposal
+type Locker interface {
+	// TryLock acquires the lock for key, returning false if it is already held
+	TryLock(key Key) (bool, error)
+	// Unlock releases a lock acquired with TryLock
+	Unlock(key Key) error
+}
+
+var _ Locker = &NoopLocker{}
+var _ Locker = &FileLocker{}
+var _ Locker = &RedisLocker{}
+
+// NewLocker returns the Locker for backend. path is the lock directory for the file backend
+// and url is the address of the server for the redis backend, the defaults are used if they are empty.
+// redisOpts are only used by the redis backend.
+func NewLocker(backend, path, url string, redisOpts RedisOptions, ttl time.Duration) (Locker, error) {
+	switch backend {
+	case NoneBackend, "":
+		return NewNoopLocker(), nil
+	case FileBackend:
+		if path == "" {
+			path = DefaultFilePath
+		}
+		return NewFileLocker(path, ttl)
+	case RedisBackend:
+		if url == "" {
+			url = DefaultRedisAddr
+		}
+		return NewRedisLocker(url, redisOpts, ttl)
+	default:
+		return nil, fmt.Errorf("unknown lock backend: %s", backend)
+	}
+}
+
+// newToken returns a random value identifying the locks held by a single Locker
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// NoopLocker always grants the lock, leaving duplicate executions to be rejected on-chain
+type NoopLocker struct{}
+
+func NewNoopLocker() *NoopLocker {
+	return &NoopLocker{}
+}
+
+func (l *NoopLocker) TryLock(key Key) (bool, error) {
+	return true, nil
+}
+
+func (l *NoopLocker) Unlock(key Key) error {
+	return nil
+}
@@ -0,0 +1,412 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package lock
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// simulateWriters runs concurrent writers that each try to execute the same proposal, returning how many submitted
+func simulateWriters(t *testing.T, lockers []Locker) int {
+	key := Key{Source: 1, Destination: 2, Nonce: 3}
+	var submitted []Locker
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for _, l := range lockers {
+		wg.Add(1)
+		go func(l Locker) {
+			defer wg.Done()
+			<-start
+			acquired, err := l.TryLock(key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !acquired {
+				return
+			}
+			mu.Lock()
+			submitted = append(submitted, l)
+			mu.Unlock()
+			// Hold the lock while the transaction is "mined"
+			time.Sleep(100 * time.Millisecond)
+		}(l)
+	}
+	close(start)
+	wg.Wait()
+
+	for _, l := range submitted {
+		if err := l.Unlock(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return len(submitted)
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFileLocker_ConcurrentWriters(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// Each writer has its own locker, as separate relayer processes would
+	var lockers []Locker
+	for i := 0; i < 2; i++ {
+		l, err := NewLocker(FileBackend, dir, "", RedisOptions{}, DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lockers = append(lockers, l)
+	}
+
+	if n := simulateWriters(t, lockers); n != 1 {
+		t.Fatalf("expected one writer to submit, got: %d", n)
+	}
+
+	// Released after mining, so the lock can be acquired again
+	acquired, err := lockers[0].TryLock(Key{Source: 1, Destination: 2, Nonce: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acquired {
+		t.Fatal("expected lock to be released")
+	}
+}
+
+func TestFileLocker_Stale(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := NewFileLocker(dir, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{Source: 1, Destination: 2, Nonce: 3}
+
+	acquired, err := l.TryLock(key)
+	if err != nil || !acquired {
+		t.Fatalf("expected to acquire lock, err: %v", err)
+	}
+
+	other, err := NewFileLocker(dir, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	acquired, err = other.TryLock(key)
+	if err != nil || !acquired {
+		t.Fatalf("expected stale lock to be taken over, err: %v", err)
+	}
+
+	// The original holder must not release the lock it lost
+	err = l.Unlock(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(l.path(key)); err != nil {
+		t.Fatalf("lock held by another relayer was removed: %v", err)
+	}
+}
+
+func TestFileLocker_StaleTakenOver(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	key := Key{Source: 1, Destination: 2, Nonce: 3}
+
+	a, err := NewFileLocker(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewFileLocker(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Left behind by a relayer that crashed while holding the lock
+	err = ioutil.WriteFile(a.path(key), []byte("crashed"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(a.path(key), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both relayers find the lock stale, but b takes it over first
+	acquired, err := b.TryLock(key)
+	if err != nil || !acquired {
+		t.Fatalf("expected to take over the stale lock, err: %v", err)
+	}
+
+	acquired, err = a.takeOver(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acquired {
+		t.Fatal("expected the lock taken over by another relayer not to be acquired")
+	}
+	token, err := ioutil.ReadFile(a.path(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(token) != b.token {
+		t.Fatal("expected the lock file of the relayer that took over the lock to be kept")
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected only the lock file to remain, got %d files", len(files))
+	}
+}
+
+func TestNoopLocker(t *testing.T) {
+	if n := simulateWriters(t, []Locker{NewNoopLocker(), NewNoopLocker()}); n != 2 {
+		t.Fatalf("expected both writers to submit, got: %d", n)
+	}
+}
+
+func TestNewLocker_Unknown(t *testing.T) {
+	_, err := NewLocker("zookeeper", "", "", RedisOptions{}, DefaultTTL)
+	if err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+// fakeRedis implements the AUTH, SET NX and EVAL unlock commands used by RedisLocker
+type fakeRedis struct {
+	password string
+	keys     map[string]string
+	conns    []net.Conn // Every connection accepted
+	lock     sync.Mutex
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeRedis{password: password, keys: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.lock.Lock()
+			s.conns = append(s.conns, conn)
+			s.lock.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s, l.Addr().String(), func() { l.Close() }
+}
+
+// accepted returns the number of connections accepted
+func (s *fakeRedis) accepted() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.conns)
+}
+
+// dropConns closes every open connection, as a server closing idle clients would
+func (s *fakeRedis) dropConns() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authenticated := s.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		switch {
+		case args[0] == "AUTH":
+			if args[1] != s.password {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				break
+			}
+			authenticated = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case !authenticated:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "SET":
+			if _, ok := s.keys[args[1]]; ok {
+				fmt.Fprint(conn, "$-1\r\n")
+				break
+			}
+			s.keys[args[1]] = args[2]
+			fmt.Fprint(conn, "+OK\r\n")
+		case args[0] == "EVAL":
+			if s.keys[args[3]] == args[4] {
+				delete(s.keys, args[3])
+				fmt.Fprint(conn, ":1\r\n")
+				break
+			}
+			fmt.Fprint(conn, ":0\r\n")
+		default:
+			fmt.Fprint(conn, "-ERR unknown command\r\n")
+		}
+		s.lock.Unlock()
+	}
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisLocker_ConcurrentWriters(t *testing.T) {
+	_, addr, stop := startFakeRedis(t, "")
+	defer stop()
+
+	var lockers []Locker
+	for i := 0; i < 2; i++ {
+		l, err := NewLocker(RedisBackend, "", "redis://"+addr, RedisOptions{}, DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lockers = append(lockers, l)
+	}
+
+	if n := simulateWriters(t, lockers); n != 1 {
+		t.Fatalf("expected one writer to submit, got: %d", n)
+	}
+
+	acquired, err := lockers[1].TryLock(Key{Source: 1, Destination: 2, Nonce: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acquired {
+		t.Fatal("expected lock to be released")
+	}
+}
+
+func TestRedisLocker_Unavailable(t *testing.T) {
+	l, err := NewRedisLocker("127.0.0.1:1", RedisOptions{}, DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.TryLock(Key{})
+	if err == nil {
+		t.Fatal("expected error when redis is unavailable")
+	}
+}
+
+func TestRedisLocker_ReusesConnection(t *testing.T) {
+	server, addr, stop := startFakeRedis(t, "")
+	defer stop()
+
+	l, err := NewRedisLocker(addr, RedisOptions{}, DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		key := Key{Source: 1, Destination: 2, Nonce: msg.Nonce(i)}
+		acquired, err := l.TryLock(key)
+		if err != nil || !acquired {
+			t.Fatalf("expected to acquire lock, err: %v", err)
+		}
+		if err = l.Unlock(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := server.accepted(); n != 1 {
+		t.Fatalf("expected one connection, got: %d", n)
+	}
+
+	// A connection closed by the server is replaced without failing the command
+	server.dropConns()
+	acquired, err := l.TryLock(Key{Source: 1, Destination: 2, Nonce: 3})
+	if err != nil || !acquired {
+		t.Fatalf("expected to acquire lock after reconnecting, err: %v", err)
+	}
+	if n := server.accepted(); n != 2 {
+		t.Fatalf("expected two connections, got: %d", n)
+	}
+}
+
+func TestRedisLocker_Auth(t *testing.T) {
+	_, addr, stop := startFakeRedis(t, "secret")
+	defer stop()
+	key := Key{Source: 1, Destination: 2, Nonce: 3}
+
+	for _, password := range []string{"", "wrong"} {
+		l, err := NewRedisLocker(addr, RedisOptions{Password: password}, DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.TryLock(key); err == nil {
+			t.Fatalf("expected error with password %q", password)
+		}
+	}
+
+	l, err := NewRedisLocker(addr, RedisOptions{Password: "secret"}, DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired, err := l.TryLock(key)
+	if err != nil || !acquired {
+		t.Fatalf("expected to acquire lock, err: %v", err)
+	}
+}
+
+func TestNewRedisLocker_TLS(t *testing.T) {
+	l, err := NewRedisLocker("rediss://localhost:6380", RedisOptions{}, DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.opts.TLS || l.addr != "localhost:6380" {
+		t.Fatalf("unexpected redis config, tls: %t addr: %s", l.opts.TLS, l.addr)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package lock
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redisKeyPrefix = "chainbridge:lock:"
+
+var redisDialTimeout = 5 * time.Second
+
+// unlockScript only deletes the key if it still holds our token, so an expired lock taken over by another relayer is left alone
+const unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// RedisOptions configures the connection to the redis server
+type RedisOptions struct {
+	Password string // Sent with AUTH after connecting, if set
+	TLS      bool   // Connect with TLS, also enabled by a rediss:// url
+}
+
+// RedisLocker holds each lock as a redis key set with NX and an expiry of the TTL
+type RedisLocker struct {
+	addr  string
+	opts  RedisOptions
+	ttl   time.Duration
+	token string // Identifies locks held by this relayer
+
+	conn     net.Conn // Reused for each command, nil until the first command or after a failure
+	reader   *bufio.Reader
+	connLock sync.Mutex
+}
+
+// NewRedisLocker returns a RedisLocker for the server at url, either host:port, redis://host:port or rediss://host:port
+func NewRedisLocker(url string, opts RedisOptions, ttl time.Duration) (*RedisLocker, error) {
+	if strings.HasPrefix(url, "rediss://") {
+		opts.TLS = true
+	}
+	addr := strings.TrimPrefix(strings.TrimPrefix(url, "rediss://"), "redis://")
+	if addr == "" {
+		return nil, errors.New("redis lock backend requires a server address")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisLocker{addr: addr, opts: opts, ttl: ttl, token: token}, nil
+}
+
+// TryLock sets the key for the lock if it does not already exist
+func (l *RedisLocker) TryLock(key Key) (bool, error) {
+	reply, err := l.do("SET", redisKeyPrefix+key.String(), l.token, "NX", "PX", strconv.FormatInt(l.ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	// A nil reply means the key is already set
+	return reply != nil, nil
+}
+
+// Unlock deletes the key for the lock if it is still held by this relayer
+func (l *RedisLocker) Unlock(key Key) error {
+	_, err := l.do("EVAL", unlockScript, "1", redisKeyPrefix+key.String(), l.token)
+	return err
+}
+
+// do sends a command and returns the reply. The connection is opened on first use and dropped after a
+// network error. A command that fails on a reused connection is retried once on a new one, as the server
+// may have closed it while idle.
+func (l *RedisLocker) do(args ...string) (interface{}, error) {
+	l.connLock.Lock()
+	defer l.connLock.Unlock()
+
+	reused := l.conn != nil
+	reply, err := l.send(args...)
+	if err != nil && reused && !isRedisError(err) {
+		reply, err = l.send(args...)
+	}
+	return reply, err
+}
+
+// send writes a command on the connection, connecting first if needed
+func (l *RedisLocker) send(args ...string) (interface{}, error) {
+	if l.conn == nil {
+		err := l.connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := l.roundTrip(args...)
+	if err != nil && !isRedisError(err) {
+		// The connection may be left part way through a reply
+		l.disconnect()
+	}
+	return reply, err
+}
+
+// connect dials the server and authenticates if a password is set
+func (l *RedisLocker) connect() error {
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var conn net.Conn
+	var err error
+	if l.opts.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", l.addr, &tls.Config{})
+	} else {
+		conn, err = dialer.Dial("tcp", l.addr)
+	}
+	if err != nil {
+		return err
+	}
+	l.conn = conn
+	l.reader = bufio.NewReader(conn)
+
+	if l.opts.Password != "" {
+		_, err = l.roundTrip("AUTH", l.opts.Password)
+		if err != nil {
+			l.disconnect()
+			return fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l *RedisLocker) disconnect() {
+	l.conn.Close()
+	l.conn = nil
+	l.reader = nil
+}
+
+// roundTrip writes a single command and reads its reply
+func (l *RedisLocker) roundTrip(args ...string) (interface{}, error) {
+	err := l.conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err = io.WriteString(l.conn, cmd.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return readReply(l.reader)
+}
+
+// redisError is an error reply from the server, after which the connection can still be used
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func isRedisError(err error) bool {
+	var e redisError
+	return errors.As(err, &e)
+}
+
+// readReply parses a simple string, error, integer or bulk string RESP reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply: %q", line)
+	}
+}
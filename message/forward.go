@@ -0,0 +1,60 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// forwardMagic prefixes the metadata of generic messages sent via another chain. It is followed by the chain the
+// message is forwarded to, then the original metadata.
+var forwardMagic = []byte{0xf0, 'f', 'w', 'd'}
+
+var ErrNotForwardable = errors.New("only generic transfers can be forwarded")
+
+// Forward returns m addressed to chain via, with forwardTo set to m's destination in its metadata. The writer of
+// chain via deposits it again to the destination.
+func Forward(m msg.Message, via msg.ChainId) (msg.Message, error) {
+	if m.Type != msg.GenericTransfer || len(m.Payload) != 1 {
+		return msg.Message{}, fmt.Errorf("%w: %s", ErrNotForwardable, m.Type)
+	}
+	metadata, ok := m.Payload[0].([]byte)
+	if !ok {
+		return msg.Message{}, fmt.Errorf("%w: metadata is %T", ErrUnsupportedPayload, m.Payload[0])
+	}
+	if _, forwarded := Unforward(m); forwarded {
+		return msg.Message{}, fmt.Errorf("%w: message is already forwarded", ErrNotForwardable)
+	}
+
+	data := make([]byte, 0, len(forwardMagic)+1+len(metadata))
+	data = append(data, forwardMagic...)
+	data = append(data, byte(m.Destination))
+	data = append(data, metadata...)
+
+	forwarded := m
+	forwarded.Destination = via
+	forwarded.Payload = []interface{}{data}
+	return forwarded, nil
+}
+
+// Unforward returns the message m was created from by Forward, which is addressed to the forwardTo chain. False is
+// returned if m is not forwarded.
+func Unforward(m msg.Message) (msg.Message, bool) {
+	if m.Type != msg.GenericTransfer || len(m.Payload) != 1 {
+		return m, false
+	}
+	data, ok := m.Payload[0].([]byte)
+	if !ok || len(data) <= len(forwardMagic) || !bytes.HasPrefix(data, forwardMagic) {
+		return m, false
+	}
+
+	original := m
+	original.Destination = msg.ChainId(data[len(forwardMagic)])
+	original.Payload = []interface{}{data[len(forwardMagic)+1:]}
+	return original, true
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestForward(t *testing.T) {
+	m := msg.NewGenericTransfer(0, 2, 1, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	if _, ok := Unforward(m); ok {
+		t.Fatal("expected a message that is not forwarded")
+	}
+
+	forwarded, err := Forward(m, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forwarded.Destination != 1 {
+		t.Fatalf("expected destination 1, got: %d", forwarded.Destination)
+	}
+	original, ok := Unforward(forwarded)
+	if !ok {
+		t.Fatal("expected a forwarded message")
+	}
+	if !reflect.DeepEqual(original, m) {
+		t.Fatalf("expected %+v, got: %+v", m, original)
+	}
+
+	_, err = Forward(forwarded, 3)
+	if !errors.Is(err, ErrNotForwardable) {
+		t.Fatalf("expected ErrNotForwardable for a forwarded message, got: %v", err)
+	}
+	_, err = Forward(msg.NewFungibleTransfer(0, 2, 1, big.NewInt(1), m.ResourceId, []byte{1}), 1)
+	if !errors.Is(err, ErrNotForwardable) {
+		t.Fatalf("expected ErrNotForwardable for a fungible transfer, got: %v", err)
+	}
+
+	// The signature of the message before it was forwarded is valid for the forwarded message
+	kp := keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+	signed, err := Sign(m, kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.Message = forwarded
+	signer, err := signed.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != kp.CommonAddress() {
+		t.Fatalf("expected signer %s, got: %s", kp.CommonAddress().Hex(), signer.Hex())
+	}
+}
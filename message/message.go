@@ -0,0 +1,161 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+Package message defines the canonical serialization of msg.Message.
+
+Messages are encoded with protocol buffers, using the schema in message.proto. JSON, in the protobuf JSON
+mapping of the same schema, is supported as a secondary format.
+
+To regenerate message.pb.go after changing the schema, run `make proto`.
+*/
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Format selects how messages are serialized
+type Format string
+
+const (
+	FormatProtobuf Format = "protobuf"
+	FormatJSON     Format = "json"
+)
+
+// DefaultFormat is the format used unless another is configured
+const DefaultFormat = FormatProtobuf
+
+var ErrUnknownFormat = errors.New("unknown message format")
+var ErrUnknownTransferType = errors.New("unknown transfer type")
+var ErrUnsupportedPayload = errors.New("unsupported payload item, must be []byte")
+
+var transferTypes = map[msg.TransferType]TransferType{
+	msg.FungibleTransfer:    TransferType_FUNGIBLE,
+	msg.NonFungibleTransfer: TransferType_NON_FUNGIBLE,
+	msg.GenericTransfer:     TransferType_GENERIC,
+}
+
+// ParseFormat returns the Format named by s
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatProtobuf, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, s)
+	}
+}
+
+// Marshal encodes m with protocol buffers
+func Marshal(m msg.Message) ([]byte, error) {
+	pb, err := toProto(m)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pb)
+}
+
+// Unmarshal decodes a message encoded by Marshal
+func Unmarshal(b []byte) (msg.Message, error) {
+	var pb Message
+	err := proto.Unmarshal(b, &pb)
+	if err != nil {
+		return msg.Message{}, err
+	}
+	return fromProto(&pb)
+}
+
+// MarshalFormat encodes m in the given format
+func MarshalFormat(f Format, m msg.Message) ([]byte, error) {
+	switch f {
+	case FormatProtobuf:
+		return Marshal(m)
+	case FormatJSON:
+		pb, err := toProto(m)
+		if err != nil {
+			return nil, err
+		}
+		return protojson.Marshal(pb)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+	}
+}
+
+// UnmarshalFormat decodes a message encoded by MarshalFormat in the same format
+func UnmarshalFormat(f Format, b []byte) (msg.Message, error) {
+	switch f {
+	case FormatProtobuf:
+		return Unmarshal(b)
+	case FormatJSON:
+		var pb Message
+		err := protojson.Unmarshal(b, &pb)
+		if err != nil {
+			return msg.Message{}, err
+		}
+		return fromProto(&pb)
+	default:
+		return msg.Message{}, fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+	}
+}
+
+func toProto(m msg.Message) (*Message, error) {
+	transferType, ok := transferTypes[m.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransferType, m.Type)
+	}
+
+	payload := make([][]byte, len(m.Payload))
+	for i, item := range m.Payload {
+		b, ok := item.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d is %T", ErrUnsupportedPayload, i, item)
+		}
+		payload[i] = b
+	}
+
+	return &Message{
+		Source:       uint32(m.Source),
+		Destination:  uint32(m.Destination),
+		Type:         transferType,
+		DepositNonce: uint64(m.DepositNonce),
+		ResourceId:   m.ResourceId[:],
+		Payload:      payload,
+	}, nil
+}
+
+func fromProto(pb *Message) (msg.Message, error) {
+	var transferType msg.TransferType
+	for t, pbType := range transferTypes {
+		if pbType == pb.Type {
+			transferType = t
+		}
+	}
+	if transferType == "" {
+		return msg.Message{}, fmt.Errorf("%w: %s", ErrUnknownTransferType, pb.Type)
+	}
+	if pb.Source > 255 || pb.Destination > 255 {
+		return msg.Message{}, fmt.Errorf("chain ID out of range: source %d, destination %d", pb.Source, pb.Destination)
+	}
+	if len(pb.ResourceId) != len(msg.ResourceId{}) {
+		return msg.Message{}, fmt.Errorf("invalid resource ID length %d", len(pb.ResourceId))
+	}
+
+	payload := make([]interface{}, len(pb.Payload))
+	for i, item := range pb.Payload {
+		payload[i] = item
+	}
+
+	return msg.Message{
+		Source:       msg.ChainId(pb.Source),
+		Destination:  msg.ChainId(pb.Destination),
+		Type:         transferType,
+		DepositNonce: msg.Nonce(pb.DepositNonce),
+		ResourceId:   msg.ResourceIdFromSlice(pb.ResourceId),
+		Payload:      payload,
+	}, nil
+}
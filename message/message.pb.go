@@ -0,0 +1,261 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: message/message.proto
+
+package message
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TransferType is the type of bridge transfer a Message carries
+type TransferType int32
+
+const (
+	TransferType_TRANSFER_TYPE_UNSPECIFIED TransferType = 0
+	TransferType_FUNGIBLE                  TransferType = 1
+	TransferType_NON_FUNGIBLE              TransferType = 2
+	TransferType_GENERIC                   TransferType = 3
+)
+
+// Enum value maps for TransferType.
+var (
+	TransferType_name = map[int32]string{
+		0: "TRANSFER_TYPE_UNSPECIFIED",
+		1: "FUNGIBLE",
+		2: "NON_FUNGIBLE",
+		3: "GENERIC",
+	}
+	TransferType_value = map[string]int32{
+		"TRANSFER_TYPE_UNSPECIFIED": 0,
+		"FUNGIBLE":                  1,
+		"NON_FUNGIBLE":              2,
+		"GENERIC":                   3,
+	}
+)
+
+func (x TransferType) Enum() *TransferType {
+	p := new(TransferType)
+	*p = x
+	return p
+}
+
+func (x TransferType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransferType) Descriptor() protoreflect.EnumDescriptor {
+	return file_message_message_proto_enumTypes[0].Descriptor()
+}
+
+func (TransferType) Type() protoreflect.EnumType {
+	return &file_message_message_proto_enumTypes[0]
+}
+
+func (x TransferType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransferType.Descriptor instead.
+func (TransferType) EnumDescriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{0}
+}
+
+// Message is the serialized form of a msg.Message
+type Message struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source       uint32       `protobuf:"varint,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination  uint32       `protobuf:"varint,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Type         TransferType `protobuf:"varint,3,opt,name=type,proto3,enum=chainbridge.message.TransferType" json:"type,omitempty"`
+	DepositNonce uint64       `protobuf:"varint,4,opt,name=deposit_nonce,json=depositNonce,proto3" json:"deposit_nonce,omitempty"`
+	ResourceId   []byte       `protobuf:"bytes,5,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	// Every payload item is a byte slice, in the order set by the msg constructor for the transfer type
+	Payload [][]byte `protobuf:"bytes,6,rep,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_message_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetSource() uint32 {
+	if x != nil {
+		return x.Source
+	}
+	return 0
+}
+
+func (x *Message) GetDestination() uint32 {
+	if x != nil {
+		return x.Destination
+	}
+	return 0
+}
+
+func (x *Message) GetType() TransferType {
+	if x != nil {
+		return x.Type
+	}
+	return TransferType_TRANSFER_TYPE_UNSPECIFIED
+}
+
+func (x *Message) GetDepositNonce() uint64 {
+	if x != nil {
+		return x.DepositNonce
+	}
+	return 0
+}
+
+func (x *Message) GetResourceId() []byte {
+	if x != nil {
+		return x.ResourceId
+	}
+	return nil
+}
+
+func (x *Message) GetPayload() [][]byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+var File_message_message_proto protoreflect.FileDescriptor
+
+var file_message_message_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x13, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x62, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xda, 0x01, 0x0a,
+	0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x21, 0x2e, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0c, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x1f,
+	0x0a, 0x0b, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x2a, 0x5a, 0x0a, 0x0c, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x19, 0x54, 0x52, 0x41,
+	0x4e, 0x53, 0x46, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x46, 0x55, 0x4e, 0x47,
+	0x49, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x4f, 0x4e, 0x5f, 0x46, 0x55,
+	0x4e, 0x47, 0x49, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x47, 0x45, 0x4e, 0x45,
+	0x52, 0x49, 0x43, 0x10, 0x03, 0x42, 0x2a, 0x5a, 0x28, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x53, 0x61, 0x66, 0x65, 0x2f, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_message_message_proto_rawDescOnce sync.Once
+	file_message_message_proto_rawDescData = file_message_message_proto_rawDesc
+)
+
+func file_message_message_proto_rawDescGZIP() []byte {
+	file_message_message_proto_rawDescOnce.Do(func() {
+		file_message_message_proto_rawDescData = protoimpl.X.CompressGZIP(file_message_message_proto_rawDescData)
+	})
+	return file_message_message_proto_rawDescData
+}
+
+var file_message_message_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_message_message_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_message_message_proto_goTypes = []interface{}{
+	(TransferType)(0), // 0: chainbridge.message.TransferType
+	(*Message)(nil),   // 1: chainbridge.message.Message
+}
+var file_message_message_proto_depIdxs = []int32{
+	0, // 0: chainbridge.message.Message.type:type_name -> chainbridge.message.TransferType
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_message_message_proto_init() }
+func file_message_message_proto_init() {
+	if File_message_message_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_message_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Message); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_message_message_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_message_message_proto_goTypes,
+		DependencyIndexes: file_message_message_proto_depIdxs,
+		EnumInfos:         file_message_message_proto_enumTypes,
+		MessageInfos:      file_message_message_proto_msgTypes,
+	}.Build()
+	File_message_message_proto = out.File
+	file_message_message_proto_rawDesc = nil
+	file_message_message_proto_goTypes = nil
+	file_message_message_proto_depIdxs = nil
+}
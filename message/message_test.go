@@ -0,0 +1,97 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"google.golang.org/protobuf/proto"
+)
+
+var resourceId = msg.ResourceIdFromSlice([]byte{0x01, 0x02, 0x03})
+
+var messages = map[string]msg.Message{
+	"fungible":    msg.NewFungibleTransfer(1, 2, 3, big.NewInt(100), resourceId, []byte("recipient")),
+	"nonFungible": msg.NewNonFungibleTransfer(2, 1, 4, resourceId, big.NewInt(5), []byte("recipient"), []byte("metadata")),
+	"generic":     msg.NewGenericTransfer(1, 255, 1<<40, resourceId, []byte("metadata")),
+}
+
+func TestRoundtrip(t *testing.T) {
+	for _, format := range []Format{FormatProtobuf, FormatJSON} {
+		for name, m := range messages {
+			t.Run(string(format)+"/"+name, func(t *testing.T) {
+				b, err := MarshalFormat(format, m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				res, err := UnmarshalFormat(format, b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(res, m) {
+					t.Fatalf("roundtrip mismatch. expected: %#v got: %#v", m, res)
+				}
+			})
+		}
+	}
+}
+
+func TestMarshal_Invalid(t *testing.T) {
+	m := messages["generic"]
+	m.Type = "unknown"
+	_, err := Marshal(m)
+	if !errors.Is(err, ErrUnknownTransferType) {
+		t.Fatalf("expected ErrUnknownTransferType, got: %v", err)
+	}
+
+	m = messages["generic"]
+	m.Payload = []interface{}{"metadata"}
+	_, err = Marshal(m)
+	if !errors.Is(err, ErrUnsupportedPayload) {
+		t.Fatalf("expected ErrUnsupportedPayload, got: %v", err)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	b, err := proto.Marshal(&Message{Type: TransferType_GENERIC, Source: 256, ResourceId: resourceId[:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Unmarshal(b)
+	if err == nil {
+		t.Fatal("expected out of range chain ID to fail")
+	}
+
+	b, err = proto.Marshal(&Message{Type: TransferType_GENERIC, ResourceId: []byte{0x01}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Unmarshal(b)
+	if err == nil {
+		t.Fatal("expected short resource ID to fail")
+	}
+
+	_, err = Unmarshal([]byte{0xff})
+	if err == nil {
+		t.Fatal("expected malformed message to fail")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != FormatJSON {
+		t.Fatalf("expected %s, got %s", FormatJSON, f)
+	}
+	_, err = ParseFormat("xml")
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got: %v", err)
+	}
+}
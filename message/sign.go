@@ -0,0 +1,82 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/chainbridge-utils/crypto/secp256k1"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrInvalidSignature = errors.New("invalid relayer signature")
+
+// SignedMessage is a message with the signature of the relayer that built it, so that a change to the message after
+// it was signed can be detected
+type SignedMessage struct {
+	msg.Message
+	RelayerSignature []byte // Signature of Hash(Message), nil if the message is not signed
+}
+
+// Hash returns the keccak256 hash of every field of m. Each field is length prefixed, so different messages cannot
+// have the same encoding. Payload items must be []byte.
+func Hash(m msg.Message) (common.Hash, error) {
+	var data []byte
+	appendField := func(b []byte) {
+		data = append(data, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(data[len(data)-8:], uint64(len(b)))
+		data = append(data, b...)
+	}
+
+	appendField([]byte{byte(m.Source)})
+	appendField([]byte{byte(m.Destination)})
+	appendField([]byte(m.Type))
+	appendField(new(big.Int).SetUint64(uint64(m.DepositNonce)).Bytes())
+	appendField(m.ResourceId[:])
+	for i, item := range m.Payload {
+		b, ok := item.([]byte)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("%w: item %d is %T", ErrUnsupportedPayload, i, item)
+		}
+		appendField(b)
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Sign signs the hash of m with the relayer keypair kp
+func Sign(m msg.Message, kp *secp256k1.Keypair) (SignedMessage, error) {
+	hash, err := Hash(m)
+	if err != nil {
+		return SignedMessage{}, err
+	}
+	sig, err := crypto.Sign(hash.Bytes(), kp.PrivateKey())
+	if err != nil {
+		return SignedMessage{}, err
+	}
+	return SignedMessage{Message: m, RelayerSignature: sig}, nil
+}
+
+// Signer returns the address of the relayer that signed the message. If the message was changed after it was signed
+// a different address is returned, so callers must check it is one they trust. Forwarded messages are signed before
+// they are forwarded, so the signature is checked against the message Unforward returns.
+func (s SignedMessage) Signer() (common.Address, error) {
+	if len(s.RelayerSignature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(s.RelayerSignature))
+	}
+	original, _ := Unforward(s.Message)
+	hash, err := Hash(original)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash.Bytes(), s.RelayerSignature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/keystore"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestSign(t *testing.T) {
+	kp := keystore.TestKeyRing.EthereumKeys[keystore.AliceKey]
+
+	for name, m := range messages {
+		t.Run(name, func(t *testing.T) {
+			signed, err := Sign(m, kp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer, err := signed.Signer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signer != kp.CommonAddress() {
+				t.Fatalf("expected signer %s, got: %s", kp.CommonAddress().Hex(), signer.Hex())
+			}
+
+			tampered := signed
+			tampered.DepositNonce++
+			signer, err = tampered.Signer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signer == kp.CommonAddress() {
+				t.Fatal("expected a tampered message to recover a different signer")
+			}
+		})
+	}
+}
+
+func TestSignedMessage_SignerInvalid(t *testing.T) {
+	m := msg.NewFungibleTransfer(1, 2, 3, big.NewInt(100), resourceId, []byte("recipient"))
+	_, err := SignedMessage{Message: m}.Signer()
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for an unsigned message, got: %v", err)
+	}
+
+	_, err = Sign(msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Payload: []interface{}{"not bytes"}}, keystore.TestKeyRing.EthereumKeys[keystore.AliceKey])
+	if !errors.Is(err, ErrUnsupportedPayload) {
+		t.Fatalf("expected ErrUnsupportedPayload, got: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// ErrInvalidMessage is wrapped by the errors Validate returns
+var ErrInvalidMessage = errors.New("invalid message")
+
+// payloadLengths is the number of payload items of each transfer type
+var payloadLengths = map[msg.TransferType]int{
+	msg.FungibleTransfer:    2, // amount, recipient
+	msg.NonFungibleTransfer: 3, // tokenId, recipient, metadata
+	msg.GenericTransfer:     1, // metadata
+}
+
+// recipientIndex is the position of the recipient address in the payload of each transfer type that has one
+var recipientIndex = map[msg.TransferType]int{
+	msg.FungibleTransfer:    1,
+	msg.NonFungibleTransfer: 1,
+}
+
+// Validate checks that m is sane before it is routed, as messages built from malformed on-chain data may not be.
+// The source and destination must differ and the deposit nonce must be set. For the transfer types defined by msg,
+// the payload must have the type's number of items and the recipient must not be a zero address. Payloads of
+// other transfer types, such as those added by chain packages, are not checked.
+func Validate(m msg.Message) error {
+	if m.Source == m.Destination {
+		return fmt.Errorf("%w: source and destination are both chain %d", ErrInvalidMessage, m.Source)
+	}
+	if m.DepositNonce == 0 {
+		return fmt.Errorf("%w: deposit nonce is zero", ErrInvalidMessage)
+	}
+
+	expected, ok := payloadLengths[m.Type]
+	if !ok {
+		return nil
+	}
+	if len(m.Payload) != expected {
+		return fmt.Errorf("%w: %s has %d payload items, expected %d", ErrInvalidMessage, m.Type, len(m.Payload), expected)
+	}
+	if i, ok := recipientIndex[m.Type]; ok {
+		recipient, ok := m.Payload[i].([]byte)
+		if !ok {
+			return fmt.Errorf("%w: recipient is %T, expected []byte", ErrInvalidMessage, m.Payload[i])
+		}
+		if isZero(recipient) {
+			return fmt.Errorf("%w: recipient is a zero address", ErrInvalidMessage)
+		}
+	}
+	return nil
+}
+
+// isZero returns whether b is empty or only contains zero bytes
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
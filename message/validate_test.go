@@ -0,0 +1,49 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+func TestValidate(t *testing.T) {
+	recipient := []byte{0x01, 0x02}
+	zeroAddress := make([]byte, 20)
+
+	testCases := []struct {
+		name  string
+		m     msg.Message
+		valid bool
+	}{
+		{"fungible", msg.NewFungibleTransfer(1, 2, 3, big.NewInt(100), resourceId, recipient), true},
+		{"nonFungible", msg.NewNonFungibleTransfer(1, 2, 3, resourceId, big.NewInt(5), recipient, []byte("metadata")), true},
+		{"generic", msg.NewGenericTransfer(1, 2, 3, resourceId, []byte("metadata")), true},
+		{"otherType", msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Type: "other"}, true},
+		{"sameChain", msg.NewFungibleTransfer(1, 1, 3, big.NewInt(100), resourceId, recipient), false},
+		{"zeroNonce", msg.NewFungibleTransfer(1, 2, 0, big.NewInt(100), resourceId, recipient), false},
+		{"fungibleMissingRecipient", msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Type: msg.FungibleTransfer, Payload: []interface{}{big.NewInt(100).Bytes()}}, false},
+		{"nonFungibleExtraItem", msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Type: msg.NonFungibleTransfer, Payload: []interface{}{[]byte{5}, recipient, []byte{}, []byte{}}}, false},
+		{"genericNoMetadata", msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Type: msg.GenericTransfer}, false},
+		{"fungibleZeroRecipient", msg.NewFungibleTransfer(1, 2, 3, big.NewInt(100), resourceId, zeroAddress), false},
+		{"fungibleEmptyRecipient", msg.NewFungibleTransfer(1, 2, 3, big.NewInt(100), resourceId, []byte{}), false},
+		{"nonFungibleZeroRecipient", msg.NewNonFungibleTransfer(1, 2, 3, resourceId, big.NewInt(5), zeroAddress, []byte("metadata")), false},
+		{"recipientNotBytes", msg.Message{Source: 1, Destination: 2, DepositNonce: 3, Type: msg.FungibleTransfer, Payload: []interface{}{[]byte{1}, "recipient"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.m)
+			if tc.valid && err != nil {
+				t.Fatalf("expected message to be valid, got: %v", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidMessage) {
+				t.Fatalf("expected ErrInvalidMessage, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package metadata fetches the metadata of tokens that is relayed alongside their transfers.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChainSafe/ChainBridge/bindings/IERC721Metadata"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// URIFetcher returns the URI of an erc721 token's metadata
+type URIFetcher interface {
+	TokenURI(token common.Address, tokenId *big.Int) (string, error)
+}
+
+var _ URIFetcher = &ERC721URIFetcher{}
+
+// ErrNoTokenURI is returned by TokenURI when the token contract reverts the tokenURI call or returns nothing, as
+// contracts without the metadata extension do
+var ErrNoTokenURI = errors.New("token has no URI")
+
+// ERC721URIFetcher calls tokenURI on erc721 contracts that implement the metadata extension
+type ERC721URIFetcher struct {
+	backend bind.ContractCaller
+	opts    func() *bind.CallOpts
+}
+
+// NewERC721URIFetcher returns a fetcher calling contracts through backend, with the call options returned by opts
+func NewERC721URIFetcher(backend bind.ContractCaller, opts func() *bind.CallOpts) *ERC721URIFetcher {
+	return &ERC721URIFetcher{backend: backend, opts: opts}
+}
+
+// TokenURI returns the URI of tokenId. ErrNoTokenURI is returned if token does not implement tokenURI or the token
+// does not exist, any other error if the call could not be made.
+func (f *ERC721URIFetcher) TokenURI(token common.Address, tokenId *big.Int) (string, error) {
+	caller, err := IERC721Metadata.NewIERC721MetadataCaller(token, f.backend)
+	if err != nil {
+		return "", err
+	}
+	uri, err := caller.TokenURI(f.opts(), tokenId)
+	if isMissingURI(err) {
+		return "", fmt.Errorf("%w: token %s of %s: %v", ErrNoTokenURI, tokenId, token.Hex(), err)
+	} else if err != nil {
+		return "", fmt.Errorf("unable to get URI of token %s of %s: %w", tokenId, token.Hex(), err)
+	}
+	return uri, nil
+}
+
+// isMissingURI returns true if the tokenURI call was made but reverted or returned nothing
+func isMissingURI(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, bind.ErrNoCode) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "attempting to unmarshall an empty string")
+}
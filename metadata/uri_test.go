@@ -0,0 +1,84 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ChainSafe/ChainBridge/bindings/IERC721Metadata"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var tokenABI, _ = abi.JSON(strings.NewReader(IERC721Metadata.IERC721MetadataABI))
+
+// mockERC721 answers tokenURI calls to a single token contract
+type mockERC721 struct {
+	address common.Address
+	uris    map[int64]string
+	err     error // Returned by every call if set, as if the node could not be reached
+}
+
+func (m *mockERC721) CodeAt(_ context.Context, contract common.Address, _ *big.Int) ([]byte, error) {
+	if contract == m.address {
+		return []byte{1}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockERC721) CallContract(_ context.Context, call eth.CallMsg, _ *big.Int) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	method := tokenABI.Methods["tokenURI"]
+	if *call.To != m.address || !bytes.Equal(call.Data[:4], method.ID) {
+		return nil, errors.New("execution reverted")
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	uri, ok := m.uris[args[0].(*big.Int).Int64()]
+	if !ok {
+		return nil, errors.New("execution reverted: ERC721Metadata: URI query for nonexistent token")
+	}
+	return method.Outputs.Pack(uri)
+}
+
+func TestERC721URIFetcher(t *testing.T) {
+	token := common.HexToAddress("0x0000000000000000000000000000000000000721")
+	backend := &mockERC721{address: token, uris: map[int64]string{1: "ipfs://QmToken/1.json"}}
+	fetcher := NewERC721URIFetcher(backend, func() *bind.CallOpts { return &bind.CallOpts{} })
+
+	uri, err := fetcher.TokenURI(token, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != "ipfs://QmToken/1.json" {
+		t.Fatalf("unexpected URI: %s", uri)
+	}
+
+	_, err = fetcher.TokenURI(token, big.NewInt(2))
+	if !errors.Is(err, ErrNoTokenURI) {
+		t.Fatalf("expected ErrNoTokenURI for a token that does not exist, got: %v", err)
+	}
+	_, err = fetcher.TokenURI(common.HexToAddress("0x01"), big.NewInt(1))
+	if !errors.Is(err, ErrNoTokenURI) {
+		t.Fatalf("expected ErrNoTokenURI for a contract without tokenURI, got: %v", err)
+	}
+
+	// A failed request is not mistaken for a token without a URI
+	backend.err = errors.New("connection refused")
+	_, err = fetcher.TokenURI(token, big.NewInt(1))
+	if err == nil || errors.Is(err, ErrNoTokenURI) {
+		t.Fatalf("expected a request error, got: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/core"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	log "github.com/ChainSafe/log15"
+)
+
+type httpMetricServer struct {
+	port         int
+	blockTimeout int // After this duration (seconds) with no change in block height a chain will be considered unhealthy
+	chains       []core.Chain
+	stats        []ChainInfo
+}
+
+type httpResponse struct {
+	Chains []ChainInfo `json:"chains,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type ChainInfo struct {
+	ChainId        msg.ChainId `json:"chainId"`
+	Height         *big.Int    `json:"height"`
+	LastUpdated    time.Time   `json:"lastUpdated"`
+	ProcessedRange *BlockRange `json:"processedRange,omitempty"` // Set for chains that implement core.RangeReporter
+}
+
+// BlockRange is the first and last blocks the listener of a chain has processed
+type BlockRange struct {
+	From *big.Int `json:"from"`
+	To   *big.Int `json:"to"`
+}
+
+func NewHealthServer(port int, chains []core.Chain, blockTimeout int) *httpMetricServer {
+	return &httpMetricServer{
+		port:         port,
+		chains:       chains,
+		blockTimeout: blockTimeout,
+		stats:        make([]ChainInfo, len(chains)),
+	}
+}
+
+// healthStatus is a catch-all update that grabs the latest updates on the running chains
+// It assumes that the configuration was set correctly, therefore the relevant chains are
+// only those that are in the core.Core registry.
+func (s httpMetricServer) HealthStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Iterate through their block heads and update the cache accordingly
+	for i, chain := range s.chains {
+		current := chain.LatestBlock()
+		prev := s.stats[i]
+		if s.stats[i].Height == nil {
+			// First time we've received a block for this chain
+			s.stats[i] = ChainInfo{
+				ChainId:     chain.Id(),
+				Height:      current.Height,
+				LastUpdated: current.LastUpdated,
+			}
+		} else {
+			now := time.Now()
+			timeDiff := now.Sub(prev.LastUpdated)
+			// If block has changed, update it
+			if current.Height.Cmp(prev.Height) == 1 {
+				s.stats[i].LastUpdated = current.LastUpdated
+				s.stats[i].Height = current.Height
+			} else if int(timeDiff.Seconds()) >= s.blockTimeout { // Error if we exceeded the time limit
+				response := &httpResponse{
+					Chains: []ChainInfo{},
+					Error:  fmt.Sprintf("chain %d height hasn't changed for %f seconds. Current Height: %s", prev.ChainId, timeDiff.Seconds(), current.Height),
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+				err := json.NewEncoder(w).Encode(response)
+				if err != nil {
+					log.Error("Failed to write metrics", "err", err)
+				}
+				return
+			} else if current.Height != nil && prev.Height != nil && current.Height.Cmp(prev.Height) == -1 { // Error for having a smaller blockheight than previous
+				response := &httpResponse{
+					Chains: []ChainInfo{},
+					Error:  fmt.Sprintf("unexpected block height. previous = %s current = %s", prev.Height, current.Height),
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+				err := json.NewEncoder(w).Encode(response)
+				if err != nil {
+					log.Error("Failed to write metrics", "err", err)
+				}
+				return
+			}
+		}
+
+		if r, ok := chain.(core.RangeReporter); ok {
+			from, to := r.GetProcessedRange()
+			s.stats[i].ProcessedRange = &BlockRange{From: from, To: to}
+		}
+	}
+
+	response := &httpResponse{
+		Chains: s.stats,
+		Error:  "",
+	}
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Error("Failed to serve metrics")
+	}
+}
+
+// StatusHandler serves the status of the bridge's chains as JSON
+func StatusHandler(c *core.Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(c.Status())
+		if err != nil {
+			log.Error("Failed to write status", "err", err)
+		}
+	}
+}
+
+// QueuedMessage is a message waiting for a chain's writer, as served by QueueHandler
+type QueuedMessage struct {
+	Source      msg.ChainId      `json:"source"`
+	Destination msg.ChainId      `json:"destination"`
+	Nonce       msg.Nonce        `json:"nonce"`
+	Type        msg.TransferType `json:"type"`
+	ResourceId  string           `json:"resourceId"`
+	EnqueuedAt  time.Time        `json:"enqueuedAt"`
+	Attempts    int              `json:"attempts"`
+	LastError   string           `json:"lastError,omitempty"`
+	InFlight    bool             `json:"inFlight"`
+}
+
+// QueueHandler serves the messages queued for a chain's writer as JSON at /chains/{id}/queue
+func QueueHandler(c *core.Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 3 || parts[0] != "chains" || parts[2] != "queue" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid chain id %q", parts[1]), http.StatusBadRequest)
+			return
+		}
+
+		queue, err := c.Queue(msg.ChainId(id))
+		if errors.Is(err, core.ErrChainNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		messages := make([]QueuedMessage, len(queue))
+		for i, q := range queue {
+			messages[i] = QueuedMessage{
+				Source:      q.Message.Source,
+				Destination: q.Message.Destination,
+				Nonce:       q.Message.DepositNonce,
+				Type:        q.Message.Type,
+				ResourceId:  q.Message.ResourceId.Hex(),
+				EnqueuedAt:  q.EnqueuedAt,
+				Attempts:    q.Attempts,
+				LastError:   q.LastError,
+				InFlight:    q.InFlight,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(messages)
+		if err != nil {
+			log.Error("Failed to write queue", "err", err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The profile package writes pprof profiles of the running relayer on demand.
+
+When the relayer receives SIGUSR1 a heap profile is written immediately, then a CPU profile is recorded for the
+profiling duration. Both are written to the output directory, named by the time the signal was received:
+
+	cpu_20060102_150405.prof
+	heap_20060102_150405.prof
+
+Signals received while a CPU profile is being recorded are ignored.
+*/
+package profile
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"syscall"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+// DefaultDuration is the length of CPU profiles
+const DefaultDuration = 30 * time.Second
+
+// timestampFormat names the profile files
+const timestampFormat = "20060102_150405"
+
+// Profiler writes profiles to dir each time SIGUSR1 is received
+type Profiler struct {
+	dir      string
+	duration time.Duration
+	log      log15.Logger
+	signals  chan os.Signal
+	stop     chan struct{}
+}
+
+func NewProfiler(dir string, duration time.Duration, log log15.Logger) *Profiler {
+	return &Profiler{
+		dir:      dir,
+		duration: duration,
+		log:      log,
+		signals:  make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start creates the output directory and starts handling SIGUSR1
+func (p *Profiler) Start() error {
+	err := os.MkdirAll(p.dir, 0700)
+	if err != nil {
+		return err
+	}
+	signal.Notify(p.signals, syscall.SIGUSR1)
+	go p.run()
+	p.log.Info("Send SIGUSR1 to write a profile", "dir", p.dir, "duration", p.duration)
+	return nil
+}
+
+// Stop stops handling signals, ending any CPU profile being recorded
+func (p *Profiler) Stop() {
+	signal.Stop(p.signals)
+	close(p.stop)
+}
+
+func (p *Profiler) run() {
+	for {
+		select {
+		case <-p.signals:
+			err := p.profile(time.Now())
+			if err != nil {
+				p.log.Error("Failed to write profile", "err", err)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// profile writes a heap profile, then records a CPU profile until the duration has passed or the profiler is stopped
+func (p *Profiler) profile(at time.Time) error {
+	timestamp := at.Format(timestampFormat)
+
+	heapPath := filepath.Join(p.dir, fmt.Sprintf("heap_%s.prof", timestamp))
+	err := writeHeapProfile(heapPath)
+	if err != nil {
+		return err
+	}
+
+	cpuPath := filepath.Join(p.dir, fmt.Sprintf("cpu_%s.prof", timestamp))
+	cpu, err := os.Create(cpuPath)
+	if err != nil {
+		return err
+	}
+	defer cpu.Close()
+
+	err = pprof.StartCPUProfile(cpu)
+	if err != nil {
+		return err
+	}
+	p.log.Info("Recording CPU profile", "file", cpuPath, "duration", p.duration)
+	timer := time.NewTimer(p.duration)
+	select {
+	case <-timer.C:
+	case <-p.stop:
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+
+	err = cpu.Close()
+	if err != nil {
+		return err
+	}
+	p.log.Info("Wrote profiles", "cpu", cpuPath, "heap", heapPath)
+	return nil
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Collects garbage first, so the profile is up to date
+	runtime.GC()
+	err = pprof.WriteHeapProfile(f)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
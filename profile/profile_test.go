@@ -0,0 +1,60 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+// waitForProfile waits for a file matching pattern in dir to be written
+func waitForProfile(t *testing.T, dir, pattern string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) == 1 {
+			info, err := os.Stat(matches[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() > 0 {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no %s profile was written to %s", pattern, dir)
+}
+
+func TestProfiler_Signal(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewProfiler(filepath.Join(dir, "out"), 100*time.Millisecond, log15.Root())
+	err = p.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForProfile(t, filepath.Join(dir, "out"), "heap_*.prof")
+	waitForProfile(t, filepath.Join(dir, "out"), "cpu_*.prof")
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The registry package maps chain IDs to the names chains are configured with, so log lines and errors can name a chain
+rather than only give its ID.
+
+Chains are registered by core.NewChain as they are initialized.
+*/
+package registry
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+)
+
+// ChainNames is the name of each registered chain. It must only be accessed with Register and NameOf once chains
+// are running.
+var ChainNames = make(map[msg.ChainId]string)
+
+var chainNamesLock sync.RWMutex
+
+// Register sets the name of chain id, replacing any name it was registered with
+func Register(id msg.ChainId, name string) {
+	chainNamesLock.Lock()
+	defer chainNamesLock.Unlock()
+	ChainNames[id] = name
+}
+
+// NameOf returns the name of chain id, or "unknown(<id>)" if it is not registered
+func NameOf(id msg.ChainId) string {
+	chainNamesLock.RLock()
+	defer chainNamesLock.RUnlock()
+	if name, ok := ChainNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", id)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package registry
+
+import (
+	"testing"
+)
+
+func TestNameOf(t *testing.T) {
+	if name := NameOf(0); name != "unknown(0)" {
+		t.Fatalf("expected unregistered chain to be unknown(0), got: %s", name)
+	}
+
+	Register(0, "ethereum")
+	if name := NameOf(0); name != "ethereum" {
+		t.Fatalf("expected ethereum, got: %s", name)
+	}
+	if name := NameOf(1); name != "unknown(1)" {
+		t.Fatalf("expected unregistered chain to be unknown(1), got: %s", name)
+	}
+}
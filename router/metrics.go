@@ -0,0 +1,104 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package router
+
+import (
+	"math"
+	"time"
+
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rateWindow is the time constant of the EWMA of the message rate
+const rateWindow = 10 * time.Second
+
+var (
+	queueDepthGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "chainbridge_router_queue_depth",
+		Help: "Number of messages waiting for or being resolved by the router's writers",
+	})
+	sentGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "chainbridge_router_messages_sent",
+		Help: "Number of messages queued for a writer by the router",
+	})
+	failedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "chainbridge_router_messages_failed",
+		Help: "Number of messages the router rejected",
+	})
+	writersGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "chainbridge_router_writers_registered",
+		Help: "Number of writers registered with the router, including fast lane writers",
+	})
+	rateGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "chainbridge_router_message_rate",
+		Help: "Messages queued by the router per second, averaged over the last 10 seconds",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepthGauge, sentGauge, failedGauge, writersGauge, rateGauge)
+}
+
+// RouterMetrics is a snapshot of the router's queues and throughput
+type RouterMetrics struct {
+	QueueDepth           int     `json:"queueDepth"`           // Messages waiting for or being resolved by all writers
+	TotalSent            uint64  `json:"totalSent"`            // Messages queued for a writer, counting each destination of SendToDestinations
+	TotalFailed          uint64  `json:"totalFailed"`          // Calls to Send or SendToDestinations that returned an error
+	WritersRegistered    int     `json:"writersRegistered"`    // Registered writers, including fast lane writers
+	MessageRatePerSecond float64 `json:"messageRatePerSecond"` // Exponentially weighted moving average over the last 10 seconds
+}
+
+// Metrics returns the router's current metrics and updates its Prometheus gauges with them
+func (r *Router) Metrics() RouterMetrics {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.updateMetrics()
+}
+
+// recordSent counts n queued messages. The router lock must be held.
+func (r *Router) recordSent(n int) {
+	r.decayRate(time.Now())
+	r.rate += float64(n) / rateWindow.Seconds()
+	r.sent += uint64(n)
+	r.updateMetrics()
+}
+
+// recordFailed counts a rejected message. The router lock must be held.
+func (r *Router) recordFailed() {
+	r.failed++
+	r.updateMetrics()
+}
+
+// decayRate decays the message rate to now. The router lock must be held.
+func (r *Router) decayRate(now time.Time) {
+	if !r.rateUpdated.IsZero() {
+		r.rate *= math.Exp(-now.Sub(r.rateUpdated).Seconds() / rateWindow.Seconds())
+	}
+	r.rateUpdated = now
+}
+
+// updateMetrics sets the Prometheus gauges to the router's current metrics and returns them.
+// The router lock must be held.
+func (r *Router) updateMetrics() RouterMetrics {
+	r.decayRate(time.Now())
+	m := RouterMetrics{
+		TotalSent:            r.sent,
+		TotalFailed:          r.failed,
+		WritersRegistered:    len(r.registry) + len(r.priority),
+		MessageRatePerSecond: r.rate,
+	}
+	for _, registry := range []map[msg.ChainId]*destination{r.registry, r.priority} {
+		for _, d := range registry {
+			m.QueueDepth += d.pending()
+		}
+	}
+
+	queueDepthGauge.Set(float64(m.QueueDepth))
+	sentGauge.Set(float64(m.TotalSent))
+	failedGauge.Set(float64(m.TotalFailed))
+	writersGauge.Set(float64(m.WritersRegistered))
+	rateGauge.Set(m.MessageRatePerSecond)
+	return m
+}
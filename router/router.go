@@ -0,0 +1,686 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package router
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/chains"
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	log "github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ chains.SignedRouter = &Router{}
+
+// ErrQueueFull is returned by Send when the destination already has MaxQueueDepth messages waiting
+var ErrQueueFull = errors.New("router queue is full")
+
+// ErrDraining is returned by Send when the destination Writer is being drained
+var ErrDraining = errors.New("destination writer is draining")
+
+// ErrForwardedMetadata is returned by Send for generic messages whose metadata could be mistaken for a message
+// the router has forwarded
+var ErrForwardedMetadata = errors.New("metadata has the forwarding prefix")
+
+// DrainPollInterval is how often Drain checks whether a Writer has resolved its queued messages
+var DrainPollInterval = 10 * time.Millisecond
+
+// DefaultMaxQueueDepth is the number of messages that may wait for a single destination
+const DefaultMaxQueueDepth = 1000
+
+// DefaultMaxInFlight is the number of messages a single destination's Writer may resolve at once. Messages are passed
+// to writers one at a time by default, ethereum writers limit the proposals they submit at once with maxConcurrentProposals.
+const DefaultMaxInFlight = 1
+
+var droppedMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "chainbridge_router_dropped_messages_total",
+	Help: "Number of messages dropped by the router because the destination queue was full",
+}, []string{"destination"})
+
+func init() {
+	prometheus.MustRegister(droppedMessages)
+}
+
+// QueueConfig bounds the messages waiting for each destination Writer, and the number it resolves at once
+type QueueConfig struct {
+	MaxQueueDepth int  // Maximum number of waiting messages per destination, 0 for no limit
+	DropOldest    bool // When full, drop the oldest waiting message instead of returning ErrQueueFull
+	MaxInFlight   int  // Maximum number of messages each destination's Writer resolves at once, 1 if 0 or less
+}
+
+// QueuedMessage is a message waiting for the Writer of its destination, or being resolved by it
+type QueuedMessage struct {
+	Message    msg.Message
+	EnqueuedAt time.Time
+	Attempts   int    // Number of times the message has been passed to a Writer
+	LastError  string // Why the last attempt did not resolve the message, empty if it has not been attempted
+	InFlight   bool   // The Writer is resolving the message
+}
+
+// queued is a message in the queue of a destination
+type queued struct {
+	message.SignedMessage
+	enqueuedAt time.Time
+	attempts   int
+	lastError  string
+}
+
+// destination holds the Writer registered for a chain and the messages waiting for it
+type destination struct {
+	writer   chains.Writer
+	queue    []queued      // Messages not yet passed to the writer, oldest first
+	inflight []*queued     // Messages being resolved by the writer, in the order they were passed to it
+	notify   chan struct{} // Wakes the dispatcher when a message is queued
+	stop     chan struct{} // Closed when the writer is replaced or drained
+	stopped  bool          // Set once stop is closed
+	draining bool          // Set while the writer is drained, messages are no longer queued for it
+}
+
+// close stops the dispatcher if it has not already been stopped. The router lock must be held.
+func (d *destination) close() {
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+}
+
+// pending returns the number of messages the writer has not resolved, including those it is resolving.
+// The router lock must be held.
+func (d *destination) pending() int {
+	return len(d.queue) + len(d.inflight)
+}
+
+// resolved removes m from the messages being resolved. The router lock must be held.
+func (d *destination) resolved(m *queued) {
+	for i, inflight := range d.inflight {
+		if inflight == m {
+			d.inflight = append(d.inflight[:i:i], d.inflight[i+1:]...)
+			return
+		}
+	}
+}
+
+// wake signals the dispatcher without blocking if it has already been signalled
+func (d *destination) wake() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Sink receives every message the router queues, such as to publish it for consumers outside the bridge. Publish is
+// called with the router lock held, so it must not block or call the router.
+type Sink interface {
+	Publish(m msg.Message)
+}
+
+// Route is a source and destination pair, whose messages are sent via other chains
+type Route struct {
+	Source      msg.ChainId
+	Destination msg.ChainId
+}
+
+// Router forwards messages from their source to their destination
+type Router struct {
+	registry map[msg.ChainId]*destination
+	// priority holds the fast lane writers of destinations, which resolve the resource IDs in their
+	// routing table instead of the destination's writer
+	priority map[msg.ChainId]*destination
+	routes   map[msg.ChainId]map[msg.ResourceId]bool
+	hops     map[Route][]msg.ChainId // Chains messages are sent via, in order, to reach destinations without a Writer
+	sinks    []Sink                  // Receive the messages queued
+	cfg      QueueConfig
+	lock     *sync.RWMutex
+	log      log.Logger
+
+	sent        uint64    // Messages queued for a writer
+	failed      uint64    // Calls to Send or SendToDestinations that returned an error
+	rate        float64   // EWMA of the messages queued per second, as of rateUpdated
+	rateUpdated time.Time // When rate was last decayed, zero until a message is sent
+}
+
+func NewRouter(log log.Logger) *Router {
+	return NewRouterWithConfig(log, QueueConfig{MaxQueueDepth: DefaultMaxQueueDepth, MaxInFlight: DefaultMaxInFlight})
+}
+
+func NewRouterWithConfig(log log.Logger, cfg QueueConfig) *Router {
+	return &Router{
+		registry: make(map[msg.ChainId]*destination),
+		priority: make(map[msg.ChainId]*destination),
+		routes:   make(map[msg.ChainId]map[msg.ResourceId]bool),
+		hops:     make(map[Route][]msg.ChainId),
+		cfg:      cfg,
+		lock:     &sync.RWMutex{},
+		log:      log,
+	}
+}
+
+// Send queues a message for the destination Writer if it exists. Messages that fail message.Validate are rejected,
+// as are messages for a Writer being drained, with ErrDraining. If the queue is full the message is rejected with ErrQueueFull, or the oldest waiting message is dropped if
+// DropOldest is set.
+func (r *Router) Send(m msg.Message) error {
+	return r.SendSigned(message.SignedMessage{Message: m})
+}
+
+// SendSigned queues a message signed by the relayer as described by Send. Writers that implement
+// chains.SignedWriter are passed the signature with the message, other writers only the message.
+func (r *Router) SendSigned(m message.SignedMessage) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	err := r.send(m)
+	if err != nil {
+		r.recordFailed()
+		return err
+	}
+	r.recordSent(1)
+	r.publish(m.Message)
+	return nil
+}
+
+// send queues m as described by Send. The router lock must be held.
+func (r *Router) send(m message.SignedMessage) error {
+	msg := m.Message
+	err := message.Validate(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, forwarded := message.Unforward(msg); forwarded {
+		return ErrForwardedMetadata
+	}
+
+	r.log.Trace("Routing message", "src", msg.Source, "dest", msg.Destination, "nonce", msg.DepositNonce, "rId", msg.ResourceId.Hex())
+	d := r.route(msg.Destination, msg.ResourceId)
+	if d == nil {
+		via, ok := r.nextHop(msg.Source, msg.Destination)
+		if !ok {
+			return fmt.Errorf("unknown destination chainId: %d", msg.Destination)
+		}
+		forwarded, err := message.Forward(msg, via)
+		if err != nil {
+			return fmt.Errorf("destination chainId %d is only reachable via chainId %d: %w", msg.Destination, via, err)
+		}
+		r.log.Debug("Forwarding message", "src", msg.Source, "dest", msg.Destination, "via", via, "nonce", msg.DepositNonce)
+		m.Message = forwarded
+		msg = forwarded
+		d = r.route(via, msg.ResourceId)
+	}
+	if d.draining {
+		return ErrDraining
+	}
+	if !r.hasRoom(d) && !r.cfg.DropOldest {
+		return ErrQueueFull
+	}
+
+	r.enqueue(d, m)
+	return nil
+}
+
+// SendToDestinations queues a copy of the message for each destination, with Destination set to that chain.
+// Each destination's Writer resolves its copy independently of the others. The copies are not signed, so Writers
+// that require signed messages reject them. The message is only queued if every
+// destination exists and is not being drained and, unless DropOldest is set, has room for it, and every copy passes message.Validate.
+// A destination listed more than once is sent a single copy.
+func (r *Router) SendToDestinations(m msg.Message, destinations []msg.ChainId) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	destinations = uniqueDestinations(destinations)
+
+	err := r.sendToDestinations(m, destinations)
+	if err != nil {
+		r.recordFailed()
+		return err
+	}
+	r.recordSent(len(destinations))
+	for _, id := range destinations {
+		routed := m
+		routed.Destination = id
+		r.publish(routed)
+	}
+	return nil
+}
+
+// AddSink passes every message queued from now on to s
+func (r *Router) AddSink(s Sink) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sinks = append(r.sinks, s)
+}
+
+// publish passes m to the sinks. The router lock must be held.
+func (r *Router) publish(m msg.Message) {
+	for _, s := range r.sinks {
+		s.Publish(m)
+	}
+}
+
+// sendToDestinations queues the copies of m as described by SendToDestinations. The router lock must be held.
+func (r *Router) sendToDestinations(m msg.Message, destinations []msg.ChainId) error {
+	ds := make([]*destination, len(destinations))
+	for i, id := range destinations {
+		routed := m
+		routed.Destination = id
+		err := message.Validate(routed)
+		if err != nil {
+			return err
+		}
+		if _, forwarded := message.Unforward(routed); forwarded {
+			return ErrForwardedMetadata
+		}
+		d := r.route(id, m.ResourceId)
+		if d == nil {
+			return fmt.Errorf("unknown destination chainId: %d", id)
+		}
+		if d.draining {
+			return fmt.Errorf("%w: destination chainId %d", ErrDraining, id)
+		}
+		if !r.hasRoom(d) && !r.cfg.DropOldest {
+			return fmt.Errorf("%w: destination chainId %d", ErrQueueFull, id)
+		}
+		ds[i] = d
+	}
+
+	for i, d := range ds {
+		routed := m
+		routed.Destination = destinations[i]
+		r.log.Trace("Routing message", "src", routed.Source, "dest", routed.Destination, "nonce", routed.DepositNonce, "rId", routed.ResourceId.Hex())
+		r.enqueue(d, message.SignedMessage{Message: routed})
+	}
+	return nil
+}
+
+// uniqueDestinations returns ids without duplicates, in the order they first appear
+func uniqueDestinations(ids []msg.ChainId) []msg.ChainId {
+	seen := make(map[msg.ChainId]bool, len(ids))
+	unique := make([]msg.ChainId, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// route returns the destination that resolves messages with resourceId for chain id, which is its fast lane
+// if the resource ID is in its routing table. The router lock must be held.
+func (r *Router) route(id msg.ChainId, resourceId msg.ResourceId) *destination {
+	if d := r.priority[id]; d != nil && r.routes[id][resourceId] {
+		return d
+	}
+	return r.registry[id]
+}
+
+// nextHop returns the chain with a Writer that messages from source to destination are sent via. The route of the
+// pair is used if one is set, otherwise the chain after source in a route to destination that passes through it.
+// The router lock must be held.
+func (r *Router) nextHop(source, destination msg.ChainId) (msg.ChainId, bool) {
+	var next msg.ChainId
+	found := false
+	if via, ok := r.hops[Route{Source: source, Destination: destination}]; ok {
+		next, found = via[0], true
+	} else {
+		for route, via := range r.hops {
+			if route.Destination != destination {
+				continue
+			}
+			for i, hop := range via {
+				if hop != source {
+					continue
+				}
+				next, found = destination, true
+				if i+1 < len(via) {
+					next = via[i+1]
+				}
+			}
+		}
+	}
+	if !found || next == destination || r.registry[next] == nil {
+		return 0, false
+	}
+	return next, true
+}
+
+// SetRoute sends messages from source to destination, which has no Writer, via the chains in via in order. The
+// router forwards them to the first chain, whose Writer deposits them again for the next chain. Only generic
+// messages can be forwarded.
+func (r *Router) SetRoute(source, destination msg.ChainId, via []msg.ChainId) error {
+	if len(via) == 0 {
+		return fmt.Errorf("route from chainId %d to %d has no hops", source, destination)
+	}
+	seen := map[msg.ChainId]bool{source: true, destination: true}
+	for _, hop := range via {
+		if seen[hop] {
+			return fmt.Errorf("route from chainId %d to %d visits chainId %d more than once", source, destination, hop)
+		}
+		seen[hop] = true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.log.Debug("Setting route", "src", source, "dest", destination, "via", via)
+	r.hops[Route{Source: source, Destination: destination}] = append([]msg.ChainId{}, via...)
+	return nil
+}
+
+// hasRoom returns whether another message can be queued for d without exceeding MaxQueueDepth.
+// The router lock must be held.
+func (r *Router) hasRoom(d *destination) bool {
+	return r.cfg.MaxQueueDepth <= 0 || len(d.queue) < r.cfg.MaxQueueDepth
+}
+
+// enqueue adds msg to the queue for d, first dropping the oldest message if the queue is full.
+// The router lock must be held.
+func (r *Router) enqueue(d *destination, m message.SignedMessage) {
+	if !r.hasRoom(d) {
+		dropped := d.queue[0]
+		d.queue = d.queue[1:]
+		droppedMessages.WithLabelValues(strconv.Itoa(int(m.Destination))).Inc()
+		r.log.Warn("Router queue full, dropping oldest message", "src", dropped.Source, "dest", dropped.Destination, "nonce", dropped.DepositNonce)
+	}
+
+	d.queue = append(d.queue, queued{SignedMessage: m, enqueuedAt: time.Now()})
+	d.wake()
+}
+
+// dispatch passes queued messages to the destination's writer, oldest first, until the writer is replaced or
+// drained. Up to MaxInFlight messages are resolved at once, each in its own routine.
+func (r *Router) dispatch(d *destination) {
+	for {
+		r.lock.Lock()
+		if len(d.queue) == 0 || len(d.inflight) >= r.maxInFlight() {
+			r.lock.Unlock()
+			select {
+			case <-d.notify:
+				continue
+			case <-d.stop:
+				return
+			}
+		}
+		m := d.queue[0]
+		d.queue = d.queue[1:]
+		m.attempts++
+		d.inflight = append(d.inflight, &m)
+		r.lock.Unlock()
+
+		go r.resolve(d, &m)
+	}
+}
+
+// resolve passes m to the destination's writer, then wakes the dispatcher to pass it another message
+func (r *Router) resolve(d *destination, m *queued) {
+	if w, ok := d.writer.(chains.SignedWriter); ok {
+		w.ResolveSignedMessage(m.SignedMessage)
+	} else {
+		d.writer.ResolveMessage(m.Message)
+	}
+
+	r.lock.Lock()
+	d.resolved(m)
+	r.updateMetrics()
+	r.lock.Unlock()
+	d.wake()
+}
+
+// maxInFlight returns the number of messages a destination's writer may resolve at once
+func (r *Router) maxInFlight() int {
+	if r.cfg.MaxInFlight <= 0 {
+		return 1
+	}
+	return r.cfg.MaxInFlight
+}
+
+// register sets the writer for id in registry and starts its dispatcher. Messages waiting for a previous
+// writer, including those it has not finished resolving, are passed to the new writer. A writer being drained is not
+// replaced, ErrDraining is returned instead. The caller must hold the lock.
+func (r *Router) register(registry map[msg.ChainId]*destination, id msg.ChainId, w chains.Writer) error {
+	old := registry[id]
+	if old != nil && old.draining {
+		return ErrDraining
+	}
+	d := &destination{
+		writer: w,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+
+	if old != nil {
+		for _, inflight := range old.inflight {
+			requeued := *inflight
+			requeued.lastError = "writer was replaced while resolving the message"
+			d.queue = append(d.queue, requeued)
+		}
+		old.inflight = nil
+		d.queue = append(d.queue, old.queue...)
+		old.queue = nil
+		old.close()
+	}
+
+	registry[id] = d
+	d.wake()
+	go r.dispatch(d)
+	return nil
+}
+
+// Listen registers a Writer with a ChainId which Router.Send can then use to propagate messages
+func (r *Router) Listen(id msg.ChainId, w chains.Writer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.log.Debug("Registering new chain in router", "id", id)
+	err := r.register(r.registry, id, w)
+	if err != nil {
+		r.log.Error("Unable to register chain in router", "id", id, "err", err)
+	}
+}
+
+// ListenPriority registers a fast lane Writer for chain id. Messages to id with one of resourceIds are queued for
+// it instead of the Writer registered with Listen, so they do not wait behind other messages.
+func (r *Router) ListenPriority(id msg.ChainId, w chains.Writer, resourceIds []msg.ResourceId) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.log.Debug("Registering priority writer in router", "id", id, "resourceIds", len(resourceIds))
+
+	routes := make(map[msg.ResourceId]bool, len(resourceIds))
+	for _, rId := range resourceIds {
+		routes[rId] = true
+	}
+	if old := r.priority[id]; old != nil && old.draining {
+		r.log.Error("Unable to register priority writer in router", "id", id, "err", ErrDraining)
+		return
+	}
+	r.routes[id] = routes
+	_ = r.register(r.priority, id, w)
+}
+
+// Replace swaps the Writer registered for an existing ChainId, such as after a chain reconnects.
+// Messages still pending on the old Writer are passed to the new one. ErrDraining is returned if the Writer is
+// being drained.
+func (r *Router) Replace(id msg.ChainId, w chains.Writer) error {
+	return r.replace(r.registry, id, w)
+}
+
+// ReplacePriority swaps the priority Writer registered for an existing ChainId as Replace does. It keeps routing
+// the resource IDs it was registered with.
+func (r *Router) ReplacePriority(id msg.ChainId, w chains.Writer) error {
+	return r.replace(r.priority, id, w)
+}
+
+func (r *Router) replace(registry map[msg.ChainId]*destination, id msg.ChainId, w chains.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	old := registry[id]
+	if old == nil {
+		return fmt.Errorf("cannot replace writer for unknown chainId: %d", id)
+	}
+
+	r.log.Debug("Replacing chain in router", "id", id, "pending", old.pending())
+	return r.register(registry, id, w)
+}
+
+// Pending returns the number of messages waiting for the Writers of id, including those they are resolving
+func (r *Router) Pending(id msg.ChainId) int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	pending := 0
+	if d := r.registry[id]; d != nil {
+		pending += d.pending()
+	}
+	if d := r.priority[id]; d != nil {
+		pending += d.pending()
+	}
+	return pending
+}
+
+// Queue returns the messages waiting for the Writers of id, including those they are resolving, with the priority
+// Writer's first. Each Writer's messages in flight are listed before the messages waiting behind them.
+func (r *Router) Queue(id msg.ChainId) []QueuedMessage {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var queue []QueuedMessage
+	for _, d := range []*destination{r.priority[id], r.registry[id]} {
+		if d == nil {
+			continue
+		}
+		for _, m := range d.inflight {
+			queue = append(queue, m.snapshot(true))
+		}
+		for _, m := range d.queue {
+			queue = append(queue, m.snapshot(false))
+		}
+	}
+	return queue
+}
+
+func (m queued) snapshot(inFlight bool) QueuedMessage {
+	return QueuedMessage{
+		Message:    m.Message,
+		EnqueuedAt: m.enqueuedAt,
+		Attempts:   m.attempts,
+		LastError:  m.lastError,
+		InFlight:   inFlight,
+	}
+}
+
+// DrainNonce removes the message from source with nonce from the queues of id's Writers, so it is never resolved,
+// and returns whether it was queued. A message a Writer is already resolving is not removed.
+func (r *Router) DrainNonce(id msg.ChainId, source msg.ChainId, nonce msg.Nonce) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, d := range []*destination{r.priority[id], r.registry[id]} {
+		if d == nil {
+			continue
+		}
+		for i, m := range d.queue {
+			if m.Source == source && m.DepositNonce == nonce {
+				d.queue = append(d.queue[:i:i], d.queue[i+1:]...)
+				r.log.Warn("Removed message from queue", "src", source, "dest", id, "nonce", nonce)
+				r.updateMetrics()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Drain stops queueing messages for w, rejecting them with ErrDraining, and waits up to timeout for w to resolve
+// the messages already queued for it. w is then unregistered and the messages it did not start resolving are
+// returned, oldest first. A message w is still resolving when the timeout expires is left to finish.
+func (r *Router) Drain(w chains.Writer, timeout time.Duration) []msg.Message {
+	r.lock.Lock()
+	var ds []*destination
+	for _, registry := range []map[msg.ChainId]*destination{r.registry, r.priority} {
+		for _, d := range registry {
+			if d.writer == w {
+				d.draining = true
+				ds = append(ds, d)
+			}
+		}
+	}
+	r.lock.Unlock()
+	r.log.Debug("Draining writer", "destinations", len(ds), "timeout", timeout)
+
+	deadline := time.Now().Add(timeout)
+	for r.pendingIn(ds) > 0 && time.Now().Before(deadline) {
+		time.Sleep(DrainPollInterval)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var remaining []msg.Message
+	for _, d := range ds {
+		for _, m := range d.queue {
+			remaining = append(remaining, m.Message)
+		}
+		d.queue = nil
+	}
+	// Only the destinations still registered are stopped and removed, one replaced while draining has already been
+	// stopped by register
+	for id, d := range r.registry {
+		if containsDestination(ds, d) {
+			d.close()
+			delete(r.registry, id)
+		}
+	}
+	for id, d := range r.priority {
+		if containsDestination(ds, d) {
+			d.close()
+			delete(r.priority, id)
+			delete(r.routes, id)
+		}
+	}
+	return remaining
+}
+
+// Unregister drains the Writers registered for id, including its priority Writer, as Drain does and returns the
+// messages they did not start resolving. Messages to id are rejected once it returns.
+func (r *Router) Unregister(id msg.ChainId, timeout time.Duration) []msg.Message {
+	r.lock.RLock()
+	var writers []chains.Writer
+	for _, registry := range []map[msg.ChainId]*destination{r.registry, r.priority} {
+		if d := registry[id]; d != nil {
+			writers = append(writers, d.writer)
+		}
+	}
+	r.lock.RUnlock()
+	r.log.Debug("Unregistering chain from router", "id", id, "writers", len(writers))
+
+	var remaining []msg.Message
+	for _, w := range writers {
+		remaining = append(remaining, r.Drain(w, timeout)...)
+	}
+	return remaining
+}
+
+func containsDestination(ds []*destination, d *destination) bool {
+	for _, drained := range ds {
+		if drained == d {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingIn returns the number of messages waiting for the writers of ds, including those they are resolving
+func (r *Router) pendingIn(ds []*destination) int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	pending := 0
+	for _, d := range ds {
+		pending += d.pending()
+	}
+	return pending
+}
@@ -0,0 +1,890 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package router
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/ChainBridge/message"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	"github.com/ChainSafe/log15"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type mockWriter struct {
+	msgs  []msg.Message
+	lock  sync.Mutex
+	block chan struct{} // If set, ResolveMessage waits for this to be closed
+}
+
+func (w *mockWriter) ResolveMessage(msg msg.Message) bool {
+	if w.block != nil {
+		<-w.block
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.msgs = append(w.msgs, msg)
+	return true
+}
+
+func (w *mockWriter) received() []msg.Message {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]msg.Message{}, w.msgs...)
+}
+
+func newTestRouter() *Router {
+	tLog := log15.New("test_router")
+	tLog.SetHandler(log15.LvlFilterHandler(log15.LvlTrace, tLog.GetHandler()))
+	return NewRouter(tLog)
+}
+
+func TestRouter(t *testing.T) {
+	router := newTestRouter()
+
+	ethW := &mockWriter{}
+	router.Listen(msg.ChainId(0), ethW)
+
+	ctfgW := &mockWriter{}
+	router.Listen(msg.ChainId(1), ctfgW)
+
+	msgEthToCtfg := msg.Message{
+		Source:       msg.ChainId(0),
+		Destination:  msg.ChainId(1),
+		DepositNonce: 1,
+	}
+
+	msgCtfgToEth := msg.Message{
+		Source:       msg.ChainId(1),
+		Destination:  msg.ChainId(0),
+		DepositNonce: 1,
+	}
+
+	err := router.Send(msgCtfgToEth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = router.Send(msgEthToCtfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Second)
+
+	if !reflect.DeepEqual(ethW.received()[0], msgCtfgToEth) {
+		t.Error("Unexpected message")
+	}
+
+	if !reflect.DeepEqual(ctfgW.received()[0], msgEthToCtfg) {
+		t.Error("Unexpected message")
+	}
+
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(2), DepositNonce: 1})
+	if err == nil {
+		t.Fatal("expected error for unknown destination")
+	}
+}
+
+func TestRouter_Replace(t *testing.T) {
+	router := newTestRouter()
+
+	original := &mockWriter{}
+	router.Listen(msg.ChainId(1), original)
+
+	replacement := &mockWriter{}
+	err := router.Replace(msg.ChainId(1), replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 1}
+	err = router.Send(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Second)
+
+	if len(original.received()) != 0 {
+		t.Fatalf("original writer should not receive messages after replacement, got: %v", original.received())
+	}
+	if got := replacement.received(); len(got) != 1 || !reflect.DeepEqual(got[0], m) {
+		t.Fatalf("replacement writer did not receive message, got: %v", got)
+	}
+
+	err = router.Replace(msg.ChainId(2), replacement)
+	if err == nil {
+		t.Fatal("expected error replacing unknown chain")
+	}
+}
+
+func TestRouter_ReplaceDrainsPending(t *testing.T) {
+	router := newTestRouter()
+
+	// The original writer never resolves, as if its connection was lost
+	original := &mockWriter{block: make(chan struct{})}
+	defer close(original.block)
+	router.Listen(msg.ChainId(1), original)
+
+	m := msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 1}
+	err := router.Send(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := &mockWriter{}
+	err = router.Replace(msg.ChainId(1), replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Second)
+
+	if got := replacement.received(); len(got) != 1 || !reflect.DeepEqual(got[0], m) {
+		t.Fatalf("pending message was not passed to replacement writer, got: %v", got)
+	}
+
+	router.lock.RLock()
+	defer router.lock.RUnlock()
+	d := router.registry[msg.ChainId(1)]
+	if len(d.queue) != 0 || len(d.inflight) != 0 {
+		t.Fatalf("expected no pending messages, got queue: %v inflight: %v", d.queue, d.inflight)
+	}
+}
+
+// fillQueue sends nonces 1 through n to chain 1, waiting until the blocked writer has taken the first
+func fillQueue(t *testing.T, router *Router, n int) {
+	for i := 1; i <= n; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			waitForInflight(t, router, msg.ChainId(1))
+		}
+	}
+}
+
+func waitForInflight(t *testing.T, router *Router, id msg.ChainId) {
+	for i := 0; i < 100; i++ {
+		router.lock.RLock()
+		inflight := len(router.registry[id].inflight)
+		router.lock.RUnlock()
+		if inflight != 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("writer did not receive message")
+}
+
+func receivedNonces(w *mockWriter) []msg.Nonce {
+	var nonces []msg.Nonce
+	for _, m := range w.received() {
+		nonces = append(nonces, m.DepositNonce)
+	}
+	return nonces
+}
+
+func TestRouter_MaxInFlight(t *testing.T) {
+	router := NewRouterWithConfig(log15.New("test_router"), QueueConfig{MaxInFlight: 2})
+	writer := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), writer)
+
+	for i := 1; i <= 5; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	queue := router.Queue(msg.ChainId(1))
+	if len(queue) != 5 {
+		t.Fatalf("expected 5 pending messages, got: %d", len(queue))
+	}
+	for i, m := range queue {
+		if inFlight := i < 2; m.InFlight != inFlight || m.Message.DepositNonce != msg.Nonce(i+1) {
+			t.Fatalf("expected only nonces 1 and 2 to be in flight, got nonce %d in flight: %v", m.Message.DepositNonce, m.InFlight)
+		}
+	}
+
+	close(writer.block)
+	time.Sleep(100 * time.Millisecond)
+	if received := receivedNonces(writer); len(received) != 5 {
+		t.Fatalf("expected every message to be resolved, got: %v", received)
+	}
+	if pending := router.Pending(msg.ChainId(1)); pending != 0 {
+		t.Fatalf("expected no pending messages, got: %d", pending)
+	}
+}
+
+func TestRouter_QueueFull(t *testing.T) {
+	router := NewRouterWithConfig(log15.New("test_router"), QueueConfig{MaxQueueDepth: 2})
+
+	w := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), w)
+
+	// One message is held by the writer, two more fill the queue
+	fillQueue(t, router, 3)
+
+	err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 4})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+
+	close(w.block)
+	time.Sleep(time.Second)
+
+	if got := receivedNonces(w); !reflect.DeepEqual(got, []msg.Nonce{1, 2, 3}) {
+		t.Fatalf("unexpected messages received: %v", got)
+	}
+
+	// Once drained the queue accepts messages again
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouter_DropOldest(t *testing.T) {
+	router := NewRouterWithConfig(log15.New("test_router"), QueueConfig{MaxQueueDepth: 2, DropOldest: true})
+	dropped := droppedMessages.WithLabelValues("1")
+	before := testutil.ToFloat64(dropped)
+
+	w := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), w)
+
+	fillQueue(t, router, 3)
+
+	err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(w.block)
+	time.Sleep(time.Second)
+
+	// Nonce 2 was the oldest message still waiting in the queue
+	if got := receivedNonces(w); !reflect.DeepEqual(got, []msg.Nonce{1, 3, 4}) {
+		t.Fatalf("unexpected messages received: %v", got)
+	}
+	if diff := testutil.ToFloat64(dropped) - before; diff != 1 {
+		t.Fatalf("expected one dropped message to be counted, got: %v", diff)
+	}
+}
+
+func TestRouter_SendToDestinations(t *testing.T) {
+	router := newTestRouter()
+
+	destinations := []msg.ChainId{1, 2, 3}
+	writers := make(map[msg.ChainId]*mockWriter)
+	for _, id := range destinations {
+		// Each writer takes a while to resolve, so they must be called in parallel to finish in time
+		writers[id] = &mockWriter{block: make(chan struct{})}
+		router.Listen(id, writers[id])
+	}
+
+	m := msg.Message{Source: 0, DepositNonce: 1}
+	err := router.SendToDestinations(m, destinations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range writers {
+		go func(w *mockWriter) {
+			time.Sleep(500 * time.Millisecond)
+			close(w.block)
+		}(w)
+	}
+
+	timeout := time.After(time.Second)
+	for _, id := range destinations {
+		for len(writers[id].received()) == 0 {
+			select {
+			case <-timeout:
+				t.Fatalf("destination %d did not receive the message", id)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		expected := m
+		expected.Destination = id
+		if !reflect.DeepEqual(writers[id].received()[0], expected) {
+			t.Fatalf("unexpected message for destination %d: %+v", id, writers[id].received()[0])
+		}
+	}
+
+	// Nothing is queued if any destination is unknown
+	err = router.SendToDestinations(m, []msg.ChainId{1, 4})
+	if err == nil {
+		t.Fatal("expected error for unknown destination")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if len(writers[1].received()) != 1 {
+		t.Fatal("message was routed despite unknown destination")
+	}
+}
+
+func TestRouter_SendToDestinationsDuplicates(t *testing.T) {
+	router := NewRouterWithConfig(log15.New("test_router"), QueueConfig{MaxQueueDepth: 1})
+	dropped := droppedMessages.WithLabelValues("1")
+	before := testutil.ToFloat64(dropped)
+
+	w := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), w)
+	// The writer holds the first message, so the queue has room for one more
+	fillQueue(t, router, 1)
+
+	err := router.SendToDestinations(msg.Message{Source: 0, DepositNonce: 2}, []msg.ChainId{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queue := router.Queue(msg.ChainId(1)); len(queue) != 2 {
+		t.Fatalf("expected one copy to be queued behind the message in flight, got %d messages", len(queue))
+	}
+	if diff := testutil.ToFloat64(dropped) - before; diff != 0 {
+		t.Fatalf("expected no message to be dropped, got: %v", diff)
+	}
+
+	err = router.SendToDestinations(msg.Message{Source: 0, DepositNonce: 3}, []msg.ChainId{1, 1})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+
+	close(w.block)
+	waitForMessages(t, w, 2)
+	if got := receivedNonces(w); !reflect.DeepEqual(got, []msg.Nonce{1, 2}) {
+		t.Fatalf("unexpected messages received: %v", got)
+	}
+}
+
+type mockSink struct {
+	msgs []msg.Message
+	lock sync.Mutex
+}
+
+func (s *mockSink) Publish(m msg.Message) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.msgs = append(s.msgs, m)
+}
+
+func (s *mockSink) published() []msg.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]msg.Message{}, s.msgs...)
+}
+
+func TestRouter_Sink(t *testing.T) {
+	router := newTestRouter()
+	for _, id := range []msg.ChainId{1, 2} {
+		router.Listen(id, &mockWriter{})
+	}
+	sink := &mockSink{}
+	router.AddSink(sink)
+
+	m := msg.Message{Source: 0, Destination: 1, DepositNonce: 1}
+	err := router.Send(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published := sink.published(); len(published) != 1 || !reflect.DeepEqual(published[0], m) {
+		t.Fatalf("expected the message to be published once, got: %v", published)
+	}
+
+	// Each destination is published
+	err = router.SendToDestinations(msg.Message{Source: 0, DepositNonce: 2}, []msg.ChainId{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := sink.published()
+	if len(published) != 3 || published[1].Destination != 1 || published[2].Destination != 2 {
+		t.Fatalf("expected a message for each destination, got: %v", published)
+	}
+
+	// Messages that are not routed are not published
+	err = router.Send(msg.Message{Source: 0, Destination: 3, DepositNonce: 3})
+	if err == nil {
+		t.Fatal("expected error for unknown destination")
+	}
+	if len(sink.published()) != 3 {
+		t.Fatalf("expected an unrouted message not to be published, got: %v", sink.published())
+	}
+}
+
+func TestRouter_Pending(t *testing.T) {
+	router := newTestRouter()
+
+	// The writer blocks on the first message, so the rest wait in the queue
+	writer := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), writer)
+	for i := 1; i <= 3; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if pending := router.Pending(msg.ChainId(1)); pending != 3 {
+		t.Fatalf("expected 3 pending messages, got: %d", pending)
+	}
+	if pending := router.Pending(msg.ChainId(2)); pending != 0 {
+		t.Fatalf("expected no pending messages for an unknown chain, got: %d", pending)
+	}
+
+	close(writer.block)
+	time.Sleep(100 * time.Millisecond)
+	if pending := router.Pending(msg.ChainId(1)); pending != 0 {
+		t.Fatalf("expected no pending messages once resolved, got: %d", pending)
+	}
+}
+
+func TestRouter_Queue(t *testing.T) {
+	router := newTestRouter()
+
+	// The writer blocks on the first message, so the rest wait in the queue
+	writer := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), writer)
+	before := time.Now()
+	fillQueue(t, router, 5)
+
+	queue := router.Queue(msg.ChainId(1))
+	if len(queue) != 5 {
+		t.Fatalf("expected 5 queued messages, got: %d", len(queue))
+	}
+	for i, q := range queue {
+		if q.Message.DepositNonce != msg.Nonce(i+1) || q.Message.Destination != msg.ChainId(1) {
+			t.Fatalf("expected nonce %d to chain 1 at position %d, got: %+v", i+1, i, q.Message)
+		}
+		if q.EnqueuedAt.Before(before) || q.EnqueuedAt.After(time.Now()) {
+			t.Fatalf("unexpected enqueue time of nonce %d: %s", i+1, q.EnqueuedAt)
+		}
+		if i > 0 && q.EnqueuedAt.Before(queue[i-1].EnqueuedAt) {
+			t.Fatalf("nonce %d was enqueued before nonce %d", i+1, i)
+		}
+		inFlight := i == 0
+		attempts := 0
+		if inFlight {
+			attempts = 1
+		}
+		if q.InFlight != inFlight || q.Attempts != attempts || q.LastError != "" {
+			t.Fatalf("expected nonce %d in flight: %t with %d attempts, got: %+v", i+1, inFlight, attempts, q)
+		}
+	}
+	if queue := router.Queue(msg.ChainId(2)); len(queue) != 0 {
+		t.Fatalf("expected no queued messages for an unknown chain, got: %d", len(queue))
+	}
+
+	// The message in flight is passed to the replacement writer, which attempts it again
+	replacement := &mockWriter{block: make(chan struct{})}
+	err := router.Replace(msg.ChainId(1), replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForInflight(t, router, msg.ChainId(1))
+	queue = router.Queue(msg.ChainId(1))
+	if len(queue) != 5 || queue[0].Message.DepositNonce != 1 || !queue[0].InFlight || queue[0].Attempts != 2 || queue[0].LastError == "" {
+		t.Fatalf("expected nonce 1 to be attempted again with an error, got: %+v", queue[0])
+	}
+
+	close(writer.block)
+	close(replacement.block)
+	waitForMessages(t, replacement, 5)
+}
+
+func TestRouter_DrainNonce(t *testing.T) {
+	router := newTestRouter()
+	router.cfg.MaxInFlight = 1
+
+	writer := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), writer)
+	fillQueue(t, router, 3)
+
+	if router.DrainNonce(msg.ChainId(1), msg.ChainId(0), 1) {
+		t.Fatal("expected the message in flight not to be removed")
+	}
+	if router.DrainNonce(msg.ChainId(1), msg.ChainId(2), 2) {
+		t.Fatal("expected no message from another source to be removed")
+	}
+	if !router.DrainNonce(msg.ChainId(1), msg.ChainId(0), 2) {
+		t.Fatal("expected nonce 2 to be removed")
+	}
+	if router.DrainNonce(msg.ChainId(1), msg.ChainId(0), 2) {
+		t.Fatal("expected nonce 2 to be removed only once")
+	}
+
+	close(writer.block)
+	waitForMessages(t, writer, 2)
+	time.Sleep(100 * time.Millisecond)
+	if nonces := receivedNonces(writer); !reflect.DeepEqual(nonces, []msg.Nonce{1, 3}) {
+		t.Fatalf("expected nonces 1 and 3 to be resolved, got: %v", nonces)
+	}
+}
+
+func TestRouter_ListenPriority(t *testing.T) {
+	router := newTestRouter()
+	wbtc := msg.ResourceIdFromSlice([]byte("WBTC"))
+	weth := msg.ResourceIdFromSlice([]byte("WETH"))
+
+	// The standard writer blocks, so its messages wait in the queue
+	writer := &mockWriter{block: make(chan struct{})}
+	router.Listen(msg.ChainId(1), writer)
+	fastLane := &mockWriter{}
+	router.ListenPriority(msg.ChainId(1), fastLane, []msg.ResourceId{wbtc})
+
+	for i := 1; i <= 3; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i), ResourceId: weth})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	deposit := msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(4), ResourceId: wbtc}
+	err := router.Send(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if received := fastLane.received(); !reflect.DeepEqual(received, []msg.Message{deposit}) {
+		t.Fatalf("expected the WBTC deposit to skip the standard queue, fast lane received: %v", received)
+	}
+	if pending := router.Pending(msg.ChainId(1)); pending != 3 {
+		t.Fatalf("expected 3 pending messages, got: %d", pending)
+	}
+
+	close(writer.block)
+	time.Sleep(100 * time.Millisecond)
+	if received := writer.received(); len(received) != 3 {
+		t.Fatalf("expected the standard writer to resolve 3 messages, got: %d", len(received))
+	}
+	if received := fastLane.received(); len(received) != 1 {
+		t.Fatalf("expected the fast lane to resolve only the WBTC deposit, got: %d", len(received))
+	}
+}
+
+func TestRouter_ReplacePriority(t *testing.T) {
+	router := newTestRouter()
+	wbtc := msg.ResourceIdFromSlice([]byte("WBTC"))
+
+	router.Listen(msg.ChainId(1), &mockWriter{})
+	original := &mockWriter{}
+	router.ListenPriority(msg.ChainId(1), original, []msg.ResourceId{wbtc})
+
+	replacement := &mockWriter{}
+	err := router.ReplacePriority(msg.ChainId(1), replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 1, ResourceId: wbtc}
+	err = router.Send(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if received := original.received(); len(received) != 0 {
+		t.Fatalf("original priority writer should not receive messages after replacement, got: %v", received)
+	}
+	if received := replacement.received(); !reflect.DeepEqual(received, []msg.Message{deposit}) {
+		t.Fatalf("expected the replacement to receive the WBTC deposit, got: %v", received)
+	}
+
+	err = router.ReplacePriority(msg.ChainId(2), replacement)
+	if err == nil {
+		t.Fatal("expected error replacing unknown priority writer")
+	}
+}
+
+func TestRouter_InvalidMessage(t *testing.T) {
+	router := newTestRouter()
+	writer := &mockWriter{}
+	router.Listen(msg.ChainId(1), writer)
+
+	err := router.Send(msg.Message{Source: msg.ChainId(1), Destination: msg.ChainId(1), DepositNonce: 1})
+	if !errors.Is(err, message.ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got: %v", err)
+	}
+	err = router.SendToDestinations(msg.Message{Source: msg.ChainId(0)}, []msg.ChainId{1})
+	if !errors.Is(err, message.ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if len(writer.received()) != 0 {
+		t.Fatalf("expected invalid messages not to be forwarded, got: %v", writer.received())
+	}
+}
+
+func TestRouter_Drain(t *testing.T) {
+	router := newTestRouter()
+	router.cfg.MaxInFlight = 1
+	writer := &mockWriter{block: make(chan struct{})}
+	defer close(writer.block)
+	router.Listen(msg.ChainId(1), writer)
+
+	for i := 1; i <= 3; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remaining := make(chan []msg.Message)
+	go func() {
+		remaining <- router.Drain(writer, 100*time.Millisecond)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 4})
+	if !errors.Is(err, ErrDraining) {
+		t.Fatalf("expected ErrDraining, got: %v", err)
+	}
+
+	// The first message is still being resolved, the others were never started
+	dropped := <-remaining
+	if len(dropped) != 2 || dropped[0].DepositNonce != 2 || dropped[1].DepositNonce != 3 {
+		t.Fatalf("expected nonces 2 and 3 to be returned, got: %v", dropped)
+	}
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 5})
+	if err == nil {
+		t.Fatal("expected the drained writer to be unregistered")
+	}
+}
+
+func TestRouter_ReplaceWhileDraining(t *testing.T) {
+	router := newTestRouter()
+	router.cfg.MaxInFlight = 1
+	writer := &mockWriter{block: make(chan struct{})}
+	defer close(writer.block)
+	router.Listen(msg.ChainId(1), writer)
+	fillQueue(t, router, 2)
+
+	remaining := make(chan []msg.Message)
+	go func() {
+		remaining <- router.Drain(writer, 100*time.Millisecond)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The draining writer is not replaced, so it is only stopped once by Drain
+	replacement := &mockWriter{}
+	err := router.Replace(msg.ChainId(1), replacement)
+	if !errors.Is(err, ErrDraining) {
+		t.Fatalf("expected ErrDraining, got: %v", err)
+	}
+	router.Listen(msg.ChainId(1), replacement)
+
+	if dropped := <-remaining; len(dropped) != 1 || dropped[0].DepositNonce != 2 {
+		t.Fatalf("expected nonce 2 to be returned, got: %v", dropped)
+	}
+	if got := replacement.received(); len(got) != 0 {
+		t.Fatalf("expected the replacement not to receive messages, got: %v", got)
+	}
+
+	// Once drained the chain can be registered again
+	router.Listen(msg.ChainId(1), replacement)
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMessages(t, replacement, 1)
+}
+
+func TestRouter_Unregister(t *testing.T) {
+	router := newTestRouter()
+	writer := &mockWriter{}
+	fastLane := &mockWriter{}
+	wbtc := msg.ResourceIdFromSlice([]byte{1})
+	router.Listen(msg.ChainId(1), writer)
+	router.ListenPriority(msg.ChainId(1), fastLane, []msg.ResourceId{wbtc})
+	other := &mockWriter{}
+	router.Listen(msg.ChainId(2), other)
+
+	err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 2, ResourceId: wbtc})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := router.Unregister(msg.ChainId(1), time.Second)
+	if len(remaining) != 0 {
+		t.Fatalf("expected the queued messages to be resolved, got: %v", remaining)
+	}
+	if len(writer.received()) != 1 || len(fastLane.received()) != 1 {
+		t.Fatalf("expected each writer to resolve one message, got: %d and %d", len(writer.received()), len(fastLane.received()))
+	}
+	for _, rId := range []msg.ResourceId{{}, wbtc} {
+		err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: 3, ResourceId: rId})
+		if err == nil {
+			t.Fatal("expected an error for an unregistered destination")
+		}
+	}
+
+	// Other chains are not affected
+	err = router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(2), DepositNonce: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouter_Metrics(t *testing.T) {
+	router := newTestRouter()
+
+	w := &mockWriter{}
+	router.Listen(msg.ChainId(1), w)
+	for i := 1; i <= 100; i++ {
+		err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(1), DepositNonce: msg.Nonce(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := router.Send(msg.Message{Source: msg.ChainId(0), Destination: msg.ChainId(2), DepositNonce: 101})
+	if err == nil {
+		t.Fatal("expected an error for an unknown destination")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	m := router.Metrics()
+	if m.TotalSent != 100 {
+		t.Fatalf("expected 100 sent messages, got: %d", m.TotalSent)
+	}
+	if m.TotalFailed != 1 {
+		t.Fatalf("expected 1 failed message, got: %d", m.TotalFailed)
+	}
+	if m.WritersRegistered != 1 {
+		t.Fatalf("expected 1 registered writer, got: %d", m.WritersRegistered)
+	}
+	if m.QueueDepth != 0 {
+		t.Fatalf("expected the queue to be empty, got: %d", m.QueueDepth)
+	}
+	if m.MessageRatePerSecond <= 0 {
+		t.Fatalf("expected a positive message rate, got: %f", m.MessageRatePerSecond)
+	}
+	if sent := testutil.ToFloat64(sentGauge); sent != 100 {
+		t.Fatalf("expected the sent gauge to be 100, got: %v", sent)
+	}
+}
+
+// depositWriter deposits the messages forwarded to it on its chain, as the writer of a chain messages are forwarded
+// via does. The deposits are routed by the router of a relayer of the chain and the one they are forwarded to.
+type depositWriter struct {
+	chain  msg.ChainId
+	router *Router
+	nonce  msg.Nonce
+	errs   chan error
+}
+
+func (w *depositWriter) ResolveMessage(m msg.Message) bool {
+	original, ok := message.Unforward(m)
+	if !ok {
+		w.errs <- errors.New("expected a forwarded message")
+		return false
+	}
+	w.nonce++
+	deposit := msg.NewGenericTransfer(w.chain, original.Destination, w.nonce, original.ResourceId, original.Payload[0].([]byte))
+	w.errs <- w.router.Send(deposit)
+	return true
+}
+
+func waitForMessages(t *testing.T, w *mockWriter, n int) []msg.Message {
+	for i := 0; i < 100; i++ {
+		if received := w.received(); len(received) >= n {
+			return received
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d messages, got: %d", n, len(w.received()))
+	return nil
+}
+
+func TestRouter_Forward(t *testing.T) {
+	// One relayer connects chains 0 and 1, another chains 1 and 2, so chain 2 is only reachable from 0 via 1
+	first, second := newTestRouter(), newTestRouter()
+	chain0 := &mockWriter{}
+	forwarder := &depositWriter{chain: 1, router: second, errs: make(chan error, 1)}
+	first.Listen(0, chain0)
+	first.Listen(1, forwarder)
+	chain1, chain2 := &mockWriter{}, &mockWriter{}
+	second.Listen(1, chain1)
+	second.Listen(2, chain2)
+
+	generic := msg.NewGenericTransfer(0, 2, 7, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata"))
+	err := first.Send(generic)
+	if err == nil {
+		t.Fatal("expected error sending to a chain without a route")
+	}
+	err = first.SetRoute(0, 2, []msg.ChainId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = first.Send(generic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-forwarder.errs:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the message to be forwarded via chain 1")
+	}
+
+	received := waitForMessages(t, chain2, 1)
+	expected := msg.NewGenericTransfer(1, 2, 1, generic.ResourceId, []byte("metadata"))
+	if !reflect.DeepEqual(received[0], expected) {
+		t.Fatalf("expected %+v, got: %+v", expected, received[0])
+	}
+	if len(chain0.received()) != 0 || len(chain1.received()) != 0 {
+		t.Fatal("expected no messages for chains 0 and 1")
+	}
+
+	// Only generic messages can be forwarded
+	err = first.Send(msg.NewFungibleTransfer(0, 2, 8, big.NewInt(1), generic.ResourceId, []byte{1}))
+	if !errors.Is(err, message.ErrNotForwardable) {
+		t.Fatalf("expected ErrNotForwardable, got: %v", err)
+	}
+	// Metadata that could be mistaken for a forwarded message is rejected
+	forwarded, err := message.Forward(generic, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = first.Send(forwarded)
+	if !errors.Is(err, ErrForwardedMetadata) {
+		t.Fatalf("expected ErrForwardedMetadata, got: %v", err)
+	}
+}
+
+func TestRouter_ForwardMultipleHops(t *testing.T) {
+	router := newTestRouter()
+	chain3 := &mockWriter{}
+	router.Listen(3, chain3)
+
+	err := router.SetRoute(0, 2, []msg.ChainId{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The deposit made on chain 1 is sent on to the next hop of the route
+	err = router.Send(msg.NewGenericTransfer(1, 2, 1, msg.ResourceIdFromSlice([]byte{1}), []byte("metadata")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := waitForMessages(t, chain3, 1)
+	original, ok := message.Unforward(received[0])
+	if !ok || received[0].Destination != 3 || original.Destination != 2 || original.Source != 1 {
+		t.Fatalf("expected a message forwarded to 2 via 3, got: %+v", received[0])
+	}
+
+	err = router.SetRoute(0, 2, []msg.ChainId{1, 1})
+	if err == nil {
+		t.Fatal("expected error for a route that visits a chain twice")
+	}
+}
@@ -6,32 +6,20 @@ package utils
 import (
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common/math"
+	handlerabi "github.com/ChainSafe/ChainBridge/chains/ethereum/abi"
 )
 
-// constructErc20Data constructs the data field to be passed into a n erc20 deposit call
+// ConstructErc20DepositData constructs the data field to be passed into an erc20 deposit call
 func ConstructErc20DepositData(destRecipient []byte, amount *big.Int) []byte {
-	var data []byte
-	data = append(data, math.PaddedBigBytes(amount, 32)...)
-	data = append(data, math.PaddedBigBytes(big.NewInt(int64(len(destRecipient))), 32)...)
-	data = append(data, destRecipient...)
-	return data
+	return handlerabi.EncodeERC20DepositData(destRecipient, amount)
 }
 
-// constructErc20Data constructs the data field to be passed into an erc721 deposit call
+// ConstructErc721DepositData constructs the data field to be passed into an erc721 deposit call
 func ConstructErc721DepositData(tokenId *big.Int, destRecipient []byte) []byte {
-	var data []byte
-	data = append(data, math.PaddedBigBytes(tokenId, 32)...)                               // Resource Id + Token Id
-	data = append(data, math.PaddedBigBytes(big.NewInt(int64(len(destRecipient))), 32)...) // Length of recipient
-	data = append(data, destRecipient...)                                                  // Recipient
-
-	return data
+	return handlerabi.EncodeERC721DepositData(tokenId, destRecipient)
 }
 
+// ConstructGenericDepositData constructs the data field to be passed into a generic deposit call
 func ConstructGenericDepositData(metadata []byte) []byte {
-	var data []byte
-	data = append(data, math.PaddedBigBytes(big.NewInt(int64(len(metadata))), 32)...)
-	data = append(data, metadata...)
-
-	return data
+	return handlerabi.EncodeGenericDepositData(metadata)
 }
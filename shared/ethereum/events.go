@@ -15,9 +15,12 @@ func (es EventSig) GetTopic() common.Hash {
 }
 
 const (
-	Deposit       EventSig = "Deposit(uint8,bytes32,uint64)"
-	ProposalEvent EventSig = "ProposalEvent(uint8,uint64,uint8,bytes32,bytes32)"
-	ProposalVote  EventSig = "ProposalVote(uint8,uint64,uint8,bytes32)"
+	Deposit         EventSig = "Deposit(uint8,bytes32,uint64)"
+	PermitDeposited EventSig = "PermitDeposited(uint8,bytes32,uint64,uint256,uint8,bytes32,bytes32)"
+	ProposalEvent   EventSig = "ProposalEvent(uint8,uint64,uint8,bytes32,bytes32)"
+	ProposalVote    EventSig = "ProposalVote(uint8,uint64,uint8,bytes32)"
+	ResourceIDSet   EventSig = "ResourceIDSet(bytes32,address)"
+	TokensUnlocked  EventSig = "TokensUnlocked(address,address,uint256)"
 )
 
 type ProposalStatus int
@@ -0,0 +1,57 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package utils
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DeployMockFeeHandler deploys a fee handler for testing. calculateFee always returns fee, and any other call
+// (including collectFee) stores the value sent in storage slot 0.
+func DeployMockFeeHandler(client *Client, fee *big.Int) (common.Address, error) {
+	var code []byte
+	code = append(code, common.FromHex("0x603e80600b6000396000f3")...)                       // creation code, copies the 62 byte runtime
+	code = append(code, common.FromHex("0x60003560e01c63d88d2f9d1460145734600055005b7f")...) // dispatch calculateFee selector, else store callvalue
+	code = append(code, common.LeftPadBytes(fee.Bytes(), 32)...)
+	code = append(code, common.FromHex("0x60005260206000f3")...) // return fee
+
+	return deployBytecode(client, code)
+}
+
+// DeployMockRelayerFees deploys a bridge for testing relay fees. getAvailableFees always returns fees, and any
+// other call (including claimFee) stores the caller in storage slot 0.
+func DeployMockRelayerFees(client *Client, fees *big.Int) (common.Address, error) {
+	var code []byte
+	code = append(code, common.FromHex("0x603e80600b6000396000f3")...)                       // creation code, copies the 62 byte runtime
+	code = append(code, common.FromHex("0x60003560e01c63007a94c11460145733600055005b7f")...) // dispatch getAvailableFees selector, else store caller
+	code = append(code, common.LeftPadBytes(fees.Bytes(), 32)...)
+	code = append(code, common.FromHex("0x60005260206000f3")...) // return fees
+
+	return deployBytecode(client, code)
+}
+
+func deployBytecode(client *Client, code []byte) (common.Address, error) {
+	err := client.LockNonceAndUpdate()
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	addr, tx, _, err := bind.DeployContract(client.Opts, abi.ABI{}, code, client.Client)
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	err = WaitForTx(client, tx)
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	client.UnlockNonce()
+
+	return addr, nil
+}
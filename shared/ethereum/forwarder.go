@@ -0,0 +1,22 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package utils
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DeployMockForwarder deploys a minimal EIP-2771 forwarder for testing. getNonce always returns nonce, and any other
+// call (including execute) stores the caller in storage slot 0. Requests are not verified or forwarded.
+func DeployMockForwarder(client *Client, nonce *big.Int) (common.Address, error) {
+	var code []byte
+	code = append(code, common.FromHex("0x603e80600b6000396000f3")...)                       // creation code, copies the 62 byte runtime
+	code = append(code, common.FromHex("0x60003560e01c632d0335ab1460145733600055005b7f")...) // dispatch getNonce selector, else store caller
+	code = append(code, common.LeftPadBytes(nonce.Bytes(), 32)...)
+	code = append(code, common.FromHex("0x60005260206000f3")...) // return nonce
+
+	return deployBytecode(client, code)
+}
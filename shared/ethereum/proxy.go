@@ -0,0 +1,37 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package utils
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DeployMinimalProxy deploys an EIP-1167 minimal proxy clone that delegates all calls to impl
+func DeployMinimalProxy(client *Client, impl common.Address) (common.Address, error) {
+	var code []byte
+	code = append(code, common.FromHex("0x3d602d80600a3d3981f3363d3d373d3d3d363d73")...) // creation code and runtime prefix
+	code = append(code, impl.Bytes()...)
+	code = append(code, common.FromHex("0x5af43d82803e903d91602b57fd5bf3")...) // runtime suffix
+
+	err := client.LockNonceAndUpdate()
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	addr, tx, _, err := bind.DeployContract(client.Opts, abi.ABI{}, code, client.Client)
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	err = WaitForTx(client, tx)
+	if err != nil {
+		return ZeroAddress, err
+	}
+
+	client.UnlockNonce()
+
+	return addr, nil
+}
@@ -99,3 +99,35 @@ func Erc20AssertResourceMapping(t *testing.T, client *utils.Client, handler comm
 		t.Fatalf("Unexpected address for resource ID %x. Expected: %x Got: %x", rId, expected, addr)
 	}
 }
+
+func DeployMinimalProxy(t *testing.T, client *utils.Client, impl common.Address) common.Address {
+	addr, err := utils.DeployMinimalProxy(client, impl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func DeployMockFeeHandler(t *testing.T, client *utils.Client, fee *big.Int) common.Address {
+	addr, err := utils.DeployMockFeeHandler(client, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func DeployMockRelayerFees(t *testing.T, client *utils.Client, fees *big.Int) common.Address {
+	addr, err := utils.DeployMockRelayerFees(client, fees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func DeployMockForwarder(t *testing.T, client *utils.Client, nonce *big.Int) common.Address {
+	addr, err := utils.DeployMockForwarder(client, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
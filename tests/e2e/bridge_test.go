@@ -0,0 +1,146 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+// +build integration
+
+package e2e
+
+import (
+	"math/big"
+	"testing"
+
+	ethChain "github.com/ChainSafe/ChainBridge/chains/ethereum"
+	"github.com/ChainSafe/ChainBridge/core"
+	eth "github.com/ChainSafe/ChainBridge/e2e/ethereum"
+	ethutils "github.com/ChainSafe/ChainBridge/shared/ethereum"
+	ethtest "github.com/ChainSafe/ChainBridge/shared/ethereum/testing"
+	"github.com/ChainSafe/chainbridge-utils/msg"
+	log "github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const ChainAId = msg.ChainId(0)
+const ChainBId = msg.ChainId(1)
+
+// Relayer is the only relayer of both bridges
+const Relayer = "bob"
+
+type testContext struct {
+	chainA *eth.TestContext
+	chainB *eth.TestContext
+
+	Erc20ResourceId   msg.ResourceId // Tokens locked on chain A are minted on chain B
+	GenericResourceId msg.ResourceId // Deposits on chain B store a hash on chain A
+}
+
+// startBridge runs a relayer for both chains in-process
+func startBridge(t *testing.T, ctx *testContext) *core.Core {
+	logger := log.Root().New("relayer", Relayer)
+	sysErr := make(chan error)
+	bridge := core.NewCore(sysErr)
+
+	for _, c := range []struct {
+		id       msg.ChainId
+		ctx      *eth.TestContext
+		endpoint string
+	}{
+		{ChainAId, ctx.chainA, eth.EthAEndpoint},
+		{ChainBId, ctx.chainB, eth.EthBEndpoint},
+	} {
+		cfg := eth.CreateConfig(Relayer, c.id, c.ctx.BaseContracts, c.endpoint)
+		chain, err := ethChain.InitializeChain(cfg, logger.New("chain", c.id), sysErr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bridge.Register(chain)
+		err = chain.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bridge
+}
+
+func setupErc20(t *testing.T, ctx *testContext) {
+	// Tokens are locked in the handler on chain A
+	erc20A := ethtest.Erc20DeployMint(t, ctx.chainA.Client, big.NewInt(1000))
+	ethtest.Erc20Approve(t, ctx.chainA.Client, erc20A, ctx.chainA.BaseContracts.ERC20HandlerAddress, big.NewInt(500))
+	rId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(erc20A.Bytes(), 31), byte(ChainAId)))
+	ethtest.RegisterResource(t, ctx.chainA.Client, ctx.chainA.BaseContracts.BridgeAddress, ctx.chainA.BaseContracts.ERC20HandlerAddress, rId, erc20A)
+
+	// And minted by the handler on chain B
+	erc20B := ethtest.Erc20DeployMint(t, ctx.chainB.Client, big.NewInt(0))
+	ethtest.Erc20AddMinter(t, ctx.chainB.Client, erc20B, ctx.chainB.BaseContracts.ERC20HandlerAddress)
+	ethtest.RegisterResource(t, ctx.chainB.Client, ctx.chainB.BaseContracts.BridgeAddress, ctx.chainB.BaseContracts.ERC20HandlerAddress, rId, erc20B)
+	ethtest.SetBurnable(t, ctx.chainB.Client, ctx.chainB.BaseContracts.BridgeAddress, ctx.chainB.BaseContracts.ERC20HandlerAddress, erc20B)
+
+	ctx.chainA.TestContracts.Erc20Eth = erc20A
+	ctx.chainB.TestContracts.Erc20Eth = erc20B
+	ctx.Erc20ResourceId = rId
+}
+
+func setupGeneric(t *testing.T, ctx *testContext) {
+	// The asset store on chain A is called with the deposit metadata
+	assetStore := ethtest.DeployAssetStore(t, ctx.chainA.Client)
+	rId := msg.ResourceIdFromSlice(append(common.LeftPadBytes(assetStore.Bytes(), 31), byte(ChainAId)))
+	ethtest.RegisterGenericResource(t, ctx.chainA.Client, ctx.chainA.BaseContracts.BridgeAddress, ctx.chainA.BaseContracts.GenericHandlerAddress, rId, assetStore, [4]byte{}, ethutils.StoreFunctionSig)
+	// The resource must be registered on chain B for deposits, the address is not called
+	ethtest.RegisterGenericResource(t, ctx.chainB.Client, ctx.chainB.BaseContracts.BridgeAddress, ctx.chainB.BaseContracts.GenericHandlerAddress, rId, assetStore, [4]byte{}, [4]byte{})
+
+	ctx.chainA.TestContracts.AssetStoreEth = assetStore
+	ctx.GenericResourceId = rId
+}
+
+// Test_TwoChains relays deposits in both directions between two ethereum chains with a single relayer
+func Test_TwoChains(t *testing.T) {
+	clientA := ethtest.NewClient(t, eth.EthAEndpoint, eth.AliceKp)
+	clientB := ethtest.NewClient(t, eth.EthBEndpoint, eth.AliceKp)
+	ctx := &testContext{
+		chainA: &eth.TestContext{
+			BaseContracts: eth.DeployTestContracts(t, clientA, eth.EthAEndpoint, ChainAId, big.NewInt(1)),
+			Client:        clientA,
+		},
+		chainB: &eth.TestContext{
+			BaseContracts: eth.DeployTestContracts(t, clientB, eth.EthBEndpoint, ChainBId, big.NewInt(1)),
+			Client:        clientB,
+		},
+	}
+	setupErc20(t, ctx)
+	setupGeneric(t, ctx)
+
+	bridge := startBridge(t, ctx)
+
+	t.Run("Erc20 deposit mints on chain B", func(t *testing.T) {
+		recipient := eth.CharlieKp.CommonAddress()
+		amount := big.NewInt(10)
+		nonce := ethtest.GetDepositNonce(t, ctx.chainA.Client, ctx.chainA.BaseContracts.BridgeAddress, ChainBId) + 1
+
+		err := ethutils.UpdateNonce(ctx.chainA.Client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eth.CreateErc20Deposit(t, ctx.chainA.Client, ChainBId, recipient.Bytes(), amount, ctx.chainA.BaseContracts, ctx.Erc20ResourceId)
+		eth.WaitForProposalExecutedEvent(t, ctx.chainB.Client, ctx.chainB.BaseContracts.BridgeAddress, nonce)
+
+		ethtest.Erc20AssertBalance(t, ctx.chainB.Client, amount, ctx.chainB.TestContracts.Erc20Eth, recipient)
+		ethtest.Erc20AssertBalance(t, ctx.chainA.Client, amount, ctx.chainA.TestContracts.Erc20Eth, ctx.chainA.BaseContracts.ERC20HandlerAddress)
+	})
+
+	t.Run("Generic deposit calls chain A", func(t *testing.T) {
+		hash := crypto.Keccak256Hash([]byte("chainbridge"))
+		nonce := ethtest.GetDepositNonce(t, ctx.chainB.Client, ctx.chainB.BaseContracts.BridgeAddress, ChainAId) + 1
+
+		eth.CreateGenericDeposit(t, ctx.chainB.Client, ChainAId, hash[:], ctx.chainB.BaseContracts, ctx.GenericResourceId)
+		eth.WaitForProposalExecutedEvent(t, ctx.chainA.Client, ctx.chainA.BaseContracts.BridgeAddress, nonce)
+
+		ethtest.AssertHashExistence(t, ctx.chainA.Client, hash, ctx.chainA.TestContracts.AssetStoreEth)
+	})
+
+	select {
+	case err := <-bridge.Errors():
+		t.Fatalf("bridge error: %s", err)
+	default:
+	}
+}
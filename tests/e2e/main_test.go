@@ -0,0 +1,86 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+// +build integration
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	eth "github.com/ChainSafe/ChainBridge/e2e/ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ComposeFile starts the chains the tests run against
+const ComposeFile = "../../docker-compose-integration.yml"
+
+// ExternalChainsEnv skips docker-compose when set, for chains that are already running on the same ports
+const ExternalChainsEnv = "E2E_EXTERNAL_CHAINS"
+
+// StartTimeout is the maximum time to wait for the chains to accept connections
+const StartTimeout = time.Minute
+
+func TestMain(m *testing.M) {
+	external := os.Getenv(ExternalChainsEnv) != ""
+	if !external {
+		err := compose("up", "-d", "-V")
+		if err != nil {
+			fmt.Println("Unable to start chains:", err)
+			os.Exit(1)
+		}
+	}
+
+	err := waitForChains(eth.EthAEndpoint, eth.EthBEndpoint)
+	code := 1
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		code = m.Run()
+	}
+
+	if !external {
+		err = compose("down", "-v")
+		if err != nil {
+			fmt.Println("Unable to stop chains:", err)
+		}
+	}
+	os.Exit(code)
+}
+
+func compose(args ...string) error {
+	cmd := exec.Command("docker-compose", append([]string{"-f", ComposeFile}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// waitForChains returns once each endpoint reports its latest block
+func waitForChains(endpoints ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+	defer cancel()
+	for _, endpoint := range endpoints {
+		for {
+			client, err := ethclient.DialContext(ctx, endpoint)
+			if err == nil {
+				_, err = client.BlockNumber(ctx)
+				client.Close()
+			}
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("chain at %s did not start: %w", endpoint, err)
+			case <-time.After(time.Second):
+			}
+		}
+	}
+	return nil
+}
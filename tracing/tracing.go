@@ -0,0 +1,66 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+/*
+The tracing package exports OpenTelemetry traces of the relayer to a tracing backend.
+
+Spans are sent to a Jaeger collector's HTTP endpoint. A fraction of traces, set by the sample rate, is recorded so
+the overhead of tracing stays low on busy relayers.
+*/
+package tracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Supported tracing backends
+const JaegerBackend = "jaeger"
+
+// DefaultSampleRate is the fraction of traces recorded when no sample rate is configured
+const DefaultSampleRate = 0.1
+
+// ServiceName identifies the relayer's spans in the tracing backend
+const ServiceName = "chainbridge"
+
+// NewJaegerExporter returns an exporter that sends spans to the Jaeger collector HTTP endpoint, eg.
+// http://localhost:14268/api/traces
+func NewJaegerExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(endpoint)))
+}
+
+// NewTracerProvider returns a provider that records sampleRate of new traces, and the spans of traces sampled by
+// the caller, and exports them in batches with exporter
+func NewTracerProvider(exporter sdktrace.SpanExporter, sampleRate float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))),
+		sdktrace.WithResource(resource.NewWithAttributes("", attribute.String("service.name", ServiceName))),
+	)
+}
+
+// Setup installs the global tracer provider of backend, which must be shut down to export the remaining spans
+func Setup(backend, endpoint string, sampleRate float64) (*sdktrace.TracerProvider, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("tracing sample rate %v is not between 0 and 1", sampleRate)
+	}
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch backend {
+	case JaegerBackend:
+		exporter, err = NewJaegerExporter(endpoint)
+	default:
+		return nil, fmt.Errorf("unsupported tracing backend %s", backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tp := NewTracerProvider(exporter, sampleRate)
+	otel.SetTracerProvider(tp)
+	return tp, nil
+}
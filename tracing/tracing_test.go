@@ -0,0 +1,115 @@
+// Copyright 2020 ChainSafe Systems
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// mockCollector is a Jaeger collector HTTP endpoint that records the batches of spans posted to it
+type mockCollector struct {
+	batches [][]byte
+	lock    sync.Mutex
+}
+
+func (c *mockCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-thrift" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	c.batches = append(c.batches, body)
+	c.lock.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// exported returns whether a batch holding name was posted
+func (c *mockCollector) exported(name string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, batch := range c.batches {
+		if bytes.Contains(batch, []byte(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestJaegerExporter(t *testing.T) {
+	collector := &mockCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	exporter, err := NewJaegerExporter(srv.URL + "/api/traces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := NewTracerProvider(exporter, 1)
+	_, span := tp.Tracer("test").Start(context.Background(), "resolveMessage")
+	span.End()
+	err = tp.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !collector.exported("resolveMessage") {
+		t.Fatal("expected the span to be exported to the collector")
+	}
+	if !collector.exported(ServiceName) {
+		t.Fatal("expected the spans to be exported with the service name")
+	}
+}
+
+func TestJaegerExporter_NotSampled(t *testing.T) {
+	collector := &mockCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	exporter, err := NewJaegerExporter(srv.URL + "/api/traces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := NewTracerProvider(exporter, 0)
+	_, span := tp.Tracer("test").Start(context.Background(), "resolveMessage")
+	span.End()
+	err = tp.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if collector.exported("resolveMessage") {
+		t.Fatal("expected the span not to be sampled with a sample rate of 0")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	_, err := Setup("zipkin", "http://localhost:9411", DefaultSampleRate)
+	if err == nil {
+		t.Fatal("expected an error for an unsupported backend")
+	}
+	_, err = Setup(JaegerBackend, "http://localhost:14268/api/traces", 2)
+	if err == nil {
+		t.Fatal("expected an error for a sample rate above 1")
+	}
+
+	tp, err := Setup(JaegerBackend, "http://localhost:14268/api/traces", DefaultSampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tp.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}